@@ -0,0 +1,205 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"text/tabwriter"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/news"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// doctorCheck is the outcome of one doctor check.
+type doctorCheck struct {
+	Name   string // Name is the component checked.
+	Passed bool   // Passed reports whether the check succeeded.
+	Detail string // Detail describes what was found.
+	Hint   string // Hint suggests how to fix a failed check.
+}
+
+// doctor runs the startup checks with the bot's flags, prints a PASS/FAIL table and exits with
+// status 1 if any check failed.
+func doctor(cmd *cobra.Command, args []string) {
+	// Keep logs of the checked components out of the report; failures are reported in it
+	log.SetLevel(log.ErrorLevel)
+
+	config, err := botConfig(cmd)
+	checks := []doctorCheck{checkConfig(config, err)}
+
+	noBackup, _ := cmd.Flags().GetBool("no-migration-backup")
+	options := database.DefaultInitOptions()
+	options.MigrationBackup = !noBackup
+	checks = append(checks, checkDatabase(config.DatabasePath, options))
+
+	if config.DiscordToken == "" {
+		checks = append(checks, doctorCheck{
+			Name:   "Discord token",
+			Detail: "no token given",
+			Hint:   "Set DISCORD_TOKEN or pass --token",
+		})
+	} else {
+		dg, err := discordgo.New("Bot " + config.DiscordToken)
+		if err != nil {
+			log.Fatalf("Failed to create Discord session: %v", err)
+		}
+		checks = append(checks, checkDiscordToken(dg))
+	}
+
+	checks = append(checks, checkNewsAPI(news.Fetcher(&types.Bot{Config: config})))
+
+	if failed := writeDoctorReport(cmd.OutOrStdout(), checks); failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// checkConfig validates the bot configuration; parseErr is an error reading it from the flags.
+func checkConfig(config *types.Config, parseErr error) doctorCheck {
+	check := doctorCheck{Name: "Configuration"}
+	err := parseErr
+	if err == nil {
+		err = config.Validate()
+	}
+	if err != nil {
+		check.Detail = err.Error()
+		check.Hint = "Fix the flag or environment variable named above; see stobot --help"
+		return check
+	}
+	check.Passed = true
+	check.Detail = fmt.Sprintf("polling %d news every %ds", config.PollCount, config.PollPeriod)
+	if config.Environment != "" {
+		check.Detail += fmt.Sprintf(" in %s", config.Environment)
+	}
+	return check
+}
+
+// checkDatabase opens and migrates the database at dbPath, pings it and checks that it can be
+// written with a write probe that is rolled back.
+func checkDatabase(dbPath string, options database.InitOptions) doctorCheck {
+	check := doctorCheck{
+		Name: "Database",
+		Hint: "Check that the directory of --database-path (DATABASE_PATH) exists and is writable by the bot, " +
+			"and that no other process holds the database locked",
+	}
+	if dbPath == "" {
+		check.Detail = "no database path given"
+		check.Hint = "Set DATABASE_PATH or DATABASE_URL, or pass --database-path"
+		return check
+	}
+
+	db, err := database.InitDatabaseWithOptions(dbPath, options)
+	if err != nil {
+		check.Detail = fmt.Sprintf("failed to open %s: %v", database.DisplayPath(dbPath), err)
+		return check
+	}
+	defer db.Close()
+
+	if err := db.Ping(); err != nil {
+		check.Detail = fmt.Sprintf("failed to ping %s: %v", database.DisplayPath(dbPath), err)
+		return check
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		check.Detail = fmt.Sprintf("failed to begin write probe: %v", err)
+		return check
+	}
+	_, err = tx.Exec(`CREATE TABLE doctor_write_probe (id INTEGER)`)
+	if rollbackErr := tx.Rollback(); err == nil && rollbackErr != nil {
+		err = rollbackErr
+	}
+	if err != nil {
+		check.Detail = fmt.Sprintf("write probe on %s failed: %v", database.DisplayPath(dbPath), err)
+		return check
+	}
+
+	version, err := database.GetSchemaVersion(db)
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+	check.Passed = true
+	check.Detail = fmt.Sprintf("%s is writable, schema version %d", database.DisplayPath(dbPath), version)
+	check.Hint = ""
+	return check
+}
+
+// checkDiscordToken checks that Discord accepts the session's token by looking up the bot user.
+func checkDiscordToken(s *discordgo.Session) doctorCheck {
+	check := doctorCheck{Name: "Discord token"}
+	user, err := s.User("@me")
+	if err != nil {
+		var restErr *discordgo.RESTError
+		if errors.As(err, &restErr) && restErr.Response != nil && restErr.Response.StatusCode == http.StatusUnauthorized {
+			check.Detail = "Discord rejected the token"
+			check.Hint = "Copy the token from the Bot page of the Discord Developer Portal (not the client secret) into DISCORD_TOKEN"
+			return check
+		}
+		check.Detail = fmt.Sprintf("failed to reach Discord: %v", err)
+		check.Hint = "Check the network connection to discord.com and https://discordstatus.com"
+		return check
+	}
+	if !user.Bot {
+		check.Detail = fmt.Sprintf("the token belongs to %s, which is not a bot account", user.Username)
+		check.Hint = "Use the token of a bot user from the Bot page of the Discord Developer Portal"
+		return check
+	}
+	check.Passed = true
+	check.Detail = fmt.Sprintf("logged in as %s (%s)", user.Username, user.ID)
+	return check
+}
+
+// checkNewsAPI checks that the news API can be reached by fetching one news item, without retries.
+func checkNewsAPI(fetcher types.NewsFetcher) doctorCheck {
+	check := doctorCheck{
+		Name: "News API",
+		Hint: "Check the network connection to the news API, and --api-base-url (STO_API_BASE_URL) if set",
+	}
+	newsItems, err := fetcher.FetchNews("", 1, types.FetchOptions{ItemLimit: 1})
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+	if len(newsItems) == 0 {
+		check.Detail = "the news API returned no news"
+		return check
+	}
+	check.Passed = true
+	check.Detail = fmt.Sprintf("latest news: %d ('%s')", newsItems[0].ID, newsItems[0].Title)
+	check.Hint = ""
+	return check
+}
+
+// writeDoctorReport writes the checks as a PASS/FAIL table, with the hints of failed checks
+// below them, and returns the number of failed checks.
+func writeDoctorReport(w io.Writer, checks []doctorCheck) int {
+	failed := 0
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tRESULT\tDETAIL")
+	for _, check := range checks {
+		result := "PASS"
+		if !check.Passed {
+			result = "FAIL"
+			failed++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", check.Name, result, check.Detail)
+		if !check.Passed && check.Hint != "" {
+			fmt.Fprintf(tw, "\t\thint: %s\n", check.Hint)
+		}
+	}
+	tw.Flush()
+
+	if failed > 0 {
+		fmt.Fprintf(w, "\n%d of %d checks failed\n", failed, len(checks))
+	} else {
+		fmt.Fprintf(w, "\nAll %d checks passed\n", len(checks))
+	}
+	return failed
+}
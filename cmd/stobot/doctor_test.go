@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+func TestCheckConfig(t *testing.T) {
+	valid := types.Config{DiscordToken: "token", PollPeriod: 600, PollCount: 20, FreshSeconds: 600, MsgCount: 10, DatabasePath: "stobot.db"}
+
+	tests := []struct {
+		name     string
+		config   types.Config
+		parseErr error
+		passed   bool
+		detail   string
+	}{
+		{name: "valid", config: valid, passed: true, detail: "polling 20 news every 600s"},
+		{name: "missing token", config: types.Config{PollPeriod: 600}, detail: "discord token is required"},
+		{name: "bad flag", config: valid, parseErr: errors.New("invalid embed color"), detail: "invalid embed color"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := checkConfig(&tt.config, tt.parseErr)
+			if check.Passed != tt.passed || !strings.Contains(check.Detail, tt.detail) {
+				t.Errorf("Expected passed=%v with detail %q, got %+v", tt.passed, tt.detail, check)
+			}
+			if !check.Passed && check.Hint == "" {
+				t.Error("Expected a hint for a failed check")
+			}
+		})
+	}
+}
+
+func TestCheckDatabase(t *testing.T) {
+	options := database.DefaultInitOptions()
+	options.MigrationBackup = false
+
+	check := checkDatabase(filepath.Join(t.TempDir(), "stobot.db"), options)
+	if !check.Passed || !strings.Contains(check.Detail, "is writable") {
+		t.Errorf("Expected a writable database to pass, got %+v", check)
+	}
+
+	// A database below a regular file cannot be created
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	check = checkDatabase(filepath.Join(file, "stobot.db"), options)
+	if check.Passed || check.Hint == "" {
+		t.Errorf("Expected an unusable database path to fail with a hint, got %+v", check)
+	}
+
+	if check := checkDatabase("", options); check.Passed || !strings.Contains(check.Hint, "DATABASE_PATH") {
+		t.Errorf("Expected a missing database path to fail, got %+v", check)
+	}
+}
+
+func TestCheckDiscordToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   interface{}
+		passed bool
+		detail string
+	}{
+		{name: "bot", status: http.StatusOK, body: map[string]interface{}{"id": "bot-1", "username": "STOBot", "bot": true},
+			passed: true, detail: "logged in as STOBot (bot-1)"},
+		{name: "user account", status: http.StatusOK, body: map[string]interface{}{"id": "user-1", "username": "someone"},
+			detail: "not a bot account"},
+		{name: "rejected", status: http.StatusUnauthorized, body: map[string]interface{}{"message": "401: Unauthorized", "code": 0},
+			detail: "rejected the token"},
+		{name: "unavailable", status: http.StatusBadGateway, body: map[string]interface{}{"message": "Bad Gateway"},
+			detail: "failed to reach Discord"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := testhelpers.NewFakeDiscord(t)
+			fake.Handle("GET", "/users/@me", func(w http.ResponseWriter, r *http.Request) {
+				testhelpers.RespondJSON(w, tt.status, tt.body)
+			})
+			session := fake.Session()
+			session.MaxRestRetries = 0
+
+			check := checkDiscordToken(session)
+			if check.Passed != tt.passed || !strings.Contains(check.Detail, tt.detail) {
+				t.Errorf("Expected passed=%v with detail %q, got %+v", tt.passed, tt.detail, check)
+			}
+		})
+	}
+}
+
+func TestCheckNewsAPI(t *testing.T) {
+	fetcher := testhelpers.NewFakeNewsFetcher(types.NewsItem{ID: 7, Title: "Season Update"}, types.NewsItem{ID: 6, Title: "Older"})
+	check := checkNewsAPI(fetcher)
+	if !check.Passed || check.Detail != "latest news: 7 ('Season Update')" {
+		t.Errorf("Expected a reachable API to pass, got %+v", check)
+	}
+	if calls := fetcher.Calls(); len(calls) != 1 || calls[0].Count != 1 {
+		t.Errorf("Expected a single fetch of one news item, got %+v", calls)
+	}
+
+	if check := checkNewsAPI(testhelpers.NewFakeNewsFetcher()); check.Passed {
+		t.Errorf("Expected an API without news to fail, got %+v", check)
+	}
+
+	failing := testhelpers.NewFakeNewsFetcher()
+	failing.Err = errors.New("connection refused")
+	if check := checkNewsAPI(failing); check.Passed || !strings.Contains(check.Detail, "connection refused") {
+		t.Errorf("Expected an unreachable API to fail, got %+v", check)
+	}
+}
+
+func TestWriteDoctorReport(t *testing.T) {
+	var out bytes.Buffer
+	failed := writeDoctorReport(&out, []doctorCheck{
+		{Name: "Configuration", Passed: true, Detail: "polling 20 news every 600s"},
+		{Name: "Discord token", Detail: "Discord rejected the token", Hint: "Reset the token"},
+	})
+	if failed != 1 {
+		t.Errorf("Expected 1 failed check, got %d", failed)
+	}
+
+	report := out.String()
+	for _, expected := range []string{"Configuration  PASS", "Discord token  FAIL", "hint: Reset the token", "1 of 2 checks failed"} {
+		if !strings.Contains(report, expected) {
+			t.Errorf("Expected the report to contain %q, got:\n%s", expected, report)
+		}
+	}
+
+	out.Reset()
+	if failed := writeDoctorReport(&out, []doctorCheck{{Name: "Database", Passed: true}}); failed != 0 || !strings.Contains(out.String(), "All 1 checks passed") {
+		t.Errorf("Expected all checks to pass, got %d:\n%s", failed, out.String())
+	}
+}
@@ -4,14 +4,24 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"os"
 	"os/signal"
+	"slices"
 	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
+	"github.com/FracKenA/sto_news_discord_bot/internal/app"
 	"github.com/FracKenA/sto_news_discord_bot/internal/database"
 	"github.com/FracKenA/sto_news_discord_bot/internal/discord"
+	"github.com/FracKenA/sto_news_discord_bot/internal/logging"
 	"github.com/FracKenA/sto_news_discord_bot/internal/news"
+	"github.com/FracKenA/sto_news_discord_bot/internal/ratelimit"
 	"github.com/FracKenA/sto_news_discord_bot/internal/types"
 
 	"github.com/bwmarrin/discordgo"
@@ -21,25 +31,55 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// Build information, set via -ldflags at build time (see Makefile).
+var (
+	version   = "dev"
+	buildTime = "unknown"
+	gitCommit = "unknown"
+)
+
+// markAllPostedBatchSize is the number of cached news items mark-all-posted loads and marks at a time.
+const markAllPostedBatchSize = 500
+
+// populateBatchSize is the number of fetched news items populate-db caches and marks at a time.
+const populateBatchSize = 500
+
+// noChannelsGuidance explains what happens when populate-db runs before any channel is registered.
+const noChannelsGuidance = "No registered channels found: news will be cached but no posted markers will be written. " +
+	"Run import-channels first, or rely on channels auto-marking cached news as posted when they are registered. " +
+	"Use --require-channels to abort in this case."
+
 // populateDatabase populates the database with historical news to prevent re-posting old articles.
+// Progress is checkpointed per tag after each page, so an interrupted run resumes where it stopped.
 func populateDatabase(cmd *cobra.Command, args []string) {
 	// Get command line flags
 	dbPath, _ := cmd.Flags().GetString("database-path")
 	count, _ := cmd.Flags().GetInt("count")
 	tags, _ := cmd.Flags().GetStringSlice("tags")
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	force, _ := cmd.Flags().GetBool("force")
+	rateLimit, _ := cmd.Flags().GetInt("rate-limit")
+	requireChannels, _ := cmd.Flags().GetBool("require-channels")
+	baseURL, _ := cmd.Flags().GetString("api-base-url")
 
 	// Initialize logger
-	log.SetFormatter(&log.JSONFormatter{})
-	log.SetLevel(log.DebugLevel)
+	configureLogging(cmd, log.DebugLevel)
+
+	if rateLimit < 0 {
+		log.Fatal("Rate limit must not be negative")
+	}
+	aliases, err := tagAliases(cmd)
+	if err != nil {
+		log.Fatalf("Configuration validation failed: %v", err)
+	}
 
 	log.Infof("Populating database with historical news (dry-run: %v)", dryRun)
-	log.Infof("Database path: %s", dbPath)
+	log.Infof("Database path: %s", database.DisplayPath(dbPath))
 	log.Infof("Count per tag: %d", count)
 	log.Infof("Tags: %v", tags)
 
 	// Initialize database
-	db, err := database.InitDatabase(dbPath)
+	db, err := openDatabase(cmd, dbPath)
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
@@ -49,57 +89,270 @@ func populateDatabase(cmd *cobra.Command, args []string) {
 	bot := &types.Bot{
 		DB: db,
 		Config: &types.Config{
-			PollCount: count,
+			PollCount:  count,
+			BaseURL:    baseURL,
+			TagAliases: aliases,
 		},
 	}
 
-	totalProcessed := 0
-	totalCached := 0
+	// Get all registered channels to mark news as posted, before fetching anything
+	channels, err := database.GetRegisteredChannels(bot)
+	if err != nil {
+		log.Fatalf("Failed to get registered channels: %v", err)
+	}
+	if err := checkPopulateChannels(channels, requireChannels); err != nil {
+		log.Fatalf("Aborting populate-db: %v", err)
+	}
+
+	options := populateOptions{DryRun: dryRun, Force: force}
+	if rateLimit > 0 {
+		options.Limiter = ratelimit.New(float64(rateLimit)/60, 1)
+	}
+
+	// Stop between pages on interrupt; the next run resumes from the last checkpoint
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	summary := populateTags(ctx, bot, tags, count, channels, options)
+
+	if ctx.Err() != nil {
+		log.Warn("Interrupted: run populate-db again to resume")
+	}
+	if dryRun {
+		log.Infof("DRY RUN COMPLETE: Would have processed %d total news items and written up to %d posted markers (%d news × %d channels)",
+			summary.Processed, summary.Processed*len(channels), summary.Processed, len(channels))
+	} else {
+		log.Infof("POPULATE COMPLETE: Processed %d total news items, cached %d items, wrote %d posted markers across %d channels (%d tags already populated)",
+			summary.Processed, summary.Cached, summary.Markers, len(channels), summary.Skipped)
+	}
+}
+
+// populatePageSize is the number of news items populate-db fetches per request, and checkpoints after.
+const populatePageSize = 100
+
+// populateOptions control a populate-db run.
+type populateOptions struct {
+	DryRun  bool               // DryRun fetches news without caching it, marking it or saving progress.
+	Force   bool               // Force populates tags from the start, even ones already populated.
+	Limiter *ratelimit.Limiter // Limiter paces news API requests; nil does not limit them.
+}
+
+// populateSummary counts what populate-db did.
+type populateSummary struct {
+	Processed int // Processed is the number of news items fetched.
+	Cached    int // Cached is the number of news items cached.
+	Markers   int // Markers is the number of new posted markers written.
+	Skipped   int // Skipped is the number of tags not fetched because they were already populated.
+}
+
+// add adds the counts of other to s.
+func (s *populateSummary) add(other populateSummary) {
+	s.Processed += other.Processed
+	s.Cached += other.Cached
+	s.Markers += other.Markers
+	s.Skipped += other.Skipped
+}
 
+// populateTags populates count news items per tag with populateTag, logging progress with the
+// rate and expected time left. Tags that fail are logged and skipped; cancelling ctx stops
+// before the next page.
+func populateTags(ctx context.Context, bot *types.Bot, tags []string, count int, channels []string, options populateOptions) populateSummary {
+	var summary populateSummary
+	progress := newPopulateProgress(len(tags) * count)
 	for _, tag := range tags {
+		if ctx.Err() != nil {
+			break
+		}
 		log.Infof("Processing tag: %s", tag)
 
-		// Fetch news for this tag with pagination support
-		newsItems, err := news.FetchNews(bot, tag, count, news.BulkFetchOptions())
-		if err != nil {
-			log.Errorf("Failed to fetch news for tag %s: %v", tag, err)
-			continue
+		tagSummary, err := populateTag(ctx, bot, tag, count, channels, options, progress)
+		summary.add(tagSummary)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.Errorf("Failed to populate news for tag %s: %v", tag, err)
 		}
+	}
+	return summary
+}
 
-		log.Infof("Fetched %d news items for tag: %s", len(newsItems), tag)
+// populateTag fetches count news items with tag from the bot's news fetcher, a page at a time
+// and, unless options.DryRun is set, caches them, marks them as posted to channels and
+// checkpoints the tag's progress after each page. A tag already populated is skipped and a
+// partly populated one resumes after its last checkpoint, unless options.Force is set. progress
+// is advanced by the items fetched and by the items skipped.
+func populateTag(ctx context.Context, bot *types.Bot, tag string, count int, channels []string, options populateOptions, progress *populateProgress) (populateSummary, error) {
+	var summary populateSummary
+
+	state := database.PopulateState{Tag: tag}
+	if options.Force {
+		if !options.DryRun {
+			if err := database.ResetPopulateState(bot, tag); err != nil {
+				return summary, err
+			}
+		}
+	} else {
+		var err error
+		if state, err = database.GetPopulateState(bot, tag); err != nil {
+			return summary, err
+		}
+	}
+	if state.Completed {
+		log.Infof("Tag %s was already populated (%d news items), skipping; use --force to populate it again", tag, state.LastOffset)
+		progress.skip(count)
+		summary.Skipped++
+		return summary, nil
+	}
+	if state.LastOffset > 0 {
+		log.Infof("Resuming tag %s after %d news items", tag, state.LastOffset)
+		progress.skip(min(state.LastOffset, count))
+	}
 
-		if !dryRun {
-			// Cache all news items using bulk options
-			if err := news.CacheNewsWithOptions(bot, newsItems, news.BulkDatabaseOptions()); err != nil {
-				log.Errorf("Failed to cache news items for tag %s: %v", tag, err)
-				continue
+	for offset := state.LastOffset; offset < count; {
+		if options.Limiter != nil {
+			if err := options.Limiter.Wait(ctx); err != nil {
+				return summary, err
 			}
+		} else if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+
+		limit := min(populatePageSize, count-offset)
+		fetchOptions := news.BulkFetchOptions()
+		fetchOptions.PageSize = populatePageSize
+		fetchOptions.Offset = offset
+		newsItems, err := news.FetchNews(bot, tag, limit, fetchOptions)
+		if err != nil {
+			return summary, fmt.Errorf("failed to fetch news at offset %d: %v", offset, err)
+		}
+		offset += limit
+		// An empty page means the API has no older news with this tag
+		done := offset >= count || len(newsItems) == 0
 
-			// Get all registered channels to mark news as posted
-			channels, err := database.GetRegisteredChannels(bot)
+		if options.DryRun {
+			log.Infof("DRY RUN: Would cache %d news items for tag %s", len(newsItems), tag)
+		} else {
+			cached, markers, err := populateNewsItems(bot, newsItems, channels)
+			summary.Cached += cached
+			summary.Markers += markers
 			if err != nil {
-				log.Warnf("No registered channels found, skipping posted_news population: %v", err)
-			} else {
-				// Mark all news as posted to all registered channels using bulk options
-				if err := news.MarkMultipleNewsAsPosted(bot, newsItems, channels, news.BulkDatabaseOptions()); err != nil {
-					log.Errorf("Failed to mark news items as posted: %v", err)
-				} else {
-					log.Infof("Marked %d news items as posted to %d channels", len(newsItems), len(channels))
-				}
+				return summary, err
+			}
+			if err := database.SavePopulateState(bot, database.PopulateState{Tag: tag, LastOffset: offset, Completed: done}); err != nil {
+				return summary, err
 			}
-			totalCached += len(newsItems)
-		} else {
-			log.Infof("DRY RUN: Would cache %d news items for tag %s", len(newsItems), tag)
 		}
+		summary.Processed += len(newsItems)
 
-		totalProcessed += len(newsItems)
+		progress.advance(limit, len(newsItems))
+		if done {
+			progress.skip(count - offset)
+		}
+		log.Info(progress)
+		if done {
+			break
+		}
 	}
+	log.Infof("Populated tag %s: %d news items", tag, summary.Processed)
+	return summary, nil
+}
 
-	if dryRun {
-		log.Infof("DRY RUN COMPLETE: Would have processed %d total news items", totalProcessed)
-	} else {
-		log.Infof("POPULATE COMPLETE: Processed %d total news items, cached %d items", totalProcessed, totalCached)
+// populateProgress tracks how far populate-db got through the news items of all tags, to report
+// the fetch rate and the expected time left.
+type populateProgress struct {
+	total    int              // total is the number of news items to populate, over all tags.
+	skipped  int              // skipped are the items populated by an earlier run, or missing from the API.
+	advanced int              // advanced are the items this run got through.
+	fetched  int              // fetched are the news items this run fetched.
+	start    time.Time        // start is when this run started.
+	now      func() time.Time // now is the clock the rate is measured by (replaced in tests).
+}
+
+// newPopulateProgress returns the progress of a run populating total news items, starting now.
+func newPopulateProgress(total int) *populateProgress {
+	return &populateProgress{total: total, start: time.Now(), now: time.Now}
+}
+
+// skip counts n items as done without fetching them.
+func (p *populateProgress) skip(n int) {
+	p.skipped += max(n, 0)
+}
+
+// advance counts n items as done, of which fetched were fetched.
+func (p *populateProgress) advance(n, fetched int) {
+	p.advanced += n
+	p.fetched += fetched
+}
+
+// String returns a progress line with the items done, the fetch rate and the expected time left.
+func (p *populateProgress) String() string {
+	done := min(p.skipped+p.advanced, p.total)
+	percent := 100.0
+	if p.total > 0 {
+		percent = float64(done) * 100 / float64(p.total)
+	}
+	elapsed := p.now().Sub(p.start)
+
+	rate, eta := "0.0 items/sec", "unknown"
+	if elapsed > 0 && p.advanced > 0 {
+		rate = fmt.Sprintf("%.1f items/sec", float64(p.fetched)/elapsed.Seconds())
+		left := time.Duration(float64(elapsed) * float64(p.total-done) / float64(p.advanced))
+		eta = left.Round(time.Second).String()
+	}
+	return fmt.Sprintf("Progress: %d/%d news items (%.0f%%), %s, ETA %s", done, p.total, percent, rate, eta)
+}
+
+// checkPopulateChannels warns when populate-db has no channels to mark news as posted for,
+// or returns an error in that case if requireChannels is set.
+func checkPopulateChannels(channels []string, requireChannels bool) error {
+	if len(channels) > 0 {
+		return nil
+	}
+	if requireChannels {
+		return fmt.Errorf("no registered channels found and --require-channels is set; run import-channels first")
+	}
+	log.Warn(noChannelsGuidance)
+	return nil
+}
+
+// populateNewsItems caches news items and marks them as posted to the given channels in batches
+// of populateBatchSize, logging progress. It returns the number of items cached and the number of
+// new (news × channel) posted markers written; markers that already existed are not counted.
+func populateNewsItems(bot *types.Bot, newsItems []types.NewsItem, channels []string) (int, int, error) {
+	postedBefore, err := database.CountPostedNews(bot)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	cached := 0
+	markersWritten := func() int {
+		postedAfter, err := database.CountPostedNews(bot)
+		if err != nil {
+			log.Warnf("Failed to count posted markers: %v", err)
+			return 0
+		}
+		return postedAfter - postedBefore
 	}
+
+	for start := 0; start < len(newsItems); start += populateBatchSize {
+		end := start + populateBatchSize
+		if end > len(newsItems) {
+			end = len(newsItems)
+		}
+		batch := newsItems[start:end]
+
+		if err := news.CacheNewsWithOptions(bot, batch, news.BulkDatabaseOptions()); err != nil {
+			return cached, markersWritten(), fmt.Errorf("failed to cache news items: %v", err)
+		}
+		cached += len(batch)
+
+		if len(channels) > 0 {
+			if err := news.MarkMultipleNewsAsPosted(bot, batch, channels, news.BulkDatabaseOptions()); err != nil {
+				return cached, markersWritten(), fmt.Errorf("failed to mark news items as posted: %v", err)
+			}
+		}
+
+		log.Infof("Populated %d/%d news items (%d channels)", end, len(newsItems), len(channels))
+	}
+	return cached, markersWritten(), nil
 }
 
 // importChannels imports channel configuration from a channels.txt file into the database.
@@ -109,13 +362,12 @@ func importChannels(cmd *cobra.Command, args []string) {
 	channelsFile, _ := cmd.Flags().GetString("channels-file")
 
 	// Initialize logger
-	log.SetFormatter(&log.JSONFormatter{})
-	log.SetLevel(log.InfoLevel)
+	configureLogging(cmd, log.InfoLevel)
 
-	log.Infof("Importing channels from %s to database %s", channelsFile, dbPath)
+	log.Infof("Importing channels from %s to database %s", channelsFile, database.DisplayPath(dbPath))
 
 	// Initialize database
-	db, err := database.InitDatabase(dbPath)
+	db, err := openDatabase(cmd, dbPath)
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
@@ -135,19 +387,175 @@ func importChannels(cmd *cobra.Command, args []string) {
 	log.Info("Channel import completed successfully")
 }
 
+// exportChannels writes the registered channels in the channels.txt format read by import-channels.
+func exportChannels(cmd *cobra.Command, args []string) {
+	// Get command line flags
+	dbPath, _ := cmd.Flags().GetString("database-path")
+	output, _ := cmd.Flags().GetString("output")
+	environment, _ := cmd.Flags().GetString("environment")
+
+	// Initialize logger
+	configureLogging(cmd, log.InfoLevel)
+
+	environment = strings.ToUpper(environment)
+	if environment != "" && environment != "DEV" && environment != "PROD" {
+		log.Fatalf("Invalid environment %q: must be DEV or PROD", environment)
+	}
+
+	// Initialize database
+	db, err := openDatabase(cmd, dbPath)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	// Create bot instance
+	bot := &types.Bot{
+		DB: db,
+	}
+
+	count, err := writeChannelsExport(bot, output, environment)
+	if err != nil {
+		log.Fatalf("Failed to export channels: %v", err)
+	}
+
+	log.Infof("Exported %d channels", count)
+}
+
+// writeChannelsExport exports the channels to the output file, or to stdout when output is "-".
+func writeChannelsExport(bot *types.Bot, output, environment string) (int, error) {
+	if output == "-" {
+		return database.ExportChannels(bot, os.Stdout, environment)
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create output file: %v", err)
+	}
+
+	count, err := database.ExportChannels(bot, file, environment)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to close output file: %v", closeErr)
+	}
+	return count, err
+}
+
+// exportNews writes the cached news to a file or stdout.
+func exportNews(cmd *cobra.Command, args []string) {
+	// Get command line flags
+	dbPath, _ := cmd.Flags().GetString("database-path")
+	output, _ := cmd.Flags().GetString("output")
+	format, _ := cmd.Flags().GetString("format")
+	tag, _ := cmd.Flags().GetString("tag")
+	platform, _ := cmd.Flags().GetString("platform")
+	since, _ := cmd.Flags().GetString("since")
+	until, _ := cmd.Flags().GetString("until")
+
+	// Initialize logger
+	configureLogging(cmd, log.InfoLevel)
+
+	filter, err := newsExportFilter(tag, platform, since, until)
+	if err != nil {
+		log.Fatalf("Invalid filter: %v", err)
+	}
+	format = strings.ToLower(format)
+	if format != database.NewsExportJSON && format != database.NewsExportCSV {
+		log.Fatalf("Invalid format %q: must be json or csv", format)
+	}
+
+	// Initialize database
+	db, err := openDatabase(cmd, dbPath)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	// Create bot instance
+	bot := &types.Bot{
+		DB: db,
+	}
+
+	count, err := writeNewsExport(bot, output, format, filter)
+	if err != nil {
+		log.Fatalf("Failed to export news: %v", err)
+	}
+
+	log.Infof("Exported %d news items", count)
+}
+
+// newsExportFilter builds the export-news filter. since and until are dates (YYYY-MM-DD) or
+// RFC 3339 times; an until date includes the whole day.
+func newsExportFilter(tag, platform, since, until string) (database.NewsExportFilter, error) {
+	filter := database.NewsExportFilter{Tag: strings.TrimSpace(tag), Platform: strings.TrimSpace(platform)}
+
+	if since != "" {
+		t, _, err := parseExportTime(since)
+		if err != nil {
+			return filter, fmt.Errorf("--since: %v", err)
+		}
+		filter.Since = t
+	}
+	if until != "" {
+		t, dateOnly, err := parseExportTime(until)
+		if err != nil {
+			return filter, fmt.Errorf("--until: %v", err)
+		}
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		filter.Until = t
+	}
+
+	if !filter.Since.IsZero() && !filter.Until.IsZero() && !filter.Until.After(filter.Since) {
+		return filter, fmt.Errorf("--until must be after --since")
+	}
+	return filter, nil
+}
+
+// parseExportTime parses a date (YYYY-MM-DD, as the start of that day in UTC) or an RFC 3339 time,
+// and reports whether value was a date.
+func parseExportTime(value string) (time.Time, bool, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%q is not a date (YYYY-MM-DD) or RFC 3339 time", value)
+	}
+	return t, false, nil
+}
+
+// writeNewsExport exports the news to the output file, or to stdout when output is "-".
+func writeNewsExport(bot *types.Bot, output, format string, filter database.NewsExportFilter) (int, error) {
+	if output == "-" {
+		return database.ExportNews(bot, os.Stdout, format, filter)
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create output file: %v", err)
+	}
+
+	count, err := database.ExportNews(bot, file, format, filter)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to close output file: %v", closeErr)
+	}
+	return count, err
+}
+
 // listChannels lists registered channels in the database.
 func listChannels(cmd *cobra.Command, args []string) {
 	// Get command line flags
 	dbPath, _ := cmd.Flags().GetString("database-path")
 
 	// Initialize logger
-	log.SetFormatter(&log.JSONFormatter{})
-	log.SetLevel(log.InfoLevel)
+	configureLogging(cmd, log.InfoLevel)
 
-	log.Infof("Listing channels from database %s", dbPath)
+	log.Infof("Listing channels from database %s", database.DisplayPath(dbPath))
 
 	// Initialize database
-	db, err := database.InitDatabase(dbPath)
+	db, err := openDatabase(cmd, dbPath)
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
@@ -171,20 +579,226 @@ func listChannels(cmd *cobra.Command, args []string) {
 
 	log.Infof("Found %d registered channels:", len(channels))
 	for _, channelID := range channels {
-		platforms, err := database.GetChannelPlatforms(bot, channelID)
-		if err != nil {
-			log.Errorf("Failed to get platforms for channel %s: %v", channelID, err)
+		cfg, err := database.GetChannelConfig(bot, channelID)
+		if err != nil || cfg == nil {
+			log.Errorf("Failed to get config for channel %s: %v", channelID, err)
 			continue
 		}
 
-		environment, err := database.GetChannelEnvironment(bot, channelID)
+		log.Infof("  Channel %s: platforms %v, environment %s, %s", channelID, cfg.Platforms, cfg.Environment, channelState(*cfg))
+	}
+}
+
+// channelState describes whether news is posted to a channel, for list-channels.
+func channelState(cfg database.ChannelConfig) string {
+	switch {
+	case cfg.Disabled:
+		return "disabled"
+	case cfg.Paused && cfg.PauseHold:
+		return "paused, holding news"
+	case cfg.Paused:
+		return "paused, skipping news"
+	case cfg.PostFailures > 0:
+		return fmt.Sprintf("enabled, %d failed posts", cfg.PostFailures)
+	default:
+		return "enabled"
+	}
+}
+
+// setChannelsDisabled returns the run function of channels enable (disabled false) and
+// channels disable (disabled true), which update the channels given as arguments.
+func setChannelsDisabled(disabled bool) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		dbPath, _ := cmd.Flags().GetString("database-path")
+
+		// Initialize logger
+		configureLogging(cmd, log.InfoLevel)
+
+		db, err := openDatabase(cmd, dbPath)
 		if err != nil {
-			log.Errorf("Failed to get environment for channel %s: %v", channelID, err)
+			log.Fatalf("Failed to initialize database: %v", err)
+		}
+		defer db.Close()
+		bot := &types.Bot{DB: db}
+
+		action := "Enabled"
+		if disabled {
+			action = "Disabled"
+		}
+		failed := false
+		for _, channelID := range args {
+			if err := database.UpdateChannelDisabled(bot, channelID, disabled); err != nil {
+				log.Errorf("Failed to update channel %s: %v", channelID, err)
+				failed = true
+				continue
+			}
+			log.Infof("%s channel %s", action, channelID)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	}
+}
+
+// setPlatforms sets the platforms of many channels at once and reports the outcome per channel.
+func setPlatforms(cmd *cobra.Command, args []string) {
+	dbPath, _ := cmd.Flags().GetString("database-path")
+	channels, _ := cmd.Flags().GetString("channels")
+	platformsSpec, _ := cmd.Flags().GetString("platforms")
+	environment, _ := cmd.Flags().GetString("environment")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	ignoreErrors, _ := cmd.Flags().GetBool("ignore-errors")
+
+	// Initialize logger
+	configureLogging(cmd, log.InfoLevel)
+
+	platforms, err := types.ParsePlatforms(platformsSpec)
+	if err != nil {
+		log.Fatalf("Invalid platforms: %v", err)
+	}
+	environment = strings.ToUpper(environment)
+	if environment != "" && environment != "DEV" && environment != "PROD" {
+		log.Fatalf("Invalid environment %q: must be DEV or PROD", environment)
+	}
+	channelIDs, err := parseChannelIDs(channels)
+	if err != nil {
+		log.Fatalf("Invalid channels: %v", err)
+	}
+
+	db, err := openDatabase(cmd, dbPath)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+	bot := &types.Bot{DB: db}
+
+	results, err := database.BulkUpdateChannelPlatforms(bot, platforms, database.BulkPlatformsOptions{
+		ChannelIDs:   channelIDs,
+		Environment:  environment,
+		DryRun:       dryRun,
+		IgnoreErrors: ignoreErrors,
+	})
+	for _, result := range results {
+		if result.Err != nil {
+			log.Errorf("  Channel %s: %v", result.ChannelID, result.Err)
 			continue
 		}
+		log.Infof("  Channel %s: %s", result.ChannelID, describePlatformsChange(result, platforms))
+	}
+	if err != nil {
+		log.Fatalf("Failed to set platforms: %v", err)
+	}
+
+	summary := summarizePlatformsResults(results)
+	if dryRun {
+		log.Infof("Dry run: would set the platforms of %d channels to %s (%s); nothing was changed", len(results), strings.Join(platforms, ","), summary)
+		return
+	}
+	log.Infof("Set the platforms of %d channels to %s (%s)", len(results), strings.Join(platforms, ","), summary)
+	for _, result := range results {
+		if result.Err != nil {
+			os.Exit(1)
+		}
+	}
+}
+
+// parseChannelIDs parses the --channels value of set-platforms: comma-separated channel IDs, or
+// "all" for every registered channel, returned as nil.
+func parseChannelIDs(spec string) ([]string, error) {
+	if strings.EqualFold(strings.TrimSpace(spec), "all") {
+		return nil, nil
+	}
+	var channelIDs []string
+	for _, channelID := range strings.Split(spec, ",") {
+		channelID = strings.TrimSpace(channelID)
+		if channelID != "" && !slices.Contains(channelIDs, channelID) {
+			channelIDs = append(channelIDs, channelID)
+		}
+	}
+	if len(channelIDs) == 0 {
+		return nil, errors.New(`no channels given: list channel IDs or use "all"`)
+	}
+	return channelIDs, nil
+}
+
+// describePlatformsChange describes the platform change of a channel updated by set-platforms.
+func describePlatformsChange(result database.ChannelPlatformsResult, platforms []string) string {
+	if !result.Changed {
+		return fmt.Sprintf("%s (unchanged)", strings.Join(platforms, ","))
+	}
+	return fmt.Sprintf("%s -> %s", strings.Join(result.Previous, ","), strings.Join(platforms, ","))
+}
+
+// summarizePlatformsResults counts the changed, unchanged and failed channels of set-platforms.
+func summarizePlatformsResults(results []database.ChannelPlatformsResult) string {
+	changed, unchanged, failed := 0, 0, 0
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			failed++
+		case result.Changed:
+			changed++
+		default:
+			unchanged++
+		}
+	}
+	return fmt.Sprintf("%d changed, %d unchanged, %d failed", changed, unchanged, failed)
+}
+
+// migrateChannel moves a channel's registration, settings and posting history to another channel.
+func migrateChannel(cmd *cobra.Command, args []string) {
+	dbPath, _ := cmd.Flags().GetString("database-path")
+	token, _ := cmd.Flags().GetString("token")
+
+	// Initialize logger
+	configureLogging(cmd, log.InfoLevel)
+
+	db, err := openDatabase(cmd, dbPath)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+	bot := &types.Bot{DB: db}
+
+	if token != "" {
+		dg, err := discordgo.New("Bot " + token)
+		if err != nil {
+			log.Fatalf("Failed to create Discord session: %v", err)
+		}
+		bot.Session = dg
+	} else {
+		log.Warn("No Discord token given, the new channel is not checked to exist")
+	}
+
+	moved, err := moveChannel(bot, args[0], args[1])
+	if err != nil {
+		log.Fatalf("Failed to migrate channel %s to %s: %v", args[0], args[1], err)
+	}
+	log.Infof("Migrated channel %s to %s with %d posted news items", args[0], args[1], moved)
+}
+
+// moveChannel moves the registration of channel oldID to newID. With a Discord session, newID
+// must be an existing channel, and its server is recorded.
+func moveChannel(bot *types.Bot, oldID, newID string) (int64, error) {
+	guildID := ""
+	if bot.Session != nil {
+		channel, err := bot.Session.Channel(newID)
+		if err != nil {
+			return 0, fmt.Errorf("channel %s could not be found: %v", newID, err)
+		}
+		guildID = channel.GuildID
+	}
 
-		log.Infof("  Channel %s: platforms %v, environment %s", channelID, platforms, environment)
+	moved, err := database.MigrateChannel(bot, oldID, newID)
+	if err != nil {
+		return 0, err
+	}
+	if guildID != "" {
+		if err := database.UpdateChannelGuild(bot, newID, guildID); err != nil {
+			return moved, err
+		}
 	}
+	return moved, nil
 }
 
 // markAllPosted marks all cached news as already posted to prevent re-sending old messages.
@@ -194,14 +808,13 @@ func markAllPosted(cmd *cobra.Command, args []string) {
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
 
 	// Initialize logger
-	log.SetFormatter(&log.JSONFormatter{})
-	log.SetLevel(log.InfoLevel)
+	configureLogging(cmd, log.InfoLevel)
 
 	log.Infof("Marking all cached news as posted (dry-run: %v)", dryRun)
-	log.Infof("Database path: %s", dbPath)
+	log.Infof("Database path: %s", database.DisplayPath(dbPath))
 
 	// Initialize database
-	db, err := database.InitDatabase(dbPath)
+	db, err := openDatabase(cmd, dbPath)
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
@@ -225,31 +838,40 @@ func markAllPosted(cmd *cobra.Command, args []string) {
 
 	log.Infof("Found %d registered channels", len(channels))
 
-	// Get all cached news items
-	newsItems, err := database.GetAllCachedNews(bot)
+	// Count cached news items; they are processed in batches below
+	newsCount, err := database.CountCachedNews(bot)
 	if err != nil {
-		log.Fatalf("Failed to get cached news: %v", err)
+		log.Fatalf("Failed to count cached news: %v", err)
 	}
 
-	if len(newsItems) == 0 {
+	if newsCount == 0 {
 		log.Info("No cached news items found")
 		return
 	}
 
-	log.Infof("Found %d cached news items", len(newsItems))
+	log.Infof("Found %d cached news items", newsCount)
 
 	if dryRun {
 		log.Infof("DRY RUN: Would mark %d news items as posted to %d channels (%d total operations)",
-			len(newsItems), len(channels), len(newsItems)*len(channels))
+			newsCount, len(channels), newsCount*len(channels))
 		return
 	}
 
-	// Mark all news as posted to all channels
-	if err := news.MarkMultipleNewsAsPosted(bot, newsItems, channels, news.BulkDatabaseOptions()); err != nil {
+	// Mark all news as posted to all channels, one batch of cached news at a time
+	processed := 0
+	err = database.ForEachCachedNews(bot, markAllPostedBatchSize, func(batch []types.NewsItem) error {
+		if err := news.MarkMultipleNewsAsPosted(bot, batch, channels, news.BulkDatabaseOptions()); err != nil {
+			return err
+		}
+		processed += len(batch)
+		log.Infof("Marked %d/%d news items as posted", processed, newsCount)
+		return nil
+	})
+	if err != nil {
 		log.Fatalf("Failed to mark news items as posted: %v", err)
 	}
 
-	log.Infof("Successfully marked %d news items as posted to %d channels", len(newsItems), len(channels))
+	log.Infof("Successfully marked %d news items as posted to %d channels", processed, len(channels))
 }
 
 // main is the entry point for the STOBot application.
@@ -266,13 +888,42 @@ func main() {
 	}
 
 	var config types.Config
+	rootCmd.PersistentFlags().String("log-level", getEnvString("LOG_LEVEL", ""), "Log level, optionally followed by per-component levels, e.g. warn,news=debug (components: news, database, discord)")
+	rootCmd.PersistentFlags().String("log-format", getEnvString("LOG_FORMAT", logging.FormatJSON), "Log format: json, or text for local development")
 	rootCmd.Flags().StringVar(&config.DiscordToken, "token", os.Getenv("DISCORD_TOKEN"), "Discord bot token")
 	rootCmd.Flags().IntVar(&config.PollPeriod, "poll-period", getEnvInt("POLL_PERIOD", 600), "Time in seconds between checking for news")
 	rootCmd.Flags().IntVar(&config.PollCount, "poll-count", getEnvInt("POLL_COUNT", 20), "Number of news to poll in each period")
+	rootCmd.Flags().String("poll-tags", getEnvString("POLL_TAGS", strings.Join(news.DefaultPollTags, ",")), "Comma-separated news tags fetched in each period and merged (empty fetches the API's default feed)")
 	rootCmd.Flags().IntVar(&config.FreshSeconds, "fresh-seconds", getEnvInt("FRESH_SECONDS", 600), "Maximum age of news items to post")
 	rootCmd.Flags().IntVar(&config.MsgCount, "msg-count", getEnvInt("MSG_COUNT", 10), "Number of Discord messages to check for duplicates")
+	rootCmd.Flags().BoolVar(&config.SkipDuplicateCheck, "skip-duplicate-check", getEnvBool("SKIP_DUPLICATE_CHECK", false), "Do not check recent channel messages before posting (for bots without Read Message History)")
+	rootCmd.Flags().BoolVar(&config.DryRun, "dry-run", getEnvBool("DRY_RUN", false), "Log the news posts instead of sending them, without connecting to Discord; logged posts are recorded apart from real ones so they are logged once")
+	rootCmd.Flags().BoolVar(&config.DisableUsageStats, "disable-usage-stats", getEnvBool("DISABLE_USAGE_STATS", false), "Do not record slash command usage for /stobot_engagement_report")
+	rootCmd.Flags().IntVar(&config.PostConcurrency, "post-concurrency", getEnvInt("POST_CONCURRENCY", news.DefaultPostConcurrency), "Number of channels posted to at once; posts are paced to 5 per second overall")
+	rootCmd.Flags().IntVar(&config.CatchUpDays, "catchup-days", getEnvInt("CATCHUP_DAYS", news.DefaultCatchUpDays), "Days of unposted news to post at startup (0 disables the catch-up)")
+	rootCmd.Flags().IntVar(&config.CacheRetentionDays, "cache-retention-days", getEnvInt("CACHE_RETENTION_DAYS", database.DefaultCacheRetentionDays), "Days unposted news is kept in the cache (0 keeps it forever)")
+	rootCmd.Flags().IntVar(&config.DisableAfterFailures, "disable-after-failures", getEnvInt("DISABLE_AFTER_FAILURES", news.DefaultDisableAfterFailures), "Consecutive posts failing because a channel was deleted or the bot lost access before the channel is disabled (0 never disables)")
+	rootCmd.Flags().IntVar(&config.FutureSkewSeconds, "future-skew-seconds", getEnvInt("FUTURE_SKEW_SECONDS", news.DefaultFutureSkewSeconds), "Seconds in the future news may be dated and still be posted; news dated later is held back until it is due")
+	rootCmd.Flags().IntVar(&config.MaxPostsPerCycle, "max-posts-per-cycle", getEnvInt("MAX_POSTS_PER_CYCLE", news.DefaultMaxPostsPerCycle), "News items posted to a channel per poll cycle or catch-up, unless the channel sets its own; the rest is posted by later cycles, oldest first (0 disables the limit)")
+	rootCmd.Flags().IntVar(&config.UpdateNoticeSeconds, "update-notice-seconds", getEnvInt("UPDATE_NOTICE_SECONDS", news.DefaultUpdateNoticeSeconds), "Seconds an article must be dated after its cached version before channels it was posted to are told it was updated")
+	rootCmd.Flags().IntVar(&config.GalleryMaxImages, "gallery-max-images", getEnvInt("GALLERY_MAX_IMAGES", news.DefaultGalleryMaxImages), "Extra images, such as screenshots, posted as a gallery with news in channels showing galleries (at most 9)")
+	rootCmd.Flags().IntVar(&config.RegisterBackfillCount, "register-backfill-count", getEnvInt("REGISTER_BACKFILL_COUNT", news.DefaultRegisterBackfillCount), "Newest cached news items posted to a channel registered with backfill recent (at most 50)")
+	rootCmd.Flags().IntVar(&config.ThreadArchiveMinutes, "thread-archive-minutes", getEnvInt("THREAD_ARCHIVE_MINUTES", news.DefaultThreadArchiveMinutes), "Minutes of inactivity before news discussion threads are archived: 60, 1440, 4320 or 10080")
+	rootCmd.Flags().IntVar(&config.SearchCooldownUses, "search-cooldown-uses", getEnvInt("SEARCH_COOLDOWN_USES", discord.DefaultSearchCooldownUses), "Searches each user may run per --search-cooldown-seconds; administrators are not limited (0 disables the cooldown)")
+	rootCmd.Flags().IntVar(&config.SearchCooldownSeconds, "search-cooldown-seconds", getEnvInt("SEARCH_COOLDOWN_SECONDS", discord.DefaultSearchCooldownSeconds), "Window in seconds of the per-user search cooldown")
+	rootCmd.Flags().IntVar(&config.DuplicateWindowDays, "duplicate-window-days", getEnvInt("DUPLICATE_WINDOW_DAYS", database.DefaultDuplicateWindowDays), "Days a posted article keeps copies republished under a new ID from being posted to the same channel (0 disables the check)")
 	rootCmd.Flags().StringVar(&config.ChannelsPath, "channels-path", getEnvString("CHANNELS_PATH", "/data/channels.txt"), "Path to channels file")
-	rootCmd.Flags().StringVar(&config.DatabasePath, "database-path", getEnvString("DATABASE_PATH", "./data/stobot.db"), "Path to SQLite database")
+	rootCmd.Flags().StringVar(&config.DatabasePath, "database-path", defaultDatabasePath(), "Path to SQLite database, or a postgres:// URL")
+	rootCmd.Flags().StringVar(&config.Environment, "environment", getEnvEnvironment(), "Bot environment (DEV or PROD); only channels registered in this environment are served")
+	rootCmd.Flags().String("embed-colors", getEnvString("EMBED_COLORS", ""), "Embed color per news tag as tag=color pairs or a JSON object, e.g. patch-notes=#ff8800,events=#9b59b6 (\"default\" sets the fallback)")
+	rootCmd.Flags().String("tag-aliases", getEnvString("TAG_ALIASES", ""), "Tag aliases as alias=tag pairs or a JSON object, e.g. maintenance=server-maintenance, added to the built-in ones")
+	rootCmd.Flags().StringArray("url-rewrite", getEnvURLRewrites(), "URL rewrite rule old-prefix=>new-prefix applied to article links and thumbnails (repeatable)")
+	rootCmd.Flags().StringVar(&config.BaseURL, "api-base-url", getEnvString("STO_API_BASE_URL", ""), "News API endpoint override, e.g. for a proxy or mock server (default: Arc Games API)")
+	rootCmd.Flags().StringVar(&config.GameStatusURL, "game-status-url", getEnvString("GAME_STATUS_URL", ""), "STO server status endpoint override for /stobot_game_status (default: launcher status endpoint)")
+	rootCmd.Flags().StringVar(&config.DefaultThumbnailURL, "default-thumbnail-url", getEnvString("DEFAULT_THUMBNAIL_URL", ""), "Image to show when an article thumbnail can no longer be loaded (default: no thumbnail)")
+	rootCmd.Flags().String("metrics-addr", getEnvString("METRICS_ADDR", ""), "Address to serve Prometheus /metrics and /healthz on, e.g. :9090 (default: disabled)")
+	rootCmd.Flags().String("feed-addr", getEnvString("FEED_ADDR", ""), "Address to serve Atom feeds of cached news on at /feed.xml and /feed/{tag}.xml, e.g. :9091; the same address as --metrics-addr shares its listener (default: disabled)")
+	rootCmd.PersistentFlags().Bool("no-migration-backup", false, "Do not back up the database before applying schema migrations")
 
 	// Add populate-db subcommand
 	var populateCmd = &cobra.Command{
@@ -280,10 +931,15 @@ func main() {
 		Short: "Populate database with historical news to prevent re-posting old articles",
 		Run:   populateDatabase,
 	}
-	populateCmd.Flags().StringVar(&config.DatabasePath, "database-path", getEnvString("DATABASE_PATH", "./data/stobot.db"), "Path to SQLite database")
+	populateCmd.Flags().StringVar(&config.DatabasePath, "database-path", defaultDatabasePath(), "Path to SQLite database, or a postgres:// URL")
+	populateCmd.Flags().StringVar(&config.BaseURL, "api-base-url", getEnvString("STO_API_BASE_URL", ""), "News API endpoint override, e.g. for a proxy or mock server (default: Arc Games API)")
+	populateCmd.Flags().String("tag-aliases", getEnvString("TAG_ALIASES", ""), "Tag aliases as alias=tag pairs or a JSON object, e.g. maintenance=server-maintenance, added to the built-in ones")
 	populateCmd.Flags().IntVar(&config.PollCount, "count", getEnvInt("POLL_COUNT", 100), "Number of news items to fetch and mark as posted")
 	populateCmd.Flags().StringSliceP("tags", "t", []string{"star-trek-online", "patch-notes"}, "News tags to populate")
 	populateCmd.Flags().BoolP("dry-run", "n", false, "Show what would be populated without making changes")
+	populateCmd.Flags().Bool("force", false, "Populate every tag from the start, including tags a previous run completed")
+	populateCmd.Flags().Int("rate-limit", getEnvInt("POPULATE_RATE_LIMIT", 0), "News API requests per minute (0 does not limit requests)")
+	populateCmd.Flags().Bool("require-channels", false, "Abort if no channels are registered instead of only caching news")
 
 	// Add import-channels subcommand
 	var importCmd = &cobra.Command{
@@ -291,16 +947,96 @@ func main() {
 		Short: "Import channel configuration from channels.txt file into database",
 		Run:   importChannels,
 	}
-	importCmd.Flags().StringVar(&config.DatabasePath, "database-path", getEnvString("DATABASE_PATH", "./data/stobot.db"), "Path to SQLite database")
+	importCmd.Flags().StringVar(&config.DatabasePath, "database-path", defaultDatabasePath(), "Path to SQLite database, or a postgres:// URL")
 	importCmd.Flags().StringVar(&config.ChannelsPath, "channels-file", getEnvString("CHANNELS_PATH", "./channels.txt"), "Path to channels.txt file to import")
 
-	// Add list-channels subcommand
-	var listCmd = &cobra.Command{
+	// Add export-channels subcommand
+	var exportCmd = &cobra.Command{
+		Use:   "export-channels",
+		Short: "Export registered channels in the channels.txt format read by import-channels",
+		Run:   exportChannels,
+	}
+	exportCmd.Flags().StringVar(&config.DatabasePath, "database-path", defaultDatabasePath(), "Path to SQLite database, or a postgres:// URL")
+	exportCmd.Flags().StringP("output", "o", "-", "File to write the channels to (- for stdout)")
+	exportCmd.Flags().String("environment", "", "Only export channels in this environment (DEV or PROD)")
+
+	// Add export-news subcommand
+	var exportNewsCmd = &cobra.Command{
+		Use:   "export-news",
+		Short: "Export cached news as JSON or CSV for external analysis",
+		Long: "Write the cached news, oldest first, as a JSON array of news items or as CSV with a header row.\n" +
+			"--since and --until take a date (YYYY-MM-DD, --until including that day) or an RFC 3339 time.",
+		Run: exportNews,
+	}
+	exportNewsCmd.Flags().StringVar(&config.DatabasePath, "database-path", defaultDatabasePath(), "Path to SQLite database, or a postgres:// URL")
+	exportNewsCmd.Flags().StringP("output", "o", "-", "File to write the news to (- for stdout)")
+	exportNewsCmd.Flags().String("format", database.NewsExportJSON, "Export format (json or csv)")
+	exportNewsCmd.Flags().String("tag", "", "Only export news with this tag")
+	exportNewsCmd.Flags().String("platform", "", "Only export news for this platform")
+	exportNewsCmd.Flags().String("since", "", "Only export news updated on or after this date")
+	exportNewsCmd.Flags().String("until", "", "Only export news updated up to this date")
+
+	// Add list-channels subcommand
+	var listCmd = &cobra.Command{
 		Use:   "list-channels",
 		Short: "List registered channels in the database",
 		Run:   listChannels,
 	}
-	listCmd.Flags().StringVar(&config.DatabasePath, "database-path", getEnvString("DATABASE_PATH", "./data/stobot.db"), "Path to SQLite database")
+	listCmd.Flags().StringVar(&config.DatabasePath, "database-path", defaultDatabasePath(), "Path to SQLite database, or a postgres:// URL")
+
+	// Add channels subcommand to take channels out of posting and back
+	var channelsCmd = &cobra.Command{
+		Use:   "channels",
+		Short: "Channel maintenance commands",
+	}
+	var enableChannelsCmd = &cobra.Command{
+		Use:   "enable <channel-id>...",
+		Short: "Resume posting to channels, e.g. ones disabled after failed posts",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   setChannelsDisabled(false),
+	}
+	enableChannelsCmd.Flags().StringVar(&config.DatabasePath, "database-path", defaultDatabasePath(), "Path to SQLite database, or a postgres:// URL")
+	channelsCmd.AddCommand(enableChannelsCmd)
+	var disableChannelsCmd = &cobra.Command{
+		Use:   "disable <channel-id>...",
+		Short: "Stop posting to channels without unregistering them",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   setChannelsDisabled(true),
+	}
+	disableChannelsCmd.Flags().StringVar(&config.DatabasePath, "database-path", defaultDatabasePath(), "Path to SQLite database, or a postgres:// URL")
+	channelsCmd.AddCommand(disableChannelsCmd)
+	var setPlatformsCmd = &cobra.Command{
+		Use:   "set-platforms",
+		Short: "Set the platforms of many channels at once",
+		Long: "Set the platforms of the channels given with --channels, or of every registered channel with\n" +
+			"--channels all, in one transaction. --environment only updates channels in that environment.\n" +
+			"If any channel cannot be updated, none is, unless --ignore-errors is set. With --dry-run, only\n" +
+			"report the changes.",
+		Args: cobra.NoArgs,
+		Run:  setPlatforms,
+	}
+	setPlatformsCmd.Flags().StringVar(&config.DatabasePath, "database-path", defaultDatabasePath(), "Path to SQLite database, or a postgres:// URL")
+	setPlatformsCmd.Flags().String("channels", "", "Comma-separated channel IDs to update, or all")
+	setPlatformsCmd.Flags().String("platforms", "", "Comma-separated platforms to set (pc, xbox, ps, or all)")
+	setPlatformsCmd.Flags().String("environment", "", "Only update channels in this environment (DEV or PROD)")
+	setPlatformsCmd.Flags().BoolP("dry-run", "n", false, "Only report the changes")
+	setPlatformsCmd.Flags().Bool("ignore-errors", false, "Update the other channels when some cannot be updated")
+	_ = setPlatformsCmd.MarkFlagRequired("channels")
+	_ = setPlatformsCmd.MarkFlagRequired("platforms")
+	channelsCmd.AddCommand(setPlatformsCmd)
+
+	// Add migrate-channel subcommand
+	var migrateChannelCmd = &cobra.Command{
+		Use:   "migrate-channel <old-channel-id> <new-channel-id>",
+		Short: "Move a channel's registration, settings and posting history to a new channel",
+		Long: "Move the registration of a channel, e.g. one that was deleted and recreated, to a new channel ID,\n" +
+			"keeping its settings and the record of the news posted to it. The new channel must not be\n" +
+			"registered. With --token, the new channel is checked to exist.",
+		Args: cobra.ExactArgs(2),
+		Run:  migrateChannel,
+	}
+	migrateChannelCmd.Flags().StringVar(&config.DatabasePath, "database-path", defaultDatabasePath(), "Path to SQLite database, or a postgres:// URL")
+	migrateChannelCmd.Flags().String("token", os.Getenv("DISCORD_TOKEN"), "Discord bot token, to check that the new channel exists")
 
 	// Add mark-all-posted subcommand
 	var markPostedCmd = &cobra.Command{
@@ -308,94 +1044,780 @@ func main() {
 		Short: "Mark all cached news as already posted to prevent re-sending old messages",
 		Run:   markAllPosted,
 	}
-	markPostedCmd.Flags().StringVar(&config.DatabasePath, "database-path", getEnvString("DATABASE_PATH", "./data/stobot.db"), "Path to SQLite database")
+	markPostedCmd.Flags().StringVar(&config.DatabasePath, "database-path", defaultDatabasePath(), "Path to SQLite database, or a postgres:// URL")
 	markPostedCmd.Flags().BoolP("dry-run", "n", false, "Show what would be marked without making changes")
 
+	// Add poll-once subcommand
+	pollOnceCmd := newPollOnceCmd()
+
+	// Add catchup subcommand
+	var catchUpCmd = &cobra.Command{
+		Use:   "catchup",
+		Short: "Post unposted news from the last days once and exit",
+		Long: "Run the startup catch-up once: post news from the last --days days that has not been posted\n" +
+			"to each registered channel over the Discord REST API, and exit. With --dry-run, only list\n" +
+			"which news would be posted to which channels.",
+		Run: catchUp,
+	}
+	catchUpCmd.Flags().StringVar(&config.DiscordToken, "token", os.Getenv("DISCORD_TOKEN"), "Discord bot token (not needed with --dry-run)")
+	catchUpCmd.Flags().Int("days", getEnvInt("CATCHUP_DAYS", news.DefaultCatchUpDays), "Days of news to catch up on")
+	catchUpCmd.Flags().IntVar(&config.PollCount, "poll-count", getEnvInt("POLL_COUNT", 20), "Number of news to poll; the catch-up fetches ten times as many")
+	catchUpCmd.Flags().IntVar(&config.DisableAfterFailures, "disable-after-failures", getEnvInt("DISABLE_AFTER_FAILURES", news.DefaultDisableAfterFailures), "Consecutive posts failing because a channel was deleted or the bot lost access before the channel is disabled (0 never disables)")
+	catchUpCmd.Flags().IntVar(&config.FutureSkewSeconds, "future-skew-seconds", getEnvInt("FUTURE_SKEW_SECONDS", news.DefaultFutureSkewSeconds), "Seconds in the future news may be dated and still be posted; news dated later is held back until it is due")
+	catchUpCmd.Flags().IntVar(&config.MaxPostsPerCycle, "max-posts-per-cycle", getEnvInt("MAX_POSTS_PER_CYCLE", news.DefaultMaxPostsPerCycle), "News items posted to a channel per poll cycle or catch-up, unless the channel sets its own; the rest is posted by later cycles, oldest first (0 disables the limit)")
+	catchUpCmd.Flags().IntVar(&config.ThreadArchiveMinutes, "thread-archive-minutes", getEnvInt("THREAD_ARCHIVE_MINUTES", news.DefaultThreadArchiveMinutes), "Minutes of inactivity before news discussion threads are archived: 60, 1440, 4320 or 10080")
+	catchUpCmd.Flags().IntVar(&config.DuplicateWindowDays, "duplicate-window-days", getEnvInt("DUPLICATE_WINDOW_DAYS", database.DefaultDuplicateWindowDays), "Days a posted article keeps copies republished under a new ID from being posted to the same channel (0 disables the check)")
+	catchUpCmd.Flags().StringVar(&config.DatabasePath, "database-path", defaultDatabasePath(), "Path to SQLite database, or a postgres:// URL")
+	catchUpCmd.Flags().StringVar(&config.Environment, "environment", getEnvEnvironment(), "Bot environment (DEV or PROD); only channels registered in this environment are served")
+	catchUpCmd.Flags().String("embed-colors", getEnvString("EMBED_COLORS", ""), "Embed color per news tag as tag=color pairs or a JSON object, e.g. patch-notes=#ff8800,events=#9b59b6 (\"default\" sets the fallback)")
+	catchUpCmd.Flags().String("tag-aliases", getEnvString("TAG_ALIASES", ""), "Tag aliases as alias=tag pairs or a JSON object, e.g. maintenance=server-maintenance, added to the built-in ones")
+	catchUpCmd.Flags().StringArray("url-rewrite", getEnvURLRewrites(), "URL rewrite rule old-prefix=>new-prefix applied to article links and thumbnails (repeatable)")
+	catchUpCmd.Flags().StringVar(&config.BaseURL, "api-base-url", getEnvString("STO_API_BASE_URL", ""), "News API endpoint override, e.g. for a proxy or mock server (default: Arc Games API)")
+	catchUpCmd.Flags().StringVar(&config.DefaultThumbnailURL, "default-thumbnail-url", getEnvString("DEFAULT_THUMBNAIL_URL", ""), "Image to show when an article thumbnail can no longer be loaded (default: no thumbnail)")
+	catchUpCmd.Flags().BoolP("dry-run", "n", false, "Only list the news that would be posted to each channel")
+
+	// Add db subcommand with database maintenance helpers
+	var dbCmd = &cobra.Command{
+		Use:   "db",
+		Short: "Database maintenance commands",
+	}
+	var restoreBackupCmd = &cobra.Command{
+		Use:   "restore-backup [number|path]",
+		Short: "List pre-migration database backups, or restore one",
+		Args:  cobra.MaximumNArgs(1),
+		Run:   restoreBackup,
+	}
+	restoreBackupCmd.Flags().StringVar(&config.DatabasePath, "database-path", defaultDatabasePath(), "Path to SQLite database")
+	dbCmd.AddCommand(restoreBackupCmd)
+	var rewriteURLsCmd = &cobra.Command{
+		Use:   "rewrite-urls",
+		Short: "Rewrite stored thumbnail and article URLs according to the URL rewrite rules",
+		Long: "Report the cached news URLs matched by the URL rewrite rules (--dry-run, the default),\n" +
+			"or permanently update them (--apply).",
+		Run: rewriteURLs,
+	}
+	rewriteURLsCmd.Flags().StringVar(&config.DatabasePath, "database-path", defaultDatabasePath(), "Path to SQLite database, or a postgres:// URL")
+	rewriteURLsCmd.Flags().StringArray("url-rewrite", getEnvURLRewrites(), "URL rewrite rule old-prefix=>new-prefix (repeatable)")
+	rewriteURLsCmd.Flags().Bool("apply", false, "Write the rewritten URLs to the database")
+	rewriteURLsCmd.Flags().BoolP("dry-run", "n", false, "Only report the URLs that would be rewritten (default)")
+	rewriteURLsCmd.MarkFlagsMutuallyExclusive("apply", "dry-run")
+	dbCmd.AddCommand(rewriteURLsCmd)
+
+	// Add prune subcommand
+	var pruneCmd = &cobra.Command{
+		Use:   "prune",
+		Short: "Remove old news from the cache",
+		Long: "Remove news fetched more than --retention-days days ago from the cache. News that has been\n" +
+			"posted to a channel is kept for digests and stats unless --prune-posted is set. With --dry-run,\n" +
+			"only report how many entries would be removed.",
+		Run: prune,
+	}
+	pruneCmd.Flags().StringVar(&config.DatabasePath, "database-path", defaultDatabasePath(), "Path to SQLite database, or a postgres:// URL")
+	pruneCmd.Flags().Int("retention-days", getEnvInt("CACHE_RETENTION_DAYS", database.DefaultCacheRetentionDays), "Remove news fetched more than this many days ago (0 keeps all news)")
+	pruneCmd.Flags().Bool("prune-posted", false, "Also remove news that has been posted to a channel")
+	pruneCmd.Flags().BoolP("dry-run", "n", false, "Only report how many entries would be removed")
+
+	// Add retag subcommand
+	var retagCmd = &cobra.Command{
+		Use:   "retag",
+		Short: "Refetch cached news and add the tags the news API gives them",
+		Long: "Look up cached news in the news API, --rate requests per second, and merge the tags it gives\n" +
+			"into the cached tags, keeping locally added ones. Use --ids to only retag some news, and\n" +
+			"--dry-run to only report the tags that would change.",
+		Run: retag,
+	}
+	retagCmd.Flags().StringVar(&config.DatabasePath, "database-path", defaultDatabasePath(), "Path to SQLite database, or a postgres:// URL")
+	retagCmd.Flags().StringVar(&config.BaseURL, "api-base-url", getEnvString("STO_API_BASE_URL", ""), "News API endpoint override, e.g. for a proxy or mock server (default: Arc Games API)")
+	retagCmd.Flags().String("tag-aliases", getEnvString("TAG_ALIASES", ""), "Tag aliases as alias=tag pairs or a JSON object, e.g. maintenance=server-maintenance, added to the built-in ones")
+	retagCmd.Flags().Int64Slice("ids", nil, "Only retag the cached news with these IDs (comma-separated)")
+	retagCmd.Flags().Float64("rate", news.DefaultRetagRate, "News API requests per second")
+	retagCmd.Flags().BoolP("dry-run", "n", false, "Only report the tags that would change")
+
+	// Add backfill-content subcommand
+	var backfillContentCmd = &cobra.Command{
+		Use:   "backfill-content",
+		Short: "Fetch the content of cached news that has none",
+		Long: "Fetch the article body of cached news without content, such as news cached before content\n" +
+			"was stored, --rate requests per second, and store it as plain text so content searches and\n" +
+			"/stobot_read find it. Use --limit to only fetch some news, and --dry-run to only report it.",
+		Run: backfillContent,
+	}
+	backfillContentCmd.Flags().StringVar(&config.DatabasePath, "database-path", defaultDatabasePath(), "Path to SQLite database, or a postgres:// URL")
+	backfillContentCmd.Flags().StringVar(&config.BaseURL, "api-base-url", getEnvString("STO_API_BASE_URL", ""), "News API endpoint override, e.g. for a proxy or mock server (default: Arc Games API)")
+	backfillContentCmd.Flags().Int("limit", 0, "Most news items to fetch (0 fetches all news without content)")
+	backfillContentCmd.Flags().Float64("rate", news.DefaultBackfillRate, "News API requests per second")
+	backfillContentCmd.Flags().BoolP("dry-run", "n", false, "Only report the news that would be given content")
+
 	rootCmd.AddCommand(populateCmd)
 	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(exportNewsCmd)
 	rootCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(channelsCmd)
+	rootCmd.AddCommand(migrateChannelCmd)
 	rootCmd.AddCommand(markPostedCmd)
+	rootCmd.AddCommand(pollOnceCmd)
+	rootCmd.AddCommand(catchUpCmd)
+	rootCmd.AddCommand(dbCmd)
+	rootCmd.AddCommand(pruneCmd)
+	rootCmd.AddCommand(retagCmd)
+	rootCmd.AddCommand(backfillContentCmd)
+
+	// Add doctor subcommand; it checks the configuration the bot would run with
+	var doctorCmd = &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the configuration, database, Discord token and news API",
+		Long: "Run a series of startup checks with the flags and environment the bot would run with:\n" +
+			"the configuration, opening and migrating the database with a write probe, the Discord token\n" +
+			"and the news API. Print a PASS/FAIL table with hints, and exit with status 1 if a check fails.",
+		Run: doctor,
+	}
+	doctorCmd.Flags().AddFlagSet(rootCmd.Flags())
+	rootCmd.AddCommand(doctorCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		log.Fatal(err)
 	}
 }
 
-// runBot initializes and starts the STOBot application.
-func runBot(cmd *cobra.Command, args []string) {
+// newPollOnceCmd returns the poll-once subcommand. Its flags are the subset of the root
+// command's that a poll cycle uses, read into the config by botConfig like the root command's.
+func newPollOnceCmd() *cobra.Command {
+	var config types.Config
+	pollOnceCmd := &cobra.Command{
+		Use:   "poll-once",
+		Short: "Run a single poll cycle and exit (for cron-based deployments)",
+		Long: "Fetch news once, post unposted news to all registered channels over the Discord REST API, and exit.\n" +
+			"Exit status is 0 on success, 1 if the cycle could not run, and 2 if some posts failed.",
+		Run: pollOnce,
+	}
+	pollOnceCmd.Flags().StringVar(&config.DiscordToken, "token", os.Getenv("DISCORD_TOKEN"), "Discord bot token")
+	pollOnceCmd.Flags().IntVar(&config.PollCount, "poll-count", getEnvInt("POLL_COUNT", 20), "Number of news to poll")
+	pollOnceCmd.Flags().String("poll-tags", getEnvString("POLL_TAGS", strings.Join(news.DefaultPollTags, ",")), "Comma-separated news tags fetched and merged (empty fetches the API's default feed)")
+	pollOnceCmd.Flags().IntVar(&config.FreshSeconds, "fresh-seconds", getEnvInt("FRESH_SECONDS", 600), "Maximum age of news items to post")
+	pollOnceCmd.Flags().IntVar(&config.MsgCount, "msg-count", getEnvInt("MSG_COUNT", 10), "Number of Discord messages to check for duplicates")
+	pollOnceCmd.Flags().BoolVar(&config.SkipDuplicateCheck, "skip-duplicate-check", getEnvBool("SKIP_DUPLICATE_CHECK", false), "Do not check recent channel messages before posting (for bots without Read Message History)")
+	pollOnceCmd.Flags().IntVar(&config.PostConcurrency, "post-concurrency", getEnvInt("POST_CONCURRENCY", news.DefaultPostConcurrency), "Number of channels posted to at once; posts are paced to 5 per second overall")
+	pollOnceCmd.Flags().StringVar(&config.DatabasePath, "database-path", defaultDatabasePath(), "Path to SQLite database, or a postgres:// URL")
+	pollOnceCmd.Flags().StringVar(&config.Environment, "environment", getEnvEnvironment(), "Bot environment (DEV or PROD); only channels registered in this environment are served")
+	pollOnceCmd.Flags().IntVar(&config.CacheRetentionDays, "cache-retention-days", getEnvInt("CACHE_RETENTION_DAYS", database.DefaultCacheRetentionDays), "Days unposted news is kept in the cache (0 keeps it forever)")
+	pollOnceCmd.Flags().IntVar(&config.DisableAfterFailures, "disable-after-failures", getEnvInt("DISABLE_AFTER_FAILURES", news.DefaultDisableAfterFailures), "Consecutive posts failing because a channel was deleted or the bot lost access before the channel is disabled (0 never disables)")
+	pollOnceCmd.Flags().IntVar(&config.FutureSkewSeconds, "future-skew-seconds", getEnvInt("FUTURE_SKEW_SECONDS", news.DefaultFutureSkewSeconds), "Seconds in the future news may be dated and still be posted; news dated later is held back until it is due")
+	pollOnceCmd.Flags().IntVar(&config.MaxPostsPerCycle, "max-posts-per-cycle", getEnvInt("MAX_POSTS_PER_CYCLE", news.DefaultMaxPostsPerCycle), "News items posted to a channel per poll cycle or catch-up, unless the channel sets its own; the rest is posted by later cycles, oldest first (0 disables the limit)")
+	pollOnceCmd.Flags().IntVar(&config.UpdateNoticeSeconds, "update-notice-seconds", getEnvInt("UPDATE_NOTICE_SECONDS", news.DefaultUpdateNoticeSeconds), "Seconds an article must be dated after its cached version before channels it was posted to are told it was updated")
+	pollOnceCmd.Flags().IntVar(&config.GalleryMaxImages, "gallery-max-images", getEnvInt("GALLERY_MAX_IMAGES", news.DefaultGalleryMaxImages), "Extra images, such as screenshots, posted as a gallery with news in channels showing galleries (at most 9)")
+	pollOnceCmd.Flags().IntVar(&config.ThreadArchiveMinutes, "thread-archive-minutes", getEnvInt("THREAD_ARCHIVE_MINUTES", news.DefaultThreadArchiveMinutes), "Minutes of inactivity before news discussion threads are archived: 60, 1440, 4320 or 10080")
+	pollOnceCmd.Flags().IntVar(&config.DuplicateWindowDays, "duplicate-window-days", getEnvInt("DUPLICATE_WINDOW_DAYS", database.DefaultDuplicateWindowDays), "Days a posted article keeps copies republished under a new ID from being posted to the same channel (0 disables the check)")
+	pollOnceCmd.Flags().String("embed-colors", getEnvString("EMBED_COLORS", ""), "Embed color per news tag as tag=color pairs or a JSON object, e.g. patch-notes=#ff8800,events=#9b59b6 (\"default\" sets the fallback)")
+	pollOnceCmd.Flags().String("tag-aliases", getEnvString("TAG_ALIASES", ""), "Tag aliases as alias=tag pairs or a JSON object, e.g. maintenance=server-maintenance, added to the built-in ones")
+	pollOnceCmd.Flags().StringArray("url-rewrite", getEnvURLRewrites(), "URL rewrite rule old-prefix=>new-prefix applied to article links and thumbnails (repeatable)")
+	pollOnceCmd.Flags().StringVar(&config.BaseURL, "api-base-url", getEnvString("STO_API_BASE_URL", ""), "News API endpoint override, e.g. for a proxy or mock server (default: Arc Games API)")
+	pollOnceCmd.Flags().StringVar(&config.DefaultThumbnailURL, "default-thumbnail-url", getEnvString("DEFAULT_THUMBNAIL_URL", ""), "Image to show when an article thumbnail can no longer be loaded (default: no thumbnail)")
+	return pollOnceCmd
+}
+
+// Exit codes of poll-once.
+const (
+	pollOnceExitOK      = 0 // The cycle completed and every post was sent.
+	pollOnceExitFailed  = 1 // The cycle could not run.
+	pollOnceExitPartial = 2 // The cycle completed but some posts failed.
+)
+
+// pollOnce runs a single poll cycle over the Discord REST API and exits with a status code
+// reflecting the outcome, for cron-based deployments.
+func pollOnce(cmd *cobra.Command, args []string) {
+	// Initialize logger
+	configureLogging(cmd, log.InfoLevel)
+
+	config, err := botConfig(cmd)
+	if err != nil {
+		log.Fatalf("Configuration validation failed: %v", err)
+	}
+	if config.DiscordToken == "" {
+		log.Fatal("Discord token is required")
+	}
+	if err := types.ValidateEnvironment(config.Environment); err != nil {
+		log.Fatalf("Configuration validation failed: %v", err)
+	}
+
+	db, err := openDatabase(cmd, config.DatabasePath)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	dg, err := newRESTSession(config.DiscordToken)
+	if err != nil {
+		db.Close()
+		log.Fatalf("Failed to create Discord session: %v", err)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		log.Warnf("Failed to get hostname: %v", err)
+	}
+
+	bot := &types.Bot{
+		Session:    dg,
+		DB:         db,
+		Config:     config,
+		InstanceID: types.BuildInstanceID(config.Environment, hostname, 0),
+		Version:    version,
+	}
+
+	// Stop between channels on interrupt
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	summary, err := news.RunPollCycle(ctx, bot)
+	cancel()
+	db.Close()
+
+	code := pollOnceExitCode(summary, err)
+	if err != nil {
+		log.Errorf("Poll cycle failed: %v (%s)", err, summary)
+	} else {
+		log.Infof("Poll cycle complete: %s", summary)
+	}
+	os.Exit(code)
+}
+
+// pollOnceExitCode maps the outcome of a poll cycle to the poll-once exit code.
+func pollOnceExitCode(summary news.PollCycleSummary, err error) int {
+	switch {
+	case err != nil:
+		return pollOnceExitFailed
+	case summary.Failed > 0:
+		return pollOnceExitPartial
+	default:
+		return pollOnceExitOK
+	}
+}
+
+// newRESTSession creates a Discord session that is only used for REST calls; no gateway
+// connection is opened. The bot user is looked up so the duplicate check recognizes the
+// bot's own messages.
+func newRESTSession(token string) (*discordgo.Session, error) {
+	dg, err := discordgo.New("Bot " + token)
+	if err != nil {
+		return nil, err
+	}
+	user, err := dg.User("@me")
+	if err != nil {
+		log.Warnf("Failed to get bot user, recent messages will not be checked for duplicates: %v", err)
+		return dg, nil
+	}
+	dg.State.User = user
+	return dg, nil
+}
+
+// catchUp runs the catch-up once over the Discord REST API and exits. With --dry-run it
+// only lists the planned posts.
+func catchUp(cmd *cobra.Command, args []string) {
 	config := &types.Config{}
 	config.DiscordToken, _ = cmd.Flags().GetString("token")
-	config.PollPeriod, _ = cmd.Flags().GetInt("poll-period")
 	config.PollCount, _ = cmd.Flags().GetInt("poll-count")
-	config.FreshSeconds, _ = cmd.Flags().GetInt("fresh-seconds")
-	config.MsgCount, _ = cmd.Flags().GetInt("msg-count")
-	config.ChannelsPath, _ = cmd.Flags().GetString("channels-path")
 	config.DatabasePath, _ = cmd.Flags().GetString("database-path")
-	config.Environment = getEnvString("STOBOT_ENVIRONMENT", "PROD") // Default to PROD if not set
+	config.BaseURL, _ = cmd.Flags().GetString("api-base-url")
+	config.DefaultThumbnailURL, _ = cmd.Flags().GetString("default-thumbnail-url")
+	config.DuplicateWindowDays, _ = cmd.Flags().GetInt("duplicate-window-days")
+	config.DisableAfterFailures, _ = cmd.Flags().GetInt("disable-after-failures")
+	config.FutureSkewSeconds, _ = cmd.Flags().GetInt("future-skew-seconds")
+	config.MaxPostsPerCycle, _ = cmd.Flags().GetInt("max-posts-per-cycle")
+	config.ThreadArchiveMinutes, _ = cmd.Flags().GetInt("thread-archive-minutes")
+	config.Environment, _ = cmd.Flags().GetString("environment")
+	days, _ := cmd.Flags().GetInt("days")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
 
-	if config.DiscordToken == "" {
+	// Initialize logger
+	configureLogging(cmd, log.InfoLevel)
+
+	if days <= 0 {
+		log.Fatal("Days must be positive")
+	}
+	if !dryRun && config.DiscordToken == "" {
 		log.Fatal("Discord token is required")
 	}
+	if err := types.ValidateEnvironment(config.Environment); err != nil {
+		log.Fatalf("Configuration validation failed: %v", err)
+	}
 
-	// Validate config
-	if err := config.Validate(); err != nil {
+	rules, err := urlRewriteRules(cmd)
+	if err != nil {
 		log.Fatalf("Configuration validation failed: %v", err)
 	}
+	config.URLRewrites = rules
 
-	log.Infof("Bot starting in %s environment", config.Environment)
+	colors, err := embedColors(cmd)
+	if err != nil {
+		log.Fatalf("Configuration validation failed: %v", err)
+	}
+	config.EmbedColors = colors
 
-	// Initialize logger
-	log.SetFormatter(&log.JSONFormatter{})
-	log.SetLevel(log.InfoLevel)
+	aliases, err := tagAliases(cmd)
+	if err != nil {
+		log.Fatalf("Configuration validation failed: %v", err)
+	}
+	config.TagAliases = aliases
 
-	// Initialize database
-	db, err := database.InitDatabase(config.DatabasePath)
+	db, err := openDatabase(cmd, config.DatabasePath)
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 	defer db.Close()
 
-	// Create Discord session
-	dg, err := discordgo.New("Bot " + config.DiscordToken)
+	bot := &types.Bot{
+		DB:      db,
+		Config:  config,
+		Version: version,
+	}
+
+	// Stop between posts on interrupt
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if dryRun {
+		posts, err := news.PlanCatchUp(ctx, bot, days)
+		if err != nil {
+			log.Fatalf("Catch-up failed: %v", err)
+		}
+		for _, post := range posts {
+			log.Infof("News %d ('%s') -> channel %s", post.NewsID, post.Title, post.ChannelID)
+		}
+		log.Infof("DRY RUN: Would post %d news items from the last %d days", len(posts), days)
+		return
+	}
+
+	dg, err := newRESTSession(config.DiscordToken)
 	if err != nil {
 		log.Fatalf("Failed to create Discord session: %v", err)
 	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		log.Warnf("Failed to get hostname: %v", err)
+	}
+	bot.Session = dg
+	bot.InstanceID = types.BuildInstanceID(config.Environment, hostname, 0)
+
+	posted, err := news.CatchUpUnpostedNews(ctx, bot, days)
+	if err != nil {
+		log.Fatalf("Catch-up failed after %d posts: %v", posted, err)
+	}
+	log.Infof("Catch-up complete: posted %d news items from the last %d days", posted, days)
+}
+
+// openDatabase initializes the database, backing it up before pending migrations
+// unless --no-migration-backup is set.
+func openDatabase(cmd *cobra.Command, dbPath string) (*sql.DB, error) {
+	noBackup, _ := cmd.Flags().GetBool("no-migration-backup")
+	options := database.DefaultInitOptions()
+	options.MigrationBackup = !noBackup
+	return database.InitDatabaseWithOptions(dbPath, options)
+}
+
+// restoreBackup lists the pre-migration backups of the database, or restores the one given
+// by number (as listed) or path.
+func restoreBackup(cmd *cobra.Command, args []string) {
+	// Get command line flags
+	dbPath, _ := cmd.Flags().GetString("database-path")
+
+	// Initialize logger
+	configureLogging(cmd, log.InfoLevel)
+
+	if database.IsPostgresURL(dbPath) {
+		log.Fatal("Migration backups are only made of SQLite databases; restore PostgreSQL databases with pg_restore")
+	}
+
+	backups, err := database.ListMigrationBackups(dbPath)
+	if err != nil {
+		log.Fatalf("Failed to list backups: %v", err)
+	}
+
+	if len(args) == 0 {
+		if len(backups) == 0 {
+			log.Infof("No migration backups found for database %s", database.DisplayPath(dbPath))
+			return
+		}
+		log.Infof("Found %d migration backups for database %s (newest first):", len(backups), database.DisplayPath(dbPath))
+		for n, backup := range backups {
+			log.Infof("  %d: %s", n+1, backup)
+		}
+		log.Info("Stop the bot, then restore one with: stobot db restore-backup <number|path>")
+		return
+	}
+
+	backupPath, err := resolveBackup(args[0], backups)
+	if err != nil {
+		log.Fatalf("Failed to restore backup: %v", err)
+	}
+	if err := database.RestoreBackup(backupPath, dbPath); err != nil {
+		log.Fatalf("Failed to restore backup: %v", err)
+	}
+
+	log.Infof("Database %s restored from %s", database.DisplayPath(dbPath), backupPath)
+}
+
+// resolveBackup returns the backup selected by a 1-based number from backups, or the argument
+// itself when it is not a number.
+func resolveBackup(arg string, backups []string) (string, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil {
+		return arg, nil
+	}
+	if n < 1 || n > len(backups) {
+		return "", fmt.Errorf("backup number %d out of range (found %d backups)", n, len(backups))
+	}
+	return backups[n-1], nil
+}
+
+// rewriteURLs reports the stored URLs matched by the URL rewrite rules and, with --apply,
+// permanently rewrites them.
+func rewriteURLs(cmd *cobra.Command, args []string) {
+	// Get command line flags
+	dbPath, _ := cmd.Flags().GetString("database-path")
+	apply, _ := cmd.Flags().GetBool("apply")
+
+	// Initialize logger
+	configureLogging(cmd, log.InfoLevel)
+
+	rules, err := urlRewriteRules(cmd)
+	if err != nil {
+		log.Fatalf("Failed to parse URL rewrite rules: %v", err)
+	}
+	if len(rules) == 0 {
+		log.Fatal("No URL rewrite rules configured: use --url-rewrite or URL_REWRITES")
+	}
+
+	db, err := openDatabase(cmd, dbPath)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
 
 	bot := &types.Bot{
-		Session: dg,
-		DB:      db,
-		Config:  config,
+		DB:     db,
+		Config: &types.Config{URLRewrites: rules},
+	}
+
+	changes, err := database.RewriteStoredURLs(bot, rules, apply)
+	if err != nil {
+		log.Fatalf("Failed to rewrite URLs: %v", err)
+	}
+
+	for _, change := range changes {
+		log.Infof("News %d %s: %s -> %s", change.NewsID, change.Column, change.OldURL, change.NewURL)
+	}
+	if apply {
+		log.Infof("Rewrote %d stored URLs", len(changes))
+	} else {
+		log.Infof("DRY RUN: %d stored URLs would be rewritten; run with --apply to update them", len(changes))
 	}
+}
+
+// prune removes old news from the cache, or reports how much would be removed.
+func prune(cmd *cobra.Command, args []string) {
+	// Get command line flags
+	dbPath, _ := cmd.Flags().GetString("database-path")
+	retentionDays, _ := cmd.Flags().GetInt("retention-days")
+	prunePosted, _ := cmd.Flags().GetBool("prune-posted")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
 
-	// Register event handlers
-	dg.AddHandler(discord.Ready(bot))
-	dg.AddHandler(discord.InteractionCreate(bot))
+	// Initialize logger
+	configureLogging(cmd, log.InfoLevel)
 
-	// Set intents
-	dg.Identify.Intents = discordgo.IntentsGuildMessages | discordgo.IntentsDirectMessages
+	if retentionDays < 0 {
+		log.Fatal("Retention days must not be negative")
+	}
+	if retentionDays == 0 {
+		log.Info("Retention is 0 days: keeping all cached news")
+		return
+	}
 
-	// Open connection
-	err = dg.Open()
+	db, err := openDatabase(cmd, dbPath)
 	if err != nil {
-		log.Fatalf("Failed to open Discord connection: %v", err)
+		log.Fatalf("Failed to initialize database: %v", err)
 	}
-	defer dg.Close()
+	defer db.Close()
 
-	log.Info("Bot is now running. Press CTRL-C to exit.")
+	bot := &types.Bot{
+		DB:     db,
+		Config: &types.Config{},
+	}
 
-	// --- CATCH UP ON UNPOSTED NEWS AT STARTUP ---
-	go news.CatchUpUnpostedNews(bot, 7) // 7 days catch-up window
-	// --------------------------------------------
+	result, err := database.PruneNewsCache(bot, database.PruneOptions{
+		RetentionDays: retentionDays,
+		PrunePosted:   prunePosted,
+		DryRun:        dryRun,
+	})
+	if err != nil {
+		log.Fatalf("Failed to prune cache: %v", err)
+	}
 
-	// Start news polling
-	go news.NewsPoller(bot)
+	if dryRun {
+		log.Infof("DRY RUN: %d cached news older than %d days would be removed", result.Removed, retentionDays)
+	} else {
+		log.Infof("Removed %d cached news older than %d days", result.Removed, retentionDays)
+	}
+	if result.KeptPosted > 0 {
+		log.Infof("Kept %d older news that has been posted; run with --prune-posted to remove it", result.KeptPosted)
+	}
+}
 
-	// Wait for interrupt
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
-	<-stop
+// retag merges the tags the news API gives cached news into the cache, or reports the changes.
+func retag(cmd *cobra.Command, args []string) {
+	// Get command line flags
+	dbPath, _ := cmd.Flags().GetString("database-path")
+	baseURL, _ := cmd.Flags().GetString("api-base-url")
+	ids, _ := cmd.Flags().GetInt64Slice("ids")
+	rate, _ := cmd.Flags().GetFloat64("rate")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
 
-	log.Info("Gracefully shutting down...")
+	// Initialize logger
+	configureLogging(cmd, log.InfoLevel)
+
+	if rate <= 0 {
+		log.Fatal("Rate must be positive")
+	}
+	aliases, err := tagAliases(cmd)
+	if err != nil {
+		log.Fatalf("Configuration validation failed: %v", err)
+	}
+
+	db, err := openDatabase(cmd, dbPath)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	bot := &types.Bot{
+		DB:     db,
+		Config: &types.Config{BaseURL: baseURL, TagAliases: aliases},
+	}
+
+	// Stop between fetches on interrupt
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	result, err := news.RetagNews(ctx, bot, news.RetagOptions{
+		IDs:     ids,
+		DryRun:  dryRun,
+		Limiter: ratelimit.New(rate, 1),
+	})
+	if err != nil {
+		log.Fatalf("Failed to retag news: %v", err)
+	}
+
+	for _, change := range result.Changes {
+		log.Infof("News %d tags: %s -> %s", change.NewsID, strings.Join(change.OldTags, ","), strings.Join(change.NewTags, ","))
+	}
+	if result.Canceled {
+		log.Warn("Interrupted: not all cached news was checked")
+	}
+	if dryRun {
+		log.Infof("DRY RUN: %d of %d checked news items would be retagged (%d missing, %d failed)",
+			len(result.Changes), result.Checked, result.Missing, result.Failed)
+	} else {
+		log.Infof("Retagged %d of %d checked news items (%d missing, %d failed)",
+			len(result.Changes), result.Checked, result.Missing, result.Failed)
+	}
+}
+
+// backfillContent fetches the content of cached news without content, or reports how much
+// would be filled.
+func backfillContent(cmd *cobra.Command, args []string) {
+	// Get command line flags
+	dbPath, _ := cmd.Flags().GetString("database-path")
+	baseURL, _ := cmd.Flags().GetString("api-base-url")
+	limit, _ := cmd.Flags().GetInt("limit")
+	rate, _ := cmd.Flags().GetFloat64("rate")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	// Initialize logger
+	configureLogging(cmd, log.InfoLevel)
+
+	if rate <= 0 {
+		log.Fatal("Rate must be positive")
+	}
+	aliases, err := tagAliases(cmd)
+	if err != nil {
+		log.Fatalf("Configuration validation failed: %v", err)
+	}
+	if limit < 0 {
+		log.Fatal("Limit must not be negative")
+	}
+
+	db, err := openDatabase(cmd, dbPath)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	bot := &types.Bot{
+		DB:     db,
+		Config: &types.Config{BaseURL: baseURL, TagAliases: aliases},
+	}
+
+	// Stop between fetches on interrupt
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	result, err := news.BackfillContent(ctx, bot, news.BackfillOptions{
+		Limit:   limit,
+		DryRun:  dryRun,
+		Limiter: ratelimit.New(rate, 1),
+	})
+	if err != nil {
+		log.Fatalf("Failed to backfill content: %v", err)
+	}
+
+	if result.Canceled {
+		log.Warn("Interrupted: not all news without content was checked")
+	}
+	if dryRun {
+		log.Infof("DRY RUN: %d of %d checked news items would be given content (%d missing, %d failed)",
+			result.Filled, result.Checked, result.Missing, result.Failed)
+	} else {
+		log.Infof("Gave %d of %d checked news items content (%d missing, %d failed)",
+			result.Filled, result.Checked, result.Missing, result.Failed)
+	}
+}
+
+// embedColors parses the --embed-colors overrides of a command.
+func embedColors(cmd *cobra.Command) (map[string]int, error) {
+	spec, _ := cmd.Flags().GetString("embed-colors")
+	return types.ParseEmbedColors(spec)
+}
+
+// tagAliases parses the --tag-aliases of a command, merged with the built-in aliases.
+func tagAliases(cmd *cobra.Command) (map[string]string, error) {
+	spec, _ := cmd.Flags().GetString("tag-aliases")
+	return types.ParseTagAliases(spec)
+}
+
+// pollTags parses the comma-separated --poll-tags of a command, dropping empty and repeated tags.
+func pollTags(cmd *cobra.Command) []string {
+	spec, _ := cmd.Flags().GetString("poll-tags")
+	var tags []string
+	for _, tag := range strings.Split(spec, ",") {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// configureLogging sets up logging from the --log-level and --log-format flags every command
+// inherits from the root command. Without a level, everything logs at defaultLevel.
+func configureLogging(cmd *cobra.Command, defaultLevel log.Level) {
+	spec, _ := cmd.Flags().GetString("log-level")
+	format, _ := cmd.Flags().GetString("log-format")
+
+	levels, err := logging.ParseLevels(spec, defaultLevel)
+	if err != nil {
+		log.Fatalf("Invalid log level: %v", err)
+	}
+	if err := logging.Configure(levels, format); err != nil {
+		log.Fatalf("Invalid log format: %v", err)
+	}
+}
+
+// urlRewriteRules parses the --url-rewrite rules of a command.
+func urlRewriteRules(cmd *cobra.Command) ([]types.URLRewriteRule, error) {
+	specs, _ := cmd.Flags().GetStringArray("url-rewrite")
+	return types.ParseURLRewriteRules(specs)
+}
+
+// botConfig reads the bot configuration from the flags of the root command (or a command
+// sharing them, like doctor, or some of them, like poll-once; settings without a flag are left
+// zero). The URL rewrite rules, embed colors and tag aliases are parsed; the config
+// is not validated.
+func botConfig(cmd *cobra.Command) (*types.Config, error) {
+	config := &types.Config{}
+	config.DiscordToken, _ = cmd.Flags().GetString("token")
+	config.PollPeriod, _ = cmd.Flags().GetInt("poll-period")
+	config.PollCount, _ = cmd.Flags().GetInt("poll-count")
+	config.PollTags = pollTags(cmd)
+	config.FreshSeconds, _ = cmd.Flags().GetInt("fresh-seconds")
+	config.MsgCount, _ = cmd.Flags().GetInt("msg-count")
+	config.SkipDuplicateCheck, _ = cmd.Flags().GetBool("skip-duplicate-check")
+	config.DisableUsageStats, _ = cmd.Flags().GetBool("disable-usage-stats")
+	config.DryRun, _ = cmd.Flags().GetBool("dry-run")
+	config.PostConcurrency, _ = cmd.Flags().GetInt("post-concurrency")
+	config.CatchUpDays, _ = cmd.Flags().GetInt("catchup-days")
+	config.CacheRetentionDays, _ = cmd.Flags().GetInt("cache-retention-days")
+	config.DuplicateWindowDays, _ = cmd.Flags().GetInt("duplicate-window-days")
+	config.DisableAfterFailures, _ = cmd.Flags().GetInt("disable-after-failures")
+	config.FutureSkewSeconds, _ = cmd.Flags().GetInt("future-skew-seconds")
+	config.MaxPostsPerCycle, _ = cmd.Flags().GetInt("max-posts-per-cycle")
+	config.UpdateNoticeSeconds, _ = cmd.Flags().GetInt("update-notice-seconds")
+	config.GalleryMaxImages, _ = cmd.Flags().GetInt("gallery-max-images")
+	config.RegisterBackfillCount, _ = cmd.Flags().GetInt("register-backfill-count")
+	config.ThreadArchiveMinutes, _ = cmd.Flags().GetInt("thread-archive-minutes")
+	config.SearchCooldownUses, _ = cmd.Flags().GetInt("search-cooldown-uses")
+	config.SearchCooldownSeconds, _ = cmd.Flags().GetInt("search-cooldown-seconds")
+	config.ChannelsPath, _ = cmd.Flags().GetString("channels-path")
+	config.DatabasePath, _ = cmd.Flags().GetString("database-path")
+	config.BaseURL, _ = cmd.Flags().GetString("api-base-url")
+	config.GameStatusURL, _ = cmd.Flags().GetString("game-status-url")
+	config.DefaultThumbnailURL, _ = cmd.Flags().GetString("default-thumbnail-url")
+	config.Environment, _ = cmd.Flags().GetString("environment")
+
+	rules, err := urlRewriteRules(cmd)
+	if err != nil {
+		return config, err
+	}
+	config.URLRewrites = rules
+
+	colors, err := embedColors(cmd)
+	if err != nil {
+		return config, err
+	}
+	config.EmbedColors = colors
+
+	aliases, err := tagAliases(cmd)
+	if err != nil {
+		return config, err
+	}
+	config.TagAliases = aliases
+	return config, nil
+}
+
+// runBot initializes and starts the STOBot application, running it until interrupted.
+func runBot(cmd *cobra.Command, args []string) {
+	// Initialize logger
+	configureLogging(cmd, log.InfoLevel)
+
+	config, err := botConfig(cmd)
+	if err != nil {
+		log.Fatalf("Configuration validation failed: %v", err)
+	}
+
+	if config.DiscordToken == "" && !config.DryRun {
+		log.Fatal("Discord token is required")
+	}
+
+	log.Infof("Bot starting in %s environment", config.Environment)
+
+	options := app.Options{Version: version}
+	options.NoMigrationBackup, _ = cmd.Flags().GetBool("no-migration-backup")
+	options.MetricsAddr, _ = cmd.Flags().GetString("metrics-addr")
+	options.FeedAddr, _ = cmd.Flags().GetString("feed-addr")
+
+	stobot, err := app.New(config, options)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Infof("Bot instance %s (version %s, commit %s, built %s)", stobot.Bot().InstanceID, version, gitCommit, buildTime)
+	log.Info("Bot is now running. Press CTRL-C to exit.")
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	runErr := stobot.Run(ctx)
+	if err := stobot.Shutdown(); err != nil {
+		log.Warn(err)
+	}
+	if runErr != nil {
+		log.Fatal(runErr)
+	}
 }
 
 // getEnvInt retrieves an integer value from the environment or returns a default value.
@@ -408,6 +1830,21 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvBool retrieves a boolean value from the environment or returns a default value.
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
+
+// getEnvURLRewrites returns the URL rewrite rules from URL_REWRITES, separated by whitespace.
+func getEnvURLRewrites() []string {
+	return strings.Fields(os.Getenv("URL_REWRITES"))
+}
+
 // getEnvString retrieves a string value from the environment or returns a default value.
 func getEnvString(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -415,3 +1852,15 @@ func getEnvString(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// defaultDatabasePath returns the database given by DATABASE_URL, e.g. a postgres:// URL, or
+// else by DATABASE_PATH.
+func defaultDatabasePath() string {
+	return getEnvString("DATABASE_URL", getEnvString("DATABASE_PATH", "./data/stobot.db"))
+}
+
+// getEnvEnvironment returns the bot environment from ENVIRONMENT, falling back to the older
+// STOBOT_ENVIRONMENT and then PROD.
+func getEnvEnvironment() string {
+	return getEnvString("ENVIRONMENT", getEnvString("STOBOT_ENVIRONMENT", "PROD"))
+}
@@ -4,15 +4,29 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"syscall"
 	"testing"
+	"time"
 
 	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/logging"
+	"github.com/FracKenA/sto_news_discord_bot/internal/news"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
 	"github.com/FracKenA/sto_news_discord_bot/internal/types"
 
 	_ "github.com/mattn/go-sqlite3"
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spf13/cobra"
 )
 
 func TestMarkAllPostedFunctionExists(t *testing.T) {
@@ -122,6 +136,71 @@ func TestListChannelsCommand(t *testing.T) {
 	t.Log("ListChannels command functionality test passed")
 }
 
+func TestChannelState(t *testing.T) {
+	tests := []struct {
+		cfg      database.ChannelConfig
+		expected string
+	}{
+		{cfg: database.ChannelConfig{}, expected: "enabled"},
+		{cfg: database.ChannelConfig{PostFailures: 2}, expected: "enabled, 2 failed posts"},
+		{cfg: database.ChannelConfig{Disabled: true, PostFailures: 5}, expected: "disabled"},
+		{cfg: database.ChannelConfig{Paused: true}, expected: "paused, skipping news"},
+		{cfg: database.ChannelConfig{Paused: true, PauseHold: true, PostFailures: 1}, expected: "paused, holding news"},
+		{cfg: database.ChannelConfig{Disabled: true, Paused: true}, expected: "disabled"},
+	}
+
+	for _, tt := range tests {
+		if state := channelState(tt.cfg); state != tt.expected {
+			t.Errorf("Expected state %q for %+v, got %q", tt.expected, tt.cfg, state)
+		}
+	}
+}
+
+func TestMoveChannel(t *testing.T) {
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	if err := database.AddChannel(bot, "channel-old"); err != nil {
+		t.Fatalf("Failed to add channel: %v", err)
+	}
+	if err := database.UpdateChannelPlatforms(bot, "channel-old", []string{"ps"}); err != nil {
+		t.Fatalf("Failed to update platforms: %v", err)
+	}
+	if err := database.MarkNewsAsPosted(bot, 1, "channel-old"); err != nil {
+		t.Fatalf("Failed to mark news as posted: %v", err)
+	}
+
+	fake := testhelpers.NewFakeDiscord(t)
+	bot.Session = fake.Session()
+	fake.Handle("GET", "/channels/channel-gone", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Unknown Channel", "code": 10003})
+	})
+	fake.Handle("GET", "/channels/channel-new", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "channel-new", "guild_id": "guild-1"})
+	})
+
+	if _, err := moveChannel(bot, "channel-old", "channel-gone"); err == nil {
+		t.Fatal("Expected moving to a missing channel to fail")
+	}
+
+	moved, err := moveChannel(bot, "channel-old", "channel-new")
+	if err != nil || moved != 1 {
+		t.Fatalf("Expected 1 post moved, got %d (%v)", moved, err)
+	}
+	cfg, err := database.GetChannelConfig(bot, "channel-new")
+	if err != nil || cfg == nil || cfg.GuildID != "guild-1" || len(cfg.Platforms) != 1 || cfg.Platforms[0] != "ps" {
+		t.Errorf("Expected the settings to move and the server to be recorded, got %+v (%v)", cfg, err)
+	}
+	if posted, _ := database.IsNewsPosted(bot, 1, "channel-new"); !posted {
+		t.Error("Expected the posting history to move")
+	}
+
+	// Without a session the new channel is not looked up
+	bot.Session = nil
+	if _, err := moveChannel(bot, "channel-new", "channel-unchecked"); err != nil {
+		t.Errorf("Expected the move without a session to succeed, got %v", err)
+	}
+}
+
 func TestMarkAllPostedCommand(t *testing.T) {
 	// Create temporary directory for test
 	tempDir := t.TempDir()
@@ -239,6 +318,119 @@ func TestBotConfiguration(t *testing.T) {
 	t.Log("Bot configuration test passed")
 }
 
+func TestPollTags(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected []string
+	}{
+		{"star-trek-online,patch-notes,events,dev-blogs", []string{"star-trek-online", "patch-notes", "events", "dev-blogs"}},
+		{" Patch-Notes , ,events,patch-notes", []string{"patch-notes", "events"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		cmd := &cobra.Command{}
+		cmd.Flags().String("poll-tags", tt.spec, "")
+		if tags := pollTags(cmd); !slices.Equal(tags, tt.expected) {
+			t.Errorf("pollTags(%q) = %v, want %v", tt.spec, tags, tt.expected)
+		}
+	}
+}
+
+func TestBotConfigPostConcurrency(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.Flags().Int("post-concurrency", news.DefaultPostConcurrency, "")
+	if err := cmd.Flags().Parse([]string{"--post-concurrency", "7"}); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	config, err := botConfig(cmd)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if config.PostConcurrency != 7 {
+		t.Errorf("Expected a post concurrency of 7, got %d", config.PostConcurrency)
+	}
+}
+
+func TestBotConfigGameStatusURL(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("game-status-url", "", "")
+	if err := cmd.Flags().Parse([]string{"--game-status-url", "http://localhost:8080/status"}); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	config, err := botConfig(cmd)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if config.GameStatusURL != "http://localhost:8080/status" {
+		t.Errorf("Expected the status endpoint override, got %q", config.GameStatusURL)
+	}
+}
+
+func TestPollOnceConfig(t *testing.T) {
+	t.Setenv("FRESH_SECONDS", "")
+	t.Setenv("POST_CONCURRENCY", "")
+	cmd := newPollOnceCmd()
+	if err := cmd.Flags().Parse(nil); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	config, err := botConfig(cmd)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	// Subscribers are only sent news that is still fresh
+	if config.FreshSeconds != 600 || config.PostConcurrency != news.DefaultPostConcurrency {
+		t.Errorf("Expected the default fresh seconds and post concurrency, got %d and %d", config.FreshSeconds, config.PostConcurrency)
+	}
+
+	cmd = newPollOnceCmd()
+	if err := cmd.Flags().Parse([]string{"--fresh-seconds", "1200", "--api-base-url", "http://localhost:8080/news"}); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if config, err = botConfig(cmd); err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if config.FreshSeconds != 1200 || config.BaseURL != "http://localhost:8080/news" {
+		t.Errorf("Expected the flags in the config, got fresh seconds %d and API %q", config.FreshSeconds, config.BaseURL)
+	}
+}
+
+func TestConfigureLogging(t *testing.T) {
+	formatter, level := log.StandardLogger().Formatter, log.GetLevel()
+	t.Cleanup(func() {
+		log.StandardLogger().ReplaceHooks(make(log.LevelHooks))
+		_ = logging.Configure(logging.Levels{Default: level}, "")
+		log.SetFormatter(formatter)
+	})
+	hook := test.NewGlobal()
+
+	cmd := &cobra.Command{}
+	cmd.Flags().String("log-level", "warn,news=debug", "")
+	cmd.Flags().String("log-format", "text", "")
+	configureLogging(cmd, log.InfoLevel)
+
+	if log.GetLevel() != log.WarnLevel {
+		t.Errorf("Expected the warn level, got %v", log.GetLevel())
+	}
+	if _, ok := log.StandardLogger().Formatter.(*log.TextFormatter); !ok {
+		t.Errorf("Expected the text format, got %T", log.StandardLogger().Formatter)
+	}
+	logging.Logger(logging.News).Debug("Fetching news")
+	logging.Logger(logging.Discord).Info("Command received")
+	if entries := hook.AllEntries(); len(entries) != 1 || entries[0].Data["component"] != logging.News {
+		t.Errorf("Expected only the news debug line, got %+v", entries)
+	}
+
+	// Without a level, the command's default applies
+	cmd = &cobra.Command{}
+	configureLogging(cmd, log.DebugLevel)
+	if log.GetLevel() != log.DebugLevel {
+		t.Errorf("Expected the default debug level, got %v", log.GetLevel())
+	}
+	if _, ok := log.StandardLogger().Formatter.(*log.JSONFormatter); !ok {
+		t.Errorf("Expected the JSON format by default, got %T", log.StandardLogger().Formatter)
+	}
+}
+
 func TestEnvironmentVariables(t *testing.T) {
 	// Test environment variable handling
 	testCases := map[string]string{
@@ -377,3 +569,447 @@ func TestSignalHandling(t *testing.T) {
 
 	t.Log("Signal handling concepts test passed")
 }
+
+func TestCheckPopulateChannels(t *testing.T) {
+	tests := []struct {
+		name            string
+		channels        []string
+		requireChannels bool
+		expectError     bool
+		expectWarning   bool
+	}{
+		{"channels registered", []string{"123"}, false, false, false},
+		{"channels registered and required", []string{"123"}, true, false, false},
+		{"no channels warns", nil, false, false, true},
+		{"no channels aborts when required", nil, true, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			log.SetOutput(&output)
+			defer log.SetOutput(os.Stderr)
+
+			err := checkPopulateChannels(tt.channels, tt.requireChannels)
+			if tt.expectError && err == nil {
+				t.Error("Expected an error, got nil")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+
+			warned := strings.Contains(output.String(), "No registered channels found")
+			if warned != tt.expectWarning {
+				t.Errorf("Expected warning %v, got %v (output: %q)", tt.expectWarning, warned, output.String())
+			}
+		})
+	}
+}
+
+func TestPopulateNewsItemsMarkers(t *testing.T) {
+	db, err := database.InitDatabase(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	bot := &types.Bot{DB: db, Config: &types.Config{}}
+	newsItems := []types.NewsItem{
+		{ID: 1, Title: "News 1", Summary: "Summary 1"},
+		{ID: 2, Title: "News 2", Summary: "Summary 2"},
+		{ID: 3, Title: "News 3", Summary: "Summary 3"},
+	}
+
+	// With no channels, news is cached but no markers are written
+	cached, markers, err := populateNewsItems(bot, newsItems, nil)
+	if err != nil {
+		t.Fatalf("Failed to populate news items: %v", err)
+	}
+	if cached != 3 || markers != 0 {
+		t.Errorf("Expected 3 cached and 0 markers without channels, got %d and %d", cached, markers)
+	}
+
+	// Once channels exist, every (news × channel) pair gets a marker
+	if _, err := db.Exec("INSERT INTO channels (id) VALUES ('111'), ('222')"); err != nil {
+		t.Fatalf("Failed to insert channels: %v", err)
+	}
+	cached, markers, err = populateNewsItems(bot, newsItems, []string{"111", "222"})
+	if err != nil {
+		t.Fatalf("Failed to populate news items: %v", err)
+	}
+	if cached != 3 || markers != 6 {
+		t.Errorf("Expected 3 cached and 6 markers (3 news × 2 channels), got %d and %d", cached, markers)
+	}
+
+	// Re-populating the same news writes no new markers
+	_, markers, err = populateNewsItems(bot, newsItems, []string{"111", "222"})
+	if err != nil {
+		t.Fatalf("Failed to populate news items: %v", err)
+	}
+	if markers != 0 {
+		t.Errorf("Expected 0 new markers on re-populate, got %d", markers)
+	}
+}
+
+func TestPopulateTags(t *testing.T) {
+	tests := []struct {
+		name      string
+		dryRun    bool
+		fetchErr  error
+		processed int
+		cached    int
+		markers   int
+	}{
+		{"populate", false, nil, 3, 3, 3},
+		{"dry run", true, nil, 3, 0, 0},
+		{"fetch failure", false, errors.New("source unavailable"), 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := database.InitDatabase(filepath.Join(t.TempDir(), "test.db"))
+			if err != nil {
+				t.Fatalf("Failed to initialize database: %v", err)
+			}
+			defer db.Close()
+			if _, err := db.Exec("INSERT INTO channels (id) VALUES ('111')"); err != nil {
+				t.Fatalf("Failed to insert channels: %v", err)
+			}
+
+			fetcher := testhelpers.NewFakeNewsFetcher(
+				types.NewsItem{ID: 1, Title: "Season Update", Tags: []string{"star-trek-online"}},
+				types.NewsItem{ID: 2, Title: "Patch Notes", Tags: []string{"patch-notes"}},
+				types.NewsItem{ID: 3, Title: "Dev Blog", Tags: []string{"star-trek-online", "dev-blogs"}},
+			)
+			fetcher.Err = tt.fetchErr
+			bot := &types.Bot{DB: db, Config: &types.Config{}, Fetcher: fetcher}
+
+			summary := populateTags(context.Background(), bot, []string{"star-trek-online", "patch-notes"}, 100, []string{"111"}, populateOptions{DryRun: tt.dryRun})
+
+			expected := populateSummary{Processed: tt.processed, Cached: tt.cached, Markers: tt.markers}
+			if summary != expected {
+				t.Errorf("Expected %+v, got %+v", expected, summary)
+			}
+			if calls := fetcher.Calls(); len(calls) != 2 || calls[0].Tag != "star-trek-online" || calls[1].Tag != "patch-notes" {
+				t.Errorf("Expected a fetch per tag, got %+v", calls)
+			}
+		})
+	}
+}
+
+// populateTestNews returns count news items with the star-trek-online tag, newest first.
+func populateTestNews(count int) []types.NewsItem {
+	var newsItems []types.NewsItem
+	for i := range count {
+		newsItems = append(newsItems, types.NewsItem{ID: int64(count - i), Title: fmt.Sprintf("News %d", count-i), Tags: []string{"star-trek-online"}})
+	}
+	return newsItems
+}
+
+func TestPopulateTagResume(t *testing.T) {
+	db, err := database.InitDatabase(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	// The API fails after the first two pages, as if the run died midway
+	fetcher := testhelpers.NewFakeNewsFetcher(populateTestNews(250)...)
+	pages := 0
+	fetcher.Fetch = func(tag string, count int, options types.FetchOptions) ([]types.NewsItem, error) {
+		if pages++; pages > 2 {
+			return nil, errors.New("connection reset")
+		}
+		return testhelpers.NewFakeNewsFetcher(fetcher.News...).FetchNews(tag, count, options)
+	}
+	bot := &types.Bot{DB: db, Config: &types.Config{}, Fetcher: fetcher}
+
+	summary, err := populateTag(context.Background(), bot, "star-trek-online", 250, nil, populateOptions{}, newPopulateProgress(250))
+	if err == nil {
+		t.Fatal("Expected the interrupted run to fail")
+	}
+	if summary.Processed != 200 || summary.Cached != 200 {
+		t.Errorf("Expected 200 news items populated before the failure, got %+v", summary)
+	}
+	state, err := database.GetPopulateState(bot, "star-trek-online")
+	if err != nil {
+		t.Fatalf("Failed to get populate state: %v", err)
+	}
+	if state.LastOffset != 200 || state.Completed {
+		t.Errorf("Expected a checkpoint after 200 items, got %+v", state)
+	}
+
+	// The next run resumes after the checkpoint instead of fetching everything again
+	fetcher.Fetch = nil
+	summary, err = populateTag(context.Background(), bot, "star-trek-online", 250, nil, populateOptions{}, newPopulateProgress(250))
+	if err != nil {
+		t.Fatalf("Failed to resume: %v", err)
+	}
+	if summary.Processed != 50 {
+		t.Errorf("Expected the remaining 50 news items to be populated, got %+v", summary)
+	}
+	calls := fetcher.Calls()
+	if last := calls[len(calls)-1]; last.Options.Offset != 200 || last.Count != 50 {
+		t.Errorf("Expected the resumed fetch to start at offset 200, got %+v", last)
+	}
+	if state, _ = database.GetPopulateState(bot, "star-trek-online"); state.LastOffset != 250 || !state.Completed {
+		t.Errorf("Expected the tag to be completed, got %+v", state)
+	}
+	cached, err := database.CountCachedNews(bot)
+	if err != nil {
+		t.Fatalf("Failed to count cached news: %v", err)
+	}
+	if cached != 250 {
+		t.Errorf("Expected 250 cached news items, got %d", cached)
+	}
+
+	// A completed tag is skipped, unless forced
+	before := len(fetcher.Calls())
+	summary, err = populateTag(context.Background(), bot, "star-trek-online", 250, nil, populateOptions{}, newPopulateProgress(250))
+	if err != nil || summary.Skipped != 1 || len(fetcher.Calls()) != before {
+		t.Errorf("Expected the completed tag to be skipped without fetching, got %+v (%v)", summary, err)
+	}
+	summary, err = populateTag(context.Background(), bot, "star-trek-online", 250, nil, populateOptions{Force: true}, newPopulateProgress(250))
+	if err != nil || summary.Processed != 250 {
+		t.Errorf("Expected --force to populate the tag again, got %+v (%v)", summary, err)
+	}
+}
+
+func TestPopulateTagRunsOutOfNews(t *testing.T) {
+	db, err := database.InitDatabase(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+	fetcher := testhelpers.NewFakeNewsFetcher(populateTestNews(120)...)
+	bot := &types.Bot{DB: db, Config: &types.Config{}, Fetcher: fetcher}
+
+	progress := newPopulateProgress(500)
+	summary, err := populateTag(context.Background(), bot, "star-trek-online", 500, nil, populateOptions{}, progress)
+	if err != nil {
+		t.Fatalf("Failed to populate: %v", err)
+	}
+	if summary.Processed != 120 || len(fetcher.Calls()) != 3 {
+		t.Errorf("Expected 120 news items in 3 fetches, got %+v in %d", summary, len(fetcher.Calls()))
+	}
+	if state, _ := database.GetPopulateState(bot, "star-trek-online"); !state.Completed {
+		t.Errorf("Expected the tag to be completed once the API ran out of news, got %+v", state)
+	}
+	if !strings.Contains(progress.String(), "500/500 news items (100%)") {
+		t.Errorf("Expected the missing news to count as done, got %q", progress)
+	}
+}
+
+func TestPopulateTagDryRunSavesNothing(t *testing.T) {
+	db, err := database.InitDatabase(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+	bot := &types.Bot{DB: db, Config: &types.Config{}, Fetcher: testhelpers.NewFakeNewsFetcher(populateTestNews(10)...)}
+
+	summary, err := populateTag(context.Background(), bot, "star-trek-online", 10, nil, populateOptions{DryRun: true}, newPopulateProgress(10))
+	if err != nil || summary.Processed != 10 || summary.Cached != 0 {
+		t.Errorf("Expected 10 news items processed and none cached, got %+v (%v)", summary, err)
+	}
+	if state, _ := database.GetPopulateState(bot, "star-trek-online"); state.LastOffset != 0 || state.Completed {
+		t.Errorf("Expected no checkpoint in a dry run, got %+v", state)
+	}
+}
+
+func TestPopulateProgress(t *testing.T) {
+	start := time.Date(2024, 6, 11, 12, 0, 0, 0, time.UTC)
+	progress := &populateProgress{total: 1000, start: start, now: func() time.Time { return start }}
+	if line := progress.String(); line != "Progress: 0/1000 news items (0%), 0.0 items/sec, ETA unknown" {
+		t.Errorf("Unexpected progress line before any page: %q", line)
+	}
+
+	// 200 items resumed from an earlier run do not count towards the rate
+	progress.skip(200)
+	progress.advance(200, 200)
+	progress.now = func() time.Time { return start.Add(20 * time.Second) }
+	if line := progress.String(); line != "Progress: 400/1000 news items (40%), 10.0 items/sec, ETA 1m0s" {
+		t.Errorf("Unexpected progress line: %q", line)
+	}
+}
+
+func TestResolveBackup(t *testing.T) {
+	backups := []string{"stobot.db.pre-migrate-0-20250611T180400", "stobot.db.pre-migrate-0-20250611T180300"}
+
+	tests := []struct {
+		name        string
+		arg         string
+		expected    string
+		expectError bool
+	}{
+		{"first by number", "1", backups[0], false},
+		{"last by number", "2", backups[1], false},
+		{"number out of range", "3", "", true},
+		{"zero", "0", "", true},
+		{"path", "/data/backup.db", "/data/backup.db", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveBackup(tt.arg, backups)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected an error, got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to resolve backup: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPollOnceExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		summary  news.PollCycleSummary
+		err      error
+		expected int
+	}{
+		{"success", news.PollCycleSummary{Channels: 2, Posted: 3}, nil, pollOnceExitOK},
+		{"nothing to post", news.PollCycleSummary{}, nil, pollOnceExitOK},
+		{"partial failure", news.PollCycleSummary{Channels: 2, Posted: 1, Failed: 1}, nil, pollOnceExitPartial},
+		{"cycle failed", news.PollCycleSummary{}, errors.New("failed to fetch news"), pollOnceExitFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pollOnceExitCode(tt.summary, tt.err); got != tt.expected {
+				t.Errorf("Expected exit code %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestWriteChannelsExport(t *testing.T) {
+	tempDir := t.TempDir()
+	db, err := database.InitDatabase(filepath.Join(tempDir, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	bot := &types.Bot{DB: db}
+	if err := database.AddChannelWithEnvironment(bot, "123456789", "DEV"); err != nil {
+		t.Fatalf("Failed to add channel: %v", err)
+	}
+
+	output := filepath.Join(tempDir, "channels.txt")
+	count, err := writeChannelsExport(bot, output, "")
+	if err != nil {
+		t.Fatalf("Failed to export channels: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 exported channel, got %d", count)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	if string(data) != "channel:123456789|pc,xbox,ps|DEV\n" {
+		t.Errorf("Unexpected export contents: %q", data)
+	}
+
+	if _, err := writeChannelsExport(bot, filepath.Join(tempDir, "missing", "channels.txt"), ""); err == nil {
+		t.Error("Expected an error for an unwritable output path")
+	}
+}
+
+func TestParseChannelIDs(t *testing.T) {
+	tests := []struct {
+		spec        string
+		expected    []string
+		expectError bool
+	}{
+		{spec: "all", expected: nil},
+		{spec: " ALL ", expected: nil},
+		{spec: "123, 456,123,", expected: []string{"123", "456"}},
+		{spec: "", expectError: true},
+		{spec: " , ", expectError: true},
+	}
+	for _, tt := range tests {
+		channelIDs, err := parseChannelIDs(tt.spec)
+		if tt.expectError {
+			if err == nil {
+				t.Errorf("parseChannelIDs(%q): expected an error, got %v", tt.spec, channelIDs)
+			}
+			continue
+		}
+		if err != nil || !slices.Equal(channelIDs, tt.expected) {
+			t.Errorf("parseChannelIDs(%q) = %v (%v), want %v", tt.spec, channelIDs, err, tt.expected)
+		}
+	}
+}
+
+func TestSummarizePlatformsResults(t *testing.T) {
+	platforms := []string{"pc", "ps"}
+	results := []database.ChannelPlatformsResult{
+		{ChannelID: "1", Previous: []string{"pc", "xbox", "ps"}, Changed: true},
+		{ChannelID: "2", Previous: platforms},
+		{ChannelID: "3", Err: errors.New("channel 3 is not registered")},
+	}
+	if summary := summarizePlatformsResults(results); summary != "1 changed, 1 unchanged, 1 failed" {
+		t.Errorf("Unexpected summary %q", summary)
+	}
+	if change := describePlatformsChange(results[0], platforms); change != "pc,xbox,ps -> pc,ps" {
+		t.Errorf("Unexpected change %q", change)
+	}
+	if change := describePlatformsChange(results[1], platforms); change != "pc,ps (unchanged)" {
+		t.Errorf("Unexpected change %q", change)
+	}
+}
+
+func TestNewsExportFilter(t *testing.T) {
+	tests := []struct {
+		name          string
+		since, until  string
+		expectedSince time.Time
+		expectedUntil time.Time
+		expectError   bool
+	}{
+		{name: "no dates"},
+		{
+			name: "dates", since: "2024-06-01", until: "2024-06-30",
+			expectedSince: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			expectedUntil: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "times", since: "2024-06-01T12:00:00Z", until: "2024-06-02T12:00:00+02:00",
+			expectedSince: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+			expectedUntil: time.Date(2024, 6, 2, 10, 0, 0, 0, time.UTC),
+		},
+		{name: "invalid date", since: "06/01/2024", expectError: true},
+		{name: "until before since", since: "2024-06-30", until: "2024-06-01", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := newsExportFilter("events", "pc", tt.since, tt.until)
+			if tt.expectError {
+				if err == nil {
+					t.Fatalf("Expected an error, got %+v", filter)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to build filter: %v", err)
+			}
+			if filter.Tag != "events" || filter.Platform != "pc" {
+				t.Errorf("Expected tag events and platform pc, got %+v", filter)
+			}
+			if !filter.Since.Equal(tt.expectedSince) || !filter.Until.Equal(tt.expectedUntil) {
+				t.Errorf("Expected %v to %v, got %v to %v", tt.expectedSince, tt.expectedUntil, filter.Since, filter.Until)
+			}
+		})
+	}
+}
@@ -0,0 +1,360 @@
+// Package app runs STOBot: it opens the database and Discord session, wires up the slash command
+// handlers and runs the startup catch-up, news poller and digest scheduler until stopped.
+//
+// The stobot command is a thin CLI around it; other Go programs and integration tests can run the
+// bot the same way (programs outside this module through the pkg/stobot package):
+//
+//	a, err := app.New(config, app.Options{Version: "1.2.3"})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer a.Shutdown()
+//	if err := a.Run(ctx); err != nil {
+//	    log.Fatal(err)
+//	}
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/discord"
+	"github.com/FracKenA/sto_news_discord_bot/internal/feed"
+	"github.com/FracKenA/sto_news_discord_bot/internal/metrics"
+	"github.com/FracKenA/sto_news_discord_bot/internal/news"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultShutdownTimeout is how long Run waits for in-flight news posts when the bot stops.
+const DefaultShutdownTimeout = 30 * time.Second
+
+// Options configure an App beyond the bot's Config.
+type Options struct {
+	// Version is the build version recorded alongside posted news.
+	Version string
+
+	// NoMigrationBackup skips backing up the database before pending migrations.
+	NoMigrationBackup bool
+
+	// MetricsAddr is the address Prometheus metrics and the health check are served on; empty
+	// serves none.
+	MetricsAddr string
+	// FeedAddr is the address the Atom news feeds are served on; empty serves none. It may equal
+	// MetricsAddr to share its listener.
+	FeedAddr string
+
+	// ShutdownTimeout is how long Run waits for in-flight news posts when the bot stops; 0 uses
+	// DefaultShutdownTimeout.
+	ShutdownTimeout time.Duration
+
+	// Session is the Discord session to use instead of one connecting with Config.DiscordToken.
+	// The App neither opens nor closes it, so it may be a REST-only session, e.g. in tests.
+	Session *discordgo.Session
+	// DB is the database to use instead of opening Config.DatabasePath. The App does not close it.
+	DB *sql.DB
+	// Fetcher fetches news instead of the news API in Config; nil uses the API.
+	Fetcher types.NewsFetcher
+}
+
+// App is a running STOBot: its database, Discord session and background posting.
+type App struct {
+	bot     *types.Bot
+	options Options
+
+	ownsSession bool
+	ownsDB      bool
+
+	servers []*http.Server
+	stop    chan struct{} // stop is closed by Shutdown to stop Run.
+
+	mu      sync.Mutex
+	runDone chan struct{} // runDone is closed when Run returns; nil until Run is called.
+	closed  bool          // closed is set once Shutdown was called.
+}
+
+// New validates config and prepares a bot: it opens the database and creates the Discord
+// session, unless options provide them, and registers the Discord event handlers. Nothing is
+// posted until Run is called. Call Shutdown to release what New opened, even if Run is not.
+//
+// Example:
+//
+//	a, err := app.New(config, app.Options{MetricsAddr: ":9090"})
+func New(config *types.Config, options Options) (*App, error) {
+	if config == nil {
+		return nil, errors.New("config is required")
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %v", err)
+	}
+
+	a := &App{options: options, stop: make(chan struct{})}
+
+	db := options.DB
+	if db == nil {
+		initOptions := database.DefaultInitOptions()
+		initOptions.MigrationBackup = !options.NoMigrationBackup
+		var err error
+		if db, err = database.InitDatabaseWithOptions(config.DatabasePath, initOptions); err != nil {
+			return nil, fmt.Errorf("failed to initialize database: %v", err)
+		}
+		a.ownsDB = true
+	}
+
+	session := options.Session
+	if session == nil {
+		var err error
+		if session, err = discordgo.New("Bot " + config.DiscordToken); err != nil {
+			a.closeDB(db)
+			return nil, fmt.Errorf("failed to create Discord session: %v", err)
+		}
+		session.Identify.Intents = discordgo.IntentsGuildMessages | discordgo.IntentsDirectMessages
+		a.ownsSession = true
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		log.Warnf("Failed to get hostname: %v", err)
+	}
+
+	a.bot = &types.Bot{
+		Session:    session,
+		DB:         db,
+		Config:     config,
+		InstanceID: types.BuildInstanceID(config.Environment, hostname, 0),
+		Version:    options.Version,
+		Fetcher:    options.Fetcher,
+	}
+
+	// News cached before content was stored is skipped by content searches and /stobot_read
+	if missing, err := database.CountNewsWithoutContent(a.bot); err != nil {
+		log.Warnf("Failed to count cached news without content: %v", err)
+	} else if missing > 0 {
+		log.Warnf("%d cached news items have no content; run backfill-content to fetch it", missing)
+	}
+
+	session.AddHandler(discord.Ready(a.bot))
+	session.AddHandler(discord.InteractionCreate(a.bot))
+	return a, nil
+}
+
+// Bot returns the bot the App runs.
+func (a *App) Bot() *types.Bot {
+	return a.bot
+}
+
+// Run connects to Discord, unless Options.Session was given, starts the metrics and feed servers
+// and posts news until ctx is cancelled or Shutdown is called. Posts left pending by the last run
+// are reconciled first, then the startup catch-up, news poller and digest scheduler run in the
+// background. When stopped, Run waits up to Options.ShutdownTimeout for in-flight posts to be
+// marked as posted before returning.
+//
+// With Config.DryRun set, Run neither connects to Discord nor reconciles, catches up or sends
+// digests: only the news poller runs, logging the posts it would send.
+func (a *App) Run(ctx context.Context) error {
+	a.mu.Lock()
+	if a.closed || a.runDone != nil {
+		a.mu.Unlock()
+		return errors.New("app is already running or was shut down")
+	}
+	done := make(chan struct{})
+	a.runDone = done
+	a.mu.Unlock()
+	defer close(done)
+
+	dryRun := a.bot.Config.DryRun
+	if dryRun {
+		log.Info("Running dry: news posts are logged, nothing is sent to Discord")
+	} else if a.ownsSession {
+		if err := a.bot.Session.Open(); err != nil {
+			return fmt.Errorf("failed to open Discord connection: %v", err)
+		}
+	}
+	a.startServers()
+
+	// Background posting stops when the bot stops
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var wg sync.WaitGroup
+
+	// Posts interrupted by the last shutdown are resolved before anything is posted; a dry run
+	// leaves none pending
+	if !dryRun {
+		if pending, err := news.ReconcilePendingPosts(ctx, a.bot); err != nil {
+			log.Errorf("Failed to reconcile pending posts: %v", err)
+		} else if pending > 0 {
+			log.Infof("Reconciled %d posts left pending by the last run", pending)
+		}
+	}
+
+	// Catch up on unposted news at startup
+	if days := a.bot.Config.CatchUpDays; dryRun {
+		log.Info("[catchup] Startup catch-up skipped in a dry run")
+	} else if days > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			posted, err := news.CatchUpUnpostedNews(ctx, a.bot, days)
+			if err != nil && !errors.Is(err, context.Canceled) {
+				log.Errorf("[catchup] Catch-up failed: %v", err)
+			}
+			log.Infof("[catchup] Posted %d news items from the last %d days", posted, days)
+		}()
+	} else {
+		log.Info("[catchup] Startup catch-up disabled")
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		news.NewsPoller(ctx, a.bot)
+	}()
+
+	// Post weekly digests to channels in digest mode
+	if !dryRun {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			news.DigestScheduler(ctx, a.bot)
+		}()
+	}
+
+	// Forget expired search cooldowns
+	go discord.RunCommandCooldownCleanup(ctx, a.bot)
+
+	select {
+	case <-ctx.Done():
+	case <-a.stop:
+	}
+	log.Info("Gracefully shutting down...")
+
+	// Let in-flight posts finish and be marked as posted before the session and database close
+	cancel()
+	timeout := a.options.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
+	if !WaitForShutdown(&wg, timeout) {
+		log.Warnf("News posting did not stop within %v, closing anyway", timeout)
+	}
+	return nil
+}
+
+// Shutdown stops Run, waits for it to return and closes the servers, and the session and
+// database unless they were given in Options. It is safe to call more than once, and without Run.
+func (a *App) Shutdown() error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil
+	}
+	a.closed = true
+	done := a.runDone
+	a.mu.Unlock()
+
+	close(a.stop)
+	if done != nil {
+		<-done
+	}
+
+	for _, server := range a.servers {
+		server.Close()
+	}
+	var err error
+	if a.ownsSession && !a.bot.Config.DryRun {
+		if closeErr := a.bot.Session.Close(); closeErr != nil {
+			err = fmt.Errorf("failed to close Discord session: %v", closeErr)
+		}
+	}
+	a.closeDB(a.bot.DB)
+	return err
+}
+
+// closeDB closes db if the App opened it.
+func (a *App) closeDB(db *sql.DB) {
+	if !a.ownsDB {
+		return
+	}
+	if err := db.Close(); err != nil {
+		log.Warnf("Failed to close database: %v", err)
+	}
+}
+
+// startServers starts the metrics and feed servers configured in Options, closed by Shutdown.
+func (a *App) startServers() {
+	metricsAddr, feedAddr := a.options.MetricsAddr, a.options.FeedAddr
+	var feedHandler *feed.Handler
+	if feedAddr != "" {
+		feedHandler = feed.NewHandler(a.bot)
+	}
+	if metricsAddr != "" {
+		// The feed shares the metrics listener when both are on the same address
+		var routes map[string]http.Handler
+		if feedHandler != nil && feedAddr == metricsAddr {
+			routes = feedHandler.Routes()
+		}
+		a.serve("Metrics", metrics.NewServer(metricsAddr, HealthCheck(a.bot), routes))
+		log.Infof("Serving metrics on %s", metricsAddr)
+	}
+	if feedHandler != nil {
+		if feedAddr != metricsAddr {
+			a.serve("Feed", feed.NewServer(feedAddr, feedHandler))
+		}
+		log.Infof("Serving news feeds on %s/feed.xml", feedAddr)
+	}
+}
+
+// serve runs server in the background until Shutdown closes it.
+func (a *App) serve(name string, server *http.Server) {
+	a.servers = append(a.servers, server)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("%s server failed: %v", name, err)
+		}
+	}()
+}
+
+// HealthCheck returns a check that fails when the database does not respond or the
+// Discord session is not connected. A dry run does not connect, so only its database is checked.
+func HealthCheck(b *types.Bot) func() error {
+	return func() error {
+		if err := b.DB.Ping(); err != nil {
+			return fmt.Errorf("database: %v", err)
+		}
+		if b.Config != nil && b.Config.DryRun {
+			return nil
+		}
+		b.Session.RLock()
+		ready := b.Session.DataReady
+		b.Session.RUnlock()
+		if !ready {
+			return errors.New("discord session is not connected")
+		}
+		return nil
+	}
+}
+
+// WaitForShutdown waits for wg, giving up after timeout. It reports whether wg finished.
+func WaitForShutdown(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
@@ -0,0 +1,218 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// testConfig returns a valid config polling every second, with its database in a test directory.
+func testConfig(t *testing.T) *types.Config {
+	t.Helper()
+	return &types.Config{
+		DiscordToken: "test_token",
+		PollPeriod:   1,
+		PollCount:    10,
+		FreshSeconds: 3600,
+		MsgCount:     10,
+		DatabasePath: filepath.Join(t.TempDir(), "stobot.db"),
+		Environment:  "PROD",
+	}
+}
+
+func TestAppPostsNews(t *testing.T) {
+	fake := testhelpers.NewFakeDiscord(t)
+	fetcher := testhelpers.NewFakeNewsFetcher(types.NewsItem{
+		ID:        1,
+		Title:     "Patch Notes",
+		Summary:   "Fixes and improvements.",
+		Tags:      []string{"patch-notes"},
+		Platforms: []string{"pc"},
+		Updated:   time.Now().Add(-time.Minute),
+	})
+
+	stobot, err := New(testConfig(t), Options{NoMigrationBackup: true, Session: fake.Session(), Fetcher: fetcher, ShutdownTimeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("Failed to create app: %v", err)
+	}
+	defer stobot.Shutdown()
+	if err := database.AddChannel(stobot.Bot(), "channel-a"); err != nil {
+		t.Fatalf("Failed to register channel: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runErr := make(chan error, 1)
+	go func() { runErr <- stobot.Run(ctx) }()
+
+	// The first poll cycle posts the news to the registered channel
+	deadline := time.Now().Add(10 * time.Second)
+	var posts []testhelpers.FakeDiscordRequest
+	for len(posts) == 0 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+		posts = fake.RequestsTo("POST", "/channels/channel-a/messages")
+	}
+	if len(posts) != 1 {
+		t.Fatalf("Expected 1 post to channel-a, got %d", len(posts))
+	}
+	var message discordgo.MessageSend
+	if err := json.Unmarshal(posts[0].Body, &message); err != nil {
+		t.Fatalf("Failed to decode post: %v", err)
+	}
+	if len(message.Embeds) != 1 || message.Embeds[0].Title != "Patch Notes" {
+		t.Errorf("Expected the patch notes, got %+v", message.Embeds)
+	}
+
+	if err := stobot.Shutdown(); err != nil {
+		t.Errorf("Failed to shut down: %v", err)
+	}
+	select {
+	case err := <-runErr:
+		if err != nil {
+			t.Errorf("Expected Run to stop cleanly, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Expected Run to return after Shutdown")
+	}
+
+	// The post was recorded before the database closed, and the app cannot run again
+	if err := stobot.Run(context.Background()); err == nil {
+		t.Error("Expected a shut down app not to run")
+	}
+	if err := stobot.Shutdown(); err != nil {
+		t.Errorf("Expected a second Shutdown to do nothing, got %v", err)
+	}
+	db, err := database.InitDatabase(stobot.Bot().Config.DatabasePath)
+	if err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	defer db.Close()
+	posted, err := database.IsNewsPosted(&types.Bot{DB: db}, 1, "channel-a")
+	if err != nil || !posted {
+		t.Errorf("Expected the news to be marked as posted, got %v (%v)", posted, err)
+	}
+}
+
+func TestAppDryRun(t *testing.T) {
+	fake := testhelpers.NewFakeDiscord(t)
+	fetcher := testhelpers.NewFakeNewsFetcher(types.NewsItem{
+		ID:        1,
+		Title:     "Patch Notes",
+		Summary:   "Fixes and improvements.",
+		Tags:      []string{"patch-notes"},
+		Platforms: []string{"pc"},
+		Updated:   time.Now().Add(-time.Minute),
+	})
+	config := testConfig(t)
+	config.DiscordToken = ""
+	config.DryRun = true
+
+	stobot, err := New(config, Options{NoMigrationBackup: true, Session: fake.Session(), Fetcher: fetcher, ShutdownTimeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("Failed to create app: %v", err)
+	}
+	defer stobot.Shutdown()
+	bot := stobot.Bot()
+	if err := database.AddChannel(bot, "channel-a"); err != nil {
+		t.Fatalf("Failed to register channel: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runErr := make(chan error, 1)
+	go func() { runErr <- stobot.Run(ctx) }()
+
+	// The first poll cycle records the news as posted by the dry run
+	deadline := time.Now().Add(10 * time.Second)
+	posted := false
+	for !posted && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+		posted, _ = database.IsNewsDryRunPosted(bot, 1, "channel-a")
+	}
+	if !posted {
+		t.Fatal("Expected the news to be posted by the dry run")
+	}
+	if err := HealthCheck(bot)(); err != nil {
+		t.Errorf("Expected a dry run to be healthy without a connection, got %v", err)
+	}
+
+	cancel()
+	select {
+	case err := <-runErr:
+		if err != nil {
+			t.Errorf("Expected Run to stop cleanly, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Expected Run to return after cancel")
+	}
+	if requests := fake.Requests(); len(requests) != 0 {
+		t.Errorf("Expected no Discord requests in a dry run, got %d: %+v", len(requests), requests)
+	}
+	if posted, err := database.IsNewsPosted(bot, 1, "channel-a"); err != nil || posted {
+		t.Errorf("Expected the news not to be marked as posted, got %v (%v)", posted, err)
+	}
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	config := testConfig(t)
+	config.PollPeriod = 0
+	if _, err := New(config, Options{}); err == nil {
+		t.Error("Expected an invalid config to be rejected")
+	}
+	if _, err := New(nil, Options{}); err == nil {
+		t.Error("Expected a missing config to be rejected")
+	}
+}
+
+func TestWaitForShutdown(t *testing.T) {
+	var wg sync.WaitGroup
+	if !WaitForShutdown(&wg, time.Second) {
+		t.Error("Expected an idle wait group to finish")
+	}
+
+	wg.Add(1)
+	if WaitForShutdown(&wg, 10*time.Millisecond) {
+		t.Error("Expected a busy wait group to time out")
+	}
+
+	go wg.Done()
+	if !WaitForShutdown(&wg, time.Second) {
+		t.Error("Expected the wait group to finish once work is done")
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	db, err := database.InitDatabase(filepath.Join(t.TempDir(), "stobot.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	session, err := discordgo.New("Bot test_token")
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	check := HealthCheck(&types.Bot{Session: session, DB: db})
+
+	if err := check(); err == nil || !strings.Contains(err.Error(), "discord") {
+		t.Errorf("Expected a disconnected session to be unhealthy, got %v", err)
+	}
+
+	session.DataReady = true
+	if err := check(); err != nil {
+		t.Errorf("Expected a healthy bot, got %v", err)
+	}
+
+	db.Close()
+	if err := check(); err == nil || !strings.Contains(err.Error(), "database") {
+		t.Errorf("Expected a closed database to be unhealthy, got %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// CountNewsWithoutContent returns the number of cached news items without content, such as news
+// cached before the content column was added. Content searches and /stobot_read skip them.
+func CountNewsWithoutContent(b *types.Bot) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM news_cache WHERE content IS NULL OR content = ''`
+	if err := b.DB.QueryRow(query).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count news without content: %v", err)
+	}
+	return count, nil
+}
+
+// GetNewsIDsWithoutContent returns the IDs of up to limit cached news items without content,
+// newest first, starting below beforeID (0 starts from the newest item).
+func GetNewsIDsWithoutContent(b *types.Bot, beforeID int64, limit int) ([]int64, error) {
+	query := `SELECT id FROM news_cache
+			  WHERE (content IS NULL OR content = '') AND (? = 0 OR id < ?)
+			  ORDER BY id DESC
+			  LIMIT ?`
+	rows, err := b.DB.Query(query, beforeID, beforeID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query news without content: %v", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan news ID: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read news without content: %v", err)
+	}
+	return ids, nil
+}
+
+// UpdateNewsContent replaces the content of a cached news item.
+func UpdateNewsContent(b *types.Bot, newsID int64, content string) error {
+	result, err := b.DB.Exec(`UPDATE news_cache SET content = ? WHERE id = ?`, content, newsID)
+	if err != nil {
+		return fmt.Errorf("failed to update content of news %d: %v", newsID, err)
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if updated == 0 {
+		return fmt.Errorf("news %d is not cached", newsID)
+	}
+	return nil
+}
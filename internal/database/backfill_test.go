@@ -0,0 +1,64 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+func TestNewsWithoutContent(t *testing.T) {
+	db, err := InitDatabase(testDatabasePath(t, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+	bot := &types.Bot{DB: db}
+
+	if err := CacheNews(bot, []types.NewsItem{
+		{ID: 1, Title: "Season Update", Updated: time.Now()},
+		{ID: 2, Title: "Patch Notes", Content: "Fixes", Updated: time.Now()},
+		{ID: 3, Title: "Dev Blog", Updated: time.Now()},
+		{ID: 4, Title: "Event", Updated: time.Now()},
+	}); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+	// Rows cached before the content column have NULL content
+	if _, err := db.Exec(`UPDATE news_cache SET content = NULL WHERE id = 3`); err != nil {
+		t.Fatalf("Failed to clear content: %v", err)
+	}
+
+	if count, err := CountNewsWithoutContent(bot); err != nil || count != 3 {
+		t.Errorf("Expected 3 news items without content, got %d (%v)", count, err)
+	}
+
+	ids, err := GetNewsIDsWithoutContent(bot, 0, 2)
+	if err != nil {
+		t.Fatalf("Failed to get news without content: %v", err)
+	}
+	if !reflect.DeepEqual(ids, []int64{4, 3}) {
+		t.Errorf("Expected the newest news without content first, got %v", ids)
+	}
+	if ids, err = GetNewsIDsWithoutContent(bot, 3, 2); err != nil || !reflect.DeepEqual(ids, []int64{1}) {
+		t.Errorf("Expected the next page to hold news 1, got %v (%v)", ids, err)
+	}
+
+	if err := UpdateNewsContent(bot, 3, "Blog post"); err != nil {
+		t.Fatalf("Failed to update content: %v", err)
+	}
+	newsItem, err := GetCachedNewsByID(bot, 3)
+	if err != nil {
+		t.Fatalf("Failed to get cached news: %v", err)
+	}
+	if newsItem.Content != "Blog post" || newsItem.Title != "Dev Blog" {
+		t.Errorf("Expected only the content to be updated, got %+v", newsItem)
+	}
+	if count, _ := CountNewsWithoutContent(bot); count != 2 {
+		t.Errorf("Expected 2 news items without content after the update, got %d", count)
+	}
+
+	if err := UpdateNewsContent(bot, 5, "Missing"); err == nil {
+		t.Error("Expected an error for news that is not cached")
+	}
+}
@@ -0,0 +1,206 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// SchemaVersion is the schema version recorded once migrations succeed, in PRAGMA user_version
+// for SQLite databases.
+// Increment it whenever migrateDatabase gains a migration so existing databases are backed up
+// before it runs.
+const SchemaVersion = 26
+
+// maxMigrationBackups is the number of pre-migration backups kept per database.
+const maxMigrationBackups = 3
+
+// migrationBackupTimeFormat is the timestamp format used in pre-migration backup file names.
+const migrationBackupTimeFormat = "20060102T150405"
+
+// migrate applies schema migrations; tests replace it to simulate failing migrations.
+var migrate = migrateDatabase
+
+// InitOptions controls how a database is opened.
+type InitOptions struct {
+	MigrationBackup bool // MigrationBackup backs up an existing database before migrating it.
+
+	// JournalMode is the SQLite journal mode, e.g. WAL; empty keeps the database's current mode.
+	// Existing databases are converted when they are opened.
+	JournalMode string
+	BusyTimeout time.Duration // BusyTimeout is how long a statement waits for a lock held by another connection.
+	ForeignKeys bool          // ForeignKeys enforces foreign key constraints.
+
+	// MaxOpenConns limits the open connections (0 = unlimited). A single connection serializes
+	// the poller, catch-up and command handlers, so they never see "database is locked".
+	MaxOpenConns int
+}
+
+// DefaultInitOptions returns the options used by InitDatabase.
+func DefaultInitOptions() InitOptions {
+	return InitOptions{
+		MigrationBackup: true,
+		JournalMode:     "WAL",
+		BusyTimeout:     5 * time.Second,
+		ForeignKeys:     true,
+		MaxOpenConns:    1,
+	}
+}
+
+// backupBeforeMigration backs up an existing database whose schema version is older than
+// SchemaVersion to <dbPath>.pre-migrate-<version>-<timestamp> and rotates old backups.
+// It returns the backup path, or "" if no migration is pending.
+func backupBeforeMigration(db *sql.DB, dbPath string) (string, error) {
+	if dbPath == ":memory:" || strings.HasPrefix(dbPath, "file:") {
+		return "", nil
+	}
+
+	var tableCount int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type='table'`).Scan(&tableCount); err != nil {
+		return "", fmt.Errorf("failed to check for existing tables: %v", err)
+	}
+	if tableCount == 0 {
+		return "", nil // New database, nothing to protect
+	}
+
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return "", fmt.Errorf("failed to read schema version: %v", err)
+	}
+	if version >= SchemaVersion {
+		return "", nil
+	}
+
+	backupPath := fmt.Sprintf("%s.pre-migrate-%d-%s", dbPath, version, now().UTC().Format(migrationBackupTimeFormat))
+	if err := BackupDatabase(db, backupPath); err != nil {
+		return "", err
+	}
+	logger().Infof("Backed up database before migrating from schema version %d: %s", version, backupPath)
+
+	if err := rotateMigrationBackups(dbPath, maxMigrationBackups); err != nil {
+		logger().Warnf("Failed to rotate migration backups: %v", err)
+	}
+
+	return backupPath, nil
+}
+
+// setSchemaVersion records that all migrations up to SchemaVersion have been applied.
+func setSchemaVersion(db *sql.DB) error {
+	if _, err := db.Exec(fmt.Sprintf(dialectOf(db).writeSchemaVersion, SchemaVersion)); err != nil {
+		return fmt.Errorf("failed to set schema version: %v", err)
+	}
+	return nil
+}
+
+// BackupDatabase copies a database to destPath using SQLite's online backup API, which is
+// consistent even while the database is in WAL mode. An existing file at destPath is overwritten.
+func BackupDatabase(db *sql.DB, destPath string) error {
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %v", err)
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn interface{}) error {
+		src, ok := driverConn.(*sqlite3.SQLiteConn)
+		if !ok {
+			return fmt.Errorf("backup requires a sqlite3 connection, got %T", driverConn)
+		}
+		return backupConn(src, destPath)
+	})
+}
+
+// backupConn copies the main database of src into the database at destPath.
+func backupConn(src *sqlite3.SQLiteConn, destPath string) error {
+	destDB, err := sql.Open("sqlite3", destPath)
+	if err != nil {
+		return fmt.Errorf("failed to open backup destination: %v", err)
+	}
+	defer destDB.Close()
+
+	conn, err := destDB.Conn(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to open backup destination: %v", err)
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn interface{}) error {
+		dest := driverConn.(*sqlite3.SQLiteConn)
+
+		backup, err := dest.Backup("main", src, "main")
+		if err != nil {
+			return fmt.Errorf("failed to start backup: %v", err)
+		}
+		if _, err := backup.Step(-1); err != nil {
+			backup.Finish()
+			return fmt.Errorf("failed to copy database: %v", err)
+		}
+		if err := backup.Finish(); err != nil {
+			return fmt.Errorf("failed to finish backup: %v", err)
+		}
+		return nil
+	})
+}
+
+// ListMigrationBackups returns the pre-migration backups of a database, newest first.
+func ListMigrationBackups(dbPath string) ([]string, error) {
+	backups, err := filepath.Glob(dbPath + ".pre-migrate-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migration backups: %v", err)
+	}
+
+	// Names end in -<version>-<timestamp>; order by timestamp
+	timestamp := func(path string) string {
+		return path[strings.LastIndex(path, "-")+1:]
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return timestamp(backups[i]) > timestamp(backups[j])
+	})
+
+	return backups, nil
+}
+
+// rotateMigrationBackups removes all but the newest keep pre-migration backups.
+func rotateMigrationBackups(dbPath string, keep int) error {
+	backups, err := ListMigrationBackups(dbPath)
+	if err != nil {
+		return err
+	}
+
+	for i := keep; i < len(backups); i++ {
+		if err := os.Remove(backups[i]); err != nil {
+			return fmt.Errorf("failed to remove old backup %s: %v", backups[i], err)
+		}
+		logger().Infof("Removed old migration backup: %s", backups[i])
+	}
+
+	return nil
+}
+
+// RestoreBackup replaces the contents of the database at dbPath with a backup.
+// The bot must not be running against dbPath while restoring.
+func RestoreBackup(backupPath, dbPath string) error {
+	if _, err := os.Stat(backupPath); err != nil {
+		return fmt.Errorf("backup not found: %v", err)
+	}
+
+	backupDB, err := sql.Open("sqlite3", backupPath)
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %v", err)
+	}
+	defer backupDB.Close()
+
+	if err := BackupDatabase(backupDB, dbPath); err != nil {
+		return fmt.Errorf("failed to restore backup: %v", err)
+	}
+
+	logger().Infof("Restored database %s from backup %s", dbPath, backupPath)
+	return nil
+}
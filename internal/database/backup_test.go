@@ -0,0 +1,169 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// createOutdatedDatabase creates a database with one channel and one posted news row,
+// and resets its schema version so the next InitDatabase sees pending migrations.
+func createOutdatedDatabase(t *testing.T) string {
+	t.Helper()
+
+	dbPath := filepath.Join(t.TempDir(), "stobot.db")
+	db, err := InitDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	bot := &types.Bot{DB: db}
+	if err := AddChannel(bot, "channel-1"); err != nil {
+		t.Fatalf("Failed to add channel: %v", err)
+	}
+	if err := MarkNewsAsPosted(bot, 42, "channel-1"); err != nil {
+		t.Fatalf("Failed to mark news as posted: %v", err)
+	}
+	resetSchemaVersion(t, db)
+
+	return dbPath
+}
+
+func resetSchemaVersion(t *testing.T, db *sql.DB) {
+	t.Helper()
+	if _, err := db.Exec(`PRAGMA user_version = 0`); err != nil {
+		t.Fatalf("Failed to reset schema version: %v", err)
+	}
+}
+
+func TestInitDatabaseBacksUpBeforeMigration(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "stobot.db")
+
+	// A new database has nothing to back up
+	db, err := InitDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	backups, _ := ListMigrationBackups(dbPath)
+	if len(backups) != 0 {
+		t.Fatalf("Expected no backups for a new database, got %v", backups)
+	}
+
+	// An up-to-date database is not backed up again
+	db.Close()
+	db, err = InitDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	backups, _ = ListMigrationBackups(dbPath)
+	if len(backups) != 0 {
+		t.Fatalf("Expected no backups for an up-to-date database, got %v", backups)
+	}
+
+	// Each pending migration creates a backup; only the newest maxMigrationBackups are kept
+	start := time.Date(2025, 6, 11, 18, 0, 0, 0, time.UTC)
+	for n := 0; n < maxMigrationBackups+2; n++ {
+		resetSchemaVersion(t, db)
+		db.Close()
+
+		setClock(t, start.Add(time.Duration(n)*time.Minute))
+		db, err = InitDatabase(dbPath)
+		if err != nil {
+			t.Fatalf("Failed to reopen database: %v", err)
+		}
+	}
+	db.Close()
+
+	backups, err = ListMigrationBackups(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to list backups: %v", err)
+	}
+	if len(backups) != maxMigrationBackups {
+		t.Fatalf("Expected %d backups after rotation, got %v", maxMigrationBackups, backups)
+	}
+	newest := dbPath + ".pre-migrate-0-20250611T180400"
+	if backups[0] != newest {
+		t.Errorf("Expected newest backup %s first, got %s", newest, backups[0])
+	}
+	if oldest := dbPath + ".pre-migrate-0-20250611T180200"; backups[2] != oldest {
+		t.Errorf("Expected oldest kept backup %s, got %s", oldest, backups[2])
+	}
+}
+
+func TestInitDatabaseWithoutMigrationBackup(t *testing.T) {
+	dbPath := createOutdatedDatabase(t)
+
+	db, err := InitDatabaseWithOptions(dbPath, InitOptions{MigrationBackup: false})
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	db.Close()
+
+	backups, _ := ListMigrationBackups(dbPath)
+	if len(backups) != 0 {
+		t.Errorf("Expected no backups with MigrationBackup disabled, got %v", backups)
+	}
+}
+
+func TestRestoreBackupAfterFailedMigration(t *testing.T) {
+	dbPath := createOutdatedDatabase(t)
+
+	// A migration that fails halfway, leaving posted_news renamed away
+	saved := migrate
+	migrate = func(db *sql.DB) error {
+		if _, err := db.Exec(`ALTER TABLE posted_news RENAME TO posted_news_old`); err != nil {
+			return err
+		}
+		return errors.New("simulated migration failure")
+	}
+	_, err := InitDatabase(dbPath)
+	migrate = saved
+	if err == nil || !strings.Contains(err.Error(), "simulated migration failure") {
+		t.Fatalf("Expected the simulated migration failure, got %v", err)
+	}
+
+	backups, err := ListMigrationBackups(dbPath)
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("Expected 1 backup, got %v (err: %v)", backups, err)
+	}
+
+	if err := RestoreBackup(backups[0], dbPath); err != nil {
+		t.Fatalf("Failed to restore backup: %v", err)
+	}
+
+	db, err := InitDatabaseWithOptions(dbPath, InitOptions{MigrationBackup: false})
+	if err != nil {
+		t.Fatalf("Failed to initialize restored database: %v", err)
+	}
+	defer db.Close()
+
+	var leftover int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'posted_news_old'`).Scan(&leftover); err != nil {
+		t.Fatalf("Failed to inspect schema: %v", err)
+	}
+	if leftover != 0 {
+		t.Error("Expected the half-migrated table to be gone after restore")
+	}
+
+	posted, err := IsNewsPosted(&types.Bot{DB: db}, 42, "channel-1")
+	if err != nil {
+		t.Fatalf("Failed to check posted news: %v", err)
+	}
+	if !posted {
+		t.Error("Expected posted news to survive the restore")
+	}
+}
+
+func TestRestoreBackupMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	err := RestoreBackup(filepath.Join(dir, "missing.db"), filepath.Join(dir, "stobot.db"))
+	if err == nil {
+		t.Error("Expected an error for a missing backup")
+	}
+}
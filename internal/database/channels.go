@@ -0,0 +1,481 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// channelPageSize is the number of channels read per query by ForEachActiveChannel.
+const channelPageSize = 500
+
+// ChannelConfig holds a registered channel's posting configuration.
+type ChannelConfig = types.ChannelConfig
+
+// ForEachActiveChannel calls fn for every registered channel served by this bot, in channel ID
+// order. When the bot has an environment configured only channels in that environment are
+// visited; otherwise all channels are. Disabled channels are never visited.
+//
+// Channels are read with one query per page of channelPageSize rows, joining all config columns,
+// and each page is fully read and closed before fn is called, so fn may query or write the
+// database freely.
+//
+// If fn returns an error, iteration stops immediately and ForEachActiveChannel returns that
+// error unchanged. Callbacks that want to skip a failing channel and keep going should handle
+// the problem themselves and return nil.
+func ForEachActiveChannel(b *types.Bot, fn func(ChannelConfig) error) error {
+	environment := ""
+	if b.Config != nil {
+		environment = b.Config.Environment
+	}
+
+	lastID := ""
+	for {
+		page, err := getChannelConfigPage(b, environment, lastID, channelPageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, cfg := range page {
+			if err := fn(cfg); err != nil {
+				return err
+			}
+		}
+
+		if len(page) < channelPageSize {
+			return nil
+		}
+		lastID = page[len(page)-1].ID
+	}
+}
+
+// getChannelConfigPage returns up to limit channel configs with IDs after afterID.
+func getChannelConfigPage(b *types.Bot, environment string, afterID string, limit int) ([]ChannelConfig, error) {
+	query := `SELECT id, platforms, environment, spoiler_tags, auto_publish, strict_patch_notes, tags, excluded_tags, ping_role, digest_day, digest_hour, webhook_url, quiet_hours_start, quiet_hours_end, guild_id, locale, disabled, post_failures, create_threads, paused, pause_hold, max_posts_per_cycle, update_notices, show_galleries, message_style, create_events FROM channels
+			  WHERE id > ? AND (? = '' OR environment = ?) AND disabled = 0
+			  ORDER BY id
+			  LIMIT ?`
+
+	rows, err := b.DB.Query(query, afterID, environment, environment, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query channel configs: %v", err)
+	}
+	defer rows.Close()
+
+	var page []ChannelConfig
+	for rows.Next() {
+		cfg, err := scanChannelConfig(rows)
+		if err != nil {
+			return nil, err
+		}
+		page = append(page, cfg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read channel configs: %v", err)
+	}
+
+	return page, nil
+}
+
+// GetChannelConfig retrieves the configuration of a single channel.
+// It returns nil without error if the channel is not registered.
+func GetChannelConfig(b *types.Bot, channelID string) (*ChannelConfig, error) {
+	query := "SELECT id, platforms, environment, spoiler_tags, auto_publish, strict_patch_notes, tags, excluded_tags, ping_role, digest_day, digest_hour, webhook_url, quiet_hours_start, quiet_hours_end, guild_id, locale, disabled, post_failures, create_threads, paused, pause_hold, max_posts_per_cycle, update_notices, show_galleries, message_style, create_events FROM channels WHERE id = ?"
+
+	cfg, err := scanChannelConfig(b.DB.QueryRow(query, channelID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanChannelConfig scans a row of (id, platforms, environment, spoiler_tags, auto_publish, strict_patch_notes,
+// tags, excluded_tags, ping_role, digest_day, digest_hour, webhook_url, quiet_hours_start, quiet_hours_end,
+// guild_id, locale, disabled, post_failures, create_threads, paused, pause_hold, max_posts_per_cycle,
+// update_notices, show_galleries, message_style, create_events) into a ChannelConfig.
+func scanChannelConfig(row rowScanner) (ChannelConfig, error) {
+	var cfg ChannelConfig
+	var platforms, spoilerTags, tags, excludedTags string
+	var digestDay, quietStart, quietEnd sql.NullInt64
+	var guildID sql.NullString
+	if err := row.Scan(&cfg.ID, &platforms, &cfg.Environment, &spoilerTags, &cfg.AutoPublish, &cfg.StrictPatchNotes, &tags, &excludedTags,
+		&cfg.PingRole, &digestDay, &cfg.DigestHour, &cfg.WebhookURL, &quietStart, &quietEnd, &guildID, &cfg.Locale,
+		&cfg.Disabled, &cfg.PostFailures, &cfg.CreateThreads, &cfg.Paused, &cfg.PauseHold, &cfg.MaxPostsPerCycle, &cfg.UpdateNotices, &cfg.ShowGalleries, &cfg.MessageStyle, &cfg.CreateEvents); err != nil {
+		if err == sql.ErrNoRows {
+			return cfg, err
+		}
+		return cfg, fmt.Errorf("failed to scan channel config: %v", err)
+	}
+
+	cfg.Platforms = strings.Split(platforms, ",")
+	if spoilerTags != "" {
+		cfg.SpoilerTags = strings.Split(spoilerTags, ",")
+	}
+	if tags != "" {
+		cfg.Tags = strings.Split(tags, ",")
+	}
+	if excludedTags != "" {
+		cfg.ExcludedTags = strings.Split(excludedTags, ",")
+	}
+	if digestDay.Valid {
+		cfg.Digest = true
+		cfg.DigestDay = time.Weekday(digestDay.Int64)
+	}
+	cfg.GuildID = guildID.String
+	if quietStart.Valid && quietEnd.Valid {
+		cfg.QuietHours = true
+		cfg.QuietHoursStart = int(quietStart.Int64)
+		cfg.QuietHoursEnd = int(quietEnd.Int64)
+	}
+	return cfg, nil
+}
+
+// UpdateChannelAutoPublish enables or disables crossposting of bot messages in a channel.
+func UpdateChannelAutoPublish(b *types.Bot, channelID string, enabled bool) error {
+	query := `UPDATE channels SET auto_publish = ?, updated_at = CURRENT_TIMESTAMP 
+			  WHERE id = ?`
+
+	result, err := b.DB.Exec(query, enabled, channelID)
+	if err != nil {
+		return fmt.Errorf("failed to update channel auto-publish: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("channel %s not found", channelID)
+	}
+
+	return nil
+}
+
+// UpdateChannelStrictPatchNotes enables or disables strict platform patch notes for a channel.
+func UpdateChannelStrictPatchNotes(b *types.Bot, channelID string, enabled bool) error {
+	query := `UPDATE channels SET strict_patch_notes = ?, updated_at = CURRENT_TIMESTAMP 
+			  WHERE id = ?`
+
+	result, err := b.DB.Exec(query, enabled, channelID)
+	if err != nil {
+		return fmt.Errorf("failed to update channel strict patch notes: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("channel %s not found", channelID)
+	}
+
+	return nil
+}
+
+// UpdateChannelMaxPostsPerCycle sets how many news items may be posted to a channel per poll
+// cycle or catch-up; 0 uses the bot's default.
+func UpdateChannelMaxPostsPerCycle(b *types.Bot, channelID string, maxPosts int) error {
+	if maxPosts < 0 {
+		return fmt.Errorf("max posts per cycle must not be negative, got %d", maxPosts)
+	}
+
+	query := `UPDATE channels SET max_posts_per_cycle = ?, updated_at = CURRENT_TIMESTAMP 
+			  WHERE id = ?`
+
+	result, err := b.DB.Exec(query, maxPosts, channelID)
+	if err != nil {
+		return fmt.Errorf("failed to update channel max posts per cycle: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("channel %s not found", channelID)
+	}
+
+	return nil
+}
+
+// UpdateChannelUpdateNotices sets how a channel is told about articles updated after they were
+// posted there: types.UpdateNoticesPost, types.UpdateNoticesEdit or types.UpdateNoticesOff.
+func UpdateChannelUpdateNotices(b *types.Bot, channelID, mode string) error {
+	switch mode {
+	case types.UpdateNoticesPost, types.UpdateNoticesEdit, types.UpdateNoticesOff:
+	default:
+		return fmt.Errorf("invalid update notice mode %q", mode)
+	}
+
+	query := `UPDATE channels SET update_notices = ?, updated_at = CURRENT_TIMESTAMP 
+			  WHERE id = ?`
+
+	result, err := b.DB.Exec(query, mode, channelID)
+	if err != nil {
+		return fmt.Errorf("failed to update channel update notices: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("channel %s not found", channelID)
+	}
+
+	return nil
+}
+
+// UpdateChannelMessageStyle sets how news is posted to a channel: types.MessageStyleEmbed or
+// types.MessageStyleCompact.
+func UpdateChannelMessageStyle(b *types.Bot, channelID, style string) error {
+	switch style {
+	case types.MessageStyleEmbed, types.MessageStyleCompact:
+	default:
+		return fmt.Errorf("invalid message style %q", style)
+	}
+
+	query := `UPDATE channels SET message_style = ?, updated_at = CURRENT_TIMESTAMP 
+			  WHERE id = ?`
+
+	result, err := b.DB.Exec(query, style, channelID)
+	if err != nil {
+		return fmt.Errorf("failed to update channel message style: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("channel %s not found", channelID)
+	}
+
+	return nil
+}
+
+// UpdateChannelCreateThreads enables or disables discussion threads on a channel's news posts.
+func UpdateChannelCreateThreads(b *types.Bot, channelID string, enabled bool) error {
+	query := `UPDATE channels SET create_threads = ?, updated_at = CURRENT_TIMESTAMP 
+			  WHERE id = ?`
+
+	result, err := b.DB.Exec(query, enabled, channelID)
+	if err != nil {
+		return fmt.Errorf("failed to update channel create threads: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("channel %s not found", channelID)
+	}
+
+	return nil
+}
+
+// UpdateChannelCreateEvents enables or disables Discord scheduled events for the in-game events
+// announced in a channel's news posts.
+func UpdateChannelCreateEvents(b *types.Bot, channelID string, enabled bool) error {
+	query := `UPDATE channels SET create_events = ?, updated_at = CURRENT_TIMESTAMP 
+			  WHERE id = ?`
+
+	result, err := b.DB.Exec(query, enabled, channelID)
+	if err != nil {
+		return fmt.Errorf("failed to update channel create events: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("channel %s not found", channelID)
+	}
+
+	return nil
+}
+
+// UpdateChannelShowGalleries enables or disables image galleries on a channel's news posts.
+func UpdateChannelShowGalleries(b *types.Bot, channelID string, enabled bool) error {
+	query := `UPDATE channels SET show_galleries = ?, updated_at = CURRENT_TIMESTAMP 
+			  WHERE id = ?`
+
+	result, err := b.DB.Exec(query, enabled, channelID)
+	if err != nil {
+		return fmt.Errorf("failed to update channel show galleries: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("channel %s not found", channelID)
+	}
+
+	return nil
+}
+
+// RecordChannelPostFailure counts a post to a channel that failed because the channel is gone or
+// the bot lost access to it, and disables the channel once maxFailures consecutive posts have
+// failed. It reports whether this failure disabled the channel; a maxFailures of 0 never does.
+func RecordChannelPostFailure(b *types.Bot, channelID string, maxFailures int) (bool, error) {
+	if _, err := b.DB.Exec(`UPDATE channels SET post_failures = post_failures + 1 WHERE id = ? AND disabled = 0`, channelID); err != nil {
+		return false, fmt.Errorf("failed to count channel post failure: %v", err)
+	}
+	if maxFailures <= 0 {
+		return false, nil
+	}
+
+	result, err := b.DB.Exec(`UPDATE channels SET disabled = 1, updated_at = CURRENT_TIMESTAMP
+							  WHERE id = ? AND disabled = 0 AND post_failures >= ?`, channelID, maxFailures)
+	if err != nil {
+		return false, fmt.Errorf("failed to disable channel: %v", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// ResetChannelPostFailures clears a channel's count of consecutive failed posts after a post succeeded.
+func ResetChannelPostFailures(b *types.Bot, channelID string) error {
+	if _, err := b.DB.Exec(`UPDATE channels SET post_failures = 0 WHERE id = ? AND post_failures > 0`, channelID); err != nil {
+		return fmt.Errorf("failed to reset channel post failures: %v", err)
+	}
+	return nil
+}
+
+// UpdateChannelDisabled disables or re-enables posting to a channel and clears its count of
+// failed posts, so a re-enabled channel gets the full number of attempts again.
+func UpdateChannelDisabled(b *types.Bot, channelID string, disabled bool) error {
+	query := `UPDATE channels SET disabled = ?, post_failures = 0, updated_at = CURRENT_TIMESTAMP 
+			  WHERE id = ?`
+
+	result, err := b.DB.Exec(query, disabled, channelID)
+	if err != nil {
+		return fmt.Errorf("failed to update channel disabled: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("channel %s not found", channelID)
+	}
+
+	return nil
+}
+
+// MigrateChannel moves the registration of channel oldID, with its settings and posting history,
+// to channel newID, e.g. after a server recreated its announcements channel. The new channel
+// starts with posting enabled and without the old channel's webhook, which only posts to the old
+// channel. It returns the number of posts moved.
+func MigrateChannel(b *types.Bot, oldID, newID string) (int64, error) {
+	if oldID == newID {
+		return 0, fmt.Errorf("channel %s cannot be migrated to itself", oldID)
+	}
+
+	// Every column but the ID is copied, so settings added later move along too
+	rows, err := b.DB.Query(`SELECT name FROM pragma_table_info('channels') WHERE name != 'id'`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read channel columns: %v", err)
+	}
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to read channel columns: %v", err)
+		}
+		columns = append(columns, column)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read channel columns: %v", err)
+	}
+
+	tx, err := b.DB.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			logger().Printf("Warning: failed to rollback transaction: %v", rollbackErr)
+		}
+	}()
+
+	var registered bool
+	if err := tx.QueryRow(`SELECT COUNT(*) > 0 FROM channels WHERE id = ?`, newID).Scan(&registered); err != nil {
+		return 0, fmt.Errorf("failed to check channel %s: %v", newID, err)
+	}
+	if registered {
+		return 0, fmt.Errorf("channel %s is already registered", newID)
+	}
+
+	// Column names come from the schema, never user input
+	query := fmt.Sprintf(`INSERT INTO channels (id, %[1]s) SELECT ?, %[1]s FROM channels WHERE id = ?`, strings.Join(columns, ", "))
+	result, err := tx.Exec(query, newID, oldID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to copy channel %s: %v", oldID, err)
+	}
+	if copied, err := result.RowsAffected(); err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %v", err)
+	} else if copied == 0 {
+		return 0, fmt.Errorf("channel %s not found", oldID)
+	}
+
+	query = `UPDATE channels SET webhook_url = '', disabled = 0, post_failures = 0, updated_at = CURRENT_TIMESTAMP 
+			 WHERE id = ?`
+	if _, err := tx.Exec(query, newID); err != nil {
+		return 0, fmt.Errorf("failed to reset channel %s: %v", newID, err)
+	}
+
+	result, err = tx.Exec(`UPDATE posted_news SET channel_id = ? WHERE channel_id = ?`, newID, oldID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to move posted news: %v", err)
+	}
+	moved, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if _, err := tx.Exec(`UPDATE held_news SET channel_id = ? WHERE channel_id = ?`, newID, oldID); err != nil {
+		return 0, fmt.Errorf("failed to move held news: %v", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM channels WHERE id = ?`, oldID); err != nil {
+		return 0, fmt.Errorf("failed to remove channel %s: %v", oldID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit channel migration: %v", err)
+	}
+	return moved, nil
+}
@@ -0,0 +1,900 @@
+package database
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// queryCount counts statements prepared through the "sqlite3_counting" driver.
+var queryCount int64
+
+var registerCountingDriver sync.Once
+
+// countingConn wraps a driver connection and counts every statement it prepares.
+// It deliberately exposes only driver.Conn so database/sql routes all queries through Prepare.
+type countingConn struct {
+	driver.Conn
+}
+
+func (c countingConn) Prepare(query string) (driver.Stmt, error) {
+	atomic.AddInt64(&queryCount, 1)
+	return c.Conn.Prepare(query)
+}
+
+type countingDriver struct {
+	base driver.Driver
+}
+
+func (d countingDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.base.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return countingConn{Conn: conn}, nil
+}
+
+// seedChannelDatabase creates a database with count channels, alternating PROD and DEV,
+// and returns a bot whose connection counts queries.
+func seedChannelDatabase(tb testing.TB, count int) *types.Bot {
+	tb.Helper()
+
+	dbPath := filepath.Join(tb.TempDir(), "channels.db")
+	db, err := InitDatabase(dbPath)
+	if err != nil {
+		tb.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		tb.Fatalf("Failed to begin transaction: %v", err)
+	}
+	for i := 0; i < count; i++ {
+		environment := "PROD"
+		if i%2 == 1 {
+			environment = "DEV"
+		}
+		_, err := tx.Exec(`INSERT INTO channels (id, platforms, environment) VALUES (?, ?, ?)`,
+			fmt.Sprintf("channel-%05d", i), "pc,xbox", environment)
+		if err != nil {
+			tb.Fatalf("Failed to seed channel: %v", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		tb.Fatalf("Failed to commit seed data: %v", err)
+	}
+	db.Close()
+
+	registerCountingDriver.Do(func() {
+		sql.Register("sqlite3_counting", countingDriver{base: &sqlite3.SQLiteDriver{}})
+	})
+	countingDB, err := sql.Open("sqlite3_counting", dbPath)
+	if err != nil {
+		tb.Fatalf("Failed to open counting database: %v", err)
+	}
+	tb.Cleanup(func() { countingDB.Close() })
+
+	return &types.Bot{DB: countingDB, Config: &types.Config{}}
+}
+
+func TestForEachActiveChannel(t *testing.T) {
+	// More than two pages so page boundaries are exercised
+	total := channelPageSize*2 + 1
+	bot := seedChannelDatabase(t, total)
+
+	var visited []string
+	err := ForEachActiveChannel(bot, func(cfg ChannelConfig) error {
+		visited = append(visited, cfg.ID)
+		if len(cfg.Platforms) != 2 || cfg.Platforms[0] != "pc" || cfg.Platforms[1] != "xbox" {
+			t.Errorf("Unexpected platforms for %s: %v", cfg.ID, cfg.Platforms)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEachActiveChannel failed: %v", err)
+	}
+
+	if len(visited) != total {
+		t.Fatalf("Expected %d channels, visited %d", total, len(visited))
+	}
+	for i, id := range visited {
+		if expected := fmt.Sprintf("channel-%05d", i); id != expected {
+			t.Fatalf("Expected channel %d to be %s, got %s", i, expected, id)
+		}
+	}
+}
+
+func TestForEachActiveChannelEnvironment(t *testing.T) {
+	bot := seedChannelDatabase(t, 10)
+	bot.Config.Environment = "DEV"
+
+	count := 0
+	err := ForEachActiveChannel(bot, func(cfg ChannelConfig) error {
+		count++
+		if cfg.Environment != "DEV" {
+			t.Errorf("Expected only DEV channels, got %s in %s", cfg.ID, cfg.Environment)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEachActiveChannel failed: %v", err)
+	}
+	if count != 5 {
+		t.Errorf("Expected 5 DEV channels, got %d", count)
+	}
+}
+
+func TestForEachActiveChannelStopsOnError(t *testing.T) {
+	bot := seedChannelDatabase(t, channelPageSize+10)
+	stopErr := errors.New("stop here")
+
+	count := 0
+	err := ForEachActiveChannel(bot, func(cfg ChannelConfig) error {
+		count++
+		if count == 3 {
+			return stopErr
+		}
+		return nil
+	})
+	if !errors.Is(err, stopErr) {
+		t.Fatalf("Expected callback error to be returned unchanged, got %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected iteration to stop after 3 channels, visited %d", count)
+	}
+}
+
+func TestForEachActiveChannelCallbackCanWrite(t *testing.T) {
+	bot := seedChannelDatabase(t, 20)
+
+	// Writing from inside the callback must not deadlock against the channel query
+	err := ForEachActiveChannel(bot, func(cfg ChannelConfig) error {
+		return MarkNewsAsPosted(bot, 1, cfg.ID)
+	})
+	if err != nil {
+		t.Fatalf("ForEachActiveChannel failed: %v", err)
+	}
+
+	var posted int
+	if err := bot.DB.QueryRow("SELECT COUNT(*) FROM posted_news").Scan(&posted); err != nil {
+		t.Fatalf("Failed to count posted news: %v", err)
+	}
+	if posted != 20 {
+		t.Errorf("Expected 20 posted rows, got %d", posted)
+	}
+}
+
+func TestGetChannelConfig(t *testing.T) {
+	bot := seedChannelDatabase(t, 2)
+
+	cfg, err := GetChannelConfig(bot, "channel-00001")
+	if err != nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if cfg == nil || cfg.ID != "channel-00001" || cfg.Environment != "DEV" {
+		t.Errorf("Unexpected channel config: %+v", cfg)
+	}
+
+	cfg, err = GetChannelConfig(bot, "missing")
+	if err != nil {
+		t.Fatalf("Failed to get missing channel config: %v", err)
+	}
+	if cfg != nil {
+		t.Errorf("Expected nil config for unregistered channel, got %+v", cfg)
+	}
+}
+
+// BenchmarkChannelListingPerChannelQueries measures the previous pattern of listing channel IDs
+// and then loading each channel's settings with separate queries.
+func BenchmarkChannelListingPerChannelQueries(b *testing.B) {
+	bot := seedChannelDatabase(b, 2000)
+
+	atomic.StoreInt64(&queryCount, 0)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		channels, err := GetRegisteredChannels(bot)
+		if err != nil {
+			b.Fatalf("Failed to get channels: %v", err)
+		}
+		for _, channelID := range channels {
+			if _, err := GetChannelEnvironment(bot, channelID); err != nil {
+				b.Fatalf("Failed to get environment: %v", err)
+			}
+			if _, err := GetChannelPlatforms(bot, channelID); err != nil {
+				b.Fatalf("Failed to get platforms: %v", err)
+			}
+		}
+	}
+	b.ReportMetric(float64(atomic.LoadInt64(&queryCount))/float64(b.N), "queries/op")
+}
+
+// BenchmarkForEachActiveChannel measures streaming the same configuration with ForEachActiveChannel.
+func BenchmarkForEachActiveChannel(b *testing.B) {
+	bot := seedChannelDatabase(b, 2000)
+
+	atomic.StoreInt64(&queryCount, 0)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		err := ForEachActiveChannel(bot, func(cfg ChannelConfig) error {
+			return nil
+		})
+		if err != nil {
+			b.Fatalf("ForEachActiveChannel failed: %v", err)
+		}
+	}
+	b.ReportMetric(float64(atomic.LoadInt64(&queryCount))/float64(b.N), "queries/op")
+}
+
+func TestUpdateChannelStrictPatchNotes(t *testing.T) {
+	bot := seedChannelDatabase(t, 1)
+
+	if err := UpdateChannelStrictPatchNotes(bot, "channel-00000", true); err != nil {
+		t.Fatalf("Failed to enable strict patch notes: %v", err)
+	}
+	cfg, err := GetChannelConfig(bot, "channel-00000")
+	if err != nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if !cfg.StrictPatchNotes {
+		t.Error("Expected strict patch notes to be enabled")
+	}
+
+	if err := UpdateChannelStrictPatchNotes(bot, "missing", true); err == nil {
+		t.Error("Expected an error for an unregistered channel")
+	}
+}
+
+func TestUpdateChannelCreateThreads(t *testing.T) {
+	bot := seedChannelDatabase(t, 1)
+
+	if err := UpdateChannelCreateThreads(bot, "channel-00000", true); err != nil {
+		t.Fatalf("Failed to enable threads: %v", err)
+	}
+	cfg, err := GetChannelConfig(bot, "channel-00000")
+	if err != nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if !cfg.CreateThreads {
+		t.Error("Expected threads to be enabled")
+	}
+
+	if err := UpdateChannelCreateThreads(bot, "missing", true); err == nil {
+		t.Error("Expected an error for an unregistered channel")
+	}
+}
+
+func TestUpdateChannelMaxPostsPerCycle(t *testing.T) {
+	bot := seedChannelDatabase(t, 1)
+
+	if err := UpdateChannelMaxPostsPerCycle(bot, "channel-00000", 5); err != nil {
+		t.Fatalf("Failed to update max posts per cycle: %v", err)
+	}
+	cfg, err := GetChannelConfig(bot, "channel-00000")
+	if err != nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if cfg.MaxPostsPerCycle != 5 {
+		t.Errorf("Expected 5 posts per cycle, got %d", cfg.MaxPostsPerCycle)
+	}
+
+	if err := UpdateChannelMaxPostsPerCycle(bot, "channel-00000", -1); err == nil {
+		t.Error("Expected an error for a negative limit")
+	}
+	if err := UpdateChannelMaxPostsPerCycle(bot, "missing", 5); err == nil {
+		t.Error("Expected an error for an unregistered channel")
+	}
+}
+
+func TestUpdateChannelUpdateNotices(t *testing.T) {
+	bot := seedChannelDatabase(t, 1)
+
+	cfg, err := GetChannelConfig(bot, "channel-00000")
+	if err != nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if cfg.UpdateNotices != types.UpdateNoticesPost {
+		t.Errorf("Expected update notices %q by default, got %q", types.UpdateNoticesPost, cfg.UpdateNotices)
+	}
+
+	if err := UpdateChannelUpdateNotices(bot, "channel-00000", types.UpdateNoticesEdit); err != nil {
+		t.Fatalf("Failed to update update notices: %v", err)
+	}
+	if cfg, err = GetChannelConfig(bot, "channel-00000"); err != nil || cfg.UpdateNotices != types.UpdateNoticesEdit {
+		t.Errorf("Expected update notices %q, got %+v (%v)", types.UpdateNoticesEdit, cfg, err)
+	}
+
+	if err := UpdateChannelUpdateNotices(bot, "channel-00000", "sometimes"); err == nil {
+		t.Error("Expected an error for an invalid mode")
+	}
+	if err := UpdateChannelUpdateNotices(bot, "missing", types.UpdateNoticesOff); err == nil {
+		t.Error("Expected an error for an unregistered channel")
+	}
+}
+
+func TestUpdateChannelShowGalleries(t *testing.T) {
+	bot := seedChannelDatabase(t, 1)
+
+	cfg, err := GetChannelConfig(bot, "channel-00000")
+	if err != nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if cfg.ShowGalleries {
+		t.Error("Expected galleries to be off by default")
+	}
+
+	if err := UpdateChannelShowGalleries(bot, "channel-00000", true); err != nil {
+		t.Fatalf("Failed to update show galleries: %v", err)
+	}
+	if cfg, err = GetChannelConfig(bot, "channel-00000"); err != nil || !cfg.ShowGalleries {
+		t.Errorf("Expected galleries to be shown, got %+v (%v)", cfg, err)
+	}
+
+	if err := UpdateChannelShowGalleries(bot, "missing", true); err == nil {
+		t.Error("Expected an error for an unregistered channel")
+	}
+}
+
+func TestUpdateChannelMessageStyle(t *testing.T) {
+	bot := seedChannelDatabase(t, 1)
+
+	cfg, err := GetChannelConfig(bot, "channel-00000")
+	if err != nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if cfg.MessageStyle != types.MessageStyleEmbed {
+		t.Errorf("Expected message style %q by default, got %q", types.MessageStyleEmbed, cfg.MessageStyle)
+	}
+
+	if err := UpdateChannelMessageStyle(bot, "channel-00000", types.MessageStyleCompact); err != nil {
+		t.Fatalf("Failed to update message style: %v", err)
+	}
+	if cfg, err = GetChannelConfig(bot, "channel-00000"); err != nil || cfg.MessageStyle != types.MessageStyleCompact {
+		t.Errorf("Expected message style %q, got %+v (%v)", types.MessageStyleCompact, cfg, err)
+	}
+
+	if err := UpdateChannelMessageStyle(bot, "channel-00000", "fancy"); err == nil {
+		t.Error("Expected an error for an invalid style")
+	}
+	if err := UpdateChannelMessageStyle(bot, "missing", types.MessageStyleEmbed); err == nil {
+		t.Error("Expected an error for an unregistered channel")
+	}
+}
+
+func TestChannelPostFailures(t *testing.T) {
+	bot := seedChannelDatabase(t, 2)
+	getConfig := func() *ChannelConfig {
+		t.Helper()
+		cfg, err := GetChannelConfig(bot, "channel-00000")
+		if err != nil {
+			t.Fatalf("Failed to get channel config: %v", err)
+		}
+		return cfg
+	}
+
+	for n := 1; n <= 2; n++ {
+		disabled, err := RecordChannelPostFailure(bot, "channel-00000", 3)
+		if err != nil {
+			t.Fatalf("Failed to record post failure: %v", err)
+		}
+		if disabled {
+			t.Fatalf("Expected failure %d not to disable the channel", n)
+		}
+	}
+	if cfg := getConfig(); cfg.PostFailures != 2 || cfg.Disabled {
+		t.Fatalf("Expected 2 failures on an enabled channel, got %+v", cfg)
+	}
+
+	// A successful post starts the count over
+	if err := ResetChannelPostFailures(bot, "channel-00000"); err != nil {
+		t.Fatalf("Failed to reset post failures: %v", err)
+	}
+	for n := 1; n <= 3; n++ {
+		disabled, err := RecordChannelPostFailure(bot, "channel-00000", 3)
+		if err != nil {
+			t.Fatalf("Failed to record post failure: %v", err)
+		}
+		if disabled != (n == 3) {
+			t.Fatalf("Expected failure %d to disable the channel: %v, got %v", n, n == 3, disabled)
+		}
+	}
+	if cfg := getConfig(); !cfg.Disabled {
+		t.Fatalf("Expected the channel to be disabled, got %+v", cfg)
+	}
+
+	var visited []string
+	if err := ForEachActiveChannel(bot, func(cfg ChannelConfig) error {
+		visited = append(visited, cfg.ID)
+		return nil
+	}); err != nil {
+		t.Fatalf("ForEachActiveChannel failed: %v", err)
+	}
+	if !reflect.DeepEqual(visited, []string{"channel-00001"}) {
+		t.Errorf("Expected only the enabled channel to be visited, got %v", visited)
+	}
+
+	if err := UpdateChannelDisabled(bot, "channel-00000", false); err != nil {
+		t.Fatalf("Failed to enable channel: %v", err)
+	}
+	if cfg := getConfig(); cfg.Disabled || cfg.PostFailures != 0 {
+		t.Errorf("Expected an enabled channel without failures, got %+v", cfg)
+	}
+
+	// Without a limit, failures are counted but never disable the channel
+	for n := 0; n < 10; n++ {
+		if disabled, err := RecordChannelPostFailure(bot, "channel-00000", 0); err != nil || disabled {
+			t.Fatalf("Expected failures without a limit not to disable the channel: %v, %v", disabled, err)
+		}
+	}
+
+	if err := UpdateChannelDisabled(bot, "missing", true); err == nil {
+		t.Error("Expected an error for an unregistered channel")
+	}
+}
+
+func TestChannelTags(t *testing.T) {
+	bot := seedChannelDatabase(t, 1)
+
+	// New channels post all tags
+	tags, err := GetChannelTags(bot, "channel-00000")
+	if err != nil {
+		t.Fatalf("Failed to get channel tags: %v", err)
+	}
+	if len(tags) != 0 {
+		t.Errorf("Expected no tag filter by default, got %v", tags)
+	}
+
+	if err := UpdateChannelTags(bot, "channel-00000", []string{"patch-notes", "events"}); err != nil {
+		t.Fatalf("Failed to update channel tags: %v", err)
+	}
+	cfg, err := GetChannelConfig(bot, "channel-00000")
+	if err != nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Tags, []string{"patch-notes", "events"}) {
+		t.Errorf("Expected tags [patch-notes events], got %v", cfg.Tags)
+	}
+
+	// An empty list goes back to all tags
+	if err := UpdateChannelTags(bot, "channel-00000", nil); err != nil {
+		t.Fatalf("Failed to clear channel tags: %v", err)
+	}
+	tags, err = GetChannelTags(bot, "channel-00000")
+	if err != nil {
+		t.Fatalf("Failed to get channel tags: %v", err)
+	}
+	if len(tags) != 0 {
+		t.Errorf("Expected tags to be cleared, got %v", tags)
+	}
+
+	if err := UpdateChannelTags(bot, "missing", []string{"events"}); err == nil {
+		t.Error("Expected an error for an unregistered channel")
+	}
+}
+
+func TestChannelExcludedTags(t *testing.T) {
+	bot := seedChannelDatabase(t, 1)
+
+	excludedTags, err := GetChannelExcludedTags(bot, "channel-00000")
+	if err != nil {
+		t.Fatalf("Failed to get excluded tags: %v", err)
+	}
+	if len(excludedTags) != 0 {
+		t.Errorf("Expected no excluded tags by default, got %v", excludedTags)
+	}
+
+	if err := SetChannelExcludedTags(bot, "channel-00000", []string{"dev-blogs", "events"}); err != nil {
+		t.Fatalf("Failed to set excluded tags: %v", err)
+	}
+	cfg, err := GetChannelConfig(bot, "channel-00000")
+	if err != nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.ExcludedTags, []string{"dev-blogs", "events"}) {
+		t.Errorf("Expected excluded tags [dev-blogs events], got %v", cfg.ExcludedTags)
+	}
+
+	if err := SetChannelExcludedTags(bot, "channel-00000", nil); err != nil {
+		t.Fatalf("Failed to clear excluded tags: %v", err)
+	}
+	excludedTags, err = GetChannelExcludedTags(bot, "channel-00000")
+	if err != nil {
+		t.Fatalf("Failed to get excluded tags: %v", err)
+	}
+	if len(excludedTags) != 0 {
+		t.Errorf("Expected excluded tags to be cleared, got %v", excludedTags)
+	}
+
+	if err := SetChannelExcludedTags(bot, "missing", []string{"events"}); err == nil {
+		t.Error("Expected an error for an unregistered channel")
+	}
+}
+
+func TestChannelPingRole(t *testing.T) {
+	bot := seedChannelDatabase(t, 1)
+
+	pingRole, err := GetChannelPingRole(bot, "channel-00000")
+	if err != nil {
+		t.Fatalf("Failed to get ping role: %v", err)
+	}
+	if pingRole != "" {
+		t.Errorf("Expected no ping role by default, got %q", pingRole)
+	}
+
+	if err := UpdateChannelPingRole(bot, "channel-00000", "role-1"); err != nil {
+		t.Fatalf("Failed to update ping role: %v", err)
+	}
+	cfg, err := GetChannelConfig(bot, "channel-00000")
+	if err != nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if cfg.PingRole != "role-1" {
+		t.Errorf("Expected ping role role-1, got %q", cfg.PingRole)
+	}
+
+	if err := UpdateChannelPingRole(bot, "channel-00000", ""); err != nil {
+		t.Fatalf("Failed to clear ping role: %v", err)
+	}
+	pingRole, err = GetChannelPingRole(bot, "channel-00000")
+	if err != nil {
+		t.Fatalf("Failed to get ping role: %v", err)
+	}
+	if pingRole != "" {
+		t.Errorf("Expected ping role to be cleared, got %q", pingRole)
+	}
+
+	if err := UpdateChannelPingRole(bot, "missing", "role-1"); err == nil {
+		t.Error("Expected an error for an unregistered channel")
+	}
+}
+
+func TestChannelWebhook(t *testing.T) {
+	bot := seedChannelDatabase(t, 1)
+	const webhookURL = "https://discord.com/api/webhooks/111/token"
+
+	if err := UpdateChannelWebhook(bot, "channel-00000", webhookURL); err != nil {
+		t.Fatalf("Failed to update webhook: %v", err)
+	}
+	configs, err := getChannelConfigPage(bot, "", "", 10)
+	if err != nil {
+		t.Fatalf("Failed to get channel configs: %v", err)
+	}
+	if len(configs) != 1 || configs[0].WebhookURL != webhookURL {
+		t.Errorf("Expected webhook %q, got %+v", webhookURL, configs)
+	}
+
+	if err := UpdateChannelWebhook(bot, "channel-00000", ""); err != nil {
+		t.Fatalf("Failed to clear webhook: %v", err)
+	}
+	cfg, err := GetChannelConfig(bot, "channel-00000")
+	if err != nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if cfg.WebhookURL != "" {
+		t.Errorf("Expected webhook to be cleared, got %q", cfg.WebhookURL)
+	}
+
+	if err := UpdateChannelWebhook(bot, "missing", webhookURL); err == nil {
+		t.Error("Expected an error for an unregistered channel")
+	}
+}
+
+func TestChannelQuietHours(t *testing.T) {
+	bot := seedChannelDatabase(t, 1)
+
+	cfg, err := GetChannelConfig(bot, "channel-00000")
+	if err != nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if cfg.QuietHours {
+		t.Errorf("Expected no quiet hours by default, got %+v", cfg)
+	}
+
+	if err := UpdateChannelQuietHours(bot, "channel-00000", true, 22, 7); err != nil {
+		t.Fatalf("Failed to update quiet hours: %v", err)
+	}
+	cfg, err = GetChannelConfig(bot, "channel-00000")
+	if err != nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if !cfg.QuietHours || cfg.QuietHoursStart != 22 || cfg.QuietHoursEnd != 7 {
+		t.Errorf("Expected quiet hours 22 to 7, got %+v", cfg)
+	}
+
+	if err := UpdateChannelQuietHours(bot, "channel-00000", false, 0, 0); err != nil {
+		t.Fatalf("Failed to clear quiet hours: %v", err)
+	}
+	cfg, err = GetChannelConfig(bot, "channel-00000")
+	if err != nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if cfg.QuietHours {
+		t.Errorf("Expected quiet hours to be cleared, got %+v", cfg)
+	}
+
+	for _, hours := range [][2]int{{5, 5}, {-1, 7}, {22, 24}} {
+		if err := UpdateChannelQuietHours(bot, "channel-00000", true, hours[0], hours[1]); err == nil {
+			t.Errorf("Expected an error for quiet hours %d to %d", hours[0], hours[1])
+		}
+	}
+	if err := UpdateChannelQuietHours(bot, "missing", true, 22, 7); err == nil {
+		t.Error("Expected an error for an unregistered channel")
+	}
+}
+
+func TestGetRecentPostsForChannel(t *testing.T) {
+	bot := seedChannelDatabase(t, 2)
+
+	posts, err := GetRecentPostsForChannel(bot, "channel-00000", 5)
+	if err != nil {
+		t.Fatalf("Failed to get recent posts: %v", err)
+	}
+	if len(posts) != 0 {
+		t.Errorf("Expected no posts for a new channel, got %v", posts)
+	}
+
+	var newsItems []types.NewsItem
+	for id := int64(1); id <= 6; id++ {
+		newsItems = append(newsItems, types.NewsItem{ID: id, Title: fmt.Sprintf("News %d", id), Updated: time.Now()})
+	}
+	if err := CacheNews(bot, newsItems[:5]); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+
+	// Six posts an hour apart; news 6 was never cached, news 1 went to another channel too
+	base := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	for id := int64(1); id <= 6; id++ {
+		postedAt := base.Add(time.Duration(id) * time.Hour).Format("2006-01-02 15:04:05")
+		if _, err := bot.DB.Exec(`INSERT INTO posted_news (news_id, channel_id, posted_at) VALUES (?, ?, ?)`,
+			id, "channel-00000", postedAt); err != nil {
+			t.Fatalf("Failed to insert posted news: %v", err)
+		}
+	}
+	if err := MarkNewsAsPosted(bot, 1, "channel-00001"); err != nil {
+		t.Fatalf("Failed to mark news as posted: %v", err)
+	}
+
+	posts, err = GetRecentPostsForChannel(bot, "channel-00000", 5)
+	if err != nil {
+		t.Fatalf("Failed to get recent posts: %v", err)
+	}
+	if len(posts) != 5 {
+		t.Fatalf("Expected 5 posts, got %d", len(posts))
+	}
+	expected := RecentPost{NewsID: 6, Title: "", PostedAt: base.Add(6 * time.Hour)}
+	if posts[0].NewsID != expected.NewsID || posts[0].Title != expected.Title || !posts[0].PostedAt.Equal(expected.PostedAt) {
+		t.Errorf("Expected newest post %+v, got %+v", expected, posts[0])
+	}
+	if posts[1].Title != "News 5" || posts[4].NewsID != 2 {
+		t.Errorf("Expected posts 5 down to 2, got %+v", posts)
+	}
+}
+
+func TestMigrateChannel(t *testing.T) {
+	bot := seedChannelDatabase(t, 2)
+	oldID := "channel-00000"
+	if err := UpdateChannelPlatforms(bot, oldID, []string{"xbox", "ps"}); err != nil {
+		t.Fatalf("Failed to update platforms: %v", err)
+	}
+	if err := UpdateChannelTags(bot, oldID, []string{"patch-notes"}); err != nil {
+		t.Fatalf("Failed to update tags: %v", err)
+	}
+	if err := UpdateChannelWebhook(bot, oldID, "https://discord.com/api/webhooks/1/old"); err != nil {
+		t.Fatalf("Failed to update webhook: %v", err)
+	}
+	if err := UpdateChannelDisabled(bot, oldID, true); err != nil {
+		t.Fatalf("Failed to disable channel: %v", err)
+	}
+	for id := int64(1); id <= 3; id++ {
+		if err := MarkNewsAsPosted(bot, id, oldID); err != nil {
+			t.Fatalf("Failed to mark news as posted: %v", err)
+		}
+	}
+	if err := MarkNewsAsPosted(bot, 1, "channel-00001"); err != nil {
+		t.Fatalf("Failed to mark news as posted: %v", err)
+	}
+
+	moved, err := MigrateChannel(bot, oldID, "channel-new")
+	if err != nil {
+		t.Fatalf("Failed to migrate channel: %v", err)
+	}
+	if moved != 3 {
+		t.Errorf("Expected 3 posts moved, got %d", moved)
+	}
+
+	cfg, err := GetChannelConfig(bot, "channel-new")
+	if err != nil || cfg == nil {
+		t.Fatalf("Expected the new channel to be registered, got %+v (%v)", cfg, err)
+	}
+	if !reflect.DeepEqual(cfg.Platforms, []string{"xbox", "ps"}) || !reflect.DeepEqual(cfg.Tags, []string{"patch-notes"}) || cfg.Environment != "PROD" {
+		t.Errorf("Expected the settings to move to the new channel, got %+v", cfg)
+	}
+	if cfg.WebhookURL != "" || cfg.Disabled {
+		t.Errorf("Expected the new channel to post as the bot and be enabled, got %+v", cfg)
+	}
+	if cfg, _ := GetChannelConfig(bot, oldID); cfg != nil {
+		t.Errorf("Expected the old channel to be removed, got %+v", cfg)
+	}
+
+	for id := int64(1); id <= 3; id++ {
+		if posted, err := IsNewsPosted(bot, id, "channel-new"); err != nil || !posted {
+			t.Errorf("Expected news %d posted to the new channel (%v)", id, err)
+		}
+	}
+	if posted, _ := IsNewsPosted(bot, 1, "channel-00001"); !posted {
+		t.Error("Expected other channels to keep their history")
+	}
+
+	// Nothing changes when the migration is refused
+	for _, tt := range []struct{ oldID, newID string }{
+		{"missing", "channel-other"},
+		{"channel-new", "channel-00001"},
+		{"channel-new", "channel-new"},
+	} {
+		if _, err := MigrateChannel(bot, tt.oldID, tt.newID); err == nil {
+			t.Errorf("Expected migrating %s to %s to fail", tt.oldID, tt.newID)
+		}
+	}
+	channels, err := GetRegisteredChannels(bot)
+	sort.Strings(channels)
+	if err != nil || !reflect.DeepEqual(channels, []string{"channel-00001", "channel-new"}) {
+		t.Errorf("Expected the channels to be unchanged, got %v (%v)", channels, err)
+	}
+}
+
+func TestChannelDigest(t *testing.T) {
+	bot := seedChannelDatabase(t, 1)
+
+	cfg, err := GetChannelConfig(bot, "channel-00000")
+	if err != nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if cfg.Digest {
+		t.Error("Expected digest mode to be off by default")
+	}
+
+	before := time.Now().Add(-time.Second)
+	if err := UpdateChannelDigest(bot, "channel-00000", true, time.Monday, 9); err != nil {
+		t.Fatalf("Failed to update digest: %v", err)
+	}
+	cfg, err = GetChannelConfig(bot, "channel-00000")
+	if err != nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if !cfg.Digest || cfg.DigestDay != time.Monday || cfg.DigestHour != 9 {
+		t.Errorf("Expected a Monday 09:00 digest, got %+v", cfg)
+	}
+
+	// Enabling starts the schedule from now
+	lastDigest, err := GetChannelLastDigest(bot, "channel-00000")
+	if err != nil {
+		t.Fatalf("Failed to get last digest: %v", err)
+	}
+	if lastDigest.Before(before) {
+		t.Errorf("Expected the last digest to be set to now, got %v", lastDigest)
+	}
+
+	postedAt := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	if err := SetChannelLastDigest(bot, "channel-00000", postedAt); err != nil {
+		t.Fatalf("Failed to set last digest: %v", err)
+	}
+	lastDigest, err = GetChannelLastDigest(bot, "channel-00000")
+	if err != nil {
+		t.Fatalf("Failed to get last digest: %v", err)
+	}
+	if !lastDigest.Equal(postedAt) {
+		t.Errorf("Expected last digest %v, got %v", postedAt, lastDigest)
+	}
+
+	// Sunday is weekday 0 and must not read as digest mode off
+	if err := UpdateChannelDigest(bot, "channel-00000", true, time.Sunday, 0); err != nil {
+		t.Fatalf("Failed to update digest: %v", err)
+	}
+	if cfg, _ = GetChannelConfig(bot, "channel-00000"); !cfg.Digest || cfg.DigestDay != time.Sunday {
+		t.Errorf("Expected a Sunday digest, got %+v", cfg)
+	}
+
+	if err := UpdateChannelDigest(bot, "channel-00000", false, 0, 0); err != nil {
+		t.Fatalf("Failed to disable digest: %v", err)
+	}
+	if cfg, _ = GetChannelConfig(bot, "channel-00000"); cfg.Digest {
+		t.Error("Expected digest mode to be disabled")
+	}
+
+	if err := UpdateChannelDigest(bot, "channel-00000", true, time.Monday, 24); err == nil {
+		t.Error("Expected an error for an invalid hour")
+	}
+	if err := UpdateChannelDigest(bot, "missing", true, time.Monday, 9); err == nil {
+		t.Error("Expected an error for an unregistered channel")
+	}
+}
+
+func TestGetDigestNews(t *testing.T) {
+	bot := seedChannelDatabase(t, 2)
+
+	newsItems := []types.NewsItem{
+		{ID: 1, Title: "Old", Updated: time.Now()},
+		{ID: 2, Title: "Recent", Updated: time.Now()},
+		{ID: 3, Title: "Elsewhere", Updated: time.Now()},
+	}
+	if err := CacheNews(bot, newsItems); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+
+	since := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	posts := []struct {
+		newsID    int64
+		channelID string
+		postedAt  time.Time
+	}{
+		{1, "channel-00000", since.Add(-time.Hour)},
+		{2, "channel-00000", since.Add(time.Hour)},
+		{2, "channel-00001", since.Add(2 * time.Hour)},
+		{3, "channel-00001", since.Add(3 * time.Hour)},
+		{4, "channel-00000", since.Add(time.Hour)}, // no longer cached
+	}
+	for _, p := range posts {
+		if _, err := bot.DB.Exec(`INSERT INTO posted_news (news_id, channel_id, posted_at) VALUES (?, ?, ?)`,
+			p.newsID, p.channelID, p.postedAt.Format("2006-01-02 15:04:05")); err != nil {
+			t.Fatalf("Failed to insert posted news: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		channelID string
+		expected  []int64
+	}{
+		{"one channel", "channel-00000", []int64{2}},
+		{"all channels, each item once, newest first", "", []int64{3, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := GetDigestNews(bot, tt.channelID, since)
+			if err != nil {
+				t.Fatalf("Failed to get digest news: %v", err)
+			}
+			var ids []int64
+			for _, item := range items {
+				ids = append(ids, item.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("Expected news %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+}
+
+func TestUpdateChannelCreateEvents(t *testing.T) {
+	bot := seedChannelDatabase(t, 1)
+
+	cfg, err := GetChannelConfig(bot, "channel-00000")
+	if err != nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if cfg.CreateEvents {
+		t.Error("Expected scheduled events to be off by default")
+	}
+
+	if err := UpdateChannelCreateEvents(bot, "channel-00000", true); err != nil {
+		t.Fatalf("Failed to update create events: %v", err)
+	}
+	if cfg, err = GetChannelConfig(bot, "channel-00000"); err != nil || !cfg.CreateEvents {
+		t.Errorf("Expected scheduled events to be created, got %+v (%v)", cfg, err)
+	}
+
+	if err := UpdateChannelCreateEvents(bot, "missing", true); err == nil {
+		t.Error("Expected an error for an unregistered channel")
+	}
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// GetUserCheckpoint returns when a user last caught up on a server's news with
+// /stobot_catch_me_up, or the zero time if never.
+func GetUserCheckpoint(b *types.Bot, userID, guildID string) (time.Time, error) {
+	var checkedAt sql.NullTime
+	err := b.DB.QueryRow(`SELECT checked_at FROM user_checkpoints WHERE user_id = ? AND guild_id = ?`,
+		userID, guildID).Scan(&checkedAt)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get checkpoint: %v", err)
+	}
+	return checkedAt.Time, nil
+}
+
+// SetUserCheckpoint records when a user caught up on a server's news.
+func SetUserCheckpoint(b *types.Bot, userID, guildID string, at time.Time) error {
+	query := `INSERT INTO user_checkpoints (user_id, guild_id, checked_at) VALUES (?, ?, ?)
+			  ON CONFLICT(user_id, guild_id) DO UPDATE SET checked_at = excluded.checked_at`
+	if _, err := b.DB.Exec(query, userID, guildID, at.UTC().Format("2006-01-02 15:04:05")); err != nil {
+		return fmt.Errorf("failed to set checkpoint: %v", err)
+	}
+	return nil
+}
+
+// RemoveUserCheckpoint forgets when a user caught up on a server's news; the next catch-up
+// covers the default period again.
+func RemoveUserCheckpoint(b *types.Bot, userID, guildID string) error {
+	if _, err := b.DB.Exec(`DELETE FROM user_checkpoints WHERE user_id = ? AND guild_id = ?`, userID, guildID); err != nil {
+		return fmt.Errorf("failed to remove checkpoint: %v", err)
+	}
+	return nil
+}
+
+// GetNewsPostedSince returns the cached news posted to a channel since a time, newest first.
+// Post times are whole seconds, so news posted in the second of since is included.
+// News marked as posted without a post, e.g. skipped or marked when the channel was registered,
+// is left out.
+func GetNewsPostedSince(b *types.Bot, channelID string, since time.Time) ([]types.NewsItem, error) {
+	query := `SELECT nc.id, nc.title, nc.summary, nc.content, nc.tags, nc.platforms, nc.updated_at, nc.thumbnail_url, nc.url, nc.platform_dates, nc.author
+			  FROM posted_news pn
+			  JOIN news_cache nc ON nc.id = pn.news_id
+			  WHERE pn.channel_id = ? AND pn.posted_at >= ? AND pn.status = ? AND pn.delivery IS NOT NULL
+			  ORDER BY pn.posted_at DESC, nc.id DESC`
+
+	rows, err := b.DB.Query(query, channelID, since.UTC().Format("2006-01-02 15:04:05"), PostStatusSent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query posted news: %v", err)
+	}
+	defer rows.Close()
+
+	return parseNewsRows(rows)
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+func TestUserCheckpoint(t *testing.T) {
+	bot := setupLatencyTest(t)
+
+	checkedAt, err := GetUserCheckpoint(bot, "user-1", "guild-1")
+	if err != nil || !checkedAt.IsZero() {
+		t.Fatalf("Expected no checkpoint, got %v (%v)", checkedAt, err)
+	}
+
+	first := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	second := first.Add(48 * time.Hour)
+	for _, at := range []time.Time{first, second} {
+		if err := SetUserCheckpoint(bot, "user-1", "guild-1", at); err != nil {
+			t.Fatalf("Failed to set checkpoint: %v", err)
+		}
+	}
+	if err := SetUserCheckpoint(bot, "user-1", "guild-2", first); err != nil {
+		t.Fatalf("Failed to set checkpoint: %v", err)
+	}
+
+	// Checkpoints are kept per server
+	if checkedAt, err = GetUserCheckpoint(bot, "user-1", "guild-1"); err != nil || !checkedAt.Equal(second) {
+		t.Errorf("Expected checkpoint %v, got %v (%v)", second, checkedAt, err)
+	}
+	if checkedAt, err = GetUserCheckpoint(bot, "user-1", "guild-2"); err != nil || !checkedAt.Equal(first) {
+		t.Errorf("Expected checkpoint %v in the other server, got %v (%v)", first, checkedAt, err)
+	}
+
+	if err := RemoveUserCheckpoint(bot, "user-1", "guild-1"); err != nil {
+		t.Fatalf("Failed to remove checkpoint: %v", err)
+	}
+	if checkedAt, err = GetUserCheckpoint(bot, "user-1", "guild-1"); err != nil || !checkedAt.IsZero() {
+		t.Errorf("Expected the checkpoint removed, got %v (%v)", checkedAt, err)
+	}
+}
+
+func TestGetNewsPostedSince(t *testing.T) {
+	bot := setupLatencyTest(t)
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	var newsItems []types.NewsItem
+	for id := int64(1); id <= 5; id++ {
+		newsItems = append(newsItems, types.NewsItem{ID: id, Title: "News", Updated: start})
+	}
+	if err := CacheNews(bot, newsItems); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+
+	// News 1 before the checkpoint, 2 and 3 after, 4 to another channel, 5 skipped
+	deliveries := []struct {
+		newsID    int64
+		channelID string
+		at        time.Time
+	}{
+		{1, "channel-a", start.Add(-time.Hour)},
+		{2, "channel-a", start.Add(time.Hour)},
+		{3, "channel-a", start.Add(2 * time.Hour)},
+		{4, "channel-b", start.Add(time.Hour)},
+	}
+	for _, delivery := range deliveries {
+		setClock(t, delivery.at)
+		if err := MarkNewsAsDelivered(bot, newsItems[delivery.newsID-1], delivery.channelID, DeliveryLive); err != nil {
+			t.Fatalf("Failed to mark news as delivered: %v", err)
+		}
+	}
+	if err := MarkNewsAsPosted(bot, 5, "channel-a"); err != nil {
+		t.Fatalf("Failed to mark news as posted: %v", err)
+	}
+
+	posted, err := GetNewsPostedSince(bot, "channel-a", start)
+	if err != nil {
+		t.Fatalf("Failed to get posted news: %v", err)
+	}
+	if ids := newsIDs(posted); !slices.Equal(ids, []int64{3, 2}) {
+		t.Errorf("Expected news 3 and 2, newest first, got %v", ids)
+	}
+}
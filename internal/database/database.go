@@ -1,21 +1,25 @@
 // Package database provides persistent storage and migration logic for STOBot.
 //
-// It manages news caching, channel registration, and posted news tracking using SQLite.
+// It manages news caching, channel registration, and posted news tracking using SQLite, or
+// PostgreSQL when the database path is a postgres:// URL.
 package database
 
 import (
 	"bufio"
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/FracKenA/sto_news_discord_bot/internal/types"
-
-	log "github.com/sirupsen/logrus"
 )
 
 // DatabaseOptions controls how database operations behave
@@ -29,19 +33,30 @@ func DefaultDatabaseOptions() DatabaseOptions {
 // BulkDatabaseOptions returns options optimized for bulk operations
 func BulkDatabaseOptions() DatabaseOptions {
 	return DatabaseOptions{
-		UseBatch:     true,
-		IgnoreErrors: true,
-		RetryCount:   3,
-		LogProgress:  true,
+		UseBatch:      true,
+		IgnoreErrors:  true,
+		RetryCount:    3,
+		LogProgress:   true,
+		RetryDelay:    50 * time.Millisecond,
+		MaxRetryDelay: 500 * time.Millisecond,
 	}
 }
 
 // InitDatabase initializes and returns a database connection
 func InitDatabase(dbPath string) (*sql.DB, error) {
-	return initDatabase(dbPath)
+	return initDatabase(dbPath, DefaultInitOptions())
+}
+
+// InitDatabaseWithOptions initializes and returns a database connection with custom options.
+func InitDatabaseWithOptions(dbPath string, options InitOptions) (*sql.DB, error) {
+	return initDatabase(dbPath, options)
 }
 
-func initDatabase(dbPath string) (*sql.DB, error) {
+func initDatabase(dbPath string, options InitOptions) (*sql.DB, error) {
+	if IsPostgresURL(dbPath) {
+		return initPostgres(dbPath, options)
+	}
+
 	// Create data directory if it doesn't exist and path starts with /data
 	if strings.HasPrefix(dbPath, "/data/") {
 		if err := os.MkdirAll("/data", 0755); err != nil {
@@ -57,40 +72,120 @@ func initDatabase(dbPath string) (*sql.DB, error) {
 		}
 	}
 
-	db, err := sql.Open("sqlite3", dbPath)
+	// Migrations rebuild tables and copy rows that may predate the foreign keys, so they run
+	// without foreign key enforcement, as SQLite recommends for schema changes
+	migrationOptions := options
+	migrationOptions.ForeignKeys = false
+	db, err := openDatabase(dbPath, migrationOptions)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %v", err)
+		return nil, err
 	}
 
-	// Test connection
-	if err := db.Ping(); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %v", err)
+	// Back up existing databases before migrating them
+	if options.MigrationBackup {
+		if _, err := backupBeforeMigration(db, dbPath); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to back up database before migration: %v", err)
+		}
 	}
 
 	// Create tables
 	if err := createTables(db); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to create tables: %v", err)
 	}
 
 	// Add migration to add tags column to existing databases
-	if err := migrateDatabase(db); err != nil {
+	if err := migrate(db); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to migrate database: %v", err)
 	}
 
-	log.Info("Database initialized successfully")
+	if err := setSchemaVersion(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	// Reopen with the requested pragmas; an in-memory database would be lost on reopening
+	if options.ForeignKeys && dbPath != ":memory:" {
+		db.Close()
+		if db, err = openDatabase(dbPath, options); err != nil {
+			return nil, err
+		}
+	}
+
+	logger().Info("Database initialized successfully")
+	return db, nil
+}
+
+// openDatabase opens the database at dbPath with the connection settings of options.
+func openDatabase(dbPath string, options InitOptions) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", databaseDSN(dbPath, options))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(options.MaxOpenConns)
+
+	// Test connection
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %v", err)
+	}
+
 	return db, nil
 }
 
+// databaseDSN adds the connection pragmas of options to a database path. The driver applies
+// them to every new connection. A busy timeout makes writes wait for a lock held by another
+// connection (e.g. a bulk populate next to the poller) instead of failing with SQLITE_BUSY.
+func databaseDSN(dbPath string, options InitOptions) string {
+	var params []string
+	if options.JournalMode != "" {
+		params = append(params, "_journal_mode="+options.JournalMode)
+	}
+	if options.BusyTimeout > 0 {
+		params = append(params, fmt.Sprintf("_busy_timeout=%d", options.BusyTimeout.Milliseconds()))
+	}
+	if options.ForeignKeys {
+		params = append(params, "_foreign_keys=on")
+	}
+	if len(params) == 0 {
+		return dbPath
+	}
+
+	separator := "?"
+	if strings.Contains(dbPath, "?") {
+		separator = "&"
+	}
+	return dbPath + separator + strings.Join(params, "&")
+}
+
+// waitForRetry waits before the given retry of a database write (1 for the first retry).
+// It returns ctx's error if ctx is cancelled first.
+func waitForRetry(ctx context.Context, options DatabaseOptions, retry int) error {
+	delay := options.RetryBackoff(retry)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 func migrateDatabase(db *sql.DB) error {
 	// Check if tags column exists, if not add it
-	var tagsColumnExists bool
-	err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('news_cache') WHERE name='tags'`).Scan(&tagsColumnExists)
+	tagsColumnExists, err := columnExists(db, "news_cache", "tags")
 	if err != nil {
-		return fmt.Errorf("failed to check for tags column: %v", err)
+		return err
 	}
 
 	if !tagsColumnExists {
-		log.Info("Adding tags column to news_cache table")
+		logger().Info("Adding tags column to news_cache table")
 		if _, err := db.Exec(`ALTER TABLE news_cache ADD COLUMN tags TEXT`); err != nil {
 			return fmt.Errorf("failed to add tags column: %v", err)
 		}
@@ -102,42 +197,43 @@ func migrateDatabase(db *sql.DB) error {
 	}
 
 	// Check if content column exists, if not add it
-	var contentColumnExists bool
-	err = db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('news_cache') WHERE name='content'`).Scan(&contentColumnExists)
+	contentColumnExists, err := columnExists(db, "news_cache", "content")
 	if err != nil {
-		return fmt.Errorf("failed to check for content column: %v", err)
+		return err
 	}
 
 	if !contentColumnExists {
-		log.Info("Adding content column to news_cache table")
+		logger().Info("Adding content column to news_cache table")
 		if _, err := db.Exec(`ALTER TABLE news_cache ADD COLUMN content TEXT`); err != nil {
 			return fmt.Errorf("failed to add content column: %v", err)
 		}
 	}
 
 	// Check if old tag column exists (for cleanup)
-	var tagColumnExists bool
-	err = db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('news_cache') WHERE name='tag'`).Scan(&tagColumnExists)
+	tagColumnExists, err := columnExists(db, "news_cache", "tag")
 	if err != nil {
-		return fmt.Errorf("failed to check for tag column: %v", err)
+		return err
 	}
 
 	if tagColumnExists {
-		log.Info("Found legacy 'tag' column - this can be removed in a future version")
+		logger().Info("Found legacy 'tag' column - this can be removed in a future version")
 		// Note: We don't automatically remove it to avoid data loss
 		// In production, you might want to migrate data from 'tag' to 'tags' first
 	}
 
-	// Check if posted_news table has the old schema (news_id as PRIMARY KEY)
+	// Check if posted_news table has the old schema (news_id as PRIMARY KEY), which only
+	// databases of backends with legacy schemas can have
 	var postedNewsSchema string
-	err = db.QueryRow(`SELECT sql FROM sqlite_master WHERE type='table' AND name='posted_news'`).Scan(&postedNewsSchema)
-	if err != nil && err != sql.ErrNoRows {
-		return fmt.Errorf("failed to check posted_news schema: %v", err)
+	if dialectOf(db).legacySchemas {
+		err = db.QueryRow(`SELECT sql FROM sqlite_master WHERE type='table' AND name='posted_news'`).Scan(&postedNewsSchema)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("failed to check posted_news schema: %v", err)
+		}
 	}
 
 	// Check if the schema has the old PRIMARY KEY on news_id
 	if strings.Contains(postedNewsSchema, "news_id INTEGER PRIMARY KEY") {
-		log.Info("Migrating posted_news table to new schema")
+		logger().Info("Migrating posted_news table to new schema")
 
 		// Create backup table with old data
 		if _, err := db.Exec(`CREATE TABLE posted_news_backup AS SELECT * FROM posted_news`); err != nil {
@@ -171,13 +267,13 @@ func migrateDatabase(db *sql.DB) error {
 		}
 
 		if hasPostedAt {
-			if _, err := db.Exec(`INSERT OR IGNORE INTO posted_news (news_id, channel_id, posted_at) 
-				SELECT news_id, channel_id, posted_at FROM posted_news_backup`); err != nil {
+			if _, err := db.Exec(dialectOf(db).insertIgnore("posted_news", []string{"news_id", "channel_id", "posted_at"},
+				`SELECT news_id, channel_id, posted_at FROM posted_news_backup`)); err != nil {
 				return fmt.Errorf("failed to restore posted_news data: %v", err)
 			}
 		} else {
-			if _, err := db.Exec(`INSERT OR IGNORE INTO posted_news (news_id, channel_id) 
-				SELECT news_id, channel_id FROM posted_news_backup`); err != nil {
+			if _, err := db.Exec(dialectOf(db).insertIgnore("posted_news", []string{"news_id", "channel_id"},
+				`SELECT news_id, channel_id FROM posted_news_backup`)); err != nil {
 				return fmt.Errorf("failed to restore posted_news data: %v", err)
 			}
 		}
@@ -195,23 +291,109 @@ func migrateDatabase(db *sql.DB) error {
 			return fmt.Errorf("failed to create news_id index: %v", err)
 		}
 
-		log.Info("Successfully migrated posted_news table")
+		logger().Info("Successfully migrated posted_news table")
 	}
 
 	// Check if environment column exists in channels table, if not add it
-	var environmentColumnExists bool
-	err = db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('channels') WHERE name='environment'`).Scan(&environmentColumnExists)
+	environmentColumnExists, err := columnExists(db, "channels", "environment")
 	if err != nil {
-		return fmt.Errorf("failed to check for environment column: %v", err)
+		return err
 	}
 
 	if !environmentColumnExists {
-		log.Info("Adding environment column to channels table")
+		logger().Info("Adding environment column to channels table")
 		if _, err := db.Exec(`ALTER TABLE channels ADD COLUMN environment TEXT NOT NULL DEFAULT 'PROD' CHECK (environment IN ('DEV', 'PROD'))`); err != nil {
 			return fmt.Errorf("failed to add environment column: %v", err)
 		}
 	}
 
+	// Columns added after the initial schema; existing rows get the column default (NULL if none)
+	columns := []struct {
+		table      string
+		column     string
+		definition string
+	}{
+		{"channels", "spoiler_tags", "TEXT NOT NULL DEFAULT ''"},
+		{"channels", "auto_publish", "INTEGER NOT NULL DEFAULT 0"},
+		{"channels", "strict_patch_notes", "INTEGER NOT NULL DEFAULT 0"},
+		{"channels", "tags", "TEXT NOT NULL DEFAULT ''"},
+		{"channels", "excluded_tags", "TEXT NOT NULL DEFAULT ''"},
+		{"channels", "ping_role", "TEXT NOT NULL DEFAULT ''"},
+		{"channels", "digest_day", "INTEGER"},
+		{"channels", "digest_hour", "INTEGER NOT NULL DEFAULT 0"},
+		{"channels", "last_digest_at", "DATETIME"},
+		{"channels", "webhook_url", "TEXT NOT NULL DEFAULT ''"},
+		{"channels", "quiet_hours_start", "INTEGER"},
+		{"channels", "quiet_hours_end", "INTEGER"},
+		{"channels", "guild_id", "TEXT"},
+		{"channels", "locale", "TEXT NOT NULL DEFAULT 'en'"},
+		{"channels", "disabled", "INTEGER NOT NULL DEFAULT 0"},
+		{"channels", "post_failures", "INTEGER NOT NULL DEFAULT 0"},
+		{"channels", "create_threads", "INTEGER NOT NULL DEFAULT 0"},
+		{"channels", "paused", "INTEGER NOT NULL DEFAULT 0"},
+		{"channels", "pause_hold", "INTEGER NOT NULL DEFAULT 0"},
+		{"channels", "max_posts_per_cycle", "INTEGER NOT NULL DEFAULT 0"},
+		{"channels", "update_notices", "TEXT NOT NULL DEFAULT 'notice'"},
+		{"channels", "show_galleries", "INTEGER NOT NULL DEFAULT 0"},
+		{"channels", "message_style", "TEXT NOT NULL DEFAULT 'embed'"},
+		{"channels", "create_events", "INTEGER NOT NULL DEFAULT 0"},
+		{"posted_news", "posted_by", "TEXT"},
+		{"posted_news", "bot_version", "TEXT"},
+		{"posted_news", "message_id", "TEXT"},
+		{"posted_news", "publish_status", "TEXT"},
+		{"posted_news", "latency_seconds", "INTEGER"},
+		{"posted_news", "delivery", "TEXT"},
+		{"posted_news", "status", "TEXT NOT NULL DEFAULT 'sent'"},
+		{"posted_news", "thread_id", "TEXT"},
+		{"news_cache", "fingerprint", "TEXT"},
+		{"news_cache", "url", "TEXT"},
+		{"news_cache", "platform_dates", "TEXT"},
+		{"news_cache", "author", "TEXT"},
+	}
+	for _, c := range columns {
+		if err := addColumnIfMissing(db, c.table, c.column, c.definition); err != nil {
+			return err
+		}
+	}
+
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_channels_guild ON channels(guild_id)`); err != nil {
+		return fmt.Errorf("failed to create guild index: %v", err)
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_news_cache_fingerprint ON news_cache(fingerprint)`); err != nil {
+		return fmt.Errorf("failed to create fingerprint index: %v", err)
+	}
+	// Weekly and monthly statistics filter posts by when they were posted, overall and per channel;
+	// the overall index also covers news_id so popular news is counted from the index alone
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_posted_news_posted_at ON posted_news(posted_at, news_id)`); err != nil {
+		return fmt.Errorf("failed to create posted_at index: %v", err)
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_posted_news_channel_posted_at ON posted_news(channel_id, posted_at)`); err != nil {
+		return fmt.Errorf("failed to create channel posted_at index: %v", err)
+	}
+	if err := backfillNewsFingerprints(db); err != nil {
+		return err
+	}
+	if err := normalizeCachedTags(db); err != nil {
+		return err
+	}
+
+	return migrateNewsFTS(db)
+}
+
+// addColumnIfMissing adds a column to a table unless it already exists.
+func addColumnIfMissing(db *sql.DB, table, column, definition string) error {
+	exists, err := columnExists(db, table, column)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		logger().Infof("Adding %s column to %s table", column, table)
+		if _, err := db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition)); err != nil {
+			return fmt.Errorf("failed to add %s column: %v", column, err)
+		}
+	}
+
 	return nil
 }
 
@@ -221,6 +403,30 @@ func createTables(db *sql.DB) error {
 			id TEXT PRIMARY KEY,
 			platforms TEXT NOT NULL DEFAULT 'pc,xbox,ps',
 			environment TEXT NOT NULL DEFAULT 'PROD' CHECK (environment IN ('DEV', 'PROD')),
+			spoiler_tags TEXT NOT NULL DEFAULT '',
+			auto_publish INTEGER NOT NULL DEFAULT 0,
+			strict_patch_notes INTEGER NOT NULL DEFAULT 0,
+			tags TEXT NOT NULL DEFAULT '',
+			excluded_tags TEXT NOT NULL DEFAULT '',
+			ping_role TEXT NOT NULL DEFAULT '',
+			digest_day INTEGER,
+			digest_hour INTEGER NOT NULL DEFAULT 0,
+			last_digest_at DATETIME,
+			webhook_url TEXT NOT NULL DEFAULT '',
+			quiet_hours_start INTEGER,
+			quiet_hours_end INTEGER,
+			guild_id TEXT,
+			locale TEXT NOT NULL DEFAULT 'en',
+			disabled INTEGER NOT NULL DEFAULT 0,
+			post_failures INTEGER NOT NULL DEFAULT 0,
+			create_threads INTEGER NOT NULL DEFAULT 0,
+			paused INTEGER NOT NULL DEFAULT 0,
+			pause_hold INTEGER NOT NULL DEFAULT 0,
+			max_posts_per_cycle INTEGER NOT NULL DEFAULT 0,
+			update_notices TEXT NOT NULL DEFAULT 'notice',
+			show_galleries INTEGER NOT NULL DEFAULT 0,
+			message_style TEXT NOT NULL DEFAULT 'embed',
+			create_events INTEGER NOT NULL DEFAULT 0,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
 		)`,
@@ -229,6 +435,14 @@ func createTables(db *sql.DB) error {
 			news_id INTEGER NOT NULL,
 			channel_id TEXT NOT NULL,
 			posted_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			posted_by TEXT,
+			bot_version TEXT,
+			message_id TEXT,
+			publish_status TEXT,
+			latency_seconds INTEGER,
+			delivery TEXT,
+			status TEXT NOT NULL DEFAULT 'sent',
+			thread_id TEXT,
 			UNIQUE(news_id, channel_id),
 			FOREIGN KEY (channel_id) REFERENCES channels(id)
 		)`,
@@ -241,12 +455,84 @@ func createTables(db *sql.DB) error {
 			platforms TEXT,
 			updated_at DATETIME,
 			thumbnail_url TEXT,
-			fetched_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			fetched_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			fingerprint TEXT,
+			url TEXT,
+			platform_dates TEXT,
+			author TEXT
+		)`,
+		`CREATE TABLE IF NOT EXISTS localized_news (
+			id INTEGER NOT NULL,
+			locale TEXT NOT NULL,
+			title TEXT NOT NULL,
+			summary TEXT,
+			content TEXT,
+			tags TEXT,
+			platforms TEXT,
+			updated_at DATETIME,
+			thumbnail_url TEXT,
+			url TEXT,
+			fetched_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (id, locale)
+		)`,
+		`CREATE TABLE IF NOT EXISTS user_subscriptions (
+			user_id TEXT PRIMARY KEY,
+			tags TEXT NOT NULL DEFAULT '',
+			platforms TEXT NOT NULL DEFAULT 'pc,xbox,ps',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS user_deliveries (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			news_id INTEGER NOT NULL,
+			user_id TEXT NOT NULL,
+			status TEXT NOT NULL,
+			delivered_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(news_id, user_id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS command_usage (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			command_name TEXT NOT NULL,
+			guild_id TEXT NOT NULL DEFAULT '',
+			user_hash TEXT NOT NULL,
+			used_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS populate_state (
+			tag TEXT PRIMARY KEY,
+			last_offset INTEGER NOT NULL DEFAULT 0,
+			completed INTEGER NOT NULL DEFAULT 0,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS held_news (
+			news_id INTEGER NOT NULL,
+			channel_id TEXT NOT NULL,
+			held_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (news_id, channel_id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS user_checkpoints (
+			user_id TEXT NOT NULL,
+			guild_id TEXT NOT NULL DEFAULT '',
+			checked_at DATETIME NOT NULL,
+			PRIMARY KEY (user_id, guild_id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS dry_run_posted (
+			news_id INTEGER NOT NULL,
+			channel_id TEXT NOT NULL,
+			delivery TEXT,
+			posted_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (news_id, channel_id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS news_events (
+			news_id INTEGER NOT NULL,
+			guild_id TEXT NOT NULL,
+			event_id TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (news_id, guild_id)
 		)`,
 		`CREATE INDEX IF NOT EXISTS idx_posted_news_channel ON posted_news(channel_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_posted_news_id ON posted_news(news_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_news_cache_tags ON news_cache(tags)`,
 		`CREATE INDEX IF NOT EXISTS idx_news_cache_updated ON news_cache(updated_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_command_usage_used ON command_usage(used_at)`,
 	}
 
 	for _, query := range queries {
@@ -256,59 +542,59 @@ func createTables(db *sql.DB) error {
 	}
 
 	// Add migration to add tags column to existing databases
-	if err := migrateDatabase(db); err != nil {
+	if err := migrate(db); err != nil {
 		return fmt.Errorf("failed to migrate database: %v", err)
 	}
 
 	return nil
 }
 
-// AddChannel registers a new channel in the database.
+// AddChannel registers a new channel in the database for the bot's environment, or PROD when
+// the bot has none.
 func AddChannel(b *types.Bot, channelID string) error {
-	// Check if this is a new channel registration
-	var exists int
-	checkQuery := `SELECT 1 FROM channels WHERE id = ?`
-	err := b.DB.QueryRow(checkQuery, channelID).Scan(&exists)
-	isNewChannel := (err == sql.ErrNoRows)
-
-	// Register the channel
-	query := `INSERT OR REPLACE INTO channels (id, platforms, environment, updated_at) 
-			  VALUES (?, 'pc,xbox,ps', 'PROD', CURRENT_TIMESTAMP)`
+	environment := "PROD"
+	if b.Config != nil && b.Config.Environment != "" {
+		environment = b.Config.Environment
+	}
+	return AddChannelWithEnvironment(b, channelID, environment)
+}
 
-	_, err = b.DB.Exec(query, channelID)
+// AddChannelWithEnvironment registers a new channel in the database with specified environment.
+func AddChannelWithEnvironment(b *types.Bot, channelID string, environment string) error {
+	isNewChannel, err := registerChannel(b, channelID, environment)
 	if err != nil {
-		return fmt.Errorf("failed to add channel: %v", err)
+		return err
 	}
 
 	// If this is a new channel, mark all existing cached news as posted to prevent spam
 	if isNewChannel {
-		log.Infof("New channel registered: %s, marking existing news as posted", channelID)
+		logger().WithField("channel_id", channelID).Infof("New channel registered: %s (environment: %s), marking existing news as posted", channelID, environment)
 
-		// Get all cached news items
-		allNews, err := GetAllCachedNews(b)
-		if err != nil {
-			log.Errorf("Failed to get cached news for new channel %s: %v", channelID, err)
-			// Don't fail the registration, just log the error
-		} else if len(allNews) > 0 {
-			// Mark all existing news as posted to this new channel using bulk options
-			err = MarkMultipleNewsAsPosted(b, allNews, []string{channelID}, BulkDatabaseOptions())
-			if err != nil {
-				log.Errorf("Failed to mark existing news as posted for new channel %s: %v", channelID, err)
-				// Don't fail the registration, just log the error
-			} else {
-				log.Infof("Marked %d existing news items as posted for new channel %s", len(allNews), channelID)
-			}
+		// Don't fail the registration, the error is logged
+		if _, err := MarkCachedNewsAsPosted(b, channelID); err != nil {
+			logger().WithField("channel_id", channelID).Errorf("Failed to mark existing news as posted for new channel %s: %v", channelID, err)
 		}
 	}
 
 	return nil
 }
 
-// AddChannelWithEnvironment registers a new channel in the database with specified environment.
-func AddChannelWithEnvironment(b *types.Bot, channelID string, environment string) error {
+// RegisterChannel registers a channel in the bot's environment like AddChannel, but leaves the
+// cached news unmarked, and reports whether the channel is new. The caller decides what a new
+// channel is sent of the cached news, e.g. with MarkCachedNewsAsPosted.
+func RegisterChannel(b *types.Bot, channelID string) (bool, error) {
+	environment := "PROD"
+	if b.Config != nil && b.Config.Environment != "" {
+		environment = b.Config.Environment
+	}
+	return registerChannel(b, channelID, environment)
+}
+
+// registerChannel registers a channel with the default platforms and reports whether it is new.
+func registerChannel(b *types.Bot, channelID string, environment string) (bool, error) {
 	// Validate environment value
 	if environment != "DEV" && environment != "PROD" {
-		return fmt.Errorf("invalid environment value: %s. Must be 'DEV' or 'PROD'", environment)
+		return false, fmt.Errorf("invalid environment value: %s. Must be 'DEV' or 'PROD'", environment)
 	}
 
 	// Check if this is a new channel registration
@@ -318,36 +604,40 @@ func AddChannelWithEnvironment(b *types.Bot, channelID string, environment strin
 	isNewChannel := (err == sql.ErrNoRows)
 
 	// Register the channel
-	query := `INSERT OR REPLACE INTO channels (id, platforms, environment, updated_at) 
-			  VALUES (?, 'pc,xbox,ps', ?, CURRENT_TIMESTAMP)`
+	query := dialectOf(b.DB).upsert("channels", []string{"id"}, []string{"id", "platforms", "environment", "updated_at"},
+		`VALUES (?, 'pc,xbox,ps', ?, CURRENT_TIMESTAMP)`)
 
 	_, err = b.DB.Exec(query, channelID, environment)
 	if err != nil {
-		return fmt.Errorf("failed to add channel: %v", err)
+		return false, fmt.Errorf("failed to add channel: %v", err)
 	}
 
-	// If this is a new channel, mark all existing cached news as posted to prevent spam
-	if isNewChannel {
-		log.Infof("New channel registered: %s (environment: %s), marking existing news as posted", channelID, environment)
+	return isNewChannel, nil
+}
 
-		// Get all cached news items
-		allNews, err := GetAllCachedNews(b)
-		if err != nil {
-			log.Errorf("Failed to get cached news for new channel %s: %v", channelID, err)
-			// Don't fail the registration, just log the error
-		} else if len(allNews) > 0 {
-			// Mark all existing news as posted to this new channel using bulk options
-			err = MarkMultipleNewsAsPosted(b, allNews, []string{channelID}, BulkDatabaseOptions())
-			if err != nil {
-				log.Errorf("Failed to mark existing news as posted for new channel %s: %v", channelID, err)
-				// Don't fail the registration, just log the error
-			} else {
-				log.Infof("Marked %d existing news items as posted for new channel %s", len(allNews), channelID)
-			}
-		}
+// MarkCachedNewsAsPosted marks every cached news item except the given IDs as posted to a
+// channel and returns how many were marked. Only news IDs are loaded, so this stays cheap for
+// large caches.
+func MarkCachedNewsAsPosted(b *types.Bot, channelID string, except ...int64) (int, error) {
+	newsIDs, err := GetAllCachedNewsIDs(b)
+	if err != nil {
+		return 0, err
+	}
+	if len(except) > 0 {
+		newsIDs = slices.DeleteFunc(newsIDs, func(id int64) bool {
+			return slices.Contains(except, id)
+		})
+	}
+	if len(newsIDs) == 0 {
+		return 0, nil
 	}
 
-	return nil
+	// Mark all existing news as posted to this channel using bulk options
+	if err := MarkNewsIDsAsPosted(b, newsIDs, []string{channelID}, BulkDatabaseOptions()); err != nil {
+		return 0, err
+	}
+	logger().WithField("channel_id", channelID).Infof("Marked %d existing news items as posted for channel %s", len(newsIDs), channelID)
+	return len(newsIDs), nil
 }
 
 // RemoveChannel removes a channel and its associated posted news entries from the database.
@@ -358,20 +648,25 @@ func RemoveChannel(b *types.Bot, channelID string) error {
 	}
 	defer func() {
 		if rollbackErr := tx.Rollback(); rollbackErr != nil {
-			log.Printf("Warning: failed to rollback transaction: %v", rollbackErr)
+			logger().Printf("Warning: failed to rollback transaction: %v", rollbackErr)
 		}
 	}()
 
-	// Remove from channels
-	_, err = tx.Exec("DELETE FROM channels WHERE id = ?", channelID)
+	// Remove posted news entries for this channel first; they reference the channel
+	_, err = tx.Exec("DELETE FROM posted_news WHERE channel_id = ?", channelID)
 	if err != nil {
-		return fmt.Errorf("failed to remove channel: %v", err)
+		return fmt.Errorf("failed to remove posted news: %v", err)
 	}
 
-	// Remove posted news entries for this channel
-	_, err = tx.Exec("DELETE FROM posted_news WHERE channel_id = ?", channelID)
+	_, err = tx.Exec("DELETE FROM held_news WHERE channel_id = ?", channelID)
 	if err != nil {
-		return fmt.Errorf("failed to remove posted news: %v", err)
+		return fmt.Errorf("failed to remove held news: %v", err)
+	}
+
+	// Remove from channels
+	_, err = tx.Exec("DELETE FROM channels WHERE id = ?", channelID)
+	if err != nil {
+		return fmt.Errorf("failed to remove channel: %v", err)
 	}
 
 	return tx.Commit()
@@ -415,13 +710,19 @@ func GetRegisteredChannels(b *types.Bot) ([]string, error) {
 	return channels, nil
 }
 
-// UpdateChannelPlatforms updates the platforms associated with a channel.
+// UpdateChannelPlatforms updates the platforms associated with a channel. Platforms are normalized
+// with types.NormalizePlatforms; unknown platforms are rejected.
 func UpdateChannelPlatforms(b *types.Bot, channelID string, platforms []string) error {
+	platforms, err := types.NormalizePlatforms(platforms)
+	if err != nil {
+		return err
+	}
+
 	query := `UPDATE channels SET platforms = ?, updated_at = CURRENT_TIMESTAMP 
 			  WHERE id = ?`
 
 	platformsStr := strings.Join(platforms, ",")
-	_, err := b.DB.Exec(query, platformsStr, channelID)
+	_, err = b.DB.Exec(query, platformsStr, channelID)
 	if err != nil {
 		return fmt.Errorf("failed to update channel platforms: %v", err)
 	}
@@ -429,35 +730,33 @@ func UpdateChannelPlatforms(b *types.Bot, channelID string, platforms []string)
 	return nil
 }
 
-// GetChannelEnvironment retrieves the environment associated with a channel.
-func GetChannelEnvironment(b *types.Bot, channelID string) (string, error) {
-	var environment string
-	query := "SELECT environment FROM channels WHERE id = ?"
+// GetChannelTags retrieves the news tags posted to a channel. An empty list means all tags.
+func GetChannelTags(b *types.Bot, channelID string) ([]string, error) {
+	var tags string
+	query := "SELECT tags FROM channels WHERE id = ?"
 
-	err := b.DB.QueryRow(query, channelID).Scan(&environment)
+	err := b.DB.QueryRow(query, channelID).Scan(&tags)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return "PROD", nil // Default to PROD if channel not found
+			return []string{}, nil // Channel not registered
 		}
-		return "", fmt.Errorf("failed to get channel environment: %v", err)
+		return nil, fmt.Errorf("failed to get channel tags: %v", err)
 	}
 
-	return environment, nil
-}
-
-// UpdateChannelEnvironment updates the environment associated with a channel.
-func UpdateChannelEnvironment(b *types.Bot, channelID string, environment string) error {
-	// Validate environment value
-	if environment != "DEV" && environment != "PROD" {
-		return fmt.Errorf("invalid environment value: %s. Must be 'DEV' or 'PROD'", environment)
+	if tags == "" {
+		return []string{}, nil
 	}
+	return strings.Split(tags, ","), nil
+}
 
-	query := `UPDATE channels SET environment = ?, updated_at = CURRENT_TIMESTAMP 
+// UpdateChannelTags sets the news tags posted to a channel. An empty list posts all tags.
+func UpdateChannelTags(b *types.Bot, channelID string, tags []string) error {
+	query := `UPDATE channels SET tags = ?, updated_at = CURRENT_TIMESTAMP 
 			  WHERE id = ?`
 
-	result, err := b.DB.Exec(query, environment, channelID)
+	result, err := b.DB.Exec(query, strings.Join(tags, ","), channelID)
 	if err != nil {
-		return fmt.Errorf("failed to update channel environment: %v", err)
+		return fmt.Errorf("failed to update channel tags: %v", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -472,126 +771,373 @@ func UpdateChannelEnvironment(b *types.Bot, channelID string, environment string
 	return nil
 }
 
-// GetChannelsByEnvironment retrieves all channels for a specific environment.
-func GetChannelsByEnvironment(b *types.Bot, environment string) ([]string, error) {
-	// Validate environment value
-	if environment != "DEV" && environment != "PROD" {
-		return nil, fmt.Errorf("invalid environment value: %s. Must be 'DEV' or 'PROD'", environment)
+// GetChannelExcludedTags retrieves the news tags a channel opts out of.
+func GetChannelExcludedTags(b *types.Bot, channelID string) ([]string, error) {
+	var excludedTags string
+	query := "SELECT excluded_tags FROM channels WHERE id = ?"
+
+	err := b.DB.QueryRow(query, channelID).Scan(&excludedTags)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return []string{}, nil // Channel not registered
+		}
+		return nil, fmt.Errorf("failed to get channel excluded tags: %v", err)
 	}
 
-	query := "SELECT id FROM channels WHERE environment = ?"
+	if excludedTags == "" {
+		return []string{}, nil
+	}
+	return strings.Split(excludedTags, ","), nil
+}
 
-	rows, err := b.DB.Query(query, environment)
+// SetChannelExcludedTags sets the news tags a channel opts out of. An empty list clears them.
+func SetChannelExcludedTags(b *types.Bot, channelID string, excludedTags []string) error {
+	query := `UPDATE channels SET excluded_tags = ?, updated_at = CURRENT_TIMESTAMP 
+			  WHERE id = ?`
+
+	result, err := b.DB.Exec(query, strings.Join(excludedTags, ","), channelID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query channels by environment: %v", err)
+		return fmt.Errorf("failed to update channel excluded tags: %v", err)
 	}
-	defer rows.Close()
 
-	var channels []string
-	for rows.Next() {
-		var channelID string
-		if err := rows.Scan(&channelID); err != nil {
-			return nil, fmt.Errorf("failed to scan channel: %v", err)
-		}
-		channels = append(channels, channelID)
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
 	}
 
-	return channels, nil
+	if rowsAffected == 0 {
+		return fmt.Errorf("channel %s not found", channelID)
+	}
+
+	return nil
 }
 
-// IsNewsPosted checks if a news item has been posted to a specific channel.
-func IsNewsPosted(b *types.Bot, newsID int64, channelID string) (bool, error) {
-	query := "SELECT 1 FROM posted_news WHERE news_id = ? AND channel_id = ?"
+// GetChannelPingRole retrieves the ID of the role mentioned in a channel's news posts.
+// An empty ID means no role is mentioned.
+func GetChannelPingRole(b *types.Bot, channelID string) (string, error) {
+	var pingRole string
+	query := "SELECT ping_role FROM channels WHERE id = ?"
 
-	var exists int
-	err := b.DB.QueryRow(query, newsID, channelID).Scan(&exists)
+	err := b.DB.QueryRow(query, channelID).Scan(&pingRole)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return false, nil
+			return "", nil // Channel not registered
 		}
-		return false, fmt.Errorf("failed to check if news is posted: %v", err)
+		return "", fmt.Errorf("failed to get channel ping role: %v", err)
 	}
 
-	return true, nil
+	return pingRole, nil
 }
 
-// MarkNewsAsPosted marks a news item as posted to a specific channel.
-func MarkNewsAsPosted(b *types.Bot, newsID int64, channelID string) error {
-	return MarkNewsAsPostedWithOptions(b, newsID, channelID, DefaultDatabaseOptions())
-}
+// UpdateChannelPingRole sets the role mentioned in a channel's news posts. An empty ID disables it.
+func UpdateChannelPingRole(b *types.Bot, channelID string, roleID string) error {
+	query := `UPDATE channels SET ping_role = ?, updated_at = CURRENT_TIMESTAMP 
+			  WHERE id = ?`
 
-// MarkNewsAsPostedWithOptions marks a news item as posted to a specific channel with custom options.
-func MarkNewsAsPostedWithOptions(b *types.Bot, newsID int64, channelID string, options DatabaseOptions) error {
-	query := `INSERT OR IGNORE INTO posted_news (news_id, channel_id) 
-			  VALUES (?, ?)`
+	result, err := b.DB.Exec(query, roleID, channelID)
+	if err != nil {
+		return fmt.Errorf("failed to update channel ping role: %v", err)
+	}
 
-	var err error
-	for attempt := 0; attempt <= options.RetryCount; attempt++ {
-		_, err = b.DB.Exec(query, newsID, channelID)
-		if err == nil {
-			return nil
-		}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
 
-		if attempt < options.RetryCount {
-			log.Debugf("Retry %d/%d for marking news %d as posted: %v", attempt+1, options.RetryCount, newsID, err)
-		}
+	if rowsAffected == 0 {
+		return fmt.Errorf("channel %s not found", channelID)
 	}
 
-	return fmt.Errorf("failed to mark news as posted after %d retries: %v", options.RetryCount, err)
+	return nil
 }
 
-// MarkMultipleNewsAsPosted marks multiple news items as posted to multiple channels with custom options.
-func MarkMultipleNewsAsPosted(b *types.Bot, newsItems []types.NewsItem, channelIDs []string, options DatabaseOptions) error {
-	if !options.UseBatch {
-		// Single operations
-		for _, newsItem := range newsItems {
-			for _, channelID := range channelIDs {
-				if err := MarkNewsAsPostedWithOptions(b, newsItem.ID, channelID, options); err != nil {
-					if !options.IgnoreErrors {
-						return err
-					}
-					log.Debugf("Ignoring error marking news %d as posted to channel %s: %v", newsItem.ID, channelID, err)
-				}
-			}
-		}
-		return nil
+// UpdateChannelWebhook sets the webhook URL a channel's news is posted through. An empty URL
+// posts as the bot again. The URL contains the webhook's token and must not be logged.
+func UpdateChannelWebhook(b *types.Bot, channelID string, webhookURL string) error {
+	query := `UPDATE channels SET webhook_url = ?, updated_at = CURRENT_TIMESTAMP 
+			  WHERE id = ?`
+
+	result, err := b.DB.Exec(query, webhookURL, channelID)
+	if err != nil {
+		return fmt.Errorf("failed to update channel webhook: %v", err)
 	}
 
-	// Batch operation with transaction
-	tx, err := b.DB.Begin()
+	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %v", err)
+		return fmt.Errorf("failed to get rows affected: %v", err)
 	}
-	defer func() {
-		if rollbackErr := tx.Rollback(); rollbackErr != nil {
-			log.Printf("Warning: failed to rollback transaction: %v", rollbackErr)
-		}
-	}()
 
-	query := `INSERT OR IGNORE INTO posted_news (news_id, channel_id) VALUES (?, ?)`
+	if rowsAffected == 0 {
+		return fmt.Errorf("channel %s not found", channelID)
+	}
 
-	total := len(newsItems) * len(channelIDs)
-	processed := 0
+	return nil
+}
 
-	for _, newsItem := range newsItems {
-		for _, channelID := range channelIDs {
-			_, err = tx.Exec(query, newsItem.ID, channelID)
-			if err != nil {
-				if !options.IgnoreErrors {
-					return fmt.Errorf("failed to mark news %d as posted to channel %s: %v", newsItem.ID, channelID, err)
-				}
-				log.Debugf("Ignoring error in batch: news %d to channel %s: %v", newsItem.ID, channelID, err)
-			}
+// UpdateChannelQuietHours sets the UTC hours between which news is held back from a channel.
+// Disabling quiet hours clears the hours.
+func UpdateChannelQuietHours(b *types.Bot, channelID string, enabled bool, start, end int) error {
+	if enabled && (start < 0 || start > 23 || end < 0 || end > 23 || start == end) {
+		return fmt.Errorf("invalid quiet hours: %d to %d", start, end)
+	}
 
-			processed++
+	quietStart := sql.NullInt64{Int64: int64(start), Valid: enabled}
+	quietEnd := sql.NullInt64{Int64: int64(end), Valid: enabled}
+	query := `UPDATE channels SET quiet_hours_start = ?, quiet_hours_end = ?, updated_at = CURRENT_TIMESTAMP 
+			  WHERE id = ?`
+
+	result, err := b.DB.Exec(query, quietStart, quietEnd, channelID)
+	if err != nil {
+		return fmt.Errorf("failed to update channel quiet hours: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("channel %s not found", channelID)
+	}
+
+	return nil
+}
+
+// GetChannelSpoilerTags retrieves the tags whose articles are posted behind spoiler markers in a channel.
+func GetChannelSpoilerTags(b *types.Bot, channelID string) ([]string, error) {
+	var spoilerTags string
+	query := "SELECT spoiler_tags FROM channels WHERE id = ?"
+
+	err := b.DB.QueryRow(query, channelID).Scan(&spoilerTags)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return []string{}, nil // Channel not registered
+		}
+		return nil, fmt.Errorf("failed to get channel spoiler tags: %v", err)
+	}
+
+	if spoilerTags == "" {
+		return []string{}, nil
+	}
+	return strings.Split(spoilerTags, ","), nil
+}
+
+// UpdateChannelSpoilerTags sets the spoiler tags for a channel. An empty list disables spoiler handling.
+func UpdateChannelSpoilerTags(b *types.Bot, channelID string, spoilerTags []string) error {
+	query := `UPDATE channels SET spoiler_tags = ?, updated_at = CURRENT_TIMESTAMP 
+			  WHERE id = ?`
+
+	result, err := b.DB.Exec(query, strings.Join(spoilerTags, ","), channelID)
+	if err != nil {
+		return fmt.Errorf("failed to update channel spoiler tags: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("channel %s not found", channelID)
+	}
+
+	return nil
+}
+
+// GetChannelEnvironment retrieves the environment associated with a channel.
+func GetChannelEnvironment(b *types.Bot, channelID string) (string, error) {
+	var environment string
+	query := "SELECT environment FROM channels WHERE id = ?"
+
+	err := b.DB.QueryRow(query, channelID).Scan(&environment)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "PROD", nil // Default to PROD if channel not found
+		}
+		return "", fmt.Errorf("failed to get channel environment: %v", err)
+	}
+
+	return environment, nil
+}
+
+// UpdateChannelEnvironment updates the environment associated with a channel.
+func UpdateChannelEnvironment(b *types.Bot, channelID string, environment string) error {
+	// Validate environment value
+	if environment != "DEV" && environment != "PROD" {
+		return fmt.Errorf("invalid environment value: %s. Must be 'DEV' or 'PROD'", environment)
+	}
+
+	query := `UPDATE channels SET environment = ?, updated_at = CURRENT_TIMESTAMP 
+			  WHERE id = ?`
+
+	result, err := b.DB.Exec(query, environment, channelID)
+	if err != nil {
+		return fmt.Errorf("failed to update channel environment: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("channel %s not found", channelID)
+	}
+
+	return nil
+}
+
+// GetChannelsByEnvironment retrieves all channels for a specific environment.
+func GetChannelsByEnvironment(b *types.Bot, environment string) ([]string, error) {
+	// Validate environment value
+	if environment != "DEV" && environment != "PROD" {
+		return nil, fmt.Errorf("invalid environment value: %s. Must be 'DEV' or 'PROD'", environment)
+	}
+
+	query := "SELECT id FROM channels WHERE environment = ?"
+
+	rows, err := b.DB.Query(query, environment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query channels by environment: %v", err)
+	}
+	defer rows.Close()
+
+	var channels []string
+	for rows.Next() {
+		var channelID string
+		if err := rows.Scan(&channelID); err != nil {
+			return nil, fmt.Errorf("failed to scan channel: %v", err)
+		}
+		channels = append(channels, channelID)
+	}
+
+	return channels, nil
+}
+
+// IsNewsPosted checks if a news item has been posted to a specific channel. A post still being
+// sent counts as posted until it is older than PendingPostTimeout, see ReservePost.
+func IsNewsPosted(b *types.Bot, newsID int64, channelID string) (bool, error) {
+	query := `SELECT 1 FROM posted_news WHERE news_id = ? AND channel_id = ? 
+			  AND (status = ? OR posted_at >= ?)`
+
+	var exists int
+	err := b.DB.QueryRow(query, newsID, channelID, PostStatusSent, pendingCutoff()).Scan(&exists)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check if news is posted: %v", err)
+	}
+
+	return true, nil
+}
+
+// MarkNewsAsPosted marks a news item as posted to a specific channel.
+func MarkNewsAsPosted(b *types.Bot, newsID int64, channelID string) error {
+	return MarkNewsAsPostedWithOptions(b, newsID, channelID, DefaultDatabaseOptions())
+}
+
+// MarkNewsAsPostedWithOptions marks a news item as posted to a specific channel with custom options.
+func MarkNewsAsPostedWithOptions(b *types.Bot, newsID int64, channelID string, options DatabaseOptions) error {
+	return MarkNewsAsPostedContext(context.Background(), b, newsID, channelID, options)
+}
+
+// MarkNewsAsPostedContext marks a news item as posted to a specific channel with custom options.
+// A pending post of the item is marked as sent.
+// Failed writes are retried with backoff; cancelling ctx stops the retries and returns ctx's error.
+func MarkNewsAsPostedContext(ctx context.Context, b *types.Bot, newsID int64, channelID string, options DatabaseOptions) error {
+	query := `INSERT INTO posted_news (news_id, channel_id, posted_by, bot_version) 
+			  VALUES (?, ?, ?, ?)` + confirmPendingPost
+
+	postedBy, botVersion := postedByValues(b)
+
+	var err error
+	for attempt := 0; attempt <= options.RetryCount; attempt++ {
+		if attempt > 0 {
+			logger().WithField("news_id", newsID).Debugf("Retry %d/%d for marking news %d as posted: %v", attempt, options.RetryCount, newsID, err)
+			if waitErr := waitForRetry(ctx, options, attempt); waitErr != nil {
+				return waitErr
+			}
+		}
+
+		_, err = b.DB.ExecContext(ctx, query, newsID, channelID, postedBy, botVersion)
+		if err == nil {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("failed to mark news as posted after %d retries: %v", options.RetryCount, err)
+}
+
+// postedByValues returns the posted_by and bot_version values for rows written by this bot,
+// using NULL when the bot has no identity set.
+func postedByValues(b *types.Bot) (sql.NullString, sql.NullString) {
+	return sql.NullString{String: b.InstanceID, Valid: b.InstanceID != ""},
+		sql.NullString{String: b.Version, Valid: b.Version != ""}
+}
+
+// MarkMultipleNewsAsPosted marks multiple news items as posted to multiple channels with custom options.
+func MarkMultipleNewsAsPosted(b *types.Bot, newsItems []types.NewsItem, channelIDs []string, options DatabaseOptions) error {
+	newsIDs := make([]int64, len(newsItems))
+	for i, newsItem := range newsItems {
+		newsIDs[i] = newsItem.ID
+	}
+	return MarkNewsIDsAsPosted(b, newsIDs, channelIDs, options)
+}
+
+// MarkNewsIDsAsPosted marks multiple news IDs as posted to multiple channels with custom options.
+func MarkNewsIDsAsPosted(b *types.Bot, newsIDs []int64, channelIDs []string, options DatabaseOptions) error {
+	if !options.UseBatch {
+		// Single operations
+		for _, newsID := range newsIDs {
+			for _, channelID := range channelIDs {
+				if err := MarkNewsAsPostedWithOptions(b, newsID, channelID, options); err != nil {
+					if !options.IgnoreErrors {
+						return err
+					}
+					logger().WithField("channel_id", channelID).WithField("news_id", newsID).Debugf("Ignoring error marking news %d as posted to channel %s: %v", newsID, channelID, err)
+				}
+			}
+		}
+		return nil
+	}
+
+	// Batch operation with transaction
+	tx, err := b.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			logger().Printf("Warning: failed to rollback transaction: %v", rollbackErr)
+		}
+	}()
+
+	query := `INSERT INTO posted_news (news_id, channel_id, posted_by, bot_version) VALUES (?, ?, ?, ?)` + confirmPendingPost
+
+	postedBy, botVersion := postedByValues(b)
+	total := len(newsIDs) * len(channelIDs)
+	processed := 0
+
+	for _, newsID := range newsIDs {
+		for _, channelID := range channelIDs {
+			_, err = tx.Exec(query, newsID, channelID, postedBy, botVersion)
+			if err != nil {
+				if !options.IgnoreErrors {
+					return fmt.Errorf("failed to mark news %d as posted to channel %s: %v", newsID, channelID, err)
+				}
+				logger().WithField("channel_id", channelID).WithField("news_id", newsID).Debugf("Ignoring error in batch: news %d to channel %s: %v", newsID, channelID, err)
+			}
+
+			processed++
 			if options.LogProgress && processed%100 == 0 {
-				log.Infof("Marked %d/%d news items as posted", processed, total)
+				logger().Infof("Marked %d/%d news items as posted", processed, total)
 			}
 		}
 	}
 
 	if options.LogProgress && processed > 0 {
-		log.Infof("Completed marking %d news items as posted", processed)
+		logger().Infof("Completed marking %d news items as posted", processed)
 	}
 
 	return tx.Commit()
@@ -604,95 +1150,106 @@ func CacheNews(b *types.Bot, news []types.NewsItem) error {
 
 // CacheNewsWithOptions caches news items in the database with custom options.
 func CacheNewsWithOptions(b *types.Bot, news []types.NewsItem, options DatabaseOptions) error {
+	return CacheNewsContext(context.Background(), b, news, options)
+}
+
+// cacheNewsQuery returns the statement caching a news item, replacing its cached version.
+func cacheNewsQuery(d dialect) string {
+	return d.upsert("news_cache", []string{"id"},
+		[]string{"id", "title", "summary", "content", "tags", "platforms", "updated_at", "thumbnail_url", "fetched_at", "fingerprint", "url", "platform_dates", "author"},
+		`VALUES (?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, ?, ?, ?, ?)`)
+}
+
+// CacheNewsContext caches news items in the database with custom options. Tags are normalized
+// with the configured aliases (see types.NormalizeTag). Failed single writes are retried with
+// backoff; cancelling ctx stops the retries and returns ctx's error.
+func CacheNewsContext(ctx context.Context, b *types.Bot, news []types.NewsItem, options DatabaseOptions) error {
 	if len(news) == 0 {
 		return nil
 	}
 
 	if !options.UseBatch {
 		// Single operations
-		query := `INSERT OR REPLACE INTO news_cache 
-				  (id, title, summary, content, tags, platforms, updated_at, thumbnail_url, fetched_at) 
-				  VALUES (?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)`
+		query := cacheNewsQuery(dialectOf(b.DB))
 		for _, item := range news {
 			platformsStr := strings.Join(item.Platforms, ",")
-			tagsStr := strings.Join(item.Tags, ",")
+			tagsStr := strings.Join(b.Config.NormalizeTags(item.Tags), ",")
 			var err error
 			for attempt := 0; attempt <= options.RetryCount; attempt++ {
-				_, err = b.DB.Exec(query, item.ID, item.Title, item.Summary, item.Content,
-					tagsStr, platformsStr, item.Updated, item.ThumbnailURL)
+				if attempt > 0 {
+					logger().WithField("news_id", item.ID).Debugf("Retry %d/%d for caching news %d: %v", attempt, options.RetryCount, item.ID, err)
+					if waitErr := waitForRetry(ctx, options, attempt); waitErr != nil {
+						return waitErr
+					}
+				}
+				_, err = b.DB.ExecContext(ctx, query, item.ID, item.Title, item.Summary, item.Content,
+					tagsStr, platformsStr, item.Updated, item.ThumbnailURL, NewsFingerprint(item), item.URL, platformDatesJSON(item), item.Author)
 				if err == nil {
 					break
 				}
-				if attempt < options.RetryCount {
-					log.Debugf("Retry %d/%d for caching news %d: %v", attempt+1, options.RetryCount, item.ID, err)
-				}
 			}
 			// Handle final error after all retries
 			if err != nil {
 				if !options.IgnoreErrors {
 					return fmt.Errorf("failed to cache news item %d after %d retries: %v", item.ID, options.RetryCount, err)
 				}
-				log.Debugf("Ignoring error caching news item %d: %v", item.ID, err)
+				logger().WithField("news_id", item.ID).Debugf("Ignoring error caching news item %d: %v", item.ID, err)
 			}
 		}
 		return nil
 	}
 
 	// Batch operation with transaction
-	tx, err := b.DB.Begin()
+	tx, err := b.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %v", err)
 	}
 	defer func() {
 		if rollbackErr := tx.Rollback(); rollbackErr != nil {
-			log.Printf("Warning: failed to rollback transaction: %v", rollbackErr)
+			logger().Printf("Warning: failed to rollback transaction: %v", rollbackErr)
 		}
 	}()
 
-	query := `INSERT OR REPLACE INTO news_cache 
-			  (id, title, summary, content, tags, platforms, updated_at, thumbnail_url, fetched_at) 
-			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)`
+	query := cacheNewsQuery(dialectOf(b.DB))
 
 	for i, item := range news {
 		platformsStr := strings.Join(item.Platforms, ",")
-		tagsStr := strings.Join(item.Tags, ",")
-		_, err = tx.Exec(query, item.ID, item.Title, item.Summary, item.Content,
-			tagsStr, platformsStr, item.Updated, item.ThumbnailURL)
+		tagsStr := strings.Join(b.Config.NormalizeTags(item.Tags), ",")
+		_, err = tx.ExecContext(ctx, query, item.ID, item.Title, item.Summary, item.Content,
+			tagsStr, platformsStr, item.Updated, item.ThumbnailURL, NewsFingerprint(item), item.URL, platformDatesJSON(item), item.Author)
 		if err != nil {
 			if !options.IgnoreErrors {
 				return fmt.Errorf("failed to cache news item %d: %v", item.ID, err)
 			}
-			log.Debugf("Ignoring error in batch caching news item %d: %v", item.ID, err)
+			logger().WithField("news_id", item.ID).Debugf("Ignoring error in batch caching news item %d: %v", item.ID, err)
 		}
 		if options.LogProgress && (i+1)%100 == 0 {
-			log.Infof("Cached %d/%d news items", i+1, len(news))
+			logger().Infof("Cached %d/%d news items", i+1, len(news))
 		}
 	}
 	if options.LogProgress && len(news) > 0 {
-		log.Infof("Completed caching %d news items", len(news))
+		logger().Infof("Completed caching %d news items", len(news))
 	}
 	return tx.Commit()
 }
 
-// CleanOldCache removes cache entries older than 30 days.
+// CleanOldCache removes cache entries older than Config.CacheRetentionDays days that have not
+// been posted to any channel. A retention of 0 keeps all entries.
 func CleanOldCache(b *types.Bot) error {
-	// Remove cache entries older than 30 days
-	query := `DELETE FROM news_cache 
-			  WHERE fetched_at < datetime('now', '-30 days')`
-	result, err := b.DB.Exec(query)
+	result, err := PruneNewsCache(b, PruneOptions{RetentionDays: b.Config.CacheRetentionDays})
 	if err != nil {
 		return fmt.Errorf("failed to clean old cache: %v", err)
 	}
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected > 0 {
-		log.Infof("Cleaned %d old cache entries", rowsAffected)
+	if result.Removed > 0 {
+		logger().Infof("Cleaned %d old cache entries", result.Removed)
 	}
 	return nil
 }
 
 // ImportChannelsFromFile imports channel configuration from a channels.txt file into the database.
+// Each line has the form channel:ID|platforms with an optional |environment (DEV or PROD, default PROD).
 func ImportChannelsFromFile(b *types.Bot, filePath string) error {
-	log.Infof("Importing channels from file: %s", filePath)
+	logger().Infof("Importing channels from file: %s", filePath)
 
 	file, err := os.Open(filePath)
 	if err != nil {
@@ -710,7 +1267,7 @@ func ImportChannelsFromFile(b *types.Bot, filePath string) error {
 	}
 	defer func() {
 		if rollbackErr := tx.Rollback(); rollbackErr != nil {
-			log.Printf("Warning: failed to rollback transaction: %v", rollbackErr)
+			logger().Printf("Warning: failed to rollback transaction: %v", rollbackErr)
 		}
 	}()
 
@@ -720,16 +1277,16 @@ func ImportChannelsFromFile(b *types.Bot, filePath string) error {
 			continue
 		}
 
-		// Parse channel entry: channel:123456789|pc,ps,xbox
+		// Parse channel entry: channel:123456789|pc,ps,xbox[|DEV]
 		if !strings.HasPrefix(line, "channel:") {
-			log.Warnf("Skipping invalid line: %s", line)
+			logger().Warnf("Skipping invalid line: %s", line)
 			skippedCount++
 			continue
 		}
 
 		parts := strings.Split(strings.TrimPrefix(line, "channel:"), "|")
-		if len(parts) != 2 {
-			log.Warnf("Skipping malformed line: %s", line)
+		if len(parts) != 2 && len(parts) != 3 {
+			logger().Warnf("Skipping malformed line: %s", line)
 			skippedCount++
 			continue
 		}
@@ -737,25 +1294,42 @@ func ImportChannelsFromFile(b *types.Bot, filePath string) error {
 		channelID := strings.TrimSpace(parts[0])
 		platformsStr := strings.TrimSpace(parts[1])
 
+		environment := "PROD"
+		if len(parts) == 3 {
+			environment = strings.ToUpper(strings.TrimSpace(parts[2]))
+			if environment != "DEV" && environment != "PROD" {
+				logger().Warnf("Skipping line with invalid environment: %s", line)
+				skippedCount++
+				continue
+			}
+		}
+
 		// Validate channel ID is numeric
 		if _, err := strconv.ParseUint(channelID, 10, 64); err != nil {
-			log.Warnf("Skipping line with invalid channel ID: %s", line)
+			logger().Warnf("Skipping line with invalid channel ID: %s", line)
 			skippedCount++
 			continue
 		}
 
-		// Clean up platforms
-		platforms := strings.Split(platformsStr, ",")
+		// Normalize platforms, dropping unknown ones
 		var validPlatforms []string
-		for _, platform := range platforms {
-			platform = strings.TrimSpace(platform)
-			if platform != "" {
-				validPlatforms = append(validPlatforms, platform)
+		for _, platform := range strings.Split(platformsStr, ",") {
+			if strings.TrimSpace(platform) == "" {
+				continue
+			}
+			normalized, err := types.NormalizePlatforms([]string{platform})
+			if err != nil {
+				logger().WithField("channel_id", channelID).Warnf("Ignoring platform of channel %s: %v", channelID, err)
+				continue
 			}
+			validPlatforms = append(validPlatforms, normalized...)
 		}
 
-		if len(validPlatforms) == 0 {
-			validPlatforms = []string{"pc", "xbox", "ps"} // default platforms
+		// Normalizing again removes duplicates; without valid platforms the channel gets all of them
+		if normalized, err := types.NormalizePlatforms(validPlatforms); err == nil {
+			validPlatforms = normalized
+		} else {
+			validPlatforms = types.Platforms // default platforms
 		}
 
 		platformsStr = strings.Join(validPlatforms, ",")
@@ -764,7 +1338,7 @@ func ImportChannelsFromFile(b *types.Bot, filePath string) error {
 		var existingPlatforms string
 		err := tx.QueryRow("SELECT platforms FROM channels WHERE id = ?", channelID).Scan(&existingPlatforms)
 		if err == nil {
-			log.Infof("Channel %s already exists with platforms %s, skipping", channelID, existingPlatforms)
+			logger().WithField("channel_id", channelID).Infof("Channel %s already exists with platforms %s, skipping", channelID, existingPlatforms)
 			skippedCount++
 			continue
 		} else if err != sql.ErrNoRows {
@@ -773,13 +1347,13 @@ func ImportChannelsFromFile(b *types.Bot, filePath string) error {
 
 		// Insert channel
 		_, err = tx.Exec(`INSERT INTO channels (id, platforms, environment, created_at, updated_at) 
-						  VALUES (?, ?, 'PROD', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
-			channelID, platformsStr)
+						  VALUES (?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+			channelID, platformsStr, environment)
 		if err != nil {
 			return fmt.Errorf("failed to insert channel %s: %v", channelID, err)
 		}
 
-		log.Infof("Imported channel %s with platforms %s", channelID, platformsStr)
+		logger().WithField("channel_id", channelID).Infof("Imported channel %s with platforms %s (%s)", channelID, platformsStr, environment)
 		importedCount++
 	}
 
@@ -792,13 +1366,67 @@ func ImportChannelsFromFile(b *types.Bot, filePath string) error {
 		return fmt.Errorf("failed to commit transaction: %v", err)
 	}
 
-	log.Infof("Import completed: %d channels imported, %d skipped", importedCount, skippedCount)
+	logger().Infof("Import completed: %d channels imported, %d skipped", importedCount, skippedCount)
 	return nil
 }
 
+// ChannelFileLine formats a channel as a channels.txt line (channel:ID|platforms|environment),
+// as read by ImportChannelsFromFile.
+func ChannelFileLine(cfg ChannelConfig) string {
+	return fmt.Sprintf("channel:%s|%s|%s", cfg.ID, strings.Join(cfg.Platforms, ","), cfg.Environment)
+}
+
+// ExportChannels writes the registered channels to w in the channels.txt format read by
+// ImportChannelsFromFile, ordered by channel ID. When environment is set only channels in that
+// environment are written. It returns the number of channels written.
+func ExportChannels(b *types.Bot, w io.Writer, environment string) (int, error) {
+	query := `SELECT id, platforms, environment FROM channels
+			  WHERE ? = '' OR environment = ?
+			  ORDER BY id`
+
+	rows, err := b.DB.Query(query, environment, environment)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query channels: %v", err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var cfg ChannelConfig
+		var platforms string
+		if err := rows.Scan(&cfg.ID, &platforms, &cfg.Environment); err != nil {
+			return count, fmt.Errorf("failed to scan channel: %v", err)
+		}
+		cfg.Platforms = strings.Split(platforms, ",")
+
+		if _, err := fmt.Fprintln(w, ChannelFileLine(cfg)); err != nil {
+			return count, fmt.Errorf("failed to write channel %s: %v", cfg.ID, err)
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, fmt.Errorf("failed to read channels: %v", err)
+	}
+
+	return count, nil
+}
+
+// cachedNewsWarnThreshold is the cache size above which GetAllCachedNews logs a warning,
+// since loading every row into memory gets slow; use GetCachedNewsPage or ForEachCachedNews instead.
+const cachedNewsWarnThreshold = 10000
+
 // GetAllCachedNews retrieves all cached news items from the database.
+// It is intended for small caches and warns when the cache exceeds cachedNewsWarnThreshold rows.
 func GetAllCachedNews(b *types.Bot) ([]types.NewsItem, error) {
-	query := `SELECT id, title, summary, content, tags, platforms, updated_at, thumbnail_url 
+	count, err := CountCachedNews(b)
+	if err != nil {
+		return nil, err
+	}
+	if count > cachedNewsWarnThreshold {
+		logger().Warnf("Loading all %d cached news items into memory; consider GetCachedNewsPage or ForEachCachedNews", count)
+	}
+
+	query := `SELECT id, title, summary, content, tags, platforms, updated_at, thumbnail_url, url, platform_dates, author 
 			  FROM news_cache 
 			  ORDER BY id DESC`
 
@@ -811,7 +1439,117 @@ func GetAllCachedNews(b *types.Bot) ([]types.NewsItem, error) {
 	return parseNewsRows(rows)
 }
 
+// CountCachedNews returns the number of cached news items.
+func CountCachedNews(b *types.Bot) (int, error) {
+	var count int
+	if err := b.DB.QueryRow("SELECT COUNT(*) FROM news_cache").Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count cached news: %v", err)
+	}
+	return count, nil
+}
+
+// CountPostedNews returns the number of posted news markers across all channels.
+func CountPostedNews(b *types.Bot) (int, error) {
+	var count int
+	if err := b.DB.QueryRow("SELECT COUNT(*) FROM posted_news").Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count posted news: %v", err)
+	}
+	return count, nil
+}
+
+// GetCachedNewsPage retrieves up to limit cached news items starting at offset, newest ID first.
+func GetCachedNewsPage(b *types.Bot, offset, limit int) ([]types.NewsItem, error) {
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 {
+		return []types.NewsItem{}, nil
+	}
+
+	query := `SELECT id, title, summary, content, tags, platforms, updated_at, thumbnail_url, url, platform_dates, author 
+			  FROM news_cache 
+			  ORDER BY id DESC
+			  LIMIT ? OFFSET ?`
+
+	rows, err := b.DB.Query(query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cached news page: %v", err)
+	}
+	defer rows.Close()
+
+	return parseNewsRows(rows)
+}
+
+// ForEachCachedNews calls fn with successive batches of at most batchSize cached news items,
+// newest ID first. Each batch is read with one query and closed before fn is called, so fn
+// may write to the database.
+//
+// If fn returns an error, iteration stops immediately and ForEachCachedNews returns that
+// error unchanged.
+func ForEachCachedNews(b *types.Bot, batchSize int, fn func([]types.NewsItem) error) error {
+	if batchSize <= 0 {
+		return fmt.Errorf("invalid batch size: %d", batchSize)
+	}
+
+	query := `SELECT id, title, summary, content, tags, platforms, updated_at, thumbnail_url, url, platform_dates, author 
+			  FROM news_cache 
+			  WHERE ? = 0 OR id < ?
+			  ORDER BY id DESC
+			  LIMIT ?`
+
+	// lastID 0 starts from the newest item; news IDs are always positive
+	var lastID int64
+	for {
+		rows, err := b.DB.Query(query, lastID, lastID, batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to query cached news batch: %v", err)
+		}
+		batch, err := parseNewsRows(rows)
+		rows.Close()
+		if err != nil {
+			return err
+		}
+
+		if len(batch) > 0 {
+			if err := fn(batch); err != nil {
+				return err
+			}
+		}
+
+		if len(batch) < batchSize {
+			return nil
+		}
+		lastID = batch[len(batch)-1].ID
+	}
+}
+
+// GetAllCachedNewsIDs retrieves the IDs of all cached news items, newest first.
+func GetAllCachedNewsIDs(b *types.Bot) ([]int64, error) {
+	rows, err := b.DB.Query("SELECT id FROM news_cache ORDER BY id DESC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cached news IDs: %v", err)
+	}
+	defer rows.Close()
+
+	var newsIDs []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan news ID: %v", err)
+		}
+		newsIDs = append(newsIDs, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %v", err)
+	}
+
+	return newsIDs, nil
+}
+
 // SearchNewsContent searches for news items containing the specified text in title, summary, or content.
+// With the full-text index the text matches whole words (the last one as a prefix) and results are
+// ranked by relevance; otherwise it matches anywhere and results are ordered newest first.
 func SearchNewsContent(b *types.Bot, searchTerm string, limit int) ([]types.NewsItem, error) {
 	if limit <= 0 {
 		limit = 10 // Default limit
@@ -820,12 +1558,31 @@ func SearchNewsContent(b *types.Bot, searchTerm string, limit int) ([]types.News
 		limit = 25 // Maximum limit to prevent overwhelming Discord
 	}
 
-	query := `SELECT id, title, summary, content, tags, platforms, updated_at, thumbnail_url 
+	if phrase := ftsPhrase(searchTerm, true); phrase != "" && newsFTSAvailable(b.DB) {
+		query := `SELECT nc.id, nc.title, nc.summary, nc.content, nc.tags, nc.platforms, nc.updated_at, nc.thumbnail_url, nc.url, nc.platform_dates, nc.author 
+				  FROM news_fts
+				  JOIN news_cache nc ON nc.id = news_fts.rowid
+				  WHERE news_fts MATCH ?
+				  AND nc.content IS NOT NULL AND nc.content != ''
+				  ORDER BY bm25(news_fts, 5.0, 3.0, 1.0)
+				  LIMIT ?`
+
+		rows, err := b.DB.Query(query, phrase, limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search news content: %v", err)
+		}
+		defer rows.Close()
+
+		return parseNewsRows(rows)
+	}
+
+	d := dialectOf(b.DB)
+	query := fmt.Sprintf(`SELECT id, title, summary, content, tags, platforms, updated_at, thumbnail_url, url, platform_dates, author 
 			  FROM news_cache 
-			  WHERE (title LIKE ? OR summary LIKE ? OR content LIKE ?)
+			  WHERE (%s OR %s OR %s)
 			  AND content IS NOT NULL AND content != ''
 			  ORDER BY updated_at DESC
-			  LIMIT ?`
+			  LIMIT ?`, d.match("title"), d.match("summary"), d.match("content"))
 
 	searchPattern := "%" + searchTerm + "%"
 	rows, err := b.DB.Query(query, searchPattern, searchPattern, searchPattern, limit)
@@ -837,7 +1594,8 @@ func SearchNewsContent(b *types.Bot, searchTerm string, limit int) ([]types.News
 	return parseNewsRows(rows)
 }
 
-// SearchNewsByTags searches for news items that contain any of the specified tags.
+// SearchNewsByTags searches for news items that contain any of the specified tags, normalized as
+// cached tags are.
 func SearchNewsByTags(b *types.Bot, tags []string, limit int) ([]types.NewsItem, error) {
 	if limit <= 0 {
 		limit = 10
@@ -846,19 +1604,21 @@ func SearchNewsByTags(b *types.Bot, tags []string, limit int) ([]types.NewsItem,
 		limit = 25
 	}
 
+	tags = b.Config.NormalizeTags(tags)
 	if len(tags) == 0 {
 		return []types.NewsItem{}, nil
 	}
 
 	// Build WHERE clause for tags
+	d := dialectOf(b.DB)
 	var conditions []string
 	var args []interface{}
 	for _, tag := range tags {
-		conditions = append(conditions, "tags LIKE ?")
+		conditions = append(conditions, d.match("tags"))
 		args = append(args, "%"+tag+"%")
 	}
 
-	query := fmt.Sprintf(`SELECT id, title, summary, content, tags, platforms, updated_at, thumbnail_url 
+	query := fmt.Sprintf(`SELECT id, title, summary, content, tags, platforms, updated_at, thumbnail_url, url, platform_dates, author 
 			  FROM news_cache 
 			  WHERE (%s)
 			  ORDER BY updated_at DESC
@@ -881,14 +1641,14 @@ func GetRandomNews(b *types.Bot, platform string) (*types.NewsItem, error) {
 	var args []interface{}
 
 	if platform != "" {
-		query = `SELECT id, title, summary, content, tags, platforms, updated_at, thumbnail_url 
+		query = fmt.Sprintf(`SELECT id, title, summary, content, tags, platforms, updated_at, thumbnail_url, url, platform_dates, author 
 				 FROM news_cache 
-				 WHERE platforms LIKE ?
+				 WHERE %s
 				 ORDER BY RANDOM() 
-				 LIMIT 1`
+				 LIMIT 1`, dialectOf(b.DB).match("platforms"))
 		args = append(args, "%"+platform+"%")
 	} else {
-		query = `SELECT id, title, summary, content, tags, platforms, updated_at, thumbnail_url 
+		query = `SELECT id, title, summary, content, tags, platforms, updated_at, thumbnail_url, url, platform_dates, author 
 				 FROM news_cache 
 				 ORDER BY RANDOM() 
 				 LIMIT 1`
@@ -912,6 +1672,30 @@ func GetRandomNews(b *types.Bot, platform string) (*types.NewsItem, error) {
 	return &newsItems[0], nil
 }
 
+// GetCachedNewsByID returns a cached news item, or nil if it is not cached.
+func GetCachedNewsByID(b *types.Bot, id int64) (*types.NewsItem, error) {
+	query := `SELECT id, title, summary, content, tags, platforms, updated_at, thumbnail_url, url, platform_dates, author 
+			  FROM news_cache 
+			  WHERE id = ?`
+
+	rows, err := b.DB.Query(query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached news %d: %v", id, err)
+	}
+	defer rows.Close()
+
+	newsItems, err := parseNewsRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(newsItems) == 0 {
+		return nil, nil
+	}
+
+	return &newsItems[0], nil
+}
+
 // GetRecentNews returns recent news items.
 func GetRecentNews(b *types.Bot, limit int) ([]types.NewsItem, error) {
 	if limit <= 0 {
@@ -921,7 +1705,7 @@ func GetRecentNews(b *types.Bot, limit int) ([]types.NewsItem, error) {
 		limit = 50
 	}
 
-	query := `SELECT id, title, summary, content, tags, platforms, updated_at, thumbnail_url 
+	query := `SELECT id, title, summary, content, tags, platforms, updated_at, thumbnail_url, url, platform_dates, author 
 			  FROM news_cache 
 			  ORDER BY updated_at DESC
 			  LIMIT ?`
@@ -935,127 +1719,103 @@ func GetRecentNews(b *types.Bot, limit int) ([]types.NewsItem, error) {
 	return parseNewsRows(rows)
 }
 
-// GetDatabaseStats returns statistics about the news database.
-func GetDatabaseStats(b *types.Bot) (map[string]interface{}, error) {
-	stats := make(map[string]interface{})
-
-	// Total news count
-	var totalNews int
-	err := b.DB.QueryRow("SELECT COUNT(*) FROM news_cache").Scan(&totalNews)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get total news count: %v", err)
-	}
-	stats["total_news"] = totalNews
+// GetRecentNewsWithTag returns up to limit cached news items carrying tag, newest first. Unlike
+// the LIKE filters of the search functions, only the exact tag matches, so "patch-notes" does
+// not match "patch-notes-xbox".
+func GetRecentNewsWithTag(b *types.Bot, tag string, limit int) ([]types.NewsItem, error) {
+	query := fmt.Sprintf(`SELECT id, title, summary, content, tags, platforms, updated_at, thumbnail_url, url, platform_dates, author
+			  FROM news_cache
+			  WHERE %s
+			  ORDER BY updated_at DESC
+			  LIMIT ?`, dialectOf(b.DB).match("',' || tags || ','"))
 
-	// Total channels
-	var totalChannels int
-	err = b.DB.QueryRow("SELECT COUNT(*) FROM channels").Scan(&totalChannels)
+	rows, err := b.DB.Query(query, "%,"+tag+",%", limit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get total channels: %v", err)
+		return nil, fmt.Errorf("failed to get recent news with tag %s: %v", tag, err)
 	}
-	stats["total_channels"] = totalChannels
+	defer rows.Close()
 
-	// Total posted items
-	var totalPosted int
-	err = b.DB.QueryRow("SELECT COUNT(*) FROM posted_news").Scan(&totalPosted)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get total posted count: %v", err)
-	}
-	stats["total_posted"] = totalPosted
+	return parseNewsRows(rows)
+}
 
-	// Oldest and newest articles
-	var oldest, newest sql.NullString
-	err = b.DB.QueryRow("SELECT MIN(updated_at), MAX(updated_at) FROM news_cache").Scan(&oldest, &newest)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get date range: %v", err)
-	}
+// GetNewsSince returns cached news items updated on or after since, newest first,
+// optionally filtered by tag and platform.
+func GetNewsSince(b *types.Bot, since time.Time, tag, platform string, limit int) ([]types.NewsItem, error) {
+	return getNewsInRange(b, since, time.Time{}, tag, platform, limit)
+}
 
-	// Handle NULL values for empty database
-	if oldest.Valid && newest.Valid {
-		stats["oldest_article"] = oldest.String
-		stats["newest_article"] = newest.String
-	} else {
-		stats["oldest_article"] = ""
-		stats["newest_article"] = ""
+// GetNewsBetween returns cached news items updated on or after start and before end,
+// newest first, optionally filtered by tag and platform.
+func GetNewsBetween(b *types.Bot, start, end time.Time, tag, platform string, limit int) ([]types.NewsItem, error) {
+	if !end.After(start) {
+		return nil, fmt.Errorf("end %s is not after start %s", end.Format("2006-01-02"), start.Format("2006-01-02"))
 	}
-
-	return stats, nil
+	return getNewsInRange(b, start, end, tag, platform, limit)
 }
 
-// GetPopularTags returns the most frequently used tags.
-func GetPopularTags(b *types.Bot, limit int) ([]map[string]interface{}, error) {
+// getNewsInRange queries cached news updated in [start, end); a zero end leaves the range open.
+func getNewsInRange(b *types.Bot, start, end time.Time, tag, platform string, limit int) ([]types.NewsItem, error) {
 	if limit <= 0 {
 		limit = 10
 	}
-	if limit > 20 {
-		limit = 20
+	if limit > 25 {
+		limit = 25
 	}
 
-	// Get all tags and count them
-	rows, err := b.DB.Query("SELECT tags FROM news_cache WHERE tags IS NOT NULL AND tags != ''")
-	if err != nil {
-		return nil, fmt.Errorf("failed to query tags: %v", err)
+	d := dialectOf(b.DB)
+	conditions := []string{"updated_at >= ?"}
+	args := []interface{}{start.UTC().Format("2006-01-02 15:04:05")}
+	if !end.IsZero() {
+		conditions = append(conditions, "updated_at < ?")
+		args = append(args, end.UTC().Format("2006-01-02 15:04:05"))
 	}
-	defer rows.Close()
-
-	tagCounts := make(map[string]int)
-	for rows.Next() {
-		var tagsStr string
-		if err := rows.Scan(&tagsStr); err != nil {
-			continue
-		}
-
-		tags := strings.Split(tagsStr, ",")
-		for _, tag := range tags {
-			tag = strings.TrimSpace(tag)
-			if tag != "" {
-				tagCounts[tag]++
-			}
-		}
+	if tag != "" {
+		conditions = append(conditions, d.match("tags"))
+		args = append(args, "%"+tag+"%")
 	}
-
-	// Convert to sorted slice
-	type tagStat struct {
-		Tag   string
-		Count int
+	if platform != "" {
+		conditions = append(conditions, d.match("platforms"))
+		args = append(args, "%"+platform+"%")
 	}
 
-	var tagStats []tagStat
-	for tag, count := range tagCounts {
-		tagStats = append(tagStats, tagStat{Tag: tag, Count: count})
-	}
+	query := fmt.Sprintf(`SELECT id, title, summary, content, tags, platforms, updated_at, thumbnail_url, url, platform_dates, author 
+			  FROM news_cache 
+			  WHERE %s
+			  ORDER BY updated_at DESC
+			  LIMIT ?`, strings.Join(conditions, " AND "))
+	args = append(args, limit)
 
-	// Sort by count (descending)
-	for i := 0; i < len(tagStats)-1; i++ {
-		for j := i + 1; j < len(tagStats); j++ {
-			if tagStats[j].Count > tagStats[i].Count {
-				tagStats[i], tagStats[j] = tagStats[j], tagStats[i]
-			}
-		}
+	rows, err := b.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get news by date: %v", err)
 	}
+	defer rows.Close()
 
-	// Limit results
-	if len(tagStats) > limit {
-		tagStats = tagStats[:limit]
-	}
+	return parseNewsRows(rows)
+}
 
-	// Convert to return format
-	var result []map[string]interface{}
-	for _, stat := range tagStats {
-		result = append(result, map[string]interface{}{
-			"tag":   stat.Tag,
-			"count": stat.Count,
-		})
-	}
+// TagCount is the number of cached news items with a tag.
+type TagCount struct {
+	Tag   string
+	Count int
+}
 
-	return result, nil
+// GetPopularTags returns the most frequently used tags.
+func GetPopularTags(b *types.Bot, limit int) ([]TagCount, error) {
+	return getTagCounts(b, time.Time{}, limit)
 }
 
 // GetTrendingTags returns tags that have appeared frequently in recent news.
-func GetTrendingTags(b *types.Bot, days int, limit int) ([]map[string]interface{}, error) {
+func GetTrendingTags(b *types.Bot, days int, limit int) ([]TagCount, error) {
 	if days <= 0 {
 		days = 7 // Default to last week
 	}
+	return getTagCounts(b, time.Now().AddDate(0, 0, -days), limit)
+}
+
+// getTagCounts returns the most used tags of cached news, of news updated since cutoff unless it
+// is zero. limit defaults to 10 and is at most 20.
+func getTagCounts(b *types.Bot, cutoff time.Time, limit int) ([]TagCount, error) {
 	if limit <= 0 {
 		limit = 10
 	}
@@ -1063,67 +1823,59 @@ func GetTrendingTags(b *types.Bot, days int, limit int) ([]map[string]interface{
 		limit = 20
 	}
 
-	cutoffDate := time.Now().AddDate(0, 0, -days)
+	query := "SELECT tags FROM news_cache WHERE tags IS NOT NULL AND tags != ''"
+	var args []interface{}
+	if !cutoff.IsZero() {
+		query += " AND updated_at >= ?"
+		args = append(args, cutoff.Format("2006-01-02 15:04:05"))
+	}
 
-	rows, err := b.DB.Query(`SELECT tags FROM news_cache 
-							 WHERE tags IS NOT NULL AND tags != '' 
-							 AND updated_at >= ?`, cutoffDate.Format("2006-01-02 15:04:05"))
+	rows, err := b.DB.Query(query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query trending tags: %v", err)
+		return nil, fmt.Errorf("failed to query tags: %v", err)
 	}
 	defer rows.Close()
 
-	tagCounts := make(map[string]int)
-	for rows.Next() {
-		var tagsStr string
-		if err := rows.Scan(&tagsStr); err != nil {
-			continue
-		}
-
-		tags := strings.Split(tagsStr, ",")
-		for _, tag := range tags {
-			tag = strings.TrimSpace(tag)
-			if tag != "" {
-				tagCounts[tag]++
-			}
-		}
-	}
-
-	// Convert and sort similar to GetPopularTags
-	type tagStat struct {
-		Tag   string
-		Count int
+	tagCounts, err := aggregateTagCounts(rows)
+	if err != nil {
+		return nil, err
 	}
-
-	var tagStats []tagStat
-	for tag, count := range tagCounts {
-		tagStats = append(tagStats, tagStat{Tag: tag, Count: count})
+	if len(tagCounts) > limit {
+		tagCounts = tagCounts[:limit]
 	}
+	return tagCounts, nil
+}
 
-	// Sort by count (descending)
-	for i := 0; i < len(tagStats)-1; i++ {
-		for j := i + 1; j < len(tagStats); j++ {
-			if tagStats[j].Count > tagStats[i].Count {
-				tagStats[i], tagStats[j] = tagStats[j], tagStats[i]
-			}
+// aggregateTagCounts counts the tags of rows of comma-separated tag lists, most used first and
+// tags used equally often in alphabetical order.
+func aggregateTagCounts(rows *sql.Rows) ([]TagCount, error) {
+	counts := make(map[string]int)
+	for rows.Next() {
+		var tags string
+		if err := rows.Scan(&tags); err != nil {
+			return nil, fmt.Errorf("failed to scan tags: %v", err)
 		}
+		countListItems(counts, tags)
 	}
-
-	// Limit results
-	if len(tagStats) > limit {
-		tagStats = tagStats[:limit]
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read tags: %v", err)
 	}
+	return sortTagCounts(counts), nil
+}
 
-	// Convert to return format
-	var result []map[string]interface{}
-	for _, stat := range tagStats {
-		result = append(result, map[string]interface{}{
-			"tag":   stat.Tag,
-			"count": stat.Count,
-		})
+// sortTagCounts returns tag counts by count, descending, then by tag.
+func sortTagCounts(counts map[string]int) []TagCount {
+	tagCounts := make([]TagCount, 0, len(counts))
+	for tag, count := range counts {
+		tagCounts = append(tagCounts, TagCount{Tag: tag, Count: count})
 	}
-
-	return result, nil
+	sort.Slice(tagCounts, func(i, j int) bool {
+		if tagCounts[i].Count != tagCounts[j].Count {
+			return tagCounts[i].Count > tagCounts[j].Count
+		}
+		return tagCounts[i].Tag < tagCounts[j].Tag
+	})
+	return tagCounts
 }
 
 // GetChannelEngagement returns engagement statistics for channels.
@@ -1149,19 +1901,110 @@ func GetChannelEngagement(b *types.Bot, channelID string) (map[string]interface{
 	}
 	stats["weekly_posts"] = weeklyPosts
 
-	// First and last post dates
-	var firstPost, lastPost string
+	// Posts in last 30 days
+	monthAgo := time.Now().AddDate(0, 0, -30)
+	var monthlyPosts int
+	err = b.DB.QueryRow(`SELECT COUNT(*) FROM posted_news 
+						 WHERE channel_id = ? AND posted_at >= ?`,
+		channelID, monthAgo.Format("2006-01-02 15:04:05")).Scan(&monthlyPosts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get monthly post count: %v", err)
+	}
+	stats["posts_last_30_days"] = monthlyPosts
+
+	// First and last post dates; NULL for channels that have never had a post
+	var firstPost, lastPost sql.NullString
 	err = b.DB.QueryRow(`SELECT MIN(posted_at), MAX(posted_at) FROM posted_news 
 						 WHERE channel_id = ?`, channelID).Scan(&firstPost, &lastPost)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get post date range: %v", err)
 	}
-	stats["first_post"] = firstPost
-	stats["last_post"] = lastPost
+	stats["first_post"] = firstPost.String
+	stats["last_post"] = lastPost.String
+
+	// Posts per bot instance; rows from before instance tracking are grouped as "unknown"
+	rows, err := b.DB.Query(`SELECT COALESCE(posted_by, 'unknown'), COUNT(*) FROM posted_news 
+							 WHERE channel_id = ? GROUP BY COALESCE(posted_by, 'unknown')`, channelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get posts by instance: %v", err)
+	}
+	defer rows.Close()
+
+	postedBy := make(map[string]int)
+	for rows.Next() {
+		var instance string
+		var count int
+		if err := rows.Scan(&instance, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan posts by instance: %v", err)
+		}
+		postedBy[instance] = count
+	}
+	stats["posted_by"] = postedBy
+
+	// Delivery latency of live posts over the last 7 days
+	latency, err := GetDeliveryLatency(b, channelID, weekAgo)
+	if err != nil {
+		return nil, err
+	}
+	stats["latency"] = latency
 
 	return stats, nil
 }
 
+// RecentPost is a news item posted to a channel.
+type RecentPost struct {
+	NewsID   int64     // NewsID is the posted news item.
+	Title    string    // Title is the news title, empty if it is no longer cached.
+	PostedAt time.Time // PostedAt is when the news was posted to the channel.
+}
+
+// GetRecentPostsForChannel returns the news most recently posted to a channel, newest first.
+func GetRecentPostsForChannel(b *types.Bot, channelID string, limit int) ([]RecentPost, error) {
+	if limit <= 0 {
+		limit = 5
+	}
+
+	query := `SELECT pn.news_id, COALESCE(nc.title, ''), pn.posted_at FROM posted_news pn
+			  LEFT JOIN news_cache nc ON nc.id = pn.news_id
+			  WHERE pn.channel_id = ?
+			  ORDER BY pn.posted_at DESC, pn.id DESC
+			  LIMIT ?`
+
+	rows, err := b.DB.Query(query, channelID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recent posts: %v", err)
+	}
+	defer rows.Close()
+
+	var posts []RecentPost
+	for rows.Next() {
+		var post RecentPost
+		if err := rows.Scan(&post.NewsID, &post.Title, &post.PostedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan recent post: %v", err)
+		}
+		posts = append(posts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read recent posts: %v", err)
+	}
+
+	return posts, nil
+}
+
+// popularNewsQuery selects the most posted news items since a time, most posted first. The
+// week's posts are read first, from the covering idx_posted_news_posted_at, and counted by news
+// before joining the cache; materializing them keeps the planner from scanning every post in
+// news_id order instead, which it prefers on databases without statistics.
+const popularNewsQuery = `WITH recent AS MATERIALIZED (
+			  SELECT news_id FROM posted_news WHERE posted_at >= ?
+		  )
+		  SELECT nc.id, nc.title, nc.summary, nc.content, nc.tags, nc.platforms, nc.updated_at, nc.thumbnail_url, nc.url, nc.platform_dates, nc.author,
+				 pn.post_count
+		  FROM (SELECT news_id, COUNT(*) AS post_count FROM recent GROUP BY news_id) pn
+		  JOIN news_cache nc ON nc.id = pn.news_id
+		  ORDER BY pn.post_count DESC, nc.updated_at DESC
+		  LIMIT ?`
+
 // GetPopularNewsThisWeek returns the most posted news items from the last week.
 func GetPopularNewsThisWeek(b *types.Bot, limit int) ([]types.NewsItem, error) {
 	if limit <= 0 {
@@ -1171,18 +2014,8 @@ func GetPopularNewsThisWeek(b *types.Bot, limit int) ([]types.NewsItem, error) {
 		limit = 20
 	}
 
-	weekAgo := time.Now().AddDate(0, 0, -7)
-
-	query := `SELECT nc.id, nc.title, nc.summary, nc.content, nc.tags, nc.platforms, nc.updated_at, nc.thumbnail_url,
-					 COUNT(pn.news_id) as post_count
-			  FROM news_cache nc
-			  JOIN posted_news pn ON nc.id = pn.news_id
-			  WHERE pn.posted_at >= ?
-			  GROUP BY nc.id
-			  ORDER BY post_count DESC, nc.updated_at DESC
-			  LIMIT ?`
-
-	rows, err := b.DB.Query(query, weekAgo.Format("2006-01-02 15:04:05"), limit)
+	weekAgo := now().UTC().AddDate(0, 0, -7)
+	rows, err := b.DB.Query(popularNewsQuery, weekAgo.Format("2006-01-02 15:04:05"), limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get popular news: %v", err)
 	}
@@ -1190,33 +2023,16 @@ func GetPopularNewsThisWeek(b *types.Bot, limit int) ([]types.NewsItem, error) {
 
 	var newsItems []types.NewsItem
 	for rows.Next() {
-		var item types.NewsItem
-		var tagsStr, platformsStr string
-		var thumbnailURL *string
-		var content *string
 		var postCount int
-
-		err := rows.Scan(&item.ID, &item.Title, &item.Summary, &content, &tagsStr, &platformsStr, &item.Updated, &thumbnailURL, &postCount)
+		item, err := scanNewsItem(rows, &postCount)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan popular news item: %v", err)
 		}
-
-		// Parse tags and platforms
-		if tagsStr != "" {
-			item.Tags = strings.Split(tagsStr, ",")
-		}
-		if platformsStr != "" {
-			item.Platforms = strings.Split(platformsStr, ",")
-		}
-		if thumbnailURL != nil {
-			item.ThumbnailURL = *thumbnailURL
-		}
-		if content != nil {
-			item.Content = *content
-		}
-
 		newsItems = append(newsItems, item)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %v", err)
+	}
 
 	return newsItems, nil
 }
@@ -1225,44 +2041,85 @@ func GetPopularNewsThisWeek(b *types.Bot, limit int) ([]types.NewsItem, error) {
 func parseNewsRows(rows *sql.Rows) ([]types.NewsItem, error) {
 	var newsItems []types.NewsItem
 	for rows.Next() {
-		var item types.NewsItem
-		var tagsStr, platformsStr string
-		var thumbnailURL *string
-		var content *string
-
-		err := rows.Scan(&item.ID, &item.Title, &item.Summary, &content, &tagsStr, &platformsStr, &item.Updated, &thumbnailURL)
+		item, err := scanNewsItem(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan news item: %v", err)
+			return nil, err
 		}
+		newsItems = append(newsItems, item)
+	}
 
-		// Parse tags
-		if tagsStr != "" {
-			item.Tags = strings.Split(tagsStr, ",")
-		}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %v", err)
+	}
 
-		// Parse platforms
-		if platformsStr != "" {
-			item.Platforms = strings.Split(platformsStr, ",")
-		}
+	return newsItems, nil
+}
 
-		// Handle thumbnail URL
-		if thumbnailURL != nil {
-			item.ThumbnailURL = *thumbnailURL
-		}
+// scanNewsItem scans a row of news_cache columns (id, title, summary, content, tags, platforms,
+// updated_at, thumbnail_url, url, platform_dates, author) into a NewsItem. Any further columns are scanned
+// into extra.
+func scanNewsItem(rows *sql.Rows, extra ...interface{}) (types.NewsItem, error) {
+	var item types.NewsItem
+	var tagsStr, platformsStr string
+	var thumbnailURL, articleURL, platformDates, author *string
+	var content *string
 
-		// Handle content
-		if content != nil {
-			item.Content = *content
-		}
+	dest := append([]interface{}{&item.ID, &item.Title, &item.Summary, &content, &tagsStr, &platformsStr, &item.Updated, &thumbnailURL, &articleURL, &platformDates, &author}, extra...)
+	if err := rows.Scan(dest...); err != nil {
+		return item, fmt.Errorf("failed to scan news item: %v", err)
+	}
 
-		newsItems = append(newsItems, item)
+	// Parse tags
+	if tagsStr != "" {
+		item.Tags = strings.Split(tagsStr, ",")
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error reading rows: %v", err)
+	// Parse platforms
+	if platformsStr != "" {
+		item.Platforms = strings.Split(platformsStr, ",")
 	}
 
-	return newsItems, nil
+	// Handle thumbnail URL
+	if thumbnailURL != nil {
+		item.ThumbnailURL = *thumbnailURL
+	}
+
+	// Handle article URL, missing for news cached before it was stored
+	if articleURL != nil {
+		item.URL = *articleURL
+	}
+
+	// Handle content
+	if content != nil {
+		item.Content = *content
+	}
+
+	// Handle per-platform release dates, missing for news the API gave none for
+	if platformDates != nil && *platformDates != "" {
+		if err := json.Unmarshal([]byte(*platformDates), &item.PlatformDates); err != nil {
+			logger().WithField("news_id", item.ID).Warnf("Ignoring unreadable platform dates of news %d: %v", item.ID, err)
+		}
+	}
+
+	// Handle the author, missing for news cached before it was stored
+	if author != nil {
+		item.Author = *author
+	}
+
+	return item, nil
+}
+
+// platformDatesJSON returns item's per-platform release dates as stored in news_cache, or nil
+// when it has none.
+func platformDatesJSON(item types.NewsItem) interface{} {
+	if len(item.PlatformDates) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(item.PlatformDates)
+	if err != nil {
+		return nil
+	}
+	return string(data)
 }
 
 // Convenience functions for testing that wrap the Bot-based functions
@@ -1281,12 +2138,12 @@ func StoreNews(db *sql.DB, news []types.NewsItem, options DatabaseOptions) error
 
 // GetFreshNews retrieves fresh news items (convenience wrapper)
 func GetFreshNews(db *sql.DB, freshSeconds int) ([]types.NewsItem, error) {
-	query := `SELECT id, title, summary, content, tags, platforms, updated_at, thumbnail_url
+	query := `SELECT id, title, summary, content, tags, platforms, updated_at, thumbnail_url, url, platform_dates, author
 			  FROM news_cache 
-			  WHERE updated_at > datetime('now', '-' || ? || ' seconds')
+			  WHERE updated_at > ?
 			  ORDER BY updated_at DESC`
 
-	rows, err := db.Query(query, freshSeconds)
+	rows, err := db.Query(query, time.Now().Add(-time.Duration(freshSeconds)*time.Second))
 	if err != nil {
 		return nil, fmt.Errorf("failed to query fresh news: %v", err)
 	}
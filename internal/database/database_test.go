@@ -4,9 +4,15 @@
 package database
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
 	"testing"
 	"time"
 
@@ -435,6 +441,126 @@ func TestDatabaseMigration(t *testing.T) {
 	if count != 1 {
 		t.Error("Database migration did not preserve data")
 	}
+
+	// Verify instance tracking columns were added and are NULL for old rows
+	var postedBy, botVersion sql.NullString
+	err = db.QueryRow("SELECT posted_by, bot_version FROM posted_news WHERE news_id = 1").Scan(&postedBy, &botVersion)
+	if err != nil {
+		t.Fatalf("Failed to read instance tracking columns: %v", err)
+	}
+	if postedBy.Valid || botVersion.Valid {
+		t.Errorf("Expected NULL posted_by/bot_version for migrated rows, got %v/%v", postedBy, botVersion)
+	}
+}
+
+func TestPostedByTracking(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "test.db")
+	db, err := InitDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	channelID := "123456789"
+	devBot := &types.Bot{DB: db, InstanceID: "DEV@laptop#0", Version: "v1.2.3"}
+	legacyBot := &types.Bot{DB: db}
+	if err := AddChannel(devBot, channelID); err != nil {
+		t.Fatalf("Failed to add channel: %v", err)
+	}
+
+	if err := MarkNewsAsPosted(devBot, 1, channelID); err != nil {
+		t.Fatalf("Failed to mark news as posted: %v", err)
+	}
+	newsItems := []types.NewsItem{{ID: 2}, {ID: 3}}
+	if err := MarkMultipleNewsAsPosted(devBot, newsItems, []string{channelID}, BulkDatabaseOptions()); err != nil {
+		t.Fatalf("Failed to mark multiple news as posted: %v", err)
+	}
+	if err := MarkNewsAsPosted(legacyBot, 4, channelID); err != nil {
+		t.Fatalf("Failed to mark news as posted: %v", err)
+	}
+
+	var postedBy, botVersion sql.NullString
+	err = db.QueryRow("SELECT posted_by, bot_version FROM posted_news WHERE news_id = 2").Scan(&postedBy, &botVersion)
+	if err != nil {
+		t.Fatalf("Failed to read posted_news row: %v", err)
+	}
+	if postedBy.String != "DEV@laptop#0" || botVersion.String != "v1.2.3" {
+		t.Errorf("Expected DEV@laptop#0/v1.2.3, got %v/%v", postedBy, botVersion)
+	}
+
+	err = db.QueryRow("SELECT posted_by, bot_version FROM posted_news WHERE news_id = 4").Scan(&postedBy, &botVersion)
+	if err != nil {
+		t.Fatalf("Failed to read posted_news row: %v", err)
+	}
+	if postedBy.Valid || botVersion.Valid {
+		t.Errorf("Expected NULL posted_by/bot_version for bot without identity, got %v/%v", postedBy, botVersion)
+	}
+
+	stats, err := GetChannelEngagement(devBot, channelID)
+	if err != nil {
+		t.Fatalf("Failed to get channel engagement: %v", err)
+	}
+	breakdown, ok := stats["posted_by"].(map[string]int)
+	if !ok {
+		t.Fatalf("Expected posted_by breakdown, got %T", stats["posted_by"])
+	}
+	if breakdown["DEV@laptop#0"] != 3 {
+		t.Errorf("Expected 3 posts by DEV@laptop#0, got %d", breakdown["DEV@laptop#0"])
+	}
+	if breakdown["unknown"] != 1 {
+		t.Errorf("Expected 1 post by unknown instance, got %d", breakdown["unknown"])
+	}
+}
+
+func TestChannelEngagement(t *testing.T) {
+	db, err := InitDatabase(testDatabasePath(t, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+	bot := &types.Bot{DB: db}
+
+	for _, channelID := range []string{"channel-quiet", "channel-busy"} {
+		if err := AddChannel(bot, channelID); err != nil {
+			t.Fatalf("Failed to add channel: %v", err)
+		}
+	}
+	for newsID, daysAgo := range map[int64]int{1: 0, 2: 10, 3: 40} {
+		postedAt := time.Now().AddDate(0, 0, -daysAgo).Format("2006-01-02 15:04:05")
+		if _, err := db.Exec(`INSERT INTO posted_news (news_id, channel_id, posted_at) VALUES (?, 'channel-busy', ?)`,
+			newsID, postedAt); err != nil {
+			t.Fatalf("Failed to insert post: %v", err)
+		}
+	}
+
+	tests := []struct {
+		channelID    string
+		totalPosts   int
+		weeklyPosts  int
+		monthlyPosts int
+		hasPosts     bool
+	}{
+		{"channel-quiet", 0, 0, 0, false},
+		{"channel-busy", 3, 1, 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.channelID, func(t *testing.T) {
+			stats, err := GetChannelEngagement(bot, tt.channelID)
+			if err != nil {
+				t.Fatalf("Failed to get channel engagement: %v", err)
+			}
+			if stats["total_posts"] != tt.totalPosts || stats["weekly_posts"] != tt.weeklyPosts || stats["posts_last_30_days"] != tt.monthlyPosts {
+				t.Errorf("Expected %d total, %d weekly and %d monthly posts, got %v/%v/%v", tt.totalPosts, tt.weeklyPosts, tt.monthlyPosts,
+					stats["total_posts"], stats["weekly_posts"], stats["posts_last_30_days"])
+			}
+			firstPost, _ := stats["first_post"].(string)
+			lastPost, _ := stats["last_post"].(string)
+			if (firstPost != "" && lastPost != "") != tt.hasPosts {
+				t.Errorf("Expected first/last post dates only for channels with posts, got %q/%q", firstPost, lastPost)
+			}
+		})
+	}
 }
 
 func TestBatchDatabaseOptions(t *testing.T) {
@@ -454,6 +580,174 @@ func TestBatchDatabaseOptions(t *testing.T) {
 	}
 }
 
+func TestMarkNewsAsPostedConcurrently(t *testing.T) {
+	db, err := InitDatabase(testDatabasePath(t, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+	bot := &types.Bot{DB: db}
+	for _, channelID := range []string{"channel-a", "bulk", "bulk-2"} {
+		if err := AddChannel(bot, channelID); err != nil {
+			t.Fatalf("Failed to add channel: %v", err)
+		}
+	}
+
+	const workers, perWorker = 8, 50
+	bulkIDs := make([]int64, 500)
+	for i := range bulkIDs {
+		bulkIDs[i] = int64(100000 + i)
+	}
+
+	// A bulk transaction holds the write lock while the workers mark news one by one
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*perWorker+1)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := MarkNewsIDsAsPosted(bot, bulkIDs, []string{"bulk", "bulk-2"}, BulkDatabaseOptions()); err != nil {
+			errs <- err
+		}
+	}()
+	for worker := 0; worker < workers; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				if err := MarkNewsAsPosted(bot, int64(worker*perWorker+i), "channel-a"); err != nil {
+					errs <- err
+				}
+			}
+		}(worker)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("Concurrent write failed: %v", err)
+	}
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM posted_news WHERE channel_id = 'channel-a'").Scan(&count); err != nil {
+		t.Fatalf("Failed to count posted news: %v", err)
+	}
+	if count != workers*perWorker {
+		t.Errorf("Expected %d posted news, got %d", workers*perWorker, count)
+	}
+}
+
+func TestMarkNewsAsPostedContextCancelled(t *testing.T) {
+	db, err := InitDatabase(testDatabasePath(t, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+	bot := &types.Bot{DB: db}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	options := DefaultDatabaseOptions()
+	options.RetryDelay = time.Hour
+
+	start := time.Now()
+	err = MarkNewsAsPostedContext(ctx, bot, 1, "channel-a", options)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancellation error, got %v", err)
+	}
+	if err := CacheNewsContext(ctx, bot, []types.NewsItem{{ID: 1, Title: "Season Update"}}, options); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancellation error from caching, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected cancellation to stop the retries, took %v", elapsed)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	opts := DefaultDatabaseOptions()
+	expected := []time.Duration{50 * time.Millisecond, 100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 500 * time.Millisecond}
+	for retry, delay := range expected {
+		if got := opts.RetryBackoff(retry + 1); got != delay {
+			t.Errorf("Retry %d: expected %v, got %v", retry+1, delay, got)
+		}
+	}
+}
+
+func TestDatabaseDSN(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		options  InitOptions
+		expected string
+	}{
+		{"defaults", "./data/stobot.db", DefaultInitOptions(), "./data/stobot.db?_journal_mode=WAL&_busy_timeout=5000&_foreign_keys=on"},
+		{"existing parameters", "file:stobot.db?cache=shared", InitOptions{BusyTimeout: time.Second}, "file:stobot.db?cache=shared&_busy_timeout=1000"},
+		{"no pragmas", "./data/stobot.db", InitOptions{}, "./data/stobot.db"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := databaseDSN(tt.path, tt.options); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestInitDatabasePragmas(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+
+	// An old database in the default rollback journal mode, with a posted marker for a channel
+	// that is not registered
+	old, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	if _, err := old.Exec(`
+		CREATE TABLE channels (id TEXT PRIMARY KEY, name TEXT NOT NULL);
+		CREATE TABLE posted_news (news_id INTEGER PRIMARY KEY, channel_id TEXT NOT NULL);
+		INSERT INTO posted_news (news_id, channel_id) VALUES (1, 'channel1');
+	`); err != nil {
+		t.Fatalf("Failed to create old schema: %v", err)
+	}
+	old.Close()
+
+	db, err := InitDatabaseWithOptions(dbPath, InitOptions{JournalMode: "WAL", BusyTimeout: 5 * time.Second, ForeignKeys: true, MaxOpenConns: 1})
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	pragmas := []struct {
+		pragma   string
+		expected string
+	}{
+		{"journal_mode", "wal"},
+		{"busy_timeout", "5000"},
+		{"foreign_keys", "1"},
+	}
+	for _, p := range pragmas {
+		var value string
+		if err := db.QueryRow("PRAGMA " + p.pragma).Scan(&value); err != nil {
+			t.Fatalf("Failed to read %s: %v", p.pragma, err)
+		}
+		if value != p.expected {
+			t.Errorf("Expected %s to be %s, got %s", p.pragma, p.expected, value)
+		}
+	}
+	if stats := db.Stats(); stats.MaxOpenConnections != 1 {
+		t.Errorf("Expected 1 max open connection, got %d", stats.MaxOpenConnections)
+	}
+
+	// The old data is migrated in place, but new markers need a registered channel
+	bot := &types.Bot{DB: db}
+	if posted, err := IsNewsPosted(bot, 1, "channel1"); err != nil || !posted {
+		t.Errorf("Expected the old posted marker to survive the conversion, got %v (%v)", posted, err)
+	}
+	options := DefaultDatabaseOptions()
+	options.RetryCount = 0
+	if err := MarkNewsAsPostedWithOptions(bot, 2, "channel1", options); err == nil {
+		t.Error("Expected a foreign key error for an unregistered channel")
+	}
+}
+
 func TestSearchNewsContent(t *testing.T) {
 	// Setup test database
 	tempDir := t.TempDir()
@@ -679,3 +973,632 @@ func TestChannelEnvironmentOperations(t *testing.T) {
 		t.Error("Expected error for invalid environment in AddChannelWithEnvironment, got nil")
 	}
 }
+
+func TestChannelSpoilerTags(t *testing.T) {
+	// Setup test database
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "test.db")
+	db, err := InitDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	bot := &types.Bot{DB: db}
+	channelID := "123456789"
+	if err := AddChannel(bot, channelID); err != nil {
+		t.Fatalf("Failed to add channel: %v", err)
+	}
+
+	// New channels have no spoiler tags
+	tags, err := GetChannelSpoilerTags(bot, channelID)
+	if err != nil {
+		t.Fatalf("Failed to get spoiler tags: %v", err)
+	}
+	if len(tags) != 0 {
+		t.Errorf("Expected no spoiler tags, got %v", tags)
+	}
+
+	if err := UpdateChannelSpoilerTags(bot, channelID, []string{"story", "featured"}); err != nil {
+		t.Fatalf("Failed to update spoiler tags: %v", err)
+	}
+	tags, err = GetChannelSpoilerTags(bot, channelID)
+	if err != nil {
+		t.Fatalf("Failed to get spoiler tags: %v", err)
+	}
+	if len(tags) != 2 || tags[0] != "story" || tags[1] != "featured" {
+		t.Errorf("Expected [story featured], got %v", tags)
+	}
+
+	// Clearing disables spoiler handling
+	if err := UpdateChannelSpoilerTags(bot, channelID, nil); err != nil {
+		t.Fatalf("Failed to clear spoiler tags: %v", err)
+	}
+	tags, err = GetChannelSpoilerTags(bot, channelID)
+	if err != nil {
+		t.Fatalf("Failed to get spoiler tags: %v", err)
+	}
+	if len(tags) != 0 {
+		t.Errorf("Expected no spoiler tags after clearing, got %v", tags)
+	}
+
+	// Unregistered channels cannot be updated
+	if err := UpdateChannelSpoilerTags(bot, "unknown", []string{"story"}); err == nil {
+		t.Error("Expected error updating spoiler tags for unregistered channel")
+	}
+}
+
+// seedCachedNews stores news items with IDs 1..count.
+func seedCachedNews(t *testing.T, db *sql.DB, count int) {
+	t.Helper()
+
+	newsItems := make([]types.NewsItem, count)
+	for i := range newsItems {
+		newsItems[i] = types.NewsItem{
+			ID:      int64(i + 1),
+			Title:   "News",
+			Summary: "Summary",
+			Updated: time.Now(),
+		}
+	}
+	if err := StoreNews(db, newsItems, BulkDatabaseOptions()); err != nil {
+		t.Fatalf("Failed to store news: %v", err)
+	}
+}
+
+func TestGetCachedNewsByID(t *testing.T) {
+	db, err := InitDatabase(testDatabasePath(t, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	bot := &types.Bot{DB: db}
+	seedCachedNews(t, db, 3)
+
+	newsItem, err := GetCachedNewsByID(bot, 2)
+	if err != nil {
+		t.Fatalf("Failed to get cached news: %v", err)
+	}
+	if newsItem == nil || newsItem.ID != 2 || newsItem.Title != "News" {
+		t.Errorf("Expected news 2, got %+v", newsItem)
+	}
+
+	if newsItem, err = GetCachedNewsByID(bot, 42); err != nil || newsItem != nil {
+		t.Errorf("Expected no news for an unknown ID, got %+v (%v)", newsItem, err)
+	}
+}
+
+func TestCachedNewsPlatformDates(t *testing.T) {
+	db, err := InitDatabase(testDatabasePath(t, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+	bot := &types.Bot{DB: db}
+
+	updated := time.Date(2024, 6, 4, 16, 0, 0, 0, time.UTC)
+	consoles := time.Date(2024, 6, 11, 16, 0, 0, 0, time.UTC)
+	dates := map[string]time.Time{"pc": updated, "xbox": consoles}
+	if err := CacheNewsWithOptions(bot, []types.NewsItem{
+		{ID: 1, Title: "Season 35", Updated: updated, PlatformDates: dates},
+	}, DefaultDatabaseOptions()); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+	if err := CacheNewsWithOptions(bot, []types.NewsItem{
+		{ID: 2, Title: "Dev Blog", Updated: updated, PlatformDates: dates},
+		{ID: 3, Title: "PC Maintenance", Updated: updated},
+	}, BulkDatabaseOptions()); err != nil {
+		t.Fatalf("Failed to cache news in a batch: %v", err)
+	}
+
+	for _, newsID := range []int64{1, 2} {
+		newsItem, err := GetCachedNewsByID(bot, newsID)
+		if err != nil || newsItem == nil {
+			t.Fatalf("Failed to get cached news %d: %v", newsID, err)
+		}
+		if len(newsItem.PlatformDates) != 2 || !newsItem.PlatformDates["xbox"].Equal(consoles) {
+			t.Errorf("Expected platform dates %v for news %d, got %v", dates, newsID, newsItem.PlatformDates)
+		}
+	}
+	newsItem, err := GetCachedNewsByID(bot, 3)
+	if err != nil || newsItem == nil {
+		t.Fatalf("Failed to get cached news 3: %v", err)
+	}
+	if newsItem.PlatformDates != nil {
+		t.Errorf("Expected no platform dates, got %v", newsItem.PlatformDates)
+	}
+	if released := newsItem.ReleasedFor([]string{"xbox"}); !released.Equal(updated) {
+		t.Errorf("Expected news without platform dates to be released at %v, got %v", updated, released)
+	}
+}
+
+func TestCachedNewsURL(t *testing.T) {
+	db, err := InitDatabase(testDatabasePath(t, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+	bot := &types.Bot{DB: db}
+
+	canonical := "https://playstartrekonline.com/en/news/article/1-season-35"
+	if err := CacheNewsWithOptions(bot, []types.NewsItem{
+		{ID: 1, Title: "Season 35", URL: canonical, Updated: time.Now()},
+	}, DefaultDatabaseOptions()); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+	if err := CacheNewsWithOptions(bot, []types.NewsItem{
+		{ID: 2, Title: "Dev Blog", URL: canonical + "-dev-blog", Updated: time.Now()},
+	}, BulkDatabaseOptions()); err != nil {
+		t.Fatalf("Failed to cache news in a batch: %v", err)
+	}
+	// News cached before URLs were stored
+	if _, err := db.Exec(`INSERT INTO news_cache (id, title, summary, tags, platforms, updated_at) VALUES (3, 'Old News', '', '', '', CURRENT_TIMESTAMP)`); err != nil {
+		t.Fatalf("Failed to insert news: %v", err)
+	}
+
+	tests := []struct {
+		newsID   int64
+		url      string
+		expected string
+	}{
+		{1, canonical, canonical},
+		{2, canonical + "-dev-blog", canonical + "-dev-blog"},
+		{3, "", "https://playstartrekonline.com/en/news/article/3"},
+	}
+	for _, tt := range tests {
+		newsItem, err := GetCachedNewsByID(bot, tt.newsID)
+		if err != nil {
+			t.Fatalf("Failed to get cached news: %v", err)
+		}
+		if newsItem == nil {
+			t.Fatalf("Expected news %d to be cached", tt.newsID)
+		}
+		if newsItem.URL != tt.url || newsItem.Link() != tt.expected {
+			t.Errorf("Expected news %d to have URL %q and link %q, got %q and %q", tt.newsID, tt.url, tt.expected, newsItem.URL, newsItem.Link())
+		}
+	}
+}
+
+func TestGetCachedNewsPage(t *testing.T) {
+	tempDir := t.TempDir()
+	db, err := InitDatabase(filepath.Join(tempDir, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	bot := &types.Bot{DB: db}
+	seedCachedNews(t, db, 25)
+
+	tests := []struct {
+		name          string
+		offset        int
+		limit         int
+		expectedFirst int64
+		expectedLen   int
+	}{
+		{"first page", 0, 10, 25, 10},
+		{"second page", 10, 10, 15, 10},
+		{"last partial page", 20, 10, 5, 5},
+		{"exact end", 25, 10, 0, 0},
+		{"beyond end", 100, 10, 0, 0},
+		{"negative offset", -5, 3, 25, 3},
+		{"zero limit", 0, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := GetCachedNewsPage(bot, tt.offset, tt.limit)
+			if err != nil {
+				t.Fatalf("Failed to get page: %v", err)
+			}
+			if len(page) != tt.expectedLen {
+				t.Fatalf("Expected %d items, got %d", tt.expectedLen, len(page))
+			}
+			if tt.expectedLen > 0 && page[0].ID != tt.expectedFirst {
+				t.Errorf("Expected first ID %d, got %d", tt.expectedFirst, page[0].ID)
+			}
+		})
+	}
+}
+
+func TestForEachCachedNews(t *testing.T) {
+	tempDir := t.TempDir()
+	db, err := InitDatabase(filepath.Join(tempDir, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	bot := &types.Bot{DB: db}
+	seedCachedNews(t, db, 25)
+
+	seen := make(map[int64]bool)
+	var batchSizes []int
+	err = ForEachCachedNews(bot, 10, func(batch []types.NewsItem) error {
+		batchSizes = append(batchSizes, len(batch))
+		for _, item := range batch {
+			if seen[item.ID] {
+				t.Errorf("News %d visited twice", item.ID)
+			}
+			seen[item.ID] = true
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEachCachedNews failed: %v", err)
+	}
+	if len(seen) != 25 {
+		t.Errorf("Expected 25 news items, visited %d", len(seen))
+	}
+	if len(batchSizes) != 3 || batchSizes[0] != 10 || batchSizes[2] != 5 {
+		t.Errorf("Expected batches [10 10 5], got %v", batchSizes)
+	}
+
+	// An exact multiple of the batch size ends without an empty batch
+	batches := 0
+	err = ForEachCachedNews(bot, 5, func(batch []types.NewsItem) error {
+		batches++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEachCachedNews failed: %v", err)
+	}
+	if batches != 5 {
+		t.Errorf("Expected 5 batches, got %d", batches)
+	}
+
+	// A callback error stops iteration and is returned unchanged
+	stopErr := errors.New("stop")
+	batches = 0
+	err = ForEachCachedNews(bot, 10, func(batch []types.NewsItem) error {
+		batches++
+		return stopErr
+	})
+	if !errors.Is(err, stopErr) {
+		t.Errorf("Expected callback error, got %v", err)
+	}
+	if batches != 1 {
+		t.Errorf("Expected iteration to stop after 1 batch, got %d", batches)
+	}
+}
+
+func TestAutoMarkUsesCachedNewsIDs(t *testing.T) {
+	tempDir := t.TempDir()
+	db, err := InitDatabase(filepath.Join(tempDir, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	bot := &types.Bot{DB: db}
+	for _, channelID := range []string{"by-item", "by-id"} {
+		if err := AddChannel(bot, channelID); err != nil {
+			t.Fatalf("Failed to add channel: %v", err)
+		}
+	}
+	seedCachedNews(t, db, 30)
+
+	ids, err := GetAllCachedNewsIDs(bot)
+	if err != nil {
+		t.Fatalf("Failed to get cached news IDs: %v", err)
+	}
+	if len(ids) != 30 || ids[0] != 30 || ids[29] != 1 {
+		t.Fatalf("Expected IDs 30..1, got %v", ids)
+	}
+
+	// Marking by ID must produce the same rows as marking full items
+	allNews, err := GetAllCachedNews(bot)
+	if err != nil {
+		t.Fatalf("Failed to get cached news: %v", err)
+	}
+	if err := MarkMultipleNewsAsPosted(bot, allNews, []string{"by-item"}, BulkDatabaseOptions()); err != nil {
+		t.Fatalf("Failed to mark by item: %v", err)
+	}
+	if err := MarkNewsIDsAsPosted(bot, ids, []string{"by-id"}, BulkDatabaseOptions()); err != nil {
+		t.Fatalf("Failed to mark by ID: %v", err)
+	}
+
+	// AddChannel auto-marks via the ID-only flow
+	if err := AddChannel(bot, "auto"); err != nil {
+		t.Fatalf("Failed to add channel: %v", err)
+	}
+
+	postedIDs := func(channelID string) []int64 {
+		rows, err := db.Query("SELECT news_id FROM posted_news WHERE channel_id = ? ORDER BY news_id", channelID)
+		if err != nil {
+			t.Fatalf("Failed to query posted news: %v", err)
+		}
+		defer rows.Close()
+		var result []int64
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				t.Fatalf("Failed to scan posted news: %v", err)
+			}
+			result = append(result, id)
+		}
+		return result
+	}
+
+	byItem := postedIDs("by-item")
+	for _, channelID := range []string{"by-id", "auto"} {
+		got := postedIDs(channelID)
+		if len(got) != len(byItem) {
+			t.Fatalf("Channel %s: expected %d posted rows, got %d", channelID, len(byItem), len(got))
+		}
+		for i := range got {
+			if got[i] != byItem[i] {
+				t.Errorf("Channel %s: row %d differs: %d vs %d", channelID, i, got[i], byItem[i])
+			}
+		}
+	}
+}
+
+func TestExportChannelsRoundTrip(t *testing.T) {
+	tempDir := t.TempDir()
+	source, err := InitDatabase(filepath.Join(tempDir, "source.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer source.Close()
+	sourceBot := &types.Bot{DB: source}
+
+	channels := []struct {
+		id          string
+		platforms   []string
+		environment string
+	}{
+		{"111111111", []string{"pc", "xbox", "ps"}, "PROD"},
+		{"222222222", []string{"pc"}, "DEV"},
+		{"333333333", []string{"xbox", "ps"}, "PROD"},
+	}
+	for _, c := range channels {
+		if err := AddChannelWithEnvironment(sourceBot, c.id, c.environment); err != nil {
+			t.Fatalf("Failed to add channel: %v", err)
+		}
+		if err := UpdateChannelPlatforms(sourceBot, c.id, c.platforms); err != nil {
+			t.Fatalf("Failed to update platforms: %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	count, err := ExportChannels(sourceBot, &buf, "")
+	if err != nil {
+		t.Fatalf("Failed to export channels: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 exported channels, got %d", count)
+	}
+	expected := "channel:111111111|pc,xbox,ps|PROD\nchannel:222222222|pc|DEV\nchannel:333333333|xbox,ps|PROD\n"
+	if buf.String() != expected {
+		t.Errorf("Expected export:\n%s\ngot:\n%s", expected, buf.String())
+	}
+
+	channelsFile := filepath.Join(tempDir, "channels.txt")
+	if err := os.WriteFile(channelsFile, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write channels file: %v", err)
+	}
+
+	target, err := InitDatabase(filepath.Join(tempDir, "target.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer target.Close()
+	targetBot := &types.Bot{DB: target}
+
+	if err := ImportChannelsFromFile(targetBot, channelsFile); err != nil {
+		t.Fatalf("Failed to import channels: %v", err)
+	}
+
+	sourceIDs, err := GetRegisteredChannels(sourceBot)
+	if err != nil {
+		t.Fatalf("Failed to get channels: %v", err)
+	}
+	targetIDs, err := GetRegisteredChannels(targetBot)
+	if err != nil {
+		t.Fatalf("Failed to get channels: %v", err)
+	}
+	sort.Strings(sourceIDs)
+	sort.Strings(targetIDs)
+	if !reflect.DeepEqual(sourceIDs, targetIDs) {
+		t.Fatalf("Expected channels %v after round trip, got %v", sourceIDs, targetIDs)
+	}
+
+	for _, channelID := range sourceIDs {
+		sourcePlatforms, err := GetChannelPlatforms(sourceBot, channelID)
+		if err != nil {
+			t.Fatalf("Failed to get platforms: %v", err)
+		}
+		targetPlatforms, err := GetChannelPlatforms(targetBot, channelID)
+		if err != nil {
+			t.Fatalf("Failed to get platforms: %v", err)
+		}
+		if !reflect.DeepEqual(sourcePlatforms, targetPlatforms) {
+			t.Errorf("Expected platforms %v for %s, got %v", sourcePlatforms, channelID, targetPlatforms)
+		}
+
+		sourceEnvironment, err := GetChannelEnvironment(sourceBot, channelID)
+		if err != nil {
+			t.Fatalf("Failed to get environment: %v", err)
+		}
+		targetEnvironment, err := GetChannelEnvironment(targetBot, channelID)
+		if err != nil {
+			t.Fatalf("Failed to get environment: %v", err)
+		}
+		if sourceEnvironment != targetEnvironment {
+			t.Errorf("Expected environment %s for %s, got %s", sourceEnvironment, channelID, targetEnvironment)
+		}
+	}
+}
+
+func TestExportChannelsEnvironmentFilter(t *testing.T) {
+	db, err := InitDatabase(testDatabasePath(t, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+	bot := &types.Bot{DB: db}
+
+	if err := AddChannelWithEnvironment(bot, "111111111", "PROD"); err != nil {
+		t.Fatalf("Failed to add channel: %v", err)
+	}
+	if err := AddChannelWithEnvironment(bot, "222222222", "DEV"); err != nil {
+		t.Fatalf("Failed to add channel: %v", err)
+	}
+
+	var buf bytes.Buffer
+	count, err := ExportChannels(bot, &buf, "DEV")
+	if err != nil {
+		t.Fatalf("Failed to export channels: %v", err)
+	}
+	if count != 1 || buf.String() != "channel:222222222|pc,xbox,ps|DEV\n" {
+		t.Errorf("Expected only the DEV channel, got %d channels:\n%s", count, buf.String())
+	}
+}
+
+func TestChannelPlatformValidation(t *testing.T) {
+	tempDir := t.TempDir()
+	db, err := InitDatabase(filepath.Join(tempDir, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+	bot := &types.Bot{DB: db}
+
+	// Unknown platforms are dropped on import instead of failing the file
+	channelsFile := filepath.Join(tempDir, "channels.txt")
+	content := "channel:111111111|PC,PlayStation\nchannel:222222222|xobx,ps4,PS5\nchannel:333333333|switch\nchannel:444444444|all\n"
+	if err := os.WriteFile(channelsFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create channels file: %v", err)
+	}
+	if err := ImportChannelsFromFile(bot, channelsFile); err != nil {
+		t.Fatalf("Failed to import channels: %v", err)
+	}
+
+	expected := map[string][]string{
+		"111111111": {"pc", "ps"},
+		"222222222": {"ps"},
+		"333333333": {"pc", "xbox", "ps"},
+		"444444444": {"pc", "xbox", "ps"},
+	}
+	for channelID, want := range expected {
+		platforms, err := GetChannelPlatforms(bot, channelID)
+		if err != nil {
+			t.Fatalf("Failed to get platforms: %v", err)
+		}
+		if !reflect.DeepEqual(platforms, want) {
+			t.Errorf("Expected platforms %v for %s, got %v", want, channelID, platforms)
+		}
+	}
+
+	if err := UpdateChannelPlatforms(bot, "111111111", []string{"Xbox", "playstation"}); err != nil {
+		t.Fatalf("Failed to update platforms: %v", err)
+	}
+	if err := UpdateChannelPlatforms(bot, "111111111", []string{"pc", "xobx"}); err == nil {
+		t.Error("Expected an error for an unknown platform")
+	}
+	platforms, err := GetChannelPlatforms(bot, "111111111")
+	if err != nil {
+		t.Fatalf("Failed to get platforms: %v", err)
+	}
+	if !reflect.DeepEqual(platforms, []string{"xbox", "ps"}) {
+		t.Errorf("Expected the rejected update to keep [xbox ps], got %v", platforms)
+	}
+}
+
+func TestImportChannelsFromFileEnvironment(t *testing.T) {
+	tempDir := t.TempDir()
+	db, err := InitDatabase(filepath.Join(tempDir, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+	bot := &types.Bot{DB: db}
+
+	channelsFile := filepath.Join(tempDir, "channels.txt")
+	content := "channel:111111111|pc\nchannel:222222222|pc|dev\nchannel:333333333|pc|STAGING\n"
+	if err := os.WriteFile(channelsFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create channels file: %v", err)
+	}
+
+	if err := ImportChannelsFromFile(bot, channelsFile); err != nil {
+		t.Fatalf("Failed to import channels: %v", err)
+	}
+
+	for channelID, expected := range map[string]string{"111111111": "PROD", "222222222": "DEV"} {
+		environment, err := GetChannelEnvironment(bot, channelID)
+		if err != nil {
+			t.Fatalf("Failed to get environment: %v", err)
+		}
+		if environment != expected {
+			t.Errorf("Expected environment %s for %s, got %s", expected, channelID, environment)
+		}
+	}
+
+	platforms, err := GetChannelPlatforms(bot, "333333333")
+	if err != nil {
+		t.Fatalf("Failed to get platforms: %v", err)
+	}
+	if len(platforms) != 0 {
+		t.Error("Expected the line with an invalid environment to be skipped")
+	}
+}
+
+func TestGetNewsByDate(t *testing.T) {
+	db, err := InitDatabase(testDatabasePath(t, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	bot := &types.Bot{DB: db}
+	day := func(d int) time.Time { return time.Date(2024, 6, d, 12, 0, 0, 0, time.UTC) }
+	newsItems := []types.NewsItem{
+		{ID: 1, Title: "May", Tags: []string{"events"}, Platforms: []string{"pc"}, Updated: time.Date(2024, 5, 31, 23, 0, 0, 0, time.UTC)},
+		{ID: 2, Title: "June 1", Tags: []string{"patch-notes"}, Platforms: []string{"pc"}, Updated: day(1)},
+		{ID: 3, Title: "June 3", Tags: []string{"events"}, Platforms: []string{"xbox"}, Updated: day(3)},
+		{ID: 4, Title: "June 5", Tags: []string{"patch-notes"}, Platforms: []string{"ps"}, Updated: day(5)},
+	}
+	if err := StoreNews(db, newsItems, BulkDatabaseOptions()); err != nil {
+		t.Fatalf("Failed to store news: %v", err)
+	}
+
+	june1 := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	june4 := time.Date(2024, 6, 4, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		fetch    func() ([]types.NewsItem, error)
+		expected []int64
+	}{
+		{"since", func() ([]types.NewsItem, error) { return GetNewsSince(bot, june1, "", "", 10) }, []int64{4, 3, 2}},
+		{"since with tag", func() ([]types.NewsItem, error) { return GetNewsSince(bot, june1, "patch-notes", "", 10) }, []int64{4, 2}},
+		{"since with platform", func() ([]types.NewsItem, error) { return GetNewsSince(bot, june1, "", "xbox", 10) }, []int64{3}},
+		{"since with limit", func() ([]types.NewsItem, error) { return GetNewsSince(bot, june1, "", "", 1) }, []int64{4}},
+		{"between", func() ([]types.NewsItem, error) { return GetNewsBetween(bot, june1, june4, "", "", 10) }, []int64{3, 2}},
+		{"between with tag", func() ([]types.NewsItem, error) { return GetNewsBetween(bot, june1, june4, "events", "", 10) }, []int64{3}},
+		{"nothing in range", func() ([]types.NewsItem, error) { return GetNewsSince(bot, day(6), "", "", 10) }, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.fetch()
+			if err != nil {
+				t.Fatalf("Failed to get news: %v", err)
+			}
+			var ids []int64
+			for _, item := range result {
+				ids = append(ids, item.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("Expected news %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+
+	if _, err := GetNewsBetween(bot, june4, june1, "", "", 10); err == nil {
+		t.Error("Expected an error when end is before start")
+	}
+}
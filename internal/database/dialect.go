@@ -0,0 +1,155 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// A dialect is what the SQL of a database backend differs in. Queries in this package are
+// written for SQLite, the default backend, with the SQL that differs in meaning taken from the
+// dialect of the database: upserts, date formatting and case-insensitive matches, as well as the
+// catalog queries and features of the backend. The PostgreSQL driver only rewrites the
+// placeholders and column types of queries (see rewritePostgres).
+type dialect struct {
+	name string
+
+	// columnCountQuery counts the columns of the table given as its first argument with the name
+	// given as its second.
+	columnCountQuery string
+
+	// legacySchemas is set if databases may predate the current schema, e.g. with posted_news
+	// keyed by news ID, and need rebuilding.
+	legacySchemas bool
+
+	// fullTextSearch is set if the backend may have the news_fts index; searches use LIKE
+	// otherwise.
+	fullTextSearch bool
+
+	readSchemaVersion  string // readSchemaVersion returns the schema version of the database.
+	writeSchemaVersion string // writeSchemaVersion records the schema version given by its %d verb.
+
+	// conflictClauses is set if inserts skipping or replacing conflicting rows are written with
+	// ON CONFLICT clauses rather than INSERT OR IGNORE and INSERT OR REPLACE.
+	conflictClauses bool
+
+	like       string // like is the operator matching text against a LIKE pattern, ignoring case.
+	dateFormat string // dateFormat formats the time given by its %s verb as YYYY-MM-DD text.
+}
+
+// sqliteDialect is the dialect of SQLite databases, which keep the schema version in
+// PRAGMA user_version.
+var sqliteDialect = dialect{
+	name:               "sqlite",
+	columnCountQuery:   `SELECT COUNT(*) FROM pragma_table_info(?) WHERE name=?`,
+	legacySchemas:      true,
+	fullTextSearch:     true,
+	readSchemaVersion:  `PRAGMA user_version`,
+	writeSchemaVersion: `PRAGMA user_version = %d`,
+	like:               "LIKE",
+	dateFormat:         "date(%s)",
+}
+
+// postgresDialect is the dialect of PostgreSQL databases, which keep the schema version in the
+// schema_version table.
+var postgresDialect = dialect{
+	name: "postgres",
+	columnCountQuery: `SELECT COUNT(*) FROM information_schema.columns
+			  WHERE table_schema = current_schema() AND table_name = ? AND column_name = ?`,
+	readSchemaVersion: `SELECT COALESCE(MAX(version), 0) FROM schema_version`,
+	writeSchemaVersion: `CREATE TABLE IF NOT EXISTS schema_version (version INTEGER NOT NULL);
+			  DELETE FROM schema_version;
+			  INSERT INTO schema_version (version) VALUES (%d)`,
+	conflictClauses: true,
+	like:            "ILIKE",
+	dateFormat:      "to_char(%s, 'YYYY-MM-DD')",
+}
+
+// dialectOf returns the dialect of an open database.
+func dialectOf(db *sql.DB) dialect {
+	if _, ok := db.Driver().(postgresDriver); ok {
+		return postgresDialect
+	}
+	return sqliteDialect
+}
+
+// insertIgnore returns a statement inserting rows, given by a VALUES or SELECT clause, into the
+// columns of table and skipping those conflicting with existing rows.
+func (d dialect) insertIgnore(table string, columns []string, rows string) string {
+	if !d.conflictClauses {
+		return fmt.Sprintf("INSERT OR IGNORE INTO %s (%s) %s", table, strings.Join(columns, ", "), rows)
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) %s ON CONFLICT DO NOTHING", table, strings.Join(columns, ", "), rows)
+}
+
+// upsert returns a statement inserting a row, given by a VALUES clause, into the columns of table
+// and replacing the row with the same keys. SQLite replaces the whole row, so its columns not
+// given are reset to their defaults; PostgreSQL updates the columns given.
+func (d dialect) upsert(table string, keys, columns []string, values string) string {
+	if !d.conflictClauses {
+		return fmt.Sprintf("INSERT OR REPLACE INTO %s (%s) %s", table, strings.Join(columns, ", "), values)
+	}
+
+	var updates []string
+	for _, column := range columns {
+		if !slices.Contains(keys, column) {
+			updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", column, column))
+		}
+	}
+	conflict := "DO NOTHING"
+	if len(updates) > 0 {
+		conflict = "DO UPDATE SET " + strings.Join(updates, ", ")
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) %s ON CONFLICT (%s) %s",
+		table, strings.Join(columns, ", "), values, strings.Join(keys, ", "), conflict)
+}
+
+// match returns a condition matching expr against a LIKE pattern given as a placeholder,
+// ignoring case.
+func (d dialect) match(expr string) string {
+	return expr + " " + d.like + " ?"
+}
+
+// date returns an expression formatting the time expr as YYYY-MM-DD text.
+func (d dialect) date(expr string) string {
+	return fmt.Sprintf(d.dateFormat, expr)
+}
+
+// IsPostgresURL reports whether a database path is a PostgreSQL connection URL rather than the
+// path of a SQLite database.
+func IsPostgresURL(dbPath string) bool {
+	return strings.HasPrefix(dbPath, "postgres://") || strings.HasPrefix(dbPath, "postgresql://")
+}
+
+// DisplayPath returns a database path for logs: the path of a SQLite database, or a PostgreSQL
+// URL with its password masked.
+func DisplayPath(dbPath string) string {
+	if !IsPostgresURL(dbPath) {
+		return dbPath
+	}
+	u, err := url.Parse(dbPath)
+	if err != nil {
+		return "postgres://(invalid URL)"
+	}
+	return u.Redacted()
+}
+
+// columnExists reports whether a table has a column.
+func columnExists(db *sql.DB, table, column string) (bool, error) {
+	var count int
+	if err := db.QueryRow(dialectOf(db).columnCountQuery, table, column).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check for %s column: %v", column, err)
+	}
+	return count > 0, nil
+}
+
+// GetSchemaVersion returns the schema version recorded by the last successful migration.
+func GetSchemaVersion(db *sql.DB) (int, error) {
+	var version int
+	if err := db.QueryRow(dialectOf(db).readSchemaVersion).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %v", err)
+	}
+	return version, nil
+}
@@ -0,0 +1,81 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// UpdateChannelDigest puts a channel in weekly digest mode, posting on day at hour (UTC), or
+// takes it out of digest mode when enabled is false. Enabling digest mode also records the
+// current time as the last digest, so the first digest is posted at the next scheduled time.
+func UpdateChannelDigest(b *types.Bot, channelID string, enabled bool, day time.Weekday, hour int) error {
+	if enabled && (day < time.Sunday || day > time.Saturday || hour < 0 || hour > 23) {
+		return fmt.Errorf("invalid digest schedule: day %d, hour %d", day, hour)
+	}
+
+	digestDay := sql.NullInt64{Int64: int64(day), Valid: enabled}
+	if !enabled {
+		hour = 0
+	}
+	query := `UPDATE channels SET digest_day = ?, digest_hour = ?, last_digest_at = ?, updated_at = CURRENT_TIMESTAMP 
+			  WHERE id = ?`
+
+	result, err := b.DB.Exec(query, digestDay, hour, now().UTC().Format("2006-01-02 15:04:05"), channelID)
+	if err != nil {
+		return fmt.Errorf("failed to update channel digest: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("channel %s not found", channelID)
+	}
+
+	return nil
+}
+
+// GetChannelLastDigest returns when a channel's digest was last posted, or the zero time if never.
+func GetChannelLastDigest(b *types.Bot, channelID string) (time.Time, error) {
+	var lastDigest sql.NullTime
+	err := b.DB.QueryRow("SELECT last_digest_at FROM channels WHERE id = ?", channelID).Scan(&lastDigest)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get last digest: %v", err)
+	}
+
+	return lastDigest.Time, nil
+}
+
+// SetChannelLastDigest records when a channel's digest was posted.
+func SetChannelLastDigest(b *types.Bot, channelID string, at time.Time) error {
+	query := "UPDATE channels SET last_digest_at = ? WHERE id = ?"
+	if _, err := b.DB.Exec(query, at.UTC().Format("2006-01-02 15:04:05"), channelID); err != nil {
+		return fmt.Errorf("failed to set last digest: %v", err)
+	}
+
+	return nil
+}
+
+// GetDigestNews returns the cached news posted or collected for a digest since a time, newest
+// first. An empty channelID returns news posted to any channel, each item once.
+func GetDigestNews(b *types.Bot, channelID string, since time.Time) ([]types.NewsItem, error) {
+	query := `SELECT nc.id, nc.title, nc.summary, nc.content, nc.tags, nc.platforms, nc.updated_at, nc.thumbnail_url, nc.url, nc.platform_dates, nc.author
+			  FROM news_cache nc
+			  JOIN (SELECT news_id, MAX(posted_at) AS posted_at FROM posted_news
+					WHERE posted_at >= ? AND (? = '' OR channel_id = ?)
+					GROUP BY news_id) pn ON pn.news_id = nc.id
+			  ORDER BY pn.posted_at DESC, nc.id DESC`
+
+	rows, err := b.DB.Query(query, since.UTC().Format("2006-01-02 15:04:05"), channelID, channelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query digest news: %v", err)
+	}
+	defer rows.Close()
+
+	return parseNewsRows(rows)
+}
@@ -0,0 +1,40 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// IsNewsDryRunPosted reports whether a dry run posted a news item to a channel, or recorded it
+// as posted without posting it. Dry runs keep their posts apart from posted_news, so they never
+// keep the real bot from posting.
+func IsNewsDryRunPosted(b *types.Bot, newsID int64, channelID string) (bool, error) {
+	var exists int
+	err := b.DB.QueryRow(`SELECT 1 FROM dry_run_posted WHERE news_id = ? AND channel_id = ?`, newsID, channelID).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check if news is dry-run posted: %v", err)
+	}
+	return true, nil
+}
+
+// MarkNewsDryRunPosted records that a dry run posted a news item to a channel. delivery is how
+// the item would have reached the channel, e.g. DeliveryLive, or empty if it would not have.
+// Items already recorded are kept as they are.
+func MarkNewsDryRunPosted(b *types.Bot, newsID int64, channelID, delivery string) error {
+	query := `INSERT INTO dry_run_posted (news_id, channel_id, delivery, posted_at) VALUES (?, ?, ?, ?)
+			  ON CONFLICT(news_id, channel_id) DO NOTHING`
+
+	var deliveryValue interface{}
+	if delivery != "" {
+		deliveryValue = delivery
+	}
+	if _, err := b.DB.Exec(query, newsID, channelID, deliveryValue, now().UTC().Format("2006-01-02 15:04:05")); err != nil {
+		return fmt.Errorf("failed to mark news as dry-run posted: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package database
+
+import "testing"
+
+func TestDryRunPosted(t *testing.T) {
+	bot := setupLatencyTest(t)
+
+	if posted, err := IsNewsDryRunPosted(bot, 1, "channel-1"); err != nil || posted {
+		t.Fatalf("Expected nothing to be dry-run posted, got %v (%v)", posted, err)
+	}
+	if err := MarkNewsDryRunPosted(bot, 1, "channel-1", DeliveryLive); err != nil {
+		t.Fatalf("Failed to mark news as dry-run posted: %v", err)
+	}
+	if err := MarkNewsDryRunPosted(bot, 1, "channel-1", ""); err != nil {
+		t.Fatalf("Expected marking twice to succeed, got %v", err)
+	}
+	if posted, err := IsNewsDryRunPosted(bot, 1, "channel-1"); err != nil || !posted {
+		t.Errorf("Expected the news to be dry-run posted, got %v (%v)", posted, err)
+	}
+
+	// Dry-run posts are kept apart from real ones
+	if posted, err := IsNewsPosted(bot, 1, "channel-1"); err != nil || posted {
+		t.Errorf("Expected the news not to be posted for real, got %v (%v)", posted, err)
+	}
+
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// ReserveNewsEvent records that a Discord scheduled event is being created for a news item in a
+// server, and reports whether the caller may create it. It returns false if the server already
+// has an event for the item, or another channel of the server is creating one. After creating
+// the event, the caller records it with SetNewsEvent, or calls ReleaseNewsEvent if that failed.
+func ReserveNewsEvent(b *types.Bot, newsID int64, guildID string) (bool, error) {
+	query := `INSERT INTO news_events (news_id, guild_id, event_id) VALUES (?, ?, '')
+			  ON CONFLICT(news_id, guild_id) DO NOTHING`
+
+	result, err := b.DB.Exec(query, newsID, guildID)
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve news event: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// SetNewsEvent records the ID of the scheduled event created for a news item in a server.
+func SetNewsEvent(b *types.Bot, newsID int64, guildID, eventID string) error {
+	query := `UPDATE news_events SET event_id = ? WHERE news_id = ? AND guild_id = ?`
+
+	if _, err := b.DB.Exec(query, eventID, newsID, guildID); err != nil {
+		return fmt.Errorf("failed to set news event: %v", err)
+	}
+	return nil
+}
+
+// ReleaseNewsEvent removes the reservation of a scheduled event that was not created, so another
+// post of the news item to the server may create it. Recorded events are kept.
+func ReleaseNewsEvent(b *types.Bot, newsID int64, guildID string) error {
+	query := `DELETE FROM news_events WHERE news_id = ? AND guild_id = ? AND event_id = ''`
+
+	if _, err := b.DB.Exec(query, newsID, guildID); err != nil {
+		return fmt.Errorf("failed to release news event: %v", err)
+	}
+	return nil
+}
+
+// GetNewsEvent returns the ID of the scheduled event created for a news item in a server, or an
+// empty string if there is none.
+func GetNewsEvent(b *types.Bot, newsID int64, guildID string) (string, error) {
+	var eventID string
+	err := b.DB.QueryRow(`SELECT event_id FROM news_events WHERE news_id = ? AND guild_id = ?`, newsID, guildID).Scan(&eventID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get news event: %v", err)
+	}
+	return eventID, nil
+}
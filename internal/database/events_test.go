@@ -0,0 +1,41 @@
+package database
+
+import "testing"
+
+func TestNewsEventReservation(t *testing.T) {
+	bot := setupLatencyTest(t)
+
+	// The first channel of a server to post the news creates its event
+	reserved, err := ReserveNewsEvent(bot, 1, "guild-1")
+	if err != nil || !reserved {
+		t.Fatalf("Expected the event to be reserved, got %v (%v)", reserved, err)
+	}
+	if reserved, err := ReserveNewsEvent(bot, 1, "guild-1"); err != nil || reserved {
+		t.Errorf("Expected a second reservation in the server to fail, got %v (%v)", reserved, err)
+	}
+	if reserved, err := ReserveNewsEvent(bot, 1, "guild-2"); err != nil || !reserved {
+		t.Errorf("Expected other servers to get their own event, got %v (%v)", reserved, err)
+	}
+
+	// A failed creation can be retried
+	if err := ReleaseNewsEvent(bot, 1, "guild-2"); err != nil {
+		t.Fatalf("Failed to release event: %v", err)
+	}
+	if reserved, err := ReserveNewsEvent(bot, 1, "guild-2"); err != nil || !reserved {
+		t.Errorf("Expected a released event to be reserved again, got %v (%v)", reserved, err)
+	}
+
+	// Created events are kept
+	if err := SetNewsEvent(bot, 1, "guild-1", "event-1"); err != nil {
+		t.Fatalf("Failed to set event: %v", err)
+	}
+	if err := ReleaseNewsEvent(bot, 1, "guild-1"); err != nil {
+		t.Fatalf("Failed to release event: %v", err)
+	}
+	if eventID, err := GetNewsEvent(bot, 1, "guild-1"); err != nil || eventID != "event-1" {
+		t.Errorf("Expected event-1, got %q (%v)", eventID, err)
+	}
+	if eventID, err := GetNewsEvent(bot, 2, "guild-1"); err != nil || eventID != "" {
+		t.Errorf("Expected no event, got %q (%v)", eventID, err)
+	}
+}
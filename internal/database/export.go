@@ -0,0 +1,190 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// PostingRecord is a posted news row prepared for statistics export.
+type PostingRecord struct {
+	Date           string        // Date is the UTC posting date (YYYY-MM-DD).
+	ChannelID      string        // ChannelID is the channel the news was posted to.
+	NewsID         int64         // NewsID is the posted news item.
+	Tags           []string      // Tags are the news item's tags, empty if it is no longer cached.
+	LatencySeconds sql.NullInt64 // LatencySeconds is set for live deliveries only.
+}
+
+// GetPostingRecords returns the news posted to the given channels since a time,
+// ordered by posting time.
+func GetPostingRecords(b *types.Bot, channelIDs []string, since time.Time) ([]PostingRecord, error) {
+	if len(channelIDs) == 0 {
+		return nil, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(channelIDs)), ",")
+	query := fmt.Sprintf(`SELECT %s, pn.channel_id, pn.news_id, COALESCE(nc.tags, ''),
+					 CASE WHEN pn.delivery = ? THEN pn.latency_seconds END
+			  FROM posted_news pn
+			  LEFT JOIN news_cache nc ON nc.id = pn.news_id
+			  WHERE pn.posted_at >= ? AND pn.channel_id IN (%s)
+			  ORDER BY pn.posted_at, pn.id`, dialectOf(b.DB).date("pn.posted_at"), placeholders)
+
+	args := []interface{}{DeliveryLive, since.UTC().Format("2006-01-02 15:04:05")}
+	for _, channelID := range channelIDs {
+		args = append(args, channelID)
+	}
+
+	rows, err := b.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query posting records: %v", err)
+	}
+	defer rows.Close()
+
+	var records []PostingRecord
+	for rows.Next() {
+		var record PostingRecord
+		var tags string
+		if err := rows.Scan(&record.Date, &record.ChannelID, &record.NewsID, &tags, &record.LatencySeconds); err != nil {
+			return nil, fmt.Errorf("failed to scan posting record: %v", err)
+		}
+		if tags != "" {
+			record.Tags = strings.Split(tags, ",")
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read posting records: %v", err)
+	}
+
+	return records, nil
+}
+
+// News export formats accepted by ExportNews.
+const (
+	NewsExportJSON = "json" // A JSON array of news items, as read by NewsItem.UnmarshalJSON.
+	NewsExportCSV  = "csv"  // A CSV file with a header row and one news item per row.
+)
+
+// newsExportCSVHeader is the header row of a CSV news export.
+var newsExportCSVHeader = []string{"id", "title", "summary", "content", "tags", "platforms", "updated", "thumbnail_url"}
+
+// NewsExportFilter selects the cached news ExportNews writes. Zero fields do not filter.
+type NewsExportFilter struct {
+	Tag      string    // Tag only exports news with this tag (case-insensitive).
+	Platform string    // Platform only exports news for this platform (case-insensitive).
+	Since    time.Time // Since only exports news updated at or after this time.
+	Until    time.Time // Until only exports news updated before this time.
+}
+
+// ExportNews writes the cached news matching filter to w in format (NewsExportJSON or
+// NewsExportCSV), oldest first. Timestamps are written in RFC 3339 format in UTC.
+// It returns the number of news items written.
+func ExportNews(b *types.Bot, w io.Writer, format string, filter NewsExportFilter) (int, error) {
+	var write func(types.NewsItem) error
+	var finish func(count int) error
+	switch format {
+	case NewsExportJSON:
+		separator := "\n  "
+		write = func(item types.NewsItem) error {
+			data, err := json.MarshalIndent(item, "  ", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(w, "%s%s", separator, data)
+			separator = ",\n  "
+			return err
+		}
+		if _, err := io.WriteString(w, "["); err != nil {
+			return 0, fmt.Errorf("failed to write news export: %v", err)
+		}
+		finish = func(count int) error {
+			end := "\n]\n"
+			if count == 0 {
+				end = "]\n"
+			}
+			_, err := io.WriteString(w, end)
+			return err
+		}
+	case NewsExportCSV:
+		cw := csv.NewWriter(w)
+		write = func(item types.NewsItem) error {
+			return cw.Write([]string{
+				strconv.FormatInt(item.ID, 10),
+				item.Title,
+				item.Summary,
+				item.Content,
+				strings.Join(item.Tags, ","),
+				strings.Join(item.Platforms, ","),
+				item.Updated.Format(time.RFC3339),
+				item.ThumbnailURL,
+			})
+		}
+		if err := cw.Write(newsExportCSVHeader); err != nil {
+			return 0, fmt.Errorf("failed to write news export: %v", err)
+		}
+		finish = func(int) error {
+			cw.Flush()
+			return cw.Error()
+		}
+	default:
+		return 0, fmt.Errorf("unknown export format %q: must be %s or %s", format, NewsExportJSON, NewsExportCSV)
+	}
+
+	conditions := []string{"1 = 1"}
+	var args []interface{}
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "updated_at >= ?")
+		args = append(args, filter.Since.UTC().Format("2006-01-02 15:04:05"))
+	}
+	if !filter.Until.IsZero() {
+		conditions = append(conditions, "updated_at < ?")
+		args = append(args, filter.Until.UTC().Format("2006-01-02 15:04:05"))
+	}
+
+	query := fmt.Sprintf(`SELECT id, title, summary, content, tags, platforms, updated_at, thumbnail_url, url, platform_dates, author
+			  FROM news_cache
+			  WHERE %s
+			  ORDER BY updated_at, id`, strings.Join(conditions, " AND "))
+
+	rows, err := b.DB.Query(query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query cached news: %v", err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		item, err := scanNewsItem(rows)
+		if err != nil {
+			return count, err
+		}
+		if filter.Tag != "" && !item.HasTag(filter.Tag) {
+			continue
+		}
+		if filter.Platform != "" && !item.HasPlatform(filter.Platform) {
+			continue
+		}
+		item.Updated = item.Updated.UTC().Truncate(time.Second)
+
+		if err := write(item); err != nil {
+			return count, fmt.Errorf("failed to write news item %d: %v", item.ID, err)
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, fmt.Errorf("failed to read cached news: %v", err)
+	}
+
+	if err := finish(count); err != nil {
+		return count, fmt.Errorf("failed to write news export: %v", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,148 @@
+package database
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// exportNews are cached by setupNewsExportTest, oldest first.
+func exportNews() []types.NewsItem {
+	return []types.NewsItem{
+		{
+			ID: 101, Title: "Patch Notes", Summary: "Fixes, \"quoted\"", Content: "Line one\nLine two",
+			Tags: []string{"patch-notes", "star-trek-online"}, Platforms: []string{"pc"},
+			Updated: time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC), ThumbnailURL: "https://example.com/101.jpg",
+		},
+		{
+			ID: 102, Title: "Event", Summary: "Anniversary", Content: "Celebrate",
+			Tags: []string{"events"}, Platforms: []string{"pc", "xbox", "ps"},
+			Updated: time.Date(2024, 6, 15, 17, 0, 0, 0, time.UTC),
+		},
+		{
+			ID: 103, Title: "Console Update", Summary: "Console patch", Content: "Console fixes",
+			Tags: []string{"patch-notes"}, Platforms: []string{"xbox", "ps"},
+			Updated: time.Date(2024, 7, 2, 12, 0, 0, 0, time.UTC), ThumbnailURL: "https://example.com/103.jpg",
+		},
+	}
+}
+
+func setupNewsExportTest(t *testing.T) *types.Bot {
+	t.Helper()
+	db, err := InitDatabase(testDatabasePath(t, "export.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	bot := &types.Bot{DB: db}
+
+	if err := CacheNews(bot, exportNews()); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+	return bot
+}
+
+func TestExportNewsJSONRoundTrip(t *testing.T) {
+	bot := setupNewsExportTest(t)
+
+	var buf bytes.Buffer
+	count, err := ExportNews(bot, &buf, NewsExportJSON, NewsExportFilter{})
+	if err != nil {
+		t.Fatalf("Failed to export news: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 news items, got %d", count)
+	}
+	if !strings.Contains(buf.String(), `"id": 101,`) || !strings.Contains(buf.String(), `"updated": "2024-05-01T08:30:00Z"`) {
+		t.Errorf("Expected numeric IDs and RFC 3339 timestamps, got:\n%s", buf.String())
+	}
+
+	var newsItems []types.NewsItem
+	if err := json.Unmarshal(buf.Bytes(), &newsItems); err != nil {
+		t.Fatalf("Failed to parse export: %v", err)
+	}
+	expected := exportNews()
+	if len(newsItems) != len(expected) {
+		t.Fatalf("Expected %d news items, got %d", len(expected), len(newsItems))
+	}
+	for i, item := range newsItems {
+		want := expected[i]
+		if item.ID != want.ID || item.Title != want.Title || item.Summary != want.Summary ||
+			item.Content != want.Content || item.ThumbnailURL != want.ThumbnailURL {
+			t.Errorf("Expected %+v, got %+v", want, item)
+		}
+		if !reflect.DeepEqual(item.Tags, want.Tags) || !reflect.DeepEqual(item.Platforms, want.Platforms) {
+			t.Errorf("Expected tags %v and platforms %v, got %v and %v", want.Tags, want.Platforms, item.Tags, item.Platforms)
+		}
+		if !item.Updated.Equal(want.Updated) {
+			t.Errorf("Expected news %d updated at %v, got %v", want.ID, want.Updated, item.Updated)
+		}
+	}
+}
+
+func TestExportNewsCSV(t *testing.T) {
+	bot := setupNewsExportTest(t)
+
+	var buf bytes.Buffer
+	if _, err := ExportNews(bot, &buf, NewsExportCSV, NewsExportFilter{}); err != nil {
+		t.Fatalf("Failed to export news: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse export: %v", err)
+	}
+	if len(records) != 4 || !reflect.DeepEqual(records[0], newsExportCSVHeader) {
+		t.Fatalf("Expected a header and 3 rows, got %v", records)
+	}
+	expected := []string{"101", "Patch Notes", "Fixes, \"quoted\"", "Line one\nLine two", "patch-notes,star-trek-online", "pc",
+		"2024-05-01T08:30:00Z", "https://example.com/101.jpg"}
+	if !reflect.DeepEqual(records[1], expected) {
+		t.Errorf("Expected %q, got %q", expected, records[1])
+	}
+}
+
+func TestExportNewsFilters(t *testing.T) {
+	bot := setupNewsExportTest(t)
+
+	tests := []struct {
+		name     string
+		filter   NewsExportFilter
+		expected []int64
+	}{
+		{"no filter", NewsExportFilter{}, []int64{101, 102, 103}},
+		{"tag", NewsExportFilter{Tag: "Patch-Notes"}, []int64{101, 103}},
+		{"tag is not a substring match", NewsExportFilter{Tag: "patch"}, nil},
+		{"platform", NewsExportFilter{Platform: "xbox"}, []int64{102, 103}},
+		{"since", NewsExportFilter{Since: time.Date(2024, 6, 15, 17, 0, 0, 0, time.UTC)}, []int64{102, 103}},
+		{"until", NewsExportFilter{Until: time.Date(2024, 6, 15, 17, 0, 0, 0, time.UTC)}, []int64{101}},
+		{"combined", NewsExportFilter{Tag: "patch-notes", Platform: "pc", Since: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}, []int64{101}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			count, err := ExportNews(bot, &buf, NewsExportJSON, tt.filter)
+			if err != nil {
+				t.Fatalf("Failed to export news: %v", err)
+			}
+
+			var newsItems []types.NewsItem
+			if err := json.Unmarshal(buf.Bytes(), &newsItems); err != nil {
+				t.Fatalf("Failed to parse export: %v", err)
+			}
+			if ids := newsIDs(newsItems); !reflect.DeepEqual(ids, tt.expected) || count != len(tt.expected) {
+				t.Errorf("Expected news IDs %v, got %v (count %d)", tt.expected, ids, count)
+			}
+		})
+	}
+
+	if _, err := ExportNews(bot, &bytes.Buffer{}, "xml", NewsExportFilter{}); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// DefaultDuplicateWindowDays is how many days a posted article keeps copies of it republished
+// under a new ID from being posted to the same channel, when no window is configured.
+const DefaultDuplicateWindowDays = 14
+
+// fingerprintContentLength is the number of content characters a news fingerprint covers.
+const fingerprintContentLength = 500
+
+// NewsFingerprint returns a hash of a news item's normalized title and the first 500 characters
+// of its normalized content, or of its summary for news fetched without content. Copies of an
+// article republished under a new ID, e.g. for a console release, share the fingerprint.
+func NewsFingerprint(newsItem types.NewsItem) string {
+	return newsFingerprint(newsItem.Title, newsItem.Content, newsItem.Summary)
+}
+
+// newsFingerprint computes the fingerprint of a news item from its text columns.
+func newsFingerprint(title, content, summary string) string {
+	text := normalizeFingerprintText(content)
+	if text == "" {
+		text = normalizeFingerprintText(summary)
+	}
+	if runes := []rune(text); len(runes) > fingerprintContentLength {
+		text = string(runes[:fingerprintContentLength])
+	}
+
+	sum := sha256.Sum256([]byte(normalizeFingerprintText(title) + "\n" + text))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeFingerprintText lowercases text and collapses its whitespace, so formatting changes
+// between copies of an article do not change the fingerprint.
+func normalizeFingerprintText(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+// IsFingerprintPosted reports whether cached news with the fingerprint, other than newsID, was
+// posted to a channel at or after since.
+func IsFingerprintPosted(b *types.Bot, fingerprint string, newsID int64, channelID string, since time.Time) (bool, error) {
+	var posted bool
+	err := b.DB.QueryRow(`SELECT EXISTS (SELECT 1 FROM posted_news p
+						  JOIN news_cache n ON n.id = p.news_id
+						  WHERE n.fingerprint = ? AND n.id != ? AND p.channel_id = ? AND p.posted_at >= ?)`,
+		fingerprint, newsID, channelID, since.UTC().Format("2006-01-02 15:04:05")).Scan(&posted)
+	if err != nil {
+		return false, fmt.Errorf("failed to check posted fingerprint: %v", err)
+	}
+	return posted, nil
+}
+
+// backfillNewsFingerprints computes the fingerprint of news cached before fingerprints were
+// stored.
+func backfillNewsFingerprints(db *sql.DB) error {
+	rows, err := db.Query(`SELECT id, title, COALESCE(content, ''), COALESCE(summary, '')
+						   FROM news_cache WHERE fingerprint IS NULL`)
+	if err != nil {
+		return fmt.Errorf("failed to query news without fingerprints: %v", err)
+	}
+	fingerprints := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var title, content, summary string
+		if err := rows.Scan(&id, &title, &content, &summary); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan news: %v", err)
+		}
+		fingerprints[id] = newsFingerprint(title, content, summary)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return fmt.Errorf("failed to read news without fingerprints: %v", err)
+	}
+	rows.Close()
+
+	if len(fingerprints) == 0 {
+		return nil
+	}
+
+	logger().Infof("Computing fingerprints for %d cached news items", len(fingerprints))
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			logger().Printf("Warning: failed to rollback transaction: %v", rollbackErr)
+		}
+	}()
+
+	for id, fingerprint := range fingerprints {
+		if _, err := tx.Exec(`UPDATE news_cache SET fingerprint = ? WHERE id = ?`, fingerprint, id); err != nil {
+			return fmt.Errorf("failed to store fingerprint of news %d: %v", id, err)
+		}
+	}
+
+	return tx.Commit()
+}
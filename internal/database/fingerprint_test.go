@@ -0,0 +1,136 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+func TestNewsFingerprint(t *testing.T) {
+	original := types.NewsItem{ID: 1, Title: "Rise of Discovery Now on Consoles", Content: "Captain, the fleet needs you."}
+	longContent := strings.Repeat("a", fingerprintContentLength)
+
+	tests := []struct {
+		name     string
+		item     types.NewsItem
+		expected bool
+	}{
+		{"republished with a new ID", types.NewsItem{ID: 2, Title: original.Title, Content: original.Content}, true},
+		{"case and whitespace", types.NewsItem{ID: 2, Title: "  rise of discovery now ON consoles", Content: "Captain,\n\nthe fleet  needs you. "}, true},
+		{"different title", types.NewsItem{ID: 2, Title: "Rise of Discovery Now on PC", Content: original.Content}, false},
+		{"different content", types.NewsItem{ID: 2, Title: original.Title, Content: "Admiral, the fleet needs you."}, false},
+		{"summary without content", types.NewsItem{ID: 2, Title: original.Title, Summary: original.Content}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := NewsFingerprint(tt.item) == NewsFingerprint(original); same != tt.expected {
+				t.Errorf("Expected matching fingerprints: %v, got %v", tt.expected, same)
+			}
+		})
+	}
+
+	// Only the start of the content counts
+	first := NewsFingerprint(types.NewsItem{Title: "Patch Notes", Content: longContent + " for PC"})
+	second := NewsFingerprint(types.NewsItem{Title: "Patch Notes", Content: longContent + " for Xbox and PlayStation"})
+	if first != second {
+		t.Error("Expected content after the first 500 characters to be ignored")
+	}
+}
+
+func TestIsFingerprintPosted(t *testing.T) {
+	db, err := InitDatabase(testDatabasePath(t, "stobot.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+	bot := &types.Bot{DB: db, Config: &types.Config{}}
+
+	if err := AddChannel(bot, "channel-a"); err != nil {
+		t.Fatalf("Failed to add channel: %v", err)
+	}
+	original := types.NewsItem{ID: 1, Title: "Anniversary Event", Content: "Celebrate with us.", Updated: time.Now()}
+	republished := types.NewsItem{ID: 2, Title: "Anniversary Event", Content: "Celebrate with us.", Updated: time.Now()}
+	if err := CacheNews(bot, []types.NewsItem{original, republished}); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+	postedAt := time.Now().UTC().AddDate(0, 0, -3)
+	if _, err := db.Exec(`INSERT INTO posted_news (news_id, channel_id, posted_at) VALUES (1, 'channel-a', ?)`,
+		postedAt.Format("2006-01-02 15:04:05")); err != nil {
+		t.Fatalf("Failed to insert post: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		newsID    int64
+		channelID string
+		since     time.Time
+		expected  bool
+	}{
+		{"copy posted within the window", 2, "channel-a", time.Now().AddDate(0, 0, -14), true},
+		{"copy posted before the window", 2, "channel-a", time.Now().AddDate(0, 0, -1), false},
+		{"other channel", 2, "channel-b", time.Now().AddDate(0, 0, -14), false},
+		{"same news item", 1, "channel-a", time.Now().AddDate(0, 0, -14), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posted, err := IsFingerprintPosted(bot, NewsFingerprint(republished), tt.newsID, tt.channelID, tt.since)
+			if err != nil {
+				t.Fatalf("Failed to check fingerprint: %v", err)
+			}
+			if posted != tt.expected {
+				t.Errorf("Expected posted %v, got %v", tt.expected, posted)
+			}
+		})
+	}
+}
+
+func TestFingerprintBackfill(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "stobot.db")
+
+	// News cached before fingerprints were stored
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE news_cache (
+			id INTEGER PRIMARY KEY,
+			title TEXT NOT NULL,
+			summary TEXT,
+			content TEXT,
+			tags TEXT,
+			platforms TEXT,
+			updated_at DATETIME,
+			thumbnail_url TEXT,
+			fetched_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		INSERT INTO news_cache (id, title, summary, content) VALUES
+			(1, 'Season 35', 'New season', 'The season begins.'),
+			(2, 'Dev Blog', 'Summary only', NULL)`); err != nil {
+		t.Fatalf("Failed to create old schema: %v", err)
+	}
+	db.Close()
+
+	db, err = InitDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to initialize database with migration: %v", err)
+	}
+	defer db.Close()
+
+	expected := map[int64]string{
+		1: NewsFingerprint(types.NewsItem{Title: "Season 35", Summary: "New season", Content: "The season begins."}),
+		2: NewsFingerprint(types.NewsItem{Title: "Dev Blog", Summary: "Summary only"}),
+	}
+	for id, fingerprint := range expected {
+		var stored sql.NullString
+		if err := db.QueryRow(`SELECT fingerprint FROM news_cache WHERE id = ?`, id).Scan(&stored); err != nil {
+			t.Fatalf("Failed to get fingerprint: %v", err)
+		}
+		if stored.String != fingerprint {
+			t.Errorf("Expected news %d to have fingerprint %s, got %q", id, fingerprint, stored.String)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// newsFTSTriggers keep news_fts in step with news_cache. INSERT OR REPLACE does not fire the
+// delete trigger for the replaced row, so the insert trigger clears the old entry itself.
+var newsFTSTriggers = []struct {
+	name string
+	sql  string
+}{
+	{"news_fts_insert", `CREATE TRIGGER IF NOT EXISTS news_fts_insert AFTER INSERT ON news_cache BEGIN
+			DELETE FROM news_fts WHERE rowid = new.id;
+			INSERT INTO news_fts (rowid, title, summary, content)
+			VALUES (new.id, new.title, new.summary, COALESCE(new.content, ''));
+		END`},
+	{"news_fts_update", `CREATE TRIGGER IF NOT EXISTS news_fts_update AFTER UPDATE OF id, title, summary, content ON news_cache BEGIN
+			DELETE FROM news_fts WHERE rowid = old.id;
+			INSERT INTO news_fts (rowid, title, summary, content)
+			VALUES (new.id, new.title, new.summary, COALESCE(new.content, ''));
+		END`},
+	{"news_fts_delete", `CREATE TRIGGER IF NOT EXISTS news_fts_delete AFTER DELETE ON news_cache BEGIN
+			DELETE FROM news_fts WHERE rowid = old.id;
+		END`},
+}
+
+// ftsWordPattern matches text that FTS5 indexes as a token.
+var ftsWordPattern = regexp.MustCompile(`[\pL\pN]`)
+
+// fts5Compiled reports whether the SQLite library was built with FTS5. go-sqlite3 only
+// includes it with the sqlite_fts5 build tag.
+func fts5Compiled(db *sql.DB) (bool, error) {
+	var compiled bool
+	if err := db.QueryRow(`SELECT sqlite_compileoption_used('ENABLE_FTS5')`).Scan(&compiled); err != nil {
+		return false, fmt.Errorf("failed to check for FTS5: %v", err)
+	}
+	return compiled, nil
+}
+
+// migrateNewsFTS creates the news_fts full-text index and its triggers, and backfills it from
+// news_cache. An index left without triggers, e.g. by a build without FTS5, is rebuilt.
+// Without FTS5 the triggers are removed, since writing to news_cache would fail with them.
+// Backends without full-text search have neither.
+func migrateNewsFTS(db *sql.DB) error {
+	if !dialectOf(db).fullTextSearch {
+		return nil
+	}
+
+	compiled, err := fts5Compiled(db)
+	if err != nil {
+		return err
+	}
+	if !compiled {
+		logger().Debug("SQLite was built without FTS5; searches use LIKE")
+		for _, trigger := range newsFTSTriggers {
+			if _, err := db.Exec(`DROP TRIGGER IF EXISTS ` + trigger.name); err != nil {
+				return fmt.Errorf("failed to drop trigger %s: %v", trigger.name, err)
+			}
+		}
+		return nil
+	}
+
+	if _, err := db.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS news_fts USING fts5(title, summary, content)`); err != nil {
+		return fmt.Errorf("failed to create news_fts table: %v", err)
+	}
+
+	var triggerCount int
+	err = db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type='trigger' AND name IN ('news_fts_insert', 'news_fts_update', 'news_fts_delete')`).Scan(&triggerCount)
+	if err != nil {
+		return fmt.Errorf("failed to check for news_fts triggers: %v", err)
+	}
+	if triggerCount == len(newsFTSTriggers) {
+		return nil
+	}
+
+	logger().Info("Building full-text search index for cached news")
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			logger().Printf("Warning: failed to rollback transaction: %v", rollbackErr)
+		}
+	}()
+
+	if _, err := tx.Exec(`DELETE FROM news_fts`); err != nil {
+		return fmt.Errorf("failed to clear news_fts: %v", err)
+	}
+	if _, err := tx.Exec(`INSERT INTO news_fts (rowid, title, summary, content)
+			SELECT id, title, summary, COALESCE(content, '') FROM news_cache`); err != nil {
+		return fmt.Errorf("failed to backfill news_fts: %v", err)
+	}
+	for _, trigger := range newsFTSTriggers {
+		if _, err := tx.Exec(trigger.sql); err != nil {
+			return fmt.Errorf("failed to create trigger %s: %v", trigger.name, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// newsFTSAvailable reports whether searches can use the news_fts index: FTS5 is compiled in and
+// the database has the index. Databases not opened through InitDatabase may lack it.
+func newsFTSAvailable(db *sql.DB) bool {
+	if !dialectOf(db).fullTextSearch {
+		return false
+	}
+	var available bool
+	err := db.QueryRow(`SELECT sqlite_compileoption_used('ENABLE_FTS5')
+			AND EXISTS (SELECT 1 FROM sqlite_master WHERE type='table' AND name='news_fts')`).Scan(&available)
+	if err != nil {
+		logger().Warnf("Failed to check for the full-text search index: %v", err)
+		return false
+	}
+	return available
+}
+
+// ftsPhrase quotes text as an FTS5 phrase. With prefix set, its last word also matches longer
+// words, e.g. "ship" matches "ships". It returns "" if text has nothing to search for.
+func ftsPhrase(text string, prefix bool) string {
+	if !ftsWordPattern.MatchString(text) {
+		return ""
+	}
+	phrase := `"` + strings.ReplaceAll(text, `"`, `""`) + `"`
+	if prefix {
+		phrase += "*"
+	}
+	return phrase
+}
+
+// ftsMatchExpression builds the FTS5 query for a parsed search: any of its terms or phrases,
+// all of its required terms, and none of its excluded terms. It returns "" if the search has
+// no term or phrase to rank by.
+func ftsMatchExpression(query *SearchQuery) string {
+	var anyOf []string
+	for _, term := range query.Terms {
+		if phrase := ftsPhrase(term, true); phrase != "" {
+			anyOf = append(anyOf, phrase)
+		}
+	}
+	for _, text := range query.Phrases {
+		if phrase := ftsPhrase(text, false); phrase != "" {
+			anyOf = append(anyOf, phrase)
+		}
+	}
+	if len(anyOf) == 0 {
+		return ""
+	}
+
+	expression := "(" + strings.Join(anyOf, " OR ") + ")"
+	for _, term := range query.MustHave {
+		if phrase := ftsPhrase(term, true); phrase != "" {
+			expression += " AND " + phrase
+		}
+	}
+	for _, term := range query.MustNot {
+		if phrase := ftsPhrase(term, true); phrase != "" {
+			expression += " NOT " + phrase
+		}
+	}
+	return expression
+}
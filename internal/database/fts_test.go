@@ -0,0 +1,260 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// rankingNews has "tholian" in the content, summary and title of different articles, all
+// updated at the same time so recency does not affect their ranking.
+func rankingNews() []types.NewsItem {
+	updated := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	return []types.NewsItem{
+		{ID: 1, Title: "Weekly Update", Summary: "Event rewards", Content: "The Tholian web event returns with new rewards.", Updated: updated},
+		{ID: 2, Title: "Tholian Assembly Ships", Summary: "New crystalline ships", Content: "Three new ships join the fleet.", Updated: updated},
+		{ID: 3, Title: "Patch Notes", Summary: "Tholian mission fixes", Content: "Several missions were fixed.", Updated: updated},
+		{ID: 4, Title: "Federation News", Summary: "Starship designs", Content: "New starship designs are available.", Updated: updated},
+	}
+}
+
+func newsIDs(newsItems []types.NewsItem) []int64 {
+	var ids []int64
+	for _, newsItem := range newsItems {
+		ids = append(ids, newsItem.ID)
+	}
+	return ids
+}
+
+func resultIDs(results []SearchResult) []int64 {
+	var ids []int64
+	for _, result := range results {
+		ids = append(ids, result.NewsItem.ID)
+	}
+	return ids
+}
+
+func TestFullTextSearchRanking(t *testing.T) {
+	db, err := InitDatabase(filepath.Join(t.TempDir(), "fts.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+	if !newsFTSAvailable(db) {
+		t.Skip("SQLite was built without FTS5; run the tests with -tags sqlite_fts5")
+	}
+
+	bot := &types.Bot{DB: db}
+	if err := CacheNews(bot, rankingNews()); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+
+	// Title matches rank above summary matches, which rank above content matches
+	newsItems, err := SearchNewsContent(bot, "tholian", 10)
+	if err != nil {
+		t.Fatalf("Failed to search news: %v", err)
+	}
+	if ids := newsIDs(newsItems); !reflect.DeepEqual(ids, []int64{2, 3, 1}) {
+		t.Errorf("Expected news IDs [2 3 1], got %v", ids)
+	}
+
+	tests := []struct {
+		query    string
+		expected []int64
+	}{
+		{"tholian", []int64{2, 3, 1}},
+		{"tholian -ships", []int64{3, 1}},
+		{"starship +designs", []int64{4}},
+		{`"web event"`, []int64{1}},
+		{"thol", []int64{2, 3, 1}},
+		{"tholian sort:title order:asc", []int64{3, 2, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			results, err := AdvancedSearchNews(bot, tt.query, 10)
+			if err != nil {
+				t.Fatalf("Failed to search news: %v", err)
+			}
+			if ids := resultIDs(results); !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("Expected news IDs %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+
+	// Re-caching an article replaces its index entry
+	updated := rankingNews()[1]
+	updated.Title = "Assembly Ships"
+	updated.Summary = "Crystalline ships"
+	if err := CacheNews(bot, []types.NewsItem{updated}); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+	newsItems, err = SearchNewsContent(bot, "tholian", 10)
+	if err != nil {
+		t.Fatalf("Failed to search news: %v", err)
+	}
+	if ids := newsIDs(newsItems); !reflect.DeepEqual(ids, []int64{3, 1}) {
+		t.Errorf("Expected news IDs [3 1] after the update, got %v", ids)
+	}
+}
+
+func TestFullTextSearchBackfill(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "fts.db")
+	db, err := InitDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	if !newsFTSAvailable(db) {
+		db.Close()
+		t.Skip("SQLite was built without FTS5; run the tests with -tags sqlite_fts5")
+	}
+
+	// Articles cached by a build without FTS5 are not in the index and it has no triggers
+	for _, trigger := range newsFTSTriggers {
+		if _, err := db.Exec(`DROP TRIGGER ` + trigger.name); err != nil {
+			t.Fatalf("Failed to drop trigger: %v", err)
+		}
+	}
+	if err := CacheNews(&types.Bot{DB: db}, rankingNews()); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+	db.Close()
+
+	db, err = InitDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	defer db.Close()
+
+	newsItems, err := SearchNewsContent(&types.Bot{DB: db}, "tholian", 10)
+	if err != nil {
+		t.Fatalf("Failed to search news: %v", err)
+	}
+	if ids := newsIDs(newsItems); !reflect.DeepEqual(ids, []int64{2, 3, 1}) {
+		t.Errorf("Expected the backfilled index to return [2 3 1], got %v", ids)
+	}
+}
+
+func TestSearchWithoutFullTextIndex(t *testing.T) {
+	// A database without news_fts, as with a build without FTS5, searches with LIKE
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "like.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE news_cache (
+		id INTEGER PRIMARY KEY,
+		title TEXT NOT NULL,
+		summary TEXT,
+		content TEXT,
+		tags TEXT,
+		platforms TEXT,
+		updated_at DATETIME,
+		thumbnail_url TEXT,
+		fetched_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		fingerprint TEXT,
+		url TEXT,
+		platform_dates TEXT,
+		author TEXT
+	)`)
+	if err != nil {
+		t.Fatalf("Failed to create news_cache table: %v", err)
+	}
+	if newsFTSAvailable(db) {
+		t.Fatal("Expected no full-text index")
+	}
+
+	bot := &types.Bot{DB: db}
+	if err := CacheNews(bot, rankingNews()); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+
+	// LIKE also matches inside words
+	newsItems, err := SearchNewsContent(bot, "olian", 10)
+	if err != nil {
+		t.Fatalf("Failed to search news: %v", err)
+	}
+	if len(newsItems) != 3 {
+		t.Errorf("Expected 3 results, got %v", newsIDs(newsItems))
+	}
+
+	results, err := AdvancedSearchNews(bot, "tholian -ships", 10)
+	if err != nil {
+		t.Fatalf("Failed to search news: %v", err)
+	}
+	if ids := resultIDs(results); !reflect.DeepEqual(ids, []int64{3, 1}) {
+		t.Errorf("Expected news IDs [3 1], got %v", ids)
+	}
+}
+
+func TestParseSearchQueryAuthor(t *testing.T) {
+	tests := []struct {
+		query   string
+		authors []string
+		terms   []string
+		phrases []string
+	}{
+		{"author:kael", []string{"kael"}, nil, nil},
+		{`tholian author:"Jane Doe"`, []string{"jane doe"}, []string{"tholian"}, nil},
+		{`Author:"Jane Doe" "web event" author:Woof`, []string{"jane doe", "woof"}, nil, []string{"web event"}},
+		{"author:", nil, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			sq := ParseSearchQuery(tt.query)
+			if !reflect.DeepEqual(sq.Authors, tt.authors) || !reflect.DeepEqual(sq.Terms, tt.terms) || !reflect.DeepEqual(sq.Phrases, tt.phrases) {
+				t.Errorf("Expected authors %v, terms %v and phrases %v; got %v, %v and %v", tt.authors, tt.terms, tt.phrases, sq.Authors, sq.Terms, sq.Phrases)
+			}
+		})
+	}
+}
+
+func TestAdvancedSearchNewsAuthor(t *testing.T) {
+	db, err := InitDatabase(testDatabasePath(t, "authors.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	newsItems := rankingNews()
+	newsItems[0].Author = "Ambassador Kael"
+	newsItems[1].Author = "Jane Doe"
+	newsItems[2].Author = "Jane Doe"
+	bot := &types.Bot{DB: db}
+	if err := CacheNews(bot, newsItems); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+
+	// The author is stored with the cached article
+	cached, err := GetCachedNewsByID(bot, 2)
+	if err != nil || cached == nil {
+		t.Fatalf("Failed to get cached news: %v", err)
+	}
+	if cached.Author != "Jane Doe" {
+		t.Errorf("Expected author Jane Doe, got %q", cached.Author)
+	}
+
+	tests := []struct {
+		query    string
+		expected []int64
+	}{
+		{`author:"jane doe" sort:title order:asc`, []int64{3, 2}},
+		{"author:kael", []int64{1}},
+		{"tholian author:jane sort:title order:asc", []int64{3, 2}},
+		{"starship author:jane", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			results, err := AdvancedSearchNews(bot, tt.query, 10)
+			if err != nil {
+				t.Fatalf("Failed to search news: %v", err)
+			}
+			if ids := resultIDs(results); !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("Expected news IDs %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// UpdateChannelGuild records the Discord server a channel belongs to.
+func UpdateChannelGuild(b *types.Bot, channelID, guildID string) error {
+	query := `UPDATE channels SET guild_id = ? WHERE id = ?`
+
+	result, err := b.DB.Exec(query, sql.NullString{String: guildID, Valid: guildID != ""}, channelID)
+	if err != nil {
+		return fmt.Errorf("failed to update channel guild: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("channel %s not found", channelID)
+	}
+
+	return nil
+}
+
+// GetChannelsByGuild returns the registered channels of a guild, in channel ID order.
+// Channels whose guild is not yet known are not included.
+func GetChannelsByGuild(b *types.Bot, guildID string) ([]string, error) {
+	rows, err := b.DB.Query(`SELECT id FROM channels WHERE guild_id = ? ORDER BY id`, guildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query guild channels: %v", err)
+	}
+	defer rows.Close()
+
+	var channels []string
+	for rows.Next() {
+		var channelID string
+		if err := rows.Scan(&channelID); err != nil {
+			return nil, fmt.Errorf("failed to scan channel: %v", err)
+		}
+		channels = append(channels, channelID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read guild channels: %v", err)
+	}
+
+	return channels, nil
+}
+
+// GetGuildEngagement aggregates the posts to a guild's registered channels. An empty guildID
+// covers all registered channels, including those whose guild is not yet known.
+//
+// The returned map has "channels", "guilds" (servers with a known ID), "unknown_guild_channels",
+// "total_posts" and "weekly_posts" counts, and "posted_by" post counts per bot instance.
+func GetGuildEngagement(b *types.Bot, guildID string) (map[string]interface{}, error) {
+	stats := make(map[string]interface{})
+
+	// Registered channels and the guilds they belong to
+	var channels, guilds, unknownGuildChannels int
+	err := b.DB.QueryRow(`SELECT COUNT(*), COUNT(DISTINCT guild_id), COALESCE(SUM(guild_id IS NULL), 0) FROM channels
+						  WHERE ? = '' OR guild_id = ?`, guildID, guildID).Scan(&channels, &guilds, &unknownGuildChannels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get guild channel count: %v", err)
+	}
+	stats["channels"] = channels
+	stats["guilds"] = guilds
+	stats["unknown_guild_channels"] = unknownGuildChannels
+
+	// Total posts and posts in the last 7 days
+	weekAgo := now().UTC().AddDate(0, 0, -7)
+	var totalPosts, weeklyPosts int
+	err = b.DB.QueryRow(`SELECT COUNT(*), COALESCE(SUM(p.posted_at >= ?), 0) FROM posted_news p
+						 JOIN channels c ON c.id = p.channel_id
+						 WHERE ? = '' OR c.guild_id = ?`,
+		weekAgo.Format("2006-01-02 15:04:05"), guildID, guildID).Scan(&totalPosts, &weeklyPosts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get guild post count: %v", err)
+	}
+	stats["total_posts"] = totalPosts
+	stats["weekly_posts"] = weeklyPosts
+
+	// Posts per bot instance; rows from before instance tracking are grouped as "unknown"
+	rows, err := b.DB.Query(`SELECT COALESCE(p.posted_by, 'unknown'), COUNT(*) FROM posted_news p
+							 JOIN channels c ON c.id = p.channel_id
+							 WHERE ? = '' OR c.guild_id = ?
+							 GROUP BY COALESCE(p.posted_by, 'unknown')`, guildID, guildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get posts by instance: %v", err)
+	}
+	defer rows.Close()
+
+	postedBy := make(map[string]int)
+	for rows.Next() {
+		var instance string
+		var count int
+		if err := rows.Scan(&instance, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan posts by instance: %v", err)
+		}
+		postedBy[instance] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read posts by instance: %v", err)
+	}
+	stats["posted_by"] = postedBy
+
+	return stats, nil
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+func TestGuildEngagement(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "guilds.db")
+
+	// A channel registered before guilds were recorded
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE channels (
+			id TEXT PRIMARY KEY,
+			platforms TEXT NOT NULL DEFAULT 'pc,xbox,ps',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		INSERT INTO channels (id) VALUES ('channel-legacy')`); err != nil {
+		t.Fatalf("Failed to create old schema: %v", err)
+	}
+	db.Close()
+
+	db, err = InitDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to initialize database with migration: %v", err)
+	}
+	defer db.Close()
+	bot := &types.Bot{DB: db}
+
+	cfg, err := GetChannelConfig(bot, "channel-legacy")
+	if err != nil || cfg == nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if cfg.GuildID != "" {
+		t.Errorf("Expected no guild for a channel registered before the migration, got %q", cfg.GuildID)
+	}
+
+	guilds := map[string]string{"channel-a": "guild-1", "channel-b": "guild-1", "channel-c": "guild-2"}
+	for channelID, guildID := range guilds {
+		if err := AddChannel(bot, channelID); err != nil {
+			t.Fatalf("Failed to add channel: %v", err)
+		}
+		if err := UpdateChannelGuild(bot, channelID, guildID); err != nil {
+			t.Fatalf("Failed to update channel guild: %v", err)
+		}
+	}
+	if err := UpdateChannelGuild(bot, "channel-missing", "guild-1"); err == nil {
+		t.Error("Expected an error for an unregistered channel")
+	}
+
+	posts := []struct {
+		newsID    int64
+		channelID string
+		daysAgo   int
+	}{
+		{1, "channel-a", 0},
+		{2, "channel-a", 10},
+		{1, "channel-b", 1},
+		{1, "channel-c", 0},
+		{1, "channel-legacy", 0},
+	}
+	for _, post := range posts {
+		postedAt := time.Now().UTC().AddDate(0, 0, -post.daysAgo).Format("2006-01-02 15:04:05")
+		if _, err := db.Exec(`INSERT INTO posted_news (news_id, channel_id, posted_at, posted_by) VALUES (?, ?, ?, 'bot-1')`,
+			post.newsID, post.channelID, postedAt); err != nil {
+			t.Fatalf("Failed to insert post: %v", err)
+		}
+	}
+
+	channels, err := GetChannelsByGuild(bot, "guild-1")
+	if err != nil {
+		t.Fatalf("Failed to get guild channels: %v", err)
+	}
+	if !reflect.DeepEqual(channels, []string{"channel-a", "channel-b"}) {
+		t.Errorf("Expected [channel-a channel-b], got %v", channels)
+	}
+
+	tests := []struct {
+		name     string
+		guildID  string
+		expected map[string]interface{}
+	}{
+		{"guild", "guild-1", map[string]interface{}{
+			"channels": 2, "guilds": 1, "unknown_guild_channels": 0, "total_posts": 3, "weekly_posts": 2,
+			"posted_by": map[string]int{"bot-1": 3},
+		}},
+		{"all guilds", "", map[string]interface{}{
+			"channels": 4, "guilds": 2, "unknown_guild_channels": 1, "total_posts": 5, "weekly_posts": 4,
+			"posted_by": map[string]int{"bot-1": 5},
+		}},
+		{"unknown guild", "guild-3", map[string]interface{}{
+			"channels": 0, "guilds": 0, "unknown_guild_channels": 0, "total_posts": 0, "weekly_posts": 0,
+			"posted_by": map[string]int{},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats, err := GetGuildEngagement(bot, tt.guildID)
+			if err != nil {
+				t.Fatalf("Failed to get guild engagement: %v", err)
+			}
+			if !reflect.DeepEqual(stats, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, stats)
+			}
+		})
+	}
+}
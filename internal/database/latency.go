@@ -0,0 +1,111 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// Delivery kinds stored in posted_news.delivery. Rows marked in bulk (populate-db,
+// mark-all-posted, new-channel auto-mark) keep NULL. Only live deliveries count
+// towards latency percentiles.
+const (
+	DeliveryLive    = "live"    // Posted by the regular poller.
+	DeliveryCatchUp = "catchup" // Posted by the startup catch-up, typically long after publication.
+	DeliveryDigest  = "digest"  // Collected for a channel's weekly digest instead of being posted on its own.
+	DeliveryManual  = "manual"  // Posted by an administrator with /stobot_post.
+)
+
+// now is the clock used to timestamp deliveries and backups; tests replace it.
+var now = time.Now
+
+// LatencyStats summarizes delivery latency from article publication to Discord post.
+type LatencyStats struct {
+	Count int           // Count is the number of deliveries measured.
+	P50   time.Duration // P50 is the median latency.
+	P95   time.Duration // P95 is the 95th percentile latency.
+}
+
+// MarkNewsAsDelivered marks a news item as posted to a channel and records how long after
+// the item's Updated timestamp the post happened. A pending post of the item, see ReservePost,
+// is marked as sent; a post already sent keeps its record.
+func MarkNewsAsDelivered(b *types.Bot, newsItem types.NewsItem, channelID, delivery string) error {
+	postedAt := now().UTC()
+	query := `INSERT INTO posted_news (news_id, channel_id, posted_at, posted_by, bot_version, latency_seconds, delivery) 
+			  VALUES (?, ?, ?, ?, ?, ?, ?)
+			  ON CONFLICT(news_id, channel_id) DO UPDATE SET status = 'sent', posted_at = excluded.posted_at,
+			    latency_seconds = excluded.latency_seconds, delivery = excluded.delivery
+			  WHERE posted_news.status = 'pending'`
+
+	postedBy, botVersion := postedByValues(b)
+	latency := deliveryLatency(newsItem.Updated, postedAt)
+
+	if _, err := b.DB.Exec(query, newsItem.ID, channelID, postedAt.Format("2006-01-02 15:04:05"),
+		postedBy, botVersion, latency, delivery); err != nil {
+		return fmt.Errorf("failed to mark news as delivered: %v", err)
+	}
+
+	return nil
+}
+
+// deliveryLatency returns the whole seconds between publication and posting. Items without a
+// publication time get NULL; publication times slightly in the future (clock skew) count as 0.
+func deliveryLatency(updated, postedAt time.Time) sql.NullInt64 {
+	if updated.IsZero() {
+		return sql.NullInt64{}
+	}
+
+	seconds := int64(postedAt.Sub(updated) / time.Second)
+	if seconds < 0 {
+		logger().Debugf("News updated at %v is after post time %v, recording zero latency", updated, postedAt)
+		seconds = 0
+	}
+	return sql.NullInt64{Int64: seconds, Valid: true}
+}
+
+// GetDeliveryLatency returns latency percentiles for live deliveries posted since the given time.
+// An empty channelID covers all channels.
+func GetDeliveryLatency(b *types.Bot, channelID string, since time.Time) (LatencyStats, error) {
+	query := `SELECT latency_seconds FROM posted_news 
+			  WHERE delivery = ? AND latency_seconds IS NOT NULL AND posted_at >= ? 
+			    AND (? = '' OR channel_id = ?)
+			  ORDER BY latency_seconds`
+
+	rows, err := b.DB.Query(query, DeliveryLive, since.UTC().Format("2006-01-02 15:04:05"), channelID, channelID)
+	if err != nil {
+		return LatencyStats{}, fmt.Errorf("failed to query delivery latency: %v", err)
+	}
+	defer rows.Close()
+
+	var latencies []int64
+	for rows.Next() {
+		var seconds int64
+		if err := rows.Scan(&seconds); err != nil {
+			return LatencyStats{}, fmt.Errorf("failed to scan delivery latency: %v", err)
+		}
+		latencies = append(latencies, seconds)
+	}
+	if err := rows.Err(); err != nil {
+		return LatencyStats{}, fmt.Errorf("failed to read delivery latency: %v", err)
+	}
+
+	return LatencyStats{
+		Count: len(latencies),
+		P50:   Percentile(latencies, 50),
+		P95:   Percentile(latencies, 95),
+	}, nil
+}
+
+// Percentile returns the nearest-rank percentile of latencies in seconds, which must be sorted.
+func Percentile(sorted []int64, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100 // ceil(p/100 * n)
+	if rank < 1 {
+		rank = 1
+	}
+	return time.Duration(sorted[rank-1]) * time.Second
+}
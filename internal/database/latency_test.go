@@ -0,0 +1,177 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// setClock makes MarkNewsAsDelivered see the given time until the test ends.
+func setClock(t *testing.T, current time.Time) {
+	t.Helper()
+	saved := now
+	now = func() time.Time { return current }
+	t.Cleanup(func() { now = saved })
+}
+
+func setupLatencyTest(t *testing.T) *types.Bot {
+	t.Helper()
+
+	db, err := InitDatabase(testDatabasePath(t, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	bot := &types.Bot{DB: db, Config: &types.Config{}}
+	for _, channelID := range []string{"channel", "channel-a", "channel-b"} {
+		if err := AddChannel(bot, channelID); err != nil {
+			t.Fatalf("Failed to add channel: %v", err)
+		}
+	}
+	return bot
+}
+
+func TestMarkNewsAsDeliveredLatency(t *testing.T) {
+	bot := setupLatencyTest(t)
+	postedAt := time.Date(2025, 6, 11, 18, 0, 0, 0, time.UTC)
+	setClock(t, postedAt)
+
+	tests := []struct {
+		name            string
+		newsID          int64
+		updated         time.Time
+		expectedLatency *int64
+	}{
+		{"ten minutes after publication", 1, postedAt.Add(-10 * time.Minute), int64Ptr(600)},
+		{"sub-second remainder truncated", 2, postedAt.Add(-90*time.Second - 500*time.Millisecond), int64Ptr(90)},
+		{"publication in the future", 3, postedAt.Add(30 * time.Second), int64Ptr(0)},
+		{"no publication time", 4, time.Time{}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newsItem := types.NewsItem{ID: tt.newsID, Title: "News", Updated: tt.updated}
+			if err := MarkNewsAsDelivered(bot, newsItem, "channel", DeliveryLive); err != nil {
+				t.Fatalf("Failed to mark news as delivered: %v", err)
+			}
+
+			var latency *int64
+			var posted string
+			err := bot.DB.QueryRow("SELECT latency_seconds, posted_at FROM posted_news WHERE news_id = ?", tt.newsID).
+				Scan(&latency, &posted)
+			if err != nil {
+				t.Fatalf("Failed to read posted news: %v", err)
+			}
+
+			switch {
+			case tt.expectedLatency == nil && latency != nil:
+				t.Errorf("Expected NULL latency, got %d", *latency)
+			case tt.expectedLatency != nil && latency == nil:
+				t.Errorf("Expected latency %d, got NULL", *tt.expectedLatency)
+			case tt.expectedLatency != nil && *latency != *tt.expectedLatency:
+				t.Errorf("Expected latency %d, got %d", *tt.expectedLatency, *latency)
+			}
+			if posted[:19] != "2025-06-11T18:00:00" && posted[:19] != "2025-06-11 18:00:00" {
+				t.Errorf("Expected posted_at from the injected clock, got %s", posted)
+			}
+		})
+	}
+}
+
+func TestGetDeliveryLatency(t *testing.T) {
+	bot := setupLatencyTest(t)
+	postedAt := time.Date(2025, 6, 11, 18, 0, 0, 0, time.UTC)
+	setClock(t, postedAt)
+
+	// 20 live deliveries in channel-a taking 1..20 minutes
+	for i := 1; i <= 20; i++ {
+		newsItem := types.NewsItem{ID: int64(i), Updated: postedAt.Add(-time.Duration(i) * time.Minute)}
+		if err := MarkNewsAsDelivered(bot, newsItem, "channel-a", DeliveryLive); err != nil {
+			t.Fatalf("Failed to mark news as delivered: %v", err)
+		}
+	}
+
+	// Excluded: catch-up deliveries, bulk marks without latency, and deliveries before the window
+	stale := types.NewsItem{ID: 100, Updated: postedAt.Add(-72 * time.Hour)}
+	if err := MarkNewsAsDelivered(bot, stale, "channel-a", DeliveryCatchUp); err != nil {
+		t.Fatalf("Failed to mark catch-up delivery: %v", err)
+	}
+	if err := MarkNewsAsPosted(bot, 101, "channel-a"); err != nil {
+		t.Fatalf("Failed to mark news as posted: %v", err)
+	}
+	setClock(t, postedAt.AddDate(0, 0, -8))
+	old := types.NewsItem{ID: 102, Updated: postedAt.AddDate(0, 0, -9)}
+	if err := MarkNewsAsDelivered(bot, old, "channel-a", DeliveryLive); err != nil {
+		t.Fatalf("Failed to mark old delivery: %v", err)
+	}
+
+	// One fast live delivery in channel-b
+	setClock(t, postedAt)
+	fast := types.NewsItem{ID: 1, Updated: postedAt.Add(-5 * time.Second)}
+	if err := MarkNewsAsDelivered(bot, fast, "channel-b", DeliveryLive); err != nil {
+		t.Fatalf("Failed to mark news as delivered: %v", err)
+	}
+
+	since := postedAt.AddDate(0, 0, -7)
+	tests := []struct {
+		name      string
+		channelID string
+		expected  LatencyStats
+	}{
+		{"single channel", "channel-a", LatencyStats{Count: 20, P50: 10 * time.Minute, P95: 19 * time.Minute}},
+		{"other channel", "channel-b", LatencyStats{Count: 1, P50: 5 * time.Second, P95: 5 * time.Second}},
+		{"all channels", "", LatencyStats{Count: 21, P50: 10 * time.Minute, P95: 19 * time.Minute}},
+		{"unknown channel", "missing", LatencyStats{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats, err := GetDeliveryLatency(bot, tt.channelID, since)
+			if err != nil {
+				t.Fatalf("Failed to get delivery latency: %v", err)
+			}
+			if stats != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, stats)
+			}
+		})
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []int64
+		p        int
+		expected time.Duration
+	}{
+		{"empty", nil, 50, 0},
+		{"single value", []int64{7}, 95, 7 * time.Second},
+		{"median of even count", []int64{1, 2, 3, 4}, 50, 2 * time.Second},
+		{"median of odd count", []int64{1, 2, 3, 4, 5}, 50, 3 * time.Second},
+		{"p95 of 100", seconds(100), 95, 95 * time.Second},
+		{"p95 of 10", seconds(10), 95, 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Percentile(tt.values, tt.p); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// seconds returns the sorted values 1..n.
+func seconds(n int) []int64 {
+	values := make([]int64, n)
+	for i := range values {
+		values[i] = int64(i + 1)
+	}
+	return values
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
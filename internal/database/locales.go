@@ -0,0 +1,88 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// UpdateChannelLocale sets the locale a channel's news is posted in. The locale must be one of
+// types.Locales.
+func UpdateChannelLocale(b *types.Bot, channelID string, locale string) error {
+	locale, err := types.NormalizeLocale(locale)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE channels SET locale = ?, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = ?`
+
+	result, err := b.DB.Exec(query, locale, channelID)
+	if err != nil {
+		return fmt.Errorf("failed to update channel locale: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("channel %s not found", channelID)
+	}
+
+	return nil
+}
+
+// CacheLocalizedNews caches news items fetched in a locale. News in the default locale is cached
+// with CacheNews; other locales are kept in localized_news, keyed by news ID and locale, so the
+// variants of an article are cached side by side. Search, digests and stats use the default
+// locale only.
+func CacheLocalizedNews(b *types.Bot, locale string, news []types.NewsItem) error {
+	if locale == "" || locale == types.DefaultLocale {
+		return CacheNews(b, news)
+	}
+
+	query := dialectOf(b.DB).upsert("localized_news", []string{"id", "locale"},
+		[]string{"id", "locale", "title", "summary", "content", "tags", "platforms", "updated_at", "thumbnail_url", "url", "fetched_at"},
+		`VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)`)
+	for _, item := range news {
+		if _, err := b.DB.Exec(query, item.ID, locale, item.Title, item.Summary, item.Content,
+			strings.Join(item.Tags, ","), strings.Join(item.Platforms, ","), item.Updated, item.ThumbnailURL, item.URL); err != nil {
+			return fmt.Errorf("failed to cache news item %d in locale %s: %v", item.ID, locale, err)
+		}
+	}
+	return nil
+}
+
+// GetCachedLocalizedNews returns the cached variant of a news item in a locale, or nil if it is
+// not cached in that locale.
+func GetCachedLocalizedNews(b *types.Bot, id int64, locale string) (*types.NewsItem, error) {
+	if locale == "" || locale == types.DefaultLocale {
+		return GetCachedNewsByID(b, id)
+	}
+
+	// The author is not localized, so it is read from the cached news item
+	query := `SELECT id, title, summary, content, tags, platforms, updated_at, thumbnail_url, url, NULL,
+			  (SELECT author FROM news_cache WHERE news_cache.id = localized_news.id)
+			  FROM localized_news
+			  WHERE id = ? AND locale = ?`
+
+	rows, err := b.DB.Query(query, id, locale)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached news %d in locale %s: %v", id, locale, err)
+	}
+	defer rows.Close()
+
+	newsItems, err := parseNewsRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(newsItems) == 0 {
+		return nil, nil
+	}
+
+	return &newsItems[0], nil
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+func TestCacheLocalizedNews(t *testing.T) {
+	db, err := InitDatabase(testDatabasePath(t, "locales.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+	bot := &types.Bot{DB: db}
+
+	updated := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	if err := CacheLocalizedNews(bot, "en", []types.NewsItem{{ID: 1, Title: "Season Update", Updated: updated}}); err != nil {
+		t.Fatalf("Failed to cache English news: %v", err)
+	}
+	if err := CacheLocalizedNews(bot, "de", []types.NewsItem{{ID: 1, Title: "Saison-Update", Tags: []string{"events"}, Updated: updated,
+		URL: "https://playstartrekonline.com/de/news/article/1"}}); err != nil {
+		t.Fatalf("Failed to cache German news: %v", err)
+	}
+	if err := CacheLocalizedNews(bot, "fr", []types.NewsItem{{ID: 1, Title: "Mise à jour de saison", Updated: updated}}); err != nil {
+		t.Fatalf("Failed to cache French news: %v", err)
+	}
+
+	tests := []struct {
+		locale   string
+		expected string
+	}{
+		{locale: "en", expected: "Season Update"},
+		{locale: "", expected: "Season Update"},
+		{locale: "de", expected: "Saison-Update"},
+		{locale: "fr", expected: "Mise à jour de saison"},
+	}
+	for _, tt := range tests {
+		newsItem, err := GetCachedLocalizedNews(bot, 1, tt.locale)
+		if err != nil {
+			t.Fatalf("Failed to get cached news in locale %q: %v", tt.locale, err)
+		}
+		if newsItem == nil || newsItem.Title != tt.expected {
+			t.Errorf("Expected %q in locale %q, got %+v", tt.expected, tt.locale, newsItem)
+		}
+	}
+
+	german, _ := GetCachedLocalizedNews(bot, 1, "de")
+	if german.URL != "https://playstartrekonline.com/de/news/article/1" || len(german.Tags) != 1 || !german.Updated.Equal(updated) {
+		t.Errorf("Expected the German variant's fields to be cached, got %+v", german)
+	}
+
+	// Localized variants stay out of the default cache
+	if count, err := CountCachedNews(bot); err != nil || count != 1 {
+		t.Errorf("Expected 1 cached news item, got %d (%v)", count, err)
+	}
+
+	// Caching a variant again replaces it
+	if err := CacheLocalizedNews(bot, "de", []types.NewsItem{{ID: 1, Title: "Saison-Update (korrigiert)", Updated: updated}}); err != nil {
+		t.Fatalf("Failed to recache German news: %v", err)
+	}
+	if newsItem, _ := GetCachedLocalizedNews(bot, 1, "de"); newsItem == nil || newsItem.Title != "Saison-Update (korrigiert)" {
+		t.Errorf("Expected the German variant to be replaced, got %+v", newsItem)
+	}
+
+	if newsItem, err := GetCachedLocalizedNews(bot, 2, "de"); err != nil || newsItem != nil {
+		t.Errorf("Expected no uncached variant, got %+v (%v)", newsItem, err)
+	}
+}
+
+func TestUpdateChannelLocale(t *testing.T) {
+	db, err := InitDatabase(testDatabasePath(t, "locales.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+	bot := &types.Bot{DB: db}
+
+	if err := AddChannel(bot, "channel-a"); err != nil {
+		t.Fatalf("Failed to add channel: %v", err)
+	}
+	cfg, err := GetChannelConfig(bot, "channel-a")
+	if err != nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if cfg.Locale != types.DefaultLocale {
+		t.Errorf("Expected new channels to use locale %q, got %q", types.DefaultLocale, cfg.Locale)
+	}
+
+	if err := UpdateChannelLocale(bot, "channel-a", "FR"); err != nil {
+		t.Fatalf("Failed to update channel locale: %v", err)
+	}
+	if cfg, _ := GetChannelConfig(bot, "channel-a"); cfg.Locale != "fr" {
+		t.Errorf("Expected locale fr, got %q", cfg.Locale)
+	}
+
+	if err := UpdateChannelLocale(bot, "channel-a", "es"); err == nil {
+		t.Error("Expected an unsupported locale to be rejected")
+	}
+	if err := UpdateChannelLocale(bot, "channel-missing", "de"); err == nil {
+		t.Error("Expected an unregistered channel to be rejected")
+	}
+}
@@ -0,0 +1,12 @@
+package database
+
+import (
+	"github.com/FracKenA/sto_news_discord_bot/internal/logging"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// logger returns the log entry of the news cache, tagged component=database.
+func logger() *log.Entry {
+	return logging.Logger(logging.Database)
+}
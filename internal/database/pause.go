@@ -0,0 +1,81 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// UpdateChannelPaused pauses or resumes posting to a channel. A channel paused with hold set
+// queues the news released during the pause with HoldNews; otherwise that news is skipped.
+// Resuming clears hold, and the news held for the channel stays queued until it is posted.
+func UpdateChannelPaused(b *types.Bot, channelID string, paused, hold bool) error {
+	query := `UPDATE channels SET paused = ?, pause_hold = ?, updated_at = CURRENT_TIMESTAMP 
+			  WHERE id = ?`
+
+	result, err := b.DB.Exec(query, paused, paused && hold, channelID)
+	if err != nil {
+		return fmt.Errorf("failed to update channel paused: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("channel %s not found", channelID)
+	}
+
+	return nil
+}
+
+// HoldNews queues a cached news item for a paused channel, to be posted once it is resumed.
+// Holding an item again keeps its place in the queue.
+func HoldNews(b *types.Bot, newsID int64, channelID string) error {
+	query := dialectOf(b.DB).insertIgnore("held_news", []string{"news_id", "channel_id"}, `VALUES (?, ?)`)
+	if _, err := b.DB.Exec(query, newsID, channelID); err != nil {
+		return fmt.Errorf("failed to hold news %d for channel %s: %v", newsID, channelID, err)
+	}
+	return nil
+}
+
+// GetHeldNews returns the cached news held for a channel that has not been posted to it since,
+// in the order it was held.
+func GetHeldNews(b *types.Bot, channelID string) ([]types.NewsItem, error) {
+	query := `SELECT nc.id, nc.title, nc.summary, nc.content, nc.tags, nc.platforms, nc.updated_at, nc.thumbnail_url, nc.url, nc.platform_dates, nc.author
+			  FROM held_news hn
+			  JOIN news_cache nc ON nc.id = hn.news_id
+			  WHERE hn.channel_id = ?
+			  AND NOT EXISTS (SELECT 1 FROM posted_news pn WHERE pn.news_id = hn.news_id AND pn.channel_id = hn.channel_id)
+			  ORDER BY hn.held_at, hn.news_id`
+
+	rows, err := b.DB.Query(query, channelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query held news: %v", err)
+	}
+	defer rows.Close()
+
+	return parseNewsRows(rows)
+}
+
+// CountHeldNews returns the number of news items held for a channel that have not been posted
+// to it since.
+func CountHeldNews(b *types.Bot, channelID string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM held_news hn
+			  WHERE hn.channel_id = ?
+			  AND NOT EXISTS (SELECT 1 FROM posted_news pn WHERE pn.news_id = hn.news_id AND pn.channel_id = hn.channel_id)`
+	if err := b.DB.QueryRow(query, channelID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count held news: %v", err)
+	}
+	return count, nil
+}
+
+// ClearHeldNews empties the queue of news held for a channel.
+func ClearHeldNews(b *types.Bot, channelID string) error {
+	if _, err := b.DB.Exec(`DELETE FROM held_news WHERE channel_id = ?`, channelID); err != nil {
+		return fmt.Errorf("failed to clear held news for channel %s: %v", channelID, err)
+	}
+	return nil
+}
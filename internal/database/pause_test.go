@@ -0,0 +1,91 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+func TestPauseChannel(t *testing.T) {
+	db, err := InitDatabase(testDatabasePath(t, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+	bot := &types.Bot{DB: db}
+
+	if err := AddChannel(bot, "channel-a"); err != nil {
+		t.Fatalf("Failed to add channel: %v", err)
+	}
+	if err := CacheNews(bot, []types.NewsItem{
+		{ID: 1, Title: "Season Update", Updated: time.Now()},
+		{ID: 2, Title: "Patch Notes", Updated: time.Now()},
+		{ID: 3, Title: "Dev Blog", Updated: time.Now()},
+	}); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+
+	if err := UpdateChannelPaused(bot, "channel-a", true, true); err != nil {
+		t.Fatalf("Failed to pause channel: %v", err)
+	}
+	cfg, err := GetChannelConfig(bot, "channel-a")
+	if err != nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if !cfg.Paused || !cfg.PauseHold {
+		t.Errorf("Expected the channel to be paused in hold mode, got %+v", cfg)
+	}
+
+	// Paused channels are still visited by the poller, which skips or holds their news
+	visited := 0
+	if err := ForEachActiveChannel(bot, func(ChannelConfig) error { visited++; return nil }); err != nil {
+		t.Fatalf("Failed to list channels: %v", err)
+	}
+	if visited != 1 {
+		t.Errorf("Expected the paused channel to be visited, got %d channels", visited)
+	}
+
+	for _, newsID := range []int64{2, 1, 2} {
+		if err := HoldNews(bot, newsID, "channel-a"); err != nil {
+			t.Fatalf("Failed to hold news: %v", err)
+		}
+	}
+	heldNews, err := GetHeldNews(bot, "channel-a")
+	if err != nil {
+		t.Fatalf("Failed to get held news: %v", err)
+	}
+	if len(heldNews) != 2 {
+		t.Fatalf("Expected 2 held news items, got %+v", heldNews)
+	}
+
+	// Posted news is no longer held
+	if err := MarkNewsAsPosted(bot, 1, "channel-a"); err != nil {
+		t.Fatalf("Failed to mark news as posted: %v", err)
+	}
+	if count, err := CountHeldNews(bot, "channel-a"); err != nil || count != 1 {
+		t.Errorf("Expected 1 held news item, got %d (%v)", count, err)
+	}
+
+	// Resuming clears the hold mode but keeps the queue
+	if err := UpdateChannelPaused(bot, "channel-a", false, true); err != nil {
+		t.Fatalf("Failed to resume channel: %v", err)
+	}
+	if cfg, _ := GetChannelConfig(bot, "channel-a"); cfg.Paused || cfg.PauseHold {
+		t.Errorf("Expected the channel to be resumed, got %+v", cfg)
+	}
+	if count, _ := CountHeldNews(bot, "channel-a"); count != 1 {
+		t.Errorf("Expected the held news to be kept on resume, got %d", count)
+	}
+
+	if err := ClearHeldNews(bot, "channel-a"); err != nil {
+		t.Fatalf("Failed to clear held news: %v", err)
+	}
+	if count, _ := CountHeldNews(bot, "channel-a"); count != 0 {
+		t.Errorf("Expected no held news after clearing, got %d", count)
+	}
+
+	if err := UpdateChannelPaused(bot, "channel-unknown", true, false); err == nil {
+		t.Error("Expected an error for a channel that is not registered")
+	}
+}
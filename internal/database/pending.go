@@ -0,0 +1,100 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// Post status values stored in posted_news.status. A post is recorded as pending before it is
+// sent to Discord and marked as sent afterwards, so a crash between the two leaves a pending row
+// instead of no record at all.
+const (
+	PostStatusPending = "pending" // The post is being sent, or the bot stopped while sending it.
+	PostStatusSent    = "sent"    // The post was sent, or the item was marked as posted without one.
+)
+
+// PendingPostTimeout is how long a pending post counts as posted. Older pending posts were
+// interrupted, e.g. by a crash, and are reconciled at startup or claimed again by ReservePost.
+const PendingPostTimeout = 10 * time.Minute
+
+// confirmPendingPost completes an INSERT into posted_news so that it marks a pending post of the
+// same news and channel as sent instead of being ignored.
+const confirmPendingPost = `
+			  ON CONFLICT(news_id, channel_id) DO UPDATE SET status = 'sent' WHERE posted_news.status = 'pending'`
+
+// PendingPost is a post of a news item to a channel that was recorded but not confirmed as sent.
+type PendingPost struct {
+	NewsID    int64
+	ChannelID string
+	PostedAt  time.Time // PostedAt is when sending the post started.
+}
+
+// pendingCutoff returns the posted_at value before which pending posts are stale.
+func pendingCutoff() string {
+	return now().UTC().Add(-PendingPostTimeout).Format("2006-01-02 15:04:05")
+}
+
+// ReservePost records a news item as pending for a channel before it is sent, and reports
+// whether the caller may send it. It returns false if the item is already posted or another
+// post of it is in progress; a stale pending post is taken over. After sending, the caller
+// marks the post with MarkNewsAsDelivered, or calls ReleasePost if sending failed.
+func ReservePost(b *types.Bot, newsID int64, channelID string) (bool, error) {
+	query := `INSERT INTO posted_news (news_id, channel_id, posted_at, posted_by, bot_version, status) 
+			  VALUES (?, ?, ?, ?, ?, ?)
+			  ON CONFLICT(news_id, channel_id) DO UPDATE SET posted_at = excluded.posted_at,
+			    posted_by = excluded.posted_by, bot_version = excluded.bot_version
+			  WHERE posted_news.status = 'pending' AND posted_news.posted_at < ?`
+
+	postedBy, botVersion := postedByValues(b)
+	result, err := b.DB.Exec(query, newsID, channelID, now().UTC().Format("2006-01-02 15:04:05"),
+		postedBy, botVersion, PostStatusPending, pendingCutoff())
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve post: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// ReleasePost removes the pending record of a post that was not sent, so it is posted again later.
+// Posts already marked as sent are kept.
+func ReleasePost(b *types.Bot, newsID int64, channelID string) error {
+	query := `DELETE FROM posted_news WHERE news_id = ? AND channel_id = ? AND status = ?`
+
+	if _, err := b.DB.Exec(query, newsID, channelID, PostStatusPending); err != nil {
+		return fmt.Errorf("failed to release post: %v", err)
+	}
+	return nil
+}
+
+// GetStalePendingPosts returns the pending posts older than PendingPostTimeout, oldest first.
+func GetStalePendingPosts(b *types.Bot) ([]PendingPost, error) {
+	query := `SELECT news_id, channel_id, posted_at FROM posted_news 
+			  WHERE status = ? AND posted_at < ?
+			  ORDER BY posted_at, id`
+
+	rows, err := b.DB.Query(query, PostStatusPending, pendingCutoff())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending posts: %v", err)
+	}
+	defer rows.Close()
+
+	var posts []PendingPost
+	for rows.Next() {
+		var post PendingPost
+		if err := rows.Scan(&post.NewsID, &post.ChannelID, &post.PostedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan pending post: %v", err)
+		}
+		posts = append(posts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read pending posts: %v", err)
+	}
+
+	return posts, nil
+}
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+func TestPendingPosts(t *testing.T) {
+	bot := seedChannelDatabase(t, 1)
+	channelID := "channel-00000"
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	setClock(t, start)
+
+	isPosted := func(newsID int64) bool {
+		t.Helper()
+		posted, err := IsNewsPosted(bot, newsID, channelID)
+		if err != nil {
+			t.Fatalf("Failed to check if news %d is posted: %v", newsID, err)
+		}
+		return posted
+	}
+	reserve := func(newsID int64) bool {
+		t.Helper()
+		reserved, err := ReservePost(bot, newsID, channelID)
+		if err != nil {
+			t.Fatalf("Failed to reserve news %d: %v", newsID, err)
+		}
+		return reserved
+	}
+
+	if !reserve(1) {
+		t.Fatal("Expected the first reservation to succeed")
+	}
+	if reserve(1) {
+		t.Error("Expected a post in progress not to be reserved twice")
+	}
+	if !isPosted(1) {
+		t.Error("Expected a recent pending post to count as posted")
+	}
+
+	// Sent posts are confirmed, failed ones released for the next poll
+	if err := MarkNewsAsDelivered(bot, types.NewsItem{ID: 1, Updated: start.Add(-time.Minute)}, channelID, DeliveryLive); err != nil {
+		t.Fatalf("Failed to mark news as delivered: %v", err)
+	}
+	if !reserve(2) {
+		t.Fatal("Expected reserving news 2 to succeed")
+	}
+	if err := ReleasePost(bot, 2, channelID); err != nil {
+		t.Fatalf("Failed to release post: %v", err)
+	}
+	if isPosted(2) {
+		t.Error("Expected a released post not to count as posted")
+	}
+	if err := ReleasePost(bot, 1, channelID); err != nil {
+		t.Fatalf("Failed to release post: %v", err)
+	}
+	if !isPosted(1) {
+		t.Error("Expected releasing a sent post to keep it")
+	}
+
+	var status string
+	var latency int64
+	if err := bot.DB.QueryRow(`SELECT status, latency_seconds FROM posted_news WHERE news_id = 1`).Scan(&status, &latency); err != nil {
+		t.Fatalf("Failed to read post: %v", err)
+	}
+	if status != PostStatusSent || latency != 60 {
+		t.Errorf("Expected a sent post with 60s latency, got %s and %ds", status, latency)
+	}
+
+	// A post interrupted by a crash stays pending until it times out
+	if !reserve(3) {
+		t.Fatal("Expected reserving news 3 to succeed")
+	}
+	setClock(t, start.Add(PendingPostTimeout+time.Minute))
+	if isPosted(3) {
+		t.Error("Expected a stale pending post not to count as posted")
+	}
+	stale, err := GetStalePendingPosts(bot)
+	if err != nil {
+		t.Fatalf("Failed to get stale pending posts: %v", err)
+	}
+	if len(stale) != 1 || stale[0].NewsID != 3 || stale[0].ChannelID != channelID || !stale[0].PostedAt.Equal(start) {
+		t.Errorf("Expected news 3 to be stale since %v, got %+v", start, stale)
+	}
+	if !isPosted(1) {
+		t.Error("Expected a sent post to stay posted")
+	}
+
+	// Marking the item as posted confirms the stale post, as when it is found in the channel
+	if err := MarkNewsAsPosted(bot, 3, channelID); err != nil {
+		t.Fatalf("Failed to mark news as posted: %v", err)
+	}
+	if !isPosted(3) {
+		t.Error("Expected the confirmed post to count as posted")
+	}
+	if reserve(3) {
+		t.Error("Expected a sent post not to be reserved again")
+	}
+
+	// A stale post that is not confirmed is taken over by the next reservation
+	setClock(t, start)
+	if !reserve(4) {
+		t.Fatal("Expected reserving news 4 to succeed")
+	}
+	setClock(t, start.Add(PendingPostTimeout+time.Minute))
+	if !reserve(4) {
+		t.Error("Expected a stale pending post to be reserved again")
+	}
+	if stale, err := GetStalePendingPosts(bot); err != nil || len(stale) != 0 {
+		t.Errorf("Expected no stale posts after the takeover, got %+v (%v)", stale, err)
+	}
+}
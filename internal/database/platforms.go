@@ -0,0 +1,133 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// BulkPlatformsOptions selects the channels BulkUpdateChannelPlatforms updates and how.
+type BulkPlatformsOptions struct {
+	// ChannelIDs are the channels to update; empty updates every registered channel.
+	ChannelIDs []string
+	// Environment restricts the update to channels in this environment (DEV or PROD); empty
+	// updates channels in any environment. Channels given by ID in another environment fail.
+	Environment string
+	// DryRun reports the changes without making them.
+	DryRun bool
+	// IgnoreErrors updates the other channels when some fail; otherwise nothing is updated.
+	IgnoreErrors bool
+}
+
+// ChannelPlatformsResult is the outcome of setting the platforms of one channel.
+type ChannelPlatformsResult struct {
+	ChannelID string
+	Previous  []string // Previous are the channel's platforms before the update; nil if it failed.
+	Changed   bool     // Changed reports whether the platforms differ from Previous.
+	Err       error    // Err is why the channel could not be updated; nil if it was.
+}
+
+// BulkUpdateChannelPlatforms sets the platforms of many channels in one transaction and returns
+// the outcome for each channel, in the order given or in channel ID order for all channels.
+// Platforms are normalized with types.NormalizePlatforms; unknown platforms and an empty list are
+// rejected before any channel is touched.
+//
+// Unless options.IgnoreErrors is set, a channel that cannot be updated, e.g. because it is not
+// registered, rolls back the whole update and its error is returned along with the results so far.
+// With options.DryRun, the results are reported but the transaction is always rolled back.
+//
+// Example:
+//
+//	results, err := database.BulkUpdateChannelPlatforms(b, []string{"pc", "ps"},
+//		database.BulkPlatformsOptions{Environment: "PROD"})
+func BulkUpdateChannelPlatforms(b *types.Bot, platforms []string, options BulkPlatformsOptions) ([]ChannelPlatformsResult, error) {
+	platforms, err := types.NormalizePlatforms(platforms)
+	if err != nil {
+		return nil, err
+	}
+	platformsStr := strings.Join(platforms, ",")
+
+	tx, err := b.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			logger().Printf("Warning: failed to rollback transaction: %v", rollbackErr)
+		}
+	}()
+
+	channelIDs := options.ChannelIDs
+	if len(channelIDs) == 0 {
+		if channelIDs, err = channelIDsInEnvironment(tx, options.Environment); err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]ChannelPlatformsResult, 0, len(channelIDs))
+	for _, channelID := range channelIDs {
+		result := ChannelPlatformsResult{ChannelID: channelID}
+		result.Previous, result.Err = setChannelPlatformsTx(tx, channelID, platformsStr, options.Environment)
+		result.Changed = result.Err == nil && !slices.Equal(result.Previous, platforms)
+		results = append(results, result)
+		if result.Err != nil && !options.IgnoreErrors {
+			return results, fmt.Errorf("failed to update channel %s, no channel was updated: %v", channelID, result.Err)
+		}
+	}
+
+	if options.DryRun {
+		return results, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return results, fmt.Errorf("failed to commit channel platforms: %v", err)
+	}
+	return results, nil
+}
+
+// channelIDsInEnvironment returns the IDs of the registered channels in an environment, or of all
+// registered channels if environment is empty, in ID order.
+func channelIDsInEnvironment(tx *sql.Tx, environment string) ([]string, error) {
+	rows, err := tx.Query(`SELECT id FROM channels WHERE ? = '' OR environment = ? ORDER BY id`, environment, environment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query channels: %v", err)
+	}
+	defer rows.Close()
+
+	var channelIDs []string
+	for rows.Next() {
+		var channelID string
+		if err := rows.Scan(&channelID); err != nil {
+			return nil, fmt.Errorf("failed to scan channel: %v", err)
+		}
+		channelIDs = append(channelIDs, channelID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read channels: %v", err)
+	}
+	return channelIDs, nil
+}
+
+// setChannelPlatformsTx sets the platforms of a registered channel within tx and returns its
+// previous platforms. A channel outside environment, unless it is empty, is not updated.
+func setChannelPlatformsTx(tx *sql.Tx, channelID, platforms, environment string) ([]string, error) {
+	var previous, channelEnvironment string
+	err := tx.QueryRow(`SELECT platforms, environment FROM channels WHERE id = ?`, channelID).Scan(&previous, &channelEnvironment)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("channel %s is not registered", channelID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channel %s: %v", channelID, err)
+	}
+	if environment != "" && channelEnvironment != environment {
+		return nil, fmt.Errorf("channel %s is in environment %s, not %s", channelID, channelEnvironment, environment)
+	}
+
+	if _, err := tx.Exec(`UPDATE channels SET platforms = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`, platforms, channelID); err != nil {
+		return nil, fmt.Errorf("failed to update channel platforms: %v", err)
+	}
+	return strings.Split(previous, ","), nil
+}
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// channelPlatforms returns the platforms of the channels channel-00000 to channel-<count-1>, as
+// comma-separated lists.
+func channelPlatforms(t *testing.T, bot *types.Bot, count int) []string {
+	t.Helper()
+	var platforms []string
+	for n := 0; n < count; n++ {
+		channelID := fmt.Sprintf("channel-%05d", n)
+		cfg, err := GetChannelConfig(bot, channelID)
+		if err != nil || cfg == nil {
+			t.Fatalf("Failed to get channel %s: %v", channelID, err)
+		}
+		platforms = append(platforms, strings.Join(cfg.Platforms, ","))
+	}
+	return platforms
+}
+
+func TestBulkUpdateChannelPlatforms(t *testing.T) {
+	// channel-00000 and channel-00002 are PROD, channel-00001 and channel-00003 DEV, all pc,xbox
+	bot := seedChannelDatabase(t, 4)
+
+	// A subset, with a channel that already has the platforms
+	if err := UpdateChannelPlatforms(bot, "channel-00002", []string{"pc"}); err != nil {
+		t.Fatalf("Failed to update platforms: %v", err)
+	}
+	results, err := BulkUpdateChannelPlatforms(bot, []string{"PC"}, BulkPlatformsOptions{ChannelIDs: []string{"channel-00001", "channel-00002"}})
+	if err != nil {
+		t.Fatalf("Failed to update platforms: %v", err)
+	}
+	if len(results) != 2 || !results[0].Changed || results[1].Changed || !slices.Equal(results[0].Previous, []string{"pc", "xbox"}) {
+		t.Errorf("Expected channel-00001 changed from pc,xbox and channel-00002 unchanged, got %+v", results)
+	}
+	expected := []string{"pc,xbox", "pc", "pc", "pc,xbox"}
+	if platforms := channelPlatforms(t, bot, 4); !slices.Equal(platforms, expected) {
+		t.Errorf("Expected %v, got %v", expected, platforms)
+	}
+
+	// All channels of an environment
+	if results, err = BulkUpdateChannelPlatforms(bot, []string{"ps"}, BulkPlatformsOptions{Environment: "DEV"}); err != nil || len(results) != 2 {
+		t.Fatalf("Expected the 2 DEV channels updated, got %+v (%v)", results, err)
+	}
+	expected = []string{"pc,xbox", "ps", "pc", "ps"}
+	if platforms := channelPlatforms(t, bot, 4); !slices.Equal(platforms, expected) {
+		t.Errorf("Expected %v, got %v", expected, platforms)
+	}
+
+	// All channels, as a dry run
+	if results, err = BulkUpdateChannelPlatforms(bot, []string{"all"}, BulkPlatformsOptions{DryRun: true}); err != nil || len(results) != 4 {
+		t.Fatalf("Expected 4 channels reported, got %+v (%v)", results, err)
+	}
+	if platforms := channelPlatforms(t, bot, 4); !slices.Equal(platforms, expected) {
+		t.Errorf("Expected a dry run to change nothing, got %v", platforms)
+	}
+
+	// All channels
+	if results, err = BulkUpdateChannelPlatforms(bot, []string{"all"}, BulkPlatformsOptions{}); err != nil || len(results) != 4 {
+		t.Fatalf("Expected 4 channels updated, got %+v (%v)", results, err)
+	}
+	expected = []string{"pc,xbox,ps", "pc,xbox,ps", "pc,xbox,ps", "pc,xbox,ps"}
+	if platforms := channelPlatforms(t, bot, 4); !slices.Equal(platforms, expected) {
+		t.Errorf("Expected every channel on all platforms, got %v", platforms)
+	}
+
+	// Empty and unknown platforms are rejected
+	for _, platforms := range [][]string{nil, {""}, {"switch"}} {
+		if _, err := BulkUpdateChannelPlatforms(bot, platforms, BulkPlatformsOptions{}); err == nil {
+			t.Errorf("Expected platforms %q to be rejected", platforms)
+		}
+	}
+}
+
+func TestBulkUpdateChannelPlatformsPartialFailure(t *testing.T) {
+	bot := seedChannelDatabase(t, 2)
+	channelIDs := []string{"channel-00000", "missing", "channel-00001"}
+
+	// Without IgnoreErrors, the unregistered channel rolls back the update of channel-00000
+	results, err := BulkUpdateChannelPlatforms(bot, []string{"ps"}, BulkPlatformsOptions{ChannelIDs: channelIDs})
+	if err == nil {
+		t.Fatal("Expected an error for an unregistered channel")
+	}
+	if len(results) != 2 || results[0].Err != nil || results[1].Err == nil {
+		t.Errorf("Expected results up to the failing channel, got %+v", results)
+	}
+	if platforms := channelPlatforms(t, bot, 2); !slices.Equal(platforms, []string{"pc,xbox", "pc,xbox"}) {
+		t.Errorf("Expected no channel updated, got %v", platforms)
+	}
+
+	// A channel outside the environment fails too
+	if _, err := BulkUpdateChannelPlatforms(bot, []string{"ps"}, BulkPlatformsOptions{ChannelIDs: []string{"channel-00001"}, Environment: "PROD"}); err == nil {
+		t.Error("Expected an error for a channel in another environment")
+	}
+
+	// With IgnoreErrors, the other channels are updated
+	results, err = BulkUpdateChannelPlatforms(bot, []string{"ps"}, BulkPlatformsOptions{ChannelIDs: channelIDs, IgnoreErrors: true})
+	if err != nil {
+		t.Fatalf("Expected errors to be ignored, got %v", err)
+	}
+	if len(results) != 3 || results[1].Err == nil || results[0].Err != nil || results[2].Err != nil {
+		t.Errorf("Expected only the unregistered channel to fail, got %+v", results)
+	}
+	if platforms := channelPlatforms(t, bot, 2); !slices.Equal(platforms, []string{"ps", "ps"}) {
+		t.Errorf("Expected both registered channels updated, got %v", platforms)
+	}
+}
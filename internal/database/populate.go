@@ -0,0 +1,48 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// PopulateState is the progress of populate-db for a tag, checkpointed after each page so an
+// interrupted run resumes where it stopped.
+type PopulateState struct {
+	Tag        string // Tag is the news tag populated.
+	LastOffset int    // LastOffset is the number of news items of the tag populated so far.
+	Completed  bool   // Completed is set once every requested item of the tag was populated.
+}
+
+// GetPopulateState returns the populate-db progress of tag, which is empty for a tag never
+// populated.
+func GetPopulateState(b *types.Bot, tag string) (PopulateState, error) {
+	state := PopulateState{Tag: tag}
+	query := `SELECT last_offset, completed FROM populate_state WHERE tag = ?`
+	err := b.DB.QueryRow(query, tag).Scan(&state.LastOffset, &state.Completed)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return state, fmt.Errorf("failed to get populate state for tag %s: %v", tag, err)
+	}
+	return state, nil
+}
+
+// SavePopulateState checkpoints the populate-db progress of a tag.
+func SavePopulateState(b *types.Bot, state PopulateState) error {
+	query := `INSERT INTO populate_state (tag, last_offset, completed, updated_at) VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+			  ON CONFLICT(tag) DO UPDATE SET last_offset = excluded.last_offset, completed = excluded.completed,
+			  updated_at = excluded.updated_at`
+	if _, err := b.DB.Exec(query, state.Tag, state.LastOffset, state.Completed); err != nil {
+		return fmt.Errorf("failed to save populate state for tag %s: %v", state.Tag, err)
+	}
+	return nil
+}
+
+// ResetPopulateState forgets the populate-db progress of tag, so it is populated from the start.
+func ResetPopulateState(b *types.Bot, tag string) error {
+	if _, err := b.DB.Exec(`DELETE FROM populate_state WHERE tag = ?`, tag); err != nil {
+		return fmt.Errorf("failed to reset populate state for tag %s: %v", tag, err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package database
+
+import "testing"
+
+func TestPopulateState(t *testing.T) {
+	bot := setupSubscriptionTest(t)
+
+	state, err := GetPopulateState(bot, "patch-notes")
+	if err != nil {
+		t.Fatalf("Failed to get populate state: %v", err)
+	}
+	if state != (PopulateState{Tag: "patch-notes"}) {
+		t.Errorf("Expected no progress for a new tag, got %+v", state)
+	}
+
+	for _, saved := range []PopulateState{
+		{Tag: "patch-notes", LastOffset: 100},
+		{Tag: "patch-notes", LastOffset: 150, Completed: true},
+		{Tag: "star-trek-online", LastOffset: 200},
+	} {
+		if err := SavePopulateState(bot, saved); err != nil {
+			t.Fatalf("Failed to save populate state: %v", err)
+		}
+	}
+	if state, _ = GetPopulateState(bot, "patch-notes"); state != (PopulateState{Tag: "patch-notes", LastOffset: 150, Completed: true}) {
+		t.Errorf("Expected the last checkpoint, got %+v", state)
+	}
+
+	if err := ResetPopulateState(bot, "patch-notes"); err != nil {
+		t.Fatalf("Failed to reset populate state: %v", err)
+	}
+	if state, _ = GetPopulateState(bot, "patch-notes"); state.LastOffset != 0 || state.Completed {
+		t.Errorf("Expected the progress to be forgotten, got %+v", state)
+	}
+	if state, _ = GetPopulateState(bot, "star-trek-online"); state.LastOffset != 200 {
+		t.Errorf("Expected other tags to keep their progress, got %+v", state)
+	}
+}
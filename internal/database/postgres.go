@@ -0,0 +1,174 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// postgresDriverName is the database/sql driver of PostgreSQL databases: lib/pq with the
+// placeholders and column types of the queries of this package rewritten from SQLite's.
+const postgresDriverName = "stobot-postgres"
+
+func init() {
+	sql.Register(postgresDriverName, postgresDriver{})
+}
+
+// initPostgres initializes a PostgreSQL database. Tables are created and migrated as for SQLite;
+// there is no pre-migration backup, which is left to pg_dump, and no full-text index, so
+// searches use LIKE.
+func initPostgres(url string, options InitOptions) (*sql.DB, error) {
+	db, err := sql.Open(postgresDriverName, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(options.MaxOpenConns)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %v", err)
+	}
+
+	if err := createTables(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create tables: %v", err)
+	}
+	if err := setSchemaVersion(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	logger().Info("PostgreSQL database initialized successfully")
+	return db, nil
+}
+
+// postgresDriver opens lib/pq connections that rewrite queries with rewritePostgres.
+type postgresDriver struct{}
+
+// Open opens a connection to the database at a PostgreSQL URL or key=value connection string.
+func (postgresDriver) Open(dsn string) (driver.Conn, error) {
+	conn, err := pq.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &postgresConn{conn}, nil
+}
+
+// postgresConn is a lib/pq connection that rewrites queries for PostgreSQL.
+type postgresConn struct {
+	driver.Conn
+}
+
+// Prepare prepares a rewritten query.
+func (c *postgresConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext prepares a rewritten query.
+func (c *postgresConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.Conn.(driver.ConnPrepareContext).PrepareContext(ctx, rewritePostgres(query))
+}
+
+// ExecContext runs a rewritten statement.
+func (c *postgresConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.Conn.(driver.ExecerContext).ExecContext(ctx, rewritePostgres(query), args)
+}
+
+// QueryContext runs a rewritten query.
+func (c *postgresConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.Conn.(driver.QueryerContext).QueryContext(ctx, rewritePostgres(query), args)
+}
+
+// BeginTx starts a transaction.
+func (c *postgresConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return c.Conn.(driver.ConnBeginTx).BeginTx(ctx, opts)
+}
+
+// Ping checks the connection.
+func (c *postgresConn) Ping(ctx context.Context) error {
+	return c.Conn.(driver.Pinger).Ping(ctx)
+}
+
+// ResetSession checks the connection before it is reused.
+func (c *postgresConn) ResetSession(ctx context.Context) error {
+	return c.Conn.(driver.SessionResetter).ResetSession(ctx)
+}
+
+// IsValid reports whether the connection can be reused.
+func (c *postgresConn) IsValid() bool {
+	return c.Conn.(driver.Validator).IsValid()
+}
+
+// CheckNamedValue stores booleans as 0 and 1, as SQLite does, since the flag columns are
+// integers. Other values are converted as usual.
+func (c *postgresConn) CheckNamedValue(nv *driver.NamedValue) error {
+	b, ok := nv.Value.(bool)
+	if !ok {
+		return driver.ErrSkip
+	}
+	nv.Value = int64(0)
+	if b {
+		nv.Value = int64(1)
+	}
+	return nil
+}
+
+var (
+	ddlPattern           = regexp.MustCompile(`(?is)^\s*(CREATE|ALTER)\s+TABLE\b`)
+	autoincrementPattern = regexp.MustCompile(`(?i)\bINTEGER\s+PRIMARY\s+KEY\s+AUTOINCREMENT\b`)
+	integerPattern       = regexp.MustCompile(`(?i)\bINTEGER\b`)
+	datetimePattern      = regexp.MustCompile(`(?i)\bDATETIME\b`)
+)
+
+// rewritePostgres rewrites a query written for SQLite for PostgreSQL:
+//   - ? placeholders become $1, $2, ...
+//   - in CREATE TABLE and ALTER TABLE, SQLite's column types become PostgreSQL's
+//
+// Quoted strings and identifiers are left as they are. SQL differing in meaning, such as
+// upserts, is not rewritten; queries take it from the database's dialect.
+func rewritePostgres(query string) string {
+	ddl := ddlPattern.MatchString(query)
+
+	var out strings.Builder
+	placeholder := 0
+	rewriteCode := func(code string) {
+		if ddl {
+			code = autoincrementPattern.ReplaceAllString(code, "BIGSERIAL PRIMARY KEY")
+			code = integerPattern.ReplaceAllString(code, "BIGINT")
+			code = datetimePattern.ReplaceAllString(code, "TIMESTAMPTZ")
+		}
+		for _, r := range code {
+			if r == '?' {
+				placeholder++
+				fmt.Fprintf(&out, "$%d", placeholder)
+				continue
+			}
+			out.WriteRune(r)
+		}
+	}
+
+	// Split the query into code and quoted text; a doubled quote ends and reopens the quote
+	start := 0
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		switch {
+		case quote == 0 && (query[i] == '\'' || query[i] == '"'):
+			rewriteCode(query[start:i])
+			quote, start = query[i], i
+		case quote != 0 && query[i] == quote:
+			out.WriteString(query[start : i+1])
+			quote, start = 0, i+1
+		}
+	}
+	if quote != 0 {
+		out.WriteString(query[start:])
+	} else {
+		rewriteCode(query[start:])
+	}
+	return out.String()
+}
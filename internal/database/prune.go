@@ -0,0 +1,74 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// DefaultCacheRetentionDays is how long cached news is kept when no retention is configured.
+const DefaultCacheRetentionDays = 30
+
+// PruneOptions selects the cached news PruneNewsCache removes.
+type PruneOptions struct {
+	RetentionDays int  // RetentionDays removes news fetched more than this many days ago; 0 keeps all news.
+	PrunePosted   bool // PrunePosted also removes news that has been posted to a channel.
+	DryRun        bool // DryRun only counts the news that would be removed.
+}
+
+// PruneResult counts the cached news affected by PruneNewsCache.
+type PruneResult struct {
+	Removed    int64 // Removed is the number of news removed, or that would be removed in a dry run.
+	KeptPosted int64 // KeptPosted is the number of expired news kept because they have been posted.
+}
+
+// PruneNewsCache removes news fetched more than opts.RetentionDays days ago from the cache.
+// News that has been posted to a channel is kept for the digest and stats features unless
+// opts.PrunePosted is set; the posted_news rows themselves are never removed, so pruned news
+// is not posted again. Expired localized variants (see CacheLocalizedNews) are always removed.
+func PruneNewsCache(b *types.Bot, opts PruneOptions) (PruneResult, error) {
+	var result PruneResult
+	if opts.RetentionDays < 0 {
+		return result, fmt.Errorf("retention days must not be negative")
+	}
+	if opts.RetentionDays == 0 {
+		return result, nil
+	}
+	cutoff := now().UTC().AddDate(0, 0, -opts.RetentionDays).Format("2006-01-02 15:04:05")
+
+	var expired, posted int64
+	err := b.DB.QueryRow(`SELECT COUNT(*), COALESCE(SUM(EXISTS (SELECT 1 FROM posted_news p WHERE p.news_id = n.id)), 0)
+						  FROM news_cache n WHERE n.fetched_at < ?`, cutoff).Scan(&expired, &posted)
+	if err != nil {
+		return result, fmt.Errorf("failed to count expired cache entries: %v", err)
+	}
+	if opts.PrunePosted {
+		result.Removed = expired
+	} else {
+		result.Removed = expired - posted
+		result.KeptPosted = posted
+	}
+
+	// Localized variants are only cached for posting, so they expire whether posted or not
+	if !opts.DryRun {
+		if _, err := b.DB.Exec(`DELETE FROM localized_news WHERE fetched_at < ?`, cutoff); err != nil {
+			return result, fmt.Errorf("failed to prune localized news: %v", err)
+		}
+	}
+	if opts.DryRun || result.Removed == 0 {
+		return result, nil
+	}
+
+	res, err := b.DB.Exec(`DELETE FROM news_cache
+						   WHERE fetched_at < ?
+						   AND (? OR NOT EXISTS (SELECT 1 FROM posted_news p WHERE p.news_id = news_cache.id))`,
+		cutoff, opts.PrunePosted)
+	if err != nil {
+		return result, fmt.Errorf("failed to prune cache: %v", err)
+	}
+	result.Removed, err = res.RowsAffected()
+	if err != nil {
+		return result, fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	return result, nil
+}
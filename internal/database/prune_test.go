@@ -0,0 +1,129 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// setupPruneTest caches news 1 to 4, of which 1 to 3 were fetched 40 days ago and 1 has been posted.
+func setupPruneTest(t *testing.T) *types.Bot {
+	t.Helper()
+	db, err := InitDatabase(testDatabasePath(t, "prune.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	bot := &types.Bot{DB: db, Config: &types.Config{}}
+
+	// Registered first, as registering marks the cached news as posted
+	if err := AddChannel(bot, "channel-a"); err != nil {
+		t.Fatalf("Failed to add channel: %v", err)
+	}
+
+	var newsItems []types.NewsItem
+	for id := int64(1); id <= 4; id++ {
+		newsItems = append(newsItems, types.NewsItem{ID: id, Title: "News", Updated: time.Now()})
+	}
+	if err := CacheNews(bot, newsItems); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+	fetchedAt := time.Now().UTC().AddDate(0, 0, -40).Format("2006-01-02 15:04:05")
+	if _, err := db.Exec(`UPDATE news_cache SET fetched_at = ? WHERE id IN (1, 2, 3)`, fetchedAt); err != nil {
+		t.Fatalf("Failed to age news: %v", err)
+	}
+
+	if err := MarkNewsAsPosted(bot, 1, "channel-a"); err != nil {
+		t.Fatalf("Failed to mark news as posted: %v", err)
+	}
+	return bot
+}
+
+// cachedNewsIDs returns the IDs of the cached news in order.
+func cachedNewsIDs(t *testing.T, bot *types.Bot) []int64 {
+	t.Helper()
+	rows, err := bot.DB.Query(`SELECT id FROM news_cache ORDER BY id`)
+	if err != nil {
+		t.Fatalf("Failed to query cached news: %v", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			t.Fatalf("Failed to scan cached news: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestCleanOldCacheKeepsPostedNews(t *testing.T) {
+	bot := setupPruneTest(t)
+	bot.Config.CacheRetentionDays = DefaultCacheRetentionDays
+
+	if err := CleanOldCache(bot); err != nil {
+		t.Fatalf("Failed to clean old cache: %v", err)
+	}
+	if ids := cachedNewsIDs(t, bot); !reflect.DeepEqual(ids, []int64{1, 4}) {
+		t.Errorf("Expected cached news [1 4], got %v", ids)
+	}
+
+	// Without a retention nothing is removed
+	bot = setupPruneTest(t)
+	if err := CleanOldCache(bot); err != nil {
+		t.Fatalf("Failed to clean old cache: %v", err)
+	}
+	if ids := cachedNewsIDs(t, bot); !reflect.DeepEqual(ids, []int64{1, 2, 3, 4}) {
+		t.Errorf("Expected all news to be kept, got %v", ids)
+	}
+}
+
+func TestPruneNewsCache(t *testing.T) {
+	tests := []struct {
+		name           string
+		opts           PruneOptions
+		expectedResult PruneResult
+		expectedIDs    []int64
+	}{
+		{"default", PruneOptions{RetentionDays: 30}, PruneResult{Removed: 2, KeptPosted: 1}, []int64{1, 4}},
+		{"prune posted", PruneOptions{RetentionDays: 30, PrunePosted: true}, PruneResult{Removed: 3}, []int64{4}},
+		{"dry run", PruneOptions{RetentionDays: 30, DryRun: true}, PruneResult{Removed: 2, KeptPosted: 1}, []int64{1, 2, 3, 4}},
+		{"dry run prune posted", PruneOptions{RetentionDays: 30, PrunePosted: true, DryRun: true}, PruneResult{Removed: 3}, []int64{1, 2, 3, 4}},
+		{"longer retention", PruneOptions{RetentionDays: 60, PrunePosted: true}, PruneResult{}, []int64{1, 2, 3, 4}},
+		{"keep forever", PruneOptions{PrunePosted: true}, PruneResult{}, []int64{1, 2, 3, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := setupPruneTest(t)
+
+			result, err := PruneNewsCache(bot, tt.opts)
+			if err != nil {
+				t.Fatalf("Failed to prune cache: %v", err)
+			}
+			if result != tt.expectedResult {
+				t.Errorf("Expected %+v, got %+v", tt.expectedResult, result)
+			}
+			if ids := cachedNewsIDs(t, bot); !reflect.DeepEqual(ids, tt.expectedIDs) {
+				t.Errorf("Expected cached news %v, got %v", tt.expectedIDs, ids)
+			}
+
+			// The posted record survives so the news is not posted again
+			posted, err := IsNewsPosted(bot, 1, "channel-a")
+			if err != nil {
+				t.Fatalf("Failed to check posted news: %v", err)
+			}
+			if !posted {
+				t.Error("Expected news 1 to stay marked as posted")
+			}
+		})
+	}
+
+	if _, err := PruneNewsCache(setupPruneTest(t), PruneOptions{RetentionDays: -1}); err == nil {
+		t.Error("Expected an error for a negative retention")
+	}
+}
@@ -0,0 +1,82 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// Publish status values stored in posted_news.publish_status.
+// Rows for channels that are not auto-published keep NULL.
+const (
+	PublishStatusPublished = "published" // The message was crossposted to followers.
+	PublishStatusQueued    = "queued"    // Publishing was deferred (rate limit or transient error) and will be retried.
+	PublishStatusFailed    = "failed"    // Publishing failed permanently.
+)
+
+// QueuedPublish is a posted message waiting to be crossposted.
+type QueuedPublish struct {
+	NewsID    int64
+	ChannelID string
+	MessageID string
+}
+
+// SetPublishStatus records the Discord message ID and publish status of a posted news item.
+func SetPublishStatus(b *types.Bot, newsID int64, channelID, messageID, status string) error {
+	query := `UPDATE posted_news SET message_id = ?, publish_status = ? 
+			  WHERE news_id = ? AND channel_id = ?`
+
+	if _, err := b.DB.Exec(query, messageID, status, newsID, channelID); err != nil {
+		return fmt.Errorf("failed to set publish status: %v", err)
+	}
+
+	return nil
+}
+
+// SetPostMessage records the ID of the Discord message a news item was posted to a channel as.
+func SetPostMessage(b *types.Bot, newsID int64, channelID, messageID string) error {
+	query := `UPDATE posted_news SET message_id = ? WHERE news_id = ? AND channel_id = ?`
+
+	if _, err := b.DB.Exec(query, messageID, newsID, channelID); err != nil {
+		return fmt.Errorf("failed to set post message: %v", err)
+	}
+
+	return nil
+}
+
+// SetPostThread records the ID of the discussion thread started on a posted news item.
+func SetPostThread(b *types.Bot, newsID int64, channelID, threadID string) error {
+	query := `UPDATE posted_news SET thread_id = ? WHERE news_id = ? AND channel_id = ?`
+
+	if _, err := b.DB.Exec(query, threadID, newsID, channelID); err != nil {
+		return fmt.Errorf("failed to set post thread: %v", err)
+	}
+
+	return nil
+}
+
+// GetQueuedPublishes returns queued publishes for channels that still have auto-publish enabled,
+// oldest first.
+func GetQueuedPublishes(b *types.Bot) ([]QueuedPublish, error) {
+	query := `SELECT pn.news_id, pn.channel_id, pn.message_id FROM posted_news pn
+			  JOIN channels c ON c.id = pn.channel_id
+			  WHERE pn.publish_status = ? AND pn.message_id IS NOT NULL AND c.auto_publish = 1
+			  ORDER BY pn.posted_at, pn.id`
+
+	rows, err := b.DB.Query(query, PublishStatusQueued)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query queued publishes: %v", err)
+	}
+	defer rows.Close()
+
+	var queued []QueuedPublish
+	for rows.Next() {
+		var q QueuedPublish
+		if err := rows.Scan(&q.NewsID, &q.ChannelID, &q.MessageID); err != nil {
+			return nil, fmt.Errorf("failed to scan queued publish: %v", err)
+		}
+		queued = append(queued, q)
+	}
+
+	return queued, nil
+}
@@ -0,0 +1,102 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// MergeNewsTags merges the tags the news API gives for a news item into its cached tags. Cached
+// tags are kept in their order, including ones added locally, and fetched tags they lack are
+// appended in the API's order. Tags are compared case-insensitively after trimming spaces;
+// duplicates and empty tags are dropped, and the first spelling of a tag is kept.
+//
+// Example:
+//
+//	tags := database.MergeNewsTags([]string{"events"}, []string{"star-trek-online", "Events"})
+//	// [events star-trek-online]
+func MergeNewsTags(cached, fetched []string) []string {
+	merged := []string{}
+	seen := make(map[string]bool)
+	for _, tags := range [][]string{cached, fetched} {
+		for _, tag := range tags {
+			tag = strings.TrimSpace(tag)
+			key := strings.ToLower(tag)
+			if tag == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, tag)
+		}
+	}
+	return merged
+}
+
+// UpdateNewsTags replaces the tags of a cached news item, normalized as CacheNews normalizes them.
+func UpdateNewsTags(b *types.Bot, newsID int64, tags []string) error {
+	result, err := b.DB.Exec(`UPDATE news_cache SET tags = ? WHERE id = ?`, strings.Join(b.Config.NormalizeTags(tags), ","), newsID)
+	if err != nil {
+		return fmt.Errorf("failed to update tags of news %d: %v", newsID, err)
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if updated == 0 {
+		return fmt.Errorf("news %d is not cached", newsID)
+	}
+	return nil
+}
+
+// normalizeCachedTags normalizes the tags of cached news with the default aliases, for news cached
+// before tags were normalized. Only rows whose tags change are written, so it is cheap to run
+// on every start.
+func normalizeCachedTags(db *sql.DB) error {
+	rows, err := db.Query(`SELECT id, tags FROM news_cache WHERE tags IS NOT NULL AND tags != ''`)
+	if err != nil {
+		return fmt.Errorf("failed to query cached tags: %v", err)
+	}
+	normalized := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var tags string
+		if err := rows.Scan(&id, &tags); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan cached tags: %v", err)
+		}
+		if tagList := strings.Join(types.NormalizeTags(strings.Split(tags, ","), nil), ","); tagList != tags {
+			normalized[id] = tagList
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return fmt.Errorf("failed to read cached tags: %v", err)
+	}
+	rows.Close()
+
+	if len(normalized) == 0 {
+		return nil
+	}
+
+	logger().Infof("Normalizing the tags of %d cached news items", len(normalized))
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			logger().Printf("Warning: failed to rollback transaction: %v", rollbackErr)
+		}
+	}()
+
+	for id, tags := range normalized {
+		if _, err := tx.Exec(`UPDATE news_cache SET tags = ? WHERE id = ?`, tags, id); err != nil {
+			return fmt.Errorf("failed to normalize tags of news %d: %v", id, err)
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,146 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+func TestMergeNewsTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		cached   []string
+		fetched  []string
+		expected []string
+	}{
+		{"missing tags", nil, []string{"star-trek-online", "patch-notes"}, []string{"star-trek-online", "patch-notes"}},
+		{"partial tags", []string{"patch-notes"}, []string{"star-trek-online", "patch-notes"}, []string{"patch-notes", "star-trek-online"}},
+		{"local tags kept", []string{"featured", "events"}, []string{"events", "star-trek-online"}, []string{"featured", "events", "star-trek-online"}},
+		{"case-insensitive duplicates", []string{"Events"}, []string{"events", "EVENTS"}, []string{"Events"}},
+		{"cached duplicates and spaces", []string{" events", "events", "", "dev-blogs "}, nil, []string{"events", "dev-blogs"}},
+		{"nothing", nil, nil, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if merged := MergeNewsTags(tt.cached, tt.fetched); !reflect.DeepEqual(merged, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, merged)
+			}
+		})
+	}
+}
+
+func TestUpdateNewsTags(t *testing.T) {
+	db, err := InitDatabase(testDatabasePath(t, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+	bot := &types.Bot{DB: db}
+
+	if err := CacheNews(bot, []types.NewsItem{{ID: 1, Title: "Season Update", Updated: time.Now()}}); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+	if err := UpdateNewsTags(bot, 1, []string{"star-trek-online", "events"}); err != nil {
+		t.Fatalf("Failed to update tags: %v", err)
+	}
+
+	newsItem, err := GetCachedNewsByID(bot, 1)
+	if err != nil {
+		t.Fatalf("Failed to get cached news: %v", err)
+	}
+	if !reflect.DeepEqual(newsItem.Tags, []string{"star-trek-online", "events"}) {
+		t.Errorf("Expected updated tags, got %v", newsItem.Tags)
+	}
+
+	if err := UpdateNewsTags(bot, 2, []string{"events"}); err == nil {
+		t.Error("Expected an error for news that is not cached")
+	}
+}
+
+func TestNormalizeCachedTags(t *testing.T) {
+	dbPath := testDatabasePath(t, "test.db")
+	db, err := InitDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	// Rows cached before tags were normalized, next to normalized ones
+	rows := map[int64]string{
+		1: "Dev Blog,star-trek-online",
+		2: "patch-notes,star-trek-online",
+		3: "Patch Notes,patch-notes,Event",
+		4: "",
+	}
+	for id, tags := range rows {
+		if _, err := db.Exec(`INSERT INTO news_cache (id, title, tags) VALUES (?, ?, ?)`, id, "News", tags); err != nil {
+			t.Fatalf("Failed to insert news: %v", err)
+		}
+	}
+	db.Close()
+
+	expected := map[int64]string{
+		1: "dev-blogs,star-trek-online",
+		2: "patch-notes,star-trek-online",
+		3: "patch-notes,events",
+		4: "",
+	}
+	// Opening the database normalizes the rows; opening it again changes nothing
+	for run := 1; run <= 2; run++ {
+		db, err := InitDatabase(dbPath)
+		if err != nil {
+			t.Fatalf("Failed to reopen database: %v", err)
+		}
+		for id, tags := range expected {
+			var stored string
+			if err := db.QueryRow(`SELECT tags FROM news_cache WHERE id = ?`, id).Scan(&stored); err != nil {
+				t.Fatalf("Failed to read tags: %v", err)
+			}
+			if stored != tags {
+				t.Errorf("Run %d: expected news %d tagged %q, got %q", run, id, tags, stored)
+			}
+		}
+		db.Close()
+	}
+}
+
+func TestCacheNewsNormalizesTags(t *testing.T) {
+	db, err := InitDatabase(testDatabasePath(t, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+	aliases, err := types.ParseTagAliases("maintenance=server-maintenance")
+	if err != nil {
+		t.Fatalf("Failed to parse tag aliases: %v", err)
+	}
+	bot := &types.Bot{DB: db, Config: &types.Config{TagAliases: aliases}}
+
+	if err := CacheNews(bot, []types.NewsItem{
+		{ID: 1, Title: "Dev Blog #200", Tags: []string{"Dev Blog", "STO"}, Updated: time.Now()},
+		{ID: 2, Title: "Server Maintenance", Tags: []string{"Maintenance"}, Updated: time.Now()},
+	}); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+	cached, err := GetCachedNewsByID(bot, 1)
+	if err != nil || cached == nil {
+		t.Fatalf("Failed to get cached news: %v", err)
+	}
+	if !reflect.DeepEqual(cached.Tags, []string{"dev-blogs", "star-trek-online"}) {
+		t.Errorf("Expected normalized tags, got %v", cached.Tags)
+	}
+
+	// Searches normalize the tags they look for alike
+	results, err := SearchNewsByTags(bot, []string{"devblog"}, 10)
+	if err != nil || len(results) != 1 || results[0].ID != 1 {
+		t.Errorf("Expected news 1 found by an alias, got %v (%v)", newsIDs(results), err)
+	}
+	results, err = SearchNewsByTags(bot, []string{"Maintenance"}, 10)
+	if err != nil || len(results) != 1 || results[0].ID != 2 {
+		t.Errorf("Expected news 2 found by a configured alias, got %v (%v)", newsIDs(results), err)
+	}
+	if query := ParseSearchQuery("season tag:Dev_Blog tag:event"); !reflect.DeepEqual(query.Tags, []string{"dev-blogs", "events"}) {
+		t.Errorf("Expected normalized tag filters, got %v", query.Tags)
+	}
+}
@@ -18,6 +18,7 @@ type SearchQuery struct {
 	MustNot   []string // Excluded terms (NOT)
 	Tags      []string
 	Platforms []string
+	Authors   []string // Authors the article's byline must contain
 	DateFrom  *time.Time
 	DateTo    *time.Time
 	SortBy    string // "relevance", "date", "title"
@@ -38,7 +39,14 @@ func ParseSearchQuery(query string) *SearchQuery {
 		SortOrder: "desc",
 	}
 
-	// Extract quoted phrases first
+	// Extract quoted authors first, so their names are not searched as phrases: author:"Jane Doe"
+	authorRegex := regexp.MustCompile(`(?i)author:"([^"]+)"`)
+	for _, author := range authorRegex.FindAllStringSubmatch(query, -1) {
+		sq.Authors = append(sq.Authors, strings.ToLower(strings.TrimSpace(author[1])))
+	}
+	query = authorRegex.ReplaceAllString(query, "")
+
+	// Extract quoted phrases
 	phraseRegex := regexp.MustCompile(`"([^"]+)"`)
 	phrases := phraseRegex.FindAllStringSubmatch(query, -1)
 	for _, phrase := range phrases {
@@ -60,11 +68,18 @@ func ParseSearchQuery(query string) *SearchQuery {
 			// Excluded term: -word
 			sq.MustNot = append(sq.MustNot, strings.TrimPrefix(token, "-"))
 		case strings.HasPrefix(token, "tag:"):
-			// Tag filter: tag:events
-			sq.Tags = append(sq.Tags, strings.TrimPrefix(token, "tag:"))
+			// Tag filter: tag:events, normalized as cached tags are (tag:event finds events)
+			if tag := types.NormalizeTag(strings.TrimPrefix(token, "tag:"), nil); tag != "" {
+				sq.Tags = append(sq.Tags, tag)
+			}
 		case strings.HasPrefix(token, "platform:"):
 			// Platform filter: platform:pc
 			sq.Platforms = append(sq.Platforms, strings.TrimPrefix(token, "platform:"))
+		case strings.HasPrefix(token, "author:"):
+			// Author filter: author:ambassador
+			if author := strings.TrimPrefix(token, "author:"); author != "" {
+				sq.Authors = append(sq.Authors, author)
+			}
 		case strings.HasPrefix(token, "after:"):
 			// Date filter: after:2023-01-01
 			if date, err := time.Parse("2006-01-02", strings.TrimPrefix(token, "after:")); err == nil {
@@ -92,7 +107,11 @@ func ParseSearchQuery(query string) *SearchQuery {
 	return sq
 }
 
-// AdvancedSearchNews performs advanced search with complex query parsing
+// ftsCandidateLimit caps the full-text matches AdvancedSearchNews ranks, best matches first.
+const ftsCandidateLimit = 200
+
+// AdvancedSearchNews performs advanced search with complex query parsing. With the full-text
+// index, matches are found and ranked by SQLite (bm25); otherwise every cached article is scored.
 func AdvancedSearchNews(b *types.Bot, queryString string, limit int) ([]SearchResult, error) {
 	if limit <= 0 {
 		limit = 10
@@ -101,47 +120,128 @@ func AdvancedSearchNews(b *types.Bot, queryString string, limit int) ([]SearchRe
 		limit = 50
 	}
 
-	// Parse the query
+	// Parse the query; tags are normalized again in case of configured aliases
 	searchQuery := ParseSearchQuery(queryString)
+	searchQuery.Tags = b.Config.NormalizeTags(searchQuery.Tags)
+
+	var results []SearchResult
+	var err error
+	if expression := ftsMatchExpression(searchQuery); expression != "" && newsFTSAvailable(b.DB) {
+		results, err = rankedSearch(b, searchQuery, expression)
+	} else {
+		results, err = scoredSearch(b, searchQuery)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Sort results
+	sortResults(results, searchQuery.SortBy, searchQuery.SortOrder)
+
+	// Limit results
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, nil
+}
 
-	// Build base SQL query
+// searchFilterConditions returns the SQL conditions and arguments for the filters of a search.
+// prefix qualifies the news_cache columns, e.g. "nc.".
+func searchFilterConditions(d dialect, searchQuery *SearchQuery, prefix string) ([]string, []interface{}) {
 	var conditions []string
 	var args []interface{}
 
 	// Base condition - content must exist
-	conditions = append(conditions, "content IS NOT NULL AND content != ''")
+	conditions = append(conditions, fmt.Sprintf("%[1]scontent IS NOT NULL AND %[1]scontent != ''", prefix))
 
 	// Add date filters
 	if searchQuery.DateFrom != nil {
-		conditions = append(conditions, "updated_at >= ?")
+		conditions = append(conditions, prefix+"updated_at >= ?")
 		args = append(args, searchQuery.DateFrom.Format("2006-01-02 15:04:05"))
 	}
 	if searchQuery.DateTo != nil {
-		conditions = append(conditions, "updated_at <= ?")
+		conditions = append(conditions, prefix+"updated_at <= ?")
 		args = append(args, searchQuery.DateTo.Format("2006-01-02 15:04:05"))
 	}
 
 	// Add tag filters
 	for _, tag := range searchQuery.Tags {
-		conditions = append(conditions, "tags LIKE ?")
+		conditions = append(conditions, d.match(prefix+"tags"))
 		args = append(args, "%"+tag+"%")
 	}
 
 	// Add platform filters
 	for _, platform := range searchQuery.Platforms {
-		conditions = append(conditions, "platforms LIKE ?")
+		conditions = append(conditions, d.match(prefix+"platforms"))
 		args = append(args, "%"+platform+"%")
 	}
 
-	// Build the main query
-	whereClause := ""
-	if len(conditions) > 0 {
-		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	// Add author filters
+	for _, author := range searchQuery.Authors {
+		conditions = append(conditions, d.match(prefix+"author"))
+		args = append(args, "%"+author+"%")
 	}
 
-	query := fmt.Sprintf(`SELECT id, title, summary, content, tags, platforms, updated_at, thumbnail_url 
-			  FROM news_cache %s
-			  ORDER BY updated_at DESC`, whereClause)
+	return conditions, args
+}
+
+// rankedSearch finds the articles matching a full-text expression, scored by bm25 with title
+// matches weighing most.
+func rankedSearch(b *types.Bot, searchQuery *SearchQuery, expression string) ([]SearchResult, error) {
+	conditions, args := searchFilterConditions(dialectOf(b.DB), searchQuery, "nc.")
+	conditions = append([]string{"news_fts MATCH ?"}, conditions...)
+	args = append([]interface{}{expression}, args...)
+	args = append(args, ftsCandidateLimit)
+
+	query := fmt.Sprintf(`SELECT nc.id, nc.title, nc.summary, nc.content, nc.tags, nc.platforms, nc.updated_at, nc.thumbnail_url, nc.url, nc.platform_dates, nc.author, 
+			  bm25(news_fts, 5.0, 3.0, 1.0) AS rank
+			  FROM news_fts
+			  JOIN news_cache nc ON nc.id = news_fts.rowid
+			  WHERE %s
+			  ORDER BY rank
+			  LIMIT ?`, strings.Join(conditions, " AND "))
+
+	rows, err := b.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute search query: %v", err)
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var rank float64
+		item, err := scanNewsItem(rows, &rank)
+		if err != nil {
+			return nil, err
+		}
+
+		// bm25 is lower for better matches
+		_, matches := scoreNewsItem(item, searchQuery)
+		if len(matches) == 0 {
+			matches = []string{"full-text match"}
+		}
+		results = append(results, SearchResult{
+			NewsItem: item,
+			Score:    -rank * recencyBoost(item.Updated),
+			Matches:  matches,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %v", err)
+	}
+
+	return results, nil
+}
+
+// scoredSearch scores every cached article matching the filters of a search. It is used when
+// SQLite was built without FTS5.
+func scoredSearch(b *types.Bot, searchQuery *SearchQuery) ([]SearchResult, error) {
+	conditions, args := searchFilterConditions(dialectOf(b.DB), searchQuery, "")
+
+	query := fmt.Sprintf(`SELECT id, title, summary, content, tags, platforms, updated_at, thumbnail_url, url, platform_dates, author 
+			  FROM news_cache WHERE %s
+			  ORDER BY updated_at DESC`, strings.Join(conditions, " AND "))
 
 	rows, err := b.DB.Query(query, args...)
 	if err != nil {
@@ -167,14 +267,6 @@ func AdvancedSearchNews(b *types.Bot, queryString string, limit int) ([]SearchRe
 		}
 	}
 
-	// Sort results
-	sortResults(results, searchQuery.SortBy, searchQuery.SortOrder)
-
-	// Limit results
-	if len(results) > limit {
-		results = results[:limit]
-	}
-
 	return results, nil
 }
 
@@ -234,17 +326,37 @@ func scoreNewsItem(item types.NewsItem, query *SearchQuery) (float64, []string)
 		}
 	}
 
-	// Boost score for recent articles
-	now := time.Now()
-	if item.Updated.After(now.AddDate(0, 0, -7)) {
-		score *= 1.2 // 20% boost for articles from last week
-	} else if item.Updated.After(now.AddDate(0, -1, 0)) {
-		score *= 1.1 // 10% boost for articles from last month
+	// Authors are matched by the filters; they score only searches without terms, which would
+	// otherwise find nothing
+	author := strings.ToLower(item.Author)
+	for _, name := range query.Authors {
+		if !strings.Contains(author, name) {
+			continue
+		}
+		if len(query.Terms) == 0 && len(query.Phrases) == 0 && len(query.MustHave) == 0 {
+			score += 5.0
+		}
+		matches = append(matches, "author: "+name)
 	}
 
+	// Boost score for recent articles
+	score *= recencyBoost(item.Updated)
+
 	return score, matches
 }
 
+// recencyBoost returns the factor the relevance score of an article updated at updated is
+// boosted by.
+func recencyBoost(updated time.Time) float64 {
+	now := time.Now()
+	if updated.After(now.AddDate(0, 0, -7)) {
+		return 1.2 // 20% boost for articles from last week
+	} else if updated.After(now.AddDate(0, -1, 0)) {
+		return 1.1 // 10% boost for articles from last month
+	}
+	return 1.0
+}
+
 // sortResults sorts search results based on criteria
 func sortResults(results []SearchResult, sortBy, sortOrder string) {
 	sort.Slice(results, func(i, j int) bool {
@@ -275,7 +387,7 @@ func FuzzySearchNews(b *types.Bot, searchTerm string, limit int) ([]SearchResult
 	}
 
 	// Get all news items
-	query := `SELECT id, title, summary, content, tags, platforms, updated_at, thumbnail_url 
+	query := `SELECT id, title, summary, content, tags, platforms, updated_at, thumbnail_url, url, platform_dates, author 
 			  FROM news_cache 
 			  WHERE content IS NOT NULL AND content != ''
 			  ORDER BY updated_at DESC
@@ -363,6 +475,7 @@ func calculateFuzzyScore(item types.NewsItem, searchTerm string) float64 {
 
 // SearchWithFilters provides a simplified interface for filtered search
 func SearchWithFilters(b *types.Bot, options SearchOptions) ([]SearchResult, error) {
+	d := dialectOf(b.DB)
 	var conditions []string
 	var args []interface{}
 
@@ -371,7 +484,7 @@ func SearchWithFilters(b *types.Bot, options SearchOptions) ([]SearchResult, err
 
 	// Text search
 	if options.Query != "" {
-		textCondition := "(title LIKE ? OR summary LIKE ? OR content LIKE ?)"
+		textCondition := "(" + d.match("title") + " OR " + d.match("summary") + " OR " + d.match("content") + ")"
 		conditions = append(conditions, textCondition)
 		pattern := "%" + options.Query + "%"
 		args = append(args, pattern, pattern, pattern)
@@ -380,8 +493,8 @@ func SearchWithFilters(b *types.Bot, options SearchOptions) ([]SearchResult, err
 	// Tag filter
 	if len(options.Tags) > 0 {
 		var tagConditions []string
-		for _, tag := range options.Tags {
-			tagConditions = append(tagConditions, "tags LIKE ?")
+		for _, tag := range b.Config.NormalizeTags(options.Tags) {
+			tagConditions = append(tagConditions, d.match("tags"))
 			args = append(args, "%"+tag+"%")
 		}
 		conditions = append(conditions, "("+strings.Join(tagConditions, " OR ")+")")
@@ -391,7 +504,7 @@ func SearchWithFilters(b *types.Bot, options SearchOptions) ([]SearchResult, err
 	if len(options.Platforms) > 0 {
 		var platformConditions []string
 		for _, platform := range options.Platforms {
-			platformConditions = append(platformConditions, "platforms LIKE ?")
+			platformConditions = append(platformConditions, d.match("platforms"))
 			args = append(args, "%"+platform+"%")
 		}
 		conditions = append(conditions, "("+strings.Join(platformConditions, " OR ")+")")
@@ -417,7 +530,7 @@ func SearchWithFilters(b *types.Bot, options SearchOptions) ([]SearchResult, err
 		orderClause = strings.Replace(orderClause, "DESC", "ASC", 1)
 	}
 
-	query := fmt.Sprintf(`SELECT id, title, summary, content, tags, platforms, updated_at, thumbnail_url 
+	query := fmt.Sprintf(`SELECT id, title, summary, content, tags, platforms, updated_at, thumbnail_url, url, platform_dates, author 
 			  FROM news_cache %s %s LIMIT ?`, whereClause, orderClause)
 
 	limit := options.Limit
@@ -458,6 +571,7 @@ type SearchOptions struct {
 	Query     string
 	Tags      []string
 	Platforms []string
+	Authors   []string // Authors the article's byline must contain
 	DateFrom  *time.Time
 	DateTo    *time.Time
 	SortBy    string // "date", "title", "relevance"
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// DatabaseStats summarizes the contents of the news database.
+type DatabaseStats struct {
+	TotalNews     int // TotalNews is the number of cached news items.
+	TotalChannels int // TotalChannels is the number of registered channels.
+	TotalPosted   int // TotalPosted is the number of news posts to channels.
+
+	// OldestArticle and NewestArticle are the earliest and latest update times of cached news;
+	// both are zero when no cached news has one.
+	OldestArticle time.Time
+	NewestArticle time.Time
+
+	CountsByTag      map[string]int // CountsByTag is the number of cached news items with each tag.
+	CountsByPlatform map[string]int // CountsByPlatform is the number of cached news items for each platform.
+}
+
+// GetDatabaseStats returns statistics about the news database.
+func GetDatabaseStats(b *types.Bot) (DatabaseStats, error) {
+	stats := DatabaseStats{
+		CountsByTag:      make(map[string]int),
+		CountsByPlatform: make(map[string]int),
+	}
+
+	err := b.DB.QueryRow(`SELECT (SELECT COUNT(*) FROM news_cache), (SELECT COUNT(*) FROM channels), 
+			  (SELECT COUNT(*) FROM posted_news)`).Scan(&stats.TotalNews, &stats.TotalChannels, &stats.TotalPosted)
+	if err != nil {
+		return DatabaseStats{}, fmt.Errorf("failed to get totals: %v", err)
+	}
+
+	// MIN and MAX return the stored text, so the dates are parsed here
+	var oldest, newest sql.NullString
+	err = b.DB.QueryRow("SELECT MIN(updated_at), MAX(updated_at) FROM news_cache").Scan(&oldest, &newest)
+	if err != nil {
+		return DatabaseStats{}, fmt.Errorf("failed to get date range: %v", err)
+	}
+	if oldest.Valid && newest.Valid {
+		if stats.OldestArticle, err = parseTimestamp(oldest.String); err != nil {
+			return DatabaseStats{}, err
+		}
+		if stats.NewestArticle, err = parseTimestamp(newest.String); err != nil {
+			return DatabaseStats{}, err
+		}
+	}
+
+	rows, err := b.DB.Query("SELECT COALESCE(tags, ''), COALESCE(platforms, '') FROM news_cache")
+	if err != nil {
+		return DatabaseStats{}, fmt.Errorf("failed to query tags and platforms: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tags, platforms string
+		if err := rows.Scan(&tags, &platforms); err != nil {
+			return DatabaseStats{}, fmt.Errorf("failed to scan tags and platforms: %v", err)
+		}
+		countListItems(stats.CountsByTag, tags)
+		countListItems(stats.CountsByPlatform, platforms)
+	}
+	if err := rows.Err(); err != nil {
+		return DatabaseStats{}, fmt.Errorf("failed to read tags and platforms: %v", err)
+	}
+
+	return stats, nil
+}
+
+// countListItems adds one to counts for every distinct item of a comma-separated list.
+func countListItems(counts map[string]int, list string) {
+	// Lists are short, so a slice finds repeats faster than a map
+	items := strings.Split(list, ",")
+	for i, item := range items {
+		item = strings.TrimSpace(item)
+		items[i] = item
+		if item != "" && !slices.Contains(items[:i], item) {
+			counts[item]++
+		}
+	}
+}
+
+// parseTimestamp parses a timestamp as stored by the SQLite driver.
+func parseTimestamp(value string) (time.Time, error) {
+	value = strings.TrimSuffix(value, "Z")
+	for _, layout := range sqlite3.SQLiteTimestampFormats {
+		if t, err := time.ParseInLocation(layout, value, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("failed to parse timestamp %q", value)
+}
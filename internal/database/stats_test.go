@@ -0,0 +1,410 @@
+package database
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+func TestGetDatabaseStatsEmpty(t *testing.T) {
+	bot := setupSubscriptionTest(t)
+
+	stats, err := GetDatabaseStats(bot)
+	if err != nil {
+		t.Fatalf("Failed to get database stats: %v", err)
+	}
+	if stats.TotalNews != 0 || stats.TotalChannels != 0 || stats.TotalPosted != 0 {
+		t.Errorf("Expected empty totals, got %+v", stats)
+	}
+	if !stats.OldestArticle.IsZero() || !stats.NewestArticle.IsZero() {
+		t.Errorf("Expected no date range, got %v to %v", stats.OldestArticle, stats.NewestArticle)
+	}
+	if len(stats.CountsByTag) != 0 || len(stats.CountsByPlatform) != 0 {
+		t.Errorf("Expected empty breakdowns, got %v and %v", stats.CountsByTag, stats.CountsByPlatform)
+	}
+}
+
+func TestGetDatabaseStatsNullDates(t *testing.T) {
+	bot := setupSubscriptionTest(t)
+	if _, err := bot.DB.Exec(`INSERT INTO news_cache (id, title, tags, platforms) VALUES (1, 'Undated', NULL, NULL)`); err != nil {
+		t.Fatalf("Failed to insert news: %v", err)
+	}
+
+	stats, err := GetDatabaseStats(bot)
+	if err != nil {
+		t.Fatalf("Failed to get database stats: %v", err)
+	}
+	if stats.TotalNews != 1 {
+		t.Errorf("Expected 1 news item, got %d", stats.TotalNews)
+	}
+	if !stats.OldestArticle.IsZero() || !stats.NewestArticle.IsZero() {
+		t.Errorf("Expected no date range for undated news, got %v to %v", stats.OldestArticle, stats.NewestArticle)
+	}
+	if len(stats.CountsByTag) != 0 || len(stats.CountsByPlatform) != 0 {
+		t.Errorf("Expected empty breakdowns, got %v and %v", stats.CountsByTag, stats.CountsByPlatform)
+	}
+}
+
+func TestGetDatabaseStatsBreakdowns(t *testing.T) {
+	bot := setupSubscriptionTest(t)
+	oldest := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	newest := time.Date(2024, 6, 11, 16, 0, 0, 0, time.UTC)
+	news := []types.NewsItem{
+		{ID: 1, Title: "Season Update", Tags: []string{"star-trek-online", "events"}, Platforms: []string{"pc", "xbox", "ps"}, Updated: oldest},
+		{ID: 2, Title: "Patch Notes", Tags: []string{"patch-notes"}, Platforms: []string{"pc"}, Updated: newest},
+		{ID: 3, Title: "Console Patch Notes", Tags: []string{"patch-notes"}, Platforms: []string{"xbox", "ps"}, Updated: oldest.AddDate(0, 0, 7)},
+	}
+	if err := CacheNews(bot, news); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+	// A new channel gets the cached news marked as posted
+	if err := AddChannel(bot, "channel-a"); err != nil {
+		t.Fatalf("Failed to add channel: %v", err)
+	}
+
+	stats, err := GetDatabaseStats(bot)
+	if err != nil {
+		t.Fatalf("Failed to get database stats: %v", err)
+	}
+	if stats.TotalNews != 3 || stats.TotalChannels != 1 || stats.TotalPosted != 3 {
+		t.Errorf("Expected 3 news items posted to 1 channel, got %+v", stats)
+	}
+	if !stats.OldestArticle.Equal(oldest) || !stats.NewestArticle.Equal(newest) {
+		t.Errorf("Expected dates %v to %v, got %v to %v", oldest, newest, stats.OldestArticle, stats.NewestArticle)
+	}
+
+	expectedTags := map[string]int{"star-trek-online": 1, "events": 1, "patch-notes": 2}
+	if !reflect.DeepEqual(stats.CountsByTag, expectedTags) {
+		t.Errorf("Expected tag counts %v, got %v", expectedTags, stats.CountsByTag)
+	}
+	expectedPlatforms := map[string]int{"pc": 2, "xbox": 2, "ps": 2}
+	if !reflect.DeepEqual(stats.CountsByPlatform, expectedPlatforms) {
+		t.Errorf("Expected platform counts %v, got %v", expectedPlatforms, stats.CountsByPlatform)
+	}
+}
+
+func TestGetPopularTagsOrder(t *testing.T) {
+	bot := setupSubscriptionTest(t)
+	updated := time.Now()
+	news := []types.NewsItem{
+		{ID: 1, Title: "Event", Tags: []string{"events", "star-trek-online"}, Updated: updated},
+		{ID: 2, Title: "Patch", Tags: []string{"patch-notes", "star-trek-online"}, Updated: updated},
+		{ID: 3, Title: "Blog", Tags: []string{"dev-blogs", "star-trek-online"}, Updated: updated},
+		{ID: 4, Title: "Another Event", Tags: []string{"events"}, Updated: updated},
+		{ID: 5, Title: "Another Patch", Tags: []string{"patch-notes", "patch-notes"}, Updated: updated},
+	}
+	if err := CacheNews(bot, news); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+
+	// Count descending, then tag ascending; a tag listed twice on one article counts once
+	tagCounts, err := GetPopularTags(bot, 10)
+	if err != nil {
+		t.Fatalf("Failed to get popular tags: %v", err)
+	}
+	expected := []TagCount{{"star-trek-online", 3}, {"events", 2}, {"patch-notes", 2}, {"dev-blogs", 1}}
+	if !reflect.DeepEqual(tagCounts, expected) {
+		t.Errorf("Expected %v, got %v", expected, tagCounts)
+	}
+
+	tagCounts, err = GetPopularTags(bot, 2)
+	if err != nil {
+		t.Fatalf("Failed to get popular tags: %v", err)
+	}
+	if !reflect.DeepEqual(tagCounts, expected[:2]) {
+		t.Errorf("Expected the top 2 %v, got %v", expected[:2], tagCounts)
+	}
+}
+
+func TestGetTrendingTags(t *testing.T) {
+	bot := setupSubscriptionTest(t)
+	news := []types.NewsItem{
+		{ID: 1, Title: "Old Patch", Tags: []string{"patch-notes"}, Updated: time.Now().AddDate(0, 0, -30)},
+		{ID: 2, Title: "Older Patch", Tags: []string{"patch-notes"}, Updated: time.Now().AddDate(0, 0, -40)},
+		{ID: 3, Title: "Event", Tags: []string{"events"}, Updated: time.Now().Add(-time.Hour)},
+		{ID: 4, Title: "Blog", Tags: []string{"dev-blogs", "events"}, Updated: time.Now().AddDate(0, 0, -2)},
+	}
+	if err := CacheNews(bot, news); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+
+	tagCounts, err := GetTrendingTags(bot, 7, 10)
+	if err != nil {
+		t.Fatalf("Failed to get trending tags: %v", err)
+	}
+	expected := []TagCount{{"events", 2}, {"dev-blogs", 1}}
+	if !reflect.DeepEqual(tagCounts, expected) {
+		t.Errorf("Expected %v for the last week, got %v", expected, tagCounts)
+	}
+
+	tagCounts, err = GetTrendingTags(bot, 90, 10)
+	if err != nil {
+		t.Fatalf("Failed to get trending tags: %v", err)
+	}
+	expected = []TagCount{{"events", 2}, {"patch-notes", 2}, {"dev-blogs", 1}}
+	if !reflect.DeepEqual(tagCounts, expected) {
+		t.Errorf("Expected %v for the last quarter, got %v", expected, tagCounts)
+	}
+}
+
+// seedTagDatabase returns a bot whose cache holds count news items over 5000 distinct tags.
+func seedTagDatabase(tb testing.TB, count int) *types.Bot {
+	tb.Helper()
+	db, err := InitDatabase(testDatabasePath(tb, "tags.db"))
+	if err != nil {
+		tb.Fatalf("Failed to initialize database: %v", err)
+	}
+	tb.Cleanup(func() { db.Close() })
+
+	tx, err := db.Begin()
+	if err != nil {
+		tb.Fatalf("Failed to begin transaction: %v", err)
+	}
+	for i := 0; i < count; i++ {
+		tags := fmt.Sprintf("star-trek-online,tag-%04d,tag-%04d", i%5000, (i*7)%5000)
+		if _, err := tx.Exec(`INSERT INTO news_cache (id, title, tags) VALUES (?, ?, ?)`, i+1, fmt.Sprintf("News %d", i+1), tags); err != nil {
+			tb.Fatalf("Failed to seed news: %v", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		tb.Fatalf("Failed to commit seed data: %v", err)
+	}
+	return &types.Bot{DB: db}
+}
+
+// BenchmarkPopularTagsBubbleSort measures the previous pattern of counting the tags of the whole
+// cache and ordering them with a bubble sort.
+func BenchmarkPopularTagsBubbleSort(b *testing.B) {
+	bot := seedTagDatabase(b, 10000)
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		rows, err := bot.DB.Query("SELECT tags FROM news_cache WHERE tags IS NOT NULL AND tags != ''")
+		if err != nil {
+			b.Fatalf("Failed to query tags: %v", err)
+		}
+		counts := make(map[string]int)
+		for rows.Next() {
+			var tags string
+			if err := rows.Scan(&tags); err != nil {
+				b.Fatalf("Failed to scan tags: %v", err)
+			}
+			for _, tag := range strings.Split(tags, ",") {
+				counts[strings.TrimSpace(tag)]++
+			}
+		}
+		rows.Close()
+
+		var tagCounts []TagCount
+		for tag, count := range counts {
+			tagCounts = append(tagCounts, TagCount{Tag: tag, Count: count})
+		}
+		for i := 0; i < len(tagCounts)-1; i++ {
+			for j := i + 1; j < len(tagCounts); j++ {
+				if tagCounts[j].Count > tagCounts[i].Count {
+					tagCounts[i], tagCounts[j] = tagCounts[j], tagCounts[i]
+				}
+			}
+		}
+	}
+}
+
+// BenchmarkGetPopularTags measures GetPopularTags over the same cache.
+func BenchmarkGetPopularTags(b *testing.B) {
+	bot := seedTagDatabase(b, 10000)
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, err := GetPopularTags(bot, 20); err != nil {
+			b.Fatalf("Failed to get popular tags: %v", err)
+		}
+	}
+}
+
+func TestGetPopularNewsThisWeek(t *testing.T) {
+	bot := seedPostedNewsDatabase(t, 0)
+	setClock(t, time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC))
+	for i := 0; i < 6; i++ {
+		if err := AddChannel(bot, fmt.Sprintf("channel-%d", i)); err != nil {
+			t.Fatalf("Failed to add channel: %v", err)
+		}
+	}
+
+	for id := 1; id <= 3; id++ {
+		if _, err := bot.DB.Exec(`INSERT INTO news_cache (id, title, summary, tags, platforms, updated_at) VALUES (?, ?, '', 'patch-notes', 'pc', ?)`,
+			id, fmt.Sprintf("News %d", id), fmt.Sprintf("2024-06-1%d 00:00:00", id)); err != nil {
+			t.Fatalf("Failed to cache news: %v", err)
+		}
+	}
+	posts := []struct {
+		newsID   int
+		postedAt string
+	}{
+		{1, "2024-06-14 10:00:00"},
+		{2, "2024-06-14 10:00:00"},
+		{2, "2024-06-13 10:00:00"},
+		{3, "2024-06-01 10:00:00"}, // Older than a week
+		{3, "2024-06-02 10:00:00"},
+		{3, "2024-06-03 10:00:00"},
+	}
+	for i, post := range posts {
+		if _, err := bot.DB.Exec(`INSERT INTO posted_news (news_id, channel_id, posted_at) VALUES (?, ?, ?)`,
+			post.newsID, fmt.Sprintf("channel-%d", i), post.postedAt); err != nil {
+			t.Fatalf("Failed to record post: %v", err)
+		}
+	}
+
+	items, err := GetPopularNewsThisWeek(bot, 10)
+	if err != nil {
+		t.Fatalf("Failed to get popular news: %v", err)
+	}
+	var ids []int64
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	if !reflect.DeepEqual(ids, []int64{2, 1}) {
+		t.Errorf("Expected news 2 then 1, got %v", ids)
+	}
+	if len(items) > 0 && (items[0].Title != "News 2" || !reflect.DeepEqual(items[0].Tags, []string{"patch-notes"})) {
+		t.Errorf("Expected the cached news, got %+v", items[0])
+	}
+}
+
+func TestPostedAtQueryPlans(t *testing.T) {
+	db, err := InitDatabase(filepath.Join(t.TempDir(), "plans.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	tests := []struct {
+		name  string
+		query string
+		args  []interface{}
+		index string
+	}{
+		{
+			name:  "popular news",
+			query: popularNewsQuery,
+			args:  []interface{}{"2024-06-08 00:00:00", 10},
+			index: "idx_posted_news_posted_at",
+		},
+		{
+			name:  "channel engagement",
+			query: `SELECT COUNT(*) FROM posted_news WHERE channel_id = ? AND posted_at >= ?`,
+			args:  []interface{}{"channel-1", "2024-06-08 00:00:00"},
+			index: "idx_posted_news_channel_posted_at",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := db.Query("EXPLAIN QUERY PLAN "+tt.query, tt.args...)
+			if err != nil {
+				t.Fatalf("Failed to explain query: %v", err)
+			}
+			defer rows.Close()
+
+			var plan []string
+			for rows.Next() {
+				var id, parent, notUsed int
+				var detail string
+				if err := rows.Scan(&id, &parent, &notUsed, &detail); err != nil {
+					t.Fatalf("Failed to scan query plan: %v", err)
+				}
+				plan = append(plan, detail)
+			}
+			if !strings.Contains(strings.Join(plan, "\n"), "USING COVERING INDEX "+tt.index) &&
+				!strings.Contains(strings.Join(plan, "\n"), "USING INDEX "+tt.index) {
+				t.Errorf("Expected the query to use %s, got plan:\n%s", tt.index, strings.Join(plan, "\n"))
+			}
+		})
+	}
+}
+
+// seedPostedNewsDatabase creates a database with 1000 cached news items and count posts of them,
+// each news item posted once to each of count/1000 channels, spread over the last year.
+func seedPostedNewsDatabase(tb testing.TB, count int) *types.Bot {
+	tb.Helper()
+	db, err := InitDatabase(testDatabasePath(tb, "posted.db"))
+	if err != nil {
+		tb.Fatalf("Failed to initialize database: %v", err)
+	}
+	tb.Cleanup(func() { db.Close() })
+	if count == 0 {
+		return &types.Bot{DB: db}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		tb.Fatalf("Failed to begin transaction: %v", err)
+	}
+	for i := 0; i < 1000; i++ {
+		if _, err := tx.Exec(`INSERT INTO news_cache (id, title, summary, tags, platforms, updated_at) VALUES (?, ?, '', 'star-trek-online', 'pc', CURRENT_TIMESTAMP)`, i+1, fmt.Sprintf("News %d", i+1)); err != nil {
+			tb.Fatalf("Failed to seed news: %v", err)
+		}
+	}
+	for i := 0; i < (count+999)/1000; i++ {
+		if _, err := tx.Exec(`INSERT INTO channels (id) VALUES (?)`, fmt.Sprintf("channel-%03d", i)); err != nil {
+			tb.Fatalf("Failed to seed channels: %v", err)
+		}
+	}
+	start, step := time.Now().UTC(), 365*24*time.Hour/time.Duration(count)
+	for i := 0; i < count; i++ {
+		postedAt := start.Add(-time.Duration(i) * step)
+		if _, err := tx.Exec(`INSERT INTO posted_news (news_id, channel_id, posted_at) VALUES (?, ?, ?)`,
+			i%1000+1, fmt.Sprintf("channel-%03d", i/1000), postedAt.Format("2006-01-02 15:04:05")); err != nil {
+			tb.Fatalf("Failed to seed posts: %v", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		tb.Fatalf("Failed to commit seed data: %v", err)
+	}
+	return &types.Bot{DB: db}
+}
+
+// BenchmarkPopularNewsWithoutIndex measures the popular news query of the previous schema, which
+// joined every post to the cache and scanned the whole posted_news table for the week's posts.
+func BenchmarkPopularNewsWithoutIndex(b *testing.B) {
+	bot := seedPostedNewsDatabase(b, 100000)
+	for _, index := range []string{"idx_posted_news_posted_at", "idx_posted_news_channel_posted_at"} {
+		if _, err := bot.DB.Exec(`DROP INDEX ` + index); err != nil {
+			b.Fatalf("Failed to drop %s: %v", index, err)
+		}
+	}
+	query := `SELECT nc.id, COUNT(pn.news_id) AS post_count
+			  FROM news_cache nc
+			  JOIN posted_news pn ON nc.id = pn.news_id
+			  WHERE pn.posted_at >= ?
+			  GROUP BY nc.id
+			  ORDER BY post_count DESC, nc.updated_at DESC
+			  LIMIT ?`
+	weekAgo := time.Now().UTC().AddDate(0, 0, -7).Format("2006-01-02 15:04:05")
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		rows, err := bot.DB.Query(query, weekAgo, 10)
+		if err != nil {
+			b.Fatalf("Failed to get popular news: %v", err)
+		}
+		for rows.Next() {
+		}
+		rows.Close()
+	}
+}
+
+func BenchmarkGetPopularNewsThisWeek(b *testing.B) {
+	bot := seedPostedNewsDatabase(b, 100000)
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, err := GetPopularNewsThisWeek(bot, 10); err != nil {
+			b.Fatalf("Failed to get popular news: %v", err)
+		}
+	}
+}
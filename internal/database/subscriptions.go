@@ -0,0 +1,127 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// Statuses of news delivered to subscribers, recorded in user_deliveries.
+const (
+	DMSent   = "sent"       // The news was sent to the user.
+	DMClosed = "dms_closed" // The user does not accept messages from the bot; the news is not retried.
+)
+
+// SetUserSubscription subscribes a user to messages about news with any of tags on any of
+// platforms, replacing the user's existing subscription.
+func SetUserSubscription(b *types.Bot, userID string, tags, platforms []string) error {
+	if len(tags) == 0 {
+		return fmt.Errorf("at least one tag is required")
+	}
+	platforms, err := types.NormalizePlatforms(platforms)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO user_subscriptions (user_id, tags, platforms) VALUES (?, ?, ?)
+			  ON CONFLICT(user_id) DO UPDATE SET tags = excluded.tags, platforms = excluded.platforms`
+	if _, err := b.DB.Exec(query, userID, strings.Join(tags, ","), strings.Join(platforms, ",")); err != nil {
+		return fmt.Errorf("failed to save subscription: %v", err)
+	}
+	return nil
+}
+
+// GetUserSubscription returns a user's subscription, or nil if the user is not subscribed.
+func GetUserSubscription(b *types.Bot, userID string) (*types.UserSubscription, error) {
+	row := b.DB.QueryRow(`SELECT user_id, tags, platforms, created_at FROM user_subscriptions WHERE user_id = ?`, userID)
+	subscription, err := scanUserSubscription(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// GetUserSubscriptions returns all subscriptions, ordered by user.
+func GetUserSubscriptions(b *types.Bot) ([]types.UserSubscription, error) {
+	rows, err := b.DB.Query(`SELECT user_id, tags, platforms, created_at FROM user_subscriptions ORDER BY user_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query subscriptions: %v", err)
+	}
+	defer rows.Close()
+
+	var subscriptions []types.UserSubscription
+	for rows.Next() {
+		subscription, err := scanUserSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read subscriptions: %v", err)
+	}
+	return subscriptions, nil
+}
+
+// scanUserSubscription scans a row of (user_id, tags, platforms, created_at).
+func scanUserSubscription(row rowScanner) (types.UserSubscription, error) {
+	var subscription types.UserSubscription
+	var tags, platforms string
+	var createdAt sql.NullTime
+	if err := row.Scan(&subscription.UserID, &tags, &platforms, &createdAt); err != nil {
+		if err == sql.ErrNoRows {
+			return subscription, err
+		}
+		return subscription, fmt.Errorf("failed to scan subscription: %v", err)
+	}
+	if tags != "" {
+		subscription.Tags = strings.Split(tags, ",")
+	}
+	if platforms != "" {
+		subscription.Platforms = strings.Split(platforms, ",")
+	}
+	subscription.CreatedAt = createdAt.Time
+	return subscription, nil
+}
+
+// RemoveUserSubscription unsubscribes a user and reports whether the user was subscribed.
+// Deliveries are kept, so subscribing again does not repeat news already sent.
+func RemoveUserSubscription(b *types.Bot, userID string) (bool, error) {
+	result, err := b.DB.Exec(`DELETE FROM user_subscriptions WHERE user_id = ?`, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove subscription: %v", err)
+	}
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	return removed > 0, nil
+}
+
+// IsNewsDeliveredToUser reports whether a news item was delivered to a user, or given up on
+// because the user does not accept messages.
+func IsNewsDeliveredToUser(b *types.Bot, newsID int64, userID string) (bool, error) {
+	var delivered bool
+	err := b.DB.QueryRow(`SELECT EXISTS (SELECT 1 FROM user_deliveries WHERE news_id = ? AND user_id = ?)`,
+		newsID, userID).Scan(&delivered)
+	if err != nil {
+		return false, fmt.Errorf("failed to check delivery: %v", err)
+	}
+	return delivered, nil
+}
+
+// MarkNewsDeliveredToUser records that a news item was delivered to a user with a status, DMSent
+// or DMClosed. An existing delivery is kept.
+func MarkNewsDeliveredToUser(b *types.Bot, newsID int64, userID, status string) error {
+	query := dialectOf(b.DB).insertIgnore("user_deliveries", []string{"news_id", "user_id", "status"}, `VALUES (?, ?, ?)`)
+	_, err := b.DB.Exec(query, newsID, userID, status)
+	if err != nil {
+		return fmt.Errorf("failed to mark news as delivered: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+func setupSubscriptionTest(t *testing.T) *types.Bot {
+	t.Helper()
+	db, err := InitDatabase(testDatabasePath(t, "stobot.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return &types.Bot{DB: db, Config: &types.Config{}}
+}
+
+func TestUserSubscriptions(t *testing.T) {
+	bot := setupSubscriptionTest(t)
+
+	subscription, err := GetUserSubscription(bot, "user-1")
+	if err != nil {
+		t.Fatalf("Failed to get subscription: %v", err)
+	}
+	if subscription != nil {
+		t.Fatalf("Expected no subscription, got %+v", subscription)
+	}
+
+	if err := SetUserSubscription(bot, "user-1", []string{"events"}, []string{"PC", "playstation"}); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	if err := SetUserSubscription(bot, "user-2", []string{"patch-notes"}, []string{"all"}); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	// Subscribing again replaces the subscription
+	if err := SetUserSubscription(bot, "user-1", []string{"events", "dev-blogs"}, []string{"pc", "ps"}); err != nil {
+		t.Fatalf("Failed to update subscription: %v", err)
+	}
+
+	subscription, err = GetUserSubscription(bot, "user-1")
+	if err != nil {
+		t.Fatalf("Failed to get subscription: %v", err)
+	}
+	if subscription == nil {
+		t.Fatal("Expected a subscription")
+	}
+	if !reflect.DeepEqual(subscription.Tags, []string{"events", "dev-blogs"}) {
+		t.Errorf("Expected tags [events dev-blogs], got %v", subscription.Tags)
+	}
+	if !reflect.DeepEqual(subscription.Platforms, []string{"pc", "ps"}) {
+		t.Errorf("Expected platforms [pc ps], got %v", subscription.Platforms)
+	}
+	if subscription.CreatedAt.IsZero() {
+		t.Error("Expected a creation time")
+	}
+
+	subscriptions, err := GetUserSubscriptions(bot)
+	if err != nil {
+		t.Fatalf("Failed to get subscriptions: %v", err)
+	}
+	if len(subscriptions) != 2 || subscriptions[0].UserID != "user-1" || subscriptions[1].UserID != "user-2" {
+		t.Fatalf("Expected subscriptions of user-1 and user-2, got %+v", subscriptions)
+	}
+	if !reflect.DeepEqual(subscriptions[1].Platforms, types.Platforms) {
+		t.Errorf("Expected all platforms, got %v", subscriptions[1].Platforms)
+	}
+
+	removed, err := RemoveUserSubscription(bot, "user-1")
+	if err != nil {
+		t.Fatalf("Failed to unsubscribe: %v", err)
+	}
+	if !removed {
+		t.Error("Expected the subscription to be removed")
+	}
+	removed, err = RemoveUserSubscription(bot, "user-1")
+	if err != nil {
+		t.Fatalf("Failed to unsubscribe: %v", err)
+	}
+	if removed {
+		t.Error("Expected nothing to remove for an unsubscribed user")
+	}
+}
+
+func TestSetUserSubscriptionValidation(t *testing.T) {
+	bot := setupSubscriptionTest(t)
+
+	tests := []struct {
+		name      string
+		tags      []string
+		platforms []string
+	}{
+		{"no tags", nil, []string{"pc"}},
+		{"unknown platform", []string{"events"}, []string{"switch"}},
+		{"no platforms", []string{"events"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetUserSubscription(bot, "user-1", tt.tags, tt.platforms); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestNewsDeliveredToUser(t *testing.T) {
+	bot := setupSubscriptionTest(t)
+
+	if err := MarkNewsDeliveredToUser(bot, 1, "user-1", DMSent); err != nil {
+		t.Fatalf("Failed to mark news as delivered: %v", err)
+	}
+	// A second delivery of the same news is ignored
+	if err := MarkNewsDeliveredToUser(bot, 1, "user-1", DMClosed); err != nil {
+		t.Fatalf("Failed to mark news as delivered again: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		newsID   int64
+		userID   string
+		expected bool
+	}{
+		{"delivered", 1, "user-1", true},
+		{"other user", 1, "user-2", false},
+		{"other news", 2, "user-1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delivered, err := IsNewsDeliveredToUser(bot, tt.newsID, tt.userID)
+			if err != nil {
+				t.Fatalf("Failed to check delivery: %v", err)
+			}
+			if delivered != tt.expected {
+				t.Errorf("Expected delivered %v, got %v", tt.expected, delivered)
+			}
+		})
+	}
+
+	var status string
+	if err := bot.DB.QueryRow(`SELECT status FROM user_deliveries WHERE news_id = 1 AND user_id = 'user-1'`).Scan(&status); err != nil {
+		t.Fatalf("Failed to get delivery status: %v", err)
+	}
+	if status != DMSent {
+		t.Errorf("Expected the first status %q to be kept, got %q", DMSent, status)
+	}
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// NewsPost is a Discord message a news item was posted as.
+type NewsPost struct {
+	ChannelID string
+	MessageID string
+}
+
+// GetCachedUpdateTimes returns the cached Updated timestamps of the given news items by ID.
+// Items that are not cached are left out.
+func GetCachedUpdateTimes(b *types.Bot, newsIDs []int64) (map[int64]time.Time, error) {
+	updated := make(map[int64]time.Time, len(newsIDs))
+	if len(newsIDs) == 0 {
+		return updated, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(newsIDs)), ",")
+	args := make([]interface{}, len(newsIDs))
+	for n, id := range newsIDs {
+		args[n] = id
+	}
+	rows, err := b.DB.Query(fmt.Sprintf(`SELECT id, updated_at FROM news_cache WHERE id IN (%s)`, placeholders), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cached update times: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var updatedAt time.Time
+		if err := rows.Scan(&id, &updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan cached update time: %v", err)
+		}
+		updated[id] = updatedAt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cached update times: %v", err)
+	}
+	return updated, nil
+}
+
+// GetNewsPosts returns the messages a news item was posted as, in channel ID order. Posts sent
+// before message IDs were recorded, and news marked as posted without a post, are left out.
+func GetNewsPosts(b *types.Bot, newsID int64) ([]NewsPost, error) {
+	query := `SELECT channel_id, message_id FROM posted_news
+			  WHERE news_id = ? AND status = ? AND message_id IS NOT NULL AND message_id != ''
+			  ORDER BY channel_id`
+
+	rows, err := b.DB.Query(query, newsID, PostStatusSent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query news posts: %v", err)
+	}
+	defer rows.Close()
+
+	var posts []NewsPost
+	for rows.Next() {
+		var post NewsPost
+		if err := rows.Scan(&post.ChannelID, &post.MessageID); err != nil {
+			return nil, fmt.Errorf("failed to scan news post: %v", err)
+		}
+		posts = append(posts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read news posts: %v", err)
+	}
+	return posts, nil
+}
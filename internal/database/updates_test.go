@@ -0,0 +1,55 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+func TestGetCachedUpdateTimes(t *testing.T) {
+	bot := setupLatencyTest(t)
+	updated := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	if err := CacheNews(bot, []types.NewsItem{{ID: 1, Title: "News", Updated: updated}}); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+
+	times, err := GetCachedUpdateTimes(bot, []int64{1, 2})
+	if err != nil {
+		t.Fatalf("Failed to get cached update times: %v", err)
+	}
+	if len(times) != 1 || !times[1].Equal(updated) {
+		t.Errorf("Expected only news 1 updated at %v, got %v", updated, times)
+	}
+}
+
+func TestGetNewsPosts(t *testing.T) {
+	bot := setupLatencyTest(t)
+	newsItem := types.NewsItem{ID: 1, Title: "News", Updated: time.Now()}
+	if err := CacheNews(bot, []types.NewsItem{newsItem}); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+
+	// Posted to channel-b and channel-a, marked as posted to channel without a post
+	for _, channelID := range []string{"channel-b", "channel-a"} {
+		if err := MarkNewsAsDelivered(bot, newsItem, channelID, DeliveryLive); err != nil {
+			t.Fatalf("Failed to mark news as delivered: %v", err)
+		}
+		if err := SetPostMessage(bot, newsItem.ID, channelID, "msg-"+channelID); err != nil {
+			t.Fatalf("Failed to set post message: %v", err)
+		}
+	}
+	if err := MarkNewsAsPosted(bot, newsItem.ID, "channel"); err != nil {
+		t.Fatalf("Failed to mark news as posted: %v", err)
+	}
+
+	posts, err := GetNewsPosts(bot, newsItem.ID)
+	if err != nil {
+		t.Fatalf("Failed to get news posts: %v", err)
+	}
+	expected := []NewsPost{{ChannelID: "channel-a", MessageID: "msg-channel-a"}, {ChannelID: "channel-b", MessageID: "msg-channel-b"}}
+	if !reflect.DeepEqual(posts, expected) {
+		t.Errorf("Expected posts %+v, got %+v", expected, posts)
+	}
+}
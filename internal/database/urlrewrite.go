@@ -0,0 +1,84 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// StoredURLRewrite describes a stored URL changed by the URL rewrite rules.
+type StoredURLRewrite struct {
+	NewsID int64  // NewsID is the cached news item holding the URL.
+	Column string // Column is the news_cache column holding the URL.
+	OldURL string // OldURL is the stored URL.
+	NewURL string // NewURL is the URL after applying the rules.
+}
+
+// RewriteStoredURLs finds cached news URLs matched by the rewrite rules and returns the
+// changes. When apply is true the changes are written in a single transaction; otherwise
+// the database is left untouched (dry run).
+//
+// Both thumbnail_url and the article url are rewritten. Article links of news cached without a
+// url are built from the news ID when posting; the rules still apply to them at display time.
+func RewriteStoredURLs(b *types.Bot, rules []types.URLRewriteRule, apply bool) ([]StoredURLRewrite, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	rows, err := b.DB.Query(`SELECT id, COALESCE(thumbnail_url, ''), COALESCE(url, '') FROM news_cache
+							 WHERE COALESCE(thumbnail_url, '') != '' OR COALESCE(url, '') != '' ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cached URLs: %v", err)
+	}
+
+	var changes []StoredURLRewrite
+	for rows.Next() {
+		var newsID int64
+		var thumbnailURL, articleURL string
+		if err := rows.Scan(&newsID, &thumbnailURL, &articleURL); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan cached URLs: %v", err)
+		}
+		if rewritten, ok := types.RewriteURL(thumbnailURL, rules); ok && thumbnailURL != "" {
+			changes = append(changes, StoredURLRewrite{NewsID: newsID, Column: "thumbnail_url", OldURL: thumbnailURL, NewURL: rewritten})
+		}
+		if rewritten, ok := types.RewriteURL(articleURL, rules); ok && articleURL != "" {
+			changes = append(changes, StoredURLRewrite{NewsID: newsID, Column: "url", OldURL: articleURL, NewURL: rewritten})
+		}
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cached URLs: %v", err)
+	}
+
+	if !apply || len(changes) == 0 {
+		return changes, nil
+	}
+
+	tx, err := b.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			logger().Printf("Warning: failed to rollback transaction: %v", rollbackErr)
+		}
+	}()
+
+	for _, change := range changes {
+		// Column is one of the two column names above, never user input
+		query := fmt.Sprintf(`UPDATE news_cache SET %s = ? WHERE id = ?`, change.Column)
+		if _, err := tx.Exec(query, change.NewURL, change.NewsID); err != nil {
+			return nil, fmt.Errorf("failed to rewrite %s of news %d: %v", change.Column, change.NewsID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit URL rewrites: %v", err)
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,119 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+func getCachedThumbnailURL(t *testing.T, bot *types.Bot, newsID int64) string {
+	t.Helper()
+
+	var thumbnailURL string
+	if err := bot.DB.QueryRow("SELECT COALESCE(thumbnail_url, '') FROM news_cache WHERE id = ?", newsID).Scan(&thumbnailURL); err != nil {
+		t.Fatalf("Failed to read thumbnail URL: %v", err)
+	}
+	return thumbnailURL
+}
+
+func TestRewriteStoredURLs(t *testing.T) {
+	db, err := InitDatabase(testDatabasePath(t, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	bot := &types.Bot{DB: db}
+
+	newsItems := []types.NewsItem{
+		{ID: 1, Title: "Old", Updated: time.Now(), ThumbnailURL: "https://images.arcgames.com/1.jpg?w=400"},
+		{ID: 2, Title: "Migrated", Updated: time.Now(), ThumbnailURL: "https://cdn.playstartrekonline.com/2.jpg"},
+		{ID: 3, Title: "Unrelated", Updated: time.Now(), ThumbnailURL: "https://example.com/3.jpg"},
+		{ID: 4, Title: "No thumbnail", Updated: time.Now()},
+	}
+	if err := StoreNews(db, newsItems, BulkDatabaseOptions()); err != nil {
+		t.Fatalf("Failed to store news: %v", err)
+	}
+
+	rules := []types.URLRewriteRule{{From: "https://images.arcgames.com", To: "https://cdn.playstartrekonline.com"}}
+
+	// Dry run reports the change without writing it
+	changes, err := RewriteStoredURLs(bot, rules, false)
+	if err != nil {
+		t.Fatalf("Failed to rewrite URLs: %v", err)
+	}
+	expected := StoredURLRewrite{
+		NewsID: 1,
+		Column: "thumbnail_url",
+		OldURL: "https://images.arcgames.com/1.jpg?w=400",
+		NewURL: "https://cdn.playstartrekonline.com/1.jpg?w=400",
+	}
+	if len(changes) != 1 || changes[0] != expected {
+		t.Fatalf("Expected changes [%+v], got %+v", expected, changes)
+	}
+	if url := getCachedThumbnailURL(t, bot, 1); url != expected.OldURL {
+		t.Errorf("Expected dry run to leave %q, got %q", expected.OldURL, url)
+	}
+
+	// Apply writes it
+	if _, err := RewriteStoredURLs(bot, rules, true); err != nil {
+		t.Fatalf("Failed to rewrite URLs: %v", err)
+	}
+	if url := getCachedThumbnailURL(t, bot, 1); url != expected.NewURL {
+		t.Errorf("Expected %q after apply, got %q", expected.NewURL, url)
+	}
+	if url := getCachedThumbnailURL(t, bot, 3); url != "https://example.com/3.jpg" {
+		t.Errorf("Expected unrelated URL to be unchanged, got %q", url)
+	}
+
+	// A second run finds nothing left to rewrite
+	changes, err = RewriteStoredURLs(bot, rules, true)
+	if err != nil {
+		t.Fatalf("Failed to rewrite URLs: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("Expected no changes on second run, got %+v", changes)
+	}
+}
+
+func TestRewriteStoredArticleURLs(t *testing.T) {
+	db, err := InitDatabase(testDatabasePath(t, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	bot := &types.Bot{DB: db}
+
+	newsItems := []types.NewsItem{
+		{ID: 1, Title: "Moved", Updated: time.Now(), URL: "https://playstartrekonline.com/en/news/article/1-moved"},
+		{ID: 2, Title: "Elsewhere", Updated: time.Now(), URL: "https://example.com/news/2"},
+	}
+	if err := StoreNews(db, newsItems, BulkDatabaseOptions()); err != nil {
+		t.Fatalf("Failed to store news: %v", err)
+	}
+
+	rules := []types.URLRewriteRule{{From: "https://playstartrekonline.com/en/news", To: "https://new.example.com/news"}}
+	changes, err := RewriteStoredURLs(bot, rules, true)
+	if err != nil {
+		t.Fatalf("Failed to rewrite URLs: %v", err)
+	}
+	expected := StoredURLRewrite{
+		NewsID: 1,
+		Column: "url",
+		OldURL: "https://playstartrekonline.com/en/news/article/1-moved",
+		NewURL: "https://new.example.com/news/article/1-moved",
+	}
+	if len(changes) != 1 || changes[0] != expected {
+		t.Fatalf("Expected changes [%+v], got %+v", expected, changes)
+	}
+
+	newsItem, err := GetCachedNewsByID(bot, 1)
+	if err != nil {
+		t.Fatalf("Failed to get cached news: %v", err)
+	}
+	if newsItem == nil || newsItem.URL != expected.NewURL {
+		t.Errorf("Expected URL %q after apply, got %+v", expected.NewURL, newsItem)
+	}
+}
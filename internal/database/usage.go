@@ -0,0 +1,67 @@
+package database
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// CommandUsage counts how often a slash command was used.
+type CommandUsage struct {
+	Command string // Command is the slash command name.
+	Uses    int    // Uses is the number of times the command was used.
+	Users   int    // Users is the number of distinct users who used the command.
+	Guilds  int    // Guilds is the number of distinct servers the command was used in; DMs are not counted.
+}
+
+// RecordCommandUsage records that a user used a slash command in a guild, or in a DM when
+// guildID is empty. Only a hash of the user ID is stored, enough to count distinct users.
+func RecordCommandUsage(b *types.Bot, command, guildID, userID string) error {
+	query := `INSERT INTO command_usage (command_name, guild_id, user_hash, used_at) VALUES (?, ?, ?, ?)`
+	if _, err := b.DB.Exec(query, command, guildID, hashUserID(userID),
+		now().UTC().Format("2006-01-02 15:04:05")); err != nil {
+		return fmt.Errorf("failed to record command usage: %v", err)
+	}
+	return nil
+}
+
+// GetCommandUsageStats returns the usage of each slash command in the last days days, most
+// used first.
+func GetCommandUsageStats(b *types.Bot, days int) ([]CommandUsage, error) {
+	if days <= 0 {
+		return nil, fmt.Errorf("days must be positive")
+	}
+	since := now().UTC().AddDate(0, 0, -days).Format("2006-01-02 15:04:05")
+
+	query := `SELECT command_name, COUNT(*), COUNT(DISTINCT user_hash), COUNT(DISTINCT NULLIF(guild_id, ''))
+			  FROM command_usage
+			  WHERE used_at >= ?
+			  GROUP BY command_name
+			  ORDER BY COUNT(*) DESC, command_name`
+	rows, err := b.DB.Query(query, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query command usage: %v", err)
+	}
+	defer rows.Close()
+
+	var usage []CommandUsage
+	for rows.Next() {
+		var u CommandUsage
+		if err := rows.Scan(&u.Command, &u.Uses, &u.Users, &u.Guilds); err != nil {
+			return nil, fmt.Errorf("failed to scan command usage: %v", err)
+		}
+		usage = append(usage, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read command usage: %v", err)
+	}
+	return usage, nil
+}
+
+// hashUserID returns a hash identifying a user in command_usage without storing the user ID.
+func hashUserID(userID string) string {
+	sum := sha256.Sum256([]byte("stobot-command-usage:" + userID))
+	return hex.EncodeToString(sum[:16])
+}
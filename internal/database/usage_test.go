@@ -0,0 +1,57 @@
+package database
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommandUsageStats(t *testing.T) {
+	bot := setupSubscriptionTest(t)
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	setClock(t, start.AddDate(0, 0, -45))
+	if err := RecordCommandUsage(bot, "stobot_news", "guild-1", "user-1"); err != nil {
+		t.Fatalf("Failed to record command usage: %v", err)
+	}
+
+	setClock(t, start)
+	for _, use := range []struct{ command, guildID, userID string }{
+		{"stobot_news", "guild-1", "user-1"},
+		{"stobot_news", "guild-1", "user-1"},
+		{"stobot_news", "guild-2", "user-2"},
+		{"stobot_help", "", "user-3"},
+		{"stobot_status", "guild-1", "user-1"},
+		{"stobot_help", "guild-2", "user-2"},
+	} {
+		if err := RecordCommandUsage(bot, use.command, use.guildID, use.userID); err != nil {
+			t.Fatalf("Failed to record command usage: %v", err)
+		}
+	}
+
+	usage, err := GetCommandUsageStats(bot, 30)
+	if err != nil {
+		t.Fatalf("Failed to get command usage: %v", err)
+	}
+	expected := []CommandUsage{
+		{Command: "stobot_news", Uses: 3, Users: 2, Guilds: 2},
+		{Command: "stobot_help", Uses: 2, Users: 2, Guilds: 1},
+		{Command: "stobot_status", Uses: 1, Users: 1, Guilds: 1},
+	}
+	if !reflect.DeepEqual(usage, expected) {
+		t.Errorf("Expected usage %+v, got %+v", expected, usage)
+	}
+
+	var userHash string
+	if err := bot.DB.QueryRow(`SELECT user_hash FROM command_usage LIMIT 1`).Scan(&userHash); err != nil {
+		t.Fatalf("Failed to read user hash: %v", err)
+	}
+	if strings.Contains(userHash, "user-1") || len(userHash) != 32 {
+		t.Errorf("Expected a hashed user ID, got %q", userHash)
+	}
+
+	if _, err := GetCommandUsageStats(bot, 0); err == nil {
+		t.Error("Expected an error for a non-positive number of days")
+	}
+}
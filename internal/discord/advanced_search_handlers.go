@@ -9,14 +9,13 @@ import (
 	"github.com/FracKenA/sto_news_discord_bot/internal/types"
 
 	"github.com/bwmarrin/discordgo"
-	log "github.com/sirupsen/logrus"
 )
 
 // handleAdvancedSearchNews handles the "advanced_search" command interaction
 func handleAdvancedSearchNews(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
 	// Acknowledge interaction
 	if err := AcknowledgeWithRetry(s, i); err != nil {
-		log.Errorf("Failed to acknowledge advanced_search command: %v", err)
+		logger().Errorf("Failed to acknowledge advanced_search command: %v", err)
 		return
 	}
 
@@ -41,10 +40,10 @@ func handleAdvancedSearchNews(b *types.Bot, s *discordgo.Session, i *discordgo.I
 	}
 
 	// Perform advanced search
-	log.Infof("Performing advanced search for: %s (limit: %d)", query, limit)
+	logger().Infof("Performing advanced search for: %s (limit: %d)", query, limit)
 	results, err := database.AdvancedSearchNews(b, query, limit)
 	if err != nil {
-		log.Errorf("Failed to perform advanced search: %v", err)
+		logger().Errorf("Failed to perform advanced search: %v", err)
 		Followup(s, i, "❌ Failed to perform advanced search. Please try again later.")
 		return
 	}
@@ -59,25 +58,26 @@ func handleAdvancedSearchNews(b *types.Bot, s *discordgo.Session, i *discordgo.I
 	var embeds []*discordgo.MessageEmbed
 	for i, result := range results {
 		embed := formatAdvancedSearchResultEmbed(result, i+1)
+		b.Config.RewriteEmbedURLs(embed)
 		embeds = append(embeds, embed)
 	}
 
 	// Send results
 	content := fmt.Sprintf("🔍 **Advanced search results for \"%s\"** (%d found)", query, len(results))
-	if err := FollowupWithEmbeds(s, i, content, embeds); err != nil {
-		log.Errorf("Failed to send advanced search results: %v", err)
+	if err := FollowupWithPages(s, i, content, embeds); err != nil {
+		logger().Errorf("Failed to send advanced search results: %v", err)
 		Followup(s, i, "❌ Failed to send search results.")
 		return
 	}
 
-	log.Infof("Sent %d advanced search results", len(results))
+	logger().Infof("Sent %d advanced search results", len(results))
 }
 
 // handleFuzzySearchNews handles the "fuzzy_search" command interaction
 func handleFuzzySearchNews(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
 	// Acknowledge interaction
 	if err := AcknowledgeWithRetry(s, i); err != nil {
-		log.Errorf("Failed to acknowledge fuzzy_search command: %v", err)
+		logger().Errorf("Failed to acknowledge fuzzy_search command: %v", err)
 		return
 	}
 
@@ -102,10 +102,10 @@ func handleFuzzySearchNews(b *types.Bot, s *discordgo.Session, i *discordgo.Inte
 	}
 
 	// Perform fuzzy search
-	log.Infof("Performing fuzzy search for: %s (limit: %d)", query, limit)
+	logger().Infof("Performing fuzzy search for: %s (limit: %d)", query, limit)
 	results, err := database.FuzzySearchNews(b, query, limit)
 	if err != nil {
-		log.Errorf("Failed to perform fuzzy search: %v", err)
+		logger().Errorf("Failed to perform fuzzy search: %v", err)
 		Followup(s, i, "❌ Failed to perform fuzzy search. Please try again later.")
 		return
 	}
@@ -119,25 +119,26 @@ func handleFuzzySearchNews(b *types.Bot, s *discordgo.Session, i *discordgo.Inte
 	var embeds []*discordgo.MessageEmbed
 	for i, result := range results {
 		embed := formatFuzzySearchResultEmbed(result, i+1)
+		b.Config.RewriteEmbedURLs(embed)
 		embeds = append(embeds, embed)
 	}
 
 	// Send results
 	content := fmt.Sprintf("🔍 **Fuzzy search results for \"%s\"** (%d found)", query, len(results))
-	if err := FollowupWithEmbeds(s, i, content, embeds); err != nil {
-		log.Errorf("Failed to send fuzzy search results: %v", err)
+	if err := FollowupWithPages(s, i, content, embeds); err != nil {
+		logger().Errorf("Failed to send fuzzy search results: %v", err)
 		Followup(s, i, "❌ Failed to send search results.")
 		return
 	}
 
-	log.Infof("Sent %d fuzzy search results", len(results))
+	logger().Infof("Sent %d fuzzy search results", len(results))
 }
 
 // handleFilteredSearch handles the "filtered_search" command interaction
 func handleFilteredSearch(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
 	// Acknowledge interaction
 	if err := AcknowledgeWithRetry(s, i); err != nil {
-		log.Errorf("Failed to acknowledge filtered_search command: %v", err)
+		logger().Errorf("Failed to acknowledge filtered_search command: %v", err)
 		return
 	}
 
@@ -182,10 +183,10 @@ func handleFilteredSearch(b *types.Bot, s *discordgo.Session, i *discordgo.Inter
 	}
 
 	// Perform filtered search
-	log.Infof("Performing filtered search with options: %+v", options)
+	logger().Infof("Performing filtered search with options: %+v", options)
 	results, err := database.SearchWithFilters(b, options)
 	if err != nil {
-		log.Errorf("Failed to perform filtered search: %v", err)
+		logger().Errorf("Failed to perform filtered search: %v", err)
 		Followup(s, i, "❌ Failed to perform filtered search. Please try again later.")
 		return
 	}
@@ -199,6 +200,7 @@ func handleFilteredSearch(b *types.Bot, s *discordgo.Session, i *discordgo.Inter
 	var embeds []*discordgo.MessageEmbed
 	for i, result := range results {
 		embed := formatFilteredSearchResultEmbed(result, i+1)
+		b.Config.RewriteEmbedURLs(embed)
 		embeds = append(embeds, embed)
 	}
 
@@ -221,13 +223,13 @@ func handleFilteredSearch(b *types.Bot, s *discordgo.Session, i *discordgo.Inter
 	}
 
 	content := fmt.Sprintf("🔍 **Filtered search results** (%d found)\n**Filters:** %s", len(results), queryDesc.String())
-	if err := FollowupWithEmbeds(s, i, content, embeds); err != nil {
-		log.Errorf("Failed to send filtered search results: %v", err)
+	if err := FollowupWithPages(s, i, content, embeds); err != nil {
+		logger().Errorf("Failed to send filtered search results: %v", err)
 		Followup(s, i, "❌ Failed to send search results.")
 		return
 	}
 
-	log.Infof("Sent %d filtered search results", len(results))
+	logger().Infof("Sent %d filtered search results", len(results))
 }
 
 // formatAdvancedSearchResultEmbed formats a search result with relevance score
@@ -292,6 +294,7 @@ func buildSearchHelpText() string {
 • **Excluded:** -word (must not contain)
 • **Tags:** tag:events, tag:patch-notes
 • **Platforms:** platform:pc, platform:xbox
+• **Authors:** author:ambassador, author:"Jane Doe"
 • **Date filters:** after:2023-01-01, before:2023-12-31
 • **Sorting:** sort:date, sort:title, order:asc
 
@@ -0,0 +1,102 @@
+package discord
+
+import (
+	"strings"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Discord limits on autocomplete responses.
+const (
+	maxAutocompleteChoices = 25  // maxAutocompleteChoices is the number of choices in one response.
+	maxChoiceLength        = 100 // maxChoiceLength is the length of a choice's name and value.
+)
+
+// autocompleteTagCount is the number of popular cached tags offered as autocomplete choices.
+const autocompleteTagCount = 20
+
+// HandleAutocomplete answers autocomplete interactions with choices for the option being typed.
+// Options without autocomplete get no choices.
+func HandleAutocomplete(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if b == nil || s == nil || i == nil || i.Interaction == nil {
+		logger().Warn("HandleAutocomplete called with nil parameters")
+		return
+	}
+
+	data := i.ApplicationCommandData()
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	for _, option := range data.Options {
+		if !option.Focused {
+			continue
+		}
+		if data.Name == "stobot_search_tags" && option.Name == "tags" {
+			choices = popularTagChoices(b, option.StringValue())
+		}
+	}
+
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	})
+	if err != nil {
+		logger().Errorf("Failed to send autocomplete choices for %s: %v", data.Name, err)
+	}
+}
+
+// popularTagChoices returns the autocomplete choices for a comma-separated tag list being typed,
+// from the most used cached tags.
+func popularTagChoices(b *types.Bot, input string) []*discordgo.ApplicationCommandOptionChoice {
+	popular, err := database.GetPopularTags(b, autocompleteTagCount)
+	if err != nil {
+		logger().Errorf("Failed to get popular tags for autocomplete: %v", err)
+		return []*discordgo.ApplicationCommandOptionChoice{}
+	}
+
+	tags := make([]string, 0, len(popular))
+	for _, tagCount := range popular {
+		tags = append(tags, tagCount.Tag)
+	}
+	return tagChoices(tags, input)
+}
+
+// tagChoices returns autocomplete choices completing the last tag of input, a comma-separated tag
+// list, with the tags containing it, in the order of tags. Each choice keeps the tags typed before
+// the last one, and tags already in the list are not offered again.
+//
+// Example:
+//
+//	tagChoices([]string{"patch-notes", "events"}, "patch-notes, ev") // "patch-notes,events"
+func tagChoices(tags []string, input string) []*discordgo.ApplicationCommandOptionChoice {
+	typed, partial := "", input
+	if index := strings.LastIndex(input, ","); index >= 0 {
+		typed, partial = input[:index], input[index+1:]
+	}
+	chosen := parseTagList(typed)
+	partial = strings.ToLower(strings.TrimSpace(partial))
+
+	used := make(map[string]bool, len(chosen))
+	for _, tag := range chosen {
+		used[tag] = true
+	}
+
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	for _, tag := range tags {
+		if len(choices) >= maxAutocompleteChoices {
+			break
+		}
+		if used[tag] || !strings.Contains(tag, partial) {
+			continue
+		}
+		used[tag] = true
+
+		value := strings.Join(append(chosen[:len(chosen):len(chosen)], tag), ",")
+		if len(value) > maxChoiceLength {
+			continue
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: value, Value: value})
+	}
+	return choices
+}
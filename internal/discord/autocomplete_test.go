@@ -0,0 +1,117 @@
+package discord
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// choiceValues returns the values of autocomplete choices.
+func choiceValues(t *testing.T, choices []*discordgo.ApplicationCommandOptionChoice) []string {
+	t.Helper()
+	values := []string{}
+	for _, choice := range choices {
+		value, _ := choice.Value.(string)
+		if choice.Name != value {
+			t.Errorf("Expected choice name %q to be its value %v", choice.Name, choice.Value)
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+func TestTagChoices(t *testing.T) {
+	tags := []string{"star-trek-online", "patch-notes", "events", "patch-notes-xbox", "dev-blogs"}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"nothing typed", "", tags},
+		{"partial tag", "pat", []string{"patch-notes", "patch-notes-xbox"}},
+		{"matches inside tags", "XBOX", []string{"patch-notes-xbox"}},
+		{"keeps earlier tags", "events, patch", []string{"events,patch-notes", "events,patch-notes-xbox"}},
+		{"after a comma", "events,", []string{"events,star-trek-online", "events,patch-notes", "events,patch-notes-xbox", "events,dev-blogs"}},
+		{"skips chosen tags", "patch-notes,patch", []string{"patch-notes,patch-notes-xbox"}},
+		{"no match", "tribbles", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if values := choiceValues(t, tagChoices(tags, tt.input)); !reflect.DeepEqual(values, tt.expected) {
+				t.Errorf("tagChoices(%q) = %q, want %q", tt.input, values, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTagChoicesLimits(t *testing.T) {
+	var tags []string
+	for n := range 40 {
+		tags = append(tags, fmt.Sprintf("tag-%d", n))
+	}
+	if choices := tagChoices(tags, ""); len(choices) != maxAutocompleteChoices {
+		t.Errorf("Expected %d choices, got %d", maxAutocompleteChoices, len(choices))
+	}
+
+	// Choices too long for Discord are left out
+	typed := strings.Repeat("a", 90)
+	values := choiceValues(t, tagChoices([]string{"short", "much-longer-tag"}, typed+","))
+	if !reflect.DeepEqual(values, []string{typed + ",short"}) {
+		t.Errorf("Expected only the choice within %d characters, got %q", maxChoiceLength, values)
+	}
+}
+
+func TestHandleAutocomplete(t *testing.T) {
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	newsItems := []types.NewsItem{
+		{ID: 1, Title: "Event", Tags: []string{"events", "star-trek-online"}, Updated: time.Now()},
+		{ID: 2, Title: "Another Event", Tags: []string{"events"}, Updated: time.Now()},
+		{ID: 3, Title: "Patch Notes", Tags: []string{"patch-notes"}, Updated: time.Now()},
+	}
+	if err := database.CacheNews(bot, newsItems); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+	fake := testhelpers.NewFakeDiscord(t)
+	bot.Session = fake.Session()
+
+	option := stringOption("tags", "patch-notes,")
+	option.Focused = true
+	interaction := discoveryInteraction("stobot_search_tags", option)
+	interaction.Type = discordgo.InteractionApplicationCommandAutocomplete
+	InteractionCreate(bot)(bot.Session, interaction)
+
+	calls := fake.RequestsTo("POST", "/interactions/interaction-1/interaction-token/callback")
+	if len(calls) != 1 {
+		t.Fatalf("Expected 1 autocomplete response, got %d", len(calls))
+	}
+	var response struct {
+		Type discordgo.InteractionResponseType `json:"type"`
+		Data struct {
+			Choices []*discordgo.ApplicationCommandOptionChoice `json:"choices"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(calls[0].Body, &response); err != nil {
+		t.Fatalf("Failed to decode autocomplete response: %v", err)
+	}
+	if response.Type != discordgo.InteractionApplicationCommandAutocompleteResult {
+		t.Errorf("Expected an autocomplete result, got type %d", response.Type)
+	}
+	expected := []string{"patch-notes,events", "patch-notes,star-trek-online"}
+	if values := choiceValues(t, response.Data.Choices); !reflect.DeepEqual(values, expected) {
+		t.Errorf("Expected the most used tags %q, got %q", expected, values)
+	}
+	if followups := fake.RequestsTo("POST", "/webhooks/app-1/interaction-token"); len(followups) != 0 {
+		t.Errorf("Expected autocomplete not to run the search, got %d followups", len(followups))
+	}
+}
@@ -0,0 +1,135 @@
+package discord
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// CatchUpDefaultPeriod is how far back /stobot_catch_me_up looks the first time a user runs it
+// in a server.
+const CatchUpDefaultPeriod = 7 * 24 * time.Hour
+
+// maxCatchUpArticles is the most articles /stobot_catch_me_up lists; more are summarized by tag.
+const maxCatchUpArticles = 50
+
+// maxCatchUpTags is the most tags the summary of a long catch-up lists.
+const maxCatchUpTags = 15
+
+// handleCatchMeUp handles the "catch_me_up" command interaction: it privately shows the invoker
+// the news posted to the channel since they last ran the command in the server, or in the last
+// CatchUpDefaultPeriod the first time, and records the time they caught up.
+func handleCatchMeUp(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if err := AcknowledgeWithRetry(s, i); err != nil {
+		logger().Errorf("Failed to acknowledge catch_me_up command: %v", err)
+		return
+	}
+
+	userID := interactionUserID(i)
+	if userID == "" {
+		Followup(s, i, "❌ Could not identify you.")
+		return
+	}
+
+	channelID := i.ChannelID
+	channelLog := channelLogger(channelID)
+	platforms, err := database.GetChannelPlatforms(b, channelID)
+	if err != nil {
+		channelLog.Errorf("Failed to get channel platforms for %s: %v", channelID, err)
+		Followup(s, i, "❌ Failed to check channel status. Please try again later.")
+		return
+	}
+	if len(platforms) == 0 {
+		Followup(s, i, "📭 This channel does not get STO news. Run `/stobot_catch_me_up` in a channel registered with `/stobot_register`.")
+		return
+	}
+
+	now := time.Now()
+	since, err := database.GetUserCheckpoint(b, userID, i.GuildID)
+	if err != nil {
+		logger().Errorf("Failed to get checkpoint of user %s: %v", userID, err)
+		Followup(s, i, "❌ Failed to look up when you last caught up. Please try again later.")
+		return
+	}
+	sinceText := "you last caught up"
+	if since.IsZero() {
+		since = now.Add(-CatchUpDefaultPeriod)
+		sinceText = "the last 7 days"
+	}
+	sinceText = fmt.Sprintf("%s (<t:%d:R>)", sinceText, since.Unix())
+
+	newsItems, err := database.GetNewsPostedSince(b, channelID, since)
+	if err != nil {
+		channelLog.Errorf("Failed to get news posted to channel %s: %v", channelID, err)
+		Followup(s, i, "❌ Failed to get the news posted here. Please try again later.")
+		return
+	}
+
+	switch {
+	case len(newsItems) == 0:
+		err = FollowupWithEmbeds(s, i, fmt.Sprintf("✅ You are all caught up: no news was posted here since %s.", sinceText), nil)
+	case len(newsItems) > maxCatchUpArticles:
+		err = FollowupWithEmbeds(s, i, formatCatchUpSummary(newsItems, sinceText), nil)
+	default:
+		embeds := make([]*discordgo.MessageEmbed, len(newsItems))
+		for n, newsItem := range newsItems {
+			embeds[n] = formatNewsEmbed(newsItem)
+			b.Config.RewriteEmbedURLs(embeds[n])
+		}
+		content := fmt.Sprintf("📰 **News posted here since %s** (%d found)", sinceText, len(newsItems))
+		err = FollowupWithPages(s, i, content, embeds)
+	}
+	if err != nil {
+		channelLog.Errorf("Failed to send catch-up to user %s: %v", userID, err)
+		Followup(s, i, "❌ Failed to send the news posted here.")
+		return
+	}
+
+	// Only a catch-up the user got moves their checkpoint
+	if err := database.SetUserCheckpoint(b, userID, i.GuildID, now); err != nil {
+		logger().Errorf("Failed to set checkpoint of user %s: %v", userID, err)
+	}
+	channelLog.Infof("Caught user %s up on %d news items in channel %s", userID, len(newsItems), channelID)
+}
+
+// formatCatchUpSummary summarizes news too many to list by how many articles have each tag, most
+// first; articles with several tags count towards each.
+func formatCatchUpSummary(newsItems []types.NewsItem, sinceText string) string {
+	counts := make(map[string]int)
+	for _, newsItem := range newsItems {
+		if len(newsItem.Tags) == 0 {
+			counts["untagged"]++
+		}
+		for _, tag := range newsItem.Tags {
+			counts[tag]++
+		}
+	}
+	tags := make([]string, 0, len(counts))
+	for tag := range counts {
+		tags = append(tags, tag)
+	}
+	slices.SortFunc(tags, func(x, y string) int {
+		if counts[x] != counts[y] {
+			return counts[y] - counts[x]
+		}
+		return strings.Compare(x, y)
+	})
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "📰 **%d articles were posted here since %s**, too many to list. By tag:\n", len(newsItems), sinceText)
+	for n, tag := range tags {
+		if n == maxCatchUpTags {
+			fmt.Fprintf(&sb, "• …and %d more tags\n", len(tags)-maxCatchUpTags)
+			break
+		}
+		fmt.Fprintf(&sb, "• **%s**: %d\n", tag, counts[tag])
+	}
+	sb.WriteString("Use `/stobot_search_tags <tags>` or `/stobot_digest` to read them.")
+	return sb.String()
+}
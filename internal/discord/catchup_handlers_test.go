@@ -0,0 +1,107 @@
+package discord
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// catchUpInteraction builds a /stobot_catch_me_up interaction by user-1.
+func catchUpInteraction() *discordgo.InteractionCreate {
+	interaction := discoveryInteraction("stobot_catch_me_up")
+	interaction.Member = &discordgo.Member{User: &discordgo.User{ID: "user-1"}}
+	return interaction
+}
+
+// deliverNews caches count news items tagged tag, starting at ID first, and marks them as
+// posted to channel-a.
+func deliverNews(t *testing.T, bot *types.Bot, first int64, count int, tag string) {
+	t.Helper()
+	newsItems := make([]types.NewsItem, count)
+	for n := range newsItems {
+		newsItems[n] = types.NewsItem{ID: first + int64(n), Title: fmt.Sprintf("News %d", first+int64(n)), Tags: []string{tag}, Updated: time.Now()}
+	}
+	if err := database.CacheNews(bot, newsItems); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+	for _, newsItem := range newsItems {
+		if err := database.MarkNewsAsDelivered(bot, newsItem, "channel-a", database.DeliveryLive); err != nil {
+			t.Fatalf("Failed to mark news as delivered: %v", err)
+		}
+	}
+}
+
+func TestCatchMeUpCommand(t *testing.T) {
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	fake := testhelpers.NewFakeDiscord(t)
+	bot.Session = fake.Session()
+	lastFollowup := func() discordgo.WebhookParams {
+		t.Helper()
+		calls := fake.RequestsTo("POST", "/webhooks/app-1/interaction-token")
+		if len(calls) == 0 {
+			t.Fatal("Expected a followup")
+		}
+		var params discordgo.WebhookParams
+		if err := json.Unmarshal(calls[len(calls)-1].Body, &params); err != nil {
+			t.Fatalf("Failed to decode followup: %v", err)
+		}
+		return params
+	}
+
+	// Unregistered channels have nothing to catch up on
+	handleCatchMeUp(bot, bot.Session, catchUpInteraction())
+	if followup := lastFollowup(); !strings.Contains(followup.Content, "does not get STO news") {
+		t.Fatalf("Expected an unregistered channel message, got %q", followup.Content)
+	}
+
+	if err := database.AddChannel(bot, "channel-a"); err != nil {
+		t.Fatalf("Failed to add channel: %v", err)
+	}
+	deliverNews(t, bot, 1, 2, "events")
+
+	// The first catch-up covers the last 7 days and records a checkpoint
+	handleCatchMeUp(bot, bot.Session, catchUpInteraction())
+	followup := lastFollowup()
+	if !strings.Contains(followup.Content, "the last 7 days") || len(followup.Embeds) != 2 {
+		t.Fatalf("Expected the 2 articles of the last 7 days, got %q with %d embeds", followup.Content, len(followup.Embeds))
+	}
+	if followup.Flags&discordgo.MessageFlagsEphemeral == 0 {
+		t.Error("Expected a private followup")
+	}
+	checkedAt, err := database.GetUserCheckpoint(bot, "user-1", "guild-1")
+	if err != nil || time.Since(checkedAt) > time.Minute {
+		t.Fatalf("Expected a checkpoint of now, got %v (%v)", checkedAt, err)
+	}
+
+	// Later catch-ups start at the checkpoint
+	if err := database.SetUserCheckpoint(bot, "user-1", "guild-1", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Failed to set checkpoint: %v", err)
+	}
+	handleCatchMeUp(bot, bot.Session, catchUpInteraction())
+	if followup := lastFollowup(); !strings.Contains(followup.Content, "all caught up") {
+		t.Errorf("Expected nothing new since the checkpoint, got %q", followup.Content)
+	}
+
+	// More than maxCatchUpArticles are summarized by tag
+	if err := database.SetUserCheckpoint(bot, "user-1", "guild-1", time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("Failed to set checkpoint: %v", err)
+	}
+	deliverNews(t, bot, 100, maxCatchUpArticles, "patch-notes")
+	handleCatchMeUp(bot, bot.Session, catchUpInteraction())
+	followup = lastFollowup()
+	if len(followup.Embeds) != 0 || !strings.Contains(followup.Content, "52 articles") {
+		t.Fatalf("Expected a summary of 52 articles, got %q with %d embeds", followup.Content, len(followup.Embeds))
+	}
+	if patchNotes := strings.Index(followup.Content, "**patch-notes**: 50"); patchNotes < 0 || patchNotes > strings.Index(followup.Content, "**events**: 2") {
+		t.Errorf("Expected tag counts, most first, got %q", followup.Content)
+	}
+}
@@ -4,30 +4,32 @@
 package discord
 
 import (
+	"strings"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/news"
 	"github.com/FracKenA/sto_news_discord_bot/internal/types"
 
 	"github.com/bwmarrin/discordgo"
-	log "github.com/sirupsen/logrus"
 )
 
 // RegisterCommands registers all slash commands with Discord
 func RegisterCommands(s *discordgo.Session) {
 	// Wait for the session to be ready and get application info
 	if s.State == nil || s.State.User == nil {
-		log.Error("Session state is not ready, cannot register commands")
+		logger().Error("Session state is not ready, cannot register commands")
 		return
 	}
 
 	// For bot applications, the application ID is typically the bot's user ID
 	appID := s.State.User.ID
-	log.Infof("Registering commands for application ID: %s", appID)
+	logger().Infof("Registering commands for application ID: %s", appID)
 
 	// First, get existing commands to clean up any obsolete ones
 	existingCommands, err := s.ApplicationCommands(appID, "")
 	if err != nil {
-		log.Warnf("Failed to get existing commands: %v", err)
+		logger().Warnf("Failed to get existing commands: %v", err)
 	} else {
-		log.Infof("Found %d existing commands", len(existingCommands))
+		logger().Infof("Found %d existing commands", len(existingCommands))
 	}
 
 	commands := []*discordgo.ApplicationCommand{
@@ -38,8 +40,37 @@ func RegisterCommands(s *discordgo.Session) {
 				{
 					Type:        discordgo.ApplicationCommandOptionString,
 					Name:        "platforms",
-					Description: "Comma-separated list of platforms (pc,xbox,ps)",
+					Description: "Comma-separated list of platforms: pc, xbox, ps (or playstation), or all",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "tags",
+					Description: "Comma-separated list of news tags to post, e.g. patch-notes (default: all tags)",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionRole,
+					Name:        "ping_role",
+					Description: "Role to mention in news posts (default: none)",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "create_threads",
+					Description: "Start a discussion thread on each news post (default: false)",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "backfill",
+					Description: "Cached news to post to a new channel (default: none)",
 					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "None", Value: string(news.RegisterBackfillNone)},
+						{Name: "Recent", Value: string(news.RegisterBackfillRecent)},
+						{Name: "All", Value: string(news.RegisterBackfillAll)},
+					},
 				},
 			},
 		},
@@ -47,10 +78,266 @@ func RegisterCommands(s *discordgo.Session) {
 			Name:        "stobot_unregister",
 			Description: "Unregister this channel from STO news updates",
 		},
+		{
+			Name:        "stobot_migrate_channel",
+			Description: "Move a channel's registration and posting history to another channel (Admin only)",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "old_channel",
+					Description: "ID of the registered channel, e.g. one that was deleted",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "new_channel",
+					Description: "ID or mention of the channel to post news to from now on",
+					Required:    true,
+				},
+			},
+		},
 		{
 			Name:        "stobot_status",
 			Description: "Show bot status and registered channels",
 		},
+		{
+			Name:        "stobot_set_tags",
+			Description: "Choose which news tags are posted to this channel",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "tags",
+					Description: "Comma-separated list of tags, e.g. patch-notes,events (leave empty for all tags)",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "stobot_set_ping_role",
+			Description: "Mention a role in this channel's news posts",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionRole,
+					Name:        "role",
+					Description: "Role to mention (leave empty to stop mentioning a role)",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "stobot_set_webhook",
+			Description: "Post this channel's news through a webhook instead of the bot",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "url",
+					Description: "Webhook URL of this channel (leave empty to post as the bot again)",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "stobot_set_quiet_hours",
+			Description: "Hold this channel's news posts back during the night",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "start",
+					Description: "Hour quiet hours begin at, 0-23 UTC (leave both empty to turn quiet hours off)",
+					Required:    false,
+					MinValue:    &hourOptionMin,
+					MaxValue:    23,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "end",
+					Description: "Hour quiet hours end and held posts are sent, 0-23 UTC",
+					Required:    false,
+					MinValue:    &hourOptionMin,
+					MaxValue:    23,
+				},
+			},
+		},
+		{
+			Name:        "stobot_set_locale",
+			Description: "Choose the language this channel's news is posted in",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "locale",
+					Description: "Language of the news posts",
+					Required:    true,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "English", Value: "en"},
+						{Name: "Deutsch", Value: "de"},
+						{Name: "Français", Value: "fr"},
+					},
+				},
+			},
+		},
+		{
+			Name:        "stobot_exclude_tags",
+			Description: "Never post news with these tags to this channel",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "tags",
+					Description: "Comma-separated list of tags, e.g. dev-blogs (leave empty to clear)",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "stobot_spoiler_tags",
+			Description: "Hide summaries of articles with these tags in this channel",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "tags",
+					Description: "Comma-separated list of tags (leave empty to disable)",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "stobot_auto_publish",
+			Description: "Publish news posts in this announcement channel to following servers",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "enabled",
+					Description: "Whether to publish news posts automatically (default: true)",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "stobot_strict_patch_notes",
+			Description: "Only post patch notes whose title matches this channel's platforms",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "enabled",
+					Description: "Whether to skip patch notes titled for other platforms (default: true)",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "stobot_set_threads",
+			Description: "Start a discussion thread on each news post in this channel",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "enabled",
+					Description: "Whether to start a thread on news posts (default: true)",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "stobot_set_max_posts",
+			Description: "Limit how many news posts this channel gets per poll, spreading a backlog over later polls",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "max",
+					Description: "Posts per poll (0 or empty uses the bot's default)",
+					Required:    false,
+					MinValue:    &maxPostsOptionMin,
+					MaxValue:    50,
+				},
+			},
+		},
+		{
+			Name:        "stobot_set_update_notices",
+			Description: "Choose how this channel is told about articles updated after they were posted",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "mode",
+					Description: "What to do when an article posted here is updated",
+					Required:    true,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Post a notice", Value: types.UpdateNoticesPost},
+						{Name: "Edit the post", Value: types.UpdateNoticesEdit},
+						{Name: "Off", Value: types.UpdateNoticesOff},
+					},
+				},
+			},
+		},
+		{
+			Name:        "stobot_set_galleries",
+			Description: "Show the screenshots of news articles as a gallery in this channel",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "enabled",
+					Description: "Whether to show image galleries on news posts (default: true)",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "stobot_set_style",
+			Description: "Choose whether news is posted here as an embed or a single line",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "style",
+					Description: "How news posts look in this channel",
+					Required:    true,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Embed", Value: types.MessageStyleEmbed},
+						{Name: "Compact", Value: types.MessageStyleCompact},
+					},
+				},
+			},
+		},
+		{
+			Name:        "stobot_set_events",
+			Description: "Create Discord events for the in-game events announced in this channel",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "enabled",
+					Description: "Whether to create scheduled events for event articles (default: true)",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "stobot_pause",
+			Description: "Pause news posting in this channel, keeping its settings",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "hold",
+					Description: "Hold news released while paused and post it on resume (default: false, skip it)",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "stobot_resume",
+			Description: "Resume news posting in this channel",
+		},
+		{
+			Name:        "stobot_settings",
+			Description: "Show and edit this channel's news settings",
+		},
+		{
+			Name:        "stobot_setup",
+			Description: "Check that this channel is set up to receive news (Manage Channels)",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "test_post",
+					Description: "Send and delete a test message to check posting (default: false)",
+					Required:    false,
+				},
+			},
+		},
 		{
 			Name:        "stobot_news",
 			Description: "Get recent Star Trek Online news",
@@ -81,6 +368,82 @@ func RegisterCommands(s *discordgo.Session) {
 				},
 			},
 		},
+		{
+			Name:        "stobot_news_since",
+			Description: "Show cached news updated on or after a date",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "date",
+					Description: "Date in YYYY-MM-DD format",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "tag",
+					Description: "News category",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "General", Value: "star-trek-online"},
+						{Name: "Patch Notes", Value: "patch-notes"},
+						{Name: "Events", Value: "events"},
+						{Name: "Dev Blogs", Value: "dev-blogs"},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "platform",
+					Description: "Platform",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "PC", Value: "pc"},
+						{Name: "Xbox", Value: "xbox"},
+						{Name: "PlayStation", Value: "ps"},
+					},
+				},
+			},
+		},
+		{
+			Name:        "stobot_news_between",
+			Description: "Show cached news updated between two dates",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "start",
+					Description: "First date in YYYY-MM-DD format",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "end",
+					Description: "Last date in YYYY-MM-DD format (inclusive)",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "tag",
+					Description: "News category",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "General", Value: "star-trek-online"},
+						{Name: "Patch Notes", Value: "patch-notes"},
+						{Name: "Events", Value: "events"},
+						{Name: "Dev Blogs", Value: "dev-blogs"},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "platform",
+					Description: "Platform",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "PC", Value: "pc"},
+						{Name: "Xbox", Value: "xbox"},
+						{Name: "PlayStation", Value: "ps"},
+					},
+				},
+			},
+		},
 		{
 			Name:        "stobot_news_stats",
 			Description: "Show database statistics and popular topics",
@@ -110,6 +473,37 @@ func RegisterCommands(s *discordgo.Session) {
 				},
 			},
 		},
+		{
+			Name:        "stobot_export_channels",
+			Description: "Export this server's registered channels for backup (Manage Server)",
+		},
+		{
+			Name:        "stobot_export_stats",
+			Description: "Export posting statistics as a CSV file (Manage Server)",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "period",
+					Description: "Time period to export",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Last 7 days", Value: "7d"},
+						{Name: "Last 30 days", Value: "30d"},
+						{Name: "Last 90 days", Value: "90d"},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "scope",
+					Description: "Export this channel or all registered channels in this server",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "This channel", Value: "channel"},
+						{Name: "This server", Value: "guild"},
+					},
+				},
+			},
+		},
 		{
 			Name:        "stobot_engagement_report",
 			Description: "Show detailed engagement statistics (Admin only)",
@@ -122,6 +516,179 @@ func RegisterCommands(s *discordgo.Session) {
 			Name:        "stobot_game_status",
 			Description: "Check Star Trek Online server status",
 		},
+		{
+			Name:        "stobot_search_news",
+			Description: "Search cached news by title, summary and content",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "query",
+					Description: "Text to search for",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "limit",
+					Description: "Number of results to return (1-10, default: 5)",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "stobot_search_tags",
+			Description: "Find cached news with any of the given tags",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "tags",
+					Description:  "Comma-separated list of tags, e.g. patch-notes,events",
+					Required:     true,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "limit",
+					Description: "Number of results to return (1-25, default: 10)",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "stobot_digest",
+			Description: "Summarize the news posted in the last 7 days",
+		},
+		{
+			Name:        "stobot_preview",
+			Description: "Show how an article would be posted, without posting it",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "article",
+					Description: "News ID or article URL",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "stobot_post",
+			Description: "Post an article to this channel, e.g. one the bot missed (Admin only)",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "article",
+					Description: "News ID or article URL",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "force",
+					Description: "Post the article even if it was already posted to this channel",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "stobot_read",
+			Description: "Read the full text of an article",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "article",
+					Description: "News ID or article URL",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "stobot_subscribe",
+			Description: "Get news with some tags by direct message, or show your subscription",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "tags",
+					Description: "Tags to get news for (comma-separated)",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "platforms",
+					Description: "Platforms to get news for (comma-separated: pc,xbox,ps; default: all)",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "stobot_unsubscribe",
+			Description: "Stop getting news by direct message",
+		},
+		{
+			Name:        "stobot_catch_me_up",
+			Description: "Show the news posted here since you last caught up",
+		},
+		{
+			Name:        "stobot_digest_schedule",
+			Description: "Post a weekly digest in this channel instead of a message per article",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "day",
+					Description: "Day to post the digest on, or off to post articles individually",
+					Required:    true,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Monday", Value: "monday"},
+						{Name: "Tuesday", Value: "tuesday"},
+						{Name: "Wednesday", Value: "wednesday"},
+						{Name: "Thursday", Value: "thursday"},
+						{Name: "Friday", Value: "friday"},
+						{Name: "Saturday", Value: "saturday"},
+						{Name: "Sunday", Value: "sunday"},
+						{Name: "Off", Value: "off"},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "hour",
+					Description: "Hour to post the digest at, 0-23 UTC (default: 0)",
+					Required:    false,
+					MinValue:    &hourOptionMin,
+					MaxValue:    23,
+				},
+			},
+		},
+		{
+			Name:        "stobot_trending",
+			Description: "Show trending news tags and their latest articles",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "period",
+					Description: "Time period",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Last 7 days", Value: "week"},
+						{Name: "Last 30 days", Value: "month"},
+						{Name: "Last 90 days", Value: "quarter"},
+					},
+				},
+			},
+		},
+		{
+			Name:        "stobot_random_news",
+			Description: "Show a random article from the news archive",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "platform",
+					Description: "Platform",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "PC", Value: "pc"},
+						{Name: "Xbox", Value: "xbox"},
+						{Name: "PlayStation", Value: "ps"},
+					},
+				},
+			},
+		},
 		{
 			Name:        "stobot_advanced_search",
 			Description: "Advanced search with operators and filters",
@@ -223,7 +790,7 @@ func RegisterCommands(s *discordgo.Session) {
 		},
 	}
 
-	log.Infof("Starting to register %d commands...", len(commands))
+	logger().Infof("Starting to register %d commands...", len(commands))
 
 	// Create a map of current command names for comparison
 	currentCommandNames := make(map[string]bool)
@@ -234,49 +801,94 @@ func RegisterCommands(s *discordgo.Session) {
 	// Remove commands that are no longer in our current list
 	for _, existingCmd := range existingCommands {
 		if !currentCommandNames[existingCmd.Name] {
-			log.Infof("Removing obsolete command: %s", existingCmd.Name)
+			logger().Infof("Removing obsolete command: %s", existingCmd.Name)
 			err := s.ApplicationCommandDelete(appID, "", existingCmd.ID)
 			if err != nil {
-				log.Warnf("Failed to delete obsolete command %s: %v", existingCmd.Name, err)
+				logger().Warnf("Failed to delete obsolete command %s: %v", existingCmd.Name, err)
 			} else {
-				log.Infof("Successfully removed obsolete command: %s", existingCmd.Name)
+				logger().Infof("Successfully removed obsolete command: %s", existingCmd.Name)
 			}
 		}
 	}
 
 	successCount := 0
 	for i, command := range commands {
-		log.Infof("Registering command %d/%d: %s", i+1, len(commands), command.Name)
+		logger().Infof("Registering command %d/%d: %s", i+1, len(commands), command.Name)
 
 		// Register as global commands using the application ID
 		createdCmd, err := s.ApplicationCommandCreate(appID, "", command)
 		if err != nil {
-			log.Errorf("Failed to register command %s: %v", command.Name, err)
+			logger().Errorf("Failed to register command %s: %v", command.Name, err)
 			// Continue registering other commands even if one fails
 		} else {
-			log.Infof("Successfully registered command: %s (ID: %s)", command.Name, createdCmd.ID)
+			logger().Infof("Successfully registered command: %s (ID: %s)", command.Name, createdCmd.ID)
 			successCount++
 		}
 	}
 
-	log.Infof("Command registration completed: %d/%d commands registered successfully", successCount, len(commands))
+	logger().Infof("Command registration completed: %d/%d commands registered successfully", successCount, len(commands))
 }
 
 // HandleCommand routes slash command interactions to their handlers
 func HandleCommand(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
 	if b == nil || s == nil || i == nil || i.Interaction == nil {
-		log.Warn("HandleCommand called with nil parameters")
+		logger().Warn("HandleCommand called with nil parameters")
 		return
 	}
 
 	data := i.ApplicationCommandData()
+	if !checkCommandCooldown(b, s, i, data.Name) {
+		return
+	}
+	recordCommandUsage(b, i, data.Name)
+
 	switch data.Name {
 	case "stobot_register":
 		handleRegister(b, s, i)
 	case "stobot_unregister":
 		handleUnregister(b, s, i)
+	case "stobot_migrate_channel":
+		handleMigrateChannel(b, s, i)
 	case "stobot_status":
 		handleStatus(b, s, i)
+	case "stobot_set_tags":
+		handleSetTags(b, s, i)
+	case "stobot_set_ping_role":
+		handleSetPingRole(b, s, i)
+	case "stobot_set_webhook":
+		handleSetWebhook(b, s, i)
+	case "stobot_set_quiet_hours":
+		handleSetQuietHours(b, s, i)
+	case "stobot_set_locale":
+		handleSetLocale(b, s, i)
+	case "stobot_exclude_tags":
+		handleExcludeTags(b, s, i)
+	case "stobot_spoiler_tags":
+		handleSpoilerTags(b, s, i)
+	case "stobot_auto_publish":
+		handleAutoPublish(b, s, i)
+	case "stobot_strict_patch_notes":
+		handleStrictPatchNotes(b, s, i)
+	case "stobot_set_threads":
+		handleSetThreads(b, s, i)
+	case "stobot_set_max_posts":
+		handleSetMaxPosts(b, s, i)
+	case "stobot_set_update_notices":
+		handleSetUpdateNotices(b, s, i)
+	case "stobot_set_galleries":
+		handleSetGalleries(b, s, i)
+	case "stobot_set_style":
+		handleSetStyle(b, s, i)
+	case "stobot_set_events":
+		handleSetEvents(b, s, i)
+	case "stobot_pause":
+		handlePause(b, s, i)
+	case "stobot_resume":
+		handleResume(b, s, i)
+	case "stobot_settings":
+		handleSettings(b, s, i)
+	case "stobot_setup":
+		handleSetup(b, s, i)
 	case "stobot_news":
 		tag := "star-trek-online" // default
 		if len(data.Options) > 0 {
@@ -288,6 +900,10 @@ func HandleCommand(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionC
 			}
 		}
 		handleNews(b, s, i, tag)
+	case "stobot_news_since":
+		handleNewsSince(b, s, i)
+	case "stobot_news_between":
+		handleNewsBetween(b, s, i)
 	case "stobot_news_stats":
 		handleNewsStats(b, s, i)
 	case "stobot_server_stats":
@@ -298,10 +914,38 @@ func HandleCommand(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionC
 		handleTagTrends(b, s, i)
 	case "stobot_engagement_report":
 		handleEngagementReport(b, s, i)
+	case "stobot_export_channels":
+		handleExportChannels(b, s, i)
+	case "stobot_export_stats":
+		handleExportStats(b, s, i)
 	case "stobot_help":
 		handleHelp(b, s, i)
 	case "stobot_game_status":
 		handleGameStatus(b, s, i)
+	case "stobot_search_news":
+		handleSearchNews(b, s, i)
+	case "stobot_search_tags":
+		handleSearchTags(b, s, i)
+	case "stobot_digest":
+		handleDigest(b, s, i)
+	case "stobot_preview":
+		handlePreview(b, s, i)
+	case "stobot_post":
+		handlePost(b, s, i)
+	case "stobot_read":
+		handleRead(b, s, i)
+	case "stobot_subscribe":
+		handleSubscribe(b, s, i)
+	case "stobot_unsubscribe":
+		handleUnsubscribe(b, s, i)
+	case "stobot_catch_me_up":
+		handleCatchMeUp(b, s, i)
+	case "stobot_digest_schedule":
+		handleDigestSchedule(b, s, i)
+	case "stobot_trending":
+		handleTrending(b, s, i)
+	case "stobot_random_news":
+		handleRandomNews(b, s, i)
 	case "stobot_advanced_search":
 		handleAdvancedSearchNews(b, s, i)
 	case "stobot_fuzzy_search":
@@ -311,14 +955,44 @@ func HandleCommand(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionC
 	}
 }
 
+// HandleComponent routes message component interactions, such as button clicks, by custom ID.
+func HandleComponent(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if b == nil || s == nil || i == nil || i.Interaction == nil {
+		logger().Warn("HandleComponent called with nil parameters")
+		return
+	}
+
+	customID := i.MessageComponentData().CustomID
+	switch {
+	case strings.HasPrefix(customID, searchPagePrefix+":"):
+		handleSearchPage(s, i)
+	case strings.HasPrefix(customID, settingsPrefix+":"):
+		handleSettingsComponent(b, s, i)
+	default:
+		logger().Warnf("Unknown message component: %s", customID)
+	}
+}
+
 // handleHelp handles the "help" command interaction
 func handleHelp(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
 	helpText := "**Star Trek Online News Bot**\n\n" +
 		"**📰 Basic Commands:**\n" +
 		"• `/stobot_news [tag] [platforms] [weeks]` - Get recent STO news\n" +
+		"• `/stobot_news_since <date> [tag] [platform]` - Cached news since a date (YYYY-MM-DD)\n" +
+		"• `/stobot_news_between <start> <end> [tag] [platform]` - Cached news between two dates\n" +
 		"• `/stobot_status` - Show bot status and settings\n" +
-		"• `/stobot_game_status` - Check Star Trek Online server status\n\n" +
+		"• `/stobot_game_status` - Check Star Trek Online server status\n" +
+		"• `/stobot_subscribe [tags] [platforms]` - Get news with these tags by direct message (no options: show yours)\n" +
+		"• `/stobot_unsubscribe` - Stop getting news by direct message\n" +
+		"• `/stobot_catch_me_up` - News posted here since you last caught up (first time: last 7 days)\n\n" +
 		"**🔍 Search & Discovery:**\n" +
+		"• `/stobot_search_news <query> [limit]` - Search news titles, summaries and content\n" +
+		"• `/stobot_search_tags <tags> [limit]` - Cached news with any of these tags\n" +
+		"• `/stobot_digest` - Summary of the news posted in the last 7 days\n" +
+		"• `/stobot_preview <article>` - Preview how an article would be posted (ID or URL)\n" +
+		"• `/stobot_read <article>` - Read the full text of an article (ID or URL)\n" +
+		"• `/stobot_trending [period]` - Trending tags and their latest articles\n" +
+		"• `/stobot_random_news [platform]` - A random article from the archive\n" +
 		"• `/stobot_advanced_search <query> [limit]` - Advanced search with operators\n" +
 		"• `/stobot_fuzzy_search <query> [limit]` - Find similar articles\n" +
 		"• `/stobot_filtered_search [options]` - Search with filters and sorting\n\n" +
@@ -328,15 +1002,40 @@ func handleHelp(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCrea
 		"• **Excluded:** -word (must not contain)\n" +
 		"• **Tags:** tag:events, tag:patch-notes\n" +
 		"• **Platforms:** platform:pc, platform:xbox\n" +
+		"• **Authors:** author:ambassador, author:\"Jane Doe\"\n" +
 		"• **Date filters:** after:2023-01-01, before:2023-12-31\n\n" +
 		"**📊 Analytics & Stats:**\n" +
 		"• `/stobot_news_stats` - Database statistics\n" +
 		"• `/stobot_server_stats` - Server engagement stats\n" +
 		"• `/stobot_popular_this_week` - Most engaged articles\n" +
-		"• `/stobot_tag_trends [period]` - Trending tags over time\n\n" +
+		"• `/stobot_tag_trends [period]` - Trending tags over time\n" +
+		"• `/stobot_export_stats [period] [scope]` - Export posting statistics as CSV (Manage Server)\n" +
+		"• `/stobot_export_channels` - Export this server's registered channels (Manage Server)\n\n" +
 		"**⚙️ Admin Commands:**\n" +
-		"• `/stobot_register [platforms]` - Register this channel for STO news updates\n" +
+		"• `/stobot_register [platforms] [tags] [ping_role] [create_threads] [backfill]` - Register this channel for STO news updates\n" +
+		"• `/stobot_setup [test_post]` - Check this channel's setup end to end (Manage Channels)\n" +
 		"• `/stobot_unregister` - Unregister this channel from news updates\n" +
+		"• `/stobot_migrate_channel <old_channel> <new_channel>` - Move a registration and its history to a recreated channel\n" +
+		"• `/stobot_post <article> [force]` - Post an article to this channel, e.g. one the bot missed\n" +
+		"• `/stobot_set_tags [tags]` - Only post news with these tags (empty for all tags)\n" +
+		"• `/stobot_set_ping_role [role]` - Mention a role in news posts (empty to stop)\n" +
+		"• `/stobot_set_webhook [url]` - Post news through a webhook of this channel (empty to stop)\n" +
+		"• `/stobot_set_quiet_hours [start] [end]` - Hold news posts back between two UTC hours (empty to stop)\n" +
+		"• `/stobot_set_locale <locale>` - Post news in English, German or French\n" +
+		"• `/stobot_exclude_tags [tags]` - Never post news with these tags (empty to clear)\n" +
+		"• `/stobot_digest_schedule <day> [hour]` - Post a weekly digest instead of each article (off to stop)\n" +
+		"• `/stobot_spoiler_tags [tags]` - Hide summaries of articles with these tags\n" +
+		"• `/stobot_auto_publish [enabled]` - Publish news posts in announcement channels\n" +
+		"• `/stobot_strict_patch_notes [enabled]` - Skip patch notes titled for other platforms\n" +
+		"• `/stobot_set_threads [enabled]` - Start a discussion thread on each news post\n" +
+		"• `/stobot_set_max_posts [max]` - Limit news posts per poll, posting a backlog over later polls\n" +
+		"• `/stobot_set_update_notices <mode>` - Post a notice, edit the post or do nothing when an article is updated\n" +
+		"• `/stobot_set_galleries [enabled]` - Show the screenshots of articles as a gallery below news posts\n" +
+		"• `/stobot_set_style <style>` - Post news as an embed or as a single line with the article link\n" +
+		"• `/stobot_set_events [enabled]` - Create a server event for each in-game event announced here\n" +
+		"• `/stobot_pause [hold]` - Pause news posting here, skipping or holding news until resumed\n" +
+		"• `/stobot_resume` - Resume news posting here\n" +
+		"• `/stobot_settings` - Show and edit this channel's news settings in one panel\n" +
 		"• `/stobot_engagement_report` - Detailed usage statistics (Admin only)\n\n" +
 		"**Platforms:** pc, xbox, ps (comma-separated)\n" +
 		"**News Tags:** star-trek-online, patch-notes, events, dev-blogs\n\n" +
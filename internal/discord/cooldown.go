@@ -0,0 +1,155 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Defaults for the cooldown of expensive searches.
+const (
+	DefaultSearchCooldownUses    = 3  // DefaultSearchCooldownUses is how many expensive searches a user may run per window.
+	DefaultSearchCooldownSeconds = 60 // DefaultSearchCooldownSeconds is the length of the window in seconds.
+)
+
+// cooldownCleanupInterval is how often uses older than the cooldown window are forgotten.
+const cooldownCleanupInterval = time.Minute
+
+// expensiveCommands are the commands that scan the whole news cache, and are limited per user
+// by the search cooldown.
+var expensiveCommands = map[string]bool{
+	"stobot_search_news":     true,
+	"stobot_search_tags":     true,
+	"stobot_advanced_search": true,
+	"stobot_fuzzy_search":    true,
+	"stobot_filtered_search": true,
+}
+
+// cooldownKey identifies the uses of one command by one user.
+type cooldownKey struct {
+	userID  string
+	command string
+}
+
+// commandCooldowns remembers when users ran commands, so each user may run a command a limited
+// number of times per window. It is safe for concurrent use.
+type commandCooldowns struct {
+	now func() time.Time // now is the clock uses are timed by (replaced in tests).
+
+	mu   sync.Mutex
+	uses map[cooldownKey][]time.Time // uses are the times of the uses still in the window, oldest first.
+}
+
+// newCommandCooldowns returns an empty cooldown tracker.
+func newCommandCooldowns() *commandCooldowns {
+	return &commandCooldowns{
+		now:  time.Now,
+		uses: make(map[cooldownKey][]time.Time),
+	}
+}
+
+// searchCooldowns tracks the expensive searches of all users.
+var searchCooldowns = newCommandCooldowns()
+
+// allow records a use of command by userID if they used it fewer than limit times in the last
+// window, and reports whether they may. Otherwise nothing is recorded and wait is how long until
+// the oldest use leaves the window. A limit or window of 0 allows every use.
+func (c *commandCooldowns) allow(userID, command string, limit int, window time.Duration) (ok bool, wait time.Duration) {
+	if limit <= 0 || window <= 0 {
+		return true, 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	key := cooldownKey{userID: userID, command: command}
+	uses := unexpiredUses(c.uses[key], now.Add(-window))
+	if len(uses) >= limit {
+		c.uses[key] = uses
+		return false, uses[len(uses)-limit].Add(window).Sub(now)
+	}
+	c.uses[key] = append(uses, now)
+	return true, 0
+}
+
+// cleanup forgets the uses older than window, and the users without uses left.
+func (c *commandCooldowns) cleanup(window time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cutoff := c.now().Add(-window)
+	for key, uses := range c.uses {
+		if uses = unexpiredUses(uses, cutoff); len(uses) == 0 {
+			delete(c.uses, key)
+		} else {
+			c.uses[key] = uses
+		}
+	}
+}
+
+// size returns the number of users and commands with uses remembered.
+func (c *commandCooldowns) size() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.uses)
+}
+
+// unexpiredUses returns the uses after cutoff. uses are oldest first.
+func unexpiredUses(uses []time.Time, cutoff time.Time) []time.Time {
+	for i, use := range uses {
+		if use.After(cutoff) {
+			return uses[i:]
+		}
+	}
+	return nil
+}
+
+// RunCommandCooldownCleanup forgets expired search cooldowns every minute until ctx is done,
+// so users who stopped searching do not stay in memory.
+func RunCommandCooldownCleanup(ctx context.Context, b *types.Bot) {
+	ticker := time.NewTicker(cooldownCleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			searchCooldowns.cleanup(searchCooldownWindow(b))
+		}
+	}
+}
+
+// searchCooldownWindow returns the configured search cooldown window.
+func searchCooldownWindow(b *types.Bot) time.Duration {
+	return time.Duration(b.Config.SearchCooldownSeconds) * time.Second
+}
+
+// checkCommandCooldown reports whether the invoking user may run command now. Commands other
+// than expensive searches and administrators are not limited. When the user is over the limit,
+// they are told how long to wait.
+func checkCommandCooldown(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate, command string) bool {
+	if !expensiveCommands[command] || b.Config == nil {
+		return true
+	}
+	userID := interactionUserID(i)
+	if userID == "" {
+		return true
+	}
+
+	ok, wait := searchCooldowns.allow(userID, command, b.Config.SearchCooldownUses, searchCooldownWindow(b))
+	if ok || hasAdminPermission(s, i) {
+		return true
+	}
+
+	logger().Debugf("User %s is on cooldown for /%s for %v", userID, command, wait)
+	seconds := int(math.Ceil(wait.Seconds()))
+	Respond(s, i, fmt.Sprintf("⏳ You are searching too often. Please wait %ds before running `/%s` again.", seconds, command))
+	return false
+}
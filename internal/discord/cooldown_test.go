@@ -0,0 +1,166 @@
+package discord
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestCommandCooldownsAllow(t *testing.T) {
+	cooldowns := newCommandCooldowns()
+	start := time.Date(2024, 6, 11, 12, 0, 0, 0, time.UTC)
+	clock := start
+	cooldowns.now = func() time.Time { return clock }
+
+	for use := range 3 {
+		clock = start.Add(time.Duration(use) * 10 * time.Second)
+		if ok, _ := cooldowns.allow("user-1", "stobot_search_news", 3, time.Minute); !ok {
+			t.Fatalf("Expected use %d to be allowed", use+1)
+		}
+	}
+
+	// The fourth use waits for the first to leave the window
+	clock = start.Add(30 * time.Second)
+	ok, wait := cooldowns.allow("user-1", "stobot_search_news", 3, time.Minute)
+	if ok {
+		t.Fatal("Expected the fourth use in a minute to be refused")
+	}
+	if wait != 30*time.Second {
+		t.Errorf("Expected to wait 30s, got %v", wait)
+	}
+
+	// Other users and commands have their own cooldowns
+	if ok, _ := cooldowns.allow("user-2", "stobot_search_news", 3, time.Minute); !ok {
+		t.Error("Expected another user to be allowed")
+	}
+	if ok, _ := cooldowns.allow("user-1", "stobot_fuzzy_search", 3, time.Minute); !ok {
+		t.Error("Expected another command to be allowed")
+	}
+
+	// Refused uses are not recorded
+	clock = start.Add(time.Minute + time.Second)
+	if ok, _ := cooldowns.allow("user-1", "stobot_search_news", 3, time.Minute); !ok {
+		t.Error("Expected a use to be allowed once the first left the window")
+	}
+
+	// A limit of 0 disables the cooldown
+	for range 10 {
+		if ok, _ := cooldowns.allow("user-3", "stobot_search_news", 0, time.Minute); !ok {
+			t.Fatal("Expected every use to be allowed without a limit")
+		}
+	}
+}
+
+func TestCommandCooldownsCleanup(t *testing.T) {
+	cooldowns := newCommandCooldowns()
+	start := time.Date(2024, 6, 11, 12, 0, 0, 0, time.UTC)
+	clock := start
+	cooldowns.now = func() time.Time { return clock }
+
+	cooldowns.allow("user-1", "stobot_search_news", 3, time.Minute)
+	clock = start.Add(45 * time.Second)
+	cooldowns.allow("user-2", "stobot_search_news", 3, time.Minute)
+
+	clock = start.Add(90 * time.Second)
+	cooldowns.cleanup(time.Minute)
+	if size := cooldowns.size(); size != 1 {
+		t.Errorf("Expected only user-2 to be remembered, got %d entries", size)
+	}
+
+	clock = start.Add(2 * time.Minute)
+	cooldowns.cleanup(time.Minute)
+	if size := cooldowns.size(); size != 0 {
+		t.Errorf("Expected nothing to be remembered, got %d entries", size)
+	}
+}
+
+func TestCommandCooldownsConcurrent(t *testing.T) {
+	cooldowns := newCommandCooldowns()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := make(map[string]int)
+	for worker := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			userID := []string{"user-1", "user-2"}[worker%2]
+			for range 10 {
+				if ok, _ := cooldowns.allow(userID, "stobot_advanced_search", 3, time.Hour); ok {
+					mu.Lock()
+					allowed[userID]++
+					mu.Unlock()
+				}
+				cooldowns.cleanup(time.Hour)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, userID := range []string{"user-1", "user-2"} {
+		if allowed[userID] != 3 {
+			t.Errorf("Expected exactly 3 searches allowed for %s, got %d", userID, allowed[userID])
+		}
+	}
+}
+
+func TestHandleCommandSearchCooldown(t *testing.T) {
+	original := searchCooldowns
+	t.Cleanup(func() { searchCooldowns = original })
+
+	tests := []struct {
+		name     string
+		userID   string
+		expected int // expected is the number of searches refused.
+	}{
+		{"member", "user-1", 1},
+		{"administrator", "owner-1", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			searchCooldowns = newCommandCooldowns()
+			bot := testhelpers.CreateTestBot(t)
+			defer bot.DB.Close()
+			bot.Config.SearchCooldownUses = 2
+			bot.Config.SearchCooldownSeconds = 60
+			fake := testhelpers.NewFakeDiscord(t)
+			bot.Session = fake.Session()
+			fake.Handle("GET", "/guilds/guild-1", func(w http.ResponseWriter, r *http.Request) {
+				testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "guild-1", "owner_id": "owner-1"})
+			})
+
+			for range 3 {
+				interaction := discoveryInteraction("stobot_search_news", stringOption("query", "season"))
+				interaction.Member = &discordgo.Member{User: &discordgo.User{ID: tt.userID}}
+				HandleCommand(bot, bot.Session, interaction)
+			}
+			usageWriters.Wait()
+
+			refused := 0
+			for _, call := range fake.RequestsTo("POST", "/interactions/interaction-1/interaction-token/callback") {
+				if strings.Contains(string(call.Body), "Please wait 60s") {
+					refused++
+				}
+			}
+			if refused != tt.expected {
+				t.Errorf("Expected %d searches refused, got %d", tt.expected, refused)
+			}
+
+			// Other commands are not limited
+			interaction := discoveryInteraction("stobot_help")
+			interaction.Member = &discordgo.Member{User: &discordgo.User{ID: tt.userID}}
+			HandleCommand(bot, bot.Session, interaction)
+			usageWriters.Wait()
+			calls := fake.RequestsTo("POST", "/interactions/interaction-1/interaction-token/callback")
+			if body := string(calls[len(calls)-1].Body); strings.Contains(body, "Please wait") {
+				t.Errorf("Expected help not to be limited, got %s", body)
+			}
+		})
+	}
+}
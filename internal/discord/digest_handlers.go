@@ -0,0 +1,146 @@
+package discord
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/news"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// digestDays maps the /stobot_digest_schedule day choices to weekdays.
+var digestDays = map[string]time.Weekday{
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+	"sunday":    time.Sunday,
+}
+
+// hourOptionMin is the minimum of the UTC hour options, e.g. of /stobot_digest_schedule; MinValue takes a pointer.
+var hourOptionMin = 0.0
+
+// formatDigestSchedule returns a channel's digest schedule for display.
+func formatDigestSchedule(cfg types.ChannelConfig) string {
+	if !cfg.Digest {
+		return "off"
+	}
+	return fmt.Sprintf("%ss at %02d:00 UTC", cfg.DigestDay, cfg.DigestHour)
+}
+
+// handleDigest handles the "digest" command interaction
+func handleDigest(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Acknowledge interaction with timeout handling
+	if err := AcknowledgeWithRetry(s, i); err != nil {
+		logger().Errorf("Failed to acknowledge digest command: %v", err)
+		return
+	}
+
+	// Registered channels get their own posts; elsewhere the digest covers all channels
+	channelID := i.ChannelID
+	platforms, err := database.GetChannelPlatforms(b, channelID)
+	if err != nil {
+		channelLogger(channelID).Errorf("Failed to get channel platforms for %s: %v", channelID, err)
+		Followup(s, i, "❌ Failed to check channel status. Please try again later.")
+		return
+	}
+	scope := channelID
+	if len(platforms) == 0 {
+		scope = ""
+	}
+
+	now := time.Now()
+	since := now.Add(-news.DigestPeriod)
+	newsItems, err := database.GetDigestNews(b, scope, since)
+	if err != nil {
+		logger().Errorf("Failed to get digest news: %v", err)
+		Followup(s, i, "❌ Failed to build the digest. Please try again later.")
+		return
+	}
+
+	if len(newsItems) == 0 {
+		Followup(s, i, "📰 No news was posted in the last 7 days.")
+		return
+	}
+
+	// One embed per message keeps each message under Discord's total embed size limit
+	for _, embed := range news.BuildDigestEmbeds(b, newsItems, since, now) {
+		if err := FollowupWithEmbeds(s, i, "", []*discordgo.MessageEmbed{embed}); err != nil {
+			logger().Errorf("Failed to send digest: %v", err)
+			Followup(s, i, "❌ Failed to send the digest.")
+			return
+		}
+	}
+
+	channelLogger(channelID).Infof("Sent digest of %d articles to channel %s", len(newsItems), channelID)
+}
+
+// handleDigestSchedule handles the "digest_schedule" command interaction
+func handleDigestSchedule(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Validate inputs
+	if i == nil || i.Interaction == nil {
+		logger().Warning("handleDigestSchedule called with nil interaction")
+		return
+	}
+
+	// Check if user has administrator permission
+	if !hasAdminPermission(s, i) {
+		RespondError(s, i, "You need Administrator permission to use this command.")
+		return
+	}
+
+	day := "off"
+	hour := 0
+	for _, option := range i.ApplicationCommandData().Options {
+		switch option.Name {
+		case "day":
+			day = strings.ToLower(option.StringValue())
+		case "hour":
+			hour = int(option.IntValue())
+		}
+	}
+
+	weekday, enabled := digestDays[day]
+	if day != "off" && !enabled {
+		RespondError(s, i, fmt.Sprintf("Unknown day %q.", day))
+		return
+	}
+	if hour < 0 || hour > 23 {
+		RespondError(s, i, "The hour must be between 0 and 23 (UTC).")
+		return
+	}
+
+	channelID := i.ChannelID
+
+	platforms, err := database.GetChannelPlatforms(b, channelID)
+	if err != nil {
+		channelLogger(channelID).Errorf("Failed to get channel platforms for %s: %v", channelID, err)
+		RespondError(s, i, "Failed to check channel status. Please try again later.")
+		return
+	}
+	if len(platforms) == 0 {
+		RespondError(s, i, "This channel is not registered. Use `/stobot_register` first.")
+		return
+	}
+
+	if err := database.UpdateChannelDigest(b, channelID, enabled, weekday, hour); err != nil {
+		channelLogger(channelID).Errorf("Failed to update digest schedule for channel %s: %v", channelID, err)
+		RespondError(s, i, "Failed to update the digest schedule. Please try again later.")
+		return
+	}
+
+	cfg := types.ChannelConfig{Digest: enabled, DigestDay: weekday, DigestHour: hour}
+	channelLogger(channelID).Infof("Channel %s digest schedule set to %s", channelID, formatDigestSchedule(cfg))
+	if !enabled {
+		Respond(s, i, "✅ Digest mode disabled. New articles will be posted individually again.")
+		return
+	}
+	Respond(s, i, fmt.Sprintf("✅ Digest mode enabled. Instead of a message per article, this channel gets a weekly digest on %s.",
+		formatDigestSchedule(cfg)))
+}
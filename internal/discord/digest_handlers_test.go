@@ -0,0 +1,107 @@
+package discord
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// digestScheduleInteraction builds a /stobot_digest_schedule interaction by the guild owner.
+func digestScheduleInteraction(day string, hour int64) *discordgo.InteractionCreate {
+	interaction := discoveryInteraction("stobot_digest_schedule", stringOption("day", day),
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "hour", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(hour)})
+	interaction.Member = &discordgo.Member{User: &discordgo.User{ID: "owner-1"}}
+	return interaction
+}
+
+func TestDigestScheduleCommand(t *testing.T) {
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	fake := testhelpers.NewFakeDiscord(t)
+	bot.Session = fake.Session()
+	fake.Handle("GET", "/guilds/guild-1", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "guild-1", "owner_id": "owner-1"})
+	})
+	lastResponse := func() string {
+		t.Helper()
+		calls := fake.RequestsTo("POST", "/interactions/interaction-1/interaction-token/callback")
+		if len(calls) == 0 {
+			t.Fatal("Expected a response")
+		}
+		return string(calls[len(calls)-1].Body)
+	}
+
+	// Unregistered channels are rejected
+	handleDigestSchedule(bot, bot.Session, digestScheduleInteraction("monday", 9))
+	if response := lastResponse(); !strings.Contains(response, "not registered") {
+		t.Fatalf("Expected a not registered error, got %s", response)
+	}
+
+	if err := database.AddChannel(bot, "channel-a"); err != nil {
+		t.Fatalf("Failed to add channel: %v", err)
+	}
+
+	handleDigestSchedule(bot, bot.Session, digestScheduleInteraction("monday", 9))
+	cfg, err := database.GetChannelConfig(bot, "channel-a")
+	if err != nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if !cfg.Digest || cfg.DigestDay != time.Monday || cfg.DigestHour != 9 {
+		t.Errorf("Expected a Monday 09:00 digest, got %+v", cfg)
+	}
+	if response := lastResponse(); !strings.Contains(response, "Mondays at 09:00 UTC") {
+		t.Errorf("Expected the schedule in the response, got %s", response)
+	}
+
+	handleDigestSchedule(bot, bot.Session, digestScheduleInteraction("off", 0))
+	if cfg, _ = database.GetChannelConfig(bot, "channel-a"); cfg.Digest {
+		t.Error("Expected digest mode to be disabled")
+	}
+
+	handleDigestSchedule(bot, bot.Session, digestScheduleInteraction("monday", 24))
+	if response := lastResponse(); !strings.Contains(response, "between 0 and 23") {
+		t.Errorf("Expected an invalid hour error, got %s", response)
+	}
+}
+
+func TestDigestCommand(t *testing.T) {
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	fake := testhelpers.NewFakeDiscord(t)
+	bot.Session = fake.Session()
+	followups := func() []testhelpers.FakeDiscordRequest {
+		return fake.RequestsTo("POST", "/webhooks/app-1/interaction-token")
+	}
+
+	handleDigest(bot, bot.Session, discoveryInteraction("stobot_digest"))
+	if calls := followups(); len(calls) != 1 || !strings.Contains(string(calls[0].Body), "No news was posted") {
+		t.Fatalf("Expected an empty digest message, got %d followups", len(calls))
+	}
+
+	// An unregistered channel gets the news posted to any channel
+	if err := database.CacheNews(bot, []types.NewsItem{{ID: 1, Title: "Season Update", Tags: []string{"star-trek-online"}, Updated: time.Now()}}); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+	if err := database.AddChannel(bot, "channel-b"); err != nil {
+		t.Fatalf("Failed to add channel: %v", err)
+	}
+	if err := database.MarkNewsAsPosted(bot, 1, "channel-b"); err != nil {
+		t.Fatalf("Failed to mark news as posted: %v", err)
+	}
+
+	handleDigest(bot, bot.Session, discoveryInteraction("stobot_digest"))
+	calls := followups()
+	if len(calls) != 2 {
+		t.Fatalf("Expected a digest followup, got %d followups", len(calls))
+	}
+	if body := string(calls[1].Body); !strings.Contains(body, "Weekly STO News Digest") || !strings.Contains(body, "Season Update") {
+		t.Errorf("Expected the digest to list the article, got %s", body)
+	}
+}
@@ -0,0 +1,241 @@
+package discord
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// trendingTagCount is the number of tags listed by /stobot_trending.
+const trendingTagCount = 10
+
+// trendingArticleCount is the number of top tags /stobot_trending shows the latest article for.
+const trendingArticleCount = 3
+
+// handleTrending handles the "trending" command interaction
+func handleTrending(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Acknowledge interaction with timeout handling
+	if err := AcknowledgeWithRetry(s, i); err != nil {
+		logger().Errorf("Failed to acknowledge trending command: %v", err)
+		return
+	}
+
+	period := "week" // default
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "period" {
+			period = option.StringValue()
+		}
+	}
+	days, periodName := trendPeriod(period)
+
+	trendingTags, err := database.GetTrendingTags(b, days, trendingTagCount)
+	if err != nil {
+		logger().Errorf("Failed to get trending tags: %v", err)
+		Followup(s, i, "❌ Failed to get trending news. Please try again later.")
+		return
+	}
+
+	if len(trendingTags) == 0 {
+		Followup(s, i, fmt.Sprintf("📈 No trending news found for %s.", periodName))
+		return
+	}
+
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("📈 **Trending - %s**\n", periodName))
+	for rank, tagCount := range trendingTags {
+		content.WriteString(fmt.Sprintf("%d. **%s** (%d articles)\n", rank+1, tagCount.Tag, tagCount.Count))
+	}
+
+	// The latest article for each of the top tags
+	var embeds []*discordgo.MessageEmbed
+	seen := make(map[int64]bool)
+	for _, tagCount := range trendingTags[:min(trendingArticleCount, len(trendingTags))] {
+		newsItems, err := database.SearchNewsByTags(b, []string{tagCount.Tag}, 1)
+		if err != nil {
+			logger().Errorf("Failed to get latest news for tag %s: %v", tagCount.Tag, err)
+			continue
+		}
+		if len(newsItems) == 0 || seen[newsItems[0].ID] {
+			continue
+		}
+		seen[newsItems[0].ID] = true
+
+		embed := formatNewsEmbed(newsItems[0])
+		b.Config.RewriteEmbedURLs(embed)
+		embeds = append(embeds, embed)
+	}
+
+	if err := FollowupWithEmbeds(s, i, content.String(), embeds); err != nil {
+		logger().Errorf("Failed to send trending news: %v", err)
+		Followup(s, i, "❌ Failed to send trending news.")
+		return
+	}
+
+	logger().Infof("Sent trending news for %s", periodName)
+}
+
+// handleRandomNews handles the "random_news" command interaction
+func handleRandomNews(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Acknowledge interaction with timeout handling
+	if err := AcknowledgeWithRetry(s, i); err != nil {
+		logger().Errorf("Failed to acknowledge random_news command: %v", err)
+		return
+	}
+
+	var platform string
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "platform" {
+			platform = option.StringValue()
+		}
+	}
+
+	newsItem, err := database.GetRandomNews(b, platform)
+	if err != nil {
+		logger().Errorf("Failed to get random news: %v", err)
+		Followup(s, i, "❌ Failed to get a random article. Please try again later.")
+		return
+	}
+
+	if newsItem == nil {
+		if platform != "" {
+			Followup(s, i, fmt.Sprintf("🎲 No cached news found for platform %s.", platform))
+		} else {
+			Followup(s, i, "🎲 No cached news found yet.")
+		}
+		return
+	}
+
+	embed := formatNewsEmbed(*newsItem)
+	b.Config.RewriteEmbedURLs(embed)
+	if err := FollowupWithEmbeds(s, i, "🎲 **Random article from the archive**", []*discordgo.MessageEmbed{embed}); err != nil {
+		logger().Errorf("Failed to send random news: %v", err)
+		Followup(s, i, "❌ Failed to send the article.")
+		return
+	}
+
+	logger().Infof("Sent random news item %d", newsItem.ID)
+}
+
+// handleSearchNews handles the "search_news" command interaction
+func handleSearchNews(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Acknowledge interaction
+	if err := AcknowledgeWithRetry(s, i); err != nil {
+		logger().Errorf("Failed to acknowledge search_news command: %v", err)
+		return
+	}
+
+	// Parse command options
+	var query string
+	limit := 5
+
+	for _, option := range i.ApplicationCommandData().Options {
+		switch option.Name {
+		case "query":
+			query = strings.TrimSpace(option.StringValue())
+		case "limit":
+			if option.IntValue() > 0 && option.IntValue() <= MaxEmbedsPerMessage {
+				limit = int(option.IntValue())
+			}
+		}
+	}
+
+	if query == "" {
+		Followup(s, i, "❌ Search query is required.")
+		return
+	}
+
+	logger().Infof("Searching news for: %s (limit: %d)", query, limit)
+	results, err := database.SearchNewsContent(b, query, limit)
+	if err != nil {
+		logger().Errorf("Failed to search news: %v", err)
+		Followup(s, i, "❌ Failed to search news. Please try again later.")
+		return
+	}
+
+	if len(results) == 0 {
+		Followup(s, i, fmt.Sprintf("🔍 Nothing found for \"%s\".\n\n"+
+			"Try fewer or shorter words, check the spelling, or use `/stobot_fuzzy_search` for similar articles "+
+			"and `/stobot_advanced_search` for tag, platform and date filters.", query))
+		return
+	}
+
+	var embeds []*discordgo.MessageEmbed
+	for _, newsItem := range results {
+		embed := formatNewsEmbed(newsItem)
+		b.Config.RewriteEmbedURLs(embed)
+		embeds = append(embeds, embed)
+	}
+
+	content := fmt.Sprintf("🔍 **Search results for \"%s\"** (%d found)", query, len(results))
+	if err := FollowupWithEmbeds(s, i, content, embeds); err != nil {
+		logger().Errorf("Failed to send search results: %v", err)
+		Followup(s, i, "❌ Failed to send search results.")
+		return
+	}
+
+	logger().Infof("Sent %d search results", len(results))
+}
+
+// handleSearchTags handles the "search_tags" command interaction
+func handleSearchTags(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Acknowledge interaction
+	if err := AcknowledgeWithRetry(s, i); err != nil {
+		logger().Errorf("Failed to acknowledge search_tags command: %v", err)
+		return
+	}
+
+	// Parse command options
+	var tags []string
+	limit := 10
+
+	for _, option := range i.ApplicationCommandData().Options {
+		switch option.Name {
+		case "tags":
+			tags = parseTagList(option.StringValue())
+		case "limit":
+			if option.IntValue() > 0 && option.IntValue() <= 25 {
+				limit = int(option.IntValue())
+			}
+		}
+	}
+
+	if len(tags) == 0 {
+		Followup(s, i, "❌ At least one tag is required, e.g. `patch-notes,events`.")
+		return
+	}
+
+	logger().Infof("Searching news by tags: %v (limit: %d)", tags, limit)
+	results, err := database.SearchNewsByTags(b, tags, limit)
+	if err != nil {
+		logger().Errorf("Failed to search news by tags: %v", err)
+		Followup(s, i, "❌ Failed to search news. Please try again later.")
+		return
+	}
+
+	tagList := strings.Join(tags, ", ")
+	if len(results) == 0 {
+		Followup(s, i, fmt.Sprintf("🏷️ No cached news found with the tags %s. "+
+			"Use `/stobot_trending` to see which tags are in use.", tagList))
+		return
+	}
+
+	var embeds []*discordgo.MessageEmbed
+	for _, newsItem := range results {
+		embed := formatNewsEmbed(newsItem)
+		b.Config.RewriteEmbedURLs(embed)
+		embeds = append(embeds, embed)
+	}
+
+	content := fmt.Sprintf("🏷️ **News tagged %s** (%d found)", tagList, len(results))
+	if err := FollowupWithPages(s, i, content, embeds); err != nil {
+		logger().Errorf("Failed to send tag search results: %v", err)
+		Followup(s, i, "❌ Failed to send search results.")
+		return
+	}
+
+	logger().Infof("Sent %d tag search results", len(results))
+}
@@ -0,0 +1,135 @@
+package discord
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// discoveryInteraction builds a command interaction in channel-a with the given options.
+func discoveryInteraction(name string, options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			ID:        "interaction-1",
+			AppID:     "app-1",
+			Token:     "interaction-token",
+			Type:      discordgo.InteractionApplicationCommand,
+			GuildID:   "guild-1",
+			ChannelID: "channel-a",
+			Data: discordgo.ApplicationCommandInteractionData{
+				Name:    name,
+				Options: options,
+			},
+		},
+	}
+}
+
+func stringOption(name, value string) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: discordgo.ApplicationCommandOptionString, Value: value}
+}
+
+func TestDiscoveryCommands(t *testing.T) {
+	tests := []struct {
+		name        string
+		interaction *discordgo.InteractionCreate
+		empty       bool
+		expected    string
+		embeds      int
+	}{
+		{
+			name:        "search finds matching articles",
+			interaction: discoveryInteraction("stobot_search_news", stringOption("query", "borg")),
+			expected:    "Search results for \"borg\"** (2 found)",
+			embeds:      2,
+		},
+		{
+			name:        "search with no results",
+			interaction: discoveryInteraction("stobot_search_news", stringOption("query", "tribbles")),
+			expected:    "Nothing found for \"tribbles\"",
+		},
+		{
+			name:        "tag search finds news with any tag",
+			interaction: discoveryInteraction("stobot_search_tags", stringOption("tags", " Events, patch-notes,")),
+			expected:    "News tagged events, patch-notes** (3 found)",
+			embeds:      3,
+		},
+		{
+			name:        "tag search with no results",
+			interaction: discoveryInteraction("stobot_search_tags", stringOption("tags", "tribbles")),
+			expected:    "No cached news found with the tags tribbles.",
+		},
+		{
+			name:        "random news for a platform",
+			interaction: discoveryInteraction("stobot_random_news", stringOption("platform", "xbox")),
+			expected:    "Random article",
+			embeds:      1,
+		},
+		{
+			name:        "random news without news",
+			interaction: discoveryInteraction("stobot_random_news"),
+			empty:       true,
+			expected:    "No cached news found yet.",
+		},
+		{
+			name:        "trending",
+			interaction: discoveryInteraction("stobot_trending", stringOption("period", "month")),
+			expected:    "1. **events** (2 articles)",
+			embeds:      2,
+		},
+		{
+			name:        "trending without news",
+			interaction: discoveryInteraction("stobot_trending"),
+			empty:       true,
+			expected:    "No trending news found for Last 7 Days.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := database.InitDatabase(filepath.Join(t.TempDir(), "stobot.db"))
+			if err != nil {
+				t.Fatalf("Failed to initialize database: %v", err)
+			}
+			defer db.Close()
+
+			if !tt.empty {
+				newsItems := []types.NewsItem{
+					{ID: 1, Title: "Borg Invasion Event", Summary: "The Borg return", Content: "Fight the Borg", Tags: []string{"events"}, Platforms: []string{"pc"}, Updated: time.Now().Add(-time.Hour)},
+					{ID: 2, Title: "Patch Notes", Summary: "Borg fixes", Content: "Fixed a Borg ship", Tags: []string{"patch-notes"}, Platforms: []string{"pc"}, Updated: time.Now().Add(-2 * time.Hour)},
+					{ID: 3, Title: "Xbox Event", Summary: "Console event", Content: "Event details", Tags: []string{"events"}, Platforms: []string{"xbox"}, Updated: time.Now().Add(-3 * time.Hour)},
+				}
+				if err := database.StoreNews(db, newsItems, database.BulkDatabaseOptions()); err != nil {
+					t.Fatalf("Failed to store news: %v", err)
+				}
+			}
+
+			fake := testhelpers.NewFakeDiscord(t)
+			bot := &types.Bot{Session: fake.Session(), DB: db, Config: &types.Config{}}
+
+			HandleCommand(bot, bot.Session, tt.interaction)
+
+			followups := fake.RequestsTo("POST", "/webhooks/app-1/interaction-token")
+			if len(followups) != 1 {
+				t.Fatalf("Expected 1 followup, got %d", len(followups))
+			}
+			var params discordgo.WebhookParams
+			if err := json.Unmarshal(followups[0].Body, &params); err != nil {
+				t.Fatalf("Failed to decode followup: %v", err)
+			}
+			if !strings.Contains(params.Content, tt.expected) {
+				t.Errorf("Expected followup containing %q, got %q", tt.expected, params.Content)
+			}
+			if len(params.Embeds) != tt.embeds {
+				t.Errorf("Expected %d embeds, got %d", tt.embeds, len(params.Embeds))
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package discord
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxExportRows caps the number of CSV rows returned by /stobot_export_stats.
+const maxExportRows = 5000
+
+// statsCSVHeader is the header row of exported posting statistics.
+var statsCSVHeader = []string{"date", "channel", "articles_posted", "tags", "latency_p50_seconds"}
+
+// buildStatsCSV aggregates posting records into one CSV row per date and channel, ordered by
+// date and channel. When there are more than maxRows rows, the oldest are dropped.
+// It returns the CSV data and the number of rows before truncation.
+func buildStatsCSV(records []database.PostingRecord, maxRows int) ([]byte, int, error) {
+	type rowKey struct {
+		date      string
+		channelID string
+	}
+	type row struct {
+		articles  int
+		tags      map[string]int
+		latencies []int64
+	}
+
+	rows := make(map[rowKey]*row)
+	var keys []rowKey
+	for _, record := range records {
+		key := rowKey{record.Date, record.ChannelID}
+		r, ok := rows[key]
+		if !ok {
+			r = &row{tags: make(map[string]int)}
+			rows[key] = r
+			keys = append(keys, key)
+		}
+		r.articles++
+		for _, tag := range record.Tags {
+			if tag != "" {
+				r.tags[tag]++
+			}
+		}
+		if record.LatencySeconds.Valid {
+			r.latencies = append(r.latencies, record.LatencySeconds.Int64)
+		}
+	}
+
+	sort.Slice(keys, func(a, b int) bool {
+		if keys[a].date != keys[b].date {
+			return keys[a].date < keys[b].date
+		}
+		return keys[a].channelID < keys[b].channelID
+	})
+
+	total := len(keys)
+	if maxRows > 0 && total > maxRows {
+		keys = keys[total-maxRows:]
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(statsCSVHeader); err != nil {
+		return nil, total, fmt.Errorf("failed to write CSV header: %v", err)
+	}
+	for _, key := range keys {
+		r := rows[key]
+
+		latency := ""
+		if len(r.latencies) > 0 {
+			sort.Slice(r.latencies, func(a, b int) bool { return r.latencies[a] < r.latencies[b] })
+			latency = strconv.FormatInt(int64(database.Percentile(r.latencies, 50)/time.Second), 10)
+		}
+
+		record := []string{key.date, key.channelID, strconv.Itoa(r.articles), formatTagBreakdown(r.tags), latency}
+		if err := writer.Write(record); err != nil {
+			return nil, total, fmt.Errorf("failed to write CSV row: %v", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, total, fmt.Errorf("failed to write CSV: %v", err)
+	}
+
+	return buf.Bytes(), total, nil
+}
+
+// formatTagBreakdown formats tag counts as "tag:count" pairs separated by semicolons,
+// most frequent first.
+func formatTagBreakdown(tagCounts map[string]int) string {
+	tags := make([]string, 0, len(tagCounts))
+	for tag := range tagCounts {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(a, b int) bool {
+		if tagCounts[tags[a]] != tagCounts[tags[b]] {
+			return tagCounts[tags[a]] > tagCounts[tags[b]]
+		}
+		return tags[a] < tags[b]
+	})
+
+	pairs := make([]string, len(tags))
+	for n, tag := range tags {
+		pairs[n] = fmt.Sprintf("%s:%d", tag, tagCounts[tag])
+	}
+	return strings.Join(pairs, ";")
+}
+
+// parseExportPeriod converts a period option such as "30d" to a number of days.
+func parseExportPeriod(period string) (int, error) {
+	days, err := strconv.Atoi(strings.TrimSuffix(period, "d"))
+	if err != nil || !strings.HasSuffix(period, "d") || days <= 0 {
+		return 0, fmt.Errorf("invalid period %q", period)
+	}
+	return days, nil
+}
+
+// handleExportStats handles the "export_stats" command interaction
+func handleExportStats(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Validate inputs
+	if i == nil || i.Interaction == nil {
+		logger().Warning("handleExportStats called with nil interaction")
+		return
+	}
+
+	// Check if user can manage the server
+	if !hasMemberPermission(i, discordgo.PermissionManageServer) {
+		RespondError(s, i, "You need the Manage Server permission to use this command.")
+		return
+	}
+
+	period := "30d"
+	scope := "channel"
+	for _, option := range i.ApplicationCommandData().Options {
+		switch option.Name {
+		case "period":
+			period = option.StringValue()
+		case "scope":
+			scope = option.StringValue()
+		}
+	}
+
+	days, err := parseExportPeriod(period)
+	if err != nil {
+		RespondError(s, i, "Invalid period. Use a number of days such as `30d`.")
+		return
+	}
+
+	// Acknowledge interaction with timeout handling
+	if err := AcknowledgeWithRetry(s, i); err != nil {
+		logger().Errorf("Failed to acknowledge export_stats command: %v", err)
+		return
+	}
+
+	channels := []string{i.ChannelID}
+	if scope == "guild" {
+		channels, err = database.GetChannelsByGuild(b, i.GuildID)
+		if err != nil {
+			logger().Errorf("Failed to get channels for guild %s: %v", i.GuildID, err)
+			FollowupError(s, i, "Failed to get this server's channels. Please try again later.")
+			return
+		}
+	}
+
+	records, err := database.GetPostingRecords(b, channels, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		logger().Errorf("Failed to get posting records: %v", err)
+		FollowupError(s, i, "Failed to export statistics. Please try again later.")
+		return
+	}
+	if len(records) == 0 {
+		Followup(s, i, fmt.Sprintf("📊 No news was posted to this %s in the last %d days.", scope, days))
+		return
+	}
+
+	data, total, err := buildStatsCSV(records, maxExportRows)
+	if err != nil {
+		logger().Errorf("Failed to build statistics CSV: %v", err)
+		FollowupError(s, i, "Failed to export statistics. Please try again later.")
+		return
+	}
+
+	content := fmt.Sprintf("📊 Posting statistics for this %s, last %d days (%d rows).", scope, days, total)
+	if total > maxExportRows {
+		content = fmt.Sprintf("📊 Posting statistics for this %s, last %d days.\n⚠️ Truncated to the most recent %d of %d rows; choose a shorter period for complete data.",
+			scope, days, maxExportRows, total)
+	}
+
+	filename := fmt.Sprintf("stobot-stats-%s-%dd.csv", scope, days)
+	if err := FollowupWithFile(s, i, content, filename, "text/csv", data); err != nil {
+		logger().Errorf("Failed to send statistics export: %v", err)
+		FollowupError(s, i, "Failed to send the statistics file.")
+		return
+	}
+
+	logger().Infof("Exported %d rows of %s statistics for %d days", total, scope, days)
+}
+
+// handleExportChannels handles the "export_channels" command interaction
+func handleExportChannels(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Validate inputs
+	if i == nil || i.Interaction == nil {
+		logger().Warning("handleExportChannels called with nil interaction")
+		return
+	}
+
+	// Check if user can manage the server
+	if !hasMemberPermission(i, discordgo.PermissionManageServer) {
+		RespondError(s, i, "You need the Manage Server permission to use this command.")
+		return
+	}
+
+	// Acknowledge interaction with timeout handling
+	if err := AcknowledgeWithRetry(s, i); err != nil {
+		logger().Errorf("Failed to acknowledge export_channels command: %v", err)
+		return
+	}
+
+	channels, err := database.GetChannelsByGuild(b, i.GuildID)
+	if err != nil {
+		logger().Errorf("Failed to get channels for guild %s: %v", i.GuildID, err)
+		FollowupError(s, i, "Failed to get this server's channels. Please try again later.")
+		return
+	}
+
+	var data bytes.Buffer
+	exported := 0
+	for _, channelID := range channels {
+		cfg, err := database.GetChannelConfig(b, channelID)
+		if err != nil || cfg == nil {
+			channelLogger(channelID).Errorf("Failed to get config for channel %s: %v", channelID, err)
+			continue
+		}
+		data.WriteString(database.ChannelFileLine(*cfg) + "\n")
+		exported++
+	}
+	if exported == 0 {
+		Followup(s, i, "📋 No channels are registered in this server.")
+		return
+	}
+
+	content := fmt.Sprintf("📋 %d registered channels in this server, in the format read by `stobot import-channels`.", exported)
+	if err := FollowupWithFile(s, i, content, "stobot-channels.txt", "text/plain", data.Bytes()); err != nil {
+		logger().Errorf("Failed to send channel export: %v", err)
+		FollowupError(s, i, "Failed to send the channels file.")
+		return
+	}
+
+	logger().Infof("Exported %d channels of guild %s", exported, i.GuildID)
+}
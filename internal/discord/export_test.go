@@ -0,0 +1,283 @@
+package discord
+
+import (
+	"bytes"
+	"database/sql"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func latency(seconds int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: seconds, Valid: true}
+}
+
+func exportRecords() []database.PostingRecord {
+	return []database.PostingRecord{
+		{Date: "2025-06-10", ChannelID: "222", NewsID: 1, Tags: []string{"star-trek-online"}, LatencySeconds: latency(300)},
+		{Date: "2025-06-10", ChannelID: "111", NewsID: 1, Tags: []string{"star-trek-online"}, LatencySeconds: latency(120)},
+		{Date: "2025-06-10", ChannelID: "111", NewsID: 2, Tags: []string{"patch-notes", "star-trek-online"}, LatencySeconds: latency(60)},
+		{Date: "2025-06-10", ChannelID: "111", NewsID: 3, Tags: []string{"patch-notes"}, LatencySeconds: latency(600)},
+		{Date: "2025-06-11", ChannelID: "111", NewsID: 4, Tags: []string{"events"}},
+		{Date: "2025-06-11", ChannelID: "111", NewsID: 5},
+		{Date: "2025-06-12", ChannelID: "222", NewsID: 6, Tags: []string{"dev-blogs, \"quoted\""}, LatencySeconds: latency(45)},
+	}
+}
+
+func TestBuildStatsCSVGolden(t *testing.T) {
+	data, total, err := buildStatsCSV(exportRecords(), maxExportRows)
+	if err != nil {
+		t.Fatalf("Failed to build CSV: %v", err)
+	}
+	if total != 4 {
+		t.Errorf("Expected 4 rows, got %d", total)
+	}
+
+	golden := filepath.Join("testdata", "stats_export.golden.csv")
+	if *update {
+		if err := os.WriteFile(golden, data, 0644); err != nil {
+			t.Fatalf("Failed to update golden file: %v", err)
+		}
+	}
+
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+	if !bytes.Equal(data, expected) {
+		t.Errorf("CSV does not match %s:\ngot:\n%s\nexpected:\n%s", golden, data, expected)
+	}
+}
+
+func TestBuildStatsCSVTruncation(t *testing.T) {
+	data, total, err := buildStatsCSV(exportRecords(), 2)
+	if err != nil {
+		t.Fatalf("Failed to build CSV: %v", err)
+	}
+	if total != 4 {
+		t.Errorf("Expected 4 rows before truncation, got %d", total)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected header and 2 rows, got %d lines:\n%s", len(lines), data)
+	}
+	// The oldest rows are dropped
+	if !strings.HasPrefix(lines[1], "2025-06-11,111,") || !strings.HasPrefix(lines[2], "2025-06-12,222,") {
+		t.Errorf("Expected the most recent rows to be kept, got:\n%s", data)
+	}
+}
+
+func TestBuildStatsCSVEmpty(t *testing.T) {
+	data, total, err := buildStatsCSV(nil, maxExportRows)
+	if err != nil {
+		t.Fatalf("Failed to build CSV: %v", err)
+	}
+	if total != 0 || string(data) != strings.Join(statsCSVHeader, ",")+"\n" {
+		t.Errorf("Expected only the header, got %d rows:\n%s", total, data)
+	}
+}
+
+func TestParseExportPeriod(t *testing.T) {
+	tests := []struct {
+		period      string
+		expected    int
+		expectError bool
+	}{
+		{"7d", 7, false},
+		{"30d", 30, false},
+		{"90d", 90, false},
+		{"30", 0, true},
+		{"0d", 0, true},
+		{"-5d", 0, true},
+		{"d", 0, true},
+		{"month", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			days, err := parseExportPeriod(tt.period)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected an error, got %d days", days)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to parse period: %v", err)
+			}
+			if days != tt.expected {
+				t.Errorf("Expected %d days, got %d", tt.expected, days)
+			}
+		})
+	}
+}
+
+// exportInteraction builds a /stobot_export_stats interaction in channel-a of guild-1.
+func exportInteraction(permissions int64, options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			ID:        "interaction-1",
+			AppID:     "app-1",
+			Token:     "interaction-token",
+			Type:      discordgo.InteractionApplicationCommand,
+			GuildID:   "guild-1",
+			ChannelID: "channel-a",
+			Member: &discordgo.Member{
+				User:        &discordgo.User{ID: "user-1"},
+				Permissions: permissions,
+			},
+			Data: discordgo.ApplicationCommandInteractionData{
+				Name:    "stobot_export_stats",
+				Options: options,
+			},
+		},
+	}
+}
+
+func setupExportTest(t *testing.T) (*types.Bot, *testhelpers.FakeDiscord) {
+	t.Helper()
+
+	db, err := database.InitDatabase(filepath.Join(t.TempDir(), "stobot.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	fake := testhelpers.NewFakeDiscord(t)
+	bot := &types.Bot{Session: fake.Session(), DB: db, Config: &types.Config{}}
+
+	newsItems := []types.NewsItem{
+		{ID: 1, Title: "News 1", Tags: []string{"patch-notes"}, Updated: time.Now().Add(-time.Minute)},
+		{ID: 2, Title: "News 2", Tags: []string{"events"}, Updated: time.Now().Add(-time.Minute)},
+	}
+	if err := database.StoreNews(db, newsItems, database.DefaultDatabaseOptions()); err != nil {
+		t.Fatalf("Failed to store news: %v", err)
+	}
+	for _, channelID := range []string{"channel-a", "channel-b"} {
+		if _, err := db.Exec("INSERT INTO channels (id, guild_id) VALUES (?, 'guild-1')", channelID); err != nil {
+			t.Fatalf("Failed to add channel: %v", err)
+		}
+		for _, newsItem := range newsItems {
+			if err := database.MarkNewsAsDelivered(bot, newsItem, channelID, database.DeliveryLive); err != nil {
+				t.Fatalf("Failed to mark news as delivered: %v", err)
+			}
+		}
+	}
+
+	return bot, fake
+}
+
+func TestHandleExportStats(t *testing.T) {
+	tests := []struct {
+		name         string
+		scope        string
+		expectedRows []string
+	}{
+		{"channel scope", "channel", []string{",channel-a,2,events:1;patch-notes:1,"}},
+		{"guild scope", "guild", []string{",channel-a,2,", ",channel-b,2,"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot, fake := setupExportTest(t)
+			i := exportInteraction(discordgo.PermissionManageServer, &discordgo.ApplicationCommandInteractionDataOption{
+				Name: "scope", Type: discordgo.ApplicationCommandOptionString, Value: tt.scope,
+			})
+
+			handleExportStats(bot, bot.Session, i)
+
+			followups := fake.RequestsTo("POST", "/webhooks/app-1/interaction-token")
+			if len(followups) != 1 {
+				t.Fatalf("Expected 1 followup, got %d", len(followups))
+			}
+			body := string(followups[0].Body)
+			if !strings.Contains(body, "stobot-stats-"+tt.scope+"-30d.csv") {
+				t.Errorf("Expected a CSV attachment, got body:\n%s", body)
+			}
+			for _, row := range tt.expectedRows {
+				if !strings.Contains(body, row) {
+					t.Errorf("Expected CSV row containing %q, got body:\n%s", row, body)
+				}
+			}
+			if tt.scope == "channel" && strings.Contains(body, "channel-b") {
+				t.Error("Expected channel scope to exclude other channels")
+			}
+		})
+	}
+}
+
+func TestHandleExportStatsRequiresManageServer(t *testing.T) {
+	bot, fake := setupExportTest(t)
+
+	handleExportStats(bot, bot.Session, exportInteraction(discordgo.PermissionSendMessages))
+
+	if calls := fake.RequestsTo("POST", "/interactions/interaction-1/interaction-token/callback"); len(calls) != 1 {
+		t.Fatalf("Expected 1 error response, got %d", len(calls))
+	}
+	if calls := fake.RequestsTo("POST", "/webhooks/app-1/interaction-token"); len(calls) != 0 {
+		t.Errorf("Expected no export without permission, got %d followups", len(calls))
+	}
+}
+
+func TestFollowupWithFileSizeLimit(t *testing.T) {
+	fake := testhelpers.NewFakeDiscord(t)
+	i := exportInteraction(discordgo.PermissionManageServer)
+
+	err := FollowupWithFile(fake.Session(), i, "too big", "big.csv", "text/csv", make([]byte, MaxUploadSize+1))
+	if err == nil {
+		t.Fatal("Expected an error for a file over the upload limit")
+	}
+	if len(fake.Requests()) != 0 {
+		t.Errorf("Expected no request for an oversized file, got %d", len(fake.Requests()))
+	}
+
+	if err := FollowupWithFile(fake.Session(), i, "ok", "small.csv", "text/csv", []byte("a,b\n")); err != nil {
+		t.Fatalf("Failed to send file: %v", err)
+	}
+	if calls := fake.RequestsTo("POST", "/webhooks/app-1/interaction-token"); len(calls) != 1 {
+		t.Errorf("Expected 1 followup, got %d", len(calls))
+	}
+}
+
+func TestHandleExportChannels(t *testing.T) {
+	bot, fake := setupExportTest(t)
+	if err := database.UpdateChannelGuild(bot, "channel-b", "guild-2"); err != nil {
+		t.Fatalf("Failed to update channel guild: %v", err)
+	}
+
+	handleExportChannels(bot, bot.Session, exportInteraction(discordgo.PermissionManageServer))
+
+	followups := fake.RequestsTo("POST", "/webhooks/app-1/interaction-token")
+	if len(followups) != 1 {
+		t.Fatalf("Expected 1 followup, got %d", len(followups))
+	}
+	body := string(followups[0].Body)
+	if !strings.Contains(body, "stobot-channels.txt") || !strings.Contains(body, "channel:channel-a|pc,xbox,ps|PROD") {
+		t.Errorf("Expected a channels file with channel-a, got body:\n%s", body)
+	}
+	if strings.Contains(body, "channel-b") {
+		t.Error("Expected channels of other servers to be excluded")
+	}
+}
+
+func TestHandleExportChannelsRequiresManageServer(t *testing.T) {
+	bot, fake := setupExportTest(t)
+
+	handleExportChannels(bot, bot.Session, exportInteraction(discordgo.PermissionSendMessages))
+
+	if calls := fake.RequestsTo("POST", "/webhooks/app-1/interaction-token"); len(calls) != 0 {
+		t.Errorf("Expected no export without permission, got %d followups", len(calls))
+	}
+}
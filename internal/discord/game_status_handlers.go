@@ -1,131 +1,115 @@
 package discord
 
 import (
-	"encoding/json"
 	"fmt"
-	"net/http"
-	"strings"
+	"net/url"
 	"time"
 
+	"github.com/FracKenA/sto_news_discord_bot/internal/gamestatus"
 	"github.com/FracKenA/sto_news_discord_bot/internal/types"
 
 	"github.com/bwmarrin/discordgo"
-	log "github.com/sirupsen/logrus"
 )
 
-// GameStatusResponse represents the structure of the STO server status API response
-type GameStatusResponse struct {
-	ServerStatus string `json:"server_status"`
+// gameStatusStyles holds the emoji and embed color shown for each server state.
+var gameStatusStyles = map[gamestatus.State]struct {
+	emoji string
+	color int
+}{
+	gamestatus.StateUp:          {"✅", 0x00ff00}, // Green
+	gamestatus.StateDown:        {"❌", 0xff0000}, // Red
+	gamestatus.StateMaintenance: {"🔧", 0xffaa00}, // Orange
+	gamestatus.StateUnknown:     {"❓", 0x808080}, // Gray
 }
 
-// handleGameStatus handles the "game_status" command interaction
-func handleGameStatus(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
-	// Acknowledge interaction with timeout handling
-	if err := AcknowledgeWithRetry(s, i); err != nil {
-		log.Errorf("Failed to acknowledge game_status command: %v", err)
-		return
-	}
-
-	// Fetch server status from STO launcher API
-	log.Info("Fetching STO server status from launcher API")
-
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-
-	resp, err := client.Get("http://launcher.startrekonline.com/launcher_server_status")
-	if err != nil {
-		log.Errorf("Failed to fetch server status: %v", err)
-		Followup(s, i, "❌ Failed to fetch server status. Please try again later.")
-		return
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		log.Errorf("Server status API returned status %d", resp.StatusCode)
-		Followup(s, i, "❌ Server status API is currently unavailable.")
-		return
-	}
-
-	var statusResponse GameStatusResponse
-	if err := json.NewDecoder(resp.Body).Decode(&statusResponse); err != nil {
-		log.Errorf("Failed to decode server status response: %v", err)
-		Followup(s, i, "❌ Failed to parse server status response.")
-		return
+// gameStatusURL returns the configured server status endpoint, or the launcher's.
+func gameStatusURL(b *types.Bot) string {
+	if b.Config != nil && b.Config.GameStatusURL != "" {
+		return b.Config.GameStatusURL
 	}
+	return gamestatus.DefaultURL
+}
 
-	// Also check news endpoint for API health
-	newsResp, err := client.Get("https://api.arcgames.com/v1.0/games/sto/news?limit=1")
-	if err != nil {
-		log.Errorf("Failed to fetch news endpoint for health check: %v", err)
-		Followup(s, i, "❌ News API health check failed.")
-		return
-	}
-	defer newsResp.Body.Close()
-	if newsResp.StatusCode < 200 || newsResp.StatusCode >= 300 {
-		log.Errorf("News API health check returned status %d", newsResp.StatusCode)
-		Followup(s, i, "❌ News API is currently unavailable.")
-		return
-	}
-	var newsData map[string]interface{}
-	if err := json.NewDecoder(newsResp.Body).Decode(&newsData); err != nil {
-		log.Errorf("Failed to decode news API response: %v", err)
-		Followup(s, i, "❌ Failed to parse news API response.")
-		return
-	}
-	if _, ok := newsData["news"]; !ok {
-		log.Errorf("News API response missing 'news' key")
-		Followup(s, i, "❌ News API response invalid.")
-		return
+// gameStatusEmbed builds the embed showing a server status check.
+func gameStatusEmbed(status gamestatus.Status, statusURL string) *discordgo.MessageEmbed {
+	style, ok := gameStatusStyles[status.State]
+	if !ok {
+		style = gameStatusStyles[gamestatus.StateUnknown]
 	}
 
-	// Create embed for server status
-	statusEmoji := "✅"
-	statusColor := 0x00ff00 // Green
-
-	switch strings.ToUpper(statusResponse.ServerStatus) {
-	case "UP":
-		statusEmoji = "✅"
-		statusColor = 0x00ff00 // Green
-	case "DOWN":
-		statusEmoji = "❌"
-		statusColor = 0xff0000 // Red
-	case "MAINTENANCE":
-		statusEmoji = "🔧"
-		statusColor = 0xffaa00 // Orange
-	default:
-		statusEmoji = "❓"
-		statusColor = 0x808080 // Gray
+	source := statusURL
+	if parsed, err := url.Parse(statusURL); err == nil && parsed.Host != "" {
+		source = parsed.Host
 	}
 
 	embed := &discordgo.MessageEmbed{
 		Title:       "🎮 Star Trek Online Server Status",
-		Description: fmt.Sprintf("%s **%s**", statusEmoji, strings.ToUpper(statusResponse.ServerStatus)),
-		Color:       statusColor,
-		Timestamp:   time.Now().Format("2006-01-02T15:04:05Z"),
+		Description: fmt.Sprintf("%s **%s**", style.emoji, formatGameState(status.State)),
+		Color:       style.color,
+		Timestamp:   status.CheckedAt.UTC().Format(time.RFC3339),
 		Footer: &discordgo.MessageEmbedFooter{
-			Text: "Data from launcher.startrekonline.com",
+			Text: "Data from " + source,
 		},
 		Fields: []*discordgo.MessageEmbedField{
 			{
 				Name:   "Status",
-				Value:  strings.ToUpper(statusResponse.ServerStatus),
+				Value:  formatGameState(status.State),
 				Inline: true,
 			},
 			{
 				Name:   "Last Checked",
-				Value:  time.Now().Format("15:04:05 UTC"),
+				Value:  fmt.Sprintf("<t:%d:R>", status.CheckedAt.Unix()),
 				Inline: true,
 			},
 		},
 	}
 
+	if status.Message != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "Message",
+			Value: TruncateBytes(status.Message, MaxEmbedFieldValue),
+		})
+	}
+
+	return embed
+}
+
+// formatGameState returns a server state as shown in the embed, e.g. "MAINTENANCE".
+func formatGameState(state gamestatus.State) string {
+	switch state {
+	case gamestatus.StateUp:
+		return "UP"
+	case gamestatus.StateDown:
+		return "DOWN"
+	case gamestatus.StateMaintenance:
+		return "MAINTENANCE"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// handleGameStatus handles the "game_status" command interaction
+func handleGameStatus(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Acknowledge interaction with timeout handling
+	if err := AcknowledgeWithRetry(s, i); err != nil {
+		logger().Errorf("Failed to acknowledge game_status command: %v", err)
+		return
+	}
+
+	statusURL := gameStatusURL(b)
+	status, err := gamestatus.Check(statusURL)
+	if err != nil {
+		logger().Errorf("Failed to check STO server status: %v", err)
+		Followup(s, i, "❌ Could not reach the Star Trek Online server status. The servers or the launcher may be down; please try again in a minute.")
+		return
+	}
+
 	// Send the result with enhanced error handling
-	if err := FollowupWithEmbeds(s, i, "", []*discordgo.MessageEmbed{embed}); err != nil {
-		log.Errorf("Failed to send server status: %v", err)
+	if err := FollowupWithEmbeds(s, i, "", []*discordgo.MessageEmbed{gameStatusEmbed(status, statusURL)}); err != nil {
+		logger().Errorf("Failed to send server status: %v", err)
 		Followup(s, i, "❌ Failed to send server status.")
 		return
 	}
 
-	log.Infof("Sent STO server status: %s", statusResponse.ServerStatus)
+	logger().Infof("Sent STO server status: %s", status.State)
 }
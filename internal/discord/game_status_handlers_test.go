@@ -0,0 +1,84 @@
+package discord
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestGameStatusCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		unreachable bool
+		expected    string
+		message     string
+	}{
+		{name: "up", body: `{"server_status": "up"}`, expected: "✅ **UP**"},
+		{
+			name:     "maintenance",
+			body:     `{"server_status": "maintenance", "message": "Back at 10:00 PT"}`,
+			expected: "🔧 **MAINTENANCE**",
+			message:  "Back at 10:00 PT",
+		},
+		{name: "unreachable", unreachable: true, expected: "Could not reach"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+			if tt.unreachable {
+				server.Close()
+			}
+
+			bot := testhelpers.CreateTestBot(t)
+			defer bot.DB.Close()
+			bot.Config.GameStatusURL = server.URL
+			fake := testhelpers.NewFakeDiscord(t)
+			bot.Session = fake.Session()
+
+			handleGameStatus(bot, bot.Session, discoveryInteraction("stobot_game_status"))
+
+			calls := fake.RequestsTo("POST", "/webhooks/app-1/interaction-token")
+			if len(calls) != 1 {
+				t.Fatalf("Expected 1 followup, got %d", len(calls))
+			}
+			var followup discordgo.WebhookParams
+			if err := json.Unmarshal(calls[0].Body, &followup); err != nil {
+				t.Fatalf("Failed to decode followup: %v", err)
+			}
+
+			if tt.unreachable {
+				if !strings.Contains(followup.Content, tt.expected) {
+					t.Errorf("Expected %q in the followup, got %q", tt.expected, followup.Content)
+				}
+				return
+			}
+			if len(followup.Embeds) != 1 {
+				t.Fatalf("Expected 1 embed, got %s", calls[0].Body)
+			}
+			embed := followup.Embeds[0]
+			if embed.Description != tt.expected {
+				t.Errorf("Expected description %q, got %q", tt.expected, embed.Description)
+			}
+			var message string
+			for _, field := range embed.Fields {
+				if field.Name == "Message" {
+					message = field.Value
+				}
+			}
+			if message != tt.message {
+				t.Errorf("Expected message field %q, got %q", tt.message, message)
+			}
+		})
+	}
+}
@@ -7,43 +7,56 @@ import (
 	"github.com/FracKenA/sto_news_discord_bot/internal/types"
 
 	"github.com/bwmarrin/discordgo"
-	log "github.com/sirupsen/logrus"
 )
 
 // Ready handles the ready event when bot connects to Discord
 func Ready(b *types.Bot) func(s *discordgo.Session, event *discordgo.Ready) {
 	return func(s *discordgo.Session, event *discordgo.Ready) {
 		if event == nil || event.User == nil {
-			log.Warning("Ready event or user is nil")
+			logger().Warning("Ready event or user is nil")
 			return
 		}
 
-		log.Infof("Bot connected as %s#%s", event.User.Username, event.User.Discriminator)
+		logger().Infof("Bot connected as %s#%s (%s environment)", event.User.Username, event.User.Discriminator, botEnvironment(b))
 
 		// Skip Discord API calls if session is nil (for testing)
 		if s == nil {
-			log.Warning("Session is nil, skipping Discord API calls")
+			logger().Warning("Session is nil, skipping Discord API calls")
 			return
 		}
 
 		// Set status
 		err := s.UpdateGameStatus(0, "Monitoring Star Trek Online news")
 		if err != nil {
-			log.Errorf("Failed to set status: %v", err)
+			logger().Errorf("Failed to set status: %v", err)
 		}
 
 		// Register slash commands
 		RegisterCommands(s)
-		log.Info("Slash commands registered successfully")
+		logger().Info("Slash commands registered successfully")
 	}
 }
 
-// InteractionCreate handles slash command interactions
+// InteractionCreate handles slash command and message component interactions
 func InteractionCreate(b *types.Bot) func(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		// Check for nil interaction
 		if i == nil || i.Interaction == nil {
-			log.Warning("Received nil interaction")
+			logger().Warning("Received nil interaction")
+			return
+		}
+
+		// Route button clicks and autocomplete; ApplicationCommandData panics for other interaction types
+		if i.Type == discordgo.InteractionMessageComponent {
+			HandleComponent(b, s, i)
+			return
+		}
+		if i.Type == discordgo.InteractionApplicationCommandAutocomplete {
+			HandleAutocomplete(b, s, i)
+			return
+		}
+		if i.Type != discordgo.InteractionApplicationCommand {
+			logger().Debugf("Ignoring interaction of type %s", i.Type)
 			return
 		}
 
@@ -55,3 +68,12 @@ func InteractionCreate(b *types.Bot) func(s *discordgo.Session, i *discordgo.Int
 		HandleCommand(b, s, i)
 	}
 }
+
+// botEnvironment returns the environment this bot instance serves, or ANY for an instance
+// without one, which serves every channel.
+func botEnvironment(b *types.Bot) string {
+	if b == nil || b.Config == nil || b.Config.Environment == "" {
+		return "ANY"
+	}
+	return b.Config.Environment
+}
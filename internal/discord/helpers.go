@@ -2,34 +2,34 @@ package discord
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/FracKenA/sto_news_discord_bot/internal/types"
 
 	"github.com/bwmarrin/discordgo"
-	log "github.com/sirupsen/logrus"
 )
 
 // hasAdminPermission checks if the user has administrator permission in the guild
 func hasAdminPermission(s *discordgo.Session, i *discordgo.InteractionCreate) bool {
 	// If the interaction doesn't have guild info, we can't check permissions
 	if i.GuildID == "" || i.Member == nil || i.Member.User == nil {
-		log.Debugf("hasAdminPermission: Missing guild info - GuildID: %s, Member: %v, User: %v", i.GuildID, i.Member != nil, i.Member != nil && i.Member.User != nil)
+		logger().Debugf("hasAdminPermission: Missing guild info - GuildID: %s, Member: %v, User: %v", i.GuildID, i.Member != nil, i.Member != nil && i.Member.User != nil)
 		return false
 	}
 
-	log.Debugf("hasAdminPermission: Checking permissions for user %s in guild %s, channel %s", i.Member.User.ID, i.GuildID, i.ChannelID)
+	channelLogger(i.ChannelID).Debugf("hasAdminPermission: Checking permissions for user %s in guild %s, channel %s", i.Member.User.ID, i.GuildID, i.ChannelID)
 
 	// Try to get user permissions using guild member permissions instead of channel permissions
 	guild, err := s.Guild(i.GuildID)
 	if err != nil {
-		log.Errorf("Failed to get guild info: %v", err)
+		logger().Errorf("Failed to get guild info: %v", err)
 		return false
 	}
 
 	// Check if user is the guild owner
 	if i.Member.User.ID == guild.OwnerID {
-		log.Debugf("User %s is guild owner", i.Member.User.ID)
+		logger().Debugf("User %s is guild owner", i.Member.User.ID)
 		return true
 	}
 
@@ -37,45 +37,85 @@ func hasAdminPermission(s *discordgo.Session, i *discordgo.InteractionCreate) bo
 	for _, roleID := range i.Member.Roles {
 		role, err := s.State.Role(i.GuildID, roleID)
 		if err != nil {
-			log.Debugf("Failed to get role %s: %v", roleID, err)
+			logger().Debugf("Failed to get role %s: %v", roleID, err)
 			continue
 		}
 
 		if role.Permissions&discordgo.PermissionAdministrator != 0 {
-			log.Debugf("User %s has administrator permission via role %s", i.Member.User.ID, role.Name)
+			logger().Debugf("User %s has administrator permission via role %s", i.Member.User.ID, role.Name)
 			return true
 		}
 	}
 
-	log.Debugf("User %s does not have administrator permission", i.Member.User.ID)
+	logger().Debugf("User %s does not have administrator permission", i.Member.User.ID)
 	return false
 }
 
-// formatNewsEmbed creates a Discord embed for a news item
+// hasGuildAdminPermission checks whether a user owns a server or has administrator permission in
+// it, for commands that affect a server other than the one they were run in.
+func hasGuildAdminPermission(s *discordgo.Session, guildID, userID string) bool {
+	guild, err := s.Guild(guildID)
+	if err != nil {
+		logger().Errorf("Failed to get guild info: %v", err)
+		return false
+	}
+	if userID == guild.OwnerID {
+		return true
+	}
+
+	member, err := s.GuildMember(guildID, userID)
+	if err != nil {
+		logger().Debugf("User %s is not a member of guild %s: %v", userID, guildID, err)
+		return false
+	}
+	for _, role := range guild.Roles {
+		if role.Permissions&discordgo.PermissionAdministrator != 0 && slices.Contains(member.Roles, role.ID) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasMemberPermission checks whether the invoking member has a permission in the channel.
+// Administrators have every permission.
+func hasMemberPermission(i *discordgo.InteractionCreate, permission int64) bool {
+	if i.GuildID == "" || i.Member == nil {
+		return false
+	}
+	return i.Member.Permissions&(permission|discordgo.PermissionAdministrator) != 0
+}
+
+// formatNewsEmbed creates a Discord embed for a news item. Platforms are shown in the footer, and
+// the Tags field is left out for items without tags, as Discord rejects empty field values.
 func formatNewsEmbed(newsItem types.NewsItem) *discordgo.MessageEmbed {
+	platforms := "None"
+	if len(newsItem.Platforms) > 0 {
+		platforms = strings.Join(newsItem.Platforms, ", ")
+	}
+
 	embed := &discordgo.MessageEmbed{
-		Title:       TruncateText(newsItem.Title, 256),
-		Description: TruncateText(newsItem.Summary, 2048),
-		URL:         fmt.Sprintf("https://playstartrekonline.com/en/news/article/%d", newsItem.ID),
+		Title:       TruncateBytes(newsItem.Title, MaxEmbedTitle),
+		Description: TruncateBytes(TruncateTextAtWord(newsItem.Summary, 2048), MaxEmbedDescription),
+		URL:         newsItem.Link(),
 		Color:       0x00ff00, // Green color
 		Timestamp:   newsItem.Updated.Format("2006-01-02T15:04:05Z"),
 		Footer: &discordgo.MessageEmbedFooter{
-			Text: fmt.Sprintf("Platforms: %s", strings.Join(newsItem.Platforms, ", ")),
-		},
-		Fields: []*discordgo.MessageEmbedField{
-			{
-				Name:   "Tags",
-				Value:  strings.Join(newsItem.Tags, ", "),
-				Inline: true,
-			},
-			{
-				Name:   "Platforms",
-				Value:  strings.Join(newsItem.Platforms, ", "),
-				Inline: true,
-			},
+			Text: TruncateBytes(fmt.Sprintf("Platforms: %s", platforms), MaxEmbedFooterText),
 		},
 	}
 
+	if newsItem.Author != "" {
+		embed.Author = &discordgo.MessageEmbedAuthor{Name: TruncateBytes(newsItem.Author, MaxEmbedAuthorName)}
+	}
+
+	if len(newsItem.Tags) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Tags",
+			Value:  TruncateBytes(strings.Join(newsItem.Tags, ", "), MaxEmbedFieldValue),
+			Inline: true,
+		})
+	}
+
 	if newsItem.ThumbnailURL != "" {
 		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{
 			URL: newsItem.ThumbnailURL,
@@ -0,0 +1,17 @@
+package discord
+
+import (
+	"github.com/FracKenA/sto_news_discord_bot/internal/logging"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// logger returns the log entry of the Discord layer, tagged component=discord.
+func logger() *log.Entry {
+	return logging.Logger(logging.Discord)
+}
+
+// channelLogger returns the log entry of commands about a channel, tagged with its channel_id.
+func channelLogger(channelID string) *log.Entry {
+	return logger().WithField("channel_id", channelID)
+}
@@ -3,20 +3,20 @@ package discord
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/FracKenA/sto_news_discord_bot/internal/database"
 	"github.com/FracKenA/sto_news_discord_bot/internal/news"
 	"github.com/FracKenA/sto_news_discord_bot/internal/types"
 
 	"github.com/bwmarrin/discordgo"
-	log "github.com/sirupsen/logrus"
 )
 
 // handleNews handles the "news" command interaction
 func handleNews(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate, tag string) {
 	// Check for nil bot
 	if b == nil {
-		log.Error("Cannot handle news: nil bot provided")
+		logger().Error("Cannot handle news: nil bot provided")
 		if s != nil && i != nil {
 			Respond(s, i, "❌ Internal error: bot not available.")
 		}
@@ -29,7 +29,7 @@ func handleNews(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCrea
 	// Get recent news from cache first
 	freshNews, err := database.GetFreshNews(b.DB, b.Config.FreshSeconds)
 	if err != nil {
-		log.Errorf("Failed to get fresh news: %v", err)
+		logger().Errorf("Failed to get fresh news: %v", err)
 		Followup(s, i, "❌ Failed to fetch news. Please try again later.")
 		return
 	}
@@ -48,10 +48,10 @@ func handleNews(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCrea
 
 	// If no cached news, try to fetch new news
 	if len(filteredNews) == 0 {
-		log.Infof("No cached news found, fetching from API for tag: %s", tag)
+		logger().Infof("No cached news found, fetching from API for tag: %s", tag)
 		newsItems, err := news.FetchNews(b, tag, 5, news.DefaultFetchOptions()) // Fetch 5 recent items
 		if err != nil {
-			log.Errorf("Failed to fetch news from API: %v", err)
+			logger().Errorf("Failed to fetch news from API: %v", err)
 			Followup(s, i, "❌ No recent news found and failed to fetch from API.")
 			return
 		}
@@ -72,6 +72,7 @@ func handleNews(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCrea
 	var embeds []*discordgo.MessageEmbed
 	for _, newsItem := range filteredNews {
 		embed := formatNewsEmbed(newsItem)
+		b.Config.RewriteEmbedURLs(embed)
 		embeds = append(embeds, embed)
 	}
 
@@ -93,7 +94,7 @@ func handleNews(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCrea
 			content = fmt.Sprintf("📰 **Recent %s News** (%d items)", tagDisplay, len(filteredNews))
 		}
 		if err := FollowupWithEmbeds(s, i, content, embeds[idx:end]); err != nil {
-			log.Errorf("Failed to send news embeds: %v", err)
+			logger().Errorf("Failed to send news embeds: %v", err)
 			if idx == 0 {
 				Followup(s, i, "❌ Failed to send news items.")
 			}
@@ -101,5 +102,144 @@ func handleNews(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCrea
 		}
 	}
 
-	log.Infof("Sent %d news items for tag '%s' via slash command", len(filteredNews), tag)
+	logger().Infof("Sent %d news items for tag '%s' via slash command", len(filteredNews), tag)
+}
+
+// newsDateLayout is the date format accepted by the date range commands.
+const newsDateLayout = "2006-01-02"
+
+// maxDateRangeResults is the number of articles the date range commands return.
+const maxDateRangeResults = 10
+
+// parseNewsDate parses a YYYY-MM-DD command option as the start of that day in UTC.
+func parseNewsDate(name, value string) (time.Time, error) {
+	date, err := time.Parse(newsDateLayout, strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("`%s` must be a date in YYYY-MM-DD format, e.g. 2024-06-01 (got %q)", name, value)
+	}
+	return date, nil
+}
+
+// handleNewsSince handles the "news_since" command interaction
+func handleNewsSince(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i == nil || i.Interaction == nil {
+		logger().Warning("handleNewsSince called with nil interaction")
+		return
+	}
+
+	var dateOption, tag, platform string
+	for _, option := range i.ApplicationCommandData().Options {
+		switch option.Name {
+		case "date":
+			dateOption = option.StringValue()
+		case "tag":
+			tag = option.StringValue()
+		case "platform":
+			platform = option.StringValue()
+		}
+	}
+
+	since, err := parseNewsDate("date", dateOption)
+	if err != nil {
+		RespondError(s, i, err.Error())
+		return
+	}
+
+	if err := AcknowledgeWithRetry(s, i); err != nil {
+		logger().Errorf("Failed to acknowledge news_since command: %v", err)
+		return
+	}
+
+	newsItems, err := database.GetNewsSince(b, since, tag, platform, maxDateRangeResults)
+	if err != nil {
+		logger().Errorf("Failed to get news since %s: %v", since.Format(newsDateLayout), err)
+		Followup(s, i, "❌ Failed to get news. Please try again later.")
+		return
+	}
+
+	sendDateRangeNews(b, s, i, newsItems, fmt.Sprintf("since %s", since.Format(newsDateLayout)), tag, platform)
+}
+
+// handleNewsBetween handles the "news_between" command interaction
+func handleNewsBetween(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i == nil || i.Interaction == nil {
+		logger().Warning("handleNewsBetween called with nil interaction")
+		return
+	}
+
+	var startOption, endOption, tag, platform string
+	for _, option := range i.ApplicationCommandData().Options {
+		switch option.Name {
+		case "start":
+			startOption = option.StringValue()
+		case "end":
+			endOption = option.StringValue()
+		case "tag":
+			tag = option.StringValue()
+		case "platform":
+			platform = option.StringValue()
+		}
+	}
+
+	start, err := parseNewsDate("start", startOption)
+	if err != nil {
+		RespondError(s, i, err.Error())
+		return
+	}
+	end, err := parseNewsDate("end", endOption)
+	if err != nil {
+		RespondError(s, i, err.Error())
+		return
+	}
+	if end.Before(start) {
+		RespondError(s, i, fmt.Sprintf("`end` (%s) must not be before `start` (%s).", end.Format(newsDateLayout), start.Format(newsDateLayout)))
+		return
+	}
+
+	if err := AcknowledgeWithRetry(s, i); err != nil {
+		logger().Errorf("Failed to acknowledge news_between command: %v", err)
+		return
+	}
+
+	// The end date is inclusive, so the range runs to the start of the following day
+	newsItems, err := database.GetNewsBetween(b, start, end.AddDate(0, 0, 1), tag, platform, maxDateRangeResults)
+	if err != nil {
+		logger().Errorf("Failed to get news between %s and %s: %v", start.Format(newsDateLayout), end.Format(newsDateLayout), err)
+		Followup(s, i, "❌ Failed to get news. Please try again later.")
+		return
+	}
+
+	sendDateRangeNews(b, s, i, newsItems, fmt.Sprintf("from %s to %s", start.Format(newsDateLayout), end.Format(newsDateLayout)), tag, platform)
+}
+
+// sendDateRangeNews sends the results of a date range command as embeds.
+func sendDateRangeNews(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate, newsItems []types.NewsItem, period, tag, platform string) {
+	var filters string
+	if tag != "" {
+		filters += fmt.Sprintf(", tag %s", tag)
+	}
+	if platform != "" {
+		filters += fmt.Sprintf(", platform %s", platform)
+	}
+
+	if len(newsItems) == 0 {
+		Followup(s, i, fmt.Sprintf("📰 No cached news found %s%s.", period, filters))
+		return
+	}
+
+	var embeds []*discordgo.MessageEmbed
+	for _, newsItem := range newsItems {
+		embed := formatNewsEmbed(newsItem)
+		b.Config.RewriteEmbedURLs(embed)
+		embeds = append(embeds, embed)
+	}
+
+	content := fmt.Sprintf("📰 **News %s%s** (%d items)", period, filters, len(newsItems))
+	if err := FollowupWithEmbeds(s, i, content, embeds); err != nil {
+		logger().Errorf("Failed to send news embeds: %v", err)
+		Followup(s, i, "❌ Failed to send news items.")
+		return
+	}
+
+	logger().Infof("Sent %d news items %s%s via slash command", len(newsItems), period, filters)
 }
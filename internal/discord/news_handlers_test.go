@@ -4,8 +4,13 @@
 package discord
 
 import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
 	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
 	"github.com/FracKenA/sto_news_discord_bot/internal/types"
 
@@ -160,3 +165,136 @@ func createMockNewsInteractionWithTag(tag string) *discordgo.InteractionCreate {
 	}
 	return interaction
 }
+
+// dateRangeInteraction builds a date range command interaction with string options.
+func dateRangeInteraction(name string, options map[string]string) *discordgo.InteractionCreate {
+	var data []*discordgo.ApplicationCommandInteractionDataOption
+	for option, value := range options {
+		data = append(data, &discordgo.ApplicationCommandInteractionDataOption{Name: option, Type: discordgo.ApplicationCommandOptionString, Value: value})
+	}
+	return &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			ID:        "interaction-1",
+			AppID:     "app-1",
+			Token:     "interaction-token",
+			Type:      discordgo.InteractionApplicationCommand,
+			GuildID:   "guild-1",
+			ChannelID: "channel-a",
+			Data: discordgo.ApplicationCommandInteractionData{
+				Name:    name,
+				Options: data,
+			},
+		},
+	}
+}
+
+func TestHandleNewsDateRange(t *testing.T) {
+	tests := []struct {
+		name        string
+		command     string
+		options     map[string]string
+		expected    string
+		embeds      int
+		acknowledge bool
+	}{
+		{
+			name:        "since",
+			command:     "stobot_news_since",
+			options:     map[string]string{"date": "2024-06-01"},
+			expected:    "News since 2024-06-01** (2 items)",
+			embeds:      2,
+			acknowledge: true,
+		},
+		{
+			name:        "since with tag",
+			command:     "stobot_news_since",
+			options:     map[string]string{"date": "2024-06-01", "tag": "patch-notes"},
+			expected:    "News since 2024-06-01, tag patch-notes** (1 items)",
+			embeds:      1,
+			acknowledge: true,
+		},
+		{
+			name:        "between is inclusive of the end date",
+			command:     "stobot_news_between",
+			options:     map[string]string{"start": "2024-05-01", "end": "2024-06-01"},
+			expected:    "News from 2024-05-01 to 2024-06-01** (2 items)",
+			embeds:      2,
+			acknowledge: true,
+		},
+		{
+			name:        "nothing found",
+			command:     "stobot_news_since",
+			options:     map[string]string{"date": "2030-01-01"},
+			expected:    "No cached news found since 2030-01-01.",
+			acknowledge: true,
+		},
+		{
+			name:     "invalid date",
+			command:  "stobot_news_since",
+			options:  map[string]string{"date": "06/01/2024"},
+			expected: "must be a date in YYYY-MM-DD format",
+		},
+		{
+			name:     "end before start",
+			command:  "stobot_news_between",
+			options:  map[string]string{"start": "2024-06-05", "end": "2024-06-01"},
+			expected: "must not be before",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := database.InitDatabase(filepath.Join(t.TempDir(), "stobot.db"))
+			if err != nil {
+				t.Fatalf("Failed to initialize database: %v", err)
+			}
+			defer db.Close()
+
+			newsItems := []types.NewsItem{
+				{ID: 1, Title: "May Event", Tags: []string{"events"}, Platforms: []string{"pc"}, Updated: time.Date(2024, 5, 20, 12, 0, 0, 0, time.UTC)},
+				{ID: 2, Title: "Patch Notes", Tags: []string{"patch-notes"}, Platforms: []string{"pc"}, Updated: time.Date(2024, 6, 1, 18, 0, 0, 0, time.UTC)},
+				{ID: 3, Title: "June Event", Tags: []string{"events"}, Platforms: []string{"pc"}, Updated: time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC)},
+			}
+			if err := database.StoreNews(db, newsItems, database.BulkDatabaseOptions()); err != nil {
+				t.Fatalf("Failed to store news: %v", err)
+			}
+
+			fake := testhelpers.NewFakeDiscord(t)
+			bot := &types.Bot{Session: fake.Session(), DB: db, Config: &types.Config{}}
+
+			HandleCommand(bot, bot.Session, dateRangeInteraction(tt.command, tt.options))
+
+			callbacks := fake.RequestsTo("POST", "/interactions/interaction-1/interaction-token/callback")
+			if len(callbacks) != 1 {
+				t.Fatalf("Expected 1 interaction response, got %d", len(callbacks))
+			}
+
+			if !tt.acknowledge {
+				// Invalid input is answered directly without querying
+				var response discordgo.InteractionResponse
+				if err := json.Unmarshal(callbacks[0].Body, &response); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				if response.Data == nil || !strings.Contains(response.Data.Content, tt.expected) {
+					t.Errorf("Expected error response containing %q, got %+v", tt.expected, response.Data)
+				}
+				return
+			}
+
+			followups := fake.RequestsTo("POST", "/webhooks/app-1/interaction-token")
+			if len(followups) != 1 {
+				t.Fatalf("Expected 1 followup, got %d", len(followups))
+			}
+			var params discordgo.WebhookParams
+			if err := json.Unmarshal(followups[0].Body, &params); err != nil {
+				t.Fatalf("Failed to decode followup: %v", err)
+			}
+			if !strings.Contains(params.Content, tt.expected) {
+				t.Errorf("Expected followup containing %q, got %q", tt.expected, params.Content)
+			}
+			if len(params.Embeds) != tt.embeds {
+				t.Errorf("Expected %d embeds, got %d", tt.embeds, len(params.Embeds))
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package discord
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// SearchPageSize is the number of search results shown per page.
+const SearchPageSize = 5
+
+// SearchPageTTL is how long the Previous/Next buttons of paged search results keep working.
+const SearchPageTTL = 10 * time.Minute
+
+// searchPagePrefix starts the custom ID of the paging buttons, which is
+// "search_page:<interaction ID>:<target page>".
+const searchPagePrefix = "search_page"
+
+// searchPageExpiredMessage replaces the header of paged search results once they have expired.
+const searchPageExpiredMessage = "⌛ These search results have expired. Run the search again to page through them."
+
+// pagedSearch is a search result set kept in memory for paging.
+type pagedSearch struct {
+	content   string                    // content is the header shown above every page.
+	embeds    []*discordgo.MessageEmbed // embeds are the results, one per embed.
+	expiresAt time.Time                 // expiresAt is when the buttons stop working.
+}
+
+// pages returns the number of pages of the result set.
+func (p *pagedSearch) pages() int {
+	return (len(p.embeds) + SearchPageSize - 1) / SearchPageSize
+}
+
+// searchPageStore keeps paged search results by the ID of the interaction that ran the search.
+type searchPageStore struct {
+	ttl time.Duration
+	now func() time.Time // now is the clock results expire by (replaced in tests).
+
+	mu      sync.Mutex
+	results map[string]*pagedSearch
+}
+
+// newSearchPageStore returns a store whose results expire after ttl.
+func newSearchPageStore(ttl time.Duration) *searchPageStore {
+	return &searchPageStore{
+		ttl:     ttl,
+		now:     time.Now,
+		results: make(map[string]*pagedSearch),
+	}
+}
+
+// searchPages holds the paged search results of all search commands.
+var searchPages = newSearchPageStore(SearchPageTTL)
+
+// put stores a result set under key and removes expired ones.
+func (s *searchPageStore) put(key, content string, embeds []*discordgo.MessageEmbed) *pagedSearch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for k, results := range s.results {
+		if !now.Before(results.expiresAt) {
+			delete(s.results, k)
+		}
+	}
+
+	results := &pagedSearch{content: content, embeds: embeds, expiresAt: now.Add(s.ttl)}
+	s.results[key] = results
+	return results
+}
+
+// get returns the result set stored under key, unless it has expired.
+func (s *searchPageStore) get(key string) (*pagedSearch, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	results, ok := s.results[key]
+	if !ok {
+		return nil, false
+	}
+	if !s.now().Before(results.expiresAt) {
+		delete(s.results, key)
+		return nil, false
+	}
+	return results, true
+}
+
+// FollowupWithPages sends search results as a private follow-up. Up to SearchPageSize results are
+// sent as they are; more are sent SearchPageSize at a time with Previous/Next buttons, which work
+// for SearchPageTTL.
+func FollowupWithPages(s *discordgo.Session, i *discordgo.InteractionCreate, content string, embeds []*discordgo.MessageEmbed) error {
+	if len(embeds) <= SearchPageSize {
+		return FollowupWithEmbeds(s, i, content, embeds)
+	}
+	if i == nil || i.Interaction == nil {
+		return fmt.Errorf("nil session or interaction")
+	}
+
+	results := searchPages.put(i.ID, content, embeds)
+	data := searchPageData(i.ID, results, 0)
+	return FollowupWithComponents(s, i, data.Content, data.Embeds, data.Components)
+}
+
+// searchPageData builds the message showing a page of results; page is clamped to the result set.
+func searchPageData(key string, results *pagedSearch, page int) *discordgo.InteractionResponseData {
+	pages := results.pages()
+	if page >= pages {
+		page = pages - 1
+	}
+	if page < 0 {
+		page = 0
+	}
+
+	end := (page + 1) * SearchPageSize
+	if end > len(results.embeds) {
+		end = len(results.embeds)
+	}
+
+	return &discordgo.InteractionResponseData{
+		Content:    fmt.Sprintf("%s\n📄 Page %d/%d", results.content, page+1, pages),
+		Embeds:     results.embeds[page*SearchPageSize : end],
+		Components: searchPageButtons(key, page, pages, false),
+	}
+}
+
+// searchPageButtons returns the Previous/Next buttons for a page; disabled disables both.
+func searchPageButtons(key string, page, pages int, disabled bool) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "Previous",
+					Style:    discordgo.SecondaryButton,
+					Emoji:    discordgo.ComponentEmoji{Name: "◀️"},
+					CustomID: searchPageID(key, page-1),
+					Disabled: disabled || page <= 0,
+				},
+				discordgo.Button{
+					Label:    "Next",
+					Style:    discordgo.SecondaryButton,
+					Emoji:    discordgo.ComponentEmoji{Name: "▶️"},
+					CustomID: searchPageID(key, page+1),
+					Disabled: disabled || page >= pages-1,
+				},
+			},
+		},
+	}
+}
+
+// searchPageID returns the custom ID of a button showing page of the results stored under key.
+func searchPageID(key string, page int) string {
+	return fmt.Sprintf("%s:%s:%d", searchPagePrefix, key, page)
+}
+
+// parseSearchPageID parses a custom ID built by searchPageID.
+func parseSearchPageID(customID string) (key string, page int, ok bool) {
+	parts := strings.Split(customID, ":")
+	if len(parts) != 3 || parts[0] != searchPagePrefix || parts[1] == "" {
+		return "", 0, false
+	}
+	page, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return "", 0, false
+	}
+	return parts[1], page, true
+}
+
+// handleSearchPage handles a click on a paging button by showing the requested page in place.
+// Once the results have expired, the message says so and its buttons are disabled.
+func handleSearchPage(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	key, page, ok := parseSearchPageID(i.MessageComponentData().CustomID)
+	if !ok {
+		logger().Warnf("Invalid search page button: %s", i.MessageComponentData().CustomID)
+		return
+	}
+
+	var data *discordgo.InteractionResponseData
+	if results, found := searchPages.get(key); found {
+		data = searchPageData(key, results, page)
+	} else {
+		data = &discordgo.InteractionResponseData{
+			Content:    searchPageExpiredMessage,
+			Components: searchPageButtons(key, page, page+1, true),
+		}
+	}
+
+	operation := func() error {
+		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: data,
+		})
+	}
+	if err := withRetry(operation, DefaultRetryConfig()); err != nil {
+		logger().Errorf("Failed to update search results page: %v", err)
+	}
+}
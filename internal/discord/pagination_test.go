@@ -0,0 +1,230 @@
+package discord
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// pagedMessage is the part of a message with paging buttons the tests check.
+type pagedMessage struct {
+	Content    string                    `json:"content"`
+	Embeds     []*discordgo.MessageEmbed `json:"embeds"`
+	Components []struct {
+		Components []struct {
+			CustomID string `json:"custom_id"`
+			Disabled bool   `json:"disabled"`
+		} `json:"components"`
+	} `json:"components"`
+}
+
+// buttons returns the custom IDs of the message's buttons and whether each is disabled.
+func (m pagedMessage) buttons() map[string]bool {
+	buttons := make(map[string]bool)
+	for _, row := range m.Components {
+		for _, button := range row.Components {
+			buttons[button.CustomID] = button.Disabled
+		}
+	}
+	return buttons
+}
+
+// setSearchPages replaces the paged search store for the duration of a test.
+func setSearchPages(t *testing.T, store *searchPageStore) {
+	t.Helper()
+	previous := searchPages
+	searchPages = store
+	t.Cleanup(func() { searchPages = previous })
+}
+
+func componentInteraction(customID string) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			ID:        "interaction-2",
+			AppID:     "app-1",
+			Token:     "component-token",
+			Type:      discordgo.InteractionMessageComponent,
+			GuildID:   "guild-1",
+			ChannelID: "channel-a",
+			Data:      discordgo.MessageComponentInteractionData{CustomID: customID, ComponentType: discordgo.ButtonComponent},
+		},
+	}
+}
+
+// clickSearchPage clicks a paging button and returns the updated message.
+func clickSearchPage(t *testing.T, fake *testhelpers.FakeDiscord, bot *types.Bot, customID string) pagedMessage {
+	t.Helper()
+	before := len(fake.RequestsTo("POST", "/interactions/interaction-2/component-token/callback"))
+
+	InteractionCreate(bot)(bot.Session, componentInteraction(customID))
+
+	callbacks := fake.RequestsTo("POST", "/interactions/interaction-2/component-token/callback")
+	if len(callbacks) != before+1 {
+		t.Fatalf("Expected a response to the button click, got %d", len(callbacks)-before)
+	}
+	var response struct {
+		Type discordgo.InteractionResponseType `json:"type"`
+		Data pagedMessage                      `json:"data"`
+	}
+	if err := json.Unmarshal(callbacks[len(callbacks)-1].Body, &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Type != discordgo.InteractionResponseUpdateMessage {
+		t.Errorf("Expected the message to be updated in place, got response type %d", response.Type)
+	}
+	return response.Data
+}
+
+func TestSearchResultPages(t *testing.T) {
+	clock := time.Date(2024, 6, 11, 12, 0, 0, 0, time.UTC)
+	store := newSearchPageStore(SearchPageTTL)
+	store.now = func() time.Time { return clock }
+	setSearchPages(t, store)
+
+	db, err := database.InitDatabase(filepath.Join(t.TempDir(), "stobot.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	var newsItems []types.NewsItem
+	for id := int64(1); id <= 12; id++ {
+		newsItems = append(newsItems, types.NewsItem{
+			ID: id, Title: fmt.Sprintf("Borg Report %d", id), Summary: "The Borg", Content: "Borg",
+			Tags: []string{"events"}, Platforms: []string{"pc"}, Updated: time.Now().Add(-time.Duration(id) * time.Hour),
+		})
+	}
+	if err := database.StoreNews(db, newsItems, database.BulkDatabaseOptions()); err != nil {
+		t.Fatalf("Failed to store news: %v", err)
+	}
+
+	fake := testhelpers.NewFakeDiscord(t)
+	bot := &types.Bot{Session: fake.Session(), DB: db, Config: &types.Config{}}
+
+	limit := &discordgo.ApplicationCommandInteractionDataOption{Name: "limit", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(12)}
+	InteractionCreate(bot)(bot.Session, discoveryInteraction("stobot_advanced_search", stringOption("query", "borg"), limit))
+
+	followups := fake.RequestsTo("POST", "/webhooks/app-1/interaction-token")
+	if len(followups) != 1 {
+		t.Fatalf("Expected 1 followup, got %d", len(followups))
+	}
+	var first pagedMessage
+	if err := json.Unmarshal(followups[0].Body, &first); err != nil {
+		t.Fatalf("Failed to decode followup: %v", err)
+	}
+	if len(first.Embeds) != SearchPageSize || !strings.Contains(first.Content, "(12 found)\n📄 Page 1/3") {
+		t.Errorf("Expected the first page of 5 of 12 results, got %d embeds and %q", len(first.Embeds), first.Content)
+	}
+	expectedButtons := map[string]bool{"search_page:interaction-1:-1": true, "search_page:interaction-1:1": false}
+	if buttons := first.buttons(); fmt.Sprint(buttons) != fmt.Sprint(expectedButtons) {
+		t.Errorf("Expected buttons %v, got %v", expectedButtons, buttons)
+	}
+
+	tests := []struct {
+		name     string
+		customID string
+		page     string
+		embeds   int
+		firstID  string
+		buttons  map[string]bool
+	}{
+		{"next", "search_page:interaction-1:1", "Page 2/3", 5, "#6 - ",
+			map[string]bool{"search_page:interaction-1:0": false, "search_page:interaction-1:2": false}},
+		{"last", "search_page:interaction-1:2", "Page 3/3", 2, "#11 - ",
+			map[string]bool{"search_page:interaction-1:1": false, "search_page:interaction-1:3": true}},
+		{"previous", "search_page:interaction-1:0", "Page 1/3", 5, "#1 - ",
+			map[string]bool{"search_page:interaction-1:-1": true, "search_page:interaction-1:1": false}},
+		{"out of range", "search_page:interaction-1:7", "Page 3/3", 2, "#11 - ",
+			map[string]bool{"search_page:interaction-1:1": false, "search_page:interaction-1:3": true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := clickSearchPage(t, fake, bot, tt.customID)
+			if !strings.Contains(message.Content, tt.page) {
+				t.Errorf("Expected %s, got %q", tt.page, message.Content)
+			}
+			if len(message.Embeds) != tt.embeds {
+				t.Fatalf("Expected %d embeds, got %d", tt.embeds, len(message.Embeds))
+			}
+			if !strings.HasPrefix(message.Embeds[0].Title, tt.firstID) {
+				t.Errorf("Expected the page to start with result %s, got %q", tt.firstID, message.Embeds[0].Title)
+			}
+			if buttons := message.buttons(); fmt.Sprint(buttons) != fmt.Sprint(tt.buttons) {
+				t.Errorf("Expected buttons %v, got %v", tt.buttons, buttons)
+			}
+		})
+	}
+
+	// Once the results expire, a click disables the buttons
+	clock = clock.Add(SearchPageTTL)
+	message := clickSearchPage(t, fake, bot, "search_page:interaction-1:1")
+	if message.Content != searchPageExpiredMessage {
+		t.Errorf("Expected the expired message, got %q", message.Content)
+	}
+	for customID, disabled := range message.buttons() {
+		if !disabled {
+			t.Errorf("Expected button %s to be disabled", customID)
+		}
+	}
+}
+
+func TestSearchResultsFitOnOnePage(t *testing.T) {
+	setSearchPages(t, newSearchPageStore(SearchPageTTL))
+	fake := testhelpers.NewFakeDiscord(t)
+
+	embeds := []*discordgo.MessageEmbed{{Title: "One"}, {Title: "Two"}}
+	if err := FollowupWithPages(fake.Session(), discoveryInteraction("stobot_fuzzy_search"), "Results", embeds); err != nil {
+		t.Fatalf("Failed to send results: %v", err)
+	}
+
+	followups := fake.RequestsTo("POST", "/webhooks/app-1/interaction-token")
+	if len(followups) != 1 {
+		t.Fatalf("Expected 1 followup, got %d", len(followups))
+	}
+	var message pagedMessage
+	if err := json.Unmarshal(followups[0].Body, &message); err != nil {
+		t.Fatalf("Failed to decode followup: %v", err)
+	}
+	if len(message.Embeds) != 2 || len(message.Components) != 0 || message.Content != "Results" {
+		t.Errorf("Expected both results without buttons, got %+v", message)
+	}
+	if _, ok := searchPages.get("interaction-1"); ok {
+		t.Error("Expected results that fit on one page not to be stored")
+	}
+}
+
+func TestHandleComponentRouting(t *testing.T) {
+	setSearchPages(t, newSearchPageStore(SearchPageTTL))
+
+	tests := []struct {
+		name        string
+		interaction *discordgo.InteractionCreate
+		responses   int
+	}{
+		{"search page", componentInteraction("search_page:interaction-1:1"), 1},
+		{"invalid search page", componentInteraction("search_page:interaction-1:next"), 0},
+		{"unknown component", componentInteraction("other:1"), 0},
+		{"ping", &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{Type: discordgo.InteractionPing}}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := testhelpers.NewFakeDiscord(t)
+			bot := &types.Bot{Session: fake.Session(), Config: &types.Config{}}
+
+			InteractionCreate(bot)(bot.Session, tt.interaction)
+
+			if got := len(fake.Requests()); got != tt.responses {
+				t.Errorf("Expected %d responses, got %d", tt.responses, got)
+			}
+		})
+	}
+}
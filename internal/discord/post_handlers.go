@@ -0,0 +1,96 @@
+package discord
+
+import (
+	"fmt"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/news"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// handlePost handles the "post" command interaction: it posts an article to the channel the way
+// the poller would, e.g. one the poller missed. Articles already posted to the channel are only
+// posted again with the force option.
+func handlePost(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Validate inputs
+	if i == nil || i.Interaction == nil {
+		logger().Warning("handlePost called with nil interaction")
+		return
+	}
+
+	// Check if user has administrator permission
+	if !hasAdminPermission(s, i) {
+		RespondError(s, i, "You need Administrator permission to use this command.")
+		return
+	}
+
+	// Acknowledge interaction; fetching an uncached article can take a while
+	if err := AcknowledgeWithRetry(s, i); err != nil {
+		logger().Errorf("Failed to acknowledge post command: %v", err)
+		return
+	}
+
+	var article string
+	var force bool
+	for _, option := range i.ApplicationCommandData().Options {
+		switch option.Name {
+		case "article":
+			article = option.StringValue()
+		case "force":
+			force = option.BoolValue()
+		}
+	}
+
+	newsID, err := news.ParseArticleID(article)
+	if err != nil {
+		Followup(s, i, "❌ Please give a news ID or an article URL, e.g. `11523743` or `https://playstartrekonline.com/en/news/article/11523743`.")
+		return
+	}
+
+	channelID := i.ChannelID
+	posted, err := database.IsNewsPosted(b, newsID, channelID)
+	if err != nil {
+		channelLogger(channelID).WithField("news_id", newsID).Errorf("Failed to check if news %d was posted to channel %s: %v", newsID, channelID, err)
+		Followup(s, i, "❌ Failed to check whether the article was posted. Please try again later.")
+		return
+	}
+	if posted && !force {
+		Followup(s, i, fmt.Sprintf("⚠️ Article %d was already posted to this channel. Use `force: True` to post it again.", newsID))
+		return
+	}
+
+	newsItem, err := database.GetCachedNewsByID(b, newsID)
+	if err != nil {
+		logger().Errorf("Failed to get cached news %d: %v", newsID, err)
+		Followup(s, i, "❌ Failed to look up the article. Please try again later.")
+		return
+	}
+	if newsItem == nil {
+		if newsItem, err = news.FetchNewsByID(b, newsID); err != nil {
+			logger().Errorf("Failed to fetch news %d: %v", newsID, err)
+			Followup(s, i, "❌ Failed to fetch the article from the news API. Please try again later.")
+			return
+		}
+		// Cache it, so digests and stats know the posted article
+		if newsItem != nil {
+			if err := database.CacheNews(b, []types.NewsItem{*newsItem}); err != nil {
+				logger().Warnf("Failed to cache news %d: %v", newsID, err)
+			}
+		}
+	}
+	if newsItem == nil {
+		Followup(s, i, fmt.Sprintf("❌ No article with ID %d was found.", newsID))
+		return
+	}
+
+	if err := news.PostNewsManually(b, channelID, *newsItem); err != nil {
+		channelLogger(channelID).WithField("news_id", newsID).Errorf("Failed to post news %d to channel %s: %v", newsID, channelID, err)
+		Followup(s, i, "❌ Failed to post the article. Check that the bot can send messages in this channel.")
+		return
+	}
+
+	channelLogger(channelID).WithField("news_id", newsID).Infof("News %d ('%s') posted to channel %s on request", newsID, newsItem.Title, channelID)
+	Followup(s, i, fmt.Sprintf("✅ Posted **%s** to this channel.", TruncateBytes(newsItem.Title, MaxEmbedTitle)))
+}
@@ -0,0 +1,149 @@
+package discord
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// postInteraction returns a /stobot_post command for an article sent by owner-1, the owner of
+// guild-1 in tests that serve the guild.
+func postInteraction(article string, force bool) *discordgo.InteractionCreate {
+	interaction := discoveryInteraction("stobot_post", stringOption("article", article),
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "force", Type: discordgo.ApplicationCommandOptionBoolean, Value: force})
+	interaction.Member = &discordgo.Member{User: &discordgo.User{ID: "owner-1"}}
+	return interaction
+}
+
+func setupPostTest(t *testing.T, ownerID string) (*types.Bot, *testhelpers.FakeDiscord) {
+	t.Helper()
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/news/7" {
+			_, _ = w.Write([]byte(`{"news": {"id": 7, "title": "Missed Article", "summary": "Fetched live", "tags": ["events"]}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(api.Close)
+
+	bot := testhelpers.CreateTestBot(t)
+	t.Cleanup(func() { bot.DB.Close() })
+	bot.Config.BaseURL = api.URL + "/news"
+	fake := testhelpers.NewFakeDiscord(t)
+	bot.Session = fake.Session()
+	fake.Handle("GET", "/guilds/guild-1", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "guild-1", "owner_id": ownerID})
+	})
+
+	if err := database.CacheNews(bot, []types.NewsItem{
+		{ID: 1, Title: "Season Update", Summary: "New season", Updated: time.Now()},
+	}); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+	if err := database.AddChannel(bot, "channel-a"); err != nil {
+		t.Fatalf("Failed to add channel: %v", err)
+	}
+	return bot, fake
+}
+
+// lastFollowup returns the content of the last followup message.
+func lastFollowup(t *testing.T, fake *testhelpers.FakeDiscord) string {
+	t.Helper()
+	calls := fake.RequestsTo("POST", "/webhooks/app-1/interaction-token")
+	if len(calls) == 0 {
+		t.Fatal("Expected a followup")
+	}
+	var message discordgo.WebhookParams
+	if err := json.Unmarshal(calls[len(calls)-1].Body, &message); err != nil {
+		t.Fatalf("Failed to decode followup: %v", err)
+	}
+	return message.Content
+}
+
+func TestPostCommand(t *testing.T) {
+	bot, fake := setupPostTest(t, "owner-1")
+	posts := func() int { return len(fake.RequestsTo("POST", "/channels/channel-a/messages")) }
+
+	handlePost(bot, bot.Session, postInteraction("2", false))
+	if content := lastFollowup(t, fake); !strings.Contains(content, "No article with ID 2") {
+		t.Errorf("Expected unknown news to be reported, got %q", content)
+	}
+
+	handlePost(bot, bot.Session, postInteraction("https://playstartrekonline.com/en/news/article/7", false))
+	if content := lastFollowup(t, fake); !strings.Contains(content, "Posted **Missed Article**") {
+		t.Errorf("Expected the post to be confirmed, got %q", content)
+	}
+	if posts() != 1 {
+		t.Fatalf("Expected 1 post, got %d", posts())
+	}
+	var message discordgo.MessageSend
+	if err := json.Unmarshal(fake.RequestsTo("POST", "/channels/channel-a/messages")[0].Body, &message); err != nil {
+		t.Fatalf("Failed to decode post: %v", err)
+	}
+	if len(message.Embeds) != 1 || message.Embeds[0].Title != "Missed Article" {
+		t.Errorf("Expected the article embed, got %+v", message.Embeds)
+	}
+
+	posted, err := database.IsNewsPosted(bot, 7, "channel-a")
+	if err != nil {
+		t.Fatalf("Failed to check posted news: %v", err)
+	}
+	if !posted {
+		t.Error("Expected the article to be marked as posted")
+	}
+	var delivery string
+	if err := bot.DB.QueryRow(`SELECT delivery FROM posted_news WHERE news_id = 7 AND channel_id = 'channel-a'`).Scan(&delivery); err != nil {
+		t.Fatalf("Failed to get delivery: %v", err)
+	}
+	if delivery != database.DeliveryManual {
+		t.Errorf("Expected delivery %q, got %q", database.DeliveryManual, delivery)
+	}
+	if cached, err := database.GetCachedNewsByID(bot, 7); err != nil || cached == nil {
+		t.Errorf("Expected the fetched article to be cached, got %+v (%v)", cached, err)
+	}
+}
+
+func TestPostCommandAlreadyPosted(t *testing.T) {
+	bot, fake := setupPostTest(t, "owner-1")
+	posts := func() int { return len(fake.RequestsTo("POST", "/channels/channel-a/messages")) }
+
+	// News cached before the channel was registered counts as posted
+	handlePost(bot, bot.Session, postInteraction("1", false))
+	if content := lastFollowup(t, fake); !strings.Contains(content, "already posted") || !strings.Contains(content, "force") {
+		t.Errorf("Expected the article to be reported as posted, got %q", content)
+	}
+	if posts() != 0 {
+		t.Fatalf("Expected no post without force, got %d", posts())
+	}
+
+	handlePost(bot, bot.Session, postInteraction("1", true))
+	if content := lastFollowup(t, fake); !strings.Contains(content, "Posted **Season Update**") {
+		t.Errorf("Expected the post to be confirmed, got %q", content)
+	}
+	if posts() != 1 {
+		t.Errorf("Expected 1 post with force, got %d", posts())
+	}
+}
+
+func TestPostCommandRequiresAdmin(t *testing.T) {
+	bot, fake := setupPostTest(t, "someone-else")
+
+	handlePost(bot, bot.Session, postInteraction("1", true))
+
+	calls := fake.RequestsTo("POST", "/interactions/interaction-1/interaction-token/callback")
+	if len(calls) != 1 || !strings.Contains(string(calls[0].Body), "Administrator permission") {
+		t.Fatalf("Expected a permission error, got %v", calls)
+	}
+	if posts := fake.RequestsTo("POST", "/channels/channel-a/messages"); len(posts) != 0 {
+		t.Errorf("Expected no post without permission, got %d", len(posts))
+	}
+}
@@ -0,0 +1,70 @@
+package discord
+
+import (
+	"fmt"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/news"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// handlePreview handles the "preview" command interaction: it shows the invoker how an article
+// would be posted, without posting it.
+func handlePreview(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Acknowledge interaction; fetching an uncached article can take a while
+	if err := AcknowledgeWithRetry(s, i); err != nil {
+		logger().Errorf("Failed to acknowledge preview command: %v", err)
+		return
+	}
+
+	var article string
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "article" {
+			article = option.StringValue()
+		}
+	}
+
+	newsID, err := news.ParseArticleID(article)
+	if err != nil {
+		Followup(s, i, "❌ Please give a news ID or an article URL, e.g. `11523743` or `https://playstartrekonline.com/en/news/article/11523743`.")
+		return
+	}
+
+	newsItem, err := database.GetCachedNewsByID(b, newsID)
+	if err != nil {
+		logger().Errorf("Failed to get cached news %d: %v", newsID, err)
+		Followup(s, i, "❌ Failed to look up the article. Please try again later.")
+		return
+	}
+	if newsItem == nil {
+		if newsItem, err = news.FetchNewsByID(b, newsID); err != nil {
+			logger().Errorf("Failed to fetch news %d: %v", newsID, err)
+			Followup(s, i, "❌ Failed to fetch the article from the news API. Please try again later.")
+			return
+		}
+	}
+	if newsItem == nil {
+		Followup(s, i, fmt.Sprintf("❌ No article with ID %d was found.", newsID))
+		return
+	}
+
+	// Registered channels preview with their spoiler tags
+	var spoilerTags []string
+	cfg, err := database.GetChannelConfig(b, i.ChannelID)
+	if err != nil {
+		channelLogger(i.ChannelID).Warnf("Failed to get channel config for %s: %v", i.ChannelID, err)
+	} else if cfg != nil {
+		spoilerTags = cfg.SpoilerTags
+	}
+
+	embed := news.BuildNewsEmbed(b, *newsItem, spoilerTags)
+	if err := FollowupWithEmbeds(s, i, "👀 **Preview** — this is how the article will be posted:", []*discordgo.MessageEmbed{embed}); err != nil {
+		logger().Errorf("Failed to send preview: %v", err)
+		Followup(s, i, "❌ Failed to send the preview.")
+		return
+	}
+
+	channelLogger(i.ChannelID).WithField("news_id", newsID).Infof("Sent preview of news %d to channel %s", newsID, i.ChannelID)
+}
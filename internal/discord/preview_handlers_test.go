@@ -0,0 +1,92 @@
+package discord
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestPreviewCommand(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/news/7" {
+			_, _ = w.Write([]byte(`{"news": {"id": 7, "title": "Uncached Article", "summary": "Fetched live", "tags": ["events"]}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer api.Close()
+
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	bot.Config.BaseURL = api.URL + "/news"
+	fake := testhelpers.NewFakeDiscord(t)
+	bot.Session = fake.Session()
+
+	if err := database.CacheNews(bot, []types.NewsItem{
+		{ID: 1, Title: "Season Update", Summary: "New season", Tags: []string{"star-trek-online", "spoilers"}, Updated: time.Now()},
+	}); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		article  string
+		expected string
+		embed    string
+	}{
+		{"cached news by ID", "1", "Preview", "New season"},
+		{"cached news by URL", "https://playstartrekonline.com/en/news/article/1", "Preview", "New season"},
+		{"uncached news from the API", "7", "Preview", "Fetched live"},
+		{"unknown ID", "99", "No article with ID 99", ""},
+		{"not an ID", "season update", "news ID or an article URL", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(fake.RequestsTo("POST", "/webhooks/app-1/interaction-token"))
+			handlePreview(bot, bot.Session, discoveryInteraction("stobot_preview", stringOption("article", tt.article)))
+
+			calls := fake.RequestsTo("POST", "/webhooks/app-1/interaction-token")
+			if len(calls) != before+1 {
+				t.Fatalf("Expected 1 followup, got %d", len(calls)-before)
+			}
+			var message discordgo.WebhookParams
+			if err := json.Unmarshal(calls[len(calls)-1].Body, &message); err != nil {
+				t.Fatalf("Failed to decode followup: %v", err)
+			}
+			if !strings.Contains(message.Content, tt.expected) {
+				t.Errorf("Expected %q in the followup, got %q", tt.expected, message.Content)
+			}
+			if message.Flags&discordgo.MessageFlagsEphemeral == 0 {
+				t.Error("Expected the followup to be ephemeral")
+			}
+			if tt.embed == "" {
+				return
+			}
+			if len(message.Embeds) != 1 || message.Embeds[0].Description != tt.embed {
+				t.Errorf("Expected an embed with %q, got %s", tt.embed, calls[len(calls)-1].Body)
+			}
+		})
+	}
+
+	// Registered channels preview with their spoiler tags
+	if err := database.AddChannel(bot, "channel-a"); err != nil {
+		t.Fatalf("Failed to add channel: %v", err)
+	}
+	if err := database.UpdateChannelSpoilerTags(bot, "channel-a", []string{"spoilers"}); err != nil {
+		t.Fatalf("Failed to set spoiler tags: %v", err)
+	}
+	handlePreview(bot, bot.Session, discoveryInteraction("stobot_preview", stringOption("article", "1")))
+	calls := fake.RequestsTo("POST", "/webhooks/app-1/interaction-token")
+	if body := string(calls[len(calls)-1].Body); !strings.Contains(body, "spoiler hidden") {
+		t.Errorf("Expected the spoiler placeholder, got %s", body)
+	}
+}
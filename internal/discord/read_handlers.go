@@ -0,0 +1,125 @@
+package discord
+
+import (
+	"fmt"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/news"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxReadEmbeds is the number of embeds /stobot_read shows of an article; longer articles end
+// with a link to the full text.
+const maxReadEmbeds = 5
+
+// handleRead handles the "read" command interaction: it shows the invoker the full text of an
+// article, split over several embeds.
+func handleRead(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Acknowledge interaction; fetching uncached content can take a while
+	if err := AcknowledgeWithRetry(s, i); err != nil {
+		logger().Errorf("Failed to acknowledge read command: %v", err)
+		return
+	}
+
+	var article string
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "article" {
+			article = option.StringValue()
+		}
+	}
+
+	newsID, err := news.ParseArticleID(article)
+	if err != nil {
+		Followup(s, i, "❌ Please give a news ID or an article URL, e.g. `11523743` or `https://playstartrekonline.com/en/news/article/11523743`.")
+		return
+	}
+
+	newsItem, err := news.LoadArticle(b, newsID)
+	if err != nil {
+		logger().Errorf("Failed to load news %d: %v", newsID, err)
+		Followup(s, i, "❌ Failed to load the article. Please try again later.")
+		return
+	}
+	if newsItem == nil {
+		Followup(s, i, fmt.Sprintf("❌ No article with ID %d was found.", newsID))
+		return
+	}
+
+	embeds := formatArticleEmbeds(b, *newsItem)
+	for start := 0; start < len(embeds); {
+		end := embedBatchEnd(embeds, start)
+		content := ""
+		if start == 0 {
+			content = fmt.Sprintf("📖 **%s**", TruncateBytes(newsItem.Title, MaxEmbedTitle))
+		}
+		if err := FollowupWithEmbeds(s, i, content, embeds[start:end]); err != nil {
+			logger().Errorf("Failed to send article %d: %v", newsID, err)
+			Followup(s, i, "❌ Failed to send the article.")
+			return
+		}
+		start = end
+	}
+
+	logger().Infof("Sent %d parts of news %d", len(embeds), newsID)
+}
+
+// formatArticleEmbeds splits an article's content over at most maxReadEmbeds embeds. The first
+// embed links to the article; if the content does not fit, the last one ends with a link to it.
+// An article without content shows its summary.
+func formatArticleEmbeds(b *types.Bot, newsItem types.NewsItem) []*discordgo.MessageEmbed {
+	var rules []types.URLRewriteRule
+	var colors map[string]int
+	if b.Config != nil {
+		rules = b.Config.URLRewrites
+		colors = b.Config.EmbedColors
+	}
+	link, _ := types.RewriteURL(newsItem.Link(), rules)
+
+	text := newsItem.Content
+	if text == "" {
+		text = newsItem.Summary
+	}
+	if text == "" {
+		text = "This article has no text."
+	}
+
+	chunks := SplitText(text, MaxEmbedDescription)
+	if len(chunks) > maxReadEmbeds {
+		chunks = chunks[:maxReadEmbeds]
+		readMore := fmt.Sprintf("\n\n[Read the full article](%s)", link)
+		chunks[maxReadEmbeds-1] = TruncateBytes(chunks[maxReadEmbeds-1], MaxEmbedDescription-len(readMore)) + readMore
+	}
+
+	embeds := make([]*discordgo.MessageEmbed, len(chunks))
+	for n, chunk := range chunks {
+		embeds[n] = &discordgo.MessageEmbed{
+			Description: chunk,
+			Color:       news.EmbedColor(newsItem, colors),
+		}
+		if len(chunks) > 1 {
+			embeds[n].Footer = &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Part %d/%d", n+1, len(chunks))}
+		}
+	}
+	embeds[0].Title = TruncateBytes(newsItem.Title, MaxEmbedTitle)
+	embeds[0].URL = link
+	return embeds
+}
+
+// embedBatchEnd returns the end of the batch of embeds starting at start that fits in one message.
+func embedBatchEnd(embeds []*discordgo.MessageEmbed, start int) int {
+	total := 0
+	end := start
+	for end < len(embeds) && end-start < MaxEmbedsPerMessage {
+		size := len(embeds[end].Title) + len(embeds[end].Description)
+		if embeds[end].Footer != nil {
+			size += len(embeds[end].Footer.Text)
+		}
+		if end > start && total+size > MaxEmbedsTotalText {
+			break
+		}
+		total += size
+		end++
+	}
+	return end
+}
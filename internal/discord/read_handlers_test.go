@@ -0,0 +1,119 @@
+package discord
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestReadCommand(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/news/4" {
+			_, _ = w.Write([]byte(`{"news": {"id": 4, "title": "Dev Blog", "content": "<p>Fetched <em>live</em>.</p>"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer api.Close()
+
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	bot.Config.BaseURL = api.URL + "/news"
+	fake := testhelpers.NewFakeDiscord(t)
+	bot.Session = fake.Session()
+
+	sentence := "The fleet gathers at Earth Spacedock for the anniversary. "
+	if err := database.CacheNews(bot, []types.NewsItem{
+		{ID: 1, Title: "Short Article", Content: "Just one paragraph.", Updated: time.Now()},
+		{ID: 2, Title: "Long Article", Content: strings.Repeat(sentence, 120), Updated: time.Now()},
+		{ID: 3, Title: "Endless Article", Content: strings.Repeat(sentence, 1000), Updated: time.Now()},
+		{ID: 4, Title: "Dev Blog", Summary: "Summary only", Updated: time.Now()},
+	}); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		article   string
+		expected  string
+		followups int
+		parts     int
+		first     string
+		readMore  bool
+	}{
+		{"short article", "1", "Short Article", 1, 1, "Just one paragraph.", false},
+		{"long article", "2", "Long Article", 2, 2, "The fleet gathers", false},
+		{"article over the part limit", "https://playstartrekonline.com/en/news/article/3", "Endless Article", maxReadEmbeds, maxReadEmbeds, "The fleet gathers", true},
+		{"uncached content", "4", "Dev Blog", 1, 1, "Fetched live.", false},
+		{"unknown ID", "99", "No article with ID 99", 1, 0, "", false},
+		{"not an ID", "dev blog", "news ID or an article URL", 1, 0, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(fake.RequestsTo("POST", "/webhooks/app-1/interaction-token"))
+			handleRead(bot, bot.Session, discoveryInteraction("stobot_read", stringOption("article", tt.article)))
+
+			calls := fake.RequestsTo("POST", "/webhooks/app-1/interaction-token")[before:]
+			if len(calls) != tt.followups {
+				t.Fatalf("Expected %d followups, got %d", tt.followups, len(calls))
+			}
+
+			var embeds []*discordgo.MessageEmbed
+			for n, call := range calls {
+				var message discordgo.WebhookParams
+				if err := json.Unmarshal(call.Body, &message); err != nil {
+					t.Fatalf("Failed to decode followup: %v", err)
+				}
+				if message.Flags&discordgo.MessageFlagsEphemeral == 0 {
+					t.Error("Expected the followup to be ephemeral")
+				}
+				if n == 0 && !strings.Contains(message.Content, tt.expected) {
+					t.Errorf("Expected %q in the followup, got %q", tt.expected, message.Content)
+				}
+				total := 0
+				for _, embed := range message.Embeds {
+					if len(embed.Description) > MaxEmbedDescription {
+						t.Errorf("Expected descriptions of at most %d bytes, got %d", MaxEmbedDescription, len(embed.Description))
+					}
+					total += len(embed.Title) + len(embed.Description)
+				}
+				if total > MaxEmbedsTotalText {
+					t.Errorf("Expected at most %d bytes of embed text per message, got %d", MaxEmbedsTotalText, total)
+				}
+				embeds = append(embeds, message.Embeds...)
+			}
+
+			if len(embeds) != tt.parts {
+				t.Fatalf("Expected %d parts, got %d", tt.parts, len(embeds))
+			}
+			if tt.parts == 0 {
+				return
+			}
+			if !strings.HasPrefix(embeds[0].Description, tt.first) {
+				t.Errorf("Expected the article to start with %q, got %q", tt.first, embeds[0].Description)
+			}
+			last := embeds[len(embeds)-1].Description
+			if hasLink := strings.Contains(last, "[Read the full article](https://playstartrekonline.com/en/news/article/3)"); hasLink != tt.readMore {
+				t.Errorf("Expected a read more link: %v, got %q", tt.readMore, last[max(0, len(last)-100):])
+			}
+		})
+	}
+
+	// Fetched content is cached
+	cached, err := database.GetCachedNewsByID(bot, 4)
+	if err != nil || cached == nil {
+		t.Fatalf("Failed to get cached news: %v", err)
+	}
+	if cached.Content != "Fetched live." {
+		t.Errorf("Expected the fetched content to be cached, got %q", cached.Content)
+	}
+}
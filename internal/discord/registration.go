@@ -1,21 +1,27 @@
 package discord
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/news"
 	"github.com/FracKenA/sto_news_discord_bot/internal/types"
 
 	"github.com/bwmarrin/discordgo"
-	log "github.com/sirupsen/logrus"
 )
 
-// handleRegister handles the "register" command interaction
+// channelSetups lets tests wait for the setups of newly registered channels.
+var channelSetups sync.WaitGroup
+
+// handleRegister handles the "register" command interaction. A new channel is set up in the
+// background after the reply, as marking or posting a large news cache can take a while.
 func handleRegister(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
 	// Validate inputs
 	if i == nil || i.Interaction == nil {
-		log.Warning("handleRegister called with nil interaction")
+		logger().Warning("handleRegister called with nil interaction")
 		return
 	}
 
@@ -27,33 +33,73 @@ func handleRegister(b *types.Bot, s *discordgo.Session, i *discordgo.Interaction
 
 	// Acknowledge interaction with timeout handling
 	if err := AcknowledgeWithRetry(s, i); err != nil {
-		log.Errorf("Failed to acknowledge register command: %v", err)
+		logger().Errorf("Failed to acknowledge register command: %v", err)
 		return
 	}
 
 	data := i.ApplicationCommandData()
 	platforms := "pc,xbox,ps" // default
+	var tags []string         // default: all tags
+	var pingRole string       // default: no role mention
+	var backfillValue string  // default: none
+	createThreads := false
 
 	for _, option := range data.Options {
 		if option.Name == "platforms" && option.StringValue() != "" {
 			platforms = option.StringValue()
 		}
+		if option.Name == "tags" {
+			tags = parseTagList(option.StringValue())
+		}
+		if option.Name == "ping_role" {
+			pingRole = option.RoleValue(nil, "").ID
+		}
+		if option.Name == "create_threads" {
+			createThreads = option.BoolValue()
+		}
+		if option.Name == "backfill" {
+			backfillValue = option.StringValue()
+		}
+	}
+
+	// Reject unknown platforms before registering anything
+	platformList, err := types.ParsePlatforms(platforms)
+	if err != nil {
+		Followup(s, i, fmt.Sprintf("❌ Invalid platforms: %v", err))
+		return
+	}
+	platforms = strings.Join(platformList, ",")
+
+	backfill, err := news.ParseRegisterBackfill(backfillValue)
+	if err != nil {
+		Followup(s, i, fmt.Sprintf("❌ Invalid backfill: %v", err))
+		return
 	}
 
 	channelID := i.ChannelID
 
-	err := database.AddChannel(b, channelID)
+	// Polls skip the channel until it is set up, so they do not post the cached news first
+	finishSetup := news.BeginChannelSetup(channelID)
+	isNewChannel, err := database.RegisterChannel(b, channelID)
 	if err != nil {
+		finishSetup()
 		Followup(s, i, fmt.Sprintf("❌ Failed to register channel: %v", err))
 		return
 	}
+	if !isNewChannel {
+		finishSetup()
+	} else {
+		// Set up even if a setting below fails, so the channel never gets the backlog unasked
+		defer startChannelSetup(b, channelID, backfill, finishSetup)
+	}
+
+	if err := database.UpdateChannelGuild(b, channelID, i.GuildID); err != nil {
+		Followup(s, i, fmt.Sprintf("❌ Channel registered but failed to record its server: %v", err))
+		return
+	}
 
 	// Update platforms if specified
 	if platforms != "pc,xbox,ps" {
-		platformList := strings.Split(platforms, ",")
-		for i := range platformList {
-			platformList[i] = strings.TrimSpace(platformList[i])
-		}
 		err = database.UpdateChannelPlatforms(b, channelID, platformList)
 		if err != nil {
 			Followup(s, i, fmt.Sprintf("❌ Channel registered but failed to update platforms: %v", err))
@@ -61,14 +107,68 @@ func handleRegister(b *types.Bot, s *discordgo.Session, i *discordgo.Interaction
 		}
 	}
 
-	Followup(s, i, fmt.Sprintf("✅ Channel registered for STO news updates!\nPlatforms: %s", platforms))
+	if len(tags) > 0 {
+		if err := database.UpdateChannelTags(b, channelID, tags); err != nil {
+			Followup(s, i, fmt.Sprintf("❌ Channel registered but failed to update tags: %v", err))
+			return
+		}
+	}
+
+	if pingRole != "" {
+		if err := database.UpdateChannelPingRole(b, channelID, pingRole); err != nil {
+			Followup(s, i, fmt.Sprintf("❌ Channel registered but failed to update ping role: %v", err))
+			return
+		}
+	}
+
+	if createThreads {
+		if err := database.UpdateChannelCreateThreads(b, channelID, true); err != nil {
+			Followup(s, i, fmt.Sprintf("❌ Channel registered but failed to enable threads: %v", err))
+			return
+		}
+	}
+
+	message := fmt.Sprintf("✅ Channel registered for STO news updates!\nPlatforms: %s\nTags: %s\nPing Role: %s\nThreads: %s",
+		platforms, formatChannelTags(tags), formatPingRole(pingRole), formatEnabled(createThreads))
+	if isNewChannel {
+		message += "\nBackfill: " + formatRegisterBackfill(b, backfill)
+	}
+	Followup(s, i, message)
+}
+
+// startChannelSetup sets up a newly registered channel in the background and ends its setup,
+// begun with news.BeginChannelSetup, once done.
+func startChannelSetup(b *types.Bot, channelID string, backfill news.RegisterBackfill, finish func()) {
+	channelSetups.Add(1)
+	go func() {
+		defer channelSetups.Done()
+		defer finish()
+		posted, err := news.SetUpChannel(context.Background(), b, channelID, backfill)
+		if err != nil {
+			channelLogger(channelID).Errorf("Failed to set up new channel %s: %v", channelID, err)
+			return
+		}
+		channelLogger(channelID).Infof("Set up new channel %s with backfill %s, posted %d news items", channelID, backfill, posted)
+	}()
+}
+
+// formatRegisterBackfill describes what a new channel is sent of the cached news.
+func formatRegisterBackfill(b *types.Bot, backfill news.RegisterBackfill) string {
+	switch backfill {
+	case news.RegisterBackfillRecent:
+		return fmt.Sprintf("posting up to %d of the newest news items", news.RegisterBackfillCount(b))
+	case news.RegisterBackfillAll:
+		return "posting all cached news"
+	default:
+		return "none, only new news is posted"
+	}
 }
 
 // handleUnregister handles the "unregister" command interaction
 func handleUnregister(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
 	// Validate inputs
 	if i == nil || i.Interaction == nil {
-		log.Warning("handleUnregister called with nil interaction")
+		logger().Warning("handleUnregister called with nil interaction")
 		return
 	}
 
@@ -83,60 +183,1214 @@ func handleUnregister(b *types.Bot, s *discordgo.Session, i *discordgo.Interacti
 	// Remove channel from database
 	err := database.RemoveChannel(b, channelID)
 	if err != nil {
-		log.Errorf("Failed to unregister channel %s: %v", channelID, err)
+		channelLogger(channelID).Errorf("Failed to unregister channel %s: %v", channelID, err)
 		RespondError(s, i, "Failed to unregister channel. Please try again later.")
 		return
 	}
 
-	log.Infof("Channel %s unregistered from STO news", channelID)
+	channelLogger(channelID).Infof("Channel %s unregistered from STO news", channelID)
 	Respond(s, i, "✅ Channel successfully unregistered from Star Trek Online news updates.\n\nThe bot will no longer post news to this channel.")
 }
 
-// handleStatus handles the "status" command interaction
-func handleStatus(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+// parseChannelID returns the channel ID in value, which may also be a channel mention like <#123>.
+func parseChannelID(value string) string {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "<#") && strings.HasSuffix(value, ">") {
+		value = value[2 : len(value)-1]
+	}
+	return value
+}
+
+// handleMigrateChannel handles the "migrate_channel" command interaction, which moves a channel's
+// registration, settings and posting history to another channel of the server, e.g. after the
+// announcements channel was recreated. The invoker needs Administrator permission in the servers
+// of both channels.
+func handleMigrateChannel(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
 	// Validate inputs
-	if b == nil {
-		log.Warning("handleStatus called with nil bot")
-		if s != nil && i != nil {
-			RespondError(s, i, "Bot configuration error. Please try again later.")
+	if i == nil || i.Interaction == nil {
+		logger().Warning("handleMigrateChannel called with nil interaction")
+		return
+	}
+
+	// Check if user has administrator permission
+	if !hasAdminPermission(s, i) {
+		RespondError(s, i, "You need Administrator permission to use this command.")
+		return
+	}
+
+	var oldID, newID string
+	for _, option := range i.ApplicationCommandData().Options {
+		switch option.Name {
+		case "old_channel":
+			oldID = parseChannelID(option.StringValue())
+		case "new_channel":
+			newID = parseChannelID(option.StringValue())
+		}
+	}
+	if oldID == "" || newID == "" {
+		RespondError(s, i, "Both the old and the new channel are required.")
+		return
+	}
+	if oldID == newID {
+		RespondError(s, i, "The old and the new channel are the same channel.")
+		return
+	}
+
+	oldConfig, err := database.GetChannelConfig(b, oldID)
+	if err != nil {
+		logger().Errorf("Failed to get channel config for %s: %v", oldID, err)
+		RespondError(s, i, "Failed to check channel status. Please try again later.")
+		return
+	}
+	if oldConfig == nil {
+		RespondError(s, i, fmt.Sprintf("Channel %s is not registered.", oldID))
+		return
+	}
+	newConfig, err := database.GetChannelConfig(b, newID)
+	if err != nil {
+		logger().Errorf("Failed to get channel config for %s: %v", newID, err)
+		RespondError(s, i, "Failed to check channel status. Please try again later.")
+		return
+	}
+	if newConfig != nil {
+		RespondError(s, i, fmt.Sprintf("<#%s> is already registered. Use `/stobot_unregister` there first.", newID))
+		return
+	}
+
+	// News can only be posted to an existing channel of this server
+	newChannel, err := s.Channel(newID)
+	if err != nil {
+		logger().Warnf("Failed to look up channel %s: %v", newID, err)
+		RespondError(s, i, fmt.Sprintf("Channel %s could not be found. Check the ID and that the bot can see the channel.", newID))
+		return
+	}
+	if newChannel.GuildID != "" && newChannel.GuildID != i.GuildID {
+		RespondError(s, i, "The new channel belongs to a different server. Run the command in that server.")
+		return
+	}
+
+	// The old channel's server is recorded at registration; channels registered before that are
+	// looked up, unless they were deleted
+	oldGuildID := oldConfig.GuildID
+	if oldGuildID == "" {
+		if oldChannel, err := s.Channel(oldID); err == nil {
+			oldGuildID = oldChannel.GuildID
 		}
+	}
+	if oldGuildID != "" && oldGuildID != i.GuildID && !hasGuildAdminPermission(s, oldGuildID, i.Member.User.ID) {
+		RespondError(s, i, "You need Administrator permission in the server of the old channel too.")
+		return
+	}
+
+	moved, err := database.MigrateChannel(b, oldID, newID)
+	if err != nil {
+		logger().Errorf("Failed to migrate channel %s to %s: %v", oldID, newID, err)
+		RespondError(s, i, "Failed to migrate the channel. Please try again later.")
 		return
 	}
+	if err := database.UpdateChannelGuild(b, newID, i.GuildID); err != nil {
+		logger().Errorf("Failed to record the server of channel %s: %v", newID, err)
+	}
+
+	logger().Infof("Channel %s migrated to %s with %d posted news items", oldID, newID, moved)
+	Respond(s, i, fmt.Sprintf("✅ Moved the registration of channel %s to <#%s>, with %d posted articles.\n\n"+
+		"News will be posted to <#%s> from now on, with the same settings. A webhook of the old channel was removed; use `/stobot_set_webhook` to add one.",
+		oldID, newID, moved, newID))
+}
+
+// parseTagList parses a comma-separated list of tags, lowercasing them and dropping empty entries.
+func parseTagList(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// formatChannelTags returns a channel's tag filter for display.
+func formatChannelTags(tags []string) string {
+	if len(tags) == 0 {
+		return "all"
+	}
+	return strings.Join(tags, ", ")
+}
 
+// handleSetTags handles the "set_tags" command interaction
+func handleSetTags(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Validate inputs
 	if i == nil || i.Interaction == nil {
-		log.Warning("handleStatus called with nil interaction")
+		logger().Warning("handleSetTags called with nil interaction")
+		return
+	}
+
+	// Check if user has administrator permission
+	if !hasAdminPermission(s, i) {
+		RespondError(s, i, "You need Administrator permission to use this command.")
 		return
 	}
 
+	var tags []string
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "tags" {
+			tags = parseTagList(option.StringValue())
+		}
+	}
+
 	channelID := i.ChannelID
 
-	// Check if this channel is registered
 	platforms, err := database.GetChannelPlatforms(b, channelID)
 	if err != nil {
-		log.Errorf("Failed to get channel platforms for %s: %v", channelID, err)
+		channelLogger(channelID).Errorf("Failed to get channel platforms for %s: %v", channelID, err)
 		RespondError(s, i, "Failed to check channel status. Please try again later.")
 		return
 	}
+	if len(platforms) == 0 {
+		RespondError(s, i, "This channel is not registered. Use `/stobot_register` first.")
+		return
+	}
 
-	// Get cached news count
-	allNews, err := database.GetAllCachedNews(b)
+	if err := database.UpdateChannelTags(b, channelID, tags); err != nil {
+		channelLogger(channelID).Errorf("Failed to update tags for channel %s: %v", channelID, err)
+		RespondError(s, i, "Failed to update tags. Please try again later.")
+		return
+	}
+
+	channelLogger(channelID).Infof("Channel %s tags set to %v", channelID, tags)
+	if len(tags) == 0 {
+		Respond(s, i, "✅ All news tags will be posted to this channel.")
+		return
+	}
+	Respond(s, i, fmt.Sprintf("✅ Only news tagged %s will be posted to this channel.", strings.Join(tags, ", ")))
+}
+
+// formatPingRole returns a channel's ping role for display.
+func formatPingRole(roleID string) string {
+	if roleID == "" {
+		return "none"
+	}
+	return fmt.Sprintf("<@&%s>", roleID)
+}
+
+// handleSetPingRole handles the "set_ping_role" command interaction
+func handleSetPingRole(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Validate inputs
+	if i == nil || i.Interaction == nil {
+		logger().Warning("handleSetPingRole called with nil interaction")
+		return
+	}
+
+	// Check if user has administrator permission
+	if !hasAdminPermission(s, i) {
+		RespondError(s, i, "You need Administrator permission to use this command.")
+		return
+	}
+
+	var roleID string
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "role" {
+			roleID = option.RoleValue(nil, "").ID
+		}
+	}
+
+	channelID := i.ChannelID
+
+	platforms, err := database.GetChannelPlatforms(b, channelID)
 	if err != nil {
-		log.Errorf("Failed to get cached news count: %v", err)
-		RespondError(s, i, "Failed to get bot status. Please try again later.")
+		channelLogger(channelID).Errorf("Failed to get channel platforms for %s: %v", channelID, err)
+		RespondError(s, i, "Failed to check channel status. Please try again later.")
+		return
+	}
+	if len(platforms) == 0 {
+		RespondError(s, i, "This channel is not registered. Use `/stobot_register` first.")
 		return
 	}
 
-	// Build status message
-	var statusMsg strings.Builder
-	statusMsg.WriteString("🤖 **STOBot Status**\n\n")
+	if err := database.UpdateChannelPingRole(b, channelID, roleID); err != nil {
+		channelLogger(channelID).Errorf("Failed to update ping role for channel %s: %v", channelID, err)
+		RespondError(s, i, "Failed to update the ping role. Please try again later.")
+		return
+	}
 
-	if len(platforms) > 0 {
-		statusMsg.WriteString("✅ **This Channel**: Registered\n")
-		statusMsg.WriteString(fmt.Sprintf("📡 **Platforms**: %s\n", strings.Join(platforms, ", ")))
+	channelLogger(channelID).Infof("Channel %s ping role set to %q", channelID, roleID)
+	if roleID == "" {
+		Respond(s, i, "✅ News posts in this channel will not mention a role.")
+		return
+	}
+	Respond(s, i, fmt.Sprintf("✅ News posts in this channel will mention %s.", formatPingRole(roleID)))
+}
+
+// handleSetWebhook handles the "set_webhook" command interaction. The webhook URL contains
+// its token, so it is never logged or echoed back.
+func handleSetWebhook(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Validate inputs
+	if i == nil || i.Interaction == nil {
+		logger().Warning("handleSetWebhook called with nil interaction")
+		return
+	}
+
+	// Check if user has administrator permission
+	if !hasAdminPermission(s, i) {
+		RespondError(s, i, "You need Administrator permission to use this command.")
+		return
+	}
+
+	var webhookURL string
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "url" {
+			webhookURL = strings.TrimSpace(option.StringValue())
+		}
+	}
+
+	channelID := i.ChannelID
+
+	platforms, err := database.GetChannelPlatforms(b, channelID)
+	if err != nil {
+		channelLogger(channelID).Errorf("Failed to get channel platforms for %s: %v", channelID, err)
+		RespondError(s, i, "Failed to check channel status. Please try again later.")
+		return
+	}
+	if len(platforms) == 0 {
+		RespondError(s, i, "This channel is not registered. Use `/stobot_register` first.")
+		return
+	}
+
+	if webhookURL != "" {
+		id, token, err := news.ParseWebhookURL(webhookURL)
+		if err != nil {
+			RespondError(s, i, "That is not a Discord webhook URL. Copy it from the channel's Integrations settings.")
+			return
+		}
+		webhook, err := s.WebhookWithToken(id, token)
+		if err != nil {
+			channelLogger(channelID).Warnf("Failed to look up webhook %s for channel %s: %v", id, channelID, err)
+			RespondError(s, i, "The webhook could not be found. Check that it still exists.")
+			return
+		}
+		if webhook.ChannelID != channelID {
+			RespondError(s, i, "The webhook posts to a different channel. Use a webhook of this channel.")
+			return
+		}
+	}
+
+	if err := database.UpdateChannelWebhook(b, channelID, webhookURL); err != nil {
+		channelLogger(channelID).Errorf("Failed to update webhook for channel %s: %v", channelID, err)
+		RespondError(s, i, "Failed to update the webhook. Please try again later.")
+		return
+	}
+
+	if webhookURL == "" {
+		channelLogger(channelID).Infof("Channel %s webhook cleared", channelID)
+		Respond(s, i, "✅ News in this channel will be posted by the bot.")
+		return
+	}
+	channelLogger(channelID).Infof("Channel %s webhook set", channelID)
+	Respond(s, i, "✅ News in this channel will be posted through the webhook. If it fails, the bot posts instead.")
+}
+
+// formatQuietHours returns a channel's quiet hours for display.
+func formatQuietHours(cfg types.ChannelConfig) string {
+	if !cfg.QuietHours {
+		return "off"
+	}
+	return fmt.Sprintf("%02d:00–%02d:00 UTC", cfg.QuietHoursStart, cfg.QuietHoursEnd)
+}
+
+// handleSetQuietHours handles the "set_quiet_hours" command interaction
+func handleSetQuietHours(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Validate inputs
+	if i == nil || i.Interaction == nil {
+		logger().Warning("handleSetQuietHours called with nil interaction")
+		return
+	}
+
+	// Check if user has administrator permission
+	if !hasAdminPermission(s, i) {
+		RespondError(s, i, "You need Administrator permission to use this command.")
+		return
+	}
+
+	start, end := -1, -1
+	for _, option := range i.ApplicationCommandData().Options {
+		switch option.Name {
+		case "start":
+			start = int(option.IntValue())
+		case "end":
+			end = int(option.IntValue())
+		}
+	}
+
+	enabled := start >= 0 || end >= 0
+	if enabled && (start < 0 || end < 0) {
+		RespondError(s, i, "Give both the start and the end hour, or neither to turn quiet hours off.")
+		return
+	}
+	if start > 23 || end > 23 {
+		RespondError(s, i, "The hours must be between 0 and 23 (UTC).")
+		return
+	}
+	if enabled && start == end {
+		RespondError(s, i, "The start and end hour must differ.")
+		return
+	}
+
+	channelID := i.ChannelID
+
+	platforms, err := database.GetChannelPlatforms(b, channelID)
+	if err != nil {
+		channelLogger(channelID).Errorf("Failed to get channel platforms for %s: %v", channelID, err)
+		RespondError(s, i, "Failed to check channel status. Please try again later.")
+		return
+	}
+	if len(platforms) == 0 {
+		RespondError(s, i, "This channel is not registered. Use `/stobot_register` first.")
+		return
+	}
+
+	if err := database.UpdateChannelQuietHours(b, channelID, enabled, start, end); err != nil {
+		channelLogger(channelID).Errorf("Failed to update quiet hours for channel %s: %v", channelID, err)
+		RespondError(s, i, "Failed to update the quiet hours. Please try again later.")
+		return
+	}
+
+	cfg := types.ChannelConfig{QuietHours: enabled, QuietHoursStart: start, QuietHoursEnd: end}
+	channelLogger(channelID).Infof("Channel %s quiet hours set to %s", channelID, formatQuietHours(cfg))
+	if !enabled {
+		Respond(s, i, "✅ Quiet hours turned off. News will be posted as soon as it is published.")
+		return
+	}
+	Respond(s, i, fmt.Sprintf("✅ Quiet hours set to %s. News published in that time is posted when they end.", formatQuietHours(cfg)))
+}
+
+// localeNames are the display names of the locales news can be posted in.
+var localeNames = map[string]string{
+	"en": "English",
+	"de": "German",
+	"fr": "French",
+}
+
+// handleSetLocale handles the "set_locale" command interaction
+func handleSetLocale(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Validate inputs
+	if i == nil || i.Interaction == nil {
+		logger().Warning("handleSetLocale called with nil interaction")
+		return
+	}
+
+	// Check if user has administrator permission
+	if !hasAdminPermission(s, i) {
+		RespondError(s, i, "You need Administrator permission to use this command.")
+		return
+	}
+
+	var value string
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "locale" {
+			value = option.StringValue()
+		}
+	}
+
+	locale, err := types.NormalizeLocale(value)
+	if err != nil {
+		RespondError(s, i, fmt.Sprintf("Unknown locale. Valid locales are %s.", strings.Join(types.Locales, ", ")))
+		return
+	}
+
+	channelID := i.ChannelID
+
+	platforms, err := database.GetChannelPlatforms(b, channelID)
+	if err != nil {
+		channelLogger(channelID).Errorf("Failed to get channel platforms for %s: %v", channelID, err)
+		RespondError(s, i, "Failed to check channel status. Please try again later.")
+		return
+	}
+	if len(platforms) == 0 {
+		RespondError(s, i, "This channel is not registered. Use `/stobot_register` first.")
+		return
+	}
+
+	if err := database.UpdateChannelLocale(b, channelID, locale); err != nil {
+		channelLogger(channelID).Errorf("Failed to update locale for channel %s: %v", channelID, err)
+		RespondError(s, i, "Failed to update the locale. Please try again later.")
+		return
+	}
+
+	channelLogger(channelID).Infof("Channel %s locale set to %s", channelID, locale)
+	if locale == types.DefaultLocale {
+		Respond(s, i, "✅ News in this channel will be posted in English.")
+		return
+	}
+	Respond(s, i, fmt.Sprintf("✅ News in this channel will be posted in %s. Articles not available in %s are posted in English.", localeNames[locale], localeNames[locale]))
+}
+
+// handleExcludeTags handles the "exclude_tags" command interaction
+func handleExcludeTags(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Validate inputs
+	if i == nil || i.Interaction == nil {
+		logger().Warning("handleExcludeTags called with nil interaction")
+		return
+	}
+
+	// Check if user has administrator permission
+	if !hasAdminPermission(s, i) {
+		RespondError(s, i, "You need Administrator permission to use this command.")
+		return
+	}
+
+	var excludedTags []string
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "tags" {
+			excludedTags = parseTagList(option.StringValue())
+		}
+	}
+
+	channelID := i.ChannelID
+
+	platforms, err := database.GetChannelPlatforms(b, channelID)
+	if err != nil {
+		channelLogger(channelID).Errorf("Failed to get channel platforms for %s: %v", channelID, err)
+		RespondError(s, i, "Failed to check channel status. Please try again later.")
+		return
+	}
+	if len(platforms) == 0 {
+		RespondError(s, i, "This channel is not registered. Use `/stobot_register` first.")
+		return
+	}
+
+	if err := database.SetChannelExcludedTags(b, channelID, excludedTags); err != nil {
+		channelLogger(channelID).Errorf("Failed to update excluded tags for channel %s: %v", channelID, err)
+		RespondError(s, i, "Failed to update excluded tags. Please try again later.")
+		return
+	}
+
+	channelLogger(channelID).Infof("Channel %s excluded tags set to %v", channelID, excludedTags)
+	if len(excludedTags) == 0 {
+		Respond(s, i, "✅ No news tags are excluded from this channel.")
+		return
+	}
+	Respond(s, i, fmt.Sprintf("✅ News tagged %s will not be posted to this channel.", strings.Join(excludedTags, ", ")))
+}
+
+// handleSpoilerTags handles the "spoiler_tags" command interaction
+func handleSpoilerTags(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Validate inputs
+	if i == nil || i.Interaction == nil {
+		logger().Warning("handleSpoilerTags called with nil interaction")
+		return
+	}
+
+	// Check if user has administrator permission
+	if !hasAdminPermission(s, i) {
+		RespondError(s, i, "You need Administrator permission to use this command.")
+		return
+	}
+
+	var spoilerTags []string
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "tags" {
+			spoilerTags = parseTagList(option.StringValue())
+		}
+	}
+
+	channelID := i.ChannelID
+
+	platforms, err := database.GetChannelPlatforms(b, channelID)
+	if err != nil {
+		channelLogger(channelID).Errorf("Failed to get channel platforms for %s: %v", channelID, err)
+		RespondError(s, i, "Failed to check channel status. Please try again later.")
+		return
+	}
+	if len(platforms) == 0 {
+		RespondError(s, i, "This channel is not registered. Use `/stobot_register` first.")
+		return
+	}
+
+	if err := database.UpdateChannelSpoilerTags(b, channelID, spoilerTags); err != nil {
+		channelLogger(channelID).Errorf("Failed to update spoiler tags for channel %s: %v", channelID, err)
+		RespondError(s, i, "Failed to update spoiler tags. Please try again later.")
+		return
+	}
+
+	if len(spoilerTags) == 0 {
+		Respond(s, i, "✅ Spoiler handling disabled for this channel.")
+		return
+	}
+
+	channelLogger(channelID).Infof("Channel %s spoiler tags set to %v", channelID, spoilerTags)
+	Respond(s, i, fmt.Sprintf("✅ Articles tagged %s will be posted with their summary hidden.", strings.Join(spoilerTags, ", ")))
+}
+
+// handleAutoPublish handles the "auto_publish" command interaction
+func handleAutoPublish(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Validate inputs
+	if i == nil || i.Interaction == nil {
+		logger().Warning("handleAutoPublish called with nil interaction")
+		return
+	}
+
+	// Check if user has administrator permission
+	if !hasAdminPermission(s, i) {
+		RespondError(s, i, "You need Administrator permission to use this command.")
+		return
+	}
+
+	enabled := true
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "enabled" {
+			enabled = option.BoolValue()
+		}
+	}
+
+	channelID := i.ChannelID
+
+	platforms, err := database.GetChannelPlatforms(b, channelID)
+	if err != nil {
+		channelLogger(channelID).Errorf("Failed to get channel platforms for %s: %v", channelID, err)
+		RespondError(s, i, "Failed to check channel status. Please try again later.")
+		return
+	}
+	if len(platforms) == 0 {
+		RespondError(s, i, "This channel is not registered. Use `/stobot_register` first.")
+		return
+	}
+
+	if err := database.UpdateChannelAutoPublish(b, channelID, enabled); err != nil {
+		channelLogger(channelID).Errorf("Failed to update auto-publish for channel %s: %v", channelID, err)
+		RespondError(s, i, "Failed to update auto-publish. Please try again later.")
+		return
+	}
+
+	channelLogger(channelID).Infof("Channel %s auto-publish set to %v", channelID, enabled)
+	if !enabled {
+		Respond(s, i, "✅ Auto-publish disabled for this channel.")
+		return
+	}
+	Respond(s, i, "✅ Auto-publish enabled. News posted here will be published to following servers when this is an announcement channel.\n\nThe bot needs the **Manage Messages** permission in this channel.")
+}
+
+// handleStrictPatchNotes handles the "strict_patch_notes" command interaction
+func handleStrictPatchNotes(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Validate inputs
+	if i == nil || i.Interaction == nil {
+		logger().Warning("handleStrictPatchNotes called with nil interaction")
+		return
+	}
+
+	// Check if user has administrator permission
+	if !hasAdminPermission(s, i) {
+		RespondError(s, i, "You need Administrator permission to use this command.")
+		return
+	}
+
+	enabled := true
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "enabled" {
+			enabled = option.BoolValue()
+		}
+	}
+
+	channelID := i.ChannelID
+
+	platforms, err := database.GetChannelPlatforms(b, channelID)
+	if err != nil {
+		channelLogger(channelID).Errorf("Failed to get channel platforms for %s: %v", channelID, err)
+		RespondError(s, i, "Failed to check channel status. Please try again later.")
+		return
+	}
+	if len(platforms) == 0 {
+		RespondError(s, i, "This channel is not registered. Use `/stobot_register` first.")
+		return
+	}
+
+	if err := database.UpdateChannelStrictPatchNotes(b, channelID, enabled); err != nil {
+		channelLogger(channelID).Errorf("Failed to update strict patch notes for channel %s: %v", channelID, err)
+		RespondError(s, i, "Failed to update strict patch notes. Please try again later.")
+		return
+	}
+
+	channelLogger(channelID).Infof("Channel %s strict patch notes set to %v", channelID, enabled)
+	if !enabled {
+		Respond(s, i, "✅ Strict patch notes disabled. All patch notes for this channel's platforms will be posted.")
+		return
+	}
+	Respond(s, i, fmt.Sprintf("✅ Strict patch notes enabled. Patch notes whose title names other platforms only (e.g. \"PC Patch Notes\") are skipped; this channel gets %s.",
+		strings.Join(platforms, ", ")))
+}
+
+// handleSetThreads handles the "set_threads" command interaction
+func handleSetThreads(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Validate inputs
+	if i == nil || i.Interaction == nil {
+		logger().Warning("handleSetThreads called with nil interaction")
+		return
+	}
+
+	// Check if user has administrator permission
+	if !hasAdminPermission(s, i) {
+		RespondError(s, i, "You need Administrator permission to use this command.")
+		return
+	}
+
+	enabled := true
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "enabled" {
+			enabled = option.BoolValue()
+		}
+	}
+
+	channelID := i.ChannelID
+
+	platforms, err := database.GetChannelPlatforms(b, channelID)
+	if err != nil {
+		channelLogger(channelID).Errorf("Failed to get channel platforms for %s: %v", channelID, err)
+		RespondError(s, i, "Failed to check channel status. Please try again later.")
+		return
+	}
+	if len(platforms) == 0 {
+		RespondError(s, i, "This channel is not registered. Use `/stobot_register` first.")
+		return
+	}
+
+	if err := database.UpdateChannelCreateThreads(b, channelID, enabled); err != nil {
+		channelLogger(channelID).Errorf("Failed to update threads for channel %s: %v", channelID, err)
+		RespondError(s, i, "Failed to update threads. Please try again later.")
+		return
+	}
+
+	channelLogger(channelID).Infof("Channel %s create threads set to %v", channelID, enabled)
+	if !enabled {
+		Respond(s, i, "✅ Discussion threads disabled. Existing threads are kept.")
+		return
+	}
+	Respond(s, i, "✅ Discussion threads enabled. Each news post here gets a public thread named after the article.\n\nThe bot needs the **Create Public Threads** permission in this channel.")
+}
+
+// handleSetUpdateNotices handles the "set_update_notices" command interaction
+func handleSetUpdateNotices(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Validate inputs
+	if i == nil || i.Interaction == nil {
+		logger().Warning("handleSetUpdateNotices called with nil interaction")
+		return
+	}
+
+	// Check if user has administrator permission
+	if !hasAdminPermission(s, i) {
+		RespondError(s, i, "You need Administrator permission to use this command.")
+		return
+	}
+
+	mode := types.UpdateNoticesPost
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "mode" {
+			mode = option.StringValue()
+		}
+	}
+
+	channelID := i.ChannelID
+
+	platforms, err := database.GetChannelPlatforms(b, channelID)
+	if err != nil {
+		channelLogger(channelID).Errorf("Failed to get channel platforms for %s: %v", channelID, err)
+		RespondError(s, i, "Failed to check channel status. Please try again later.")
+		return
+	}
+	if len(platforms) == 0 {
+		RespondError(s, i, "This channel is not registered. Use `/stobot_register` first.")
+		return
+	}
+
+	if err := database.UpdateChannelUpdateNotices(b, channelID, mode); err != nil {
+		channelLogger(channelID).Errorf("Failed to update update notices for channel %s: %v", channelID, err)
+		RespondError(s, i, "Failed to update the update notices. Please try again later.")
+		return
+	}
+
+	channelLogger(channelID).Infof("Channel %s update notices set to %s", channelID, mode)
+	switch mode {
+	case types.UpdateNoticesEdit:
+		Respond(s, i, "✅ When an article posted here is updated, its post is edited to show the new version. If the post cannot be edited, a notice is posted instead.")
+	case types.UpdateNoticesOff:
+		Respond(s, i, "✅ Articles updated after they were posted here are no longer announced.")
+	default:
+		Respond(s, i, "✅ When an article posted here is updated, a short notice linking to its post is posted.")
+	}
+}
+
+// handleSetGalleries handles the "set_galleries" command interaction
+func handleSetGalleries(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Validate inputs
+	if i == nil || i.Interaction == nil {
+		logger().Warning("handleSetGalleries called with nil interaction")
+		return
+	}
+
+	// Check if user has administrator permission
+	if !hasAdminPermission(s, i) {
+		RespondError(s, i, "You need Administrator permission to use this command.")
+		return
+	}
+
+	enabled := true
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "enabled" {
+			enabled = option.BoolValue()
+		}
+	}
+
+	channelID := i.ChannelID
+
+	platforms, err := database.GetChannelPlatforms(b, channelID)
+	if err != nil {
+		channelLogger(channelID).Errorf("Failed to get channel platforms for %s: %v", channelID, err)
+		RespondError(s, i, "Failed to check channel status. Please try again later.")
+		return
+	}
+	if len(platforms) == 0 {
+		RespondError(s, i, "This channel is not registered. Use `/stobot_register` first.")
+		return
+	}
+
+	if err := database.UpdateChannelShowGalleries(b, channelID, enabled); err != nil {
+		channelLogger(channelID).Errorf("Failed to update galleries for channel %s: %v", channelID, err)
+		RespondError(s, i, "Failed to update image galleries. Please try again later.")
+		return
+	}
+
+	channelLogger(channelID).Infof("Channel %s show galleries set to %v", channelID, enabled)
+	if !enabled {
+		Respond(s, i, "✅ Image galleries disabled. News posts here show the article's main image only.")
+		return
+	}
+	Respond(s, i, "✅ Image galleries enabled. News posts of articles with several screenshots here show them below the article.")
+}
+
+// handleSetEvents handles the "set_events" command interaction
+func handleSetEvents(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Validate inputs
+	if i == nil || i.Interaction == nil {
+		logger().Warning("handleSetEvents called with nil interaction")
+		return
+	}
+
+	// Check if user has administrator permission
+	if !hasAdminPermission(s, i) {
+		RespondError(s, i, "You need Administrator permission to use this command.")
+		return
+	}
+
+	enabled := true
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "enabled" {
+			enabled = option.BoolValue()
+		}
+	}
+
+	channelID := i.ChannelID
+
+	platforms, err := database.GetChannelPlatforms(b, channelID)
+	if err != nil {
+		channelLogger(channelID).Errorf("Failed to get channel platforms for %s: %v", channelID, err)
+		RespondError(s, i, "Failed to check channel status. Please try again later.")
+		return
+	}
+	if len(platforms) == 0 {
+		RespondError(s, i, "This channel is not registered. Use `/stobot_register` first.")
+		return
+	}
+
+	// Events are created in the channel's server, which must be known
+	if i.GuildID == "" {
+		RespondError(s, i, "Scheduled events can only be created for channels in a server.")
+		return
+	}
+	if err := database.UpdateChannelGuild(b, channelID, i.GuildID); err != nil {
+		channelLogger(channelID).Errorf("Failed to record guild of channel %s: %v", channelID, err)
+	}
+
+	if err := database.UpdateChannelCreateEvents(b, channelID, enabled); err != nil {
+		channelLogger(channelID).Errorf("Failed to update events for channel %s: %v", channelID, err)
+		RespondError(s, i, "Failed to update scheduled events. Please try again later.")
+		return
+	}
+
+	channelLogger(channelID).Infof("Channel %s create events set to %v", channelID, enabled)
+	if !enabled {
+		Respond(s, i, "✅ Scheduled events disabled. Event articles posted here no longer create server events.")
+		return
+	}
+	Respond(s, i, "✅ Scheduled events enabled. Event articles posted here with clear start and end dates create a server event, once per article. The bot needs the Manage Events permission.")
+}
+
+// handleSetStyle handles the "set_style" command interaction
+func handleSetStyle(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Validate inputs
+	if i == nil || i.Interaction == nil {
+		logger().Warning("handleSetStyle called with nil interaction")
+		return
+	}
+
+	// Check if user has administrator permission
+	if !hasAdminPermission(s, i) {
+		RespondError(s, i, "You need Administrator permission to use this command.")
+		return
+	}
+
+	style := types.MessageStyleEmbed
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "style" {
+			style = option.StringValue()
+		}
+	}
+
+	channelID := i.ChannelID
+
+	platforms, err := database.GetChannelPlatforms(b, channelID)
+	if err != nil {
+		channelLogger(channelID).Errorf("Failed to get channel platforms for %s: %v", channelID, err)
+		RespondError(s, i, "Failed to check channel status. Please try again later.")
+		return
+	}
+	if len(platforms) == 0 {
+		RespondError(s, i, "This channel is not registered. Use `/stobot_register` first.")
+		return
+	}
+
+	if err := database.UpdateChannelMessageStyle(b, channelID, style); err != nil {
+		channelLogger(channelID).Errorf("Failed to update message style for channel %s: %v", channelID, err)
+		RespondError(s, i, "Failed to update the message style. Please try again later.")
+		return
+	}
+
+	channelLogger(channelID).Infof("Channel %s message style set to %s", channelID, style)
+	if style == types.MessageStyleCompact {
+		Respond(s, i, "✅ News is now posted here as a single line with the article's title and link, without a preview.")
+		return
+	}
+	Respond(s, i, "✅ News is now posted here as an embed with the article's summary and image.")
+}
+
+// formatUpdateNotices describes a channel's update notice mode for display.
+func formatUpdateNotices(mode string) string {
+	switch mode {
+	case types.UpdateNoticesEdit:
+		return "Edit the post"
+	case types.UpdateNoticesOff:
+		return "Off"
+	default:
+		return "Post a notice"
+	}
+}
+
+// maxPostsOptionMin is the minimum of the max option of /stobot_set_max_posts; MinValue takes a pointer.
+var maxPostsOptionMin = 0.0
+
+// handleSetMaxPosts handles the "set_max_posts" command interaction
+func handleSetMaxPosts(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Validate inputs
+	if i == nil || i.Interaction == nil {
+		logger().Warning("handleSetMaxPosts called with nil interaction")
+		return
+	}
+
+	// Check if user has administrator permission
+	if !hasAdminPermission(s, i) {
+		RespondError(s, i, "You need Administrator permission to use this command.")
+		return
+	}
+
+	maxPosts := 0
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "max" {
+			maxPosts = int(option.IntValue())
+		}
+	}
+	if maxPosts < 0 {
+		RespondError(s, i, "The number of posts must not be negative.")
+		return
+	}
+
+	channelID := i.ChannelID
+
+	platforms, err := database.GetChannelPlatforms(b, channelID)
+	if err != nil {
+		channelLogger(channelID).Errorf("Failed to get channel platforms for %s: %v", channelID, err)
+		RespondError(s, i, "Failed to check channel status. Please try again later.")
+		return
+	}
+	if len(platforms) == 0 {
+		RespondError(s, i, "This channel is not registered. Use `/stobot_register` first.")
+		return
+	}
+
+	if err := database.UpdateChannelMaxPostsPerCycle(b, channelID, maxPosts); err != nil {
+		channelLogger(channelID).Errorf("Failed to update max posts per cycle for channel %s: %v", channelID, err)
+		RespondError(s, i, "Failed to update the posts per poll. Please try again later.")
+		return
+	}
+
+	channelLogger(channelID).Infof("Channel %s max posts per cycle set to %d", channelID, maxPosts)
+	if maxPosts == 0 {
+		defaultMax := 0
+		if b.Config != nil {
+			defaultMax = b.Config.MaxPostsPerCycle
+		}
+		Respond(s, i, "✅ This channel now uses the bot's default posts per poll: "+formatMaxPosts(defaultMax)+".")
+		return
+	}
+	Respond(s, i, fmt.Sprintf("✅ At most %d news posts per poll in this channel. Any backlog is posted by the following polls, oldest first.", maxPosts))
+}
+
+// formatMaxPosts describes a posts per cycle limit for display.
+func formatMaxPosts(maxPosts int) string {
+	if maxPosts <= 0 {
+		return "no limit"
+	}
+	return fmt.Sprintf("%d", maxPosts)
+}
+
+// handlePause handles the "pause" command interaction
+func handlePause(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Validate inputs
+	if i == nil || i.Interaction == nil {
+		logger().Warning("handlePause called with nil interaction")
+		return
+	}
+
+	// Check if user has administrator permission
+	if !hasAdminPermission(s, i) {
+		RespondError(s, i, "You need Administrator permission to use this command.")
+		return
+	}
+
+	hold := false
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "hold" {
+			hold = option.BoolValue()
+		}
+	}
+
+	channelID := i.ChannelID
+
+	platforms, err := database.GetChannelPlatforms(b, channelID)
+	if err != nil {
+		channelLogger(channelID).Errorf("Failed to get channel platforms for %s: %v", channelID, err)
+		RespondError(s, i, "Failed to check channel status. Please try again later.")
+		return
+	}
+	if len(platforms) == 0 {
+		RespondError(s, i, "This channel is not registered. Use `/stobot_register` first.")
+		return
+	}
+
+	if err := database.UpdateChannelPaused(b, channelID, true, hold); err != nil {
+		channelLogger(channelID).Errorf("Failed to pause channel %s: %v", channelID, err)
+		RespondError(s, i, "Failed to pause news posting. Please try again later.")
+		return
+	}
+
+	channelLogger(channelID).Infof("Channel %s paused (hold %v)", channelID, hold)
+	if hold {
+		Respond(s, i, "⏸️ News posting paused. News released meanwhile is held and posted when you use `/stobot_resume`. This channel's settings are kept.")
+		return
+	}
+	Respond(s, i, "⏸️ News posting paused. News released meanwhile is skipped; use `/stobot_resume` to post again. This channel's settings are kept.")
+}
+
+// handleResume handles the "resume" command interaction
+func handleResume(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Validate inputs
+	if i == nil || i.Interaction == nil {
+		logger().Warning("handleResume called with nil interaction")
+		return
+	}
+
+	// Check if user has administrator permission
+	if !hasAdminPermission(s, i) {
+		RespondError(s, i, "You need Administrator permission to use this command.")
+		return
+	}
+
+	channelID := i.ChannelID
+
+	cfg, err := database.GetChannelConfig(b, channelID)
+	if err != nil {
+		channelLogger(channelID).Errorf("Failed to get channel config for %s: %v", channelID, err)
+		RespondError(s, i, "Failed to check channel status. Please try again later.")
+		return
+	}
+	if cfg == nil {
+		RespondError(s, i, "This channel is not registered. Use `/stobot_register` first.")
+		return
+	}
+	if !cfg.Paused {
+		RespondError(s, i, "News posting is not paused in this channel.")
+		return
+	}
+
+	if err := database.UpdateChannelPaused(b, channelID, false, false); err != nil {
+		channelLogger(channelID).Errorf("Failed to resume channel %s: %v", channelID, err)
+		RespondError(s, i, "Failed to resume news posting. Please try again later.")
+		return
+	}
+
+	channelLogger(channelID).Infof("Channel %s resumed", channelID)
+	heldCount, err := database.CountHeldNews(b, channelID)
+	if err != nil {
+		channelLogger(channelID).Errorf("Failed to count held news for channel %s: %v", channelID, err)
+	}
+	if heldCount > 0 {
+		Respond(s, i, fmt.Sprintf("▶️ News posting resumed. Articles held while paused (%d) will be posted with the next news check.", heldCount))
+		return
+	}
+	Respond(s, i, "▶️ News posting resumed.")
+}
+
+// formatPaused describes the pause mode of a paused channel for display.
+func formatPaused(cfg types.ChannelConfig) string {
+	if cfg.PauseHold {
+		return "Paused, news is held until `/stobot_resume`"
+	}
+	return "Paused, news is skipped until `/stobot_resume`"
+}
+
+// formatEnabled returns an on/off setting for display.
+func formatEnabled(enabled bool) string {
+	if enabled {
+		return "enabled"
+	}
+	return "disabled"
+}
+
+// statusRecentPosts is the number of recent posts listed by /stobot_status.
+const statusRecentPosts = 5
+
+// writeRecentPosts appends the news most recently posted to a channel to a status message.
+func writeRecentPosts(b *types.Bot, statusMsg *strings.Builder, channelID string) {
+	posts, err := database.GetRecentPostsForChannel(b, channelID, statusRecentPosts)
+	if err != nil {
+		channelLogger(channelID).Errorf("Failed to get recent posts for %s: %v", channelID, err)
+		return
+	}
+
+	if len(posts) == 0 {
+		statusMsg.WriteString("🕒 **Recent Posts**: No news posted yet\n")
+		return
+	}
+
+	statusMsg.WriteString("🕒 **Recent Posts**:\n")
+	for _, post := range posts {
+		title := post.Title
+		if title == "" {
+			title = fmt.Sprintf("News #%d", post.NewsID)
+		}
+		statusMsg.WriteString(fmt.Sprintf("• %s - %s\n", post.PostedAt.UTC().Format("2006-01-02 15:04 UTC"), title))
+	}
+}
+
+// handleStatus handles the "status" command interaction
+func handleStatus(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Validate inputs
+	if b == nil {
+		logger().Warning("handleStatus called with nil bot")
+		if s != nil && i != nil {
+			RespondError(s, i, "Bot configuration error. Please try again later.")
+		}
+		return
+	}
+
+	if i == nil || i.Interaction == nil {
+		logger().Warning("handleStatus called with nil interaction")
+		return
+	}
+
+	channelID := i.ChannelID
+
+	// Check if this channel is registered
+	platforms, err := database.GetChannelPlatforms(b, channelID)
+	if err != nil {
+		channelLogger(channelID).Errorf("Failed to get channel platforms for %s: %v", channelID, err)
+		RespondError(s, i, "Failed to check channel status. Please try again later.")
+		return
+	}
+
+	// Get cached news count
+	allNews, err := database.GetAllCachedNews(b)
+	if err != nil {
+		logger().Errorf("Failed to get cached news count: %v", err)
+		RespondError(s, i, "Failed to get bot status. Please try again later.")
+		return
+	}
+
+	// Build status message
+	var statusMsg strings.Builder
+	statusMsg.WriteString("🤖 **STOBot Status**\n\n")
+
+	if len(platforms) > 0 {
+		statusMsg.WriteString("✅ **This Channel**: Registered\n")
+		statusMsg.WriteString(fmt.Sprintf("📡 **Platforms**: %s\n", strings.Join(platforms, ", ")))
+		if tags, err := database.GetChannelTags(b, channelID); err == nil {
+			statusMsg.WriteString(fmt.Sprintf("🏷️ **Tags**: %s\n", formatChannelTags(tags)))
+		}
+		if pingRole, err := database.GetChannelPingRole(b, channelID); err == nil && pingRole != "" {
+			statusMsg.WriteString(fmt.Sprintf("🔔 **Ping Role**: %s\n", formatPingRole(pingRole)))
+		}
+		if excludedTags, err := database.GetChannelExcludedTags(b, channelID); err == nil && len(excludedTags) > 0 {
+			statusMsg.WriteString(fmt.Sprintf("🚫 **Excluded Tags**: %s\n", strings.Join(excludedTags, ", ")))
+		}
+		if spoilerTags, err := database.GetChannelSpoilerTags(b, channelID); err == nil && len(spoilerTags) > 0 {
+			statusMsg.WriteString(fmt.Sprintf("🙈 **Spoiler Tags**: %s\n", strings.Join(spoilerTags, ", ")))
+		}
+		if cfg, err := database.GetChannelConfig(b, channelID); err == nil && cfg != nil {
+			statusMsg.WriteString(fmt.Sprintf("🌐 **Environment**: %s\n", cfg.Environment))
+			if cfg.Disabled {
+				statusMsg.WriteString("⛔ **Posting**: Disabled, news is not posted here. If the bot lacked access, " +
+					"fix its permissions and ask the bot operator to re-enable the channel\n")
+			} else if cfg.Paused {
+				statusMsg.WriteString(fmt.Sprintf("⏸️ **Posting**: %s\n", formatPaused(*cfg)))
+			} else if cfg.PostFailures > 0 {
+				statusMsg.WriteString(fmt.Sprintf("⚠️ **Failed Posts**: %d in a row, check the bot's permissions here\n", cfg.PostFailures))
+			}
+			if cfg.Digest {
+				statusMsg.WriteString(fmt.Sprintf("📅 **Weekly Digest**: %s\n", formatDigestSchedule(*cfg)))
+			}
+			if cfg.StrictPatchNotes {
+				statusMsg.WriteString("🩹 **Strict Patch Notes**: Enabled\n")
+			}
+			if cfg.CreateThreads {
+				statusMsg.WriteString("🧵 **Discussion Threads**: Enabled\n")
+			}
+			if cfg.MaxPostsPerCycle > 0 {
+				statusMsg.WriteString(fmt.Sprintf("🚦 **Posts Per Poll**: At most %d\n", cfg.MaxPostsPerCycle))
+			}
+			if cfg.UpdateNotices != types.UpdateNoticesPost {
+				statusMsg.WriteString(fmt.Sprintf("📝 **Article Updates**: %s\n", formatUpdateNotices(cfg.UpdateNotices)))
+			}
+			if cfg.ShowGalleries {
+				statusMsg.WriteString("🖼️ **Image Galleries**: Enabled\n")
+			}
+			if cfg.MessageStyle == types.MessageStyleCompact {
+				statusMsg.WriteString("💬 **Message Style**: Compact\n")
+			}
+			if cfg.CreateEvents {
+				statusMsg.WriteString("📆 **Scheduled Events**: Enabled\n")
+			}
+			if cfg.QuietHours {
+				statusMsg.WriteString(fmt.Sprintf("🌙 **Quiet Hours**: %s\n", formatQuietHours(*cfg)))
+			}
+			if cfg.WebhookURL != "" {
+				statusMsg.WriteString("🪝 **Webhook**: Configured\n")
+			}
+			if cfg.Locale != "" && cfg.Locale != types.DefaultLocale {
+				statusMsg.WriteString(fmt.Sprintf("🗣️ **Language**: %s\n", localeNames[cfg.Locale]))
+			}
+		}
+		writeRecentPosts(b, &statusMsg, channelID)
 	} else {
 		statusMsg.WriteString("❌ **This Channel**: Not registered\n")
 	}
 
+	statusMsg.WriteString(fmt.Sprintf("🖥️ **Bot Environment**: %s\n", botEnvironment(b)))
 	statusMsg.WriteString(fmt.Sprintf("📰 **Cached News Items**: %d\n", len(allNews)))
 	statusMsg.WriteString(fmt.Sprintf("⏱️ **Poll Period**: %d seconds\n", b.Config.PollPeriod))
 	statusMsg.WriteString(fmt.Sprintf("🔔 **Fresh News Threshold**: %d seconds\n", b.Config.FreshSeconds))
@@ -4,8 +4,13 @@
 package discord
 
 import (
+	"net/http"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
 	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
 	"github.com/FracKenA/sto_news_discord_bot/internal/types"
 
@@ -479,3 +484,987 @@ func createEmptyChannelInteraction() *discordgo.InteractionCreate {
 		},
 	}
 }
+
+// tagsInteraction builds a command interaction with a tags option in channel-a, sent by the guild owner.
+func tagsInteraction(command, tags string) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			ID:        "interaction-1",
+			AppID:     "app-1",
+			Token:     "interaction-token",
+			Type:      discordgo.InteractionApplicationCommand,
+			GuildID:   "guild-1",
+			ChannelID: "channel-a",
+			Member:    &discordgo.Member{User: &discordgo.User{ID: "owner-1"}},
+			Data: discordgo.ApplicationCommandInteractionData{
+				Name: command,
+				Options: []*discordgo.ApplicationCommandInteractionDataOption{
+					{Name: "tags", Type: discordgo.ApplicationCommandOptionString, Value: tags},
+				},
+			},
+		},
+	}
+}
+
+func TestChannelTagCommands(t *testing.T) {
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	fake := testhelpers.NewFakeDiscord(t)
+	bot.Session = fake.Session()
+	fake.Handle("GET", "/guilds/guild-1", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "guild-1", "owner_id": "owner-1"})
+	})
+
+	assertTags := func(expected []string) {
+		t.Helper()
+		tags, err := database.GetChannelTags(bot, "channel-a")
+		if err != nil {
+			t.Fatalf("Failed to get channel tags: %v", err)
+		}
+		if !reflect.DeepEqual(tags, expected) {
+			t.Errorf("Expected tags %v, got %v", expected, tags)
+		}
+	}
+
+	handleRegister(bot, bot.Session, tagsInteraction("stobot_register", "Patch-Notes, "))
+	assertTags([]string{"patch-notes"})
+
+	handleSetTags(bot, bot.Session, tagsInteraction("stobot_set_tags", "events,dev-blogs"))
+	assertTags([]string{"events", "dev-blogs"})
+
+	handleSetTags(bot, bot.Session, tagsInteraction("stobot_set_tags", ""))
+	assertTags([]string{})
+}
+
+func TestRegisterPlatforms(t *testing.T) {
+	tests := []struct {
+		name       string
+		platforms  string
+		expected   []string
+		registered bool
+		reply      string
+	}{
+		{"aliases", "PC, PlayStation, ps5", []string{"pc", "ps"}, true, "Platforms: pc,ps"},
+		{"all", "all", []string{"pc", "xbox", "ps"}, true, "Platforms: pc,xbox,ps"},
+		{"unknown platform", "pc,xobx", nil, false, `Invalid platforms: unknown platform \"xobx\": valid platforms are pc, xbox, ps, or all`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := testhelpers.CreateTestBot(t)
+			defer bot.DB.Close()
+			fake := testhelpers.NewFakeDiscord(t)
+			bot.Session = fake.Session()
+			fake.Handle("GET", "/guilds/guild-1", func(w http.ResponseWriter, r *http.Request) {
+				testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "guild-1", "owner_id": "owner-1"})
+			})
+
+			interaction := tagsInteraction("stobot_register", "")
+			interaction.ApplicationCommandData().Options[0] = &discordgo.ApplicationCommandInteractionDataOption{
+				Name: "platforms", Type: discordgo.ApplicationCommandOptionString, Value: tt.platforms,
+			}
+			handleRegister(bot, bot.Session, interaction)
+			channelSetups.Wait()
+
+			followups := fake.RequestsTo("POST", "/webhooks/app-1/interaction-token")
+			if len(followups) != 1 || !strings.Contains(string(followups[0].Body), tt.reply) {
+				t.Fatalf("Expected a reply containing %q, got %v", tt.reply, followups)
+			}
+
+			cfg, err := database.GetChannelConfig(bot, "channel-a")
+			if err != nil {
+				t.Fatalf("Failed to get channel config: %v", err)
+			}
+			if (cfg != nil) != tt.registered {
+				t.Fatalf("Expected registered %v, got %+v", tt.registered, cfg)
+			}
+			if cfg != nil && !reflect.DeepEqual(cfg.Platforms, tt.expected) {
+				t.Errorf("Expected platforms %v, got %v", tt.expected, cfg.Platforms)
+			}
+		})
+	}
+}
+
+func TestRegisterUsesBotEnvironment(t *testing.T) {
+	tests := []struct {
+		name        string
+		environment string
+		expected    string
+	}{
+		{"DEV bot", "DEV", "DEV"},
+		{"PROD bot", "PROD", "PROD"},
+		{"bot without environment", "", "PROD"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := testhelpers.CreateTestBot(t)
+			defer bot.DB.Close()
+			bot.Config.Environment = tt.environment
+			fake := testhelpers.NewFakeDiscord(t)
+			bot.Session = fake.Session()
+			fake.Handle("GET", "/guilds/guild-1", func(w http.ResponseWriter, r *http.Request) {
+				testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "guild-1", "owner_id": "owner-1"})
+			})
+
+			handleRegister(bot, bot.Session, tagsInteraction("stobot_register", ""))
+			channelSetups.Wait()
+
+			environment, err := database.GetChannelEnvironment(bot, "channel-a")
+			if err != nil {
+				t.Fatalf("Failed to get channel environment: %v", err)
+			}
+			if environment != tt.expected {
+				t.Errorf("Expected environment %s, got %s", tt.expected, environment)
+			}
+
+			handleStatus(bot, bot.Session, tagsInteraction("stobot_status", ""))
+			calls := fake.RequestsTo("POST", "/interactions/interaction-1/interaction-token/callback")
+			if status := string(calls[len(calls)-1].Body); !strings.Contains(status, "Bot Environment**: "+botEnvironment(bot)) {
+				t.Errorf("Expected the status to show the bot environment, got %s", status)
+			}
+		})
+	}
+}
+
+func TestRegisterBackfill(t *testing.T) {
+	tests := []struct {
+		backfill string
+		posted   int
+		reply    string
+	}{
+		{"", 0, "Backfill: none"},
+		{"none", 0, "Backfill: none"},
+		{"recent", 2, "Backfill: posting up to 2 of the newest news items"},
+		{"all", 4, "Backfill: posting all cached news"},
+	}
+
+	for _, tt := range tests {
+		t.Run("backfill "+tt.backfill, func(t *testing.T) {
+			bot := testhelpers.CreateTestBot(t)
+			defer bot.DB.Close()
+			bot.Config.RegisterBackfillCount = 2
+			bot.Config.SkipDuplicateCheck = true
+			fake := testhelpers.NewFakeDiscord(t)
+			bot.Session = fake.Session()
+			fake.Handle("GET", "/guilds/guild-1", func(w http.ResponseWriter, r *http.Request) {
+				testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "guild-1", "owner_id": "owner-1"})
+			})
+			var cached []types.NewsItem
+			for id := int64(1); id <= 4; id++ {
+				cached = append(cached, types.NewsItem{ID: id, Title: "News", Tags: []string{"star-trek-online"},
+					Platforms: []string{"pc"}, Updated: time.Now().Add(-time.Duration(5-id) * time.Hour)})
+			}
+			if err := database.CacheNews(bot, cached); err != nil {
+				t.Fatalf("Failed to cache news: %v", err)
+			}
+
+			interaction := tagsInteraction("stobot_register", "")
+			interaction.ApplicationCommandData().Options[0] = &discordgo.ApplicationCommandInteractionDataOption{
+				Name: "backfill", Type: discordgo.ApplicationCommandOptionString, Value: tt.backfill,
+			}
+			handleRegister(bot, bot.Session, interaction)
+			channelSetups.Wait()
+
+			followups := fake.RequestsTo("POST", "/webhooks/app-1/interaction-token")
+			if len(followups) != 1 || !strings.Contains(string(followups[0].Body), tt.reply) {
+				t.Fatalf("Expected a reply containing %q, got %v", tt.reply, followups)
+			}
+			if posts := fake.RequestsTo("POST", "/channels/channel-a/messages"); len(posts) != tt.posted {
+				t.Errorf("Expected %d posts, got %d", tt.posted, len(posts))
+			}
+			// Every cached item is posted or marked, so polls post none of it
+			for id := int64(1); id <= 4; id++ {
+				if posted, err := database.IsNewsPosted(bot, id, "channel-a"); err != nil || !posted {
+					t.Errorf("Expected news %d marked as posted (%v)", id, err)
+				}
+			}
+
+			// Registering the channel again sends nothing more
+			handleRegister(bot, bot.Session, interaction)
+			channelSetups.Wait()
+			followups = fake.RequestsTo("POST", "/webhooks/app-1/interaction-token")
+			if strings.Contains(string(followups[len(followups)-1].Body), "Backfill") {
+				t.Errorf("Expected no backfill for a registered channel, got %s", followups[len(followups)-1].Body)
+			}
+			if posts := fake.RequestsTo("POST", "/channels/channel-a/messages"); len(posts) != tt.posted {
+				t.Errorf("Expected no posts on registering again, got %d", len(posts)-tt.posted)
+			}
+		})
+	}
+}
+
+func TestRegisterInvalidBackfill(t *testing.T) {
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	fake := testhelpers.NewFakeDiscord(t)
+	bot.Session = fake.Session()
+	fake.Handle("GET", "/guilds/guild-1", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "guild-1", "owner_id": "owner-1"})
+	})
+
+	interaction := tagsInteraction("stobot_register", "")
+	interaction.ApplicationCommandData().Options[0] = &discordgo.ApplicationCommandInteractionDataOption{
+		Name: "backfill", Type: discordgo.ApplicationCommandOptionString, Value: "everything",
+	}
+	handleRegister(bot, bot.Session, interaction)
+
+	followups := fake.RequestsTo("POST", "/webhooks/app-1/interaction-token")
+	if len(followups) != 1 || !strings.Contains(string(followups[0].Body), "Invalid backfill") {
+		t.Fatalf("Expected the backfill to be refused, got %v", followups)
+	}
+	if cfg, err := database.GetChannelConfig(bot, "channel-a"); err != nil || cfg != nil {
+		t.Errorf("Expected the channel not registered, got %+v (%v)", cfg, err)
+	}
+}
+
+func TestSetTagsRequiresRegistration(t *testing.T) {
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	fake := testhelpers.NewFakeDiscord(t)
+	bot.Session = fake.Session()
+	fake.Handle("GET", "/guilds/guild-1", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "guild-1", "owner_id": "owner-1"})
+	})
+
+	handleSetTags(bot, bot.Session, tagsInteraction("stobot_set_tags", "events"))
+
+	calls := fake.RequestsTo("POST", "/interactions/interaction-1/interaction-token/callback")
+	if len(calls) != 1 {
+		t.Fatalf("Expected 1 response, got %d", len(calls))
+	}
+	if !strings.Contains(string(calls[0].Body), "not registered") {
+		t.Errorf("Expected a not registered error, got %s", calls[0].Body)
+	}
+}
+
+func TestExcludeTagsCommand(t *testing.T) {
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	fake := testhelpers.NewFakeDiscord(t)
+	bot.Session = fake.Session()
+	fake.Handle("GET", "/guilds/guild-1", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "guild-1", "owner_id": "owner-1"})
+	})
+
+	// Unregistered channels are rejected
+	handleExcludeTags(bot, bot.Session, tagsInteraction("stobot_exclude_tags", "dev-blogs"))
+	calls := fake.RequestsTo("POST", "/interactions/interaction-1/interaction-token/callback")
+	if len(calls) != 1 || !strings.Contains(string(calls[0].Body), "not registered") {
+		t.Fatalf("Expected a not registered error, got %d responses", len(calls))
+	}
+
+	if err := database.AddChannel(bot, "channel-a"); err != nil {
+		t.Fatalf("Failed to add channel: %v", err)
+	}
+
+	handleExcludeTags(bot, bot.Session, tagsInteraction("stobot_exclude_tags", "Dev-Blogs"))
+	excludedTags, err := database.GetChannelExcludedTags(bot, "channel-a")
+	if err != nil {
+		t.Fatalf("Failed to get excluded tags: %v", err)
+	}
+	if !reflect.DeepEqual(excludedTags, []string{"dev-blogs"}) {
+		t.Errorf("Expected excluded tags [dev-blogs], got %v", excludedTags)
+	}
+
+	// The status shows the exclusion list
+	handleStatus(bot, bot.Session, tagsInteraction("stobot_status", ""))
+	calls = fake.RequestsTo("POST", "/interactions/interaction-1/interaction-token/callback")
+	if status := string(calls[len(calls)-1].Body); !strings.Contains(status, "Excluded Tags**: dev-blogs") {
+		t.Errorf("Expected the status to show the excluded tags, got %s", status)
+	}
+
+	// An empty list clears it
+	handleExcludeTags(bot, bot.Session, tagsInteraction("stobot_exclude_tags", ""))
+	excludedTags, err = database.GetChannelExcludedTags(bot, "channel-a")
+	if err != nil {
+		t.Fatalf("Failed to get excluded tags: %v", err)
+	}
+	if len(excludedTags) != 0 {
+		t.Errorf("Expected excluded tags to be cleared, got %v", excludedTags)
+	}
+}
+
+func pingRoleInteraction(command, roleID string) *discordgo.InteractionCreate {
+	interaction := tagsInteraction(command, "")
+	interaction.Data = discordgo.ApplicationCommandInteractionData{Name: command}
+	if roleID != "" {
+		interaction.Data = discordgo.ApplicationCommandInteractionData{
+			Name: command,
+			Options: []*discordgo.ApplicationCommandInteractionDataOption{
+				{Name: "role", Type: discordgo.ApplicationCommandOptionRole, Value: roleID},
+			},
+		}
+	}
+	return interaction
+}
+
+func TestSetPingRoleCommand(t *testing.T) {
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	fake := testhelpers.NewFakeDiscord(t)
+	bot.Session = fake.Session()
+	fake.Handle("GET", "/guilds/guild-1", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "guild-1", "owner_id": "owner-1"})
+	})
+
+	// Unregistered channels are rejected
+	handleSetPingRole(bot, bot.Session, pingRoleInteraction("stobot_set_ping_role", "role-1"))
+	calls := fake.RequestsTo("POST", "/interactions/interaction-1/interaction-token/callback")
+	if len(calls) != 1 || !strings.Contains(string(calls[0].Body), "not registered") {
+		t.Fatalf("Expected a not registered error, got %d responses", len(calls))
+	}
+
+	if err := database.AddChannel(bot, "channel-a"); err != nil {
+		t.Fatalf("Failed to add channel: %v", err)
+	}
+
+	handleSetPingRole(bot, bot.Session, pingRoleInteraction("stobot_set_ping_role", "role-1"))
+	pingRole, err := database.GetChannelPingRole(bot, "channel-a")
+	if err != nil {
+		t.Fatalf("Failed to get ping role: %v", err)
+	}
+	if pingRole != "role-1" {
+		t.Errorf("Expected ping role role-1, got %q", pingRole)
+	}
+
+	// The status shows the role
+	handleStatus(bot, bot.Session, tagsInteraction("stobot_status", ""))
+	calls = fake.RequestsTo("POST", "/interactions/interaction-1/interaction-token/callback")
+	// Discord JSON bodies escape < and &, so match on the role ID
+	if status := string(calls[len(calls)-1].Body); !strings.Contains(status, "Ping Role**: \\u003c@\\u0026role-1") {
+		t.Errorf("Expected the status to show the ping role, got %s", status)
+	}
+
+	// No role clears it
+	handleSetPingRole(bot, bot.Session, pingRoleInteraction("stobot_set_ping_role", ""))
+	pingRole, err = database.GetChannelPingRole(bot, "channel-a")
+	if err != nil {
+		t.Fatalf("Failed to get ping role: %v", err)
+	}
+	if pingRole != "" {
+		t.Errorf("Expected ping role to be cleared, got %q", pingRole)
+	}
+}
+
+func TestStatusRecentPosts(t *testing.T) {
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	fake := testhelpers.NewFakeDiscord(t)
+	bot.Session = fake.Session()
+
+	if err := database.AddChannel(bot, "channel-a"); err != nil {
+		t.Fatalf("Failed to add channel: %v", err)
+	}
+
+	lastStatus := func() string {
+		t.Helper()
+		handleStatus(bot, bot.Session, tagsInteraction("stobot_status", ""))
+		calls := fake.RequestsTo("POST", "/interactions/interaction-1/interaction-token/callback")
+		if len(calls) == 0 {
+			t.Fatal("Expected a status response")
+		}
+		return string(calls[len(calls)-1].Body)
+	}
+
+	status := lastStatus()
+	for _, expected := range []string{"Environment**: PROD", "Recent Posts**: No news posted yet"} {
+		if !strings.Contains(status, expected) {
+			t.Errorf("Expected the status to contain %q, got %s", expected, status)
+		}
+	}
+
+	if err := database.CacheNews(bot, []types.NewsItem{{ID: 1, Title: "Season Update", Updated: time.Now()}}); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+	for id, postedAt := range map[int64]string{1: "2024-06-01 12:00:00", 2: "2024-06-02 08:30:00"} {
+		if _, err := bot.DB.Exec(`INSERT INTO posted_news (news_id, channel_id, posted_at) VALUES (?, ?, ?)`,
+			id, "channel-a", postedAt); err != nil {
+			t.Fatalf("Failed to insert posted news: %v", err)
+		}
+	}
+
+	status = lastStatus()
+	newest := strings.Index(status, "2024-06-02 08:30 UTC - News #2")
+	oldest := strings.Index(status, "2024-06-01 12:00 UTC - Season Update")
+	if newest < 0 || oldest < 0 || newest > oldest {
+		t.Errorf("Expected recent posts newest first, got %s", status)
+	}
+	if strings.Contains(status, "No news posted yet") {
+		t.Errorf("Expected no empty history line, got %s", status)
+	}
+}
+
+func webhookInteraction(webhookURL string) *discordgo.InteractionCreate {
+	interaction := tagsInteraction("stobot_set_webhook", "")
+	interaction.Data = discordgo.ApplicationCommandInteractionData{Name: "stobot_set_webhook"}
+	if webhookURL != "" {
+		interaction.Data = discordgo.ApplicationCommandInteractionData{
+			Name: "stobot_set_webhook",
+			Options: []*discordgo.ApplicationCommandInteractionDataOption{
+				{Name: "url", Type: discordgo.ApplicationCommandOptionString, Value: webhookURL},
+			},
+		}
+	}
+	return interaction
+}
+
+func TestSetWebhookCommand(t *testing.T) {
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	fake := testhelpers.NewFakeDiscord(t)
+	bot.Session = fake.Session()
+	fake.Handle("GET", "/guilds/guild-1", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "guild-1", "owner_id": "owner-1"})
+	})
+	fake.Handle("GET", "/webhooks/111/own-token", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "111", "channel_id": "channel-a"})
+	})
+	fake.Handle("GET", "/webhooks/222/other-token", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "222", "channel_id": "channel-b"})
+	})
+	const ownWebhook = "https://discord.com/api/webhooks/111/own-token"
+
+	lastResponse := func() string {
+		t.Helper()
+		calls := fake.RequestsTo("POST", "/interactions/interaction-1/interaction-token/callback")
+		if len(calls) == 0 {
+			t.Fatalf("Expected a response")
+		}
+		return string(calls[len(calls)-1].Body)
+	}
+	webhookURL := func() string {
+		t.Helper()
+		cfg, err := database.GetChannelConfig(bot, "channel-a")
+		if err != nil {
+			t.Fatalf("Failed to get channel config: %v", err)
+		}
+		return cfg.WebhookURL
+	}
+
+	// Unregistered channels are rejected
+	handleSetWebhook(bot, bot.Session, webhookInteraction(ownWebhook))
+	if response := lastResponse(); !strings.Contains(response, "not registered") {
+		t.Fatalf("Expected a not registered error, got %s", response)
+	}
+
+	if err := database.AddChannel(bot, "channel-a"); err != nil {
+		t.Fatalf("Failed to add channel: %v", err)
+	}
+
+	rejected := []struct {
+		name       string
+		webhookURL string
+		expected   string
+	}{
+		{"not a webhook", "https://example.com/hook", "not a Discord webhook URL"},
+		{"other channel", "https://discord.com/api/webhooks/222/other-token", "different channel"},
+		{"unknown webhook", "https://discord.com/api/webhooks/333/gone", "could not be found"},
+	}
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.name == "unknown webhook" {
+				fake.Handle("GET", "/webhooks/333/gone", func(w http.ResponseWriter, r *http.Request) {
+					testhelpers.RespondJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Unknown Webhook", "code": 10015})
+				})
+			}
+			handleSetWebhook(bot, bot.Session, webhookInteraction(tt.webhookURL))
+			if response := lastResponse(); !strings.Contains(response, tt.expected) {
+				t.Errorf("Expected %q, got %s", tt.expected, response)
+			}
+			if url := webhookURL(); url != "" {
+				t.Errorf("Expected no webhook to be stored, got %q", url)
+			}
+		})
+	}
+
+	handleSetWebhook(bot, bot.Session, webhookInteraction(ownWebhook))
+	if url := webhookURL(); url != ownWebhook {
+		t.Errorf("Expected webhook %q, got %q", ownWebhook, url)
+	}
+	// The token is never echoed back
+	if response := lastResponse(); strings.Contains(response, "own-token") {
+		t.Errorf("Expected the response not to contain the webhook token, got %s", response)
+	}
+
+	// The status shows that a webhook is configured, without the URL
+	handleStatus(bot, bot.Session, tagsInteraction("stobot_status", ""))
+	status := lastResponse()
+	if !strings.Contains(status, "Webhook**: Configured") || strings.Contains(status, "own-token") {
+		t.Errorf("Expected the status to show the webhook without its URL, got %s", status)
+	}
+
+	// No URL clears it
+	handleSetWebhook(bot, bot.Session, webhookInteraction(""))
+	if url := webhookURL(); url != "" {
+		t.Errorf("Expected the webhook to be cleared, got %q", url)
+	}
+}
+
+func quietHoursInteraction(hours ...int64) *discordgo.InteractionCreate {
+	var options []*discordgo.ApplicationCommandInteractionDataOption
+	for i, name := range []string{"start", "end"} {
+		if i < len(hours) {
+			options = append(options, &discordgo.ApplicationCommandInteractionDataOption{
+				Name: name, Type: discordgo.ApplicationCommandOptionInteger, Value: float64(hours[i])})
+		}
+	}
+	interaction := discoveryInteraction("stobot_set_quiet_hours", options...)
+	interaction.Member = &discordgo.Member{User: &discordgo.User{ID: "owner-1"}}
+	return interaction
+}
+
+func TestSetQuietHoursCommand(t *testing.T) {
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	fake := testhelpers.NewFakeDiscord(t)
+	bot.Session = fake.Session()
+	fake.Handle("GET", "/guilds/guild-1", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "guild-1", "owner_id": "owner-1"})
+	})
+	lastResponse := func() string {
+		t.Helper()
+		calls := fake.RequestsTo("POST", "/interactions/interaction-1/interaction-token/callback")
+		if len(calls) == 0 {
+			t.Fatal("Expected a response")
+		}
+		return string(calls[len(calls)-1].Body)
+	}
+	channelConfig := func() *database.ChannelConfig {
+		t.Helper()
+		cfg, err := database.GetChannelConfig(bot, "channel-a")
+		if err != nil {
+			t.Fatalf("Failed to get channel config: %v", err)
+		}
+		return cfg
+	}
+
+	// Unregistered channels are rejected
+	handleSetQuietHours(bot, bot.Session, quietHoursInteraction(22, 7))
+	if response := lastResponse(); !strings.Contains(response, "not registered") {
+		t.Fatalf("Expected a not registered error, got %s", response)
+	}
+
+	if err := database.AddChannel(bot, "channel-a"); err != nil {
+		t.Fatalf("Failed to add channel: %v", err)
+	}
+
+	// Only a start hour, or an empty window, is rejected
+	handleSetQuietHours(bot, bot.Session, quietHoursInteraction(22))
+	if response := lastResponse(); !strings.Contains(response, "both the start and the end") {
+		t.Errorf("Expected a missing hour error, got %s", response)
+	}
+	handleSetQuietHours(bot, bot.Session, quietHoursInteraction(5, 5))
+	if response := lastResponse(); !strings.Contains(response, "must differ") {
+		t.Errorf("Expected an empty window error, got %s", response)
+	}
+	if channelConfig().QuietHours {
+		t.Error("Expected invalid quiet hours not to be stored")
+	}
+
+	handleSetQuietHours(bot, bot.Session, quietHoursInteraction(22, 7))
+	if cfg := channelConfig(); !cfg.QuietHours || cfg.QuietHoursStart != 22 || cfg.QuietHoursEnd != 7 {
+		t.Errorf("Expected quiet hours 22 to 7, got %+v", cfg)
+	}
+
+	// The status shows the quiet hours
+	handleStatus(bot, bot.Session, tagsInteraction("stobot_status", ""))
+	if status := lastResponse(); !strings.Contains(status, "Quiet Hours**: 22:00–07:00 UTC") {
+		t.Errorf("Expected the status to show the quiet hours, got %s", status)
+	}
+
+	// No hours turn them off
+	handleSetQuietHours(bot, bot.Session, quietHoursInteraction())
+	if cfg := channelConfig(); cfg.QuietHours {
+		t.Errorf("Expected quiet hours to be turned off, got %+v", cfg)
+	}
+}
+
+func TestSetLocaleCommand(t *testing.T) {
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	fake := testhelpers.NewFakeDiscord(t)
+	bot.Session = fake.Session()
+	fake.Handle("GET", "/guilds/guild-1", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "guild-1", "owner_id": "owner-1"})
+	})
+	lastResponse := func() string {
+		t.Helper()
+		calls := fake.RequestsTo("POST", "/interactions/interaction-1/interaction-token/callback")
+		if len(calls) == 0 {
+			t.Fatal("Expected a response")
+		}
+		return string(calls[len(calls)-1].Body)
+	}
+	localeInteraction := func(locale string) *discordgo.InteractionCreate {
+		interaction := discoveryInteraction("stobot_set_locale", stringOption("locale", locale))
+		interaction.Member = &discordgo.Member{User: &discordgo.User{ID: "owner-1"}}
+		return interaction
+	}
+
+	// Unregistered channels are rejected
+	handleSetLocale(bot, bot.Session, localeInteraction("de"))
+	if response := lastResponse(); !strings.Contains(response, "not registered") {
+		t.Fatalf("Expected a not registered error, got %s", response)
+	}
+
+	if err := database.AddChannel(bot, "channel-a"); err != nil {
+		t.Fatalf("Failed to add channel: %v", err)
+	}
+
+	// Locales outside the whitelist are rejected
+	handleSetLocale(bot, bot.Session, localeInteraction("es"))
+	if response := lastResponse(); !strings.Contains(response, "Unknown locale") {
+		t.Errorf("Expected an unknown locale error, got %s", response)
+	}
+
+	handleSetLocale(bot, bot.Session, localeInteraction("de"))
+	cfg, err := database.GetChannelConfig(bot, "channel-a")
+	if err != nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if cfg.Locale != "de" {
+		t.Errorf("Expected locale de, got %q", cfg.Locale)
+	}
+
+	// The status shows the language
+	handleStatus(bot, bot.Session, tagsInteraction("stobot_status", ""))
+	if status := lastResponse(); !strings.Contains(status, "Language**: German") {
+		t.Errorf("Expected the status to show the language, got %s", status)
+	}
+}
+
+func TestSetThreadsCommand(t *testing.T) {
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	fake := testhelpers.NewFakeDiscord(t)
+	bot.Session = fake.Session()
+	fake.Handle("GET", "/guilds/guild-1", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "guild-1", "owner_id": "owner-1"})
+	})
+	lastResponse := func() string {
+		t.Helper()
+		calls := fake.RequestsTo("POST", "/interactions/interaction-1/interaction-token/callback")
+		if len(calls) == 0 {
+			t.Fatal("Expected a response")
+		}
+		return string(calls[len(calls)-1].Body)
+	}
+	threadsInteraction := func(options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.InteractionCreate {
+		interaction := discoveryInteraction("stobot_set_threads", options...)
+		interaction.Member = &discordgo.Member{User: &discordgo.User{ID: "owner-1"}}
+		return interaction
+	}
+	createThreads := func() bool {
+		t.Helper()
+		cfg, err := database.GetChannelConfig(bot, "channel-a")
+		if err != nil {
+			t.Fatalf("Failed to get channel config: %v", err)
+		}
+		return cfg.CreateThreads
+	}
+
+	// Unregistered channels are rejected
+	handleSetThreads(bot, bot.Session, threadsInteraction())
+	if response := lastResponse(); !strings.Contains(response, "not registered") {
+		t.Fatalf("Expected a not registered error, got %s", response)
+	}
+
+	if err := database.AddChannel(bot, "channel-a"); err != nil {
+		t.Fatalf("Failed to add channel: %v", err)
+	}
+
+	// Enabled without an option
+	handleSetThreads(bot, bot.Session, threadsInteraction())
+	if !createThreads() {
+		t.Error("Expected threads to be enabled")
+	}
+	handleStatus(bot, bot.Session, tagsInteraction("stobot_status", ""))
+	if status := lastResponse(); !strings.Contains(status, "Discussion Threads**: Enabled") {
+		t.Errorf("Expected the status to show threads, got %s", status)
+	}
+
+	handleSetThreads(bot, bot.Session, threadsInteraction(&discordgo.ApplicationCommandInteractionDataOption{
+		Name: "enabled", Type: discordgo.ApplicationCommandOptionBoolean, Value: false,
+	}))
+	if createThreads() {
+		t.Error("Expected threads to be disabled")
+	}
+	if response := lastResponse(); !strings.Contains(response, "Discussion threads disabled") {
+		t.Errorf("Expected a confirmation, got %s", response)
+	}
+}
+
+func TestSetMaxPostsCommand(t *testing.T) {
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	bot.Config.MaxPostsPerCycle = 10
+	fake := testhelpers.NewFakeDiscord(t)
+	bot.Session = fake.Session()
+	fake.Handle("GET", "/guilds/guild-1", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "guild-1", "owner_id": "owner-1"})
+	})
+	lastResponse := func() string {
+		t.Helper()
+		calls := fake.RequestsTo("POST", "/interactions/interaction-1/interaction-token/callback")
+		if len(calls) == 0 {
+			t.Fatal("Expected a response")
+		}
+		return string(calls[len(calls)-1].Body)
+	}
+	maxPostsInteraction := func(options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.InteractionCreate {
+		interaction := discoveryInteraction("stobot_set_max_posts", options...)
+		interaction.Member = &discordgo.Member{User: &discordgo.User{ID: "owner-1"}}
+		return interaction
+	}
+	maxOption := func(value float64) *discordgo.ApplicationCommandInteractionDataOption {
+		return &discordgo.ApplicationCommandInteractionDataOption{Name: "max", Type: discordgo.ApplicationCommandOptionInteger, Value: value}
+	}
+	maxPosts := func() int {
+		t.Helper()
+		cfg, err := database.GetChannelConfig(bot, "channel-a")
+		if err != nil {
+			t.Fatalf("Failed to get channel config: %v", err)
+		}
+		return cfg.MaxPostsPerCycle
+	}
+
+	// Unregistered channels are rejected
+	handleSetMaxPosts(bot, bot.Session, maxPostsInteraction(maxOption(3)))
+	if response := lastResponse(); !strings.Contains(response, "not registered") {
+		t.Fatalf("Expected a not registered error, got %s", response)
+	}
+
+	if err := database.AddChannel(bot, "channel-a"); err != nil {
+		t.Fatalf("Failed to add channel: %v", err)
+	}
+
+	handleSetMaxPosts(bot, bot.Session, maxPostsInteraction(maxOption(3)))
+	if maxPosts() != 3 {
+		t.Errorf("Expected 3 posts per cycle, got %d", maxPosts())
+	}
+	if response := lastResponse(); !strings.Contains(response, "At most 3 news posts per poll") {
+		t.Errorf("Expected a confirmation, got %s", response)
+	}
+	handleStatus(bot, bot.Session, tagsInteraction("stobot_status", ""))
+	if status := lastResponse(); !strings.Contains(status, "Posts Per Poll**: At most 3") {
+		t.Errorf("Expected the status to show the posts per poll, got %s", status)
+	}
+
+	// Without a value the bot's default applies again
+	handleSetMaxPosts(bot, bot.Session, maxPostsInteraction())
+	if maxPosts() != 0 {
+		t.Errorf("Expected the default posts per cycle, got %d", maxPosts())
+	}
+	if response := lastResponse(); !strings.Contains(response, "default posts per poll: 10") {
+		t.Errorf("Expected the default in the confirmation, got %s", response)
+	}
+}
+
+func migrateChannelInteraction(oldID, newID string) *discordgo.InteractionCreate {
+	interaction := tagsInteraction("stobot_migrate_channel", "")
+	interaction.Data = discordgo.ApplicationCommandInteractionData{
+		Name: "stobot_migrate_channel",
+		Options: []*discordgo.ApplicationCommandInteractionDataOption{
+			{Name: "old_channel", Type: discordgo.ApplicationCommandOptionString, Value: oldID},
+			{Name: "new_channel", Type: discordgo.ApplicationCommandOptionString, Value: newID},
+		},
+	}
+	return interaction
+}
+
+func TestMigrateChannelCommand(t *testing.T) {
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	fake := testhelpers.NewFakeDiscord(t)
+	bot.Session = fake.Session()
+	fake.Handle("GET", "/guilds/guild-1", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "guild-1", "owner_id": "owner-1"})
+	})
+	fake.Handle("GET", "/guilds/guild-2", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "guild-2", "owner_id": "owner-2"})
+	})
+	fake.Handle("GET", "/guilds/guild-2/members/owner-1", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Unknown Member", "code": 10007})
+	})
+	for channelID, guildID := range map[string]string{"channel-new": "guild-1", "channel-other-server": "guild-2"} {
+		body := map[string]interface{}{"id": channelID, "guild_id": guildID, "type": discordgo.ChannelTypeGuildText}
+		fake.Handle("GET", "/channels/"+channelID, func(w http.ResponseWriter, r *http.Request) {
+			testhelpers.RespondJSON(w, http.StatusOK, body)
+		})
+	}
+	fake.Handle("GET", "/channels/channel-gone", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Unknown Channel", "code": 10003})
+	})
+
+	lastResponse := func() string {
+		t.Helper()
+		calls := fake.RequestsTo("POST", "/interactions/interaction-1/interaction-token/callback")
+		if len(calls) == 0 {
+			t.Fatalf("Expected a response")
+		}
+		return string(calls[len(calls)-1].Body)
+	}
+
+	for _, channelID := range []string{"channel-old", "channel-a", "channel-elsewhere"} {
+		if err := database.AddChannel(bot, channelID); err != nil {
+			t.Fatalf("Failed to add channel: %v", err)
+		}
+	}
+	if err := database.UpdateChannelGuild(bot, "channel-elsewhere", "guild-2"); err != nil {
+		t.Fatalf("Failed to update guild: %v", err)
+	}
+	if err := database.UpdateChannelPlatforms(bot, "channel-old", []string{"xbox"}); err != nil {
+		t.Fatalf("Failed to update platforms: %v", err)
+	}
+	for id := int64(1); id <= 2; id++ {
+		if err := database.MarkNewsAsPosted(bot, id, "channel-old"); err != nil {
+			t.Fatalf("Failed to mark news as posted: %v", err)
+		}
+	}
+
+	rejected := []struct {
+		name     string
+		oldID    string
+		newID    string
+		expected string
+	}{
+		{"unregistered old channel", "channel-missing", "channel-new", "channel-missing is not registered"},
+		{"registered new channel", "channel-old", "<#channel-a>", "already registered"},
+		{"missing new channel", "channel-old", "channel-gone", "could not be found"},
+		{"new channel in another server", "channel-old", "channel-other-server", "different server"},
+		{"old channel in a server the user does not administer", "channel-elsewhere", "channel-new", "server of the old channel"},
+	}
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			handleMigrateChannel(bot, bot.Session, migrateChannelInteraction(tt.oldID, tt.newID))
+			if response := lastResponse(); !strings.Contains(response, tt.expected) {
+				t.Errorf("Expected %q, got %s", tt.expected, response)
+			}
+			if cfg, _ := database.GetChannelConfig(bot, "channel-new"); cfg != nil {
+				t.Errorf("Expected nothing to be migrated, got %+v", cfg)
+			}
+		})
+	}
+
+	handleMigrateChannel(bot, bot.Session, migrateChannelInteraction("channel-old", "<#channel-new>"))
+	if response := lastResponse(); !strings.Contains(response, "with 2 posted articles") {
+		t.Fatalf("Expected the migration to succeed, got %s", response)
+	}
+	cfg, err := database.GetChannelConfig(bot, "channel-new")
+	if err != nil || cfg == nil {
+		t.Fatalf("Expected the new channel to be registered, got %+v (%v)", cfg, err)
+	}
+	if !reflect.DeepEqual(cfg.Platforms, []string{"xbox"}) || cfg.GuildID != "guild-1" {
+		t.Errorf("Expected the platforms to move and the server to be recorded, got %+v", cfg)
+	}
+	for id := int64(1); id <= 2; id++ {
+		if posted, _ := database.IsNewsPosted(bot, id, "channel-new"); !posted {
+			t.Errorf("Expected news %d to stay posted after the move", id)
+		}
+	}
+	if cfg, _ := database.GetChannelConfig(bot, "channel-old"); cfg != nil {
+		t.Errorf("Expected the old channel to be removed, got %+v", cfg)
+	}
+}
+
+func TestPauseResumeCommands(t *testing.T) {
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	fake := testhelpers.NewFakeDiscord(t)
+	bot.Session = fake.Session()
+	fake.Handle("GET", "/guilds/guild-1", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "guild-1", "owner_id": "owner-1"})
+	})
+	lastResponse := func() string {
+		t.Helper()
+		calls := fake.RequestsTo("POST", "/interactions/interaction-1/interaction-token/callback")
+		if len(calls) == 0 {
+			t.Fatal("Expected a response")
+		}
+		return string(calls[len(calls)-1].Body)
+	}
+	adminInteraction := func(command string, options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.InteractionCreate {
+		interaction := discoveryInteraction(command, options...)
+		interaction.Member = &discordgo.Member{User: &discordgo.User{ID: "owner-1"}}
+		return interaction
+	}
+	channelConfig := func() *database.ChannelConfig {
+		t.Helper()
+		cfg, err := database.GetChannelConfig(bot, "channel-a")
+		if err != nil {
+			t.Fatalf("Failed to get channel config: %v", err)
+		}
+		return cfg
+	}
+	holdOption := &discordgo.ApplicationCommandInteractionDataOption{
+		Name: "hold", Type: discordgo.ApplicationCommandOptionBoolean, Value: true,
+	}
+
+	// Unregistered channels are rejected
+	handlePause(bot, bot.Session, adminInteraction("stobot_pause"))
+	if response := lastResponse(); !strings.Contains(response, "not registered") {
+		t.Fatalf("Expected a not registered error, got %s", response)
+	}
+
+	if err := database.AddChannel(bot, "channel-a"); err != nil {
+		t.Fatalf("Failed to add channel: %v", err)
+	}
+	if err := database.UpdateChannelTags(bot, "channel-a", []string{"patch-notes"}); err != nil {
+		t.Fatalf("Failed to update tags: %v", err)
+	}
+
+	// Members cannot pause news
+	memberInteraction := discoveryInteraction("stobot_pause")
+	memberInteraction.Member = &discordgo.Member{User: &discordgo.User{ID: "user-1"}}
+	handlePause(bot, bot.Session, memberInteraction)
+	if cfg := channelConfig(); cfg.Paused {
+		t.Error("Expected a member not to be able to pause news")
+	}
+
+	handleResume(bot, bot.Session, adminInteraction("stobot_resume"))
+	if response := lastResponse(); !strings.Contains(response, "not paused") {
+		t.Errorf("Expected an error for a channel that is not paused, got %s", response)
+	}
+
+	// Skipping news by default
+	handlePause(bot, bot.Session, adminInteraction("stobot_pause"))
+	if cfg := channelConfig(); !cfg.Paused || cfg.PauseHold {
+		t.Errorf("Expected the channel to be paused, skipping news, got %+v", cfg)
+	}
+	if response := lastResponse(); !strings.Contains(response, "is skipped") {
+		t.Errorf("Expected a confirmation, got %s", response)
+	}
+	handleStatus(bot, bot.Session, tagsInteraction("stobot_status", ""))
+	if status := lastResponse(); !strings.Contains(status, "Posting**: Paused, news is skipped") {
+		t.Errorf("Expected the status to show the pause, got %s", status)
+	}
+
+	// Holding news, which is posted on resume
+	handlePause(bot, bot.Session, adminInteraction("stobot_pause", holdOption))
+	if cfg := channelConfig(); !cfg.Paused || !cfg.PauseHold {
+		t.Errorf("Expected the channel to be paused, holding news, got %+v", cfg)
+	}
+	handleStatus(bot, bot.Session, tagsInteraction("stobot_status", ""))
+	if status := lastResponse(); !strings.Contains(status, "Posting**: Paused, news is held") {
+		t.Errorf("Expected the status to show the hold, got %s", status)
+	}
+	if err := database.CacheNews(bot, []types.NewsItem{{ID: 1, Title: "Patch Notes", Updated: time.Now()}}); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+	if err := database.HoldNews(bot, 1, "channel-a"); err != nil {
+		t.Fatalf("Failed to hold news: %v", err)
+	}
+
+	handleResume(bot, bot.Session, adminInteraction("stobot_resume"))
+	cfg := channelConfig()
+	if cfg.Paused || cfg.PauseHold {
+		t.Errorf("Expected the channel to be resumed, got %+v", cfg)
+	}
+	if len(cfg.Tags) != 1 || cfg.Tags[0] != "patch-notes" {
+		t.Errorf("Expected the channel's settings to be kept, got tags %v", cfg.Tags)
+	}
+	if response := lastResponse(); !strings.Contains(response, "held while paused (1)") {
+		t.Errorf("Expected the held news to be announced, got %s", response)
+	}
+}
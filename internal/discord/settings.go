@@ -0,0 +1,368 @@
+package discord
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/news"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// settingsPrefix starts the custom IDs of the /stobot_settings panel's components.
+const settingsPrefix = "stobot_settings"
+
+// The settings a /stobot_settings panel component edits, encoded in its custom ID.
+const (
+	settingPlatforms    = "platforms"
+	settingTags         = "tags"
+	settingExcludedTags = "excluded_tags"
+	settingPingRole     = "ping_role"
+	settingClearPing    = "clear_ping_role"
+	settingPause        = "pause"
+	settingPauseHold    = "pause_hold"
+	settingResume       = "resume"
+	settingEnvironment  = "environment"
+)
+
+// panelSettings are the settings the components of a panel edit.
+var panelSettings = []string{
+	settingPlatforms, settingTags, settingExcludedTags, settingPingRole, settingClearPing,
+	settingPause, settingPauseHold, settingResume, settingEnvironment,
+}
+
+// maxSelectOptions is the number of options Discord allows in a select menu.
+const maxSelectOptions = 25
+
+// settingsID returns the custom ID of the panel component editing setting of a channel.
+func settingsID(setting, channelID string) string {
+	return fmt.Sprintf("%s:%s:%s", settingsPrefix, setting, channelID)
+}
+
+// parseSettingsID parses a custom ID built by settingsID. ok is false for unknown settings.
+func parseSettingsID(customID string) (setting, channelID string, ok bool) {
+	parts := strings.Split(customID, ":")
+	if len(parts) != 3 || parts[0] != settingsPrefix || parts[2] == "" || !slices.Contains(panelSettings, parts[1]) {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+// handleSettings handles the "settings" command interaction by showing the channel's settings
+// panel, from which each setting can be edited.
+func handleSettings(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Validate inputs
+	if i == nil || i.Interaction == nil {
+		logger().Warning("handleSettings called with nil interaction")
+		return
+	}
+
+	// Check if user has administrator permission
+	if !hasAdminPermission(s, i) {
+		RespondError(s, i, "You need Administrator permission to use this command.")
+		return
+	}
+
+	channelID := i.ChannelID
+
+	cfg, err := database.GetChannelConfig(b, channelID)
+	if err != nil {
+		channelLogger(channelID).Errorf("Failed to get channel config for %s: %v", channelID, err)
+		RespondError(s, i, "Failed to check channel status. Please try again later.")
+		return
+	}
+	if cfg == nil {
+		RespondError(s, i, "This channel is not registered. Use `/stobot_register` first.")
+		return
+	}
+
+	respondSettings(s, i, discordgo.InteractionResponseChannelMessageWithSource, settingsPanel(b, *cfg, ""))
+}
+
+// handleSettingsComponent handles an edit made on a settings panel. The channel and setting come
+// from the component's custom ID; the panel only edits the channel it was opened in, and only for
+// administrators, whoever opened it. The panel is then shown again with the change.
+func handleSettingsComponent(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	data := i.MessageComponentData()
+	setting, channelID, ok := parseSettingsID(data.CustomID)
+	if !ok {
+		logger().Warnf("Invalid settings component: %s", data.CustomID)
+		RespondError(s, i, "This settings panel is out of date. Use `/stobot_settings` to open it again.")
+		return
+	}
+	if channelID != i.ChannelID {
+		channelLogger(i.ChannelID).Warnf("Settings component of channel %s used in channel %s", channelID, i.ChannelID)
+		RespondError(s, i, "This settings panel belongs to another channel.")
+		return
+	}
+	if !hasAdminPermission(s, i) {
+		RespondError(s, i, "You need Administrator permission to change settings.")
+		return
+	}
+
+	cfg, err := database.GetChannelConfig(b, channelID)
+	if err != nil {
+		channelLogger(channelID).Errorf("Failed to get channel config for %s: %v", channelID, err)
+		RespondError(s, i, "Failed to check channel status. Please try again later.")
+		return
+	}
+	if cfg == nil {
+		RespondError(s, i, "This channel is not registered. Use `/stobot_register` first.")
+		return
+	}
+
+	notice, problem := applySetting(b, *cfg, setting, data)
+	if problem != "" {
+		RespondError(s, i, problem)
+		return
+	}
+	channelLogger(channelID).Infof("Channel %s setting %s changed from the settings panel", channelID, setting)
+
+	cfg, err = database.GetChannelConfig(b, channelID)
+	if err != nil || cfg == nil {
+		channelLogger(channelID).Errorf("Failed to get channel config for %s: %v", channelID, err)
+		RespondError(s, i, "The setting was saved, but the panel could not be refreshed. Use `/stobot_settings` again.")
+		return
+	}
+	respondSettings(s, i, discordgo.InteractionResponseUpdateMessage, settingsPanel(b, *cfg, notice))
+}
+
+// applySetting saves the edit of setting made with a panel component and returns a notice
+// describing it, or the problem to report when it was refused or failed.
+func applySetting(b *types.Bot, cfg types.ChannelConfig, setting string, data discordgo.MessageComponentInteractionData) (notice, problem string) {
+	channelID := cfg.ID
+	var err error
+	switch setting {
+	case settingPlatforms:
+		platforms, normalizeErr := types.NormalizePlatforms(data.Values)
+		if normalizeErr != nil || len(platforms) == 0 {
+			return "", "Choose at least one of pc, xbox and ps."
+		}
+		err = database.UpdateChannelPlatforms(b, channelID, platforms)
+		notice = "✅ Platforms set to " + strings.Join(platforms, ", ") + "."
+	case settingTags:
+		tags := parseTagList(strings.Join(data.Values, ","))
+		err = database.UpdateChannelTags(b, channelID, tags)
+		notice = "✅ Posting news with tags: " + formatChannelTags(tags) + "."
+	case settingExcludedTags:
+		excludedTags := parseTagList(strings.Join(data.Values, ","))
+		err = database.SetChannelExcludedTags(b, channelID, excludedTags)
+		notice = "✅ Excluded tags set to " + formatExcludedTags(excludedTags) + "."
+	case settingPingRole, settingClearPing:
+		roleID := ""
+		if setting == settingPingRole && len(data.Values) > 0 {
+			roleID = data.Values[0]
+			// Discord resolves the roles chosen in a role select menu
+			if _, ok := data.Resolved.Roles[roleID]; !ok {
+				return "", "Choose a role of this server."
+			}
+		}
+		err = database.UpdateChannelPingRole(b, channelID, roleID)
+		notice = "✅ Ping role set to " + formatPingRole(roleID) + "."
+	case settingPause, settingPauseHold:
+		hold := setting == settingPauseHold
+		err = database.UpdateChannelPaused(b, channelID, true, hold)
+		notice = "⏸️ News posting paused; news released meanwhile is skipped."
+		if hold {
+			notice = "⏸️ News posting paused; news released meanwhile is held and posted on resume."
+		}
+	case settingResume:
+		if !cfg.Paused {
+			return "", "News posting is not paused in this channel."
+		}
+		err = database.UpdateChannelPaused(b, channelID, false, false)
+		notice = "▶️ News posting resumed."
+		if heldCount, countErr := database.CountHeldNews(b, channelID); countErr == nil && heldCount > 0 {
+			notice = fmt.Sprintf("▶️ News posting resumed. Articles held while paused (%d) will be posted with the next news check.", heldCount)
+		}
+	case settingEnvironment:
+		environment := otherEnvironment(cfg.Environment)
+		err = database.UpdateChannelEnvironment(b, channelID, environment)
+		notice = fmt.Sprintf("✅ Environment set to %s; the %s bot posts news here from now on.", environment, environment)
+	}
+	if err != nil {
+		channelLogger(channelID).Errorf("Failed to update setting %s of channel %s: %v", setting, channelID, err)
+		return "", "Failed to save the setting. Please try again later."
+	}
+	return notice, ""
+}
+
+// otherEnvironment returns the environment a channel of environment is moved to from its panel.
+func otherEnvironment(environment string) string {
+	if environment == "DEV" {
+		return "PROD"
+	}
+	return "DEV"
+}
+
+// formatExcludedTags returns a channel's excluded tags for display.
+func formatExcludedTags(tags []string) string {
+	if len(tags) == 0 {
+		return "none"
+	}
+	return strings.Join(tags, ", ")
+}
+
+// formatPosting returns whether news is posted to a channel, for display.
+func formatPosting(cfg types.ChannelConfig) string {
+	if cfg.Paused {
+		return formatPaused(cfg)
+	}
+	return "Active"
+}
+
+// settingsPanel builds the settings panel of a channel: an embed summarizing its settings and the
+// components editing them. notice, when set, reports the last change above the embed.
+func settingsPanel(b *types.Bot, cfg types.ChannelConfig, notice string) *discordgo.InteractionResponseData {
+	embed := &discordgo.MessageEmbed{
+		Title:       "⚙️ News Settings",
+		Description: fmt.Sprintf("News settings of <#%s>. Changes apply from the next news check.", cfg.ID),
+		Color:       0x0066cc, // Blue color for settings
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Platforms", Value: strings.Join(cfg.Platforms, ", "), Inline: true},
+			{Name: "Environment", Value: cfg.Environment, Inline: true},
+			{Name: "Posting", Value: formatPosting(cfg), Inline: true},
+			{Name: "Tags", Value: formatChannelTags(cfg.Tags), Inline: true},
+			{Name: "Excluded Tags", Value: formatExcludedTags(cfg.ExcludedTags), Inline: true},
+			{Name: "Ping Role", Value: formatPingRole(cfg.PingRole), Inline: true},
+		},
+	}
+	for _, field := range embed.Fields {
+		if field.Value == "" {
+			field.Value = "none"
+		}
+	}
+
+	return &discordgo.InteractionResponseData{
+		Content:    notice,
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: settingsComponentRows(b, cfg),
+		Flags:      discordgo.MessageFlagsEphemeral,
+	}
+}
+
+// settingsComponentRows returns the rows of components of a channel's settings panel.
+func settingsComponentRows(b *types.Bot, cfg types.ChannelConfig) []discordgo.MessageComponent {
+	noMinimum := 0
+	tagOptions := settingsTagOptions(b, cfg)
+
+	platformOptions := make([]discordgo.SelectMenuOption, 0, len(types.Platforms))
+	for _, platform := range types.Platforms {
+		platformOptions = append(platformOptions, discordgo.SelectMenuOption{
+			Label:   platform,
+			Value:   platform,
+			Default: slices.Contains(cfg.Platforms, platform),
+		})
+	}
+
+	pauseButtons := []discordgo.MessageComponent{
+		discordgo.Button{Label: "Resume", Style: discordgo.SuccessButton, CustomID: settingsID(settingResume, cfg.ID)},
+	}
+	if !cfg.Paused {
+		pauseButtons = []discordgo.MessageComponent{
+			discordgo.Button{Label: "Pause", Style: discordgo.SecondaryButton, CustomID: settingsID(settingPause, cfg.ID)},
+			discordgo.Button{Label: "Pause and hold news", Style: discordgo.SecondaryButton, CustomID: settingsID(settingPauseHold, cfg.ID)},
+		}
+	}
+	buttons := append(pauseButtons,
+		discordgo.Button{
+			Label:    "Clear ping role",
+			Style:    discordgo.SecondaryButton,
+			CustomID: settingsID(settingClearPing, cfg.ID),
+			Disabled: cfg.PingRole == "",
+		},
+		discordgo.Button{
+			Label:    "Move to " + otherEnvironment(cfg.Environment),
+			Style:    discordgo.DangerButton,
+			CustomID: settingsID(settingEnvironment, cfg.ID),
+		},
+	)
+
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{
+				CustomID:    settingsID(settingPlatforms, cfg.ID),
+				Placeholder: "Platforms",
+				MaxValues:   len(platformOptions),
+				Options:     platformOptions,
+			},
+		}},
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{
+				CustomID:    settingsID(settingTags, cfg.ID),
+				Placeholder: "Tags to post (none selected: all tags)",
+				MinValues:   &noMinimum,
+				MaxValues:   len(tagOptions),
+				Options:     withDefaults(tagOptions, cfg.Tags),
+			},
+		}},
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{
+				CustomID:    settingsID(settingExcludedTags, cfg.ID),
+				Placeholder: "Tags never to post",
+				MinValues:   &noMinimum,
+				MaxValues:   len(tagOptions),
+				Options:     withDefaults(tagOptions, cfg.ExcludedTags),
+			},
+		}},
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{
+				MenuType:    discordgo.RoleSelectMenu,
+				CustomID:    settingsID(settingPingRole, cfg.ID),
+				Placeholder: "Role to mention in news posts",
+				MaxValues:   1,
+			},
+		}},
+		discordgo.ActionsRow{Components: buttons},
+	}
+}
+
+// settingsTagOptions returns the tags offered by a settings panel's tag menus: the channel's own
+// tags, the tags polled by default and the most used cached tags, up to the select menu limit.
+func settingsTagOptions(b *types.Bot, cfg types.ChannelConfig) []discordgo.SelectMenuOption {
+	tags := append(append([]string{}, cfg.Tags...), cfg.ExcludedTags...)
+	tags = append(tags, news.DefaultPollTags...)
+	popular, err := database.GetPopularTags(b, maxSelectOptions)
+	if err != nil {
+		channelLogger(cfg.ID).Errorf("Failed to get popular tags for the settings of channel %s: %v", cfg.ID, err)
+	}
+	for _, tagCount := range popular {
+		tags = append(tags, tagCount.Tag)
+	}
+
+	var options []discordgo.SelectMenuOption
+	for _, tag := range tags {
+		if len(options) >= maxSelectOptions {
+			break
+		}
+		if tag == "" || slices.ContainsFunc(options, func(option discordgo.SelectMenuOption) bool { return option.Value == tag }) {
+			continue
+		}
+		options = append(options, discordgo.SelectMenuOption{Label: tag, Value: tag})
+	}
+	return options
+}
+
+// withDefaults returns a copy of options with the options in selected marked as selected.
+func withDefaults(options []discordgo.SelectMenuOption, selected []string) []discordgo.SelectMenuOption {
+	marked := make([]discordgo.SelectMenuOption, len(options))
+	for index, option := range options {
+		option.Default = slices.Contains(selected, option.Value)
+		marked[index] = option
+	}
+	return marked
+}
+
+// respondSettings responds to an interaction with a settings panel, as a new message or by
+// updating the panel in place.
+func respondSettings(s *discordgo.Session, i *discordgo.InteractionCreate, responseType discordgo.InteractionResponseType, data *discordgo.InteractionResponseData) {
+	operation := func() error {
+		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: responseType, Data: data})
+	}
+	if err := withRetry(operation, DefaultRetryConfig()); err != nil {
+		logger().Errorf("Failed to send settings panel: %v", err)
+	}
+}
@@ -0,0 +1,254 @@
+package discord
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// settingsInteraction returns an edit of a settings panel component by a user.
+func settingsInteraction(customID, userID string, values ...string) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			ID:        "interaction-2",
+			AppID:     "app-1",
+			Token:     "component-token",
+			Type:      discordgo.InteractionMessageComponent,
+			GuildID:   "guild-1",
+			ChannelID: "channel-a",
+			Member:    &discordgo.Member{User: &discordgo.User{ID: userID}},
+			Data:      discordgo.MessageComponentInteractionData{CustomID: customID, Values: values},
+		},
+	}
+}
+
+// settingsResponse is the part of a settings panel response the tests check.
+type settingsResponse struct {
+	Type discordgo.InteractionResponseType `json:"type"`
+	Data struct {
+		Content    string                    `json:"content"`
+		Embeds     []*discordgo.MessageEmbed `json:"embeds"`
+		Components []struct {
+			Components []struct {
+				CustomID string `json:"custom_id"`
+			} `json:"components"`
+		} `json:"components"`
+	} `json:"data"`
+}
+
+// setupSettingsTest returns a bot with channel-a registered, whose server is owned by owner-1.
+func setupSettingsTest(t *testing.T) (*types.Bot, *testhelpers.FakeDiscord) {
+	t.Helper()
+	bot := testhelpers.CreateTestBot(t)
+	t.Cleanup(func() { bot.DB.Close() })
+	fake := testhelpers.NewFakeDiscord(t)
+	bot.Session = fake.Session()
+	fake.Handle("GET", "/guilds/guild-1", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "guild-1", "owner_id": "owner-1"})
+	})
+	if err := database.AddChannel(bot, "channel-a"); err != nil {
+		t.Fatalf("Failed to add channel: %v", err)
+	}
+	return bot, fake
+}
+
+// lastSettingsResponse decodes the last response to a settings panel component.
+func lastSettingsResponse(t *testing.T, fake *testhelpers.FakeDiscord) settingsResponse {
+	t.Helper()
+	calls := fake.RequestsTo("POST", "/interactions/interaction-2/component-token/callback")
+	if len(calls) == 0 {
+		t.Fatal("Expected a response")
+	}
+	var response settingsResponse
+	if err := json.Unmarshal(calls[len(calls)-1].Body, &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return response
+}
+
+func TestParseSettingsID(t *testing.T) {
+	tests := []struct {
+		customID  string
+		setting   string
+		channelID string
+		ok        bool
+	}{
+		{settingsID(settingTags, "channel-a"), settingTags, "channel-a", true},
+		{"stobot_settings:excluded_tags:123", settingExcludedTags, "123", true},
+		{"stobot_settings:webhook:123", "", "", false},
+		{"stobot_settings:tags:", "", "", false},
+		{"stobot_settings:tags:123:extra", "", "", false},
+		{"search_page:tags:123", "", "", false},
+	}
+	for _, tt := range tests {
+		setting, channelID, ok := parseSettingsID(tt.customID)
+		if setting != tt.setting || channelID != tt.channelID || ok != tt.ok {
+			t.Errorf("parseSettingsID(%q) = %q, %q, %v; want %q, %q, %v", tt.customID, setting, channelID, ok, tt.setting, tt.channelID, tt.ok)
+		}
+	}
+}
+
+func TestSettingsCommand(t *testing.T) {
+	bot, fake := setupSettingsTest(t)
+	lastResponse := func() string {
+		t.Helper()
+		calls := fake.RequestsTo("POST", "/interactions/interaction-1/interaction-token/callback")
+		if len(calls) == 0 {
+			t.Fatal("Expected a response")
+		}
+		return string(calls[len(calls)-1].Body)
+	}
+
+	member := discoveryInteraction("stobot_settings")
+	member.Member = &discordgo.Member{User: &discordgo.User{ID: "user-1"}}
+	HandleCommand(bot, bot.Session, member)
+	if response := lastResponse(); !strings.Contains(response, "Administrator permission") {
+		t.Errorf("Expected members to be refused, got %s", response)
+	}
+
+	admin := discoveryInteraction("stobot_settings")
+	admin.Member = &discordgo.Member{User: &discordgo.User{ID: "owner-1"}}
+	HandleCommand(bot, bot.Session, admin)
+	usageWriters.Wait()
+	calls := fake.RequestsTo("POST", "/interactions/interaction-1/interaction-token/callback")
+	var response settingsResponse
+	if err := json.Unmarshal(calls[len(calls)-1].Body, &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Type != discordgo.InteractionResponseChannelMessageWithSource || len(response.Data.Embeds) != 1 {
+		t.Fatalf("Expected the settings panel, got %s", calls[len(calls)-1].Body)
+	}
+	fields := make(map[string]string)
+	for _, field := range response.Data.Embeds[0].Fields {
+		fields[field.Name] = field.Value
+	}
+	if fields["Platforms"] != "pc, xbox, ps" || fields["Environment"] != "PROD" || fields["Tags"] != "all" ||
+		fields["Excluded Tags"] != "none" || fields["Posting"] != "Active" || fields["Ping Role"] != "none" {
+		t.Errorf("Unexpected settings summary: %v", fields)
+	}
+
+	var customIDs []string
+	for _, row := range response.Data.Components {
+		for _, component := range row.Components {
+			customIDs = append(customIDs, component.CustomID)
+		}
+	}
+	for _, setting := range []string{settingPlatforms, settingTags, settingExcludedTags, settingPingRole, settingPause, settingPauseHold, settingEnvironment} {
+		if !slices.Contains(customIDs, settingsID(setting, "channel-a")) {
+			t.Errorf("Expected a component editing %s, got %v", setting, customIDs)
+		}
+	}
+	if len(response.Data.Components) > 5 {
+		t.Errorf("Expected at most 5 rows of components, got %d", len(response.Data.Components))
+	}
+}
+
+func TestSettingsComponents(t *testing.T) {
+	bot, fake := setupSettingsTest(t)
+	channelConfig := func() *database.ChannelConfig {
+		t.Helper()
+		cfg, err := database.GetChannelConfig(bot, "channel-a")
+		if err != nil {
+			t.Fatalf("Failed to get channel config: %v", err)
+		}
+		return cfg
+	}
+	edit := func(interaction *discordgo.InteractionCreate) settingsResponse {
+		t.Helper()
+		InteractionCreate(bot)(bot.Session, interaction)
+		return lastSettingsResponse(t, fake)
+	}
+	expectUpdate := func(response settingsResponse, notice string) {
+		t.Helper()
+		if response.Type != discordgo.InteractionResponseUpdateMessage || !strings.Contains(response.Data.Content, notice) {
+			t.Errorf("Expected the panel updated with %q, got %+v", notice, response)
+		}
+	}
+
+	response := edit(settingsInteraction(settingsID(settingPlatforms, "channel-a"), "owner-1", "xbox", "ps"))
+	expectUpdate(response, "Platforms set to xbox, ps")
+	if cfg := channelConfig(); !slices.Equal(cfg.Platforms, []string{"xbox", "ps"}) {
+		t.Errorf("Expected platforms xbox and ps, got %v", cfg.Platforms)
+	}
+	if response := edit(settingsInteraction(settingsID(settingPlatforms, "channel-a"), "owner-1")); !strings.Contains(response.Data.Content, "at least one") {
+		t.Errorf("Expected a channel without platforms to be refused, got %+v", response)
+	}
+
+	expectUpdate(edit(settingsInteraction(settingsID(settingTags, "channel-a"), "owner-1", "patch-notes", "events")), "patch-notes, events")
+	expectUpdate(edit(settingsInteraction(settingsID(settingExcludedTags, "channel-a"), "owner-1", "dev-blogs")), "dev-blogs")
+	if cfg := channelConfig(); !slices.Equal(cfg.Tags, []string{"patch-notes", "events"}) || !slices.Equal(cfg.ExcludedTags, []string{"dev-blogs"}) {
+		t.Errorf("Unexpected tags %v and excluded tags %v", cfg.Tags, cfg.ExcludedTags)
+	}
+
+	// Only roles Discord resolved are accepted
+	if response := edit(settingsInteraction(settingsID(settingPingRole, "channel-a"), "owner-1", "role-9")); !strings.Contains(response.Data.Content, "Choose a role") {
+		t.Errorf("Expected an unresolved role to be refused, got %+v", response)
+	}
+	roleEdit := settingsInteraction(settingsID(settingPingRole, "channel-a"), "owner-1", "role-1")
+	roleEdit.Data = discordgo.MessageComponentInteractionData{
+		CustomID: settingsID(settingPingRole, "channel-a"),
+		Values:   []string{"role-1"},
+		Resolved: discordgo.MessageComponentInteractionDataResolved{Roles: map[string]*discordgo.Role{"role-1": {ID: "role-1"}}},
+	}
+	expectUpdate(edit(roleEdit), "<@&role-1>")
+	if cfg := channelConfig(); cfg.PingRole != "role-1" {
+		t.Errorf("Expected ping role role-1, got %q", cfg.PingRole)
+	}
+	expectUpdate(edit(settingsInteraction(settingsID(settingClearPing, "channel-a"), "owner-1")), "Ping role set to none")
+
+	expectUpdate(edit(settingsInteraction(settingsID(settingPauseHold, "channel-a"), "owner-1")), "paused")
+	if cfg := channelConfig(); !cfg.Paused || !cfg.PauseHold {
+		t.Errorf("Expected the channel paused in hold mode, got %+v", cfg)
+	}
+	response = edit(settingsInteraction(settingsID(settingResume, "channel-a"), "owner-1"))
+	expectUpdate(response, "resumed")
+	if cfg := channelConfig(); cfg.Paused {
+		t.Error("Expected the channel to be resumed")
+	}
+	if fields := response.Data.Embeds[0].Fields; fields[2].Value != "Active" {
+		t.Errorf("Expected the refreshed panel to show posting active, got %q", fields[2].Value)
+	}
+
+	expectUpdate(edit(settingsInteraction(settingsID(settingEnvironment, "channel-a"), "owner-1")), "Environment set to DEV")
+	if cfg := channelConfig(); cfg.Environment != "DEV" {
+		t.Errorf("Expected the DEV environment, got %s", cfg.Environment)
+	}
+}
+
+func TestSettingsComponentsRefused(t *testing.T) {
+	tests := []struct {
+		name        string
+		interaction *discordgo.InteractionCreate
+		expected    string
+	}{
+		{"member", settingsInteraction(settingsID(settingTags, "channel-a"), "user-1", "events"), "Administrator permission"},
+		{"panel of another channel", settingsInteraction(settingsID(settingTags, "channel-b"), "owner-1", "events"), "another channel"},
+		{"unknown setting", settingsInteraction("stobot_settings:webhook:channel-a", "owner-1", "https://example.com"), "out of date"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot, fake := setupSettingsTest(t)
+			InteractionCreate(bot)(bot.Session, tt.interaction)
+
+			response := lastSettingsResponse(t, fake)
+			if response.Type != discordgo.InteractionResponseChannelMessageWithSource || !strings.Contains(response.Data.Content, tt.expected) {
+				t.Errorf("Expected an error containing %q, got %+v", tt.expected, response)
+			}
+			cfg, err := database.GetChannelConfig(bot, "channel-a")
+			if err != nil {
+				t.Fatalf("Failed to get channel config: %v", err)
+			}
+			if len(cfg.Tags) != 0 {
+				t.Errorf("Expected the tags unchanged, got %v", cfg.Tags)
+			}
+		})
+	}
+}
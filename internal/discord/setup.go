@@ -0,0 +1,315 @@
+package discord
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/news"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Setup check outcomes.
+const (
+	setupPassed  = "✅"
+	setupWarning = "⚠️"
+	setupFailed  = "❌"
+)
+
+// requiredBotPermissions are the channel permissions the bot needs to post news embeds.
+var requiredBotPermissions = []struct {
+	permission int64
+	name       string
+}{
+	{discordgo.PermissionViewChannel, "View Channel"},
+	{discordgo.PermissionSendMessages, "Send Messages"},
+	{discordgo.PermissionEmbedLinks, "Embed Links"},
+}
+
+// stalePollCycles is the number of poll periods after which the last poll cycle is reported as stale.
+const stalePollCycles = 3
+
+// setupTestPostContent is the message sent and deleted by the optional test post.
+const setupTestPostContent = "🧪 STOBot setup test post. This message will be deleted."
+
+// lastPollCycle returns when the bot last completed a poll cycle (replaced in tests).
+var lastPollCycle = news.LastPollCycle
+
+// setupCheck is one item of the /stobot_setup checklist.
+type setupCheck struct {
+	Name   string // Name is the checklist item.
+	Status string // Status is setupPassed, setupWarning or setupFailed.
+	Detail string // Detail describes what was found.
+	Fix    string // Fix is the follow-up to resolve a warning or failure.
+}
+
+// handleSetup handles the "setup" command interaction
+func handleSetup(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Validate inputs
+	if i == nil || i.Interaction == nil {
+		logger().Warning("handleSetup called with nil interaction")
+		return
+	}
+
+	// Check if user can manage channels
+	if !hasMemberPermission(i, discordgo.PermissionManageChannels) {
+		RespondError(s, i, "You need the Manage Channels permission to use this command.")
+		return
+	}
+
+	testPost := false
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "test_post" {
+			testPost = option.BoolValue()
+		}
+	}
+
+	// Acknowledge interaction with timeout handling
+	if err := AcknowledgeWithRetry(s, i); err != nil {
+		logger().Errorf("Failed to acknowledge setup command: %v", err)
+		return
+	}
+
+	checks := runSetupChecks(b, s, i, testPost)
+	Followup(s, i, formatSetupChecklist(checks))
+}
+
+// runSetupChecks runs the setup checklist for the channel the interaction was sent in.
+// Channel configuration checks are only run when the channel is registered.
+func runSetupChecks(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate, testPost bool) []setupCheck {
+	var checks []setupCheck
+
+	cfg, err := database.GetChannelConfig(b, i.ChannelID)
+	switch {
+	case err != nil:
+		channelLogger(i.ChannelID).Errorf("Failed to get channel config for %s: %v", i.ChannelID, err)
+		checks = append(checks, setupCheck{Name: "Channel registered", Status: setupFailed,
+			Detail: "Could not read the channel configuration.", Fix: "Try again later; if this persists, check the bot logs."})
+	case cfg == nil:
+		checks = append(checks, setupCheck{Name: "Channel registered", Status: setupFailed,
+			Detail: "This channel is not registered for news.", Fix: "`/stobot_register`"})
+	default:
+		checks = append(checks, setupCheck{Name: "Channel registered", Status: setupPassed,
+			Detail: "This channel is registered for news."})
+	}
+
+	checks = append(checks, checkBotPermissions(i.AppPermissions, cfg))
+
+	if cfg != nil {
+		checks = append(checks,
+			checkPlatforms(cfg.Platforms),
+			checkTags(cfg.Tags, cfg.ExcludedTags),
+			checkEnvironment(cfg.Environment, b.Config.Environment),
+		)
+	}
+
+	checks = append(checks, checkTestPost(s, i.ChannelID, testPost))
+	checks = append(checks, checkLastPollCycle(lastPollCycle(), b.Config.PollPeriod, time.Now()))
+
+	return checks
+}
+
+// checkBotPermissions checks the bot's permissions in the channel, as sent with the interaction.
+// Manage Messages is only expected when auto-publish is enabled, Create Public Threads when
+// discussion threads are.
+func checkBotPermissions(appPermissions int64, cfg *database.ChannelConfig) setupCheck {
+	check := setupCheck{Name: "Bot permissions"}
+	if appPermissions == 0 {
+		check.Status = setupWarning
+		check.Detail = "Discord did not report the bot's permissions in this channel."
+		check.Fix = "Run `/stobot_setup test_post:True` to check posting directly."
+		return check
+	}
+
+	var missing []string
+	for _, required := range requiredBotPermissions {
+		if appPermissions&(required.permission|discordgo.PermissionAdministrator) == 0 {
+			missing = append(missing, required.name)
+		}
+	}
+	if len(missing) > 0 {
+		check.Status = setupFailed
+		check.Detail = fmt.Sprintf("The bot is missing %s.", strings.Join(missing, ", "))
+		check.Fix = fmt.Sprintf("Grant the bot %s in this channel's permission settings.", strings.Join(missing, ", "))
+		return check
+	}
+
+	if cfg != nil && cfg.AutoPublish && appPermissions&(discordgo.PermissionManageMessages|discordgo.PermissionAdministrator) == 0 {
+		check.Status = setupWarning
+		check.Detail = "The bot can post, but auto-publish needs Manage Messages."
+		check.Fix = "Grant the bot Manage Messages in this channel, or run `/stobot_auto_publish enabled:False`."
+		return check
+	}
+
+	if cfg != nil && cfg.CreateThreads && appPermissions&(discordgo.PermissionCreatePublicThreads|discordgo.PermissionAdministrator) == 0 {
+		check.Status = setupWarning
+		check.Detail = "The bot can post, but discussion threads need Create Public Threads."
+		check.Fix = "Grant the bot Create Public Threads in this channel, or run `/stobot_set_threads enabled:False`."
+		return check
+	}
+
+	if cfg != nil && cfg.CreateEvents && appPermissions&(discordgo.PermissionManageEvents|discordgo.PermissionAdministrator) == 0 {
+		check.Status = setupWarning
+		check.Detail = "The bot can post, but scheduled events need Manage Events."
+		check.Fix = "Grant the bot Manage Events in this server, or run `/stobot_set_events enabled:False`."
+		return check
+	}
+
+	check.Status = setupPassed
+	check.Detail = "The bot can send messages and embeds here."
+	return check
+}
+
+// checkPlatforms checks that the channel subscribes to known platforms only.
+func checkPlatforms(platforms []string) setupCheck {
+	check := setupCheck{Name: "Platforms"}
+	if len(platforms) == 0 {
+		check.Status = setupFailed
+		check.Detail = "No platforms are selected, so nothing will be posted."
+		check.Fix = "`/stobot_register platforms:pc,xbox,ps`"
+		return check
+	}
+
+	var unknown []string
+	for _, platform := range platforms {
+		if !containsString(types.Platforms, platform) {
+			unknown = append(unknown, platform)
+		}
+	}
+	if len(unknown) > 0 {
+		check.Status = setupWarning
+		check.Detail = fmt.Sprintf("Unknown platforms %s will never match any news.", strings.Join(unknown, ", "))
+		check.Fix = fmt.Sprintf("`/stobot_register platforms:%s`", strings.Join(types.Platforms, ","))
+		return check
+	}
+
+	check.Status = setupPassed
+	check.Detail = strings.Join(platforms, ", ")
+	return check
+}
+
+// checkTags reports the news tags posted to the channel.
+func checkTags(tags, excludedTags []string) setupCheck {
+	check := setupCheck{Name: "News tags", Status: setupPassed, Detail: fmt.Sprintf("Posting %s tags.", formatChannelTags(tags))}
+	if len(excludedTags) > 0 {
+		check.Detail = fmt.Sprintf("Posting %s tags, except %s.", formatChannelTags(tags), strings.Join(excludedTags, ", "))
+	}
+	return check
+}
+
+// checkEnvironment checks that the channel is served by this bot instance. An instance
+// without an environment serves all channels.
+func checkEnvironment(channelEnvironment, botEnvironment string) setupCheck {
+	check := setupCheck{Name: "Environment"}
+	if botEnvironment == "" || strings.EqualFold(channelEnvironment, botEnvironment) {
+		check.Status = setupPassed
+		check.Detail = fmt.Sprintf("This channel is served by the %s bot instance.", displayEnvironment(channelEnvironment))
+		return check
+	}
+
+	check.Status = setupFailed
+	check.Detail = fmt.Sprintf("This channel is registered for %s but this bot instance serves %s, so nothing will be posted here.",
+		displayEnvironment(channelEnvironment), botEnvironment)
+	check.Fix = fmt.Sprintf("Ask the bot operator to move this channel to the %s environment.", botEnvironment)
+	return check
+}
+
+// displayEnvironment returns a channel environment for display.
+func displayEnvironment(environment string) string {
+	if environment == "" {
+		return "default"
+	}
+	return environment
+}
+
+// checkTestPost sends and deletes a test message in the channel when requested.
+func checkTestPost(s *discordgo.Session, channelID string, testPost bool) setupCheck {
+	check := setupCheck{Name: "Test post"}
+	if !testPost {
+		check.Status = setupWarning
+		check.Detail = "Skipped."
+		check.Fix = "`/stobot_setup test_post:True` to send and delete a test message."
+		return check
+	}
+
+	msg, err := s.ChannelMessageSend(channelID, setupTestPostContent)
+	if err != nil {
+		channelLogger(channelID).Warnf("Setup test post failed in channel %s: %v", channelID, err)
+		check.Status = setupFailed
+		check.Detail = fmt.Sprintf("The bot could not post here: %v", err)
+		check.Fix = "Grant the bot View Channel, Send Messages and Embed Links in this channel's permission settings."
+		return check
+	}
+
+	if err := s.ChannelMessageDelete(channelID, msg.ID); err != nil {
+		channelLogger(channelID).Warnf("Failed to delete setup test post %s in channel %s: %v", msg.ID, channelID, err)
+		check.Status = setupWarning
+		check.Detail = "The test message was posted but could not be deleted."
+		check.Fix = "Delete the test message manually."
+		return check
+	}
+
+	check.Status = setupPassed
+	check.Detail = "A test message was posted and deleted."
+	return check
+}
+
+// checkLastPollCycle checks that the bot has recently completed a poll cycle.
+func checkLastPollCycle(last time.Time, pollPeriod int, now time.Time) setupCheck {
+	check := setupCheck{Name: "News polling"}
+	if last.IsZero() {
+		check.Status = setupWarning
+		check.Detail = "No poll cycle has completed since the bot started."
+		check.Fix = "Wait for the next poll cycle; if this persists, check the bot logs for fetch errors."
+		return check
+	}
+
+	age := now.Sub(last).Round(time.Second)
+	if pollPeriod > 0 && age > time.Duration(stalePollCycles*pollPeriod)*time.Second {
+		check.Status = setupWarning
+		check.Detail = fmt.Sprintf("The last poll cycle completed %s ago.", age)
+		check.Fix = "Check the bot logs for fetch errors."
+		return check
+	}
+
+	check.Status = setupPassed
+	check.Detail = fmt.Sprintf("The last poll cycle completed %s ago.", age)
+	return check
+}
+
+// formatSetupChecklist renders the checklist with a fix for each warning or failure.
+func formatSetupChecklist(checks []setupCheck) string {
+	var msg strings.Builder
+	msg.WriteString("🛠️ **STOBot Setup Checklist**\n\n")
+
+	issues := 0
+	for _, check := range checks {
+		msg.WriteString(fmt.Sprintf("%s **%s**: %s\n", check.Status, check.Name, check.Detail))
+		if check.Status != setupPassed {
+			issues++
+			if check.Fix != "" {
+				msg.WriteString(fmt.Sprintf("   ↳ Fix: %s\n", check.Fix))
+			}
+		}
+	}
+
+	if issues == 0 {
+		msg.WriteString("\n🎉 Everything is set up. News will be posted here.")
+	} else {
+		msg.WriteString(fmt.Sprintf("\n%d item(s) need attention.", issues))
+	}
+	return msg.String()
+}
+
+// containsString reports whether values contains value (case-insensitive).
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,264 @@
+package discord
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// allBotPermissions are the channel permissions of a correctly set up bot.
+const allBotPermissions = discordgo.PermissionViewChannel | discordgo.PermissionSendMessages | discordgo.PermissionEmbedLinks
+
+// setupInteraction builds a /stobot_setup interaction in channel-a from a member with Manage Channels.
+func setupInteraction(appPermissions int64, testPost bool) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			ID:             "interaction-1",
+			AppID:          "app-1",
+			Token:          "interaction-token",
+			Type:           discordgo.InteractionApplicationCommand,
+			GuildID:        "guild-1",
+			ChannelID:      "channel-a",
+			AppPermissions: appPermissions,
+			Member: &discordgo.Member{
+				User:        &discordgo.User{ID: "user-1"},
+				Permissions: discordgo.PermissionManageChannels,
+			},
+			Data: discordgo.ApplicationCommandInteractionData{
+				Name: "stobot_setup",
+				Options: []*discordgo.ApplicationCommandInteractionDataOption{
+					{Name: "test_post", Type: discordgo.ApplicationCommandOptionBoolean, Value: testPost},
+				},
+			},
+		},
+	}
+}
+
+// setLastPollCycle replaces the last poll cycle time for the duration of a test.
+func setLastPollCycle(t *testing.T, last time.Time) {
+	t.Helper()
+	original := lastPollCycle
+	lastPollCycle = func() time.Time { return last }
+	t.Cleanup(func() { lastPollCycle = original })
+}
+
+// setupChecklistFollowup returns the content of the checklist followup.
+func setupChecklistFollowup(t *testing.T, fake *testhelpers.FakeDiscord) string {
+	t.Helper()
+
+	followups := fake.RequestsTo("POST", "/webhooks/app-1/interaction-token")
+	if len(followups) != 1 {
+		t.Fatalf("Expected 1 followup, got %d", len(followups))
+	}
+	var params discordgo.WebhookParams
+	if err := json.Unmarshal(followups[0].Body, &params); err != nil {
+		t.Fatalf("Failed to decode followup: %v", err)
+	}
+	return params.Content
+}
+
+func TestHandleSetup(t *testing.T) {
+	tests := []struct {
+		name           string
+		register       bool
+		environment    string
+		platforms      string
+		createThreads  bool
+		createEvents   bool
+		appPermissions int64
+		testPost       bool
+		postStatus     int
+		lastPoll       time.Duration
+		expected       []string
+		unexpected     []string
+	}{
+		{
+			name:           "all checks pass",
+			register:       true,
+			environment:    "PROD",
+			platforms:      "pc,xbox",
+			appPermissions: allBotPermissions,
+			testPost:       true,
+			postStatus:     http.StatusOK,
+			lastPoll:       time.Minute,
+			expected:       []string{"✅ **Channel registered**", "✅ **Bot permissions**", "✅ **Platforms**: pc, xbox", "✅ **Environment**", "✅ **Test post**", "✅ **News polling**", "Everything is set up"},
+			unexpected:     []string{"❌", "⚠️"},
+		},
+		{
+			name:           "channel not registered",
+			appPermissions: allBotPermissions,
+			lastPoll:       time.Minute,
+			expected:       []string{"❌ **Channel registered**", "Fix: `/stobot_register`", "⚠️ **Test post**"},
+			unexpected:     []string{"**Platforms**", "**Environment**"},
+		},
+		{
+			name:           "missing embed permission",
+			register:       true,
+			environment:    "PROD",
+			platforms:      "pc",
+			appPermissions: discordgo.PermissionViewChannel | discordgo.PermissionSendMessages,
+			lastPoll:       time.Minute,
+			expected:       []string{"❌ **Bot permissions**: The bot is missing Embed Links.", "Fix: Grant the bot Embed Links"},
+		},
+		{
+			name:           "threads without thread permission",
+			register:       true,
+			environment:    "PROD",
+			platforms:      "pc",
+			createThreads:  true,
+			appPermissions: allBotPermissions,
+			lastPoll:       time.Minute,
+			expected:       []string{"⚠️ **Bot permissions**: The bot can post, but discussion threads need Create Public Threads.", "Fix: Grant the bot Create Public Threads"},
+		},
+		{
+			name:           "events without event permission",
+			register:       true,
+			environment:    "PROD",
+			platforms:      "pc",
+			createEvents:   true,
+			appPermissions: allBotPermissions,
+			lastPoll:       time.Minute,
+			expected:       []string{"⚠️ **Bot permissions**: The bot can post, but scheduled events need Manage Events.", "Fix: Grant the bot Manage Events"},
+		},
+		{
+			name:           "environment mismatch",
+			register:       true,
+			environment:    "DEV",
+			platforms:      "pc",
+			appPermissions: allBotPermissions,
+			lastPoll:       time.Minute,
+			expected:       []string{"❌ **Environment**: This channel is registered for DEV but this bot instance serves PROD", "Fix: Ask the bot operator to move this channel to the PROD environment."},
+		},
+		{
+			name:           "unknown platform",
+			register:       true,
+			environment:    "PROD",
+			platforms:      "pc,switch",
+			appPermissions: allBotPermissions,
+			lastPoll:       time.Minute,
+			expected:       []string{"⚠️ **Platforms**: Unknown platforms switch", "Fix: `/stobot_register platforms:pc,xbox,ps`"},
+		},
+		{
+			name:           "test post forbidden",
+			register:       true,
+			environment:    "PROD",
+			platforms:      "pc",
+			appPermissions: allBotPermissions,
+			testPost:       true,
+			postStatus:     http.StatusForbidden,
+			lastPoll:       time.Minute,
+			expected:       []string{"❌ **Test post**: The bot could not post here"},
+		},
+		{
+			name:           "stale poll cycle",
+			register:       true,
+			environment:    "PROD",
+			platforms:      "pc",
+			appPermissions: allBotPermissions,
+			lastPoll:       time.Hour,
+			expected:       []string{"⚠️ **News polling**: The last poll cycle completed 1h0m0s ago.", "Fix: Check the bot logs"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := database.InitDatabase(filepath.Join(t.TempDir(), "stobot.db"))
+			if err != nil {
+				t.Fatalf("Failed to initialize database: %v", err)
+			}
+			defer db.Close()
+
+			fake := testhelpers.NewFakeDiscord(t)
+			bot := &types.Bot{Session: fake.Session(), DB: db, Config: &types.Config{Environment: "PROD", PollPeriod: 600}}
+			if tt.register {
+				if _, err := db.Exec("INSERT INTO channels (id, platforms, environment, create_threads, create_events) VALUES ('channel-a', ?, ?, ?, ?)", tt.platforms, tt.environment, tt.createThreads, tt.createEvents); err != nil {
+					t.Fatalf("Failed to add channel: %v", err)
+				}
+			}
+			setLastPollCycle(t, time.Now().Add(-tt.lastPoll))
+
+			fake.Handle("POST", "/channels/channel-a/messages", func(w http.ResponseWriter, r *http.Request) {
+				if tt.postStatus != http.StatusOK {
+					testhelpers.RespondJSON(w, tt.postStatus, map[string]interface{}{"code": 50013, "message": "Missing Permissions"})
+					return
+				}
+				testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "test-msg", "channel_id": "channel-a"})
+			})
+
+			handleSetup(bot, bot.Session, setupInteraction(tt.appPermissions, tt.testPost))
+
+			content := setupChecklistFollowup(t, fake)
+			for _, expected := range tt.expected {
+				if !strings.Contains(content, expected) {
+					t.Errorf("Expected checklist to contain %q, got:\n%s", expected, content)
+				}
+			}
+			for _, unexpected := range tt.unexpected {
+				if strings.Contains(content, unexpected) {
+					t.Errorf("Expected checklist not to contain %q, got:\n%s", unexpected, content)
+				}
+			}
+
+			posts := fake.RequestsTo("POST", "/channels/channel-a/messages")
+			if !tt.testPost && len(posts) != 0 {
+				t.Errorf("Expected no test post, got %d", len(posts))
+			}
+			if tt.testPost && tt.postStatus == http.StatusOK {
+				if deletes := fake.RequestsTo("DELETE", "/channels/channel-a/messages/test-msg"); len(deletes) != 1 {
+					t.Errorf("Expected the test post to be deleted, got %d deletes", len(deletes))
+				}
+			}
+		})
+	}
+}
+
+func TestHandleSetupRequiresManageChannels(t *testing.T) {
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	fake := testhelpers.NewFakeDiscord(t)
+	bot.Session = fake.Session()
+
+	i := setupInteraction(allBotPermissions, true)
+	i.Member.Permissions = discordgo.PermissionSendMessages
+	handleSetup(bot, bot.Session, i)
+
+	if calls := fake.RequestsTo("POST", "/interactions/interaction-1/interaction-token/callback"); len(calls) != 1 {
+		t.Fatalf("Expected 1 error response, got %d", len(calls))
+	}
+	if calls := fake.RequestsTo("POST", "/channels/channel-a/messages"); len(calls) != 0 {
+		t.Errorf("Expected no test post without permission, got %d", len(calls))
+	}
+}
+
+func TestCheckLastPollCycle(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		last     time.Time
+		expected string
+	}{
+		{"never polled", time.Time{}, setupWarning},
+		{"recent", now.Add(-5 * time.Minute), setupPassed},
+		{"at the stale threshold", now.Add(-30 * time.Minute), setupPassed},
+		{"stale", now.Add(-31 * time.Minute), setupWarning},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := checkLastPollCycle(tt.last, 600, now)
+			if check.Status != tt.expected {
+				t.Errorf("Expected status %s, got %s (%s)", tt.expected, check.Status, check.Detail)
+			}
+		})
+	}
+}
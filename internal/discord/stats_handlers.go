@@ -2,40 +2,47 @@ package discord
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/news"
 	"github.com/FracKenA/sto_news_discord_bot/internal/types"
 
 	"github.com/bwmarrin/discordgo"
-	log "github.com/sirupsen/logrus"
 )
 
+// The engagement report lists the maxTopCommands most used slash commands of the last
+// commandUsageDays days.
+const (
+	commandUsageDays = 30
+	maxTopCommands   = 10
+)
+
+// maxStatsTags is the number of tags listed by /stobot_news_stats.
+const maxStatsTags = 8
+
+// pollStats returns how the bot's poll cycles have been running (replaced in tests).
+var pollStats = news.GetPollStats
+
 // handleNewsStats handles the "news_stats" command interaction
 func handleNewsStats(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
 	// Acknowledge interaction with timeout handling
 	if err := AcknowledgeWithRetry(s, i); err != nil {
-		log.Errorf("Failed to acknowledge news_stats command: %v", err)
+		logger().Errorf("Failed to acknowledge news_stats command: %v", err)
 		return
 	}
 
 	// Get database statistics
-	log.Info("Getting database statistics")
+	logger().Info("Getting database statistics")
 	stats, err := database.GetDatabaseStats(b)
 	if err != nil {
-		log.Errorf("Failed to get database stats: %v", err)
+		logger().Errorf("Failed to get database stats: %v", err)
 		Followup(s, i, "❌ Failed to get database statistics. Please try again later.")
 		return
 	}
 
-	// Get popular tags
-	popularTags, err := database.GetPopularTags(b, 10)
-	if err != nil {
-		log.Errorf("Failed to get popular tags: %v", err)
-		// Continue without popular tags
-	}
-
 	// Create embed
 	embed := &discordgo.MessageEmbed{
 		Title:       "📊 Database Statistics",
@@ -44,42 +51,25 @@ func handleNewsStats(b *types.Bot, s *discordgo.Session, i *discordgo.Interactio
 		Timestamp:   time.Now().Format("2006-01-02T15:04:05Z"),
 	}
 
-	// Add statistics fields
-	totalNews := stats["total_news"].(int)
-	totalChannels := stats["total_channels"].(int)
-	oldestStr := stats["oldest_article"].(string)
-	newestStr := stats["newest_article"].(string)
-
-	// Parse dates with null handling
-	var dateRangeValue string
-	if oldestStr != "" && newestStr != "" {
-		// SQLite stores dates with timezone, so use the correct format
-		oldest, err := time.Parse("2006-01-02 15:04:05-07:00", oldestStr)
-		if err != nil {
-			log.Errorf("Failed to parse oldest date '%s': %v", oldestStr, err)
-			dateRangeValue = "Invalid date format"
-		} else {
-			newest, err := time.Parse("2006-01-02 15:04:05-07:00", newestStr)
-			if err != nil {
-				log.Errorf("Failed to parse newest date '%s': %v", newestStr, err)
-				dateRangeValue = "Invalid date format"
-			} else {
-				dateRangeValue = fmt.Sprintf("%s to %s", oldest.Format("2006-01-02"), newest.Format("2006-01-02"))
-			}
-		}
-	} else {
-		dateRangeValue = "No news articles in database"
+	dateRangeValue := "No news articles in database"
+	if !stats.OldestArticle.IsZero() {
+		dateRangeValue = fmt.Sprintf("%s to %s", stats.OldestArticle.Format("2006-01-02"), stats.NewestArticle.Format("2006-01-02"))
 	}
 
 	embed.Fields = []*discordgo.MessageEmbedField{
 		{
 			Name:   "📰 Total News Articles",
-			Value:  fmt.Sprintf("%d", totalNews),
+			Value:  fmt.Sprintf("%d", stats.TotalNews),
 			Inline: true,
 		},
 		{
 			Name:   "📺 Registered Channels",
-			Value:  fmt.Sprintf("%d", totalChannels),
+			Value:  fmt.Sprintf("%d", stats.TotalChannels),
+			Inline: true,
+		},
+		{
+			Name:   "📤 Posts to Channels",
+			Value:  fmt.Sprintf("%d", stats.TotalPosted),
 			Inline: true,
 		},
 		{
@@ -89,39 +79,51 @@ func handleNewsStats(b *types.Bot, s *discordgo.Session, i *discordgo.Interactio
 		},
 	}
 
-	// Add popular tags if available
-	if len(popularTags) > 0 {
-		var tagsText strings.Builder
-		for i, tagData := range popularTags {
-			if i >= 8 { // Limit to top 8 for readability
-				break
-			}
-			tag := tagData["tag"].(string)
-			count := tagData["count"].(int)
-			tagsText.WriteString(fmt.Sprintf("• **%s** (%d)\n", tag, count))
-		}
-
+	if len(stats.CountsByTag) > 0 {
 		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-			Name:  "🔝 Most Popular Tags",
-			Value: tagsText.String(),
+			Name:   "🔝 Most Popular Tags",
+			Value:  formatCountBreakdown(stats.CountsByTag, maxStatsTags),
+			Inline: true,
 		})
 	}
+	if len(stats.CountsByPlatform) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "🎮 News by Platform",
+			Value:  formatCountBreakdown(stats.CountsByPlatform, len(stats.CountsByPlatform)),
+			Inline: true,
+		})
+	}
+
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+		Name:   "⏱️ Poll Cycles",
+		Value:  formatPollStats(pollStats()),
+		Inline: false,
+	})
 
 	// Send the result with enhanced error handling
 	if err := FollowupWithEmbeds(s, i, "", []*discordgo.MessageEmbed{embed}); err != nil {
-		log.Errorf("Failed to send database stats: %v", err)
+		logger().Errorf("Failed to send database stats: %v", err)
 		Followup(s, i, "❌ Failed to send database statistics.")
 		return
 	}
 
-	log.Infof("Sent database statistics: %d total news", totalNews)
+	logger().Infof("Sent database statistics: %d total news", stats.TotalNews)
+}
+
+// formatPollStats describes how the bot's poll cycles have been running.
+func formatPollStats(stats news.PollStats) string {
+	if stats.LastCycle.IsZero() {
+		return fmt.Sprintf("No cycle completed yet, %d skipped", stats.SkippedCycles)
+	}
+	return fmt.Sprintf("Last completed <t:%d:R>, took %v\n%d skipped while the previous cycle was still running",
+		stats.LastCycle.Unix(), stats.LastCycleDuration.Round(time.Millisecond), stats.SkippedCycles)
 }
 
 // handleServerStats handles the "server_stats" command interaction
 func handleServerStats(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
 	// Acknowledge interaction with timeout handling
 	if err := AcknowledgeWithRetry(s, i); err != nil {
-		log.Errorf("Failed to acknowledge server_stats command: %v", err)
+		logger().Errorf("Failed to acknowledge server_stats command: %v", err)
 		return
 	}
 
@@ -132,39 +134,18 @@ func handleServerStats(b *types.Bot, s *discordgo.Session, i *discordgo.Interact
 	}
 
 	// Get server engagement stats
-	log.Infof("Getting server engagement stats for guild: %s", guildID)
+	logger().Infof("Getting server engagement stats for guild: %s", guildID)
 
-	// Get all channels for this guild and aggregate stats
-	channels, err := database.GetRegisteredChannels(b)
+	stats, err := database.GetGuildEngagement(b, guildID)
 	if err != nil {
-		Followup(s, i, fmt.Sprintf("❌ Failed to get channels: %v", err))
+		logger().Errorf("Failed to get engagement for guild %s: %v", guildID, err)
+		Followup(s, i, "❌ Failed to get server statistics. Please try again later.")
 		return
 	}
 
-	totalPosts := 0
-	weeklyPosts := 0
-	activeChannels := 0
-
-	for _, channelID := range channels {
-		// Check if this channel belongs to this guild by trying to get channel info
-		channel, err := s.Channel(channelID)
-		if err != nil || channel.GuildID != guildID {
-			continue // Skip channels not in this guild
-		}
-
-		activeChannels++
-		channelStats, err := database.GetChannelEngagement(b, channelID)
-		if err != nil {
-			continue // Skip on error
-		}
-
-		if posts, ok := channelStats["total_posts"].(int); ok {
-			totalPosts += posts
-		}
-		if weekly, ok := channelStats["weekly_posts"].(int); ok {
-			weeklyPosts += weekly
-		}
-	}
+	totalPosts, _ := stats["total_posts"].(int)
+	weeklyPosts, _ := stats["weekly_posts"].(int)
+	activeChannels, _ := stats["channels"].(int)
 
 	if totalPosts == 0 {
 		Followup(s, i, "📊 No engagement data found for this server.")
@@ -200,27 +181,27 @@ func handleServerStats(b *types.Bot, s *discordgo.Session, i *discordgo.Interact
 
 	// Send the result with enhanced error handling
 	if err := FollowupWithEmbeds(s, i, "", []*discordgo.MessageEmbed{embed}); err != nil {
-		log.Errorf("Failed to send server stats: %v", err)
+		logger().Errorf("Failed to send server stats: %v", err)
 		Followup(s, i, "❌ Failed to send server statistics.")
 		return
 	}
 
-	log.Infof("Sent server stats for guild: %s", guildID)
+	logger().Infof("Sent server stats for guild: %s", guildID)
 }
 
 // handlePopularThisWeek handles the "popular_this_week" command interaction
 func handlePopularThisWeek(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
 	// Acknowledge interaction with timeout handling
 	if err := AcknowledgeWithRetry(s, i); err != nil {
-		log.Errorf("Failed to acknowledge popular_this_week command: %v", err)
+		logger().Errorf("Failed to acknowledge popular_this_week command: %v", err)
 		return
 	}
 
 	// Get popular articles this week
-	log.Info("Getting popular articles for this week")
+	logger().Info("Getting popular articles for this week")
 	popularNews, err := database.GetPopularNewsThisWeek(b, 10) // Get top 10
 	if err != nil {
-		log.Errorf("Failed to get popular news this week: %v", err)
+		logger().Errorf("Failed to get popular news this week: %v", err)
 		Followup(s, i, "❌ Failed to get popular articles. Please try again later.")
 		return
 	}
@@ -234,6 +215,7 @@ func handlePopularThisWeek(b *types.Bot, s *discordgo.Session, i *discordgo.Inte
 	var embeds []*discordgo.MessageEmbed
 	for i, newsItem := range popularNews {
 		embed := formatNewsEmbed(newsItem)
+		b.Config.RewriteEmbedURLs(embed)
 		embed.Title = fmt.Sprintf("⭐ #%d - %s", i+1, embed.Title)
 		embed.Color = 0xffd700 // Gold color for popular
 		embeds = append(embeds, embed)
@@ -242,19 +224,19 @@ func handlePopularThisWeek(b *types.Bot, s *discordgo.Session, i *discordgo.Inte
 	// Send results with enhanced error handling
 	content := fmt.Sprintf("⭐ **Most Popular Articles This Week** (%d found)", len(popularNews))
 	if err := FollowupWithEmbeds(s, i, content, embeds); err != nil {
-		log.Errorf("Failed to send popular articles: %v", err)
+		logger().Errorf("Failed to send popular articles: %v", err)
 		Followup(s, i, "❌ Failed to send popular articles.")
 		return
 	}
 
-	log.Infof("Sent %d popular articles for this week", len(popularNews))
+	logger().Infof("Sent %d popular articles for this week", len(popularNews))
 }
 
 // handleTagTrends handles the "tag_trends" command interaction
 func handleTagTrends(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
 	// Acknowledge interaction with timeout handling
 	if err := AcknowledgeWithRetry(s, i); err != nil {
-		log.Errorf("Failed to acknowledge tag_trends command: %v", err)
+		logger().Errorf("Failed to acknowledge tag_trends command: %v", err)
 		return
 	}
 
@@ -266,29 +248,13 @@ func handleTagTrends(b *types.Bot, s *discordgo.Session, i *discordgo.Interactio
 		}
 	}
 
-	// Map period to days
-	var days int
-	var periodName string
-	switch period {
-	case "week":
-		days = 7
-		periodName = "Last 7 Days"
-	case "month":
-		days = 30
-		periodName = "Last 30 Days"
-	case "quarter":
-		days = 90
-		periodName = "Last 90 Days"
-	default:
-		days = 7
-		periodName = "Last 7 Days"
-	}
+	days, periodName := trendPeriod(period)
 
 	// Get tag trends
-	log.Infof("Getting tag trends for %s (%d days)", periodName, days)
+	logger().Infof("Getting tag trends for %s (%d days)", periodName, days)
 	trendingTags, err := database.GetTrendingTags(b, days, 20) // Get top 20
 	if err != nil {
-		log.Errorf("Failed to get tag trends: %v", err)
+		logger().Errorf("Failed to get tag trends: %v", err)
 		Followup(s, i, "❌ Failed to get tag trends. Please try again later.")
 		return
 	}
@@ -308,32 +274,43 @@ func handleTagTrends(b *types.Bot, s *discordgo.Session, i *discordgo.Interactio
 
 	// Format trending tags
 	var trendsText strings.Builder
-	for i, tagData := range trendingTags {
+	for i, tagCount := range trendingTags {
 		if i >= 15 { // Limit to top 15 for readability
 			break
 		}
-		tag := tagData["tag"].(string)
-		count := tagData["count"].(int)
-		trendsText.WriteString(fmt.Sprintf("%d. **%s** (%d)\n", i+1, tag, count))
+		trendsText.WriteString(fmt.Sprintf("%d. **%s** (%d)\n", i+1, tagCount.Tag, tagCount.Count))
 	}
 
 	embed.Description = trendsText.String()
 
 	// Send the result with enhanced error handling
 	if err := FollowupWithEmbeds(s, i, "", []*discordgo.MessageEmbed{embed}); err != nil {
-		log.Errorf("Failed to send tag trends: %v", err)
+		logger().Errorf("Failed to send tag trends: %v", err)
 		Followup(s, i, "❌ Failed to send tag trends.")
 		return
 	}
 
-	log.Infof("Sent tag trends for %s", periodName)
+	logger().Infof("Sent tag trends for %s", periodName)
+}
+
+// trendPeriod maps a trend period option (week, month or quarter) to a number of days and a
+// display name, defaulting to a week.
+func trendPeriod(period string) (int, string) {
+	switch period {
+	case "month":
+		return 30, "Last 30 Days"
+	case "quarter":
+		return 90, "Last 90 Days"
+	default:
+		return 7, "Last 7 Days"
+	}
 }
 
 // handleEngagementReport handles the "engagement_report" command interaction
 func handleEngagementReport(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
 	// Validate inputs
 	if i == nil || i.Interaction == nil {
-		log.Warning("handleEngagementReport called with nil interaction")
+		logger().Warning("handleEngagementReport called with nil interaction")
 		return
 	}
 
@@ -345,52 +322,56 @@ func handleEngagementReport(b *types.Bot, s *discordgo.Session, i *discordgo.Int
 
 	// Acknowledge interaction with timeout handling
 	if err := AcknowledgeWithRetry(s, i); err != nil {
-		log.Errorf("Failed to acknowledge engagement_report command: %v", err)
+		logger().Errorf("Failed to acknowledge engagement_report command: %v", err)
 		return
 	}
 
 	// Get engagement report by aggregating various stats
-	log.Info("Getting detailed engagement report")
+	logger().Info("Getting detailed engagement report")
 
 	// Get database stats for report context
 	_, err := database.GetDatabaseStats(b)
 	if err != nil {
-		log.Errorf("Failed to get database stats: %v", err)
+		logger().Errorf("Failed to get database stats: %v", err)
 		Followup(s, i, "❌ Failed to get engagement report. Please try again later.")
 		return
 	}
 
-	channels, err := database.GetRegisteredChannels(b)
+	// Aggregate engagement across all registered channels
+	stats, err := database.GetGuildEngagement(b, "")
 	if err != nil {
-		log.Errorf("Failed to get registered channels: %v", err)
+		logger().Errorf("Failed to get engagement: %v", err)
 		Followup(s, i, "❌ Failed to get engagement report. Please try again later.")
 		return
 	}
 
-	// Calculate engagement metrics
-	totalServers := 0 // We'll need to implement guild counting
-	totalChannels := len(channels)
-	totalPosts := 0
-	weeklyPosts := 0
+	totalServers, _ := stats["guilds"].(int)
+	totalChannels, _ := stats["channels"].(int)
+	totalPosts, _ := stats["total_posts"].(int)
+	weeklyPosts, _ := stats["weekly_posts"].(int)
+	postsByInstance, _ := stats["posted_by"].(map[string]int)
 
-	// Aggregate channel engagement
-	for _, channelID := range channels {
-		channelStats, err := database.GetChannelEngagement(b, channelID)
-		if err != nil {
-			continue // Skip on error
-		}
-
-		if posts, ok := channelStats["total_posts"].(int); ok {
-			totalPosts += posts
-		}
-		if weekly, ok := channelStats["weekly_posts"].(int); ok {
-			weeklyPosts += weekly
-		}
+	// Channels registered before servers were recorded are matched to theirs by the next poll
+	serversValue := fmt.Sprintf("%d", totalServers)
+	if unknown, _ := stats["unknown_guild_channels"].(int); unknown > 0 {
+		serversValue = fmt.Sprintf("%d (+%d channels not yet matched)", totalServers, unknown)
 	}
 
 	// Calculate daily average
 	dailyAverage := float64(weeklyPosts) / 7.0
 
+	// Latency across all channels; catch-up and bulk-marked posts are excluded
+	latency, err := database.GetDeliveryLatency(b, "", time.Now().AddDate(0, 0, -7))
+	if err != nil {
+		logger().Errorf("Failed to get delivery latency: %v", err)
+	}
+
+	// Slash command usage; nothing is recorded while usage stats are disabled
+	usage, err := database.GetCommandUsageStats(b, commandUsageDays)
+	if err != nil {
+		logger().Errorf("Failed to get command usage: %v", err)
+	}
+
 	// Create detailed embed
 	embed := &discordgo.MessageEmbed{
 		Title:       "📈 Detailed Engagement Report",
@@ -402,7 +383,7 @@ func handleEngagementReport(b *types.Bot, s *discordgo.Session, i *discordgo.Int
 	embed.Fields = []*discordgo.MessageEmbedField{
 		{
 			Name:   "🏢 Total Servers",
-			Value:  fmt.Sprintf("%d", totalServers),
+			Value:  serversValue,
 			Inline: true,
 		},
 		{
@@ -427,12 +408,96 @@ func handleEngagementReport(b *types.Bot, s *discordgo.Session, i *discordgo.Int
 		},
 	}
 
+	if len(postsByInstance) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "🤖 Posts by Instance",
+			Value:  formatInstanceBreakdown(postsByInstance),
+			Inline: false,
+		})
+	}
+
+	if latency.Count > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "⏱️ Delivery Latency (7 days)",
+			Value:  formatLatency(latency),
+			Inline: false,
+		})
+	}
+
+	if len(usage) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   fmt.Sprintf("⌨️ Top Commands (%d days)", commandUsageDays),
+			Value:  formatCommandUsage(usage),
+			Inline: false,
+		})
+	}
+
 	// Send the result with enhanced error handling
 	if err := FollowupWithEmbeds(s, i, "", []*discordgo.MessageEmbed{embed}); err != nil {
-		log.Errorf("Failed to send engagement report: %v", err)
+		logger().Errorf("Failed to send engagement report: %v", err)
 		Followup(s, i, "❌ Failed to send engagement report.")
 		return
 	}
 
-	log.Info("Sent detailed engagement report")
+	logger().Info("Sent detailed engagement report")
+}
+
+// formatInstanceBreakdown formats per-instance post counts, largest first.
+func formatInstanceBreakdown(postsByInstance map[string]int) string {
+	instances := make([]string, 0, len(postsByInstance))
+	for instance := range postsByInstance {
+		instances = append(instances, instance)
+	}
+	sort.Slice(instances, func(a, b int) bool {
+		if postsByInstance[instances[a]] != postsByInstance[instances[b]] {
+			return postsByInstance[instances[a]] > postsByInstance[instances[b]]
+		}
+		return instances[a] < instances[b]
+	})
+
+	var lines []string
+	for _, instance := range instances {
+		lines = append(lines, fmt.Sprintf("`%s`: %d", instance, postsByInstance[instance]))
+	}
+	return TruncateBytes(strings.Join(lines, "\n"), MaxEmbedFieldValue)
+}
+
+// formatCountBreakdown formats up to limit items with their counts, largest first.
+func formatCountBreakdown(counts map[string]int, limit int) string {
+	items := make([]string, 0, len(counts))
+	for item := range counts {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(a, b int) bool {
+		if counts[items[a]] != counts[items[b]] {
+			return counts[items[a]] > counts[items[b]]
+		}
+		return items[a] < items[b]
+	})
+	if len(items) > limit {
+		items = items[:limit]
+	}
+
+	var lines []string
+	for _, item := range items {
+		lines = append(lines, fmt.Sprintf("• **%s** (%d)", item, counts[item]))
+	}
+	return TruncateBytes(strings.Join(lines, "\n"), MaxEmbedFieldValue)
+}
+
+// formatCommandUsage formats the most used slash commands with their use and user counts.
+func formatCommandUsage(usage []database.CommandUsage) string {
+	var lines []string
+	for n, u := range usage {
+		if n == maxTopCommands {
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%d. `/%s`: %d uses by %d users", n+1, u.Command, u.Uses, u.Users))
+	}
+	return TruncateBytes(strings.Join(lines, "\n"), MaxEmbedFieldValue)
+}
+
+// formatLatency formats delivery latency percentiles for an embed field.
+func formatLatency(latency database.LatencyStats) string {
+	return fmt.Sprintf("p50: %s\np95: %s\n(%d posts)", latency.P50, latency.P95, latency.Count)
 }
@@ -4,8 +4,14 @@
 package discord
 
 import (
+	"encoding/json"
+	"net/http"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/news"
 	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
 	"github.com/FracKenA/sto_news_discord_bot/internal/types"
 
@@ -138,6 +144,151 @@ func TestHandleServerStatsNilChecks(t *testing.T) {
 	}
 }
 
+func TestServerStatsUsesRecordedGuilds(t *testing.T) {
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	fake := testhelpers.NewFakeDiscord(t)
+	bot.Session = fake.Session()
+	fake.Handle("GET", "/guilds/guild-1", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "guild-1", "owner_id": "owner-1"})
+	})
+
+	// Registering records the channel's guild
+	handleRegister(bot, bot.Session, tagsInteraction("stobot_register", ""))
+	cfg, err := database.GetChannelConfig(bot, "channel-a")
+	if err != nil || cfg == nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if cfg.GuildID != "guild-1" {
+		t.Fatalf("Expected channel-a in guild-1, got %q", cfg.GuildID)
+	}
+
+	// channel-old was registered before guilds were recorded; channel-b is in another guild
+	if _, err := bot.DB.Exec(`INSERT INTO channels (id) VALUES ('channel-old');
+		INSERT INTO channels (id, guild_id) VALUES ('channel-b', 'guild-2')`); err != nil {
+		t.Fatalf("Failed to add channels: %v", err)
+	}
+	for _, post := range []struct {
+		newsID    int64
+		channelID string
+	}{{1, "channel-a"}, {2, "channel-a"}, {1, "channel-old"}, {1, "channel-b"}} {
+		if err := database.MarkNewsAsPosted(bot, post.newsID, post.channelID); err != nil {
+			t.Fatalf("Failed to mark news as posted: %v", err)
+		}
+	}
+
+	handleServerStats(bot, bot.Session, discoveryInteraction("stobot_server_stats"))
+
+	for _, request := range fake.Requests() {
+		if request.Method == "GET" && strings.HasPrefix(request.Path, "/channels/") {
+			t.Errorf("Expected no channel lookups, got GET %s", request.Path)
+		}
+	}
+	calls := fake.RequestsTo("POST", "/webhooks/app-1/interaction-token")
+	if len(calls) == 0 {
+		t.Fatal("Expected a followup")
+	}
+	var followup discordgo.WebhookParams
+	if err := json.Unmarshal(calls[len(calls)-1].Body, &followup); err != nil {
+		t.Fatalf("Failed to decode followup: %v", err)
+	}
+	if len(followup.Embeds) != 1 {
+		t.Fatalf("Expected 1 embed, got %s", calls[len(calls)-1].Body)
+	}
+	fields := make(map[string]string)
+	for _, field := range followup.Embeds[0].Fields {
+		fields[field.Name] = field.Value
+	}
+	if fields["📝 Total News Posted"] != "2" || fields["📺 Active Channels"] != "1" {
+		t.Errorf("Expected 2 posts in 1 channel of guild-1, got %v", fields)
+	}
+}
+
+func TestNewsStatsBreakdowns(t *testing.T) {
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	fake := testhelpers.NewFakeDiscord(t)
+	bot.Session = fake.Session()
+
+	news := []types.NewsItem{
+		{ID: 1, Title: "Season Update", Tags: []string{"star-trek-online", "events"}, Platforms: []string{"pc", "xbox"}, Updated: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 2, Title: "Patch Notes", Tags: []string{"patch-notes"}, Platforms: []string{"pc"}, Updated: time.Date(2024, 6, 11, 0, 0, 0, 0, time.UTC)},
+		{ID: 3, Title: "More Patch Notes", Tags: []string{"patch-notes"}, Platforms: []string{"pc"}, Updated: time.Date(2024, 6, 4, 0, 0, 0, 0, time.UTC)},
+	}
+	if err := database.CacheNews(bot, news); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+
+	handleNewsStats(bot, bot.Session, discoveryInteraction("stobot_news_stats"))
+
+	calls := fake.RequestsTo("POST", "/webhooks/app-1/interaction-token")
+	if len(calls) == 0 {
+		t.Fatal("Expected a followup")
+	}
+	var followup discordgo.WebhookParams
+	if err := json.Unmarshal(calls[len(calls)-1].Body, &followup); err != nil {
+		t.Fatalf("Failed to decode followup: %v", err)
+	}
+	if len(followup.Embeds) != 1 {
+		t.Fatalf("Expected 1 embed, got %s", calls[len(calls)-1].Body)
+	}
+	fields := make(map[string]string)
+	for _, field := range followup.Embeds[0].Fields {
+		fields[field.Name] = field.Value
+	}
+
+	expected := map[string]string{
+		"📰 Total News Articles": "3",
+		"📅 Date Range":          "2024-05-01 to 2024-06-11",
+		"🔝 Most Popular Tags":   "• **patch-notes** (2)\n• **events** (1)\n• **star-trek-online** (1)",
+		"🎮 News by Platform":    "• **pc** (3)\n• **xbox** (1)",
+	}
+	for name, value := range expected {
+		if fields[name] != value {
+			t.Errorf("Expected field %s to be %q, got %q", name, value, fields[name])
+		}
+	}
+}
+
+func TestNewsStatsPollCycles(t *testing.T) {
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	fake := testhelpers.NewFakeDiscord(t)
+	bot.Session = fake.Session()
+
+	lastCycle := time.Date(2024, 6, 11, 12, 0, 0, 0, time.UTC)
+	original := pollStats
+	pollStats = func() news.PollStats {
+		return news.PollStats{LastCycle: lastCycle, LastCycleDuration: 2500 * time.Millisecond, SkippedCycles: 3}
+	}
+	t.Cleanup(func() { pollStats = original })
+
+	handleNewsStats(bot, bot.Session, discoveryInteraction("stobot_news_stats"))
+
+	calls := fake.RequestsTo("POST", "/webhooks/app-1/interaction-token")
+	if len(calls) == 0 {
+		t.Fatal("Expected a followup")
+	}
+	var followup discordgo.WebhookParams
+	if err := json.Unmarshal(calls[len(calls)-1].Body, &followup); err != nil {
+		t.Fatalf("Failed to decode followup: %v", err)
+	}
+	if len(followup.Embeds) != 1 {
+		t.Fatalf("Expected 1 embed, got %s", calls[len(calls)-1].Body)
+	}
+	value := ""
+	for _, field := range followup.Embeds[0].Fields {
+		if field.Name == "⏱️ Poll Cycles" {
+			value = field.Value
+		}
+	}
+	for _, expected := range []string{"<t:1718107200:R>", "took 2.5s", "3 skipped"} {
+		if !strings.Contains(value, expected) {
+			t.Errorf("Expected the poll cycles field to contain %q, got %q", expected, value)
+		}
+	}
+}
+
 // TestHandlePopularThisWeekNilChecks tests handlePopularThisWeek with various nil conditions
 func TestHandlePopularThisWeekNilChecks(t *testing.T) {
 	bot := testhelpers.CreateTestBot(t)
@@ -0,0 +1,114 @@
+package discord
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// interactionUserID returns the ID of the user who sent an interaction, in a server or by
+// direct message, or an empty string if it is unknown.
+func interactionUserID(i *discordgo.InteractionCreate) string {
+	switch {
+	case i.Member != nil && i.Member.User != nil:
+		return i.Member.User.ID
+	case i.User != nil:
+		return i.User.ID
+	default:
+		return ""
+	}
+}
+
+// handleSubscribe handles the "subscribe" command interaction: it subscribes the invoker to
+// direct messages about news with the given tags, or shows their subscription without options.
+func handleSubscribe(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	userID := interactionUserID(i)
+	if userID == "" {
+		RespondError(s, i, "Could not identify you.")
+		return
+	}
+
+	var tagsValue, platformsValue string
+	for _, option := range i.ApplicationCommandData().Options {
+		switch option.Name {
+		case "tags":
+			tagsValue = option.StringValue()
+		case "platforms":
+			platformsValue = option.StringValue()
+		}
+	}
+
+	if tagsValue == "" && platformsValue == "" {
+		showSubscription(b, s, i, userID)
+		return
+	}
+
+	tags := parseTagList(tagsValue)
+	if len(tags) == 0 {
+		RespondError(s, i, "Please give the tags to subscribe to, e.g. `patch-notes,events`.")
+		return
+	}
+	platforms := types.Platforms
+	if platformsValue != "" {
+		var err error
+		if platforms, err = types.ParsePlatforms(platformsValue); err != nil {
+			RespondError(s, i, fmt.Sprintf("Invalid platforms: %v", err))
+			return
+		}
+	}
+
+	if err := database.SetUserSubscription(b, userID, tags, platforms); err != nil {
+		logger().Errorf("Failed to save subscription of user %s: %v", userID, err)
+		RespondError(s, i, "Failed to save your subscription. Please try again later.")
+		return
+	}
+
+	logger().Infof("User %s subscribed to tags %v on %v", userID, tags, platforms)
+	Respond(s, i, fmt.Sprintf("✅ You will get a direct message about new news tagged %s for %s.\n"+
+		"Make sure you accept direct messages from this server's members.",
+		strings.Join(tags, ", "), strings.Join(platforms, ", ")))
+}
+
+// showSubscription shows the invoker their subscription.
+func showSubscription(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate, userID string) {
+	subscription, err := database.GetUserSubscription(b, userID)
+	if err != nil {
+		logger().Errorf("Failed to get subscription of user %s: %v", userID, err)
+		RespondError(s, i, "Failed to get your subscription. Please try again later.")
+		return
+	}
+	if subscription == nil {
+		Respond(s, i, "📭 You are not subscribed to any news. Use `/stobot_subscribe <tags> [platforms]` to get news by direct message.")
+		return
+	}
+	Respond(s, i, fmt.Sprintf("📬 **Your subscription**\nTags: %s\nPlatforms: %s\nSince: %s",
+		strings.Join(subscription.Tags, ", "), strings.Join(subscription.Platforms, ", "),
+		subscription.CreatedAt.UTC().Format("2006-01-02")))
+}
+
+// handleUnsubscribe handles the "unsubscribe" command interaction
+func handleUnsubscribe(b *types.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	userID := interactionUserID(i)
+	if userID == "" {
+		RespondError(s, i, "Could not identify you.")
+		return
+	}
+
+	removed, err := database.RemoveUserSubscription(b, userID)
+	if err != nil {
+		logger().Errorf("Failed to remove subscription of user %s: %v", userID, err)
+		RespondError(s, i, "Failed to remove your subscription. Please try again later.")
+		return
+	}
+	if !removed {
+		Respond(s, i, "You are not subscribed to any news.")
+		return
+	}
+
+	logger().Infof("User %s unsubscribed", userID)
+	Respond(s, i, "✅ You will no longer get news by direct message.")
+}
@@ -0,0 +1,102 @@
+package discord
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// subscriptionInteraction returns a subscription command sent by user-1 in a server.
+func subscriptionInteraction(name string, options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.InteractionCreate {
+	interaction := discoveryInteraction(name, options...)
+	interaction.Member = &discordgo.Member{User: &discordgo.User{ID: "user-1"}}
+	return interaction
+}
+
+func TestSubscriptionCommands(t *testing.T) {
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	fake := testhelpers.NewFakeDiscord(t)
+	bot.Session = fake.Session()
+
+	// reply runs a command and returns its ephemeral response
+	reply := func(handler func(), interaction *discordgo.InteractionCreate) string {
+		t.Helper()
+		before := len(fake.RequestsTo("POST", "/interactions/interaction-1/interaction-token/callback"))
+		handler()
+		calls := fake.RequestsTo("POST", "/interactions/interaction-1/interaction-token/callback")[before:]
+		if len(calls) != 1 {
+			t.Fatalf("Expected 1 response, got %d", len(calls))
+		}
+		var response discordgo.InteractionResponse
+		if err := json.Unmarshal(calls[0].Body, &response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if response.Data.Flags&discordgo.MessageFlagsEphemeral == 0 {
+			t.Error("Expected the response to be ephemeral")
+		}
+		return response.Data.Content
+	}
+	subscribe := func(options ...*discordgo.ApplicationCommandInteractionDataOption) string {
+		interaction := subscriptionInteraction("stobot_subscribe", options...)
+		return reply(func() { handleSubscribe(bot, bot.Session, interaction) }, interaction)
+	}
+	unsubscribe := func() string {
+		interaction := subscriptionInteraction("stobot_unsubscribe")
+		return reply(func() { handleUnsubscribe(bot, bot.Session, interaction) }, interaction)
+	}
+
+	if content := subscribe(); !strings.Contains(content, "not subscribed") {
+		t.Errorf("Expected no subscription to be shown, got %q", content)
+	}
+
+	tests := []struct {
+		name      string
+		options   []*discordgo.ApplicationCommandInteractionDataOption
+		expected  string
+		tags      []string
+		platforms []string
+	}{
+		{"tags", []*discordgo.ApplicationCommandInteractionDataOption{stringOption("tags", "Patch-Notes, events")},
+			"news tagged patch-notes, events for pc, xbox, ps", []string{"patch-notes", "events"}, []string{"pc", "xbox", "ps"}},
+		{"tags and platforms", []*discordgo.ApplicationCommandInteractionDataOption{stringOption("tags", "events"), stringOption("platforms", "PlayStation")},
+			"news tagged events for ps", []string{"events"}, []string{"ps"}},
+		{"unknown platform", []*discordgo.ApplicationCommandInteractionDataOption{stringOption("tags", "dev-blogs"), stringOption("platforms", "switch")},
+			"Invalid platforms", []string{"events"}, []string{"ps"}},
+		{"platforms without tags", []*discordgo.ApplicationCommandInteractionDataOption{stringOption("platforms", "pc")},
+			"Please give the tags", []string{"events"}, []string{"ps"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if content := subscribe(tt.options...); !strings.Contains(content, tt.expected) {
+				t.Errorf("Expected %q in the response, got %q", tt.expected, content)
+			}
+			subscription, err := database.GetUserSubscription(bot, "user-1")
+			if err != nil {
+				t.Fatalf("Failed to get subscription: %v", err)
+			}
+			if subscription == nil {
+				t.Fatal("Expected a subscription")
+			}
+			if !reflect.DeepEqual(subscription.Tags, tt.tags) || !reflect.DeepEqual(subscription.Platforms, tt.platforms) {
+				t.Errorf("Expected tags %v on %v, got %v on %v", tt.tags, tt.platforms, subscription.Tags, subscription.Platforms)
+			}
+		})
+	}
+
+	if content := subscribe(); !strings.Contains(content, "Tags: events\nPlatforms: ps") {
+		t.Errorf("Expected the subscription to be shown, got %q", content)
+	}
+	if content := unsubscribe(); !strings.Contains(content, "no longer get news") {
+		t.Errorf("Expected the subscription to be removed, got %q", content)
+	}
+	if content := unsubscribe(); !strings.Contains(content, "not subscribed") {
+		t.Errorf("Expected nothing to remove, got %q", content)
+	}
+}
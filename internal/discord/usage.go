@@ -0,0 +1,44 @@
+package discord
+
+import (
+	"sync"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxPendingUsage bounds the command usage records being written at once. Usage of commands
+// run while the database is that far behind is dropped rather than queued.
+const maxPendingUsage = 16
+
+var (
+	usageSlots   = make(chan struct{}, maxPendingUsage)
+	usageWriters sync.WaitGroup // usageWriters lets tests wait for pending usage records.
+)
+
+// recordCommandUsage records the use of a slash command in the background, so a slow database
+// never delays the command, unless usage stats are disabled.
+func recordCommandUsage(b *types.Bot, i *discordgo.InteractionCreate, command string) {
+	if b.DB == nil || (b.Config != nil && b.Config.DisableUsageStats) {
+		return
+	}
+
+	select {
+	case usageSlots <- struct{}{}:
+	default:
+		logger().Debugf("Dropped usage of command %s: %d usage records pending", command, maxPendingUsage)
+		return
+	}
+
+	guildID, userID := i.GuildID, interactionUserID(i)
+	usageWriters.Add(1)
+	go func() {
+		defer usageWriters.Done()
+		defer func() { <-usageSlots }()
+		if err := database.RecordCommandUsage(b, command, guildID, userID); err != nil {
+			logger().Warnf("Failed to record usage of command %s: %v", command, err)
+		}
+	}()
+}
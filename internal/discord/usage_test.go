@@ -0,0 +1,95 @@
+package discord
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestCommandUsageRecording(t *testing.T) {
+	tests := []struct {
+		name     string
+		disabled bool
+		expected int
+	}{
+		{name: "enabled", expected: 2},
+		{name: "disabled", disabled: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := testhelpers.CreateTestBot(t)
+			defer bot.DB.Close()
+			bot.Config.DisableUsageStats = tt.disabled
+			fake := testhelpers.NewFakeDiscord(t)
+			bot.Session = fake.Session()
+
+			for range 2 {
+				interaction := discoveryInteraction("stobot_help")
+				interaction.Member = &discordgo.Member{User: &discordgo.User{ID: "user-1"}}
+				HandleCommand(bot, bot.Session, interaction)
+			}
+			usageWriters.Wait()
+
+			usage, err := database.GetCommandUsageStats(bot, commandUsageDays)
+			if err != nil {
+				t.Fatalf("Failed to get command usage: %v", err)
+			}
+			uses := 0
+			for _, u := range usage {
+				uses += u.Uses
+			}
+			if uses != tt.expected {
+				t.Errorf("Expected %d recorded uses, got %+v", tt.expected, usage)
+			}
+		})
+	}
+}
+
+func TestEngagementReportTopCommands(t *testing.T) {
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	fake := testhelpers.NewFakeDiscord(t)
+	bot.Session = fake.Session()
+	fake.Handle("GET", "/guilds/guild-1", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "guild-1", "owner_id": "owner-1"})
+	})
+
+	for _, command := range []string{"stobot_news", "stobot_news", "stobot_status"} {
+		if err := database.RecordCommandUsage(bot, command, "guild-1", "user-1"); err != nil {
+			t.Fatalf("Failed to record command usage: %v", err)
+		}
+	}
+
+	interaction := discoveryInteraction("stobot_engagement_report")
+	interaction.Member = &discordgo.Member{User: &discordgo.User{ID: "owner-1"}}
+	handleEngagementReport(bot, bot.Session, interaction)
+
+	followups := fake.RequestsTo("POST", "/webhooks/app-1/interaction-token")
+	if len(followups) != 1 {
+		t.Fatalf("Expected 1 followup, got %d", len(followups))
+	}
+	body := string(followups[0].Body)
+	for _, expected := range []string{"Top Commands (30 days)", "1. `/stobot_news`: 2 uses by 1 users", "2. `/stobot_status`: 1 uses by 1 users"} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected the report to contain %q, got %s", expected, body)
+		}
+	}
+}
+
+func TestFormatCommandUsage(t *testing.T) {
+	var usage []database.CommandUsage
+	for n := range maxTopCommands + 5 {
+		usage = append(usage, database.CommandUsage{Command: "stobot_news", Uses: 100 - n, Users: 1})
+	}
+
+	lines := strings.Split(formatCommandUsage(usage), "\n")
+	if len(lines) != maxTopCommands {
+		t.Errorf("Expected %d commands, got %d", maxTopCommands, len(lines))
+	}
+}
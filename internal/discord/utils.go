@@ -4,13 +4,16 @@
 package discord
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
 
 	"github.com/bwmarrin/discordgo"
-	log "github.com/sirupsen/logrus"
 )
 
 // Discord API limits constants
@@ -23,7 +26,9 @@ const (
 	MaxEmbedFooterText  = 2048
 	MaxEmbedAuthorName  = 256
 	MaxEmbedsPerMessage = 10
-	InteractionTimeout  = 3 * time.Second // Discord's 3-second acknowledgment requirement
+	MaxEmbedsTotalText  = 6000             // Combined length of all embed texts in one message
+	InteractionTimeout  = 3 * time.Second  // Discord's 3-second acknowledgment requirement
+	MaxUploadSize       = 10 * 1024 * 1024 // Discord's default attachment size limit for servers without boosts
 )
 
 // RetryConfig defines retry behavior for Discord API calls
@@ -52,7 +57,7 @@ func withRetry(operation func() error, config RetryConfig) error {
 			if delay > config.MaxDelay {
 				delay = config.MaxDelay
 			}
-			log.Warnf("Retrying Discord operation in %v (attempt %d/%d)", delay, attempt, config.MaxRetries)
+			logger().Warnf("Retrying Discord operation in %v (attempt %d/%d)", delay, attempt, config.MaxRetries)
 			time.Sleep(delay)
 		}
 
@@ -63,18 +68,18 @@ func withRetry(operation func() error, config RetryConfig) error {
 
 			// Check if error is retryable
 			if !isRetryableError(err) {
-				log.Errorf("Non-retryable Discord error: %v", err)
+				logger().Errorf("Non-retryable Discord error: %v", err)
 				return err
 			}
 
-			log.Warnf("Retryable Discord error on attempt %d: %v", attempt+1, err)
+			logger().Warnf("Retryable Discord error on attempt %d: %v", attempt+1, err)
 			continue
 		}
 
 		return nil // Success
 	}
 
-	log.Errorf("Discord operation failed after %d attempts: %v", config.MaxRetries+1, lastErr)
+	logger().Errorf("Discord operation failed after %d attempts: %v", config.MaxRetries+1, lastErr)
 	return fmt.Errorf("operation failed after %d retries: %w", config.MaxRetries, lastErr)
 }
 
@@ -148,12 +153,12 @@ func findInString(s, substr string) bool {
 // Respond sends a response to a Discord interaction with retry logic
 func Respond(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
 	if s == nil || i == nil || i.Interaction == nil {
-		log.Warn("Cannot respond: nil session or interaction")
+		logger().Warn("Cannot respond: nil session or interaction")
 		return
 	}
 
 	// Truncate content to Discord limits
-	content = TruncateText(content, MaxMessageLength)
+	content = TruncateBytes(content, MaxMessageLength)
 
 	operation := func() error {
 		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -166,7 +171,7 @@ func Respond(s *discordgo.Session, i *discordgo.InteractionCreate, content strin
 	}
 
 	if err := withRetry(operation, DefaultRetryConfig()); err != nil {
-		log.Errorf("Failed to respond to interaction after retries: %v", err)
+		logger().Errorf("Failed to respond to interaction after retries: %v", err)
 	}
 }
 
@@ -178,12 +183,12 @@ func RespondError(s *discordgo.Session, i *discordgo.InteractionCreate, message
 // Followup sends a follow-up message to a Discord interaction with retry logic
 func Followup(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
 	if s == nil || i == nil || i.Interaction == nil {
-		log.Warn("Cannot send followup: nil session or interaction")
+		logger().Warn("Cannot send followup: nil session or interaction")
 		return
 	}
 
 	// Truncate content to Discord limits
-	content = TruncateText(content, MaxMessageLength)
+	content = TruncateBytes(content, MaxMessageLength)
 
 	operation := func() error {
 		_, err := s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
@@ -194,7 +199,7 @@ func Followup(s *discordgo.Session, i *discordgo.InteractionCreate, content stri
 	}
 
 	if err := withRetry(operation, DefaultRetryConfig()); err != nil {
-		log.Errorf("Failed to send followup message after retries: %v", err)
+		logger().Errorf("Failed to send followup message after retries: %v", err)
 	}
 }
 
@@ -205,31 +210,59 @@ func FollowupError(s *discordgo.Session, i *discordgo.InteractionCreate, message
 
 // FollowupWithEmbeds sends a follow-up message with embeds and retry logic
 func FollowupWithEmbeds(s *discordgo.Session, i *discordgo.InteractionCreate, content string, embeds []*discordgo.MessageEmbed) error {
+	return FollowupWithComponents(s, i, content, embeds, nil)
+}
+
+// FollowupWithComponents sends a follow-up message with embeds and message components, such as
+// buttons, with retry logic.
+func FollowupWithComponents(s *discordgo.Session, i *discordgo.InteractionCreate, content string, embeds []*discordgo.MessageEmbed, components []discordgo.MessageComponent) error {
 	if s == nil || i == nil || i.Interaction == nil {
-		log.Warn("Cannot send followup with embeds: nil session or interaction")
+		logger().Warn("Cannot send followup with embeds: nil session or interaction")
 		return fmt.Errorf("nil session or interaction")
 	}
 
+	embeds = truncateEmbeds(embeds)
+
+	// Truncate content to Discord limits
+	if content != "" {
+		content = TruncateBytes(content, MaxMessageLength)
+	}
+
+	operation := func() error {
+		_, err := s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+			Content:    content,
+			Embeds:     embeds,
+			Components: components,
+			Flags:      discordgo.MessageFlagsEphemeral, // Make followup embeds private
+		})
+		return err
+	}
+
+	return withRetry(operation, DefaultRetryConfig())
+}
+
+// truncateEmbeds truncates embed texts and the number of embeds to Discord limits.
+func truncateEmbeds(embeds []*discordgo.MessageEmbed) []*discordgo.MessageEmbed {
 	// Validate and truncate embed content
 	for _, embed := range embeds {
 		if embed.Title != "" {
-			embed.Title = TruncateText(embed.Title, MaxEmbedTitle)
+			embed.Title = TruncateBytes(embed.Title, MaxEmbedTitle)
 		}
 		if embed.Description != "" {
-			embed.Description = TruncateText(embed.Description, MaxEmbedDescription)
+			embed.Description = TruncateBytes(embed.Description, MaxEmbedDescription)
 		}
 		if embed.Footer != nil && embed.Footer.Text != "" {
-			embed.Footer.Text = TruncateText(embed.Footer.Text, MaxEmbedFooterText)
+			embed.Footer.Text = TruncateBytes(embed.Footer.Text, MaxEmbedFooterText)
 		}
 		if embed.Author != nil && embed.Author.Name != "" {
-			embed.Author.Name = TruncateText(embed.Author.Name, MaxEmbedAuthorName)
+			embed.Author.Name = TruncateBytes(embed.Author.Name, MaxEmbedAuthorName)
 		}
 		for _, field := range embed.Fields {
 			if field.Name != "" {
-				field.Name = TruncateText(field.Name, MaxEmbedFieldName)
+				field.Name = TruncateBytes(field.Name, MaxEmbedFieldName)
 			}
 			if field.Value != "" {
-				field.Value = TruncateText(field.Value, MaxEmbedFieldValue)
+				field.Value = TruncateBytes(field.Value, MaxEmbedFieldValue)
 			}
 		}
 	}
@@ -237,19 +270,35 @@ func FollowupWithEmbeds(s *discordgo.Session, i *discordgo.InteractionCreate, co
 	// Limit number of embeds per message
 	if len(embeds) > MaxEmbedsPerMessage {
 		embeds = embeds[:MaxEmbedsPerMessage]
-		log.Warnf("Truncated embeds to Discord limit of %d", MaxEmbedsPerMessage)
+		logger().Warnf("Truncated embeds to Discord limit of %d", MaxEmbedsPerMessage)
+	}
+	return embeds
+}
+
+// FollowupWithFile sends a private follow-up message with a file attachment and retry logic.
+// Files larger than MaxUploadSize are rejected without calling Discord.
+func FollowupWithFile(s *discordgo.Session, i *discordgo.InteractionCreate, content, filename, contentType string, data []byte) error {
+	if s == nil || i == nil || i.Interaction == nil {
+		logger().Warn("Cannot send followup with file: nil session or interaction")
+		return fmt.Errorf("nil session or interaction")
+	}
+
+	if len(data) > MaxUploadSize {
+		return fmt.Errorf("file %s is %d bytes, over Discord's %d byte upload limit", filename, len(data), MaxUploadSize)
 	}
 
 	// Truncate content to Discord limits
 	if content != "" {
-		content = TruncateText(content, MaxMessageLength)
+		content = TruncateBytes(content, MaxMessageLength)
 	}
 
 	operation := func() error {
 		_, err := s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
 			Content: content,
-			Embeds:  embeds,
-			Flags:   discordgo.MessageFlagsEphemeral, // Make followup embeds private
+			Files: []*discordgo.File{
+				{Name: filename, ContentType: contentType, Reader: bytes.NewReader(data)},
+			},
+			Flags: discordgo.MessageFlagsEphemeral, // Make followup files private
 		})
 		return err
 	}
@@ -257,18 +306,57 @@ func FollowupWithEmbeds(s *discordgo.Session, i *discordgo.InteractionCreate, co
 	return withRetry(operation, DefaultRetryConfig())
 }
 
-// TruncateText truncates text to a maximum length, adding ellipsis if needed
+// TruncateText truncates text to at most maxLength characters, adding an ellipsis if needed.
+// It counts runes, so multi-byte characters are never split. Use TruncateBytes for Discord limits.
+// See types.TruncateText, which packages that cannot import this one use.
 func TruncateText(text string, maxLength int) string {
-	if len(text) <= maxLength {
-		return text
-	}
+	return types.TruncateText(text, maxLength)
+}
 
-	if maxLength <= 3 {
-		// Return truncated ellipsis to fit within maxLength
-		return strings.Repeat(".", maxLength)
-	}
+// TruncateTextAtWord is like TruncateText, but cuts after the last whole word that fits. Text
+// whose last word break would drop more than half of it, such as a long URL, is cut mid-word.
+func TruncateTextAtWord(text string, maxLength int) string {
+	return types.TruncateTextAtWord(text, maxLength)
+}
 
-	return text[:maxLength-3] + "..."
+// TruncateBytes truncates text to at most maxBytes bytes, adding an ellipsis if needed, without
+// splitting a UTF-8 character. Discord counts its limits in characters, so text within the
+// Max* limits in bytes is always accepted. See types.TruncateBytes.
+func TruncateBytes(text string, maxBytes int) string {
+	return types.TruncateBytes(text, maxBytes)
+}
+
+// SplitText splits text into chunks of at most maxLength bytes, preferring to break after a
+// line, a sentence or a word. Chunks are trimmed and never split a UTF-8 character.
+func SplitText(text string, maxLength int) []string {
+	var chunks []string
+	text = strings.TrimSpace(text)
+	for text != "" {
+		if len(text) <= maxLength {
+			chunks = append(chunks, text)
+			break
+		}
+
+		// Back off to a character boundary, then to the last natural break
+		cut := maxLength
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		if cut == 0 {
+			// maxLength is shorter than the first character
+			_, cut = utf8.DecodeRuneInString(text)
+		}
+		for _, separator := range []string{"\n", ". ", " "} {
+			if at := strings.LastIndex(text[:cut], separator); at > 0 {
+				cut = at + len(separator)
+				break
+			}
+		}
+
+		chunks = append(chunks, strings.TrimSpace(text[:cut]))
+		text = strings.TrimSpace(text[cut:])
+	}
+	return chunks
 }
 
 // AcknowledgeInteraction safely acknowledges an interaction within Discord's 3-second limit
@@ -284,8 +372,9 @@ func AcknowledgeInteraction(s *discordgo.Session, i *discordgo.InteractionCreate
 	// Channel to receive the operation result
 	resultChan := make(chan error, 1)
 
-	// Apply rate limiting
-	WaitForRateLimit()
+	// Apply rate limiting; acknowledgments are paced apart from messages so a burst of news
+	// posts cannot delay them past the interaction deadline
+	WaitForInteractionRateLimit()
 
 	// Perform the acknowledgment in a goroutine
 	go func() {
@@ -302,13 +391,13 @@ func AcknowledgeInteraction(s *discordgo.Session, i *discordgo.InteractionCreate
 	select {
 	case err := <-resultChan:
 		if err != nil {
-			log.Errorf("Failed to acknowledge interaction: %v", err)
+			logger().Errorf("Failed to acknowledge interaction: %v", err)
 			return err
 		}
-		log.Debug("Interaction acknowledged successfully")
+		logger().Debug("Interaction acknowledged successfully")
 		return nil
 	case <-ctx.Done():
-		log.Error("Interaction acknowledgment timed out")
+		logger().Error("Interaction acknowledgment timed out")
 		return fmt.Errorf("interaction acknowledgment timed out")
 	}
 }
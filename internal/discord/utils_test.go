@@ -4,11 +4,16 @@
 package discord
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/ratelimit"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -121,6 +126,114 @@ func TestTruncateTextDiscordLimits(t *testing.T) {
 	}
 }
 
+func TestTruncateTextMultiByte(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		maxLength int
+		atWord    bool
+		expected  string
+	}{
+		{name: "umlauts fit in runes", text: "Schöne Grüße", maxLength: 12, expected: "Schöne Grüße"},
+		{name: "umlauts", text: "Schöne Grüße aus Qo'noS", maxLength: 10, expected: "Schöne ..."},
+		{name: "emoji", text: "🚀🚀🚀🚀🚀🚀", maxLength: 5, expected: "🚀🚀..."},
+		{name: "cjk", text: "宇宙艦隊の最新ニュース", maxLength: 6, expected: "宇宙艦..."},
+		{name: "short max length", text: "🚀🚀🚀🚀", maxLength: 2, expected: ".."},
+		{name: "at word", text: "Season Update: Über die Grenze", maxLength: 20, atWord: true, expected: "Season Update:..."},
+		{name: "at word on space", text: "Große Neuigkeiten heute", maxLength: 20, atWord: true, expected: "Große Neuigkeiten..."},
+		{name: "at word with emoji", text: "🚀 Launch 🚀 Week 🚀 Rewards", maxLength: 16, atWord: true, expected: "🚀 Launch 🚀..."},
+		{name: "at word long word", text: "https://www.arcgames.com/en/games/star-trek-online/news", maxLength: 20, atWord: true,
+			expected: "https://www.arcga..."},
+		{name: "at word fits", text: "Holodeck", maxLength: 20, atWord: true, expected: "Holodeck"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := TruncateText(tt.text, tt.maxLength)
+			if tt.atWord {
+				result = TruncateTextAtWord(tt.text, tt.maxLength)
+			}
+			if result != tt.expected {
+				t.Errorf("Truncate(%q, %d) = %q, want %q", tt.text, tt.maxLength, result, tt.expected)
+			}
+			if !utf8.ValidString(result) {
+				t.Errorf("Result %q is not valid UTF-8", result)
+			}
+			if n := utf8.RuneCountInString(result); n > tt.maxLength {
+				t.Errorf("Result length %d exceeds maxLength %d", n, tt.maxLength)
+			}
+		})
+	}
+}
+
+func TestTruncateBytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		maxBytes int
+		expected string
+	}{
+		{name: "ascii fits", text: "Hello", maxBytes: 5, expected: "Hello"},
+		{name: "ascii", text: "Hello World", maxBytes: 8, expected: "Hello..."},
+		{name: "umlaut on the cut", text: "Grüße", maxBytes: 6, expected: "Gr..."},
+		{name: "umlaut fits", text: "Grüße", maxBytes: 7, expected: "Grüße"},
+		{name: "emoji", text: "🚀🚀🚀", maxBytes: 10, expected: "🚀..."},
+		{name: "emoji before ellipsis fits", text: "🚀🚀🚀", maxBytes: 11, expected: "🚀🚀..."},
+		{name: "character longer than limit", text: "🚀🚀", maxBytes: 5, expected: "..."},
+		{name: "short max bytes", text: "Grüße", maxBytes: 2, expected: ".."},
+		{name: "discord limit", text: strings.Repeat("ä", 3000), maxBytes: MaxMessageLength, expected: strings.Repeat("ä", 998) + "..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := TruncateBytes(tt.text, tt.maxBytes)
+			if result != tt.expected {
+				t.Errorf("TruncateBytes(%q, %d) = %q, want %q", tt.text, tt.maxBytes, result, tt.expected)
+			}
+			if !utf8.ValidString(result) {
+				t.Errorf("Result %q is not valid UTF-8", result)
+			}
+			if len(result) > tt.maxBytes {
+				t.Errorf("Result length %d exceeds maxBytes %d", len(result), tt.maxBytes)
+			}
+		})
+	}
+}
+
+func TestSplitText(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		maxLength int
+		expected  []string
+	}{
+		{"fits", "Short text.", 20, []string{"Short text."}},
+		{"empty", "  ", 20, nil},
+		{"sentences", "First sentence. Second sentence. Third.", 20, []string{"First sentence.", "Second sentence.", "Third."}},
+		{"lines before sentences", "One. Two\nThree. Four", 17, []string{"One. Two", "Three. Four"}},
+		{"words", "alpha beta gamma delta", 11, []string{"alpha beta", "gamma delta"}},
+		{"long word", "abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"multibyte characters", "ééééé", 3, []string{"é", "é", "é", "é", "é"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := SplitText(tt.text, tt.maxLength)
+			if len(chunks) != len(tt.expected) {
+				t.Fatalf("Expected %q, got %q", tt.expected, chunks)
+			}
+			for n, chunk := range chunks {
+				if chunk != tt.expected[n] {
+					t.Errorf("Expected %q, got %q", tt.expected, chunks)
+				}
+				if len(chunk) > tt.maxLength {
+					t.Errorf("Chunk %q is longer than %d bytes", chunk, tt.maxLength)
+				}
+			}
+		})
+	}
+}
+
 func TestRespond(t *testing.T) {
 	// Create a mock interaction
 	interaction := &discordgo.InteractionCreate{
@@ -286,7 +399,16 @@ func TestDefaultRetryConfig(t *testing.T) {
 	}
 }
 
+// setMessageLimiter replaces the shared limiter pacing messages for the duration of a test.
+func setMessageLimiter(t *testing.T, limiter *ratelimit.Limiter) {
+	t.Helper()
+	original := ratelimit.Messages
+	ratelimit.Messages = limiter
+	t.Cleanup(func() { ratelimit.Messages = original })
+}
+
 func TestWithRetry(t *testing.T) {
+	setMessageLimiter(t, ratelimit.New(1e6, 1e6))
 	tests := []struct {
 		name          string
 		operation     func() error
@@ -799,6 +921,42 @@ func TestFollowupWithEmbedsValidation(t *testing.T) {
 	})
 }
 
+func TestFormatNewsEmbedBareItem(t *testing.T) {
+	tests := []struct {
+		name     string
+		newsItem types.NewsItem
+		footer   string
+		fields   int
+	}{
+		{"bare item", types.NewsItem{ID: 1}, "Platforms: None", 0},
+		{"tags and platforms", types.NewsItem{ID: 2, Title: "News", Tags: []string{"events"}, Platforms: []string{"pc", "xbox"}}, "Platforms: pc, xbox", 1},
+		{"long tags", types.NewsItem{ID: 3, Tags: []string{strings.Repeat("t", 2000)}}, "Platforms: None", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			embed := formatNewsEmbed(tt.newsItem)
+			if embed.Footer == nil || embed.Footer.Text != tt.footer {
+				t.Errorf("Expected footer %q, got %+v", tt.footer, embed.Footer)
+			}
+			if len(embed.Fields) != tt.fields {
+				t.Fatalf("Expected %d fields, got %+v", tt.fields, embed.Fields)
+			}
+			if len(embed.Title) > MaxEmbedTitle || len(embed.Description) > MaxEmbedDescription || len(embed.Footer.Text) > MaxEmbedFooterText {
+				t.Errorf("Expected the embed within Discord's limits, got %+v", embed)
+			}
+			// Discord rejects the whole message when a field name or value is empty
+			for _, field := range embed.Fields {
+				if field.Name == "" || field.Value == "" || len(field.Name) > MaxEmbedFieldName || len(field.Value) > MaxEmbedFieldValue {
+					t.Errorf("Expected a non-empty field within limits, got %q: %d bytes", field.Name, len(field.Value))
+				}
+				if field.Name == "Platforms" {
+					t.Error("Expected platforms only in the footer")
+				}
+			}
+		})
+	}
+}
+
 func TestAcknowledgeInteraction(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -992,3 +1150,20 @@ func TestRetryConfigValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestWaitForRateLimitSharesMessageLimiter(t *testing.T) {
+	setMessageLimiter(t, ratelimit.New(1, 1))
+
+	// A news post takes the only token, so the next request has to wait for it
+	if err := ratelimit.Messages.Wait(context.Background()); err != nil {
+		t.Fatalf("Failed to take a token: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := WaitForRateLimitWithContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected to wait on the token taken by the news post, got %v", err)
+	}
+	if stats := GetGlobalRateLimitStats(); stats["rate_per_second"] != 1.0 {
+		t.Errorf("Expected the stats of the shared limiter, got %v", stats)
+	}
+}
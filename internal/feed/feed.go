@@ -0,0 +1,162 @@
+// Package feed renders cached news as an Atom feed and serves it over HTTP, for communities that
+// mirror the news into tools other than Discord.
+//
+// The feed is only served when the bot is started with --feed-addr:
+//
+//	http://stobot:9090/feed.xml              the latest news
+//	http://stobot:9090/feed/patch-notes.xml  the latest news with a tag
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// atomNamespace is the XML namespace of Atom documents (RFC 4287).
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+// idPrefix starts the IDs of the feeds and their entries. IDs are tag URIs (RFC 4151) built
+// from news IDs and tags only, so they stay the same when articles are edited or move.
+const idPrefix = "tag:playstartrekonline.com,2010:"
+
+// Options describe the feed rendered by Render.
+type Options struct {
+	Tag     string    // Tag is the tag the news was selected by; empty for all news.
+	SelfURL string    // SelfURL is where the feed is served; empty leaves out the self link.
+	Updated time.Time // Updated is the feed's update time when it has no entries.
+}
+
+// atomFeed is an Atom feed document.
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  atomAuthor  `xml:"author"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// atomAuthor is the author of a feed, inherited by its entries.
+type atomAuthor struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+// atomLink is a link of a feed or entry.
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+// atomEntry is a news item in a feed.
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Links      []atomLink     `xml:"link"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Categories []atomCategory `xml:"category"`
+}
+
+// atomText is a plain text construct.
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// atomCategory is a tag of an entry.
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// FeedID returns the Atom ID of the feed of news with tag, or of all news for an empty tag.
+func FeedID(tag string) string {
+	if tag == "" {
+		return idPrefix + "news"
+	}
+	return idPrefix + "news/tag/" + tag
+}
+
+// EntryID returns the Atom ID of the entry of a news item.
+func EntryID(newsID int64) string {
+	return fmt.Sprintf("%snews/%d", idPrefix, newsID)
+}
+
+// Render renders newsItems, newest first, as an Atom feed. The feed is updated when its newest
+// entry was, or at options.Updated when it has none. Dates are in RFC 3339, in UTC.
+//
+// Example:
+//
+//	data, err := feed.Render(newsItems, feed.Options{Tag: "patch-notes", SelfURL: "https://example.com/feed/patch-notes.xml"})
+func Render(newsItems []types.NewsItem, options Options) ([]byte, error) {
+	title := "Star Trek Online News"
+	if options.Tag != "" {
+		title += ": " + options.Tag
+	}
+
+	updated := options.Updated
+	entries := make([]atomEntry, 0, len(newsItems))
+	for i, newsItem := range newsItems {
+		if i == 0 || newsItem.Updated.After(updated) {
+			updated = newsItem.Updated
+		}
+		entries = append(entries, renderEntry(newsItem))
+	}
+
+	doc := atomFeed{
+		Xmlns:   atomNamespace,
+		ID:      FeedID(options.Tag),
+		Title:   title,
+		Updated: formatTime(updated),
+		Author:  atomAuthor{Name: "Star Trek Online", URI: types.ArticleSiteURL},
+		Links:   []atomLink{{Rel: "alternate", Type: "text/html", Href: types.ArticleSiteURL + "/en/news"}},
+		Entries: entries,
+	}
+	if options.SelfURL != "" {
+		doc.Links = append(doc.Links, atomLink{Rel: "self", Type: "application/atom+xml", Href: options.SelfURL})
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to render feed: %v", err)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// renderEntry renders a news item as a feed entry.
+func renderEntry(newsItem types.NewsItem) atomEntry {
+	title := newsItem.Title
+	if title == "" {
+		title = fmt.Sprintf("News %d", newsItem.ID)
+	}
+
+	entry := atomEntry{
+		ID:      EntryID(newsItem.ID),
+		Title:   title,
+		Updated: formatTime(newsItem.Updated),
+		Links:   []atomLink{{Rel: "alternate", Type: "text/html", Href: newsItem.Link()}},
+	}
+	if newsItem.Summary != "" {
+		entry.Summary = &atomText{Type: "text", Body: newsItem.Summary}
+	}
+	for _, tag := range newsItem.Tags {
+		entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+	}
+	return entry
+}
+
+// formatTime formats t in RFC 3339 in UTC, as Atom date constructs require.
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,198 @@
+package feed
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// feedNews returns news items as cached, newest first.
+func feedNews() []types.NewsItem {
+	return []types.NewsItem{
+		{ID: 3, Title: "Patch Notes for Xbox", Summary: "Fixes & <changes>", Tags: []string{"patch-notes-xbox"},
+			Updated: time.Date(2024, 6, 12, 10, 0, 0, 0, time.FixedZone("PDT", -7*3600))},
+		{ID: 2, Title: "Patch Notes", Summary: "Fixes", Tags: []string{"star-trek-online", "patch-notes"},
+			Updated: time.Date(2024, 6, 11, 16, 0, 0, 0, time.UTC), URL: "https://playstartrekonline.com/en/news/article/2-patch-notes"},
+		{ID: 1, Title: "Season Update", Tags: []string{"star-trek-online"},
+			Updated: time.Date(2024, 6, 4, 16, 0, 0, 0, time.UTC)},
+	}
+}
+
+func TestRender(t *testing.T) {
+	data, err := Render(feedNews(), Options{Tag: "star-trek-online", SelfURL: "http://stobot/feed/star-trek-online.xml"})
+	if err != nil {
+		t.Fatalf("Failed to render feed: %v", err)
+	}
+	if !strings.HasPrefix(string(data), xml.Header) {
+		t.Errorf("Expected an XML declaration, got %.60q", data)
+	}
+
+	var doc atomFeed
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Failed to parse rendered feed: %v", err)
+	}
+	if doc.XMLName.Space != atomNamespace || doc.XMLName.Local != "feed" {
+		t.Errorf("Expected an Atom feed element, got %+v", doc.XMLName)
+	}
+	if doc.ID != "tag:playstartrekonline.com,2010:news/tag/star-trek-online" || doc.Title != "Star Trek Online News: star-trek-online" {
+		t.Errorf("Unexpected feed ID %q or title %q", doc.ID, doc.Title)
+	}
+	// The feed is updated when its newest entry was, in UTC
+	if doc.Updated != "2024-06-12T17:00:00Z" {
+		t.Errorf("Expected the feed to be updated at the newest entry, got %q", doc.Updated)
+	}
+	if doc.Author.Name == "" {
+		t.Error("Expected a feed author, which entries inherit")
+	}
+	if len(doc.Links) != 2 || doc.Links[1].Rel != "self" || doc.Links[1].Href != "http://stobot/feed/star-trek-online.xml" {
+		t.Errorf("Expected an alternate and a self link, got %+v", doc.Links)
+	}
+
+	if len(doc.Entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(doc.Entries))
+	}
+	entry := doc.Entries[1]
+	if entry.ID != "tag:playstartrekonline.com,2010:news/2" || entry.ID != EntryID(2) {
+		t.Errorf("Expected a stable entry ID, got %q", entry.ID)
+	}
+	if entry.Updated != "2024-06-11T16:00:00Z" {
+		t.Errorf("Expected an RFC 3339 update time, got %q", entry.Updated)
+	}
+	if _, err := time.Parse(time.RFC3339, doc.Entries[0].Updated); err != nil {
+		t.Errorf("Expected RFC 3339 dates, got %q: %v", doc.Entries[0].Updated, err)
+	}
+	if len(entry.Links) != 1 || entry.Links[0].Href != "https://playstartrekonline.com/en/news/article/2-patch-notes" {
+		t.Errorf("Expected a link to the article, got %+v", entry.Links)
+	}
+	if len(entry.Categories) != 2 || entry.Categories[0].Term != "star-trek-online" || entry.Categories[1].Term != "patch-notes" {
+		t.Errorf("Expected a category per tag, got %+v", entry.Categories)
+	}
+	if summary := doc.Entries[0].Summary; summary == nil || summary.Type != "text" || summary.Body != "Fixes & <changes>" {
+		t.Errorf("Expected the summary as escaped text, got %+v", summary)
+	}
+	if doc.Entries[2].Summary != nil {
+		t.Errorf("Expected no summary element without a summary, got %+v", doc.Entries[2].Summary)
+	}
+
+	// Rendering the same news again gives the same document
+	again, err := Render(feedNews(), Options{Tag: "star-trek-online", SelfURL: "http://stobot/feed/star-trek-online.xml"})
+	if err != nil || string(again) != string(data) {
+		t.Errorf("Expected rendering to be deterministic (%v)", err)
+	}
+}
+
+func TestRenderEmpty(t *testing.T) {
+	updated := time.Date(2024, 6, 11, 12, 0, 0, 0, time.UTC)
+	data, err := Render(nil, Options{Updated: updated})
+	if err != nil {
+		t.Fatalf("Failed to render feed: %v", err)
+	}
+	var doc atomFeed
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Failed to parse rendered feed: %v", err)
+	}
+	if doc.ID != FeedID("") || doc.Updated != "2024-06-11T12:00:00Z" || len(doc.Entries) != 0 {
+		t.Errorf("Unexpected empty feed: %+v", doc)
+	}
+	for _, link := range doc.Links {
+		if link.Rel == "self" {
+			t.Errorf("Expected no self link without a URL, got %+v", link)
+		}
+	}
+}
+
+func TestFeedTag(t *testing.T) {
+	tests := []struct {
+		path string
+		tag  string
+		ok   bool
+	}{
+		{"/feed.xml", "", true},
+		{"/feed/patch-notes.xml", "patch-notes", true},
+		{"/feed/patch-notes", "", false},
+		{"/feed/.xml", "", false},
+		{"/feed/Patch%20Notes.xml", "", false},
+		{"/feed/a/b.xml", "", false},
+		{"/other.xml", "", false},
+	}
+	for _, tt := range tests {
+		tag, ok := feedTag(tt.path)
+		if tag != tt.tag || ok != tt.ok {
+			t.Errorf("feedTag(%q) = %q, %v; want %q, %v", tt.path, tag, ok, tt.tag, tt.ok)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	if err := database.CacheNews(bot, feedNews()); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+
+	handler := NewHandler(bot)
+	start := time.Date(2024, 6, 13, 12, 0, 0, 0, time.UTC)
+	clock := start
+	handler.now = func() time.Time { return clock }
+	server := httptest.NewServer(NewServer("", handler).Handler)
+	defer server.Close()
+
+	get := func(path string) (int, string, *atomFeed) {
+		t.Helper()
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("Failed to get %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK {
+			return resp.StatusCode, resp.Header.Get("Content-Type"), nil
+		}
+		var doc atomFeed
+		if err := xml.Unmarshal(body, &doc); err != nil {
+			t.Fatalf("Failed to parse %s: %v", path, err)
+		}
+		return resp.StatusCode, resp.Header.Get("Content-Type"), &doc
+	}
+
+	status, contentType, doc := get("/feed.xml")
+	if status != http.StatusOK || contentType != "application/atom+xml; charset=utf-8" {
+		t.Fatalf("Expected an Atom feed, got status %d and %q", status, contentType)
+	}
+	if len(doc.Entries) != 3 || doc.Entries[0].ID != EntryID(3) {
+		t.Errorf("Expected the 3 cached news items, newest first, got %+v", doc.Entries)
+	}
+	if doc.Links[len(doc.Links)-1].Href != server.URL+"/feed.xml" {
+		t.Errorf("Expected a self link to the feed, got %+v", doc.Links)
+	}
+
+	// Only the exact tag matches
+	_, _, doc = get("/feed/patch-notes.xml")
+	if len(doc.Entries) != 1 || doc.Entries[0].ID != EntryID(2) {
+		t.Errorf("Expected only news 2 in the patch-notes feed, got %+v", doc.Entries)
+	}
+
+	if status, _, _ := get("/feed/not a tag.xml"); status != http.StatusNotFound {
+		t.Errorf("Expected 404 for an invalid tag, got %d", status)
+	}
+
+	// Feeds are reused for a minute
+	if err := database.CacheNews(bot, []types.NewsItem{{ID: 4, Title: "Dev Blog", Tags: []string{"star-trek-online"}, Updated: start}}); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+	if _, _, doc = get("/feed.xml"); len(doc.Entries) != 3 {
+		t.Errorf("Expected the cached feed within a minute, got %d entries", len(doc.Entries))
+	}
+	clock = start.Add(CacheDuration)
+	if _, _, doc = get("/feed.xml"); len(doc.Entries) != 4 || doc.Entries[0].ID != EntryID(4) {
+		t.Errorf("Expected the feed to be rendered again after a minute, got %+v", doc.Entries)
+	}
+}
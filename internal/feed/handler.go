@@ -0,0 +1,163 @@
+package feed
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// FeedSize is the number of news items in a feed.
+const FeedSize = 50
+
+// CacheDuration is how long a rendered feed is served before it is rendered again.
+const CacheDuration = time.Minute
+
+// tagPattern matches the tags a feed can be requested for.
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// renderedFeed is a rendered feed kept for reuse.
+type renderedFeed struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+// Handler serves the feed of all news at /feed.xml and the feed of news with a tag at
+// /feed/{tag}.xml, from the bot's news cache. Rendered feeds are reused for TTL.
+type Handler struct {
+	Bot *types.Bot    // Bot is the bot whose news cache is served.
+	TTL time.Duration // TTL is how long a rendered feed is reused.
+
+	now func() time.Time // now is the clock feeds expire by (replaced in tests).
+
+	mu    sync.Mutex
+	feeds map[string]renderedFeed // feeds are the rendered feeds by the URL they were requested at.
+}
+
+// NewHandler returns a Handler serving the news cache of b, caching feeds for CacheDuration.
+func NewHandler(b *types.Bot) *Handler {
+	return &Handler{
+		Bot:   b,
+		TTL:   CacheDuration,
+		now:   time.Now,
+		feeds: make(map[string]renderedFeed),
+	}
+}
+
+// Routes returns the handler by path pattern, to add to a mux.
+func (h *Handler) Routes() map[string]http.Handler {
+	return map[string]http.Handler{
+		"/feed.xml": h,
+		"/feed/":    h,
+	}
+}
+
+// ServeHTTP serves a feed.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tag, ok := feedTag(r.URL.Path)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	data, err := h.feed(tag, selfURL(r))
+	if err != nil {
+		log.Errorf("Failed to render feed for tag %q: %v", tag, err)
+		http.Error(w, "failed to render feed", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	_, _ = w.Write(data)
+}
+
+// feedTag returns the tag requested by path, empty for the feed of all news. ok is false for
+// paths that are not a feed.
+func feedTag(path string) (tag string, ok bool) {
+	if path == "/feed.xml" {
+		return "", true
+	}
+	tag, found := strings.CutPrefix(path, "/feed/")
+	if !found {
+		return "", false
+	}
+	tag, found = strings.CutSuffix(tag, ".xml")
+	if !found || !tagPattern.MatchString(tag) {
+		return "", false
+	}
+	return tag, true
+}
+
+// selfURL returns the URL a feed was requested at.
+func selfURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + r.URL.Path
+}
+
+// feed returns the rendered feed of tag served at self, rendering it again if the cached one
+// has expired. Concurrent requests for an expired feed wait for a single render.
+func (h *Handler) feed(tag, self string) ([]byte, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	if cached, ok := h.feeds[self]; ok && now.Before(cached.expiresAt) {
+		return cached.data, nil
+	}
+	for key, cached := range h.feeds {
+		if !now.Before(cached.expiresAt) {
+			delete(h.feeds, key)
+		}
+	}
+
+	var newsItems []types.NewsItem
+	var err error
+	if tag == "" {
+		newsItems, err = database.GetRecentNews(h.Bot, FeedSize)
+	} else {
+		newsItems, err = database.GetRecentNewsWithTag(h.Bot, tag, FeedSize)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if h.Bot.Config != nil {
+		for i := range newsItems {
+			newsItems[i].URL, _ = types.RewriteURL(newsItems[i].Link(), h.Bot.Config.URLRewrites)
+		}
+	}
+
+	data, err := Render(newsItems, Options{Tag: tag, SelfURL: self, Updated: now})
+	if err != nil {
+		return nil, err
+	}
+	h.feeds[self] = renderedFeed{data: data, expiresAt: now.Add(h.TTL)}
+	return data, nil
+}
+
+// NewServer returns an HTTP server for addr serving the feeds of handler.
+func NewServer(addr string, handler *Handler) *http.Server {
+	mux := http.NewServeMux()
+	for pattern, route := range handler.Routes() {
+		mux.Handle(pattern, route)
+	}
+
+	return &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
@@ -0,0 +1,163 @@
+// Package gamestatus checks whether the Star Trek Online game servers are up, using the
+// status endpoint the game launcher reads.
+//
+// Results are cached per endpoint, so repeated /stobot_game_status commands do not hammer it:
+//
+//	status, err := gamestatus.Check(gamestatus.DefaultURL)
+package gamestatus
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultURL is the STO launcher's server status endpoint, used when no URL is configured.
+const DefaultURL = "http://launcher.startrekonline.com/launcher_server_status"
+
+// CacheDuration is how long a checked status is reused before the endpoint is asked again.
+const CacheDuration = 60 * time.Second
+
+// maxResponseSize limits how much of a status response is read.
+const maxResponseSize = 64 * 1024
+
+// State is the state of the game servers.
+type State string
+
+// Server states reported by the status endpoint.
+const (
+	StateUp          State = "up"
+	StateDown        State = "down"
+	StateMaintenance State = "maintenance"
+	StateUnknown     State = "unknown" // StateUnknown is any state the bot does not recognise.
+)
+
+// Status is the result of a status check.
+type Status struct {
+	State     State     // State is the state of the game servers.
+	Message   string    // Message is the launcher's message, e.g. the maintenance schedule; empty if none.
+	CheckedAt time.Time // CheckedAt is when the endpoint was asked.
+}
+
+// statusResponse is the launcher status endpoint's response.
+type statusResponse struct {
+	ServerStatus       string `json:"server_status"`
+	Message            string `json:"message"`
+	MaintenanceMessage string `json:"maintenance_message"`
+}
+
+// Checker checks the status at one endpoint and caches the result, including failures.
+type Checker struct {
+	URL    string        // URL is the status endpoint.
+	Client *http.Client  // Client makes the requests.
+	TTL    time.Duration // TTL is how long a result is reused.
+
+	now func() time.Time // now is the clock results are timed with (replaced in tests).
+
+	mu        sync.Mutex
+	status    Status
+	err       error
+	expiresAt time.Time
+}
+
+// NewChecker returns a Checker for url that caches results for CacheDuration.
+func NewChecker(url string) *Checker {
+	return &Checker{
+		URL:    url,
+		Client: &http.Client{Timeout: 10 * time.Second},
+		TTL:    CacheDuration,
+		now:    time.Now,
+	}
+}
+
+// Check returns the current status, asking the endpoint only if the cached result has expired.
+// Concurrent callers wait for a single request.
+func (c *Checker) Check() (Status, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.now().Before(c.expiresAt) {
+		return c.status, c.err
+	}
+
+	c.status, c.err = c.fetch()
+	c.expiresAt = c.now().Add(c.TTL)
+	return c.status, c.err
+}
+
+// fetch asks the endpoint for the current status.
+func (c *Checker) fetch() (Status, error) {
+	checkedAt := c.now()
+
+	resp, err := c.Client.Get(c.URL)
+	if err != nil {
+		return Status{}, fmt.Errorf("failed to fetch server status: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return Status{}, fmt.Errorf("server status endpoint returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return Status{}, fmt.Errorf("failed to read server status: %v", err)
+	}
+
+	status, err := parseStatus(body)
+	if err != nil {
+		return Status{}, err
+	}
+	status.CheckedAt = checkedAt
+	return status, nil
+}
+
+// parseStatus decodes a status endpoint response.
+func parseStatus(body []byte) (Status, error) {
+	var response statusResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return Status{}, fmt.Errorf("failed to decode server status: %v", err)
+	}
+
+	status := Status{State: StateUnknown, Message: strings.TrimSpace(response.MaintenanceMessage)}
+	if status.Message == "" {
+		status.Message = strings.TrimSpace(response.Message)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(response.ServerStatus)) {
+	case "up", "online":
+		status.State = StateUp
+	case "down", "offline":
+		status.State = StateDown
+	case "maintenance":
+		status.State = StateMaintenance
+	}
+	return status, nil
+}
+
+var (
+	checkersMu sync.Mutex
+	checkers   = make(map[string]*Checker)
+)
+
+// Check returns the status at url, sharing one cached result per URL between callers.
+// An empty url checks DefaultURL.
+func Check(url string) (Status, error) {
+	if url == "" {
+		url = DefaultURL
+	}
+
+	checkersMu.Lock()
+	checker, ok := checkers[url]
+	if !ok {
+		checker = NewChecker(url)
+		checkers[url] = checker
+	}
+	checkersMu.Unlock()
+
+	return checker.Check()
+}
@@ -0,0 +1,125 @@
+package gamestatus
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// statusServer serves body with status and counts the requests it gets.
+func statusServer(t *testing.T, status int, body string) (*httptest.Server, *int32) {
+	t.Helper()
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestCheckerCheck(t *testing.T) {
+	tests := []struct {
+		name            string
+		status          int
+		body            string
+		expectedState   State
+		expectedMessage string
+		expectError     bool
+	}{
+		{name: "up", status: http.StatusOK, body: `{"server_status": "up"}`, expectedState: StateUp},
+		{name: "down", status: http.StatusOK, body: `{"server_status": "DOWN"}`, expectedState: StateDown},
+		{
+			name:            "maintenance",
+			status:          http.StatusOK,
+			body:            `{"server_status": "maintenance", "message": "Servers are down for maintenance until 10:00 PT."}`,
+			expectedState:   StateMaintenance,
+			expectedMessage: "Servers are down for maintenance until 10:00 PT.",
+		},
+		{name: "unknown state", status: http.StatusOK, body: `{"server_status": "loading"}`, expectedState: StateUnknown},
+		{name: "server error", status: http.StatusBadGateway, body: `{}`, expectError: true},
+		{name: "invalid response", status: http.StatusOK, body: `<html>`, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := statusServer(t, tt.status, tt.body)
+
+			status, err := NewChecker(server.URL).Check()
+			if tt.expectError {
+				if err == nil {
+					t.Fatalf("Expected an error, got %+v", status)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to check status: %v", err)
+			}
+			if status.State != tt.expectedState {
+				t.Errorf("Expected state %s, got %s", tt.expectedState, status.State)
+			}
+			if status.Message != tt.expectedMessage {
+				t.Errorf("Expected message %q, got %q", tt.expectedMessage, status.Message)
+			}
+			if status.CheckedAt.IsZero() {
+				t.Error("Expected the check time to be set")
+			}
+		})
+	}
+}
+
+func TestCheckerUnreachable(t *testing.T) {
+	server, _ := statusServer(t, http.StatusOK, `{"server_status": "up"}`)
+	url := server.URL
+	server.Close()
+
+	if _, err := NewChecker(url).Check(); err == nil {
+		t.Fatal("Expected an error for an unreachable endpoint")
+	}
+}
+
+func TestCheckerCache(t *testing.T) {
+	server, requests := statusServer(t, http.StatusOK, `{"server_status": "up"}`)
+
+	clock := time.Date(2024, 6, 11, 12, 0, 0, 0, time.UTC)
+	checker := NewChecker(server.URL)
+	checker.now = func() time.Time { return clock }
+
+	for i := 0; i < 3; i++ {
+		if _, err := checker.Check(); err != nil {
+			t.Fatalf("Failed to check status: %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(requests); got != 1 {
+		t.Errorf("Expected 1 request within the cache duration, got %d", got)
+	}
+
+	clock = clock.Add(CacheDuration)
+	status, err := checker.Check()
+	if err != nil {
+		t.Fatalf("Failed to check status: %v", err)
+	}
+	if got := atomic.LoadInt32(requests); got != 2 {
+		t.Errorf("Expected a new request once the cache expired, got %d requests", got)
+	}
+	if !status.CheckedAt.Equal(clock) {
+		t.Errorf("Expected the status to be checked at %v, got %v", clock, status.CheckedAt)
+	}
+}
+
+func TestCheckSharesCachePerURL(t *testing.T) {
+	server, requests := statusServer(t, http.StatusOK, `{"server_status": "up"}`)
+
+	for i := 0; i < 3; i++ {
+		if _, err := Check(server.URL); err != nil {
+			t.Fatalf("Failed to check status: %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(requests); got != 1 {
+		t.Errorf("Expected 1 request for repeated checks, got %d", got)
+	}
+}
@@ -0,0 +1,123 @@
+// Package logging configures the bot's logrus output and gives each component a logger
+// tagged with its name, so its lines can be filtered and its level raised on its own.
+//
+// Levels are set as a default level followed by per-component overrides:
+//
+//	LOG_LEVEL=info                    everything at info
+//	LOG_LEVEL=warn,news=debug         the news fetcher and poller at debug, the rest at warn
+//	LOG_LEVEL=info,discord=debug      the Discord layer at debug
+package logging
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// The components that log through Logger.
+const (
+	News     = "news"     // News is the news fetcher, poller and poster.
+	Database = "database" // Database is the SQLite cache.
+	Discord  = "discord"  // Discord is the slash command and interaction layer.
+)
+
+// Components are the components whose level can be set on its own.
+var Components = []string{News, Database, Discord}
+
+// The log formats.
+const (
+	FormatJSON = "json" // FormatJSON writes a JSON object per line, for log collectors.
+	FormatText = "text" // FormatText writes human readable lines, for local development.
+)
+
+var (
+	mu      sync.RWMutex
+	loggers = map[string]*log.Logger{} // loggers are the loggers of components with their own level.
+)
+
+// Levels is a parsed level setting.
+type Levels struct {
+	Default    log.Level            // Default is the level of the standard logger and every component without its own.
+	Components map[string]log.Level // Components are the levels of components with their own.
+}
+
+// ParseLevels parses a level setting: a level, optionally followed by component=level
+// overrides, separated by commas. An empty setting is defaultLevel.
+func ParseLevels(spec string, defaultLevel log.Level) (Levels, error) {
+	levels := Levels{Default: defaultLevel, Components: map[string]log.Level{}}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		component, levelName, found := strings.Cut(part, "=")
+		if !found {
+			level, err := log.ParseLevel(part)
+			if err != nil {
+				return levels, fmt.Errorf("invalid log level %q", part)
+			}
+			levels.Default = level
+			continue
+		}
+		component = strings.ToLower(strings.TrimSpace(component))
+		if !slices.Contains(Components, component) {
+			return levels, fmt.Errorf("unknown log component %q (expected one of %s)", component, strings.Join(Components, ", "))
+		}
+		level, err := log.ParseLevel(strings.TrimSpace(levelName))
+		if err != nil {
+			return levels, fmt.Errorf("invalid log level %q for component %s", levelName, component)
+		}
+		levels.Components[component] = level
+	}
+	return levels, nil
+}
+
+// Configure sets the format and levels of the standard logger and the component loggers.
+// Component loggers write to the output of the standard logger.
+func Configure(levels Levels, format string) error {
+	var formatter log.Formatter
+	switch strings.ToLower(format) {
+	case "", FormatJSON:
+		formatter = &log.JSONFormatter{}
+	case FormatText:
+		formatter = &log.TextFormatter{FullTimestamp: true}
+	default:
+		return fmt.Errorf("invalid log format %q (expected %s or %s)", format, FormatJSON, FormatText)
+	}
+
+	log.SetFormatter(formatter)
+	log.SetLevel(levels.Default)
+
+	std := log.StandardLogger()
+	mu.Lock()
+	defer mu.Unlock()
+	loggers = map[string]*log.Logger{}
+	for component, level := range levels.Components {
+		// The component logger shares the hooks of the standard logger, so hooks added
+		// to it later see the component's lines too
+		loggers[component] = &log.Logger{
+			Out:       std.Out,
+			Formatter: formatter,
+			Hooks:     std.Hooks,
+			Level:     level,
+			ExitFunc:  os.Exit,
+		}
+	}
+	return nil
+}
+
+// Logger returns a log entry for component, tagged with a component field. Components
+// without their own level log through the standard logger.
+func Logger(component string) *log.Entry {
+	mu.RLock()
+	logger, ok := loggers[component]
+	mu.RUnlock()
+	if !ok {
+		return log.WithField("component", component)
+	}
+	return logger.WithField("component", component)
+}
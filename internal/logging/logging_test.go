@@ -0,0 +1,95 @@
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestParseLevels(t *testing.T) {
+	tests := []struct {
+		spec       string
+		level      log.Level
+		components map[string]log.Level
+		err        string
+	}{
+		{"", log.InfoLevel, map[string]log.Level{}, ""},
+		{"debug", log.DebugLevel, map[string]log.Level{}, ""},
+		{"warn, News=debug,discord=trace", log.WarnLevel, map[string]log.Level{News: log.DebugLevel, Discord: log.TraceLevel}, ""},
+		{"news=debug", log.InfoLevel, map[string]log.Level{News: log.DebugLevel}, ""},
+		{"loud", log.InfoLevel, nil, "invalid log level"},
+		{"info,poller=debug", log.InfoLevel, nil, "unknown log component"},
+		{"info,news=loud", log.InfoLevel, nil, "invalid log level"},
+	}
+	for _, tt := range tests {
+		levels, err := ParseLevels(tt.spec, log.InfoLevel)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("ParseLevels(%q) error = %v, want %q", tt.spec, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseLevels(%q) failed: %v", tt.spec, err)
+			continue
+		}
+		if levels.Default != tt.level || len(levels.Components) != len(tt.components) {
+			t.Errorf("ParseLevels(%q) = %+v, want %v %v", tt.spec, levels, tt.level, tt.components)
+		}
+		for component, level := range tt.components {
+			if levels.Components[component] != level {
+				t.Errorf("ParseLevels(%q) level of %s = %v, want %v", tt.spec, component, levels.Components[component], level)
+			}
+		}
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	std := log.StandardLogger()
+	out, formatter, level := std.Out, std.Formatter, std.GetLevel()
+	t.Cleanup(func() {
+		log.SetOutput(out)
+		log.SetFormatter(formatter)
+		log.SetLevel(level)
+		mu.Lock()
+		loggers = map[string]*log.Logger{}
+		mu.Unlock()
+	})
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	hook := test.NewGlobal()
+
+	levels, err := ParseLevels("warn,news=debug", log.InfoLevel)
+	if err != nil {
+		t.Fatalf("Failed to parse levels: %v", err)
+	}
+	if err := Configure(levels, FormatText); err != nil {
+		t.Fatalf("Failed to configure logging: %v", err)
+	}
+
+	Logger(News).WithField("news_id", 42).Debug("Fetching news")
+	Logger(Discord).Info("Command received")
+	Logger(Database).Warn("Slow query")
+
+	entries := hook.AllEntries()
+	if len(entries) != 2 {
+		t.Fatalf("Expected the news debug and database warning lines, got %d entries", len(entries))
+	}
+	if entries[0].Data["component"] != News || entries[0].Data["news_id"] != 42 || entries[0].Level != log.DebugLevel {
+		t.Errorf("Unexpected news entry: %v %v", entries[0].Level, entries[0].Data)
+	}
+	if entries[1].Data["component"] != Database {
+		t.Errorf("Expected a database entry, got %v", entries[1].Data)
+	}
+	if !strings.Contains(buf.String(), "component=news") || strings.Contains(buf.String(), "Command received") {
+		t.Errorf("Unexpected text output: %s", buf.String())
+	}
+
+	if err := Configure(levels, "xml"); err == nil {
+		t.Error("Expected an unknown format to be refused")
+	}
+}
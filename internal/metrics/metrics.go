@@ -0,0 +1,214 @@
+// Package metrics exposes STOBot's operational metrics in the Prometheus text format.
+//
+// The metrics are process-wide and always collected; they are only served when the bot is
+// started with --metrics-addr. Example scrape config target: http://stobot:9090/metrics
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// metric is a collector that can write itself in the Prometheus text format.
+type metric interface {
+	write(w io.Writer) error
+}
+
+// registry holds every metric in the order it is exposed.
+var registry []metric
+
+// Metrics collected by the bot.
+var (
+	NewsFetched        = newCounter("stobot_news_fetched_total", "News items fetched from the news API.")
+	FetchErrors        = newCounter("stobot_fetch_errors_total", "News API fetches that failed after retries.")
+	NewsSchemaErrors   = newCounter("stobot_news_schema_errors_total", "News API responses of an unexpected shape or carrying an error object.")
+	NewsPosted         = newCounter("stobot_news_posted_total", "News items posted to Discord channels.")
+	PostFailures       = newCounter("stobot_post_failures_total", "News items that could not be posted to a Discord channel.")
+	PollCycles         = newCounter("stobot_poll_cycles_total", "Poll cycles run.")
+	PollCycleFailures  = newCounter("stobot_poll_cycle_failures_total", "Poll cycles that could not complete.")
+	PollCyclesSkipped  = newCounter("stobot_poll_cycles_skipped_total", "Poll cycles skipped because the previous cycle was still running.")
+	RegisteredChannels = newGauge("stobot_registered_channels", "Registered channels served by this instance at the last poll cycle.")
+	FetchDuration      = newHistogram("stobot_fetch_duration_seconds", "Duration of news API fetches, including retries.",
+		[]float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60})
+)
+
+// Counter is a monotonically increasing value.
+type Counter struct {
+	name  string
+	help  string
+	value atomic.Uint64
+}
+
+func newCounter(name, help string) *Counter {
+	c := &Counter{name: name, help: help}
+	registry = append(registry, c)
+	return c
+}
+
+// Inc increments the counter by one.
+func (c *Counter) Inc() {
+	c.value.Add(1)
+}
+
+// Add increments the counter by n; negative values are ignored.
+func (c *Counter) Add(n int) {
+	if n > 0 {
+		c.value.Add(uint64(n))
+	}
+}
+
+// Value returns the current count.
+func (c *Counter) Value() uint64 {
+	return c.value.Load()
+}
+
+func (c *Counter) write(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", c.name, c.help, c.name, c.name, c.Value())
+	return err
+}
+
+// Gauge is a value that can go up and down.
+type Gauge struct {
+	name  string
+	help  string
+	value atomic.Int64
+}
+
+func newGauge(name, help string) *Gauge {
+	g := &Gauge{name: name, help: help}
+	registry = append(registry, g)
+	return g
+}
+
+// Set sets the gauge to v.
+func (g *Gauge) Set(v int) {
+	g.value.Store(int64(v))
+}
+
+// Value returns the current value.
+func (g *Gauge) Value() int64 {
+	return g.value.Load()
+}
+
+func (g *Gauge) write(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %d\n", g.name, g.help, g.name, g.name, g.Value())
+	return err
+}
+
+// Histogram counts observations in cumulative buckets.
+type Histogram struct {
+	name    string
+	help    string
+	buckets []float64 // buckets are the ascending upper bounds, excluding +Inf.
+
+	mu     sync.Mutex
+	counts []uint64 // counts[i] is the number of observations <= buckets[i].
+	sum    float64
+	count  uint64
+}
+
+func newHistogram(name, help string, buckets []float64) *Histogram {
+	h := &Histogram{name: name, help: help, buckets: buckets, counts: make([]uint64, len(buckets))}
+	registry = append(registry, h)
+	return h
+}
+
+// Observe records one observation.
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i, bound := range h.buckets {
+		if v <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+// ObserveSince records the seconds elapsed since start.
+func (h *Histogram) ObserveSince(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+// Count returns the number of observations.
+func (h *Histogram) Count() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count
+}
+
+func (h *Histogram) write(w io.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name); err != nil {
+		return err
+	}
+	for i, bound := range h.buckets {
+		if _, err := fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", h.name, formatFloat(bound), h.counts[i]); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n%s_sum %s\n%s_count %d\n",
+		h.name, h.count, h.name, formatFloat(h.sum), h.name, h.count)
+	return err
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// Write writes all metrics in the Prometheus text format.
+func Write(w io.Writer) error {
+	for _, m := range registry {
+		if err := m.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handler serves all metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = Write(w)
+	})
+}
+
+// HealthHandler returns 200 when check succeeds and 503 with the error otherwise.
+func HealthHandler(check func() error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if err := check(); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintf(w, "unhealthy: %v\n", err)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+}
+
+// NewServer returns an HTTP server for addr serving /metrics and /healthz, and routes by path
+// pattern, e.g. the news feed when it shares the metrics listener.
+func NewServer(addr string, healthCheck func() error, routes map[string]http.Handler) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	mux.Handle("/healthz", HealthHandler(healthCheck))
+	for pattern, handler := range routes {
+		mux.Handle(pattern, handler)
+	}
+
+	return &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
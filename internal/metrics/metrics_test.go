@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCounterAndGauge(t *testing.T) {
+	c := &Counter{name: "test_total", help: "Test counter."}
+	c.Inc()
+	c.Add(4)
+	c.Add(-2)
+	if c.Value() != 5 {
+		t.Errorf("Expected counter 5, got %d", c.Value())
+	}
+
+	g := &Gauge{name: "test_gauge", help: "Test gauge."}
+	g.Set(7)
+	g.Set(3)
+	if g.Value() != 3 {
+		t.Errorf("Expected gauge 3, got %d", g.Value())
+	}
+}
+
+func TestHistogramWrite(t *testing.T) {
+	h := &Histogram{name: "test_seconds", help: "Test histogram.", buckets: []float64{0.5, 1, 2.5}, counts: make([]uint64, 3)}
+	for _, v := range []float64{0.2, 0.5, 2, 10} {
+		h.Observe(v)
+	}
+
+	var out strings.Builder
+	if err := h.write(&out); err != nil {
+		t.Fatalf("Failed to write histogram: %v", err)
+	}
+
+	expected := `# HELP test_seconds Test histogram.
+# TYPE test_seconds histogram
+test_seconds_bucket{le="0.5"} 2
+test_seconds_bucket{le="1"} 2
+test_seconds_bucket{le="2.5"} 3
+test_seconds_bucket{le="+Inf"} 4
+test_seconds_sum 12.7
+test_seconds_count 4
+`
+	if out.String() != expected {
+		t.Errorf("Unexpected histogram output:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
+
+func TestServer(t *testing.T) {
+	var healthErr error
+	server := httptest.NewServer(NewServer("", func() error { return healthErr }, nil).Handler)
+	defer server.Close()
+
+	get := func(path string) (int, string) {
+		t.Helper()
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("Failed to get %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", path, err)
+		}
+		return resp.StatusCode, string(body)
+	}
+
+	NewsPosted.Inc()
+	status, body := get("/metrics")
+	if status != http.StatusOK {
+		t.Fatalf("Expected 200 from /metrics, got %d", status)
+	}
+	for _, expected := range []string{
+		"# TYPE stobot_news_fetched_total counter",
+		"# TYPE stobot_news_schema_errors_total counter",
+		"# TYPE stobot_registered_channels gauge",
+		"# TYPE stobot_fetch_duration_seconds histogram",
+		"stobot_news_posted_total ",
+		"stobot_fetch_duration_seconds_count ",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected /metrics to contain %q, got:\n%s", expected, body)
+		}
+	}
+
+	if status, body := get("/healthz"); status != http.StatusOK || body != "ok\n" {
+		t.Errorf("Expected a healthy 200, got %d %q", status, body)
+	}
+
+	healthErr = errors.New("database is locked")
+	if status, body := get("/healthz"); status != http.StatusServiceUnavailable || !strings.Contains(body, "database is locked") {
+		t.Errorf("Expected an unhealthy 503, got %d %q", status, body)
+	}
+}
@@ -0,0 +1,171 @@
+package news
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/metrics"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// articleIDPattern matches the news ID in an article URL, e.g. .../news/article/11523743.
+var articleIDPattern = regexp.MustCompile(`/article/(\d+)`)
+
+// ParseArticleID returns the news ID in s, which is either an ID or an article URL.
+func ParseArticleID(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if match := articleIDPattern.FindStringSubmatch(s); match != nil {
+		s = match[1]
+	}
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("%q is not a news ID or article URL", s)
+	}
+	return id, nil
+}
+
+// FetchNewsByID fetches a single news item from the news API. It returns nil if the API
+// does not know the ID.
+func FetchNewsByID(b *types.Bot, id int64) (*types.NewsItem, error) {
+	return FetchLocalizedNewsByID(b, id, types.DefaultLocale)
+}
+
+// FetchLocalizedNewsByID fetches a single news item in a locale from the news API and runs the
+// AfterFetch hooks on it. It returns nil if the API does not know the ID.
+func FetchLocalizedNewsByID(b *types.Bot, id int64, locale string) (*types.NewsItem, error) {
+	start := time.Now()
+	var baseURL string
+	if b.Config != nil {
+		baseURL = b.Config.BaseURL
+	}
+	newsItem, err := fetchNewsItemByID(baseURL, id, locale)
+	metrics.FetchDuration.ObserveSince(start)
+	if err != nil {
+		metrics.FetchErrors.Inc()
+		return nil, err
+	}
+	if newsItem == nil {
+		return nil, nil
+	}
+	metrics.NewsFetched.Inc()
+
+	// Run post-processing hooks (HTML cleanup, tag aliases and any registered extensions)
+	newsItems := DefaultHooks.RunAfterFetch(b.Config, []types.NewsItem{*newsItem})
+	if len(newsItems) == 0 {
+		return nil, nil
+	}
+	return &newsItems[0], nil
+}
+
+// LoadArticle returns a news item with its full content. Cached news without content is fetched
+// from the news API, and the cleaned content is written back to the cache. It returns nil if the
+// news is neither cached nor known to the API.
+func LoadArticle(b *types.Bot, id int64) (*types.NewsItem, error) {
+	newsItem, err := database.GetCachedNewsByID(b, id)
+	if err != nil {
+		return nil, err
+	}
+	if newsItem != nil && newsItem.Content != "" {
+		return newsItem, nil
+	}
+
+	fetched, err := FetchNewsByID(b, id)
+	if err != nil {
+		return nil, err
+	}
+	if fetched == nil {
+		return newsItem, nil
+	}
+
+	newsItems := []types.NewsItem{*fetched}
+	cleanNewsItemContent(newsItems)
+	if err := database.CacheNews(b, newsItems); err != nil {
+		logger().Warnf("Failed to cache content of news %d: %v", id, err)
+	}
+	return &newsItems[0], nil
+}
+
+// fetchNewsItemByID implements FetchLocalizedNewsByID without recording metrics. An empty
+// baseURL uses the Arc Games API.
+func fetchNewsItemByID(baseURL string, id int64, locale string) (*types.NewsItem, error) {
+	fields := []string{"id", "title", "summary", "tags", "platforms", "updated", "images", "content"}
+
+	if baseURL == "" {
+		baseURL = DefaultNewsAPIURL
+	}
+
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+	url := buildNewsURL(fmt.Sprintf("%s/%d", strings.TrimRight(baseURL, "/"), id), "", 0, 0, "", locale, fields)
+	logger().Debugf("Fetching news item from: %s", url)
+
+	body, err := fetchWithRetry(client, url, DefaultRetryConfig())
+	if err != nil {
+		var statusErr *statusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	newsItems, err := parseNewsItemResponse(body)
+	if err != nil {
+		return nil, err
+	}
+	for _, newsItem := range newsItems {
+		if newsItem.ID == id {
+			return &newsItem, nil
+		}
+	}
+	return nil, nil
+}
+
+// parseNewsItemResponse decodes a single-item news API response: a news object, an object
+// with a news field holding one, or any response ParseNewsResponse accepts.
+func parseNewsItemResponse(data []byte) ([]types.NewsItem, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &fields); err != nil {
+			return nil, fmt.Errorf("failed to decode news response: %v", err)
+		}
+		raw := trimmed
+		if news, ok := fields["news"]; ok {
+			raw = bytes.TrimSpace(news)
+		} else if _, ok := fields["id"]; !ok {
+			return ParseNewsResponse(trimmed)
+		}
+		if len(raw) > 0 && raw[0] == '{' {
+			var newsItem types.NewsItem
+			if err := json.Unmarshal(raw, &newsItem); err != nil {
+				return nil, fmt.Errorf("failed to decode news item: %v", err)
+			}
+			return []types.NewsItem{newsItem}, nil
+		}
+	}
+	return ParseNewsResponse(trimmed)
+}
+
+// BuildNewsEmbed builds the embed automatic posts use for a news item: colored by its tags with
+// the configured overrides, spoiler tags applied, URLs rewritten, and a thumbnail that can no
+// longer be loaded dropped or replaced.
+func BuildNewsEmbed(b *types.Bot, newsItem types.NewsItem, spoilerTags []string) *discordgo.MessageEmbed {
+	embed := formatNewsForChannel(newsItem, spoilerTags)
+	if b.Config != nil && len(b.Config.EmbedColors) > 0 {
+		embed.Color = EmbedColor(newsItem, b.Config.EmbedColors)
+	}
+	b.Config.RewriteEmbedURLs(embed)
+	validateThumbnail(b, embed)
+	return embed
+}
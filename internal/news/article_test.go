@@ -0,0 +1,143 @@
+package news
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+func TestParseArticleID(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    int64
+		shouldError bool
+	}{
+		{"11523743", 11523743, false},
+		{" 42 ", 42, false},
+		{"https://playstartrekonline.com/en/news/article/11523743", 11523743, false},
+		{"https://playstartrekonline.com/en/news/article/11523743/summer-event?utm=1", 11523743, false},
+		{"https://playstartrekonline.com/en/news", 0, true},
+		{"season-update", 0, true},
+		{"-5", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			id, err := ParseArticleID(tt.input)
+			if tt.shouldError {
+				if err == nil {
+					t.Errorf("Expected an error, got ID %d", id)
+				}
+				return
+			}
+			if err != nil || id != tt.expected {
+				t.Errorf("Expected ID %d, got %d (%v)", tt.expected, id, err)
+			}
+		})
+	}
+}
+
+func TestFetchNewsByID(t *testing.T) {
+	skipRetryDelays(t)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/news/1":
+			_, _ = w.Write([]byte(`{"news": {"id": 1, "title": "Season Update", "summary": "<p>New season</p>", "tags": ["star-trek-online"]}}`))
+		case "/news/2":
+			_, _ = w.Write([]byte(`{"id": 2, "title": "Patch Notes for 6/11/24"}`))
+		case "/news/3":
+			_, _ = w.Write([]byte(`{"news": [{"id": 3, "title": "Listed"}]}`))
+		case "/news/500":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer api.Close()
+	bot := &types.Bot{Config: &types.Config{BaseURL: api.URL + "/news"}}
+
+	tests := []struct {
+		id          int64
+		title       string
+		shouldError bool
+	}{
+		{1, "Season Update", false},
+		{2, "Patch Notes for 6/11/24", false},
+		{3, "Listed", false},
+		{404, "", false},
+		{500, "", true},
+	}
+	for _, tt := range tests {
+		newsItem, err := FetchNewsByID(bot, tt.id)
+		switch {
+		case tt.shouldError:
+			if err == nil {
+				t.Errorf("News %d: expected an error", tt.id)
+			}
+		case err != nil:
+			t.Errorf("News %d: failed to fetch: %v", tt.id, err)
+		case tt.title == "" && newsItem != nil:
+			t.Errorf("News %d: expected no news, got %+v", tt.id, newsItem)
+		case tt.title != "" && (newsItem == nil || newsItem.Title != tt.title):
+			t.Errorf("News %d: expected %q, got %+v", tt.id, tt.title, newsItem)
+		}
+	}
+}
+
+func TestLoadArticle(t *testing.T) {
+	skipRetryDelays(t)
+	var requests []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		if r.URL.Path == "/news/2" {
+			_, _ = w.Write([]byte(`{"news": {"id": 2, "title": "Patch Notes", "content": "<p>Fixed <b>the</b> Borg.</p><script>x()</script>"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer api.Close()
+
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	bot.Config.BaseURL = api.URL + "/news"
+	if err := database.CacheNews(bot, []types.NewsItem{
+		{ID: 1, Title: "Season Update", Content: "Cached text"},
+		{ID: 2, Title: "Patch Notes"},
+	}); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		id       int64
+		expected string
+		requests int
+	}{
+		{"cached content", 1, "Cached text", 0},
+		{"content fetched live", 2, "Fixed the Borg.", 1},
+		{"fetched content is cached", 2, "Fixed the Borg.", 1},
+		{"unknown news", 3, "", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newsItem, err := LoadArticle(bot, tt.id)
+			if err != nil {
+				t.Fatalf("Failed to load article: %v", err)
+			}
+			if tt.expected == "" {
+				if newsItem != nil {
+					t.Errorf("Expected no article, got %+v", newsItem)
+				}
+			} else if newsItem == nil || newsItem.Content != tt.expected {
+				t.Errorf("Expected content %q, got %+v", tt.expected, newsItem)
+			}
+			if len(requests) != tt.requests {
+				t.Errorf("Expected %d API requests in total, got %v", tt.requests, requests)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package news
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/ratelimit"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// DefaultBackfillRate is the number of news API requests per second BackfillContent makes when
+// the options set no limiter.
+const DefaultBackfillRate = 2
+
+// backfillBatchSize is the number of cached news IDs BackfillContent reads at once.
+const backfillBatchSize = 50
+
+// BackfillOptions controls BackfillContent.
+type BackfillOptions struct {
+	Limit   int                // Limit is the most news items to fetch; 0 fetches all news without content.
+	DryRun  bool               // DryRun fetches the content without writing it.
+	Limiter *ratelimit.Limiter // Limiter paces news API requests; nil uses DefaultBackfillRate.
+}
+
+// BackfillResult reports the outcome of BackfillContent.
+type BackfillResult struct {
+	Checked  int  // Checked is the number of cached news items fetched.
+	Filled   int  // Filled is the number of news items given content, or that would be on a dry run.
+	Missing  int  // Missing is the number of news items unknown to the API or without content there.
+	Failed   int  // Failed is the number of news items that could not be fetched or updated.
+	Canceled bool // Canceled reports that ctx was cancelled before all news was checked.
+}
+
+// BackfillContent fetches the article body of cached news without content, such as news cached
+// before the content column was added, and stores it as plain text, so content searches and
+// /stobot_read find it. News is read in batches, newest first, and fetched one item at a time
+// through the bot's fetcher, paced by the options' limiter; failures are logged and counted, and
+// do not stop the run. Cancelling ctx stops before the next fetch. An error is returned when the
+// cache cannot be read.
+func BackfillContent(ctx context.Context, b *types.Bot, options BackfillOptions) (BackfillResult, error) {
+	limiter := options.Limiter
+	if limiter == nil {
+		limiter = ratelimit.New(DefaultBackfillRate, 1)
+	}
+	fetcher := Fetcher(b)
+
+	var result BackfillResult
+	// beforeID pages past the news left without content by failures and dry runs
+	var beforeID int64
+	for options.Limit <= 0 || result.Checked < options.Limit {
+		batchSize := backfillBatchSize
+		if options.Limit > 0 {
+			batchSize = min(batchSize, options.Limit-result.Checked)
+		}
+		ids, err := database.GetNewsIDsWithoutContent(b, beforeID, batchSize)
+		if err != nil {
+			return result, fmt.Errorf("failed to read cached news: %v", err)
+		}
+
+		for _, id := range ids {
+			if err := limiter.Wait(ctx); err != nil {
+				result.Canceled = true
+				return result, nil
+			}
+			backfillNewsItem(b, fetcher, id, options.DryRun, &result)
+		}
+
+		if len(ids) < batchSize {
+			break
+		}
+		beforeID = ids[len(ids)-1]
+	}
+	return result, nil
+}
+
+// backfillNewsItem fetches the content of a cached news item and stores it, recording the
+// outcome in result.
+func backfillNewsItem(b *types.Bot, fetcher types.NewsFetcher, id int64, dryRun bool, result *BackfillResult) {
+	result.Checked++
+	fetched, err := fetcher.FetchNewsByID(id)
+	if err != nil {
+		logger().Errorf("Failed to fetch news %d: %v", id, err)
+		result.Failed++
+		return
+	}
+	if fetched == nil {
+		logger().Warnf("News %d is no longer known to the news API, leaving it without content", id)
+		result.Missing++
+		return
+	}
+
+	content := extractTextFromHTML(fetched.Content)
+	if content == "" {
+		logger().Warnf("News %d has no content in the news API", id)
+		result.Missing++
+		return
+	}
+	if !dryRun {
+		if err := database.UpdateNewsContent(b, id, content); err != nil {
+			logger().Errorf("Failed to update content of news %d: %v", id, err)
+			result.Failed++
+			return
+		}
+	}
+	result.Filled++
+}
@@ -0,0 +1,121 @@
+package news
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/ratelimit"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+func setupBackfillTest(t *testing.T) (*types.Bot, *testhelpers.FakeNewsFetcher) {
+	t.Helper()
+	bot := testhelpers.CreateTestBot(t)
+	t.Cleanup(func() { bot.DB.Close() })
+	fetcher := testhelpers.NewFakeNewsFetcher(
+		types.NewsItem{ID: 1, Title: "Season Update", Content: "<p>New <b>season</b></p><script>track()</script>"},
+		types.NewsItem{ID: 2, Title: "Patch Notes", Content: "<p>Fixes</p>"},
+		types.NewsItem{ID: 3, Title: "Dev Blog"},
+	)
+	bot.Fetcher = fetcher
+
+	if err := database.CacheNews(bot, []types.NewsItem{
+		{ID: 1, Title: "Season Update", Updated: time.Now()},
+		{ID: 2, Title: "Patch Notes", Content: "Cached fixes", Updated: time.Now()},
+		{ID: 3, Title: "Dev Blog", Updated: time.Now()},
+		{ID: 4, Title: "Removed", Updated: time.Now()},
+	}); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+	return bot, fetcher
+}
+
+func cachedContent(t *testing.T, bot *types.Bot, newsID int64) string {
+	t.Helper()
+	newsItem, err := database.GetCachedNewsByID(bot, newsID)
+	if err != nil {
+		t.Fatalf("Failed to get cached news: %v", err)
+	}
+	return newsItem.Content
+}
+
+func TestBackfillContent(t *testing.T) {
+	bot, fetcher := setupBackfillTest(t)
+	limiter := ratelimit.New(1e6, 1e6)
+
+	// A dry run fetches the content without writing it
+	result, err := BackfillContent(context.Background(), bot, BackfillOptions{DryRun: true, Limiter: limiter})
+	if err != nil {
+		t.Fatalf("Failed to backfill content: %v", err)
+	}
+	if result.Checked != 3 || result.Filled != 1 || result.Missing != 2 || result.Failed != 0 {
+		t.Errorf("Expected 3 checked, 1 filled and 2 missing, got %+v", result)
+	}
+	if content := cachedContent(t, bot, 1); content != "" {
+		t.Errorf("Expected a dry run to leave the content, got %q", content)
+	}
+
+	result, err = BackfillContent(context.Background(), bot, BackfillOptions{Limiter: limiter})
+	if err != nil {
+		t.Fatalf("Failed to backfill content: %v", err)
+	}
+	if result.Filled != 1 {
+		t.Errorf("Expected 1 news item filled, got %+v", result)
+	}
+	if content := cachedContent(t, bot, 1); content != "New season" {
+		t.Errorf("Expected the content as plain text, got %q", content)
+	}
+	if content := cachedContent(t, bot, 2); content != "Cached fixes" {
+		t.Errorf("Expected cached content to be left alone, got %q", content)
+	}
+	for _, call := range fetcher.Calls() {
+		if call.ID == 2 {
+			t.Error("Expected news with content not to be fetched")
+		}
+	}
+
+	// Failures are counted and do not stop the run
+	fetcher.Err = errors.New("source unavailable")
+	result, err = BackfillContent(context.Background(), bot, BackfillOptions{Limiter: limiter})
+	if err != nil {
+		t.Fatalf("Failed to backfill content: %v", err)
+	}
+	if result.Checked != 2 || result.Failed != 2 {
+		t.Errorf("Expected the 2 news items left to fail, got %+v", result)
+	}
+}
+
+func TestBackfillContentLimit(t *testing.T) {
+	bot, fetcher := setupBackfillTest(t)
+
+	result, err := BackfillContent(context.Background(), bot, BackfillOptions{Limit: 2, Limiter: ratelimit.New(1e6, 1e6)})
+	if err != nil {
+		t.Fatalf("Failed to backfill content: %v", err)
+	}
+	if result.Checked != 2 {
+		t.Errorf("Expected 2 news items checked, got %+v", result)
+	}
+	// News is backfilled newest first
+	calls := fetcher.Calls()
+	if len(calls) != 2 || calls[0].ID != 4 || calls[1].ID != 3 {
+		t.Errorf("Expected news 4 and 3 to be fetched, got %+v", calls)
+	}
+}
+
+func TestBackfillContentCancelled(t *testing.T) {
+	bot, _ := setupBackfillTest(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := BackfillContent(ctx, bot, BackfillOptions{Limiter: ratelimit.New(1e6, 1e6)})
+	if err != nil {
+		t.Fatalf("Expected a cancelled run to report its progress, got %v", err)
+	}
+	if !result.Canceled || result.Checked != 0 {
+		t.Errorf("Expected a cancelled run that checked nothing, got %+v", result)
+	}
+}
@@ -1,77 +1,228 @@
 package news
 
 import (
+	"context"
+	"fmt"
 	"time"
 
 	"github.com/FracKenA/sto_news_discord_bot/internal/database"
 	"github.com/FracKenA/sto_news_discord_bot/internal/types"
-	log "github.com/sirupsen/logrus"
 )
 
-// CatchUpUnpostedNews posts any unposted news items from the last N days to all registered channels.
-func CatchUpUnpostedNews(b *types.Bot, days int) {
-	// Only get channels that match the current environment
-	var channels []string
-	var err error
-	if b.Config.Environment != "" {
-		channels, err = database.GetChannelsByEnvironment(b, b.Config.Environment)
+// DefaultCatchUpDays is the default catch-up window at startup.
+const DefaultCatchUpDays = 7
+
+// catchUpTags are the news tags fetched by the catch-up.
+var catchUpTags = []string{"star-trek-online", "patch-notes"}
+
+// catchUpAction is what the catch-up does with an unposted news item in a channel.
+type catchUpAction int
+
+const (
+	catchUpPost     catchUpAction = iota // Post the item.
+	catchUpExclude                       // Mark the item as posted without sending; it has an excluded tag.
+	catchUpToDigest                      // Collect the item for the channel's weekly digest.
+	catchUpDefer                         // Queue the item for a later poll; the channel reached its posts per cycle.
+)
+
+// catchUpStep is one planned catch-up action.
+type catchUpStep struct {
+	cfg    database.ChannelConfig
+	item   types.NewsItem
+	action catchUpAction
+}
+
+// CatchUpPost is a news item the catch-up would post to a channel.
+type CatchUpPost struct {
+	NewsID    int64  // NewsID is the news item to post.
+	Title     string // Title is the news item's title.
+	ChannelID string // ChannelID is the channel to post to.
+}
+
+// fetchCatchUpNews fetches the news considered by the catch-up, each item once.
+// Tags that fail to fetch are logged and skipped.
+func fetchCatchUpNews(b *types.Bot) []types.NewsItem {
+	var newsItems []types.NewsItem
+	seen := make(map[int64]bool)
+	for _, tag := range catchUpTags {
+		items, err := FetchNews(b, tag, b.Config.PollCount*10, BulkFetchOptions())
 		if err != nil {
-			log.Errorf("[catchup] Failed to get channels for environment %s: %v", b.Config.Environment, err)
-			return
+			logger().Errorf("[catchup] Failed to fetch news for tag %s: %v", tag, err)
+			continue
 		}
-	} else {
-		// If no environment is set, get all channels (backwards compatibility)
-		channels, err = database.GetRegisteredChannels(b)
-		if err != nil {
-			log.Errorf("[catchup] Failed to get registered channels: %v", err)
-			return
+		for _, item := range items {
+			if !seen[item.ID] {
+				seen[item.ID] = true
+				newsItems = append(newsItems, item)
+			}
 		}
 	}
-	if len(channels) == 0 {
-		log.Info("[catchup] No registered channels found, skipping catch-up.")
-		return
+	return newsItems
+}
+
+// planCatchUp returns what the catch-up would do with the news items that are not yet posted to
+// each active channel and were released on the channel's platforms after cutoff, oldest first.
+// Posts over a channel's posts per cycle limit are deferred. It does not send or mark anything.
+func planCatchUp(ctx context.Context, b *types.Bot, newsItems []types.NewsItem, cutoff time.Time) ([]catchUpStep, error) {
+	var steps []catchUpStep
+	poster := Poster(b)
+
+	// Only visits channels that match the current environment (all channels if none is set)
+	err := database.ForEachActiveChannel(b, func(cfg database.ChannelConfig) error {
+		if isSettingUp(cfg.ID) {
+			return nil
+		}
+		quiet := cfg.InQuietHours(now())
+		maxPosts, posts := maxPostsPerCycle(b, cfg), 0
+		for _, newsItem := range sortOldestFirst(filterNewsByTags(filterNewsByPlatforms(newsItems, cfg.Platforms), cfg.Tags)) {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if newsItem.ReleasedFor(cfg.Platforms).Before(cutoff) {
+				continue
+			}
+			posted, err := poster.IsPosted(b, newsItem.ID, cfg.ID)
+			if err != nil {
+				logger().Errorf("[catchup] Failed to check posted for news %d: %v", newsItem.ID, err)
+				continue
+			}
+			if posted {
+				continue
+			}
+
+			switch {
+			case isFutureDated(b, newsItem):
+				continue // Left for a poll once it is due
+			case hasExcludedTag(newsItem, cfg.ExcludedTags):
+				steps = append(steps, catchUpStep{cfg: cfg, item: newsItem, action: catchUpExclude})
+			case !matchesStrictPatchNotes(cfg, newsItem):
+				continue
+			case cfg.Paused:
+				continue // Left for the poll, which skips or holds it
+			case cfg.Digest:
+				steps = append(steps, catchUpStep{cfg: cfg, item: newsItem, action: catchUpToDigest})
+			case quiet:
+				continue // Left for the first poll after the channel's quiet hours
+			case maxPosts > 0 && posts >= maxPosts:
+				steps = append(steps, catchUpStep{cfg: cfg, item: newsItem, action: catchUpDefer})
+			default:
+				posts++
+				steps = append(steps, catchUpStep{cfg: cfg, item: newsItem, action: catchUpPost})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	tags := []string{"star-trek-online", "patch-notes"}
+	return steps, nil
+}
+
+// PlanCatchUp fetches the news from the last days days and returns the posts the catch-up
+// would send, without sending or marking anything. Items later found in a channel's recent
+// messages or skipped by a BeforePost hook are still listed.
+func PlanCatchUp(ctx context.Context, b *types.Bot, days int) ([]CatchUpPost, error) {
 	cutoff := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+	steps, err := planCatchUp(ctx, b, fetchCatchUpNews(b), cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan catch-up: %v", err)
+	}
 
-	for _, tag := range tags {
-		newsItems, err := FetchNews(b, tag, b.Config.PollCount*10, BulkFetchOptions())
-		if err != nil {
-			log.Errorf("[catchup] Failed to fetch news for tag %s: %v", tag, err)
+	var posts []CatchUpPost
+	for _, step := range steps {
+		if step.action == catchUpPost {
+			posts = append(posts, CatchUpPost{NewsID: step.item.ID, Title: step.item.Title, ChannelID: step.cfg.ID})
+		}
+	}
+	return posts, nil
+}
+
+// CatchUpUnpostedNews posts any unposted news items from the last N days to all registered channels
+// and returns how many were posted. Each channel is sent at most its posts per cycle, oldest
+// first; the rest is queued for the following polls. Cancelling ctx stops the catch-up before
+// the next post.
+//
+// News is posted and marked as posted by the bot's Poster, as by the poller; a dry run does not
+// queue news.
+func CatchUpUnpostedNews(ctx context.Context, b *types.Bot, days int) (int, error) {
+	cutoff := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+	newsItems := fetchCatchUpNews(b)
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	steps, err := planCatchUp(ctx, b, newsItems, cutoff)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return 0, ctxErr
+		}
+		return 0, fmt.Errorf("failed to list registered channels: %v", err)
+	}
+
+	poster := Poster(b)
+	posted := 0
+	disabled := make(map[string]bool) // Channels disabled by failed posts during the catch-up
+	for _, step := range steps {
+		if err := ctx.Err(); err != nil {
+			return posted, err
+		}
+
+		cfg, newsItem, channelID := step.cfg, step.item, step.cfg.ID
+		if disabled[channelID] {
 			continue
 		}
-		for _, channelID := range channels {
-			platforms, err := database.GetChannelPlatforms(b, channelID)
-			if err != nil {
-				log.Errorf("[catchup] Failed to get platforms for channel %s: %v", channelID, err)
+		switch step.action {
+		case catchUpExclude:
+			if err := poster.MarkPosted(b, newsItem, channelID, ""); err != nil {
+				logger().Errorf("[catchup] Failed to mark excluded news %d as posted: %v", newsItem.ID, err)
+			}
+			continue
+		case catchUpToDigest:
+			if err := poster.MarkPosted(b, newsItem, channelID, database.DeliveryDigest); err != nil {
+				logger().Errorf("[catchup] Failed to collect news %d for the digest of channel %s: %v", newsItem.ID, channelID, err)
+			}
+			continue
+		case catchUpDefer:
+			if b.Config.DryRun {
 				continue
 			}
-			filteredNews := filterNewsByPlatforms(newsItems, platforms)
-			for _, newsItem := range filteredNews {
-				if newsItem.Updated.Before(cutoff) {
-					continue
-				}
-				posted, err := database.IsNewsPosted(b, newsItem.ID, channelID)
-				if err != nil {
-					log.Errorf("[catchup] Failed to check posted for news %d: %v", newsItem.ID, err)
-					continue
-				}
-				if posted {
-					continue
-				}
-				if IsDuplicateInRecentMessages(b, channelID, newsItem) {
-					continue
-				}
-				if err := PostNewsToChannel(b, channelID, newsItem); err != nil {
-					log.Errorf("[catchup] Failed to post news %d to channel %s: %v", newsItem.ID, channelID, err)
-					continue
-				}
-				if err := database.MarkNewsAsPosted(b, newsItem.ID, channelID); err != nil {
-					log.Errorf("[catchup] Failed to mark news %d as posted: %v", newsItem.ID, err)
-				}
-				log.Infof("[catchup] Posted news item %d ('%s') to channel %s", newsItem.ID, newsItem.Title, channelID)
+			// Cached, as queued news is read from the cache
+			if err := database.CacheNews(b, []types.NewsItem{newsItem}); err != nil {
+				logger().Errorf("[catchup] Failed to cache deferred news %d: %v", newsItem.ID, err)
+			}
+			deferPost(b, channelID, newsItem.ID)
+			continue
+		}
+
+		// Checked when posting, so copies among the caught-up news are posted once
+		if isRepublished(b, channelID, newsItem) || isDuplicatePost(b, cfg, newsItem) {
+			if err := poster.MarkPosted(b, newsItem, channelID, ""); err != nil {
+				logger().Errorf("[catchup] Failed to mark duplicate news %d as posted: %v", newsItem.ID, err)
+			}
+			continue
+		}
+		newsItem, skip := DefaultHooks.RunBeforePost(cfg, localizeNewsItem(b, cfg.Locale, newsItem))
+		if skip {
+			continue
+		}
+		sent, err := poster.Post(ctx, b, cfg, newsItem, database.DeliveryCatchUp)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return posted, ctxErr
+			}
+			logger().Errorf("[catchup] Failed to post news %d to channel %s: %v", newsItem.ID, channelID, err)
+			if recordPostFailure(b, channelID, err) {
+				disabled[channelID] = true
 			}
+			continue
+		}
+		if !sent {
+			continue
 		}
+		logger().Infof("[catchup] Posted news item %d ('%s') to channel %s", newsItem.ID, newsItem.Title, channelID)
+		posted++
 	}
+
+	return posted, nil
 }
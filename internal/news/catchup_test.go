@@ -0,0 +1,216 @@
+package news
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestPlanCatchUp(t *testing.T) {
+	newsItems := append(pollCycleNews(),
+		types.NewsItem{ID: 3, Title: "Old News", Tags: []string{"star-trek-online"}, Updated: time.Now().Add(-30 * 24 * time.Hour)})
+	bot, fake := setupPollCycleTest(t, newsItems, "channel-a", "channel-b", "channel-c", "channel-d")
+	if err := database.MarkNewsAsPosted(bot, 1, "channel-a"); err != nil {
+		t.Fatalf("Failed to mark news as posted: %v", err)
+	}
+	if err := database.UpdateChannelDigest(bot, "channel-c", true, time.Monday, 9); err != nil {
+		t.Fatalf("Failed to update digest: %v", err)
+	}
+	if err := database.UpdateChannelQuietHours(bot, "channel-d", true, 22, 7); err != nil {
+		t.Fatalf("Failed to update quiet hours: %v", err)
+	}
+	originalNow := now
+	// 03:00 tomorrow, after the news was released
+	tomorrow := time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+	now = func() time.Time { return tomorrow.Add(3 * time.Hour) }
+	t.Cleanup(func() { now = originalNow })
+
+	posts, err := PlanCatchUp(context.Background(), bot, 7)
+	if err != nil {
+		t.Fatalf("Failed to plan catch-up: %v", err)
+	}
+
+	// Old, already posted, digest and quiet hours news is not planned; each item is planned once
+	planned := make(map[string]int)
+	for _, post := range posts {
+		planned[fmt.Sprintf("%d/%s", post.NewsID, post.ChannelID)]++
+	}
+	expected := []string{"2/channel-a", "1/channel-b", "2/channel-b"}
+	if len(posts) != len(expected) {
+		t.Errorf("Expected %d planned posts, got %+v", len(expected), posts)
+	}
+	for _, key := range expected {
+		if planned[key] != 1 {
+			t.Errorf("Expected %s to be planned once, got %d", key, planned[key])
+		}
+	}
+
+	// Planning sends and marks nothing
+	if len(fake.Requests()) != 0 {
+		t.Errorf("Expected no Discord requests, got %d", len(fake.Requests()))
+	}
+	if posted, _ := database.IsNewsPosted(bot, 2, "channel-a"); posted {
+		t.Error("Expected news 2 not to be marked as posted")
+	}
+
+	count, err := CatchUpUnpostedNews(context.Background(), bot, 7)
+	if err != nil {
+		t.Fatalf("Catch-up failed: %v", err)
+	}
+	if count != len(expected) {
+		t.Errorf("Expected %d posts, got %d", len(expected), count)
+	}
+	if calls := fake.RequestsTo("POST", "/channels/channel-c/messages"); len(calls) != 0 {
+		t.Errorf("Expected no posts to the digest channel, got %d", len(calls))
+	}
+	if posts, err = PlanCatchUp(context.Background(), bot, 7); err != nil || len(posts) != 0 {
+		t.Errorf("Expected nothing left to catch up, got %+v (%v)", posts, err)
+	}
+}
+
+func TestCatchUpWithNewsFetcher(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, nil, "channel-a")
+	fetcher := testhelpers.NewFakeNewsFetcher(pollCycleNews()...)
+	bot.Fetcher = fetcher
+
+	count, err := CatchUpUnpostedNews(context.Background(), bot, 7)
+	if err != nil {
+		t.Fatalf("Catch-up failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 posts, got %d", count)
+	}
+	if calls := fake.RequestsTo("POST", "/channels/channel-a/messages"); len(calls) != 2 {
+		t.Errorf("Expected 2 posts to channel-a, got %d", len(calls))
+	}
+
+	calls := fetcher.Calls()
+	if len(calls) != len(catchUpTags) {
+		t.Fatalf("Expected a fetch per catch-up tag, got %+v", calls)
+	}
+	for n, call := range calls {
+		if call.Tag != catchUpTags[n] || call.Count != bot.Config.PollCount*10 {
+			t.Errorf("Expected %d news with tag %s, got %+v", bot.Config.PollCount*10, catchUpTags[n], call)
+		}
+	}
+
+	// A failing source posts nothing
+	fetcher.Err = fmt.Errorf("source unavailable")
+	if count, err := CatchUpUnpostedNews(context.Background(), bot, 7); err != nil || count != 0 {
+		t.Errorf("Expected nothing posted when fetching fails, got %d (%v)", count, err)
+	}
+}
+
+func TestCatchUpUnpostedNewsMaxPostsPerCycle(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, backlogNews(50), "channel-a")
+	bot.Config.MaxPostsPerCycle = 10
+
+	posts, err := PlanCatchUp(context.Background(), bot, 7)
+	if err != nil {
+		t.Fatalf("Failed to plan catch-up: %v", err)
+	}
+	if len(posts) != 10 || posts[0].NewsID != 1 || posts[9].NewsID != 10 {
+		t.Errorf("Expected the oldest 10 news planned, got %+v", posts)
+	}
+
+	count, err := CatchUpUnpostedNews(context.Background(), bot, 7)
+	if err != nil {
+		t.Fatalf("Catch-up failed: %v", err)
+	}
+	if count != 10 || len(fake.RequestsTo("POST", "/channels/channel-a/messages")) != 10 {
+		t.Errorf("Expected 10 posts, got %d", count)
+	}
+	if held, err := database.GetHeldNews(bot, "channel-a"); err != nil || len(held) != 40 || held[0].ID != 11 {
+		t.Errorf("Expected the other 40 news queued from news 11, got %d (%v)", len(held), err)
+	}
+
+	// Polls deliver the rest, at most 10 per cycle
+	for cycle := 0; cycle < 4; cycle++ {
+		summary, err := RunPollCycle(context.Background(), bot)
+		if err != nil {
+			t.Fatalf("Poll cycle failed: %v", err)
+		}
+		if summary.Posted != 10 {
+			t.Errorf("Expected 10 posts in cycle %d, got %+v", cycle+1, summary)
+		}
+	}
+	titles := postedTitles(t, fake)
+	if len(titles) != 50 || titles[0] != "News 1" || titles[49] != "News 50" {
+		t.Errorf("Expected all 50 news posted oldest first, got %v", titles)
+	}
+}
+
+func TestCatchUpUnpostedNewsDisablesDeadChannel(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, pollCycleNews(), "channel-a", "channel-b")
+	bot.Config.SkipDuplicateCheck = true
+	bot.Config.DisableAfterFailures = 1
+	fake.Handle("POST", "/channels/channel-a/messages", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Unknown Channel", "code": discordgo.ErrCodeUnknownChannel})
+	})
+
+	count, err := CatchUpUnpostedNews(context.Background(), bot, 7)
+	if err != nil {
+		t.Fatalf("Catch-up failed: %v", err)
+	}
+
+	// The deleted channel is disabled by its first failed post and not posted to again
+	if calls := fake.RequestsTo("POST", "/channels/channel-a/messages"); len(calls) != 1 {
+		t.Errorf("Expected 1 post to the deleted channel, got %d", len(calls))
+	}
+	gone, err := database.GetChannelConfig(bot, "channel-a")
+	if err != nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if !gone.Disabled {
+		t.Errorf("Expected the deleted channel to be disabled, got %+v", gone)
+	}
+
+	// The other channel still gets its posts
+	if count != 2 {
+		t.Errorf("Expected 2 posts, got %d", count)
+	}
+	if calls := fake.RequestsTo("POST", "/channels/channel-b/messages"); len(calls) != 2 {
+		t.Errorf("Expected 2 posts to the healthy channel, got %d", len(calls))
+	}
+}
+
+func TestCatchUpUnpostedNewsDryRun(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, pollCycleNews(), "channel-a")
+	bot.Config.DryRun = true
+
+	count, err := CatchUpUnpostedNews(context.Background(), bot, 7)
+	if err != nil {
+		t.Fatalf("Catch-up failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 posts, got %d", count)
+	}
+	// A repeated dry run does not log the posts again
+	if count, err := CatchUpUnpostedNews(context.Background(), bot, 7); err != nil || count != 0 {
+		t.Errorf("Expected nothing posted by a second dry run, got %d (%v)", count, err)
+	}
+	if requests := fake.Requests(); len(requests) != 0 {
+		t.Errorf("Expected no Discord requests, got %d", len(requests))
+	}
+
+	for _, newsID := range []int64{1, 2} {
+		var delivery string
+		if err := bot.DB.QueryRow(`SELECT delivery FROM dry_run_posted WHERE news_id = ? AND channel_id = ?`, newsID, "channel-a").Scan(&delivery); err != nil {
+			t.Fatalf("Expected news %d recorded as posted by the dry run: %v", newsID, err)
+		}
+		if delivery != database.DeliveryCatchUp {
+			t.Errorf("Expected news %d recorded as caught up, got %q", newsID, delivery)
+		}
+		if posted, err := database.IsNewsPosted(bot, newsID, "channel-a"); err != nil || posted {
+			t.Errorf("Expected news %d left for the real bot, got %v (%v)", newsID, posted, err)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package news
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// DefaultDisableAfterFailures is how many consecutive posts to a channel may fail because it was
+// deleted or the bot lost access before the channel is disabled.
+const DefaultDisableAfterFailures = 5
+
+// isPermanentPostError reports whether a post failed because the channel is gone (404 Unknown
+// Channel) or the bot may not post there (403 Missing Access or Missing Permissions), which
+// retrying on the next poll will not fix.
+func isPermanentPostError(err error) bool {
+	var restErr *discordgo.RESTError
+	if !errors.As(err, &restErr) || restErr.Response == nil {
+		return false
+	}
+	return restErr.Response.StatusCode == http.StatusForbidden || restErr.Response.StatusCode == http.StatusNotFound
+}
+
+// recordPostFailure counts a post to a channel that failed permanently, and reports whether the
+// channel was disabled by it, in which case the caller stops posting to the channel.
+func recordPostFailure(b *types.Bot, channelID string, err error) bool {
+	if !isPermanentPostError(err) {
+		return false
+	}
+	disabled, dbErr := database.RecordChannelPostFailure(b, channelID, b.Config.DisableAfterFailures)
+	if dbErr != nil {
+		channelLogger(channelID).Errorf("Failed to record failed post to channel %s: %v", channelID, dbErr)
+		return false
+	}
+	if disabled {
+		channelLogger(channelID).Warnf("Disabled channel %s after %d consecutive failed posts (last error: %v); "+
+			"re-enable it with 'stobot channels enable %s' once the bot can post there again",
+			channelID, b.Config.DisableAfterFailures, err, channelID)
+	}
+	return disabled
+}
+
+// recordPostSuccess clears the count of failed posts of a channel that was posted to.
+func recordPostSuccess(b *types.Bot, channelID string) {
+	if err := database.ResetChannelPostFailures(b, channelID); err != nil {
+		channelLogger(channelID).Errorf("Failed to reset failed posts of channel %s: %v", channelID, err)
+	}
+}
@@ -0,0 +1,136 @@
+package news
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestIsPermanentPostError(t *testing.T) {
+	restError := func(status int) error {
+		return &discordgo.RESTError{Response: &http.Response{StatusCode: status}}
+	}
+
+	tests := []struct {
+		name      string
+		err       error
+		permanent bool
+	}{
+		{name: "unknown channel", err: restError(http.StatusNotFound), permanent: true},
+		{name: "missing access", err: restError(http.StatusForbidden), permanent: true},
+		{name: "bad request", err: restError(http.StatusBadRequest)},
+		{name: "server error", err: restError(http.StatusInternalServerError)},
+		{name: "network error", err: errors.New("connection reset")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if permanent := isPermanentPostError(tt.err); permanent != tt.permanent {
+				t.Errorf("Expected permanent=%v, got %v", tt.permanent, permanent)
+			}
+		})
+	}
+}
+
+func TestRunPollCycleDisablesDeadChannels(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, pollCycleNews(), "channel-a", "channel-gone", "channel-invalid")
+	bot.Config.SkipDuplicateCheck = true
+	bot.Config.DisableAfterFailures = 3
+	fake.Handle("POST", "/channels/channel-gone/messages", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Unknown Channel", "code": discordgo.ErrCodeUnknownChannel})
+	})
+	fake.Handle("POST", "/channels/channel-invalid/messages", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusBadRequest, map[string]interface{}{"message": "Invalid Form Body", "code": 50035})
+	})
+
+	// Both news fail on each cycle, so the third failed post disables the channel on the second
+	for cycle := 1; cycle <= 3; cycle++ {
+		if _, err := RunPollCycle(context.Background(), bot); err != nil {
+			t.Fatalf("Poll cycle %d failed: %v", cycle, err)
+		}
+	}
+
+	if calls := fake.RequestsTo("POST", "/channels/channel-gone/messages"); len(calls) != 3 {
+		t.Errorf("Expected 3 posts to the deleted channel before it was disabled, got %d", len(calls))
+	}
+	gone, err := database.GetChannelConfig(bot, "channel-gone")
+	if err != nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if !gone.Disabled {
+		t.Errorf("Expected the deleted channel to be disabled, got %+v", gone)
+	}
+
+	// Errors other than 403 and 404 are retried on every cycle
+	invalid, err := database.GetChannelConfig(bot, "channel-invalid")
+	if err != nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if invalid.Disabled || invalid.PostFailures != 0 {
+		t.Errorf("Expected a channel with other errors to stay enabled, got %+v", invalid)
+	}
+	if calls := fake.RequestsTo("POST", "/channels/channel-invalid/messages"); len(calls) != 6 {
+		t.Errorf("Expected 6 posts to the channel with other errors, got %d", len(calls))
+	}
+
+	if calls := fake.RequestsTo("POST", "/channels/channel-a/messages"); len(calls) != 2 {
+		t.Errorf("Expected 2 posts to the healthy channel, got %d", len(calls))
+	}
+}
+
+func TestRunPollCycleResetsPostFailures(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, pollCycleNews(), "channel-a")
+	bot.Config.SkipDuplicateCheck = true
+	bot.Config.DisableAfterFailures = 3
+
+	// One post fails while the bot lacks access, the next one succeeds
+	failures := 1
+	fake.Handle("POST", "/channels/channel-a/messages", func(w http.ResponseWriter, r *http.Request) {
+		if failures > 0 {
+			failures--
+			testhelpers.RespondJSON(w, http.StatusForbidden, map[string]interface{}{"message": "Missing Access", "code": discordgo.ErrCodeMissingAccess})
+			return
+		}
+		testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "message-1", "channel_id": "channel-a"})
+	})
+
+	if _, err := RunPollCycle(context.Background(), bot); err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+
+	cfg, err := database.GetChannelConfig(bot, "channel-a")
+	if err != nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if cfg.Disabled || cfg.PostFailures != 0 {
+		t.Errorf("Expected a successful post to reset the failures, got %+v", cfg)
+	}
+}
+
+func TestPostNewsManuallyResetsPostFailures(t *testing.T) {
+	bot, _ := setupPollCycleTest(t, nil, "channel-a")
+	if _, err := database.RecordChannelPostFailure(bot, "channel-a", 3); err != nil {
+		t.Fatalf("Failed to record post failure: %v", err)
+	}
+
+	if err := PostNewsManually(bot, "channel-a", pollCycleNews()[0]); err != nil {
+		t.Fatalf("Failed to post news: %v", err)
+	}
+
+	cfg, err := database.GetChannelConfig(bot, "channel-a")
+	if err != nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if cfg.PostFailures != 0 {
+		t.Errorf("Expected a manual post to reset the failures, got %d", cfg.PostFailures)
+	}
+	if posted, err := database.IsNewsPosted(bot, 1, "channel-a"); err != nil || !posted {
+		t.Errorf("Expected the news to be marked as posted, got %v (%v)", posted, err)
+	}
+}
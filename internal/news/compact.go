@@ -0,0 +1,59 @@
+package news
+
+import (
+	"strings"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// maxMessageLength is Discord's limit on message content, in characters.
+const maxMessageLength = 2000
+
+// maxCompactTitle is the longest title of a compact news post, in characters. Together with
+// maxCompactLink it keeps compact posts, escaped and with a role mention, within maxMessageLength.
+const maxCompactTitle = 256
+
+// maxCompactLink is the longest article link of a compact news post, in bytes; longer links are
+// left out rather than cut.
+const maxCompactLink = 512
+
+// DefaultTagEmoji are the emoji compact news posts start with per news tag.
+var DefaultTagEmoji = map[string]string{
+	"patch-notes": "🩹",
+	"events":      "🎉",
+	"dev-blogs":   "✍️",
+}
+
+// defaultTagEmoji starts compact news posts without a tag that has an emoji of its own.
+const defaultTagEmoji = "📰"
+
+// compactMarkdown escapes the characters of an article title Discord would read as markdown.
+var compactMarkdown = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `_`, `\_`, `~`, `\~`, "`", "\\`", `|`, `\|`)
+
+// formatNewsCompact formats a news item as a single-line message for channels using the compact
+// message style, e.g. "🩹 **Patch Notes for 6/11/24** — <https://...>". The link is wrapped in
+// angle brackets so Discord shows no preview. Long titles are shortened, and the message is never
+// longer than maxMessageLength.
+func formatNewsCompact(newsItem types.NewsItem) string {
+	title := strings.Join(strings.Fields(newsItem.Title), " ")
+	if title == "" {
+		title = "Star Trek Online News"
+	}
+	message := tagEmoji(newsItem) + " **" + compactMarkdown.Replace(types.TruncateText(title, maxCompactTitle)) + "**"
+
+	if link := newsItem.Link(); len(link) <= maxCompactLink {
+		message += " — <" + link + ">"
+	}
+	return message
+}
+
+// tagEmoji returns the emoji of a news item's first tag that has one in DefaultTagEmoji, or the
+// default emoji.
+func tagEmoji(newsItem types.NewsItem) string {
+	for _, tag := range newsItem.Tags {
+		if emoji, ok := DefaultTagEmoji[strings.ToLower(strings.TrimSpace(tag))]; ok {
+			return emoji
+		}
+	}
+	return defaultTagEmoji
+}
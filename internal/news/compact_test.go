@@ -0,0 +1,122 @@
+package news
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestFormatNewsCompact(t *testing.T) {
+	tests := []struct {
+		name     string
+		newsItem types.NewsItem
+		expected string
+	}{
+		{
+			name:     "patch notes",
+			newsItem: types.NewsItem{ID: 1, Title: "Patch Notes 2024-05-02", Tags: []string{"star-trek-online", "patch-notes"}},
+			expected: "🩹 **Patch Notes 2024-05-02** — <https://playstartrekonline.com/en/news/article/1>",
+		},
+		{
+			name:     "markdown in the title",
+			newsItem: types.NewsItem{ID: 2, Title: "  The *Big*\n_Event_ ", Tags: []string{"Events"}, URL: "https://example.com/event"},
+			expected: "🎉 **The \\*Big\\* \\_Event\\_** — <https://example.com/event>",
+		},
+		{
+			name:     "no title or tag",
+			newsItem: types.NewsItem{ID: 3},
+			expected: "📰 **Star Trek Online News** — <https://playstartrekonline.com/en/news/article/3>",
+		},
+		{
+			name:     "link too long",
+			newsItem: types.NewsItem{ID: 4, Title: "Dev Blog", URL: "https://example.com/" + strings.Repeat("a", maxCompactLink)},
+			expected: "📰 **Dev Blog**",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if message := formatNewsCompact(tt.newsItem); message != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, message)
+			}
+		})
+	}
+}
+
+func TestFormatNewsCompactLength(t *testing.T) {
+	long := types.NewsItem{ID: 1, Title: strings.Repeat("Patch Notes ", 100), Tags: []string{"patch-notes"}}
+	message := formatNewsCompact(long)
+	if !strings.Contains(message, "...**") {
+		t.Errorf("Expected the long title to be shortened, got %q", message)
+	}
+
+	// The longest titles, all escaped or multi-byte, with the longest link still fit a message
+	link := "https://example.com/" + strings.Repeat("a", maxCompactLink-len("https://example.com/"))
+	for _, title := range []string{strings.Repeat("*", 3000), strings.Repeat("🖖", 3000), strings.Repeat("_🖖", 3000)} {
+		message := formatNewsCompact(types.NewsItem{Title: title, URL: link})
+		if len(message) > maxMessageLength || utf8.RuneCountInString(message) > maxMessageLength {
+			t.Errorf("Expected at most %d characters, got %d bytes", maxMessageLength, len(message))
+		}
+		if !strings.HasSuffix(message, "<"+link+">") {
+			t.Errorf("Expected the link to be kept, got %q", message[len(message)-40:])
+		}
+	}
+}
+
+func TestPostNewsToChannelCompact(t *testing.T) {
+	stubThumbnails(t, http.StatusOK)
+	fake := testhelpers.NewFakeDiscord(t)
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	bot.Session = fake.Session()
+	bot.Config.URLRewrites = []types.URLRewriteRule{{From: "https://playstartrekonline.com/en/news", To: "https://new.example.com/news"}}
+
+	for _, channelID := range []string{"channel-a", "channel-b"} {
+		if err := database.AddChannel(bot, channelID); err != nil {
+			t.Fatalf("Failed to add channel: %v", err)
+		}
+	}
+	if err := database.UpdateChannelMessageStyle(bot, "channel-a", types.MessageStyleCompact); err != nil {
+		t.Fatalf("Failed to set message style: %v", err)
+	}
+	if err := database.UpdateChannelPingRole(bot, "channel-a", "role-1"); err != nil {
+		t.Fatalf("Failed to set ping role: %v", err)
+	}
+
+	newsItem := types.NewsItem{ID: 42, Title: "Patch Notes", Tags: []string{"patch-notes"}, Updated: time.Now()}
+	for _, channelID := range []string{"channel-a", "channel-b"} {
+		if err := PostNewsToChannel(bot, channelID, newsItem); err != nil {
+			t.Fatalf("Failed to post news to %s: %v", channelID, err)
+		}
+	}
+
+	// The compact channel gets a single line mentioning its role
+	var compact discordgo.MessageSend
+	posts := fake.RequestsTo("POST", "/channels/channel-a/messages")
+	if len(posts) != 1 || json.Unmarshal(posts[0].Body, &compact) != nil {
+		t.Fatalf("Expected 1 post to channel-a, got %d", len(posts))
+	}
+	expected := "<@&role-1> 🩹 **Patch Notes** — <https://new.example.com/news/article/42>"
+	if compact.Content != expected || len(compact.Embeds) != 0 {
+		t.Errorf("Expected %q without embeds, got %q with %d embeds", expected, compact.Content, len(compact.Embeds))
+	}
+
+	// Other channels still get the embed
+	var embed discordgo.MessageSend
+	posts = fake.RequestsTo("POST", "/channels/channel-b/messages")
+	if len(posts) != 1 || json.Unmarshal(posts[0].Body, &embed) != nil {
+		t.Fatalf("Expected 1 post to channel-b, got %d", len(posts))
+	}
+	if embed.Content != "" || len(embed.Embeds) != 1 || embed.Embeds[0].Title != "Patch Notes" {
+		t.Errorf("Expected an embed, got %q with %+v", embed.Content, embed.Embeds)
+	}
+}
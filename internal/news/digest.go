@@ -0,0 +1,214 @@
+package news
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// DigestPeriod is the period summarized by a weekly digest.
+const DigestPeriod = 7 * 24 * time.Hour
+
+// digestDescriptionLimit is Discord's embed description limit; longer digests are split.
+const digestDescriptionLimit = 4096
+
+// digestCheckInterval is how often the digest scheduler looks for digests that are due.
+const digestCheckInterval = time.Minute
+
+// digestLateLimit is how long after its scheduled time a missed digest is still posted,
+// e.g. when the bot was down at the scheduled hour.
+const digestLateLimit = 12 * time.Hour
+
+// untaggedDigestGroup is the digest heading for news without tags.
+const untaggedDigestGroup = "other"
+
+// BuildDigestEmbeds builds the embeds summarizing newsItems published between since and until,
+// listing each article's title and link grouped by its first tag. The summary is split across
+// several embeds when it exceeds Discord's description limit. Links are rewritten with the
+// bot's URL rewrite rules.
+func BuildDigestEmbeds(b *types.Bot, newsItems []types.NewsItem, since, until time.Time) []*discordgo.MessageEmbed {
+	groups := make(map[string][]types.NewsItem)
+	for _, item := range newsItems {
+		tag := untaggedDigestGroup
+		if len(item.Tags) > 0 && item.Tags[0] != "" {
+			tag = item.Tags[0]
+		}
+		groups[tag] = append(groups[tag], item)
+	}
+
+	tags := make([]string, 0, len(groups))
+	for tag := range groups {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	var rules []types.URLRewriteRule
+	if b != nil && b.Config != nil {
+		rules = b.Config.URLRewrites
+	}
+
+	// Lines are kept whole; a group heading starts a new section
+	var lines []string
+	for _, tag := range tags {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, fmt.Sprintf("**%s** (%d)", tag, len(groups[tag])))
+		for _, item := range groups[tag] {
+			link, _ := types.RewriteURL(item.Link(), rules)
+			lines = append(lines, fmt.Sprintf("• [%s](%s)", digestTitle(item.Title), link))
+		}
+	}
+
+	var descriptions []string
+	var current strings.Builder
+	for _, line := range lines {
+		if current.Len() > 0 && current.Len()+1+len(line) > digestDescriptionLimit {
+			descriptions = append(descriptions, strings.TrimRight(current.String(), "\n"))
+			current.Reset()
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n")
+		}
+		current.WriteString(line)
+	}
+	if current.Len() > 0 {
+		descriptions = append(descriptions, strings.TrimRight(current.String(), "\n"))
+	}
+
+	title := "📰 Weekly STO News Digest"
+	footer := fmt.Sprintf("%d articles • %s – %s", len(newsItems), since.UTC().Format("Jan 2"), until.UTC().Format("Jan 2, 2006"))
+	embeds := make([]*discordgo.MessageEmbed, 0, len(descriptions))
+	for i, description := range descriptions {
+		embed := &discordgo.MessageEmbed{
+			Title:       title,
+			Description: strings.TrimLeft(description, "\n"),
+			Color:       0x00ff00,
+			Footer:      &discordgo.MessageEmbedFooter{Text: footer},
+		}
+		if len(descriptions) > 1 {
+			embed.Title = fmt.Sprintf("%s (%d/%d)", title, i+1, len(descriptions))
+		}
+		embeds = append(embeds, embed)
+	}
+
+	return embeds
+}
+
+// digestTitle makes an article title safe to use as markdown link text.
+func digestTitle(title string) string {
+	title = strings.NewReplacer("[", "(", "]", ")", "\n", " ").Replace(strings.TrimSpace(title))
+	return types.TruncateBytes(title, 200)
+}
+
+// PostDigest posts the digest of the news posted or collected for a channel over the last
+// DigestPeriod, one message per embed. It returns the number of articles summarized; nothing
+// is sent when there are none.
+func PostDigest(b *types.Bot, channelID string, now time.Time) (int, error) {
+	since := now.Add(-DigestPeriod)
+	newsItems, err := database.GetDigestNews(b, channelID, since)
+	if err != nil {
+		return 0, err
+	}
+	if len(newsItems) == 0 {
+		return 0, nil
+	}
+
+	for _, embed := range BuildDigestEmbeds(b, newsItems, since, now) {
+		if _, err := b.Session.ChannelMessageSendEmbed(channelID, embed); err != nil {
+			return 0, fmt.Errorf("failed to send digest: %v", err)
+		}
+	}
+
+	return len(newsItems), nil
+}
+
+// lastDigestTime returns the most recent scheduled digest time at or before now.
+func lastDigestTime(cfg database.ChannelConfig, now time.Time) time.Time {
+	now = now.UTC()
+	scheduled := time.Date(now.Year(), now.Month(), now.Day(), cfg.DigestHour, 0, 0, 0, time.UTC)
+	scheduled = scheduled.AddDate(0, 0, int(cfg.DigestDay)-int(now.Weekday()))
+	if scheduled.After(now) {
+		scheduled = scheduled.AddDate(0, 0, -7)
+	}
+	return scheduled
+}
+
+// RunDigestCycle posts the digests that are due at now to channels in digest mode that are not
+// paused, and returns how many were posted. A digest is due once its scheduled time has passed and
+// it has not been posted since, for up to digestLateLimit afterwards. Cancelling ctx stops before
+// the next channel.
+func RunDigestCycle(ctx context.Context, b *types.Bot, now time.Time) (int, error) {
+	var due []database.ChannelConfig
+	err := database.ForEachActiveChannel(b, func(cfg database.ChannelConfig) error {
+		if !cfg.Digest || cfg.Paused {
+			return nil
+		}
+		scheduled := lastDigestTime(cfg, now)
+		if now.Sub(scheduled) > digestLateLimit {
+			return nil
+		}
+		lastDigest, err := database.GetChannelLastDigest(b, cfg.ID)
+		if err != nil {
+			logger().Errorf("Failed to get last digest for channel %s: %v", cfg.ID, err)
+			return nil
+		}
+		if !lastDigest.Before(scheduled) {
+			return nil
+		}
+		due = append(due, cfg)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list digest channels: %v", err)
+	}
+
+	posted := 0
+	for _, cfg := range due {
+		if err := ctx.Err(); err != nil {
+			return posted, err
+		}
+		count, err := PostDigest(b, cfg.ID, now)
+		if err != nil {
+			logger().Errorf("Failed to post digest to channel %s: %v", cfg.ID, err)
+			continue
+		}
+		// Recorded even for an empty week, so the digest is not retried every check
+		if err := database.SetChannelLastDigest(b, cfg.ID, now); err != nil {
+			logger().Errorf("Failed to record digest for channel %s: %v", cfg.ID, err)
+		}
+		if count > 0 {
+			logger().Infof("Posted weekly digest of %d articles to channel %s", count, cfg.ID)
+			posted++
+		}
+	}
+
+	return posted, nil
+}
+
+// DigestScheduler posts weekly digests to channels in digest mode until ctx is cancelled.
+func DigestScheduler(ctx context.Context, b *types.Bot) {
+	ticker := time.NewTicker(digestCheckInterval)
+	defer ticker.Stop()
+
+	logger().Info("Digest scheduler started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger().Info("Digest scheduler stopped")
+			return
+		case <-ticker.C:
+			if _, err := RunDigestCycle(ctx, b, time.Now()); err != nil {
+				logger().Errorf("Digest cycle failed: %v", err)
+			}
+		}
+	}
+}
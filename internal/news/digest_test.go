@@ -0,0 +1,204 @@
+package news
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestBuildDigestEmbeds(t *testing.T) {
+	bot := &types.Bot{Config: &types.Config{URLRewrites: []types.URLRewriteRule{
+		{From: "https://playstartrekonline.com/en/news", To: "https://new.example.com/news"},
+	}}}
+	newsItems := []types.NewsItem{
+		{ID: 1, Title: "Patch Notes for 6/11/24", Tags: []string{"patch-notes"}},
+		{ID: 2, Title: "Summer [Event]", Tags: []string{"events", "star-trek-online"}},
+		{ID: 3, Title: "Untagged"},
+		{ID: 4, Title: "Patch Notes for 6/4/24", Tags: []string{"patch-notes"}},
+	}
+	since := time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC)
+
+	embeds := BuildDigestEmbeds(bot, newsItems, since, since.Add(DigestPeriod))
+	if len(embeds) != 1 {
+		t.Fatalf("Expected 1 embed, got %d", len(embeds))
+	}
+
+	expected := "**events** (1)\n" +
+		"• [Summer (Event)](https://new.example.com/news/article/2)\n" +
+		"\n" +
+		"**other** (1)\n" +
+		"• [Untagged](https://new.example.com/news/article/3)\n" +
+		"\n" +
+		"**patch-notes** (2)\n" +
+		"• [Patch Notes for 6/11/24](https://new.example.com/news/article/1)\n" +
+		"• [Patch Notes for 6/4/24](https://new.example.com/news/article/4)"
+	if embeds[0].Description != expected {
+		t.Errorf("Unexpected digest:\n%s\nexpected:\n%s", embeds[0].Description, expected)
+	}
+	if embeds[0].Footer == nil || embeds[0].Footer.Text != "4 articles • Jun 5 – Jun 12, 2024" {
+		t.Errorf("Unexpected footer: %+v", embeds[0].Footer)
+	}
+}
+
+func TestBuildDigestEmbedsSplitsLongDigests(t *testing.T) {
+	var newsItems []types.NewsItem
+	for id := int64(1); id <= 200; id++ {
+		newsItems = append(newsItems, types.NewsItem{ID: id, Title: fmt.Sprintf("A rather long article title number %d", id), Tags: []string{"events"}})
+	}
+
+	embeds := BuildDigestEmbeds(&types.Bot{}, newsItems, time.Now().Add(-DigestPeriod), time.Now())
+	if len(embeds) < 2 {
+		t.Fatalf("Expected the digest to be split, got %d embeds", len(embeds))
+	}
+
+	var all strings.Builder
+	for n, embed := range embeds {
+		if len(embed.Description) > digestDescriptionLimit {
+			t.Errorf("Embed %d description is %d characters", n, len(embed.Description))
+		}
+		if !strings.HasSuffix(embed.Title, fmt.Sprintf("(%d/%d)", n+1, len(embeds))) {
+			t.Errorf("Expected embed %d to be numbered, got %q", n, embed.Title)
+		}
+		all.WriteString(embed.Description + "\n")
+	}
+	for id := 1; id <= 200; id++ {
+		if !strings.Contains(all.String(), fmt.Sprintf("/article/%d)", id)) {
+			t.Errorf("Expected article %d in the digest", id)
+		}
+	}
+}
+
+func TestDigestTitle(t *testing.T) {
+	tests := []struct {
+		name     string
+		title    string
+		expected string
+	}{
+		{name: "markdown brackets", title: " [PC] Patch\nNotes ", expected: "(PC) Patch Notes"},
+		{name: "ascii", title: strings.Repeat("a", 250), expected: strings.Repeat("a", 197) + "..."},
+		{name: "em-dash on the cut", title: "a" + strings.Repeat("—", 100), expected: "a" + strings.Repeat("—", 65) + "..."},
+		{name: "emoji on the cut", title: "a" + strings.Repeat("🖖", 60), expected: "a" + strings.Repeat("🖖", 49) + "..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title := digestTitle(tt.title)
+			if title != tt.expected {
+				t.Errorf("digestTitle(%q) = %q, want %q", tt.title, title, tt.expected)
+			}
+			if !utf8.ValidString(title) || len(title) > 200 {
+				t.Errorf("Expected valid UTF-8 of at most 200 bytes, got %d bytes", len(title))
+			}
+		})
+	}
+}
+
+func TestLastDigestTime(t *testing.T) {
+	cfg := database.ChannelConfig{Digest: true, DigestDay: time.Monday, DigestHour: 9}
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		expected time.Time
+	}{
+		{"just after", time.Date(2024, 6, 3, 9, 30, 0, 0, time.UTC), time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)},
+		{"earlier the same day", time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC), time.Date(2024, 5, 27, 9, 0, 0, 0, time.UTC)},
+		{"later in the week", time.Date(2024, 6, 8, 12, 0, 0, 0, time.UTC), time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)},
+		{"the day before", time.Date(2024, 6, 2, 23, 0, 0, 0, time.UTC), time.Date(2024, 5, 27, 9, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lastDigestTime(cfg, tt.now); !got.Equal(tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRunPollCycleDigestMode(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, pollCycleNews(), "channel-a", "channel-b")
+	if err := database.UpdateChannelDigest(bot, "channel-a", true, time.Monday, 9); err != nil {
+		t.Fatalf("Failed to update digest: %v", err)
+	}
+
+	summary, err := RunPollCycle(context.Background(), bot)
+	if err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+	if summary.Posted != 2 {
+		t.Errorf("Expected 2 posts, got %+v", summary)
+	}
+
+	// The digest channel gets no individual posts but collects the news
+	if calls := fake.RequestsTo("POST", "/channels/channel-a/messages"); len(calls) != 0 {
+		t.Errorf("Expected no posts to the digest channel, got %d", len(calls))
+	}
+	if calls := fake.RequestsTo("POST", "/channels/channel-b/messages"); len(calls) != 2 {
+		t.Errorf("Expected 2 posts to channel-b, got %d", len(calls))
+	}
+	items, err := database.GetDigestNews(bot, "channel-a", time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to get digest news: %v", err)
+	}
+	if len(items) != 2 {
+		t.Errorf("Expected 2 news collected for the digest, got %d", len(items))
+	}
+}
+
+func TestRunDigestCycle(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, pollCycleNews(), "channel-a", "channel-b")
+	now := time.Now().UTC()
+	if err := database.UpdateChannelDigest(bot, "channel-a", true, now.Weekday(), now.Hour()); err != nil {
+		t.Fatalf("Failed to update digest: %v", err)
+	}
+	if _, err := RunPollCycle(context.Background(), bot); err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+
+	// Enabling digest mode waits for the next scheduled time
+	posted, err := RunDigestCycle(context.Background(), bot, now)
+	if err != nil {
+		t.Fatalf("Digest cycle failed: %v", err)
+	}
+	if posted != 0 {
+		t.Errorf("Expected no digest right after enabling digest mode, got %d", posted)
+	}
+
+	// A week later the digest is due once
+	if err := database.SetChannelLastDigest(bot, "channel-a", now.Add(-DigestPeriod)); err != nil {
+		t.Fatalf("Failed to set last digest: %v", err)
+	}
+	for run := 0; run < 2; run++ {
+		if posted, err = RunDigestCycle(context.Background(), bot, now); err != nil {
+			t.Fatalf("Digest cycle failed: %v", err)
+		}
+		if expected := 1 - run; posted != expected {
+			t.Errorf("Run %d: expected %d digests, got %d", run, expected, posted)
+		}
+	}
+
+	calls := fake.RequestsTo("POST", "/channels/channel-a/messages")
+	if len(calls) != 1 {
+		t.Fatalf("Expected 1 digest message, got %d", len(calls))
+	}
+	var message discordgo.MessageSend
+	if err := json.Unmarshal(calls[0].Body, &message); err != nil {
+		t.Fatalf("Failed to decode message: %v", err)
+	}
+	if len(message.Embeds) != 1 || !strings.Contains(message.Embeds[0].Description, "Season Update") ||
+		!strings.Contains(message.Embeds[0].Description, "Patch Notes for 6/11/24") {
+		t.Errorf("Expected a digest of both articles, got %s", calls[0].Body)
+	}
+	if calls := fake.RequestsTo("POST", "/channels/channel-b/messages"); len(calls) != 2 {
+		t.Errorf("Expected only the 2 regular posts to channel-b, got %d", len(calls))
+	}
+}
@@ -0,0 +1,344 @@
+package news
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // Event times are given in US time zones, also on systems without zoneinfo
+	"unicode/utf8"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// EventsTag is the news tag of articles announcing in-game events.
+const EventsTag = "events"
+
+// maxEventLength is the longest date range parseEventDates accepts; longer ranges are more
+// likely misread than real events.
+const maxEventLength = 120 * 24 * time.Hour
+
+// maxEventDescriptionLength and maxEventLocationLength are Discord's limits on scheduled events,
+// in characters.
+const (
+	maxEventDescriptionLength = 1000
+	maxEventLocationLength    = 100
+)
+
+// Building blocks of eventRangePattern. A date is a month and day, optionally with a weekday, a
+// year and a time of day before or after it, e.g. "Thursday, June 13th at 8:00 AM PT" or
+// "10am Pacific on August 29".
+const (
+	eventWeekday  = `(?:(?:mon|tues?|wed(?:nes)?|thu(?:rs?)?|fri|sat(?:ur)?|sun)(?:day)?\.?,?\s+)?`
+	eventMonth    = `(?:jan(?:uary)?|feb(?:ruary)?|mar(?:ch)?|apr(?:il)?|may|june?|july?|aug(?:ust)?|sept?(?:ember)?|oct(?:ober)?|nov(?:ember)?|dec(?:ember)?)\.?`
+	eventCalendar = eventWeekday + `\b` + eventMonth + `\s+\d{1,2}(?:st|nd|rd|th)?\b(?:,?\s+\d{4})?`
+	eventClock    = `(?:\d{1,2}(?::\d{2})?\s*[ap]\.?m\.?|noon|midnight)`
+	eventZone     = `(?:\s*\(?(?:PT|PST|PDT|Pacific(?:\s+Time)?|ET|EST|EDT|Eastern(?:\s+Time)?|UTC|GMT)\)?)?`
+	eventTime     = eventClock + eventZone
+	eventDate     = `(?:` + eventTime + `,?\s+(?:on\s+)?)?` + eventCalendar + `(?:,?\s+(?:at\s+|@\s*)?` + eventTime + `)?`
+)
+
+// eventRangePattern matches the date ranges of event articles, e.g. "From June 13 at 8am PT until
+// June 27 at 10am PT" or "starting Tuesday, October 1st and running through November 5th". The
+// start date may also be "now".
+var eventRangePattern = regexp.MustCompile(`(?i)\b(?:from|starting|starts|beginning|begins)\s+(?:on\s+)?(now|` + eventDate + `)` +
+	`(?:,?\s+(?:and\s+)?(?:(?:running|lasting|continuing|going)\s+)?(?:to|until|till|through|thru)\s+(?:on\s+)?` +
+	`|,?\s+(?:and\s+)?end(?:s|ing)\s+(?:on\s+)?|\s*[-–—]\s*)(` + eventDate + `)`)
+
+var (
+	eventCalendarPattern = regexp.MustCompile(`(?i)\b(` + eventMonth + `)\s+(\d{1,2})(?:st|nd|rd|th)?\b(?:,?\s+(\d{4}))?`)
+	eventClockPattern    = regexp.MustCompile(`(?i)\b(?:(\d{1,2})(?::(\d{2}))?\s*([ap])\.?m\b\.?|(noon|midnight))`)
+	eventZonePattern     = regexp.MustCompile(`(?i)\b(PT|PST|PDT|Pacific|ET|EST|EDT|Eastern|UTC|GMT)\b`)
+)
+
+// eventZones are the time zones of the zone names event dates are given in.
+var eventZones = map[string]string{
+	"pt": "America/Los_Angeles", "pst": "America/Los_Angeles", "pdt": "America/Los_Angeles", "pacific": "America/Los_Angeles",
+	"et": "America/New_York", "est": "America/New_York", "edt": "America/New_York", "eastern": "America/New_York",
+	"utc": "UTC", "gmt": "UTC",
+}
+
+// defaultEventZone is the zone of event dates without one; Star Trek Online announces events in
+// Pacific time.
+const defaultEventZone = "pt"
+
+// eventDateParts are the parts of a date in an event range.
+type eventDateParts struct {
+	month, day, year int // year is 0 if the date has none.
+	hour, minute     int
+	hasTime          bool
+	zone             string // zone is the lowercase zone name, or empty if the date has none.
+}
+
+// parseEventDates extracts the date range of an in-game event from an article's text, e.g.
+// "The event runs from Thursday, June 13 at 8:00 AM PT until Thursday, June 27 at 10:00 AM PT".
+// Dates without a year are taken to be within six months of now, and an end before the start
+// is in the following year. A start without a time of day is at the beginning of its day and an
+// end without one at the end of its day, in Pacific time unless a zone is given.
+//
+// It returns ok=false if the text has no date range, or if the range is ambiguous: the text
+// gives differing ranges, e.g. for different platforms, a date does not exist, or the range is
+// empty or implausibly long.
+func parseEventDates(text string, now time.Time) (start, end time.Time, ok bool) {
+	matches := eventRangePattern.FindAllStringSubmatch(text, -1)
+	if len(matches) == 0 {
+		return time.Time{}, time.Time{}, false
+	}
+
+	for i, match := range matches {
+		matchStart, matchEnd, valid := resolveEventRange(match[1], match[2], now)
+		if !valid {
+			return time.Time{}, time.Time{}, false
+		}
+		if i > 0 && (!matchStart.Equal(start) || !matchEnd.Equal(end)) {
+			return time.Time{}, time.Time{}, false
+		}
+		start, end = matchStart, matchEnd
+	}
+	return start, end, true
+}
+
+// resolveEventRange returns the times of the start and end dates of a matched event range.
+func resolveEventRange(startText, endText string, now time.Time) (start, end time.Time, ok bool) {
+	endParts, ok := parseEventDate(endText)
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+
+	if strings.EqualFold(startText, "now") {
+		start = now
+		if endParts.zone == "" {
+			endParts.zone = defaultEventZone
+		}
+	} else {
+		startParts, ok := parseEventDate(startText)
+		if !ok {
+			return time.Time{}, time.Time{}, false
+		}
+		// A zone given for one date applies to both, e.g. "June 13 at 8am until June 27 at 10am PT"
+		switch {
+		case startParts.zone == "" && endParts.zone == "":
+			startParts.zone, endParts.zone = defaultEventZone, defaultEventZone
+		case startParts.zone == "":
+			startParts.zone = endParts.zone
+		case endParts.zone == "":
+			endParts.zone = startParts.zone
+		}
+
+		year := startParts.year
+		if year == 0 {
+			year = nearestEventYear(startParts, now)
+		}
+		if start, ok = startParts.time(year, false); !ok {
+			return time.Time{}, time.Time{}, false
+		}
+	}
+
+	year := endParts.year
+	if year == 0 {
+		year = start.In(endParts.location()).Year()
+	}
+	if end, ok = endParts.time(year, true); !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	if endParts.year == 0 && !end.After(start) {
+		if end, ok = endParts.time(year+1, true); !ok {
+			return time.Time{}, time.Time{}, false
+		}
+	}
+
+	if !end.After(start) || end.Sub(start) > maxEventLength {
+		return time.Time{}, time.Time{}, false
+	}
+	return start, end, true
+}
+
+// nearestEventYear returns the year in which a date without one is closest to now: the current
+// year, or the next or previous one for dates more than six months away, e.g. a January event
+// announced in December.
+func nearestEventYear(parts eventDateParts, now time.Time) int {
+	year := now.In(parts.location()).Year()
+	date, ok := parts.time(year, false)
+	if !ok {
+		return year
+	}
+	switch {
+	case date.Before(now.AddDate(0, -6, 0)):
+		return year + 1
+	case date.After(now.AddDate(0, 6, 0)):
+		return year - 1
+	}
+	return year
+}
+
+// parseEventDate parses a date matched by eventRangePattern.
+func parseEventDate(text string) (eventDateParts, bool) {
+	var parts eventDateParts
+	calendar := eventCalendarPattern.FindStringSubmatch(text)
+	if calendar == nil {
+		return parts, false
+	}
+	parts.month = eventMonthNumber(calendar[1])
+	parts.day, _ = strconv.Atoi(calendar[2])
+	if calendar[3] != "" {
+		parts.year, _ = strconv.Atoi(calendar[3])
+	}
+	if parts.month == 0 {
+		return parts, false
+	}
+
+	if clock := eventClockPattern.FindStringSubmatch(text); clock != nil {
+		parts.hasTime = true
+		switch strings.ToLower(clock[4]) {
+		case "noon":
+			parts.hour = 12
+		case "midnight":
+			parts.hour = 0
+		default:
+			parts.hour, _ = strconv.Atoi(clock[1])
+			if clock[2] != "" {
+				parts.minute, _ = strconv.Atoi(clock[2])
+			}
+			if parts.hour < 1 || parts.hour > 12 || parts.minute > 59 {
+				return parts, false
+			}
+			parts.hour %= 12
+			if strings.EqualFold(clock[3], "p") {
+				parts.hour += 12
+			}
+		}
+	}
+
+	if zone := eventZonePattern.FindStringSubmatch(text); zone != nil {
+		parts.zone = strings.ToLower(zone[1])
+	}
+	return parts, true
+}
+
+// eventMonthNumber returns the number of a month name or abbreviation, or 0 if it is none.
+func eventMonthNumber(name string) int {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if len(name) < 3 {
+		return 0
+	}
+	for month := time.January; month <= time.December; month++ {
+		if strings.HasPrefix(strings.ToLower(month.String()), name[:3]) {
+			return int(month)
+		}
+	}
+	return 0
+}
+
+// location returns the time zone of a date.
+func (parts eventDateParts) location() *time.Location {
+	zone := parts.zone
+	if zone == "" {
+		zone = defaultEventZone
+	}
+	location, err := time.LoadLocation(eventZones[zone])
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// time returns a date in the given year. A date without a time of day is at the beginning of
+// its day, or at the end of it if end is set. It returns ok=false for dates that do not exist,
+// such as June 31.
+func (parts eventDateParts) time(year int, end bool) (time.Time, bool) {
+	date := time.Date(year, time.Month(parts.month), parts.day, parts.hour, parts.minute, 0, 0, parts.location())
+	if date.Day() != parts.day || date.Month() != time.Month(parts.month) {
+		return time.Time{}, false
+	}
+	if end && !parts.hasTime {
+		date = date.Add(23*time.Hour + 59*time.Minute)
+	}
+	return date, true
+}
+
+// createNewsEvent creates a Discord scheduled event in a channel's server for the in-game event
+// an article announces, linking to the article so members get Discord's event reminders. Only
+// articles tagged EventsTag whose text gives an unambiguous date range that has not ended get an
+// event, once per server. Failures are logged only; the post itself was sent and stays marked as
+// posted.
+func createNewsEvent(b *types.Bot, cfg database.ChannelConfig, newsItem types.NewsItem) {
+	if !newsItem.HasTag(EventsTag) {
+		return
+	}
+	newsLog := channelLogger(cfg.ID).WithField("news_id", newsItem.ID)
+	if cfg.GuildID == "" {
+		newsLog.Debugf("Not creating an event for news %d: the server of channel %s is not known yet", newsItem.ID, cfg.ID)
+		return
+	}
+
+	current := now()
+	start, end, ok := parseEventDates(newsItem.Content, current)
+	if !ok {
+		start, end, ok = parseEventDates(newsItem.Summary, current)
+	}
+	if !ok {
+		newsLog.Debugf("Not creating an event for news %d: no unambiguous event dates found", newsItem.ID)
+		return
+	}
+	if !end.After(current.Add(time.Minute)) {
+		newsLog.Debugf("Not creating an event for news %d: the event ended at %s", newsItem.ID, end.UTC().Format(time.RFC3339))
+		return
+	}
+	// Discord only schedules events starting in the future, so running events start now
+	if earliest := current.Add(time.Minute); start.Before(earliest) {
+		start = earliest
+	}
+
+	reserved, err := database.ReserveNewsEvent(b, newsItem.ID, cfg.GuildID)
+	if err != nil {
+		newsLog.Errorf("Failed to reserve event for news %d in server %s: %v", newsItem.ID, cfg.GuildID, err)
+		return
+	}
+	if !reserved {
+		newsLog.Debugf("Server %s already has an event for news %d", cfg.GuildID, newsItem.ID)
+		return
+	}
+
+	link, _ := types.RewriteURL(newsItem.Link(), b.Config.URLRewrites)
+	event, err := b.Session.GuildScheduledEventCreate(cfg.GuildID, eventParams(newsItem, link, start, end))
+	if err != nil {
+		newsLog.Errorf("Failed to create event for news %d in server %s: %v", newsItem.ID, cfg.GuildID, err)
+		if err := database.ReleaseNewsEvent(b, newsItem.ID, cfg.GuildID); err != nil {
+			newsLog.Errorf("Failed to release event of news %d in server %s: %v", newsItem.ID, cfg.GuildID, err)
+		}
+		return
+	}
+	if err := database.SetNewsEvent(b, newsItem.ID, cfg.GuildID, event.ID); err != nil {
+		newsLog.Errorf("Failed to record event of news %d in server %s: %v", newsItem.ID, cfg.GuildID, err)
+	}
+	newsLog.Infof("Created event %s for news %d in server %s", event.ID, newsItem.ID, cfg.GuildID)
+}
+
+// eventParams returns the scheduled event of an article: an external event named after it,
+// described by its summary and located at its link.
+func eventParams(newsItem types.NewsItem, link string, start, end time.Time) *discordgo.GuildScheduledEventParams {
+	description := link
+	room := maxEventDescriptionLength - utf8.RuneCountInString(link) - 2
+	if summary := strings.TrimSpace(newsItem.Summary); summary != "" && room > 0 {
+		description = types.TruncateTextAtWord(summary, room) + "\n\n" + link
+	}
+	description = types.TruncateText(description, maxEventDescriptionLength)
+
+	location := link
+	if utf8.RuneCountInString(location) > maxEventLocationLength {
+		location = "Star Trek Online"
+	}
+
+	return &discordgo.GuildScheduledEventParams{
+		Name:               threadName(newsItem.Title),
+		Description:        description,
+		ScheduledStartTime: &start,
+		ScheduledEndTime:   &end,
+		PrivacyLevel:       discordgo.GuildScheduledEventPrivacyLevelGuildOnly,
+		EntityType:         discordgo.GuildScheduledEventEntityTypeExternal,
+		EntityMetadata:     &discordgo.GuildScheduledEventEntityMetadata{Location: location},
+	}
+}
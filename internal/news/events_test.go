@@ -0,0 +1,267 @@
+package news
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestParseEventDates(t *testing.T) {
+	june := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		text  string
+		now   time.Time
+		start string
+		end   string
+	}{
+		{
+			name:  "weekdays and times after the dates",
+			text:  "From Thursday, June 13 at 8:00 AM PT until Thursday, June 27 at 10:00 AM PT, Captains can earn the Summer Event starship by completing the daily Risa Floater Challenge.",
+			start: "2024-06-13T15:00:00Z",
+			end:   "2024-06-27T17:00:00Z",
+		},
+		{
+			name:  "times before the dates",
+			text:  "The Lunar New Year event will run from 8am PT on Thursday, August 8th until 10am PT on Thursday, August 29th.",
+			start: "2024-08-08T15:00:00Z",
+			end:   "2024-08-29T17:00:00Z",
+		},
+		{
+			name:  "starting and running through, across daylight saving time",
+			text:  "Starting Tuesday, October 1st at 8:00 AM Pacific and running through Tuesday, November 5th at 10:00 AM Pacific, all Captains can play the Halloween event.",
+			start: "2024-10-01T15:00:00Z",
+			end:   "2024-11-05T18:00:00Z",
+		},
+		{
+			name:  "from now",
+			text:  "Log in from now until 10 a.m. PT on July 11 to claim your free Bonus Lobi.",
+			start: "2024-06-10T12:00:00Z",
+			end:   "2024-07-11T17:00:00Z",
+		},
+		{
+			name:  "dates without times are whole days in Pacific time",
+			text:  "Join us for the anniversary from June 20 to June 24!",
+			start: "2024-06-20T07:00:00Z",
+			end:   "2024-06-25T06:59:00Z",
+		},
+		{
+			name:  "zone given on the end only",
+			text:  "The Featured TFO runs from June 13th at 8am until June 20th at 10am ET.",
+			start: "2024-06-13T12:00:00Z",
+			end:   "2024-06-20T14:00:00Z",
+		},
+		{
+			name:  "dash between the dates",
+			text:  "Event dates: from June 13 at noon – June 14 at midnight UTC",
+			start: "2024-06-13T12:00:00Z",
+			end:   "2024-06-14T00:00:00Z",
+		},
+		{
+			name:  "explicit years",
+			text:  "Double dilithium begins on June 13, 2024 at 3pm UTC and ends on June 14, 2024 at 3pm UTC.",
+			start: "2024-06-13T15:00:00Z",
+			end:   "2024-06-14T15:00:00Z",
+		},
+		{
+			name:  "next year's event announced in December",
+			text:  "From January 9 at 8am PT until January 30 at 10am PT, the Winter Wonderland continues.",
+			now:   time.Date(2024, 12, 20, 12, 0, 0, 0, time.UTC),
+			start: "2025-01-09T16:00:00Z",
+			end:   "2025-01-30T18:00:00Z",
+		},
+		{
+			name:  "range across the new year",
+			text:  "From December 19 at 8am PT through January 2 at 10am PT, Q's Winter Wonderland returns.",
+			now:   time.Date(2024, 12, 1, 12, 0, 0, 0, time.UTC),
+			start: "2024-12-19T16:00:00Z",
+			end:   "2025-01-02T18:00:00Z",
+		},
+		{
+			name:  "abbreviated months and the same range repeated",
+			text:  "From Jun. 13 to Jun. 27 on PC, and from Jun. 13 to Jun. 27 on consoles.",
+			start: "2024-06-13T07:00:00Z",
+			end:   "2024-06-28T06:59:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := tt.now
+			if current.IsZero() {
+				current = june
+			}
+			start, end, ok := parseEventDates(tt.text, current)
+			if !ok {
+				t.Fatalf("Expected a date range in %q", tt.text)
+			}
+			if got := start.UTC().Format(time.RFC3339); got != tt.start {
+				t.Errorf("Expected start %s, got %s", tt.start, got)
+			}
+			if got := end.UTC().Format(time.RFC3339); got != tt.end {
+				t.Errorf("Expected end %s, got %s", tt.end, got)
+			}
+		})
+	}
+}
+
+func TestParseEventDatesAmbiguous(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		text string
+	}{
+		{name: "no dates", text: "The Summer Event returns soon, stay tuned!"},
+		{name: "end date only", text: "The bundle is available until June 27 at 10am PT."},
+		{name: "numeric dates", text: "The event runs from 6/13 to 6/27."},
+		{name: "differing ranges", text: "On PC, from June 13 to June 27. On consoles, from June 25 to July 9."},
+		{name: "date that does not exist", text: "Play from June 31 to July 5."},
+		{name: "end before start", text: "From June 27, 2024 to June 13, 2024."},
+		{name: "implausibly long", text: "From January 1 to December 31, take part in the anniversary."},
+		{name: "invalid time", text: "From June 13 at 13pm PT until June 20 at 10am PT."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if start, end, ok := parseEventDates(tt.text, now); ok {
+				t.Errorf("Expected no date range in %q, got %v to %v", tt.text, start, end)
+			}
+		})
+	}
+}
+
+// eventTestNews is an event article with a date range a week after the event test clock.
+func eventTestNews() types.NewsItem {
+	return types.NewsItem{
+		ID:      7,
+		Title:   "Summer Event 2024",
+		Summary: "Risa is calling, Captains!",
+		Content: "From Thursday, June 13 at 8:00 AM PT until Thursday, June 27 at 10:00 AM PT, earn the event starship.",
+		Tags:    []string{"star-trek-online", "events"},
+	}
+}
+
+// setupEventTest creates a bot with a fake Discord server and the given channels registered in
+// guild-1 with scheduled events enabled. The clock is set to June 10, 2024.
+func setupEventTest(t *testing.T, channels ...string) (*types.Bot, *testhelpers.FakeDiscord) {
+	t.Helper()
+	stubThumbnails(t, http.StatusOK)
+	clock := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	originalNow := now
+	now = func() time.Time { return clock }
+	t.Cleanup(func() { now = originalNow })
+
+	fake := testhelpers.NewFakeDiscord(t)
+	bot := testhelpers.CreateTestBot(t)
+	t.Cleanup(func() { bot.DB.Close() })
+	bot.Session = fake.Session()
+
+	for _, channelID := range channels {
+		if err := database.AddChannel(bot, channelID); err != nil {
+			t.Fatalf("Failed to add channel: %v", err)
+		}
+		if err := database.UpdateChannelGuild(bot, channelID, "guild-1"); err != nil {
+			t.Fatalf("Failed to set guild: %v", err)
+		}
+		if err := database.UpdateChannelCreateEvents(bot, channelID, true); err != nil {
+			t.Fatalf("Failed to enable events: %v", err)
+		}
+	}
+	return bot, fake
+}
+
+func TestPostNewsManuallyCreatesEvent(t *testing.T) {
+	bot, fake := setupEventTest(t, "channel-a", "channel-b")
+	fake.Handle("POST", "/guilds/guild-1/scheduled-events", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusOK, map[string]string{"id": "event-1", "guild_id": "guild-1"})
+	})
+
+	for _, channelID := range []string{"channel-a", "channel-b"} {
+		if err := PostNewsManually(bot, channelID, eventTestNews()); err != nil {
+			t.Fatalf("Failed to post news to %s: %v", channelID, err)
+		}
+	}
+
+	// Both channels are in the same server, which gets a single event
+	requests := fake.RequestsTo("POST", "/guilds/guild-1/scheduled-events")
+	if len(requests) != 1 {
+		t.Fatalf("Expected 1 scheduled event, got %d", len(requests))
+	}
+	var event discordgo.GuildScheduledEventParams
+	if err := json.Unmarshal(requests[0].Body, &event); err != nil {
+		t.Fatalf("Failed to decode event: %v", err)
+	}
+	link := "https://playstartrekonline.com/en/news/article/7"
+	if event.Name != "Summer Event 2024" || event.Description != "Risa is calling, Captains!\n\n"+link {
+		t.Errorf("Expected the article's title and summary, got %q and %q", event.Name, event.Description)
+	}
+	if event.EntityType != discordgo.GuildScheduledEventEntityTypeExternal || event.EntityMetadata == nil || event.EntityMetadata.Location != link {
+		t.Errorf("Expected an external event at the article, got %+v", event)
+	}
+	if !event.ScheduledStartTime.Equal(time.Date(2024, 6, 13, 15, 0, 0, 0, time.UTC)) ||
+		!event.ScheduledEndTime.Equal(time.Date(2024, 6, 27, 17, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the event's dates, got %v to %v", event.ScheduledStartTime, event.ScheduledEndTime)
+	}
+	if eventID, err := database.GetNewsEvent(bot, 7, "guild-1"); err != nil || eventID != "event-1" {
+		t.Errorf("Expected event-1 to be recorded, got %q (%v)", eventID, err)
+	}
+}
+
+func TestPostNewsManuallyEventFailure(t *testing.T) {
+	bot, fake := setupEventTest(t, "channel-a")
+	fake.Handle("POST", "/guilds/guild-1/scheduled-events", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusForbidden, map[string]interface{}{"code": 50013, "message": "Missing Permissions"})
+	})
+
+	// The news is posted and marked even though the event could not be created
+	if err := PostNewsManually(bot, "channel-a", eventTestNews()); err != nil {
+		t.Fatalf("Expected the post to succeed, got %v", err)
+	}
+	if posts := fake.RequestsTo("POST", "/channels/channel-a/messages"); len(posts) != 1 {
+		t.Errorf("Expected 1 post, got %d", len(posts))
+	}
+	if posted, err := database.IsNewsPosted(bot, 7, "channel-a"); err != nil || !posted {
+		t.Errorf("Expected the news to be marked as posted, got %v (%v)", posted, err)
+	}
+
+	// The reservation is released so a later post may create the event
+	if reserved, err := database.ReserveNewsEvent(bot, 7, "guild-1"); err != nil || !reserved {
+		t.Errorf("Expected the failed event to be released, got %v (%v)", reserved, err)
+	}
+}
+
+func TestCreateNewsEventSkipped(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(item *types.NewsItem)
+	}{
+		{name: "not an event article", modify: func(item *types.NewsItem) { item.Tags = []string{"patch-notes"} }},
+		{name: "no dates", modify: func(item *types.NewsItem) { item.Content = "Risa is back soon." }},
+		{name: "event already over", modify: func(item *types.NewsItem) {
+			item.Content = strings.Replace(item.Content, "June 27", "June 9", 1)
+			item.Content = strings.Replace(item.Content, "June 13", "June 1", 1)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot, fake := setupEventTest(t, "channel-a")
+			newsItem := eventTestNews()
+			tt.modify(&newsItem)
+			if err := PostNewsManually(bot, "channel-a", newsItem); err != nil {
+				t.Fatalf("Failed to post news: %v", err)
+			}
+			if requests := fake.RequestsTo("POST", "/guilds/guild-1/scheduled-events"); len(requests) != 0 {
+				t.Errorf("Expected no scheduled event, got %d", len(requests))
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package news
+
+import "github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+// ArcGamesFetcher fetches news from the Arc Games news API. It is the news fetcher of bots
+// without one.
+type ArcGamesFetcher struct {
+	BaseURL string // BaseURL overrides the news API endpoint; empty uses the Arc Games API.
+}
+
+// FetchNews fetches count news items with a tag (all news if tag is empty) from the news API.
+func (f ArcGamesFetcher) FetchNews(tag string, count int, options types.FetchOptions) ([]types.NewsItem, error) {
+	return fetchNewsItems(f.BaseURL, tag, count, options)
+}
+
+// FetchNewsByID fetches a single news item, with its content, from the news API. It returns nil
+// if the API does not know the ID.
+func (f ArcGamesFetcher) FetchNewsByID(id int64) (*types.NewsItem, error) {
+	return fetchNewsItemByID(f.BaseURL, id, types.DefaultLocale)
+}
+
+// Fetcher returns the news fetcher of a bot: its Fetcher if set, otherwise an ArcGamesFetcher
+// for the API endpoint in its config.
+func Fetcher(b *types.Bot) types.NewsFetcher {
+	if b.Fetcher != nil {
+		return b.Fetcher
+	}
+	fetcher := ArcGamesFetcher{}
+	if b.Config != nil {
+		fetcher.BaseURL = b.Config.BaseURL
+	}
+	return fetcher
+}
@@ -0,0 +1,90 @@
+package news
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+func TestFetcher(t *testing.T) {
+	fake := testhelpers.NewFakeNewsFetcher()
+	tests := []struct {
+		name     string
+		bot      *types.Bot
+		expected types.NewsFetcher
+	}{
+		{"default", &types.Bot{}, ArcGamesFetcher{}},
+		{"API endpoint override", &types.Bot{Config: &types.Config{BaseURL: "http://localhost:8080/news"}}, ArcGamesFetcher{BaseURL: "http://localhost:8080/news"}},
+		{"bot fetcher", &types.Bot{Config: &types.Config{BaseURL: "http://localhost:8080/news"}, Fetcher: fake}, fake},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if fetcher := Fetcher(tt.bot); !reflect.DeepEqual(fetcher, tt.expected) {
+				t.Errorf("Expected fetcher %#v, got %#v", tt.expected, fetcher)
+			}
+		})
+	}
+}
+
+func TestFetchNewsWithBotFetcher(t *testing.T) {
+	fetcher := testhelpers.NewFakeNewsFetcher(
+		types.NewsItem{ID: 1, Title: "Patch Notes", Tags: []string{"patch-notes"}, Updated: time.Now()},
+		types.NewsItem{ID: 2, Title: "Event", Tags: []string{"events"}, Updated: time.Now()},
+		types.NewsItem{ID: 3, Title: "More Patch Notes", Tags: []string{"patch-notes"}, Updated: time.Now()},
+	)
+	bot := &types.Bot{Config: &types.Config{}, Fetcher: fetcher}
+
+	newsItems, err := FetchNews(bot, "patch-notes", 1, DefaultFetchOptions())
+	if err != nil {
+		t.Fatalf("Failed to fetch news: %v", err)
+	}
+	if len(newsItems) != 1 || newsItems[0].ID != 1 {
+		t.Errorf("Expected news 1, got %+v", newsItems)
+	}
+	expected := []testhelpers.FakeNewsFetchCall{{Tag: "patch-notes", Count: 1, Options: DefaultFetchOptions()}}
+	if calls := fetcher.Calls(); !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected calls %+v, got %+v", expected, calls)
+	}
+
+	fetcher.Err = errors.New("source unavailable")
+	if _, err := FetchNews(bot, "", 10, DefaultFetchOptions()); err == nil {
+		t.Error("Expected the fetcher's error")
+	}
+}
+
+func TestArcGamesFetcherFetchNewsByID(t *testing.T) {
+	skipRetryDelays(t)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/news/1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"news": {"id": 1, "title": "Season Update", "content": "<p>New season</p>"}}`))
+	}))
+	defer api.Close()
+	fetcher := ArcGamesFetcher{BaseURL: api.URL + "/news"}
+
+	newsItem, err := fetcher.FetchNewsByID(1)
+	if err != nil {
+		t.Fatalf("Failed to fetch news: %v", err)
+	}
+	if newsItem == nil || newsItem.ID != 1 || newsItem.Content != "<p>New season</p>" {
+		t.Errorf("Expected news 1 with its content as the API returned it, got %+v", newsItem)
+	}
+
+	// The AfterFetch hooks clean the content of news fetched for a bot
+	bot := &types.Bot{Config: &types.Config{BaseURL: api.URL + "/news"}}
+	if newsItem, err := FetchNewsByID(bot, 1); err != nil || newsItem == nil || newsItem.Content != "New season" {
+		t.Errorf("Expected news 1 with its cleaned content, got %+v (%v)", newsItem, err)
+	}
+
+	if newsItem, err := fetcher.FetchNewsByID(2); err != nil || newsItem != nil {
+		t.Errorf("Expected nil for news unknown to the API, got %+v (%v)", newsItem, err)
+	}
+}
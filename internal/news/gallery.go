@@ -0,0 +1,50 @@
+package news
+
+import (
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// DefaultGalleryMaxImages is how many extra images of an article are posted as its gallery.
+const DefaultGalleryMaxImages = 3
+
+// minGalleryImages is how many extra images an article needs to get a gallery; a single
+// screenshot is not worth one.
+const minGalleryImages = 2
+
+// galleryEmbeds returns the gallery of a news post in a channel showing galleries: an embed per
+// extra image of the article, up to Config.GalleryMaxImages. The embeds share the URL of the
+// article's embed so Discord shows them together as one gallery. Channels without galleries,
+// articles with fewer than two extra images and spoilers get none.
+func galleryEmbeds(b *types.Bot, cfg database.ChannelConfig, newsItem types.NewsItem, embed *discordgo.MessageEmbed) []*discordgo.MessageEmbed {
+	if !cfg.ShowGalleries || isSpoiler(newsItem, cfg.SpoilerTags) {
+		return nil
+	}
+	images := newsItem.GalleryImages()
+	if len(images) < minGalleryImages {
+		return nil
+	}
+
+	maxImages := DefaultGalleryMaxImages
+	if b.Config != nil && b.Config.GalleryMaxImages > 0 {
+		maxImages = b.Config.GalleryMaxImages
+	}
+	if len(images) > maxImages {
+		images = images[:maxImages]
+	}
+
+	gallery := make([]*discordgo.MessageEmbed, 0, len(images))
+	for _, image := range images {
+		galleryEmbed := &discordgo.MessageEmbed{
+			Color: embed.Color,
+			Image: &discordgo.MessageEmbedImage{URL: image.URL},
+		}
+		// The article link was already rewritten in embed
+		b.Config.RewriteEmbedURLs(galleryEmbed)
+		galleryEmbed.URL = embed.URL
+		gallery = append(gallery, galleryEmbed)
+	}
+	return gallery
+}
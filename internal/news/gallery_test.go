@@ -0,0 +1,79 @@
+package news
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// galleryNewsItem returns an article with a thumbnail, a background and the given number of
+// screenshots.
+func galleryNewsItem(screenshots int) types.NewsItem {
+	shots := make([]interface{}, screenshots)
+	for n := range shots {
+		shots[n] = map[string]interface{}{"url": fmt.Sprintf("https://images.arcgames.com/shot-%d.jpg", n+1)}
+	}
+	return types.NewsItem{
+		ID:           7,
+		Title:        "New Ships",
+		Tags:         []string{"star-trek-online"},
+		Updated:      time.Now(),
+		ThumbnailURL: "https://images.arcgames.com/thumb.jpg",
+		Images: map[string]interface{}{
+			"img_microsite_thumbnail":  map[string]interface{}{"url": "https://images.arcgames.com/thumb.jpg"},
+			"img_microsite_background": map[string]interface{}{"url": "https://images.arcgames.com/background.jpg"},
+			"screenshots":              shots,
+		},
+	}
+}
+
+func TestNewsMessageGallery(t *testing.T) {
+	stubThumbnails(t, http.StatusOK)
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	bot.Config.URLRewrites = []types.URLRewriteRule{{From: "https://images.arcgames.com", To: "https://cdn.example.com"}}
+	cfg := database.ChannelConfig{ID: "channel-a", ShowGalleries: true}
+
+	// The full-size image is shown without a gallery too
+	embeds := newsMessage(bot, database.ChannelConfig{ID: "channel-a"}, galleryNewsItem(5)).Embeds
+	if len(embeds) != 1 {
+		t.Fatalf("Expected no gallery in a channel without galleries, got %d embeds", len(embeds))
+	}
+	if embeds[0].Image == nil || embeds[0].Image.URL != "https://cdn.example.com/background.jpg" {
+		t.Errorf("Expected the rewritten background as image, got %+v", embeds[0].Image)
+	}
+
+	// Up to the default cap of screenshots, linking to the article
+	embeds = newsMessage(bot, cfg, galleryNewsItem(5)).Embeds
+	if len(embeds) != 1+DefaultGalleryMaxImages {
+		t.Fatalf("Expected the article and %d screenshots, got %d embeds", DefaultGalleryMaxImages, len(embeds))
+	}
+	for n, embed := range embeds[1:] {
+		if expected := fmt.Sprintf("https://cdn.example.com/shot-%d.jpg", n+1); embed.Image == nil || embed.Image.URL != expected {
+			t.Errorf("Expected gallery image %d to be %s, got %+v", n, expected, embed.Image)
+		}
+		if embed.URL != embeds[0].URL {
+			t.Errorf("Expected gallery image %d to link to %s, got %s", n, embeds[0].URL, embed.URL)
+		}
+	}
+
+	bot.Config.GalleryMaxImages = 2
+	if embeds = newsMessage(bot, cfg, galleryNewsItem(5)).Embeds; len(embeds) != 3 {
+		t.Errorf("Expected the article and 2 screenshots, got %d embeds", len(embeds))
+	}
+
+	// A single screenshot is no gallery, and spoilers get none
+	if embeds = newsMessage(bot, cfg, galleryNewsItem(1)).Embeds; len(embeds) != 1 {
+		t.Errorf("Expected no gallery for a single screenshot, got %d embeds", len(embeds))
+	}
+	cfg.SpoilerTags = []string{"star-trek-online"}
+	embeds = newsMessage(bot, cfg, galleryNewsItem(5)).Embeds
+	if len(embeds) != 1 || embeds[0].Image != nil {
+		t.Errorf("Expected a spoiler without images, got %d embeds", len(embeds))
+	}
+}
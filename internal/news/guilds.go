@@ -0,0 +1,34 @@
+package news
+
+import (
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// resolveChannelGuild records the guild of a channel registered before guilds were tracked.
+// The gateway's state cache is checked before the API; failures are logged and retried by the
+// next poll cycle.
+func resolveChannelGuild(b *types.Bot, channelID string) {
+	var channel *discordgo.Channel
+	if b.Session.State != nil {
+		channel, _ = b.Session.State.Channel(channelID)
+	}
+	if channel == nil {
+		var err error
+		if channel, err = b.Session.Channel(channelID); err != nil {
+			logger().Debugf("Failed to look up the guild of channel %s: %v", channelID, err)
+			return
+		}
+	}
+	if channel.GuildID == "" {
+		return
+	}
+
+	if err := database.UpdateChannelGuild(b, channelID, channel.GuildID); err != nil {
+		logger().Warnf("Failed to record the guild of channel %s: %v", channelID, err)
+		return
+	}
+	logger().Infof("Recorded guild %s for channel %s", channel.GuildID, channelID)
+}
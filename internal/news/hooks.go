@@ -0,0 +1,176 @@
+package news
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// AfterFetchHook post-processes a batch of freshly fetched news items and returns the batch to keep.
+// cfg is the config of the bot that fetched them; it may be nil.
+type AfterFetchHook func(cfg *types.Config, items []types.NewsItem) ([]types.NewsItem, error)
+
+// BeforePostHook inspects or rewrites a news item before it is posted to a channel, with the
+// channel's settings. Returning skip=true prevents the item from being posted to that channel.
+type BeforePostHook func(cfg database.ChannelConfig, item types.NewsItem) (result types.NewsItem, skip bool, err error)
+
+// AfterPostHook is notified after a news item has been posted to a channel.
+type AfterPostHook func(channelID string, item types.NewsItem, messageID string) error
+
+type namedAfterFetchHook struct {
+	name string
+	fn   AfterFetchHook
+}
+
+type namedBeforePostHook struct {
+	name string
+	fn   BeforePostHook
+}
+
+type namedAfterPostHook struct {
+	name string
+	fn   AfterPostHook
+}
+
+// HookRegistry holds the post-processing hooks of the news pipeline.
+//
+// Hooks run in registration order. A hook that returns an error or panics is
+// logged and skipped; the pipeline continues with the input it was given.
+type HookRegistry struct {
+	mu         sync.RWMutex
+	afterFetch []namedAfterFetchHook
+	beforePost []namedBeforePostHook
+	afterPost  []namedAfterPostHook
+}
+
+// NewHookRegistry creates an empty hook registry.
+func NewHookRegistry() *HookRegistry {
+	return &HookRegistry{}
+}
+
+// DefaultHooks is the registry used by the news pipeline. Built-in processing
+// steps are registered here at init time; embedders may register additional
+// hooks before starting the bot, through the pkg/stobot package.
+var DefaultHooks = NewHookRegistry()
+
+func init() {
+	DefaultHooks.RegisterAfterFetch("clean-content", cleanContentHook)
+	DefaultHooks.RegisterAfterFetch("tag-aliases", tagAliasesHook)
+}
+
+// RegisterAfterFetch adds a hook that runs after news is fetched.
+func (r *HookRegistry) RegisterAfterFetch(name string, fn AfterFetchHook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.afterFetch = append(r.afterFetch, namedAfterFetchHook{name: name, fn: fn})
+}
+
+// RegisterBeforePost adds a hook that runs before a news item is posted.
+func (r *HookRegistry) RegisterBeforePost(name string, fn BeforePostHook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.beforePost = append(r.beforePost, namedBeforePostHook{name: name, fn: fn})
+}
+
+// RegisterAfterPost adds a hook that runs after a news item is posted.
+func (r *HookRegistry) RegisterAfterPost(name string, fn AfterPostHook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.afterPost = append(r.afterPost, namedAfterPostHook{name: name, fn: fn})
+}
+
+// RunAfterFetch passes the items fetched by a bot with config cfg through every AfterFetch hook
+// in order.
+func (r *HookRegistry) RunAfterFetch(cfg *types.Config, items []types.NewsItem) []types.NewsItem {
+	r.mu.RLock()
+	hooks := append([]namedAfterFetchHook(nil), r.afterFetch...)
+	r.mu.RUnlock()
+
+	for _, hook := range hooks {
+		var result []types.NewsItem
+		err := safeHookCall(func() error {
+			var hookErr error
+			result, hookErr = hook.fn(cfg, items)
+			return hookErr
+		})
+		if err != nil {
+			logger().Errorf("AfterFetch hook %q failed: %v", hook.name, err)
+			continue
+		}
+		items = result
+	}
+	return items
+}
+
+// RunBeforePost passes the item through every BeforePost hook in order, for the channel with
+// config cfg. It returns skip=true as soon as a hook asks for the item to be skipped.
+func (r *HookRegistry) RunBeforePost(cfg database.ChannelConfig, item types.NewsItem) (types.NewsItem, bool) {
+	channelID := cfg.ID
+	r.mu.RLock()
+	hooks := append([]namedBeforePostHook(nil), r.beforePost...)
+	r.mu.RUnlock()
+
+	for _, hook := range hooks {
+		var result types.NewsItem
+		var skip bool
+		err := safeHookCall(func() error {
+			var hookErr error
+			result, skip, hookErr = hook.fn(cfg, item)
+			return hookErr
+		})
+		if err != nil {
+			logger().Errorf("BeforePost hook %q failed for news %d in channel %s: %v", hook.name, item.ID, channelID, err)
+			continue
+		}
+		if skip {
+			logger().Debugf("BeforePost hook %q skipped news %d for channel %s", hook.name, item.ID, channelID)
+			return result, true
+		}
+		item = result
+	}
+	return item, false
+}
+
+// RunAfterPost notifies every AfterPost hook in order.
+func (r *HookRegistry) RunAfterPost(channelID string, item types.NewsItem, messageID string) {
+	r.mu.RLock()
+	hooks := append([]namedAfterPostHook(nil), r.afterPost...)
+	r.mu.RUnlock()
+
+	for _, hook := range hooks {
+		err := safeHookCall(func() error {
+			return hook.fn(channelID, item, messageID)
+		})
+		if err != nil {
+			logger().Errorf("AfterPost hook %q failed for news %d in channel %s: %v", hook.name, item.ID, channelID, err)
+		}
+	}
+}
+
+// safeHookCall runs fn and converts a panic into an error.
+func safeHookCall(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn()
+}
+
+// cleanContentHook is the built-in AfterFetch hook that strips HTML from article content.
+func cleanContentHook(cfg *types.Config, items []types.NewsItem) ([]types.NewsItem, error) {
+	cleanNewsItemContent(items)
+	return items, nil
+}
+
+// tagAliasesHook is the built-in AfterFetch hook that maps tags to their canonical tags with the
+// configured TagAliases (see types.NormalizeTag). Tags are compared with channel filters before
+// they are cached, so the aliases apply as soon as news is fetched.
+func tagAliasesHook(cfg *types.Config, items []types.NewsItem) ([]types.NewsItem, error) {
+	for i := range items {
+		items[i].Tags = cfg.NormalizeTags(items[i].Tags)
+	}
+	return items, nil
+}
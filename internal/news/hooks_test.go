@@ -0,0 +1,168 @@
+package news
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+func TestHookRegistryAfterFetchOrderAndIsolation(t *testing.T) {
+	registry := NewHookRegistry()
+	var calls []string
+
+	registry.RegisterAfterFetch("first", func(cfg *types.Config, items []types.NewsItem) ([]types.NewsItem, error) {
+		calls = append(calls, "first")
+		return append(items, types.NewsItem{ID: 2}), nil
+	})
+	registry.RegisterAfterFetch("failing", func(cfg *types.Config, items []types.NewsItem) ([]types.NewsItem, error) {
+		calls = append(calls, "failing")
+		return nil, errors.New("boom")
+	})
+	registry.RegisterAfterFetch("panicking", func(cfg *types.Config, items []types.NewsItem) ([]types.NewsItem, error) {
+		calls = append(calls, "panicking")
+		panic("hook panic")
+	})
+	registry.RegisterAfterFetch("last", func(cfg *types.Config, items []types.NewsItem) ([]types.NewsItem, error) {
+		calls = append(calls, "last")
+		return append(items, types.NewsItem{ID: 3}), nil
+	})
+
+	result := registry.RunAfterFetch(nil, []types.NewsItem{{ID: 1}})
+
+	expectedCalls := []string{"first", "failing", "panicking", "last"}
+	if !reflect.DeepEqual(calls, expectedCalls) {
+		t.Errorf("Expected calls %v, got %v", expectedCalls, calls)
+	}
+
+	var ids []int64
+	for _, item := range result {
+		ids = append(ids, item.ID)
+	}
+	if !reflect.DeepEqual(ids, []int64{1, 2, 3}) {
+		t.Errorf("Expected failing hooks to be ignored, got IDs %v", ids)
+	}
+}
+
+func TestHookRegistryBeforePost(t *testing.T) {
+	registry := NewHookRegistry()
+	var calls []string
+
+	registry.RegisterBeforePost("rename", func(cfg database.ChannelConfig, item types.NewsItem) (types.NewsItem, bool, error) {
+		calls = append(calls, "rename")
+		item.Title = "[" + cfg.ID + "] " + item.Title
+		return item, false, nil
+	})
+	registry.RegisterBeforePost("failing", func(cfg database.ChannelConfig, item types.NewsItem) (types.NewsItem, bool, error) {
+		calls = append(calls, "failing")
+		return types.NewsItem{}, true, errors.New("boom")
+	})
+	registry.RegisterBeforePost("skip-maintenance", func(cfg database.ChannelConfig, item types.NewsItem) (types.NewsItem, bool, error) {
+		calls = append(calls, "skip-maintenance")
+		return item, item.ID == 99, nil
+	})
+	registry.RegisterBeforePost("after-skip", func(cfg database.ChannelConfig, item types.NewsItem) (types.NewsItem, bool, error) {
+		calls = append(calls, "after-skip")
+		return item, false, nil
+	})
+
+	cfg := database.ChannelConfig{ID: "chan"}
+	item, skip := registry.RunBeforePost(cfg, types.NewsItem{ID: 1, Title: "Title"})
+	if skip {
+		t.Error("Expected item not to be skipped")
+	}
+	if item.Title != "[chan] Title" {
+		t.Errorf("Expected rewritten title, got %q", item.Title)
+	}
+	expectedCalls := []string{"rename", "failing", "skip-maintenance", "after-skip"}
+	if !reflect.DeepEqual(calls, expectedCalls) {
+		t.Errorf("Expected calls %v, got %v", expectedCalls, calls)
+	}
+
+	calls = nil
+	_, skip = registry.RunBeforePost(cfg, types.NewsItem{ID: 99, Title: "Maintenance"})
+	if !skip {
+		t.Error("Expected item to be skipped")
+	}
+	expectedCalls = []string{"rename", "failing", "skip-maintenance"}
+	if !reflect.DeepEqual(calls, expectedCalls) {
+		t.Errorf("Expected hooks after a skip not to run, got %v", calls)
+	}
+}
+
+func TestHookRegistryAfterPost(t *testing.T) {
+	registry := NewHookRegistry()
+	var calls []string
+
+	registry.RegisterAfterPost("panicking", func(channelID string, item types.NewsItem, messageID string) error {
+		calls = append(calls, "panicking")
+		panic("hook panic")
+	})
+	registry.RegisterAfterPost("record", func(channelID string, item types.NewsItem, messageID string) error {
+		calls = append(calls, channelID+"/"+messageID)
+		return nil
+	})
+
+	registry.RunAfterPost("chan", types.NewsItem{ID: 1}, "msg")
+
+	expectedCalls := []string{"panicking", "chan/msg"}
+	if !reflect.DeepEqual(calls, expectedCalls) {
+		t.Errorf("Expected calls %v, got %v", expectedCalls, calls)
+	}
+}
+
+func TestDefaultHooksCleanContent(t *testing.T) {
+	items := DefaultHooks.RunAfterFetch(nil, []types.NewsItem{{ID: 1, Content: "<p>Hello <b>world</b></p>"}})
+	if len(items) != 1 {
+		t.Fatalf("Expected 1 item, got %d", len(items))
+	}
+	if items[0].Content != "Hello world" {
+		t.Errorf("Expected built-in hook to clean HTML, got %q", items[0].Content)
+	}
+}
+
+func TestDefaultHooksTagAliases(t *testing.T) {
+	aliases, err := types.ParseTagAliases("maintenance=server-maintenance")
+	if err != nil {
+		t.Fatalf("Failed to parse aliases: %v", err)
+	}
+	config := &types.Config{TagAliases: aliases}
+
+	items := DefaultHooks.RunAfterFetch(config, []types.NewsItem{{ID: 1, Tags: []string{"maintenance", "dev-blog"}}})
+	if len(items) != 1 || !reflect.DeepEqual(items[0].Tags, []string{"server-maintenance", "dev-blogs"}) {
+		t.Errorf("Expected the configured and built-in aliases to be mapped, got %+v", items)
+	}
+}
+
+func TestPostUnpostedNewsBeforePostHookGetsChannelConfig(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, nil, "channel-a")
+	if err := database.UpdateChannelCreateThreads(bot, "channel-a", true); err != nil {
+		t.Fatalf("Failed to enable threads: %v", err)
+	}
+	cfg, err := database.GetChannelConfig(bot, "channel-a")
+	if err != nil {
+		t.Fatalf("Failed to get config: %v", err)
+	}
+
+	original := DefaultHooks
+	DefaultHooks = NewHookRegistry()
+	t.Cleanup(func() { DefaultHooks = original })
+	var seen []database.ChannelConfig
+	DefaultHooks.RegisterBeforePost("record", func(cfg database.ChannelConfig, item types.NewsItem) (types.NewsItem, bool, error) {
+		seen = append(seen, cfg)
+		return item, true, nil
+	})
+
+	if posted, _ := postUnpostedNews(context.Background(), bot, *cfg, pollCycleNews()[:1]); posted != 0 {
+		t.Errorf("Expected the hook to skip the post, got %d posts", posted)
+	}
+	if len(seen) != 1 || seen[0].ID != "channel-a" || !seen[0].CreateThreads {
+		t.Errorf("Expected the hook to get the channel's settings, got %+v", seen)
+	}
+	if posts := fake.RequestsTo("POST", "/channels/channel-a/messages"); len(posts) != 0 {
+		t.Errorf("Expected no posts, got %d", len(posts))
+	}
+}
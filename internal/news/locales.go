@@ -0,0 +1,38 @@
+package news
+
+import (
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// localizeNewsItem returns a news item in a channel's locale for posting. The localized variant
+// is read from the cache, or fetched from the news API and cached, so channels sharing a locale
+// fetch it once. The item itself is returned for the default locale, and when the variant cannot
+// be loaded, so the news is posted in English rather than not at all.
+func localizeNewsItem(b *types.Bot, locale string, newsItem types.NewsItem) types.NewsItem {
+	if locale == "" || locale == types.DefaultLocale {
+		return newsItem
+	}
+
+	cached, err := database.GetCachedLocalizedNews(b, newsItem.ID, locale)
+	if err != nil {
+		logger().Warnf("Failed to get cached news %d in locale %s: %v", newsItem.ID, locale, err)
+	}
+	if cached != nil {
+		return *cached
+	}
+
+	fetched, err := FetchLocalizedNewsByID(b, newsItem.ID, locale)
+	if err != nil {
+		logger().Warnf("Failed to fetch news %d in locale %s, posting it in %s: %v", newsItem.ID, locale, types.DefaultLocale, err)
+		return newsItem
+	}
+	if fetched == nil {
+		logger().Warnf("News %d is not available in locale %s, posting it in %s", newsItem.ID, locale, types.DefaultLocale)
+		return newsItem
+	}
+	if err := database.CacheLocalizedNews(b, locale, []types.NewsItem{*fetched}); err != nil {
+		logger().Warnf("Failed to cache news %d in locale %s: %v", newsItem.ID, locale, err)
+	}
+	return *fetched
+}
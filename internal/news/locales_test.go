@@ -0,0 +1,84 @@
+package news
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestRunPollCycleLocale(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, nil, "channel-a", "channel-b")
+	bot.Fetcher = testhelpers.NewFakeNewsFetcher(pollCycleNews()...)
+
+	// Only news 1 is available in German
+	var requests atomic.Int32
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path == "/1" && r.URL.Query().Get("lang") == "de" {
+			_, _ = w.Write([]byte(`{"news": {"id": 1, "title": "Saison-Update", "summary": "Neue Saison", "tags": ["star-trek-online"]}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(api.Close)
+	bot.Config.BaseURL = api.URL
+
+	if err := database.UpdateChannelLocale(bot, "channel-b", "de"); err != nil {
+		t.Fatalf("Failed to update channel locale: %v", err)
+	}
+
+	summary, err := RunPollCycle(context.Background(), bot)
+	if err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+	if summary.Posted != 4 || summary.Failed != 0 {
+		t.Errorf("Expected 4 posts, got %+v", summary)
+	}
+
+	titles := func(channelID string) map[string]bool {
+		t.Helper()
+		posted := make(map[string]bool)
+		for _, call := range fake.RequestsTo("POST", "/channels/"+channelID+"/messages") {
+			var message discordgo.MessageSend
+			if err := json.Unmarshal(call.Body, &message); err != nil {
+				t.Fatalf("Failed to decode post: %v", err)
+			}
+			posted[message.Embeds[0].Title] = true
+		}
+		return posted
+	}
+	if posted := titles("channel-a"); !posted["Season Update"] || !posted["Patch Notes for 6/11/24"] {
+		t.Errorf("Expected English posts in channel-a, got %v", posted)
+	}
+	// News missing in German is posted in English
+	if posted := titles("channel-b"); !posted["Saison-Update"] || !posted["Patch Notes for 6/11/24"] {
+		t.Errorf("Expected German posts in channel-b, got %v", posted)
+	}
+
+	// Both variants of news 1 are cached
+	english, err := database.GetCachedNewsByID(bot, 1)
+	if err != nil || english == nil || english.Title != "Season Update" {
+		t.Errorf("Expected the English variant to be cached, got %+v (%v)", english, err)
+	}
+	german, err := database.GetCachedLocalizedNews(bot, 1, "de")
+	if err != nil || german == nil || german.Title != "Saison-Update" {
+		t.Errorf("Expected the German variant to be cached, got %+v (%v)", german, err)
+	}
+
+	// The cached variant is used instead of fetching it again
+	fetched := requests.Load()
+	if newsItem := localizeNewsItem(bot, "de", *english); newsItem.Title != "Saison-Update" {
+		t.Errorf("Expected the cached German variant, got %+v", newsItem)
+	}
+	if requests.Load() != fetched {
+		t.Errorf("Expected no request for a cached variant, got %d", requests.Load()-fetched)
+	}
+}
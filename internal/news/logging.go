@@ -0,0 +1,17 @@
+package news
+
+import (
+	"github.com/FracKenA/sto_news_discord_bot/internal/logging"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// logger returns the log entry of the news fetcher, poller and poster, tagged component=news.
+func logger() *log.Entry {
+	return logging.Logger(logging.News)
+}
+
+// channelLogger returns the log entry of posts to a channel, tagged with its channel_id.
+func channelLogger(channelID string) *log.Entry {
+	return logger().WithField("channel_id", channelID)
+}
@@ -4,30 +4,149 @@
 package news
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/metrics"
+	"github.com/FracKenA/sto_news_discord_bot/internal/ratelimit"
 	"github.com/FracKenA/sto_news_discord_bot/internal/types"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/bwmarrin/discordgo"
-	log "github.com/sirupsen/logrus"
 )
 
+// DefaultNewsAPIURL is the Arc Games news endpoint, used when Config.BaseURL is empty.
+const DefaultNewsAPIURL = "https://api.arcgames.com/v1.0/games/sto/news"
+
+// now is the clock quiet hours, freshness and future dates are checked against (replaced in tests).
+var now = time.Now
+
+// DefaultFutureSkewSeconds is how far in the future news may be dated and still be posted when
+// the config sets no skew.
+const DefaultFutureSkewSeconds = 300
+
+// DefaultMaxPostsPerCycle is how many news items may be posted to a channel per poll cycle or
+// catch-up by default, so a backlog after an outage is spread over several cycles.
+const DefaultMaxPostsPerCycle = 10
+
 // NewsResponse is a local struct for API responses
 type NewsResponse struct {
 	News []types.NewsItem `json:"news"`
 }
 
-// buildNewsURL constructs the Arc Games API URL for STO news
-func buildNewsURL(tag string, limit int, offset int, platform string, fields []string) string {
-	baseURL := "https://api.arcgames.com/v1.0/games/sto/news"
+// ErrUnexpectedSchema is returned when the news API responds with valid JSON of an unrecognised shape.
+var ErrUnexpectedSchema = errors.New("unexpected news response schema")
+
+// APIError is returned when the news API responds with an error object instead of news.
+type APIError struct {
+	Message string // Message is the upstream error message.
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("news API returned error: %s", e.Message)
+}
+
+// ParseNewsResponse decodes a news API response body.
+//
+// It accepts the object form ({"news": [...]}) and a bare array of items. An
+// error object ({"error": "..."}) yields an *APIError carrying the upstream
+// message, and any other shape yields an error wrapping ErrUnexpectedSchema.
+func ParseNewsResponse(data []byte) ([]types.NewsItem, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("%w: empty response body", ErrUnexpectedSchema)
+	}
+
+	switch trimmed[0] {
+	case '[':
+		var items []types.NewsItem
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, fmt.Errorf("failed to decode news array: %v", err)
+		}
+		return items, nil
+	case '{':
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &fields); err != nil {
+			return nil, fmt.Errorf("failed to decode news response: %v", err)
+		}
+
+		if raw, ok := fields["news"]; ok {
+			var items []types.NewsItem
+			if err := json.Unmarshal(raw, &items); err != nil {
+				return nil, fmt.Errorf("%w: news field is not a list: %v", ErrUnexpectedSchema, err)
+			}
+			return items, nil
+		}
+
+		if raw, ok := fields["error"]; ok {
+			return nil, &APIError{Message: parseAPIErrorMessage(raw)}
+		}
+
+		keys := make([]string, 0, len(fields))
+		for key := range fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return nil, fmt.Errorf("%w: object with keys %v", ErrUnexpectedSchema, keys)
+	default:
+		var probe interface{}
+		if err := json.Unmarshal(trimmed, &probe); err != nil {
+			return nil, fmt.Errorf("failed to decode news response: %v", err)
+		}
+		return nil, fmt.Errorf("%w: top-level %T", ErrUnexpectedSchema, probe)
+	}
+}
+
+// parseFetchedNews parses a news API response like ParseNewsResponse, counting responses of an
+// unexpected shape or carrying an error object in metrics.NewsSchemaErrors.
+func parseFetchedNews(body []byte) ([]types.NewsItem, error) {
+	newsItems, err := ParseNewsResponse(body)
+	var apiErr *APIError
+	if errors.Is(err, ErrUnexpectedSchema) || errors.As(err, &apiErr) {
+		metrics.NewsSchemaErrors.Inc()
+	}
+	return newsItems, err
+}
+
+// parseAPIErrorMessage extracts a readable message from an error field, which
+// may be a plain string or an object with a message field.
+func parseAPIErrorMessage(raw json.RawMessage) string {
+	var message string
+	if err := json.Unmarshal(raw, &message); err == nil {
+		return message
+	}
+
+	var detail struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(raw, &detail); err == nil && detail.Message != "" {
+		return detail.Message
+	}
+
+	return string(raw)
+}
+
+// buildNewsURL constructs the Arc Games API URL for STO news, falling back to
+// DefaultNewsAPIURL when baseURL is empty. News in a locale other than the default is
+// requested with the lang parameter.
+func buildNewsURL(baseURL string, tag string, limit int, offset int, platform string, locale string, fields []string) string {
+	if baseURL == "" {
+		baseURL = DefaultNewsAPIURL
+	}
 	params := url.Values{}
 
 	if tag != "" {
@@ -45,6 +164,9 @@ func buildNewsURL(tag string, limit int, offset int, platform string, fields []s
 	if platform != "" {
 		params.Add("platform", platform)
 	}
+	if locale != "" && locale != types.DefaultLocale {
+		params.Add("lang", locale)
+	}
 
 	if len(params) > 0 {
 		return baseURL + "?" + params.Encode()
@@ -52,17 +174,15 @@ func buildNewsURL(tag string, limit int, offset int, platform string, fields []s
 	return baseURL
 }
 
-// FetchOptions controls how news fetching behaves
-type FetchOptions struct {
-	EnablePagination bool // Whether to use pagination for large requests
-	ItemLimit        int  // Items per page when using pagination (default: 100)
-}
+// DefaultPageSize is the number of news items requested per page when FetchOptions.PageSize is 0.
+const DefaultPageSize = 100
 
 // DefaultFetchOptions returns sensible defaults for regular bot operation
 func DefaultFetchOptions() types.FetchOptions {
 	return types.FetchOptions{
 		EnablePagination: false,
-		ItemLimit:        100,
+		PageSize:         DefaultPageSize,
+		Retry:            DefaultRetryConfig(),
 	}
 }
 
@@ -70,7 +190,8 @@ func DefaultFetchOptions() types.FetchOptions {
 func BulkFetchOptions() types.FetchOptions {
 	return types.FetchOptions{
 		EnablePagination: true,
-		ItemLimit:        100,
+		PageSize:         DefaultPageSize,
+		Retry:            DefaultRetryConfig(),
 	}
 }
 
@@ -89,156 +210,210 @@ func MarkMultipleNewsAsPosted(b *types.Bot, newsItems []types.NewsItem, channels
 	return database.MarkMultipleNewsAsPosted(b, newsItems, channels, options)
 }
 
-// NewsPoller periodically polls for news and processes them for registered channels.
-func NewsPoller(b *types.Bot) {
-	ticker := time.NewTicker(time.Duration(b.Config.PollPeriod) * time.Second)
-	defer ticker.Stop()
-
-	log.Info("News poller started")
-
-	for range ticker.C {
-		// Only get channels that match the current environment
-		var channels []string
-		var err error
-		if b.Config.Environment != "" {
-			channels, err = database.GetChannelsByEnvironment(b, b.Config.Environment)
-			if err != nil {
-				log.Errorf("Failed to get channels for environment %s: %v", b.Config.Environment, err)
-				continue
-			}
-		} else {
-			// If no environment is set, get all channels (backwards compatibility)
-			channels, err = database.GetRegisteredChannels(b)
-			if err != nil {
-				log.Errorf("Failed to get registered channels: %v", err)
-				continue
-			}
+// NewsPoller periodically polls for news and processes them for registered channels until
+// ctx is cancelled. A cycle in progress stops before its next post, and NewsPoller returns once
+// it has.
+//
+// Cycles run one at a time, each Config.PollPeriod plus or minus PollJitter after the previous
+// one started. When a cycle is due while the previous one is still running, e.g. because the
+// news API is slow, it is skipped with a warning rather than run alongside it.
+func NewsPoller(ctx context.Context, b *types.Bot) {
+	period := time.Duration(b.Config.PollPeriod) * time.Second
+
+	logger().Info("News poller started")
+
+	var running sync.WaitGroup
+	var busy atomic.Bool
+	var started time.Time
+	for {
+		tick, stop := newPollTimer(pollInterval(period))
+		select {
+		case <-ctx.Done():
+			stop()
+			running.Wait()
+			logger().Info("News poller stopped")
+			return
+		case <-tick:
 		}
 
-		if len(channels) == 0 {
-			log.Debug("No registered channels found")
+		if !busy.CompareAndSwap(false, true) {
+			recordSkippedPollCycle()
+			logger().Warnf("Skipping poll cycle: the previous cycle is still running after %v", time.Since(started).Round(time.Second))
 			continue
 		}
+		started = time.Now()
+		running.Add(1)
+		go func(started time.Time) {
+			defer running.Done()
+			defer busy.Store(false)
+			runTimedPollCycle(ctx, b, started)
+		}(started)
+	}
+}
 
-		for _, channelID := range channels {
-			go ProcessChannelNews(b, channelID)
-		}
-
-		// Clean old cache every poll cycle
-		if err := database.CleanOldCache(b); err != nil {
-			log.Errorf("Failed to clean old cache: %v", err)
-		}
+// runTimedPollCycle runs a poll cycle of the poller started at started and records its duration.
+func runTimedPollCycle(ctx context.Context, b *types.Bot, started time.Time) {
+	metrics.PollCycles.Inc()
+	summary, err := RunPollCycle(ctx, b)
+	duration := time.Since(started)
+	recordPollCycleDuration(duration)
+	if err != nil {
+		metrics.PollCycleFailures.Inc()
+		logger().Errorf("Poll cycle failed after %v: %v", duration.Round(time.Millisecond), err)
+		return
 	}
+	logger().Debugf("Poll cycle took %v: %s", duration.Round(time.Millisecond), summary)
 }
 
-// FetchNews fetches news items with pagination and options.
+// FetchNews fetches news items with the bot's news fetcher (see Fetcher), runs the AfterFetch
+// hooks on them and records fetch metrics.
 func FetchNews(b *types.Bot, tag string, count int, options types.FetchOptions) ([]types.NewsItem, error) {
+	start := time.Now()
+	newsItems, err := Fetcher(b).FetchNews(tag, count, options)
+	metrics.FetchDuration.ObserveSince(start)
+	if err != nil {
+		metrics.FetchErrors.Inc()
+		return nil, err
+	}
+	metrics.NewsFetched.Add(len(newsItems))
+
+	// Run post-processing hooks (HTML cleanup, tag aliases and any registered extensions)
+	return DefaultHooks.RunAfterFetch(b.Config, newsItems), nil
+}
+
+// fetchNewsItems fetches up to count news items (capped at options.ItemLimit) from the news API
+// at baseURL (the Arc Games API if empty). With pagination, items are requested options.PageSize
+// at a time for at most options.PageLimit pages.
+func fetchNewsItems(baseURL, tag string, count int, options types.FetchOptions) ([]types.NewsItem, error) {
 	fields := []string{"id", "title", "summary", "tags", "platforms", "updated", "images", "content"}
 
 	client := &http.Client{
 		Timeout: 30 * time.Second,
 	}
 
+	if options.ItemLimit > 0 && count > options.ItemLimit {
+		count = options.ItemLimit
+	}
+	pageSize := options.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+
 	// Determine if we should use pagination
-	if !options.EnablePagination || count <= options.ItemLimit {
+	if !options.EnablePagination || count <= pageSize {
 		// Single request for small counts or when pagination is disabled
-		url := buildNewsURL(tag, count, 0, "", fields)
-		log.Debugf("Fetching news from: %s", url)
+		url := buildNewsURL(baseURL, tag, count, options.Offset, "", "", fields)
+		logger().Debugf("Fetching news from: %s", url)
 
-		resp, err := client.Get(url)
+		body, err := fetchWithRetry(client, url, options.Retry)
 		if err != nil {
-			return nil, fmt.Errorf("failed to fetch news: %v", err)
+			return nil, err
 		}
-		defer resp.Body.Close()
 
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
+		newsItems, err := parseFetchedNews(body)
+		if err != nil {
+			return nil, err
 		}
-
-		var newsResponse NewsResponse
-		if err := json.NewDecoder(resp.Body).Decode(&newsResponse); err != nil {
-			return nil, fmt.Errorf("failed to decode news response: %v", err)
+		newsItems, skipped := filterFetchedNews(newsItems, make(map[int64]bool))
+		if skipped > 0 {
+			logger().Warnf("Skipped %d news items with a duplicate or missing ID, or no title and summary", skipped)
 		}
 
 		// Process tags for all items
-		processNewsItemTags(newsResponse.News, tag)
+		processNewsItemTags(newsItems, tag)
 
-		// Clean HTML content for all items
-		cleanNewsItemContent(newsResponse.News)
-
-		log.Infof("Fetched %d news items with tag '%s'", len(newsResponse.News), tag)
-		return newsResponse.News, nil
+		logger().Infof("Fetched %d news items with tag '%s'", len(newsItems), tag)
+		return newsItems, nil
 	}
 
 	// Use pagination for large requests
 	var allNews []types.NewsItem
-	offset := 0
-	itemLimit := options.ItemLimit
+	seen := make(map[int64]bool)
+	skipped := 0
+	offset := options.Offset
+	pages := 0
 
 	for len(allNews) < count {
-		// Calculate how many items to request in this batch
-		remaining := count - len(allNews)
-		limit := itemLimit
-		if remaining < itemLimit {
-			limit = remaining
+		if options.PageLimit > 0 && pages >= options.PageLimit {
+			logger().Warnf("Stopped fetching news for tag '%s' after %d pages (%d/%d items)", tag, pages, len(allNews), count)
+			break
 		}
+		pages++
+
+		// Calculate how many items to request in this batch
+		limit := min(pageSize, count-len(allNews))
 
-		url := buildNewsURL(tag, limit, offset, "", fields)
-		log.Debugf("Fetching news page: offset=%d, limit=%d, url=%s", offset, limit, url)
+		url := buildNewsURL(baseURL, tag, limit, offset, "", "", fields)
+		logger().Debugf("Fetching news page: offset=%d, limit=%d, url=%s", offset, limit, url)
 
-		resp, err := client.Get(url)
+		// Retries repeat this page only, so pagination resumes from the failing offset
+		body, err := fetchWithRetry(client, url, options.Retry)
 		if err != nil {
-			return nil, fmt.Errorf("failed to fetch news page at offset %d: %v", offset, err)
+			return nil, fmt.Errorf("failed to fetch news page at offset %d: %w", offset, err)
 		}
 
-		if resp.StatusCode != http.StatusOK {
-			resp.Body.Close()
-			return nil, fmt.Errorf("API returned status %d at offset %d", resp.StatusCode, offset)
+		pageItems, err := parseFetchedNews(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse news page at offset %d: %w", offset, err)
 		}
 
-		var newsResponse NewsResponse
-		if err := json.NewDecoder(resp.Body).Decode(&newsResponse); err != nil {
-			resp.Body.Close()
-			return nil, fmt.Errorf("failed to decode news response at offset %d: %v", offset, err)
+		// Check if there are more pages
+		if len(pageItems) == 0 {
+			logger().Infof("No more news available for tag '%s'", tag)
+			break
 		}
-		resp.Body.Close()
 
-		// Process tags for all items
-		processNewsItemTags(newsResponse.News, tag)
+		// Pages can overlap, so only items not seen on an earlier page are kept
+		newItems, pageSkipped := filterFetchedNews(pageItems, seen)
+		skipped += pageSkipped
 
-		// Clean HTML content for all items
-		cleanNewsItemContent(newsResponse.News)
+		// Process tags for all items
+		processNewsItemTags(newItems, tag)
 
-		allNews = append(allNews, newsResponse.News...)
-		log.Infof("Fetched page with %d news items (total: %d/%d)", len(newsResponse.News), len(allNews), count)
+		allNews = append(allNews, newItems...)
+		logger().Infof("Fetched page with %d news items, %d new (total: %d/%d)", len(pageItems), len(newItems), len(allNews), count)
 
-		// Check if there are more pages
-		if len(newsResponse.News) == 0 {
-			log.Infof("No more news available for tag '%s'", tag)
+		// A page with nothing new means the API is repeating itself; fetching on would not end
+		if len(newItems) == 0 {
+			logger().Warnf("News page at offset %d for tag '%s' had no new items, stopping", offset, tag)
 			break
 		}
 
-		offset += len(newsResponse.News)
+		offset += len(pageItems)
+	}
+	if skipped > 0 {
+		logger().Warnf("Skipped %d news items with a duplicate or missing ID, or no title and summary", skipped)
 	}
 
-	log.Infof("Fetched %d total news items with tag '%s'", len(allNews), tag)
+	logger().Infof("Fetched %d total news items with tag '%s'", len(allNews), tag)
 	return allNews, nil
 }
 
-// processNewsItemTags ensures the requested tag is included in the tags array.
+// filterFetchedNews drops news items that cannot be cached: items without an ID, empty items and
+// items whose ID is already in seen. It adds the kept IDs to seen and returns the kept items and
+// the number dropped.
+func filterFetchedNews(newsItems []types.NewsItem, seen map[int64]bool) ([]types.NewsItem, int) {
+	kept := make([]types.NewsItem, 0, len(newsItems))
+	for _, newsItem := range newsItems {
+		if newsItem.ID == 0 || newsItem.IsEmpty() || seen[newsItem.ID] {
+			continue
+		}
+		seen[newsItem.ID] = true
+		kept = append(kept, newsItem)
+	}
+	return kept, len(newsItems) - len(kept)
+}
+
+// processNewsItemTags normalizes the tags of news items with the default aliases (see
+// types.NormalizeTag) and ensures the requested tag is included in the tags array.
 func processNewsItemTags(newsItems []types.NewsItem, requestedTag string) {
 	for i := range newsItems {
 		// Ensure the requested tag is in the tags array if it's not already there
-		tagExists := false
-		for _, existingTag := range newsItems[i].Tags {
-			if existingTag == requestedTag {
-				tagExists = true
-				break
-			}
-		}
-		if !tagExists && requestedTag != "" {
-			newsItems[i].Tags = append(newsItems[i].Tags, requestedTag)
+		tags := newsItems[i].Tags
+		if requestedTag != "" {
+			tags = append(tags, requestedTag)
 		}
+		newsItems[i].Tags = types.NormalizeTags(tags, nil)
 	}
 }
 
@@ -251,7 +426,8 @@ func cleanNewsItemContent(newsItems []types.NewsItem) {
 	}
 }
 
-// filterNewsByPlatforms filters news items by the specified platforms.
+// filterNewsByPlatforms filters news items by the specified platforms. Items without platforms
+// are kept, since the API sometimes omits the field.
 func filterNewsByPlatforms(news []types.NewsItem, platforms []string) []types.NewsItem {
 	if len(platforms) == 0 {
 		return news
@@ -264,6 +440,10 @@ func filterNewsByPlatforms(news []types.NewsItem, platforms []string) []types.Ne
 
 	var filtered []types.NewsItem
 	for _, item := range news {
+		if len(item.Platforms) == 0 {
+			filtered = append(filtered, item)
+			continue
+		}
 		for _, itemPlatform := range item.Platforms {
 			if platformSet[strings.ToLower(itemPlatform)] {
 				filtered = append(filtered, item)
@@ -275,79 +455,353 @@ func filterNewsByPlatforms(news []types.NewsItem, platforms []string) []types.Ne
 	return filtered
 }
 
-// IsNewsFresh checks if a news item is fresh.
-func IsNewsFresh(b *types.Bot, newsItem types.NewsItem) bool {
-	freshThreshold := time.Duration(b.Config.FreshSeconds) * time.Second
-	return time.Since(newsItem.Updated) <= freshThreshold
+// filterNewsByTags filters news items to those carrying any of the specified tags.
+// An empty tag list keeps all news.
+func filterNewsByTags(news []types.NewsItem, tags []string) []types.NewsItem {
+	if len(tags) == 0 {
+		return news
+	}
+
+	var filtered []types.NewsItem
+	for _, item := range news {
+		for _, tag := range tags {
+			if item.HasTag(tag) {
+				filtered = append(filtered, item)
+				break
+			}
+		}
+	}
+
+	return filtered
 }
 
-// ProcessChannelNews processes news for a channel.
-func ProcessChannelNews(b *types.Bot, channelID string) {
-	// Check if this channel matches the bot's environment
-	if b.Config.Environment != "" {
-		channelEnv, err := database.GetChannelEnvironment(b, channelID)
-		if err != nil {
-			log.Errorf("Failed to get environment for channel %s: %v", channelID, err)
-			return
+// hasExcludedTag reports whether a news item carries any of the excluded tags.
+func hasExcludedTag(item types.NewsItem, excludedTags []string) bool {
+	for _, tag := range excludedTags {
+		if item.HasTag(tag) {
+			return true
 		}
-		if channelEnv != b.Config.Environment {
-			log.Debugf("Skipping channel %s (environment %s, bot environment %s)", channelID, channelEnv, b.Config.Environment)
-			return
+	}
+	return false
+}
+
+// IsNewsFresh checks if a news item is fresh for readers of platforms: it counts from the item's
+// latest release on those platforms (all platforms when empty), so news released on consoles
+// after PC stays fresh for console readers. News without per-platform dates counts from Updated.
+//
+// News dated in the future is only fresh within the allowed skew, see isFutureDated.
+func IsNewsFresh(b *types.Bot, newsItem types.NewsItem, platforms []string) bool {
+	age := now().Sub(newsItem.ReleasedFor(platforms))
+	if age < 0 {
+		return -age <= futureSkew(b)
+	}
+	freshThreshold := time.Duration(b.Config.FreshSeconds) * time.Second
+	return age <= freshThreshold
+}
+
+// futureSkew returns how far in the future news may be dated and still be posted.
+func futureSkew(b *types.Bot) time.Duration {
+	skew := b.Config.FutureSkewSeconds
+	if skew == 0 {
+		skew = DefaultFutureSkewSeconds
+	}
+	return time.Duration(skew) * time.Second
+}
+
+// isFutureDated reports whether a news item is dated further in the future than the allowed
+// skew, like scheduled articles the API lists early. Such items are left unposted so a later poll
+// posts them once they are due.
+func isFutureDated(b *types.Bot, newsItem types.NewsItem) bool {
+	return newsItem.Updated.Sub(now()) > futureSkew(b)
+}
+
+// maxPostsPerCycle returns how many news items may be posted to a channel per poll cycle or
+// catch-up; 0 is no limit.
+func maxPostsPerCycle(b *types.Bot, cfg database.ChannelConfig) int {
+	if cfg.MaxPostsPerCycle > 0 {
+		return cfg.MaxPostsPerCycle
+	}
+	return b.Config.MaxPostsPerCycle
+}
+
+// sortOldestFirst returns news items sorted by Updated, oldest first, and by ID when dated the
+// same, so a channel limited to a few posts per cycle posts its backlog in a stable order.
+func sortOldestFirst(newsItems []types.NewsItem) []types.NewsItem {
+	sorted := append([]types.NewsItem(nil), newsItems...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].Updated.Equal(sorted[j].Updated) {
+			return sorted[i].Updated.Before(sorted[j].Updated)
 		}
+		return sorted[i].ID < sorted[j].ID
+	})
+	return sorted
+}
+
+// deferPost queues a news item over a channel's posts per cycle limit, so a later cycle posts it
+// even once it is no longer among the fetched news.
+func deferPost(b *types.Bot, channelID string, newsID int64) {
+	if err := database.HoldNews(b, newsID, channelID); err != nil {
+		channelLogger(channelID).WithField("news_id", newsID).Errorf("Failed to queue news %d for channel %s: %v", newsID, channelID, err)
 	}
+}
 
-	platforms, err := database.GetChannelPlatforms(b, channelID)
+// ProcessChannelNews posts already-fetched news to a channel. Callers fetch and cache news
+// once per cycle and pass the same items to every channel. Cancelling ctx stops before the next post.
+func ProcessChannelNews(ctx context.Context, b *types.Bot, channelID string, newsItems []types.NewsItem) {
+	cfg, err := database.GetChannelConfig(b, channelID)
 	if err != nil {
-		log.Errorf("Failed to get platforms for channel %s: %v", channelID, err)
+		channelLogger(channelID).Errorf("Failed to get config for channel %s: %v", channelID, err)
 		return
 	}
-	if len(platforms) == 0 {
-		log.Debugf("Channel %s not registered", channelID)
+	if cfg == nil {
+		channelLogger(channelID).Debugf("Channel %s not registered", channelID)
+		return
+	}
+	if cfg.Disabled {
+		channelLogger(channelID).Debugf("Channel %s is disabled", channelID)
 		return
 	}
 
-	// Fetch all news at once (no tag or platform filtering)
-	newsItems, err := FetchNews(b, "", b.Config.PollCount, DefaultFetchOptions())
-	if err != nil {
-		log.Errorf("Failed to fetch news: %v", err)
+	// Check if this channel matches the bot's environment
+	if b.Config.Environment != "" && cfg.Environment != b.Config.Environment {
+		channelLogger(channelID).Debugf("Skipping channel %s (environment %s, bot environment %s)", channelID, cfg.Environment, b.Config.Environment)
 		return
 	}
 
-	// Write all news to DB (cache)
-	if err := database.CacheNews(b, newsItems); err != nil {
-		log.Errorf("Failed to cache news items: %v", err)
+	if len(cfg.Platforms) == 0 {
+		channelLogger(channelID).Debugf("Channel %s has no platforms", cfg.ID)
+		return
 	}
 
-	// Post all unposted news
-	for _, newsItem := range newsItems {
-		posted, err := database.IsNewsPosted(b, newsItem.ID, channelID)
+	postUnpostedNews(ctx, b, *cfg, newsItems)
+}
+
+// postUnpostedNews posts the news items not yet posted to a channel and returns how many were
+// posted and how many failed to post. Cancelling ctx stops before the next item; a post already
+// sent is still marked as posted.
+//
+// A paused channel is sent nothing: its news is marked as posted, or held for it when it was
+// paused in hold mode. Once the channel is resumed, the held news is posted along with newsItems.
+//
+// News is posted oldest first. Once a channel was sent its maximum posts per cycle, the rest is
+// queued like held news and posted by the following cycles.
+//
+// News is posted and marked as posted by the bot's Poster. A dry run neither holds nor queues news:
+// what it does not post is left for the following dry runs.
+func postUnpostedNews(ctx context.Context, b *types.Bot, cfg database.ChannelConfig, newsItems []types.NewsItem) (posted, failed int) {
+	channelID := cfg.ID
+	poster := Poster(b)
+	dryRun := b.Config.DryRun
+	channelLog := channelLogger(channelID)
+	quiet := cfg.InQuietHours(now())
+	maxPosts := maxPostsPerCycle(b, cfg)
+	held, skipped, deferred := 0, 0, 0
+	queued := false
+	if !cfg.Paused {
+		newsItems, queued = withHeldNews(b, channelID, newsItems)
+	}
+	for _, newsItem := range sortOldestFirst(filterNewsByTags(filterNewsByPlatforms(newsItems, cfg.Platforms), cfg.Tags)) {
+		newsLog := channelLog.WithField("news_id", newsItem.ID)
+		if ctx.Err() != nil {
+			newsLog.Debugf("Stopping posts to channel %s: %v", channelID, ctx.Err())
+			break
+		}
+		alreadyPosted, err := poster.IsPosted(b, newsItem.ID, channelID)
 		if err != nil {
-			log.Errorf("Failed to check if news %d is posted: %v", newsItem.ID, err)
+			newsLog.Errorf("Failed to check if news %d is posted: %v", newsItem.ID, err)
+			failed++
+			continue
+		}
+		if alreadyPosted {
+			continue
+		}
+		if isFutureDated(b, newsItem) {
+			// Left unposted, so a poll posts it once it is due
+			newsLog.Infof("Holding back news %d for channel %s: dated %s, in the future", newsItem.ID, channelID, newsItem.Updated.UTC().Format(time.RFC3339))
+			continue
+		}
+		if isRepublished(b, channelID, newsItem) {
+			// Marked as posted so the copy is not checked again every cycle
+			newsLog.Infof("Skipping news %d for channel %s: republished copy of posted news", newsItem.ID, channelID)
+			if err := poster.MarkPosted(b, newsItem, channelID, ""); err != nil {
+				newsLog.Errorf("Failed to mark republished news %d as posted: %v", newsItem.ID, err)
+			}
 			continue
 		}
-		if posted {
+		if hasExcludedTag(newsItem, cfg.ExcludedTags) {
+			// Marked as posted so the excluded item is not checked again every cycle
+			newsLog.Debugf("Skipping news %d for channel %s: excluded tag", newsItem.ID, channelID)
+			if err := poster.MarkPosted(b, newsItem, channelID, ""); err != nil {
+				newsLog.Errorf("Failed to mark excluded news %d as posted: %v", newsItem.ID, err)
+			}
 			continue
 		}
-		if err := PostNewsToChannel(b, channelID, newsItem); err != nil {
-			log.Errorf("Failed to post news %d to channel %s: %v", newsItem.ID, channelID, err)
+		if !matchesStrictPatchNotes(cfg, newsItem) {
+			newsLog.Debugf("Skipping patch notes %d for channel %s: title is for other platforms", newsItem.ID, channelID)
+			continue
+		}
+		if cfg.Paused {
+			if cfg.PauseHold {
+				// Queued for the first poll after the channel is resumed
+				if !dryRun {
+					if err := database.HoldNews(b, newsItem.ID, channelID); err != nil {
+						newsLog.Errorf("Failed to hold news %d for paused channel %s: %v", newsItem.ID, channelID, err)
+					}
+				}
+			} else if err := poster.MarkPosted(b, newsItem, channelID, ""); err != nil {
+				// Marked as posted so news released during the pause is not posted on resume
+				newsLog.Errorf("Failed to mark news %d as posted for paused channel %s: %v", newsItem.ID, channelID, err)
+			}
+			skipped++
 			continue
 		}
-		if err := database.MarkNewsAsPosted(b, newsItem.ID, channelID); err != nil {
-			log.Errorf("Failed to mark news %d as posted: %v", newsItem.ID, err)
+		if cfg.Digest {
+			// Collected for the channel's weekly digest instead of posted on its own
+			if err := poster.MarkPosted(b, newsItem, channelID, database.DeliveryDigest); err != nil {
+				newsLog.Errorf("Failed to collect news %d for the digest of channel %s: %v", newsItem.ID, channelID, err)
+			}
+			continue
+		}
+		if quiet {
+			// Left unposted, so the first poll after the quiet hours posts it
+			held++
+			continue
+		}
+		if isDuplicatePost(b, cfg, newsItem) {
+			// Already visible in the channel, e.g. after the database was reset
+			newsLog.Infof("Skipping news %d for channel %s: already in recent messages", newsItem.ID, channelID)
+			if err := poster.MarkPosted(b, newsItem, channelID, ""); err != nil {
+				newsLog.Errorf("Failed to mark duplicate news %d as posted: %v", newsItem.ID, err)
+			}
+			continue
+		}
+		if maxPosts > 0 && posted >= maxPosts {
+			if !dryRun {
+				deferPost(b, channelID, newsItem.ID)
+			}
+			deferred++
+			continue
+		}
+		newsItem, skip := DefaultHooks.RunBeforePost(cfg, localizeNewsItem(b, cfg.Locale, newsItem))
+		if skip {
+			continue
+		}
+		sent, err := poster.Post(ctx, b, cfg, newsItem, database.DeliveryLive)
+		if err != nil {
+			if ctx.Err() != nil {
+				newsLog.Debugf("Stopping posts to channel %s: %v", channelID, ctx.Err())
+				break
+			}
+			newsLog.Errorf("Failed to post news %d to channel %s: %v", newsItem.ID, channelID, err)
+			failed++
+			if recordPostFailure(b, channelID, err) {
+				break
+			}
+			continue
+		}
+		if !sent {
+			continue
 		}
-		log.Infof("Posted news item %d ('%s') to channel %s", newsItem.ID, newsItem.Title, channelID)
+		newsLog.Infof("Posted news item %d ('%s') to channel %s", newsItem.ID, newsItem.Title, channelID)
+		posted++
 	}
+	if held > 0 {
+		channelLog.Infof("Holding %d news items for channel %s until its quiet hours end at %02d:00 UTC", held, channelID, cfg.QuietHoursEnd)
+	}
+	if skipped > 0 {
+		if cfg.PauseHold {
+			channelLog.Infof("Holding %d news items for paused channel %s until it is resumed", skipped, channelID)
+		} else {
+			channelLog.Infof("Skipped %d news items for paused channel %s", skipped, channelID)
+		}
+	}
+	if deferred > 0 {
+		channelLog.Infof("Deferring %d news items for channel %s to later cycles: at most %d posts per cycle", deferred, channelID, maxPosts)
+	}
+	// Held news left unposted stays queued for the next poll
+	if queued && !dryRun && failed == 0 && held == 0 && deferred == 0 && ctx.Err() == nil {
+		if err := database.ClearHeldNews(b, channelID); err != nil {
+			channelLog.Errorf("Failed to clear held news for channel %s: %v", channelID, err)
+		}
+	}
+	return posted, failed
+}
+
+// withHeldNews adds the news held for a channel, while it was paused or over its posts per cycle
+// limit, to newsItems, after them and oldest last, and reports whether any was held.
+func withHeldNews(b *types.Bot, channelID string, newsItems []types.NewsItem) ([]types.NewsItem, bool) {
+	heldNews, err := database.GetHeldNews(b, channelID)
+	if err != nil {
+		channelLogger(channelID).Errorf("Failed to get held news for channel %s: %v", channelID, err)
+		return newsItems, false
+	}
+	if len(heldNews) == 0 {
+		return newsItems, false
+	}
+
+	seen := make(map[int64]bool, len(newsItems))
+	for _, newsItem := range newsItems {
+		seen[newsItem.ID] = true
+	}
+	merged := append([]types.NewsItem(nil), newsItems...)
+	for i := len(heldNews) - 1; i >= 0; i-- {
+		if !seen[heldNews[i].ID] {
+			merged = append(merged, heldNews[i])
+		}
+	}
+	channelLogger(channelID).Infof("Posting %d news items held for channel %s", len(heldNews), channelID)
+	return merged, true
+}
+
+// isDuplicatePost reports whether a news item already appears in a channel's recent messages,
+// unless the duplicate check is disabled in the config or the bot runs dry.
+func isDuplicatePost(b *types.Bot, cfg database.ChannelConfig, newsItem types.NewsItem) bool {
+	if b.Config.SkipDuplicateCheck || b.Config.DryRun {
+		return false
+	}
+	return isDuplicateInRecentMessages(b, cfg.ID, webhookID(cfg.WebhookURL), newsItem)
+}
+
+// isRepublished reports whether a copy of a news item republished under another ID, i.e. with
+// the same fingerprint, was posted to a channel within the configured duplicate window.
+func isRepublished(b *types.Bot, channelID string, newsItem types.NewsItem) bool {
+	if b.Config.DuplicateWindowDays <= 0 {
+		return false
+	}
+	since := now().AddDate(0, 0, -b.Config.DuplicateWindowDays)
+	republished, err := database.IsFingerprintPosted(b, database.NewsFingerprint(newsItem), newsItem.ID, channelID, since)
+	if err != nil {
+		logger().Errorf("Failed to check if news %d was republished: %v", newsItem.ID, err)
+		return false
+	}
+	return republished
 }
 
 // IsDuplicateInRecentMessages checks for duplicate news in recent messages.
 func IsDuplicateInRecentMessages(b *types.Bot, channelID string, newsItem types.NewsItem) bool {
+	return isDuplicateInRecentMessages(b, channelID, "", newsItem)
+}
+
+// isDuplicateInRecentMessages checks for duplicate news in the recent messages posted by the bot
+// or, if webhookID is set, by the channel's webhook.
+func isDuplicateInRecentMessages(b *types.Bot, channelID, webhookID string, newsItem types.NewsItem) bool {
+	var botID string
+	if b.Session.State != nil && b.Session.State.User != nil {
+		botID = b.Session.State.User.ID
+	}
+	// Without the bot's own user the messages cannot be attributed, e.g. on a REST-only session
+	if botID == "" && webhookID == "" {
+		logger().Warnf("[IsDuplicateInRecentMessages] Bot user unknown. Skipping duplicate check for channel %s.", channelID)
+		return false
+	}
+
 	messages, err := b.Session.ChannelMessages(channelID, b.Config.MsgCount, "", "", "")
 	if err != nil {
 		if strings.Contains(err.Error(), "403") || strings.Contains(err.Error(), "Missing Access") {
-			log.Warnf("[IsDuplicateInRecentMessages] Missing access to read messages in channel %s. Skipping duplicate check.", channelID)
+			logger().Warnf("[IsDuplicateInRecentMessages] Missing access to read messages in channel %s. Skipping duplicate check.", channelID)
 			return false // Don't block posting if we can't check
 		}
-		log.Errorf("Failed to get recent messages for channel %s: %v", channelID, err)
+		logger().Errorf("Failed to get recent messages for channel %s: %v", channelID, err)
 		return false
 	}
 
@@ -358,7 +812,9 @@ func IsDuplicateInRecentMessages(b *types.Bot, channelID string, newsItem types.
 	}
 
 	for _, message := range messages {
-		if message.Author.ID != b.Session.State.User.ID {
+		ownMessage := (botID != "" && message.Author != nil && message.Author.ID == botID) ||
+			(webhookID != "" && message.WebhookID == webhookID)
+		if !ownMessage {
 			continue // Only check our own messages
 		}
 
@@ -391,39 +847,69 @@ func IsDuplicateInRecentMessages(b *types.Bot, channelID string, newsItem types.
 	return false
 }
 
-// formatNewsForDiscord creates a Discord embed for a news item.
-func formatNewsForDiscord(newsItem types.NewsItem) *discordgo.MessageEmbed {
-	// Truncate summary to fit Discord's embed description limit
-	summary := newsItem.Summary
-	if len(summary) > 2048 {
-		if len(summary) <= 3 {
-			summary = summary[:2048]
-		} else {
-			summary = summary[:2045] + "..."
+// DefaultEmbedColor is the color of news embeds without a tag that has a color of its own.
+const DefaultEmbedColor = 0x00ff00 // Green
+
+// defaultEmbedColorKey is the Config.EmbedColors key overriding DefaultEmbedColor.
+const defaultEmbedColorKey = "default"
+
+// DefaultTagColors are the embed colors of news with these tags, so the kind of article is
+// visible at a glance. Config.EmbedColors overrides them.
+var DefaultTagColors = map[string]int{
+	"patch-notes": 0xff8800, // Orange
+	"events":      0x9b59b6, // Purple
+	"dev-blogs":   0x3498db, // Blue
+}
+
+// EmbedColor returns the embed color of a news item: the color of its first tag that has one in
+// overrides or DefaultTagColors, or else the default color.
+func EmbedColor(newsItem types.NewsItem, overrides map[string]int) int {
+	for _, tag := range newsItem.Tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == defaultEmbedColorKey {
+			continue
+		}
+		if color, ok := overrides[tag]; ok {
+			return color
 		}
+		if color, ok := DefaultTagColors[tag]; ok {
+			return color
+		}
+	}
+	if color, ok := overrides[defaultEmbedColorKey]; ok {
+		return color
 	}
+	return DefaultEmbedColor
+}
+
+// maxEmbedAuthorName is Discord's limit on embed author names, in characters.
+const maxEmbedAuthorName = 256
+
+// formatNewsForDiscord creates a Discord embed for a news item, colored by its tags and
+// credited to its author if it has one. Besides the thumbnail, it shows the article's full-size
+// image if it has one, see types.NewsItem.FullImage.
+func formatNewsForDiscord(newsItem types.NewsItem) *discordgo.MessageEmbed {
+	// Truncate summary to fit Discord's embed description limit
+	summary := types.TruncateBytes(newsItem.Summary, 2048)
 
 	embed := &discordgo.MessageEmbed{
 		Title:       newsItem.Title,
 		Description: summary,
-		URL:         fmt.Sprintf("https://playstartrekonline.com/en/news/article/%d", newsItem.ID),
-		Color:       0x00ff00, // Green color
+		URL:         newsItem.Link(),
+		Color:       EmbedColor(newsItem, nil),
 		Timestamp:   newsItem.Updated.Format(time.RFC3339),
-		Footer: &discordgo.MessageEmbedFooter{
-			Text: fmt.Sprintf("Platforms: %s", strings.Join(newsItem.Platforms, ", ")),
-		},
-		Fields: []*discordgo.MessageEmbedField{
-			{
-				Name:   "Tags",
-				Value:  strings.Join(newsItem.Tags, ", "),
-				Inline: true,
-			},
-			{
-				Name:   "Platforms",
-				Value:  strings.Join(newsItem.Platforms, ", "),
-				Inline: true,
-			},
-		},
+	}
+
+	if author := embedAuthorName(newsItem.Author); author != "" {
+		embed.Author = &discordgo.MessageEmbedAuthor{Name: author}
+	}
+
+	// Discord rejects fields with an empty value, so fields are left out for items without tags or platforms
+	if len(newsItem.Tags) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Tags", Value: strings.Join(newsItem.Tags, ", "), Inline: true})
+	}
+	if len(newsItem.Platforms) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Platforms", Value: strings.Join(newsItem.Platforms, ", "), Inline: true})
 	}
 
 	if newsItem.ThumbnailURL != "" {
@@ -431,17 +917,136 @@ func formatNewsForDiscord(newsItem types.NewsItem) *discordgo.MessageEmbed {
 			URL: newsItem.ThumbnailURL,
 		}
 	}
+	if image, ok := newsItem.FullImage(); ok {
+		embed.Image = &discordgo.MessageEmbedImage{URL: image.URL}
+	}
 
 	return embed
 }
 
+// embedAuthorName returns an article's author for an embed, shortened to Discord's limit
+// without splitting a character.
+func embedAuthorName(author string) string {
+	author = strings.TrimSpace(author)
+	if utf8.RuneCountInString(author) <= maxEmbedAuthorName {
+		return author
+	}
+	return strings.TrimSpace(string([]rune(author)[:maxEmbedAuthorName-3])) + "..."
+}
+
+// spoilerPlaceholder replaces the summary of articles posted behind spoiler markers.
+const spoilerPlaceholder = "(spoiler hidden — click title to read)"
+
+// formatNewsForChannel creates a Discord embed for a news item, applying the channel's spoiler tags.
+// Articles tagged with any of spoilerTags have their summary replaced and their thumbnail omitted.
+func formatNewsForChannel(newsItem types.NewsItem, spoilerTags []string) *discordgo.MessageEmbed {
+	embed := formatNewsForDiscord(newsItem)
+	if !isSpoiler(newsItem, spoilerTags) {
+		return embed
+	}
+
+	embed.Description = spoilerPlaceholder
+	embed.Thumbnail = nil
+	embed.Image = nil
+	return embed
+}
+
+// isSpoiler reports whether a news item carries any of the given spoiler tags.
+func isSpoiler(newsItem types.NewsItem, spoilerTags []string) bool {
+	for _, tag := range spoilerTags {
+		if tag != "" && newsItem.HasTag(tag) {
+			return true
+		}
+	}
+	return false
+}
+
 // PostNewsToChannel posts a news item to a Discord channel.
 func PostNewsToChannel(b *types.Bot, channelID string, newsItem types.NewsItem) error {
-	embed := formatNewsForDiscord(newsItem)
-	_, err := b.Session.ChannelMessageSendEmbed(channelID, embed)
+	cfg, err := database.GetChannelConfig(b, channelID)
+	if err != nil {
+		return err
+	}
+	if cfg == nil {
+		cfg = &database.ChannelConfig{ID: channelID}
+	}
+
+	_, err = sendNewsToChannel(context.Background(), b, *cfg, localizeNewsItem(b, cfg.Locale, newsItem))
 	return err
 }
 
+// PostNewsManually posts a news item to a channel on request, the way the poller would, and
+// marks it as posted so the poller does not post it again. Posting news that was already posted
+// to the channel is allowed; the original record is kept.
+func PostNewsManually(b *types.Bot, channelID string, newsItem types.NewsItem) error {
+	cfg, err := database.GetChannelConfig(b, channelID)
+	if err != nil {
+		return err
+	}
+	if cfg == nil {
+		cfg = &database.ChannelConfig{ID: channelID}
+	}
+
+	message, err := sendNewsToChannel(context.Background(), b, *cfg, localizeNewsItem(b, cfg.Locale, newsItem))
+	if err != nil {
+		return err
+	}
+	afterNewsPosted(b, *cfg, newsItem, message.ID, database.DeliveryManual)
+	return nil
+}
+
+// newsMessage builds the message posting a news item to a channel in the channel's message style:
+// an embed, with a gallery of the article's extra images if the channel shows galleries, or a
+// single line linking to the article for compact channels.
+func newsMessage(b *types.Bot, cfg database.ChannelConfig, newsItem types.NewsItem) *discordgo.MessageSend {
+	// Only the configured role may be pinged; mentions in article text never are
+	message := &discordgo.MessageSend{
+		AllowedMentions: &discordgo.MessageAllowedMentions{Parse: []discordgo.AllowedMentionType{}},
+	}
+	if cfg.PingRole != "" {
+		message.Content = fmt.Sprintf("<@&%s>", cfg.PingRole)
+		message.AllowedMentions.Roles = []string{cfg.PingRole}
+	}
+
+	if cfg.MessageStyle == types.MessageStyleCompact {
+		if b.Config != nil {
+			newsItem.URL, _ = types.RewriteURL(newsItem.Link(), b.Config.URLRewrites)
+		}
+		message.Content = strings.TrimSpace(message.Content + " " + formatNewsCompact(newsItem))
+		return message
+	}
+
+	embed := BuildNewsEmbed(b, newsItem, cfg.SpoilerTags)
+	message.Embeds = append([]*discordgo.MessageEmbed{embed}, galleryEmbeds(b, cfg, newsItem, embed)...)
+	return message
+}
+
+// sendNewsToChannel posts a news item to a Discord channel and returns the sent message.
+// Channels with a webhook get the post through it, falling back to the bot if it fails.
+// Posts to all channels share ratelimit.Messages; cancelling ctx stops waiting for it.
+func sendNewsToChannel(ctx context.Context, b *types.Bot, cfg database.ChannelConfig, newsItem types.NewsItem) (*discordgo.Message, error) {
+	if err := ratelimit.Messages.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("stopped waiting to post: %v", err)
+	}
+
+	if cfg.WebhookURL != "" {
+		sent, err := PostNewsViaWebhook(b, cfg, newsItem)
+		if err == nil {
+			metrics.NewsPosted.Inc()
+			return sent, nil
+		}
+		channelLogger(cfg.ID).WithField("news_id", newsItem.ID).Warnf("Failed to post news %d to channel %s through its webhook, posting as the bot: %v", newsItem.ID, cfg.ID, err)
+	}
+
+	sent, err := b.Session.ChannelMessageSendComplex(cfg.ID, newsMessage(b, cfg, newsItem))
+	if err != nil {
+		metrics.PostFailures.Inc()
+		return nil, err
+	}
+	metrics.NewsPosted.Inc()
+	return sent, nil
+}
+
 // extractTextFromHTML extracts plain text from HTML content, removing all tags and cleaning whitespace.
 func extractTextFromHTML(htmlContent string) string {
 	if htmlContent == "" {
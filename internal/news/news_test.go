@@ -5,13 +5,21 @@ package news
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 
+	"github.com/FracKenA/sto_news_discord_bot/internal/metrics"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
 	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
 )
 
 func TestBuildNewsURL(t *testing.T) {
@@ -21,6 +29,7 @@ func TestBuildNewsURL(t *testing.T) {
 		limit    int
 		offset   int
 		platform string
+		locale   string
 		fields   []string
 		expected string
 	}{
@@ -78,11 +87,29 @@ func TestBuildNewsURL(t *testing.T) {
 			fields:   []string{"title", "summary"},
 			expected: "https://api.arcgames.com/v1.0/games/sto/news?field%5B%5D=title&field%5B%5D=summary&limit=15&offset=5&platform=pc&tag=patch-notes",
 		},
+		{
+			name:     "with default locale",
+			limit:    5,
+			locale:   "en",
+			expected: "https://api.arcgames.com/v1.0/games/sto/news?limit=5",
+		},
+		{
+			name:     "with German locale",
+			limit:    5,
+			locale:   "de",
+			expected: "https://api.arcgames.com/v1.0/games/sto/news?lang=de&limit=5",
+		},
+		{
+			name:     "with French locale",
+			tag:      "patch-notes",
+			locale:   "fr",
+			expected: "https://api.arcgames.com/v1.0/games/sto/news?lang=fr&tag=patch-notes",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := buildNewsURL(tt.tag, tt.limit, tt.offset, tt.platform, tt.fields)
+			result := buildNewsURL("", tt.tag, tt.limit, tt.offset, tt.platform, tt.locale, tt.fields)
 			if result != tt.expected {
 				t.Errorf("buildNewsURL() = %v, want %v", result, tt.expected)
 			}
@@ -122,36 +149,50 @@ func TestFetchNewsFromAPI(t *testing.T) {
 	}))
 	defer server.Close()
 
-	// Test basic fetch
-	// Note: This would require modifying the actual function to accept a custom base URL
-	// For now, we'll test the response parsing logic
-	client := &http.Client{}
-	resp, err := client.Get(server.URL)
+	bot := &types.Bot{Config: &types.Config{BaseURL: server.URL}}
+	newsItems, err := FetchNews(bot, "", 10, DefaultFetchOptions())
 	if err != nil {
-		t.Fatalf("Failed to make request: %v", err)
+		t.Fatalf("Failed to fetch news: %v", err)
 	}
-	defer resp.Body.Close()
 
-	var newsResp NewsResponse
-	err = json.NewDecoder(resp.Body).Decode(&newsResp)
-	if err != nil {
-		t.Fatalf("Failed to decode response: %v", err)
+	if len(newsItems) != 2 {
+		t.Errorf("Expected 2 news items, got %d", len(newsItems))
 	}
 
-	if len(newsResp.News) != 2 {
-		t.Errorf("Expected 2 news items, got %d", len(newsResp.News))
+	if newsItems[0].ID != 12345 {
+		t.Errorf("Expected first news ID 12345, got %d", newsItems[0].ID)
 	}
 
-	if newsResp.News[0].ID != 12345 {
-		t.Errorf("Expected first news ID 12345, got %d", newsResp.News[0].ID)
+	if newsItems[0].Title != "Test News Item" {
+		t.Errorf("Expected first news title 'Test News Item', got %s", newsItems[0].Title)
 	}
+}
 
-	if newsResp.News[0].Title != "Test News Item" {
-		t.Errorf("Expected first news title 'Test News Item', got %s", newsResp.News[0].Title)
+func TestFetchNewsOffset(t *testing.T) {
+	var offsets []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offsets = append(offsets, r.URL.Query().Get("offset"))
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(NewsResponse{News: []types.NewsItem{{ID: 12345, Title: "Test News Item"}}}); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	bot := &types.Bot{Config: &types.Config{BaseURL: server.URL}}
+	options := BulkFetchOptions()
+	options.Offset = 200
+	if _, err := FetchNews(bot, "", 50, options); err != nil {
+		t.Fatalf("Failed to fetch news: %v", err)
+	}
+	if len(offsets) != 1 || offsets[0] != "200" {
+		t.Errorf("Expected one request at offset 200, got %v", offsets)
 	}
 }
 
 func TestFetchNewsError(t *testing.T) {
+	skipRetryDelays(t)
+
 	// Create a mock server that returns an error
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -162,16 +203,111 @@ func TestFetchNewsError(t *testing.T) {
 	}))
 	defer server.Close()
 
-	// Test error handling
-	client := &http.Client{}
-	resp, err := client.Get(server.URL)
-	if err != nil {
-		t.Fatalf("Failed to make request: %v", err)
+	bot := &types.Bot{Config: &types.Config{BaseURL: server.URL}}
+	if _, err := FetchNews(bot, "", 10, DefaultFetchOptions()); err == nil {
+		t.Fatal("Expected an error when the news API returns 500")
+	} else if !strings.Contains(err.Error(), "500") {
+		t.Errorf("Expected the error to report status 500, got: %v", err)
 	}
-	defer resp.Body.Close()
+}
 
-	if resp.StatusCode != http.StatusInternalServerError {
-		t.Errorf("Expected status code 500, got %d", resp.StatusCode)
+func TestFetchNewsPaginationFiltering(t *testing.T) {
+	item := func(id int64) types.NewsItem {
+		return types.NewsItem{ID: id, Title: fmt.Sprintf("News %d", id), Updated: time.Now()}
+	}
+
+	tests := []struct {
+		name             string
+		pages            map[string][]types.NewsItem // pages by offset; other offsets are empty
+		pageLimit        int
+		expectedIDs      []int64
+		expectedRequests int
+	}{
+		{
+			name: "overlapping pages",
+			pages: map[string][]types.NewsItem{
+				"0": {item(1), item(2), item(3)},
+				"3": {item(3), item(4), item(5)},
+				"6": {item(5), item(6)},
+			},
+			expectedIDs:      []int64{1, 2, 3, 4, 5, 6},
+			expectedRequests: 4,
+		},
+		{
+			name: "zero IDs and empty items",
+			pages: map[string][]types.NewsItem{
+				"0": {item(1), {ID: 0, Title: "No ID"}, {ID: 2}},
+				"3": {item(3)},
+			},
+			expectedIDs:      []int64{1, 3},
+			expectedRequests: 3,
+		},
+		{
+			name: "page limit",
+			pages: map[string][]types.NewsItem{
+				"0": {item(1), item(2), item(3)},
+				"3": {item(4), item(5), item(6)},
+				"6": {item(7), item(8), item(9)},
+			},
+			pageLimit:        2,
+			expectedIDs:      []int64{1, 2, 3, 4, 5, 6},
+			expectedRequests: 2,
+		},
+		{
+			name: "API repeating the same page",
+			pages: map[string][]types.NewsItem{
+				"0": {item(1), item(2), item(3)},
+				"3": {item(1), item(2), item(3)},
+				"6": {item(1), item(2), item(3)},
+			},
+			expectedIDs:      []int64{1, 2, 3},
+			expectedRequests: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				offset := r.URL.Query().Get("offset")
+				if offset == "" {
+					offset = "0"
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(NewsResponse{News: tt.pages[offset]})
+			}))
+			defer server.Close()
+
+			options := BulkFetchOptions()
+			options.PageSize = 3
+			options.PageLimit = tt.pageLimit
+
+			bot := &types.Bot{Config: &types.Config{BaseURL: server.URL}}
+			newsItems, err := FetchNews(bot, "", 20, options)
+			if err != nil {
+				t.Fatalf("Failed to fetch news: %v", err)
+			}
+
+			var ids []int64
+			for _, newsItem := range newsItems {
+				ids = append(ids, newsItem.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.expectedIDs) {
+				t.Errorf("Expected news IDs %v, got %v", tt.expectedIDs, ids)
+			}
+			if requests != tt.expectedRequests {
+				t.Errorf("Expected %d requests, got %d", tt.expectedRequests, requests)
+			}
+		})
+	}
+}
+
+func TestBuildNewsURLCustomBase(t *testing.T) {
+	result := buildNewsURL("http://localhost:8080/sto/news", "patch-notes", 5, 0, "", "", nil)
+	expected := "http://localhost:8080/sto/news?limit=5&tag=patch-notes"
+	if result != expected {
+		t.Errorf("buildNewsURL() = %v, want %v", result, expected)
 	}
 }
 
@@ -216,6 +352,96 @@ func TestFormatNewsForDiscord(t *testing.T) {
 	}
 }
 
+func TestFormatNewsForDiscordAuthor(t *testing.T) {
+	long := strings.Repeat("é", maxEmbedAuthorName+10)
+	tests := []struct {
+		name     string
+		author   string
+		expected string
+	}{
+		{"author", "Ambassador Kael", "Ambassador Kael"},
+		{"no author", "", ""},
+		{"long author", long, strings.Repeat("é", maxEmbedAuthorName-3) + "..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			embed := formatNewsForDiscord(types.NewsItem{ID: 1, Title: "Dev Blog", Author: tt.author})
+			if tt.expected == "" {
+				if embed.Author != nil {
+					t.Errorf("Expected no embed author, got %+v", embed.Author)
+				}
+				return
+			}
+			if embed.Author == nil || embed.Author.Name != tt.expected {
+				t.Errorf("Expected embed author %q, got %+v", tt.expected, embed.Author)
+			}
+		})
+	}
+}
+
+func TestFormatNewsForDiscordURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  string
+		expected string
+	}{
+		{"canonical URL", `{"id": 12345, "title": "Patch Notes", "url": "https://playstartrekonline.com/en/news/article/12345/xbox-ps"}`, "https://playstartrekonline.com/en/news/article/12345/xbox-ps"},
+		{"no URL", `{"id": 12345, "title": "Patch Notes"}`, "https://playstartrekonline.com/en/news/article/12345"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var response NewsResponse
+			if err := json.Unmarshal([]byte(`{"news": [`+tt.payload+`]}`), &response); err != nil {
+				t.Fatalf("Failed to unmarshal news response: %v", err)
+			}
+			if embed := formatNewsForDiscord(response.News[0]); embed.URL != tt.expected {
+				t.Errorf("Expected embed URL %q, got %q", tt.expected, embed.URL)
+			}
+		})
+	}
+}
+
+func TestEmbedColor(t *testing.T) {
+	overrides := map[string]int{"events": 0x123456, "default": 0x654321}
+
+	tests := []struct {
+		name      string
+		tags      []string
+		overrides map[string]int
+		expected  int
+	}{
+		{"patch notes", []string{"star-trek-online", "patch-notes"}, nil, 0xff8800},
+		{"events", []string{"events"}, nil, 0x9b59b6},
+		{"dev blogs", []string{"Dev-Blogs"}, nil, 0x3498db},
+		{"first recognized tag wins", []string{"events", "patch-notes"}, nil, 0x9b59b6},
+		{"no recognized tag", []string{"star-trek-online", "pc"}, nil, DefaultEmbedColor},
+		{"no tags", nil, nil, DefaultEmbedColor},
+		{"override", []string{"events"}, overrides, 0x123456},
+		{"defaults kept without override", []string{"patch-notes"}, overrides, 0xff8800},
+		{"default override", []string{"star-trek-online"}, overrides, 0x654321},
+		{"new tag color", []string{"star-trek-online"}, map[string]int{"star-trek-online": 0xabcdef}, 0xabcdef},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newsItem := types.NewsItem{ID: 1, Tags: tt.tags}
+			if got := EmbedColor(newsItem, tt.overrides); got != tt.expected {
+				t.Errorf("Expected color 0x%06x, got 0x%06x", tt.expected, got)
+			}
+		})
+	}
+
+	// Posted embeds use the configured overrides and show the platforms only once
+	bot := &types.Bot{Config: &types.Config{EmbedColors: overrides}}
+	embed := BuildNewsEmbed(bot, types.NewsItem{ID: 1, Tags: []string{"events"}, Platforms: []string{"pc"}}, nil)
+	if embed.Color != 0x123456 {
+		t.Errorf("Expected the overridden color 0x123456, got 0x%06x", embed.Color)
+	}
+	if embed.Footer != nil {
+		t.Errorf("Expected no platforms footer, got %q", embed.Footer.Text)
+	}
+}
+
 func TestFormatNewsForDiscordWithoutThumbnail(t *testing.T) {
 	newsItem := types.NewsItem{
 		ID:        12345,
@@ -234,6 +460,20 @@ func TestFormatNewsForDiscordWithoutThumbnail(t *testing.T) {
 	}
 }
 
+func TestFormatNewsForDiscordBareItem(t *testing.T) {
+	embed := formatNewsForDiscord(types.NewsItem{ID: 12345, Title: "Untagged News"})
+
+	// Discord rejects the whole post when a field value is empty
+	if len(embed.Fields) != 0 {
+		t.Errorf("Expected no fields without tags or platforms, got %+v", embed.Fields)
+	}
+
+	embed = formatNewsForDiscord(types.NewsItem{ID: 12345, Title: "PC News", Platforms: []string{"pc"}})
+	if len(embed.Fields) != 1 || embed.Fields[0].Name != "Platforms" || embed.Fields[0].Value != "pc" {
+		t.Errorf("Expected only a Platforms field, got %+v", embed.Fields)
+	}
+}
+
 func TestFormatNewsForDiscordLongSummary(t *testing.T) {
 	// Create a very long summary
 	longSummary := ""
@@ -258,6 +498,98 @@ func TestFormatNewsForDiscordLongSummary(t *testing.T) {
 	}
 }
 
+func TestFormatNewsForDiscordMultiByteSummary(t *testing.T) {
+	tests := []struct {
+		name    string
+		summary string
+	}{
+		{name: "em-dash on the cut", summary: "a" + strings.Repeat("—", 1000)},
+		{name: "curly quotes", summary: strings.Repeat("“Engage” ", 300)},
+		{name: "emoji", summary: strings.Repeat("🚀", 600)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			embed := formatNewsForDiscord(types.NewsItem{ID: 1, Title: "Test", Summary: tt.summary})
+			if !utf8.ValidString(embed.Description) {
+				t.Errorf("Expected a valid UTF-8 description, got %q", embed.Description)
+			}
+			if len(embed.Description) > 2048 || !strings.HasSuffix(embed.Description, "...") {
+				t.Errorf("Expected a truncated description of at most 2048 bytes, got %d bytes", len(embed.Description))
+			}
+		})
+	}
+}
+
+func TestFormatNewsForChannelSpoilers(t *testing.T) {
+	summary := "Captain Kira discovers the traitor is Admiral Quinn"
+	newsItem := types.NewsItem{
+		ID:           12345,
+		Title:        "New Episode: Shadows",
+		Summary:      summary,
+		Tags:         []string{"star-trek-online", "story"},
+		Platforms:    []string{"pc"},
+		Updated:      time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC),
+		ThumbnailURL: "https://example.com/spoiler.jpg",
+	}
+
+	tests := []struct {
+		name        string
+		spoilerTags []string
+		hidden      bool
+	}{
+		{"no spoiler tags", nil, false},
+		{"non-matching tag", []string{"patch-notes"}, false},
+		{"matching tag", []string{"story"}, true},
+		{"matching tag case-insensitive", []string{"patch-notes", "Story"}, true},
+		{"empty tag ignored", []string{""}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			embed := formatNewsForChannel(newsItem, tt.spoilerTags)
+
+			if embed.Title != newsItem.Title {
+				t.Errorf("Expected title %q, got %q", newsItem.Title, embed.Title)
+			}
+
+			if !tt.hidden {
+				if embed.Description != summary {
+					t.Errorf("Expected summary to be shown, got %q", embed.Description)
+				}
+				if embed.Thumbnail == nil {
+					t.Error("Expected thumbnail to be shown")
+				}
+				return
+			}
+
+			if embed.Description != spoilerPlaceholder {
+				t.Errorf("Expected spoiler placeholder, got %q", embed.Description)
+			}
+			if embed.Thumbnail != nil {
+				t.Error("Expected thumbnail to be omitted for spoiler articles")
+			}
+			if embed.Image != nil {
+				t.Error("Expected image to be omitted for spoiler articles")
+			}
+
+			// No part of the embed may contain the summary
+			texts := []string{embed.Title, embed.Description, embed.URL}
+			if embed.Footer != nil {
+				texts = append(texts, embed.Footer.Text)
+			}
+			for _, field := range embed.Fields {
+				texts = append(texts, field.Name, field.Value)
+			}
+			for _, text := range texts {
+				if strings.Contains(text, "traitor") {
+					t.Errorf("Spoiler leaked into embed text: %q", text)
+				}
+			}
+		})
+	}
+}
+
 func TestNewsItemHelperMethods(t *testing.T) {
 	newsItem := types.NewsItem{
 		ID:        12345,
@@ -382,4 +714,414 @@ func TestFetchOptions(t *testing.T) {
 	if customOpts.ItemLimit != 50 {
 		t.Errorf("Expected custom item limit 50, got %d", customOpts.ItemLimit)
 	}
+
+	for name, opts := range map[string]types.FetchOptions{"default": DefaultFetchOptions(), "bulk": BulkFetchOptions()} {
+		if opts.PageSize != DefaultPageSize || opts.ItemLimit != 0 || opts.PageLimit != 0 {
+			t.Errorf("Expected %s options to page by %d without limits, got %+v", name, DefaultPageSize, opts)
+		}
+	}
+}
+
+func TestFetchOptionsPagination(t *testing.T) {
+	tests := []struct {
+		name     string
+		count    int
+		options  types.FetchOptions
+		expected []string // expected are the offset/limit of each request made.
+	}{
+		{
+			name:     "single request without pagination",
+			count:    250,
+			options:  types.FetchOptions{PageSize: 100},
+			expected: []string{"0/250"},
+		},
+		{
+			name:     "single request within a page",
+			count:    80,
+			options:  types.FetchOptions{EnablePagination: true, PageSize: 100},
+			expected: []string{"0/80"},
+		},
+		{
+			name:     "default page size",
+			count:    250,
+			options:  types.FetchOptions{EnablePagination: true},
+			expected: []string{"0/100", "100/100", "200/50"},
+		},
+		{
+			name:     "page size",
+			count:    100,
+			options:  types.FetchOptions{EnablePagination: true, PageSize: 40},
+			expected: []string{"0/40", "40/40", "80/20"},
+		},
+		{
+			name:     "item limit caps the total",
+			count:    250,
+			options:  types.FetchOptions{EnablePagination: true, PageSize: 40, ItemLimit: 90},
+			expected: []string{"0/40", "40/40", "80/10"},
+		},
+		{
+			name:     "item limit without pagination",
+			count:    250,
+			options:  types.FetchOptions{ItemLimit: 30},
+			expected: []string{"0/30"},
+		},
+		{
+			name:     "page limit",
+			count:    250,
+			options:  types.FetchOptions{EnablePagination: true, PageSize: 40, PageLimit: 2},
+			expected: []string{"0/40", "40/40"},
+		},
+		{
+			name:     "offset",
+			count:    100,
+			options:  types.FetchOptions{EnablePagination: true, PageSize: 60, Offset: 500},
+			expected: []string{"500/60", "560/40"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				query := r.URL.Query()
+				offset := query.Get("offset")
+				if offset == "" {
+					offset = "0"
+				}
+				requests = append(requests, offset+"/"+query.Get("limit"))
+
+				// Serve as many items as were asked for, numbered from the offset
+				var start, limit int
+				fmt.Sscan(offset, &start)
+				fmt.Sscan(query.Get("limit"), &limit)
+				page := make([]types.NewsItem, 0, limit)
+				for id := start + 1; id <= start+limit; id++ {
+					page = append(page, types.NewsItem{ID: int64(id), Title: fmt.Sprintf("News %d", id)})
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(NewsResponse{News: page})
+			}))
+			defer server.Close()
+
+			newsItems, err := fetchNewsItems(server.URL, "", tt.count, tt.options)
+			if err != nil {
+				t.Fatalf("Failed to fetch news: %v", err)
+			}
+			if !reflect.DeepEqual(requests, tt.expected) {
+				t.Errorf("Expected requests %v, got %v", tt.expected, requests)
+			}
+
+			total := 0
+			for _, request := range tt.expected {
+				var offset, limit int
+				fmt.Sscanf(request, "%d/%d", &offset, &limit)
+				total += limit
+			}
+			if len(newsItems) != total {
+				t.Errorf("Expected %d news items, got %d", total, len(newsItems))
+			}
+		})
+	}
+}
+
+func TestParseNewsResponse(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		expectedIDs   []int64
+		expectAPIErr  string
+		expectSchema  bool
+		expectFailure bool
+	}{
+		{
+			name:        "object form",
+			body:        `{"news": [{"id": 1, "title": "One"}, {"id": "2", "title": "Two"}]}`,
+			expectedIDs: []int64{1, 2},
+		},
+		{
+			name:        "object form with empty list",
+			body:        `{"news": []}`,
+			expectedIDs: []int64{},
+		},
+		{
+			name:        "object form with extra fields",
+			body:        `{"news": [{"id": 3, "title": "Three"}], "total": 1}`,
+			expectedIDs: []int64{3},
+		},
+		{
+			name:        "bare array",
+			body:        `[{"id": 4, "title": "Four"}, {"id": 5, "title": "Five"}]`,
+			expectedIDs: []int64{4, 5},
+		},
+		{
+			name:        "bare empty array with whitespace",
+			body:        "  \n[]\n",
+			expectedIDs: []int64{},
+		},
+		{
+			name:         "error object with string message",
+			body:         `{"error": "rate limit exceeded"}`,
+			expectAPIErr: "rate limit exceeded",
+		},
+		{
+			name:         "error object with nested message",
+			body:         `{"error": {"code": 503, "message": "maintenance"}}`,
+			expectAPIErr: "maintenance",
+		},
+		{
+			name:         "unknown object",
+			body:         `{"items": []}`,
+			expectSchema: true,
+		},
+		{
+			name:         "news field of wrong type",
+			body:         `{"news": "none"}`,
+			expectSchema: true,
+		},
+		{
+			name:         "top-level string",
+			body:         `"hello"`,
+			expectSchema: true,
+		},
+		{
+			name:         "top-level null",
+			body:         `null`,
+			expectSchema: true,
+		},
+		{
+			name:         "empty body",
+			body:         "",
+			expectSchema: true,
+		},
+		{
+			name:          "truncated object",
+			body:          `{"news": [{"id": 1, "title": "On`,
+			expectFailure: true,
+		},
+		{
+			name:          "truncated array",
+			body:          `[{"id": 1}`,
+			expectFailure: true,
+		},
+		{
+			name:          "invalid JSON",
+			body:          `<html>Bad Gateway</html>`,
+			expectFailure: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Fetched responses of an unexpected shape or carrying an error are counted
+			api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer api.Close()
+			before := metrics.NewsSchemaErrors.Value()
+			_, _ = fetchNewsItems(api.URL, "", 5, types.FetchOptions{})
+			counted := metrics.NewsSchemaErrors.Value() - before
+			if expected := tt.expectAPIErr != "" || tt.expectSchema; (counted == 1) != expected || counted > 1 {
+				t.Errorf("Expected a schema error to be counted: %v, got %d", expected, counted)
+			}
+
+			items, err := ParseNewsResponse([]byte(tt.body))
+
+			switch {
+			case tt.expectAPIErr != "":
+				var apiErr *APIError
+				if !errors.As(err, &apiErr) {
+					t.Fatalf("Expected *APIError, got %v", err)
+				}
+				if apiErr.Message != tt.expectAPIErr {
+					t.Errorf("Expected upstream message %q, got %q", tt.expectAPIErr, apiErr.Message)
+				}
+				if !strings.Contains(err.Error(), tt.expectAPIErr) {
+					t.Errorf("Expected error text to include upstream message, got %q", err.Error())
+				}
+			case tt.expectSchema:
+				if !errors.Is(err, ErrUnexpectedSchema) {
+					t.Fatalf("Expected ErrUnexpectedSchema, got %v", err)
+				}
+			case tt.expectFailure:
+				if err == nil {
+					t.Fatal("Expected decode error, got nil")
+				}
+				if errors.Is(err, ErrUnexpectedSchema) {
+					t.Errorf("Expected decode error rather than schema error, got %v", err)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if len(items) != len(tt.expectedIDs) {
+					t.Fatalf("Expected %d items, got %d", len(tt.expectedIDs), len(items))
+				}
+				for i, id := range tt.expectedIDs {
+					if items[i].ID != id {
+						t.Errorf("Item %d: expected ID %d, got %d", i, id, items[i].ID)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPostNewsToChannelRewritesURLs(t *testing.T) {
+	stubThumbnails(t, http.StatusOK)
+	fake := testhelpers.NewFakeDiscord(t)
+	bot := testhelpers.CreateTestBot(t)
+	defer bot.DB.Close()
+	bot.Session = fake.Session()
+	bot.Config.URLRewrites = []types.URLRewriteRule{
+		{From: "https://playstartrekonline.com/en/news", To: "https://new.example.com/news"},
+		{From: "https://images.arcgames.com", To: "https://cdn.example.com"},
+	}
+
+	newsItem := types.NewsItem{ID: 42, Title: "Moved", Updated: time.Now(), ThumbnailURL: "https://images.arcgames.com/42.jpg?w=400"}
+	if err := PostNewsToChannel(bot, "channel-a", newsItem); err != nil {
+		t.Fatalf("Failed to post news: %v", err)
+	}
+
+	calls := fake.RequestsTo("POST", "/channels/channel-a/messages")
+	if len(calls) != 1 {
+		t.Fatalf("Expected 1 post, got %d", len(calls))
+	}
+	var message struct {
+		Embeds []discordgo.MessageEmbed `json:"embeds"`
+	}
+	if err := json.Unmarshal(calls[0].Body, &message); err != nil {
+		t.Fatalf("Failed to decode message: %v", err)
+	}
+	if len(message.Embeds) != 1 {
+		t.Fatalf("Expected 1 embed, got %d", len(message.Embeds))
+	}
+	if url := message.Embeds[0].URL; url != "https://new.example.com/news/article/42" {
+		t.Errorf("Expected rewritten article URL, got %q", url)
+	}
+	if message.Embeds[0].Thumbnail == nil || message.Embeds[0].Thumbnail.URL != "https://cdn.example.com/42.jpg?w=400" {
+		t.Errorf("Expected rewritten thumbnail URL, got %+v", message.Embeds[0].Thumbnail)
+	}
+	if newsItem.ThumbnailURL != "https://images.arcgames.com/42.jpg?w=400" {
+		t.Errorf("Expected the news item to be left unchanged, got %q", newsItem.ThumbnailURL)
+	}
+}
+
+func TestFilterNewsByTags(t *testing.T) {
+	newsItems := []types.NewsItem{
+		{ID: 1, Tags: []string{"star-trek-online"}},
+		{ID: 2, Tags: []string{"patch-notes"}},
+		{ID: 3, Tags: []string{"events", "star-trek-online"}},
+		{ID: 4},
+	}
+
+	tests := []struct {
+		name     string
+		tags     []string
+		expected []int64
+	}{
+		{"no filter", nil, []int64{1, 2, 3, 4}},
+		{"single tag", []string{"patch-notes"}, []int64{2}},
+		{"any of several tags", []string{"patch-notes", "events"}, []int64{2, 3}},
+		{"case-insensitive", []string{"Patch-Notes"}, []int64{2}},
+		{"no match", []string{"dev-blogs"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []int64
+			for _, item := range filterNewsByTags(newsItems, tt.tags) {
+				ids = append(ids, item.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("Expected news %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+}
+
+func TestFilterNewsByPlatforms(t *testing.T) {
+	newsItems := []types.NewsItem{
+		{ID: 1, Platforms: []string{"pc"}},
+		{ID: 2, Platforms: []string{"ps"}},
+		{ID: 3, Platforms: []string{"XBOX", "ps"}},
+		{ID: 4},
+	}
+
+	tests := []struct {
+		name      string
+		platforms []string
+		expected  []int64
+	}{
+		{"no filter", nil, []int64{1, 2, 3, 4}},
+		{"pc only keeps items without platforms", []string{"pc"}, []int64{1, 4}},
+		{"case-insensitive", []string{"xbox"}, []int64{3, 4}},
+		{"several platforms", []string{"pc", "ps"}, []int64{1, 2, 3, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []int64
+			for _, item := range filterNewsByPlatforms(newsItems, tt.platforms) {
+				ids = append(ids, item.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("Expected news %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+}
+
+func TestIsNewsFresh(t *testing.T) {
+	clock := time.Date(2024, 6, 11, 16, 0, 0, 0, time.UTC)
+	originalNow := now
+	now = func() time.Time { return clock }
+	t.Cleanup(func() { now = originalNow })
+
+	bot := &types.Bot{Config: &types.Config{FreshSeconds: 600}}
+	tests := []struct {
+		name    string
+		updated time.Time
+		fresh   bool
+	}{
+		{"just released", clock.Add(-time.Minute), true},
+		{"stale", clock.Add(-11 * time.Minute), false},
+		{"near future", clock.Add(2 * time.Minute), true},
+		{"far future", clock.Add(time.Hour), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if fresh := IsNewsFresh(bot, types.NewsItem{ID: 1, Updated: tt.updated}, nil); fresh != tt.fresh {
+				t.Errorf("Expected fresh %v, got %v", tt.fresh, fresh)
+			}
+		})
+	}
+}
+
+func TestIsFutureDated(t *testing.T) {
+	clock := time.Date(2024, 6, 11, 16, 0, 0, 0, time.UTC)
+	originalNow := now
+	now = func() time.Time { return clock }
+	t.Cleanup(func() { now = originalNow })
+
+	tests := []struct {
+		name    string
+		skew    int
+		updated time.Time
+		future  bool
+	}{
+		{"past", 0, clock.Add(-time.Hour), false},
+		{"near future", 0, clock.Add(4 * time.Minute), false},
+		{"at the default skew", 0, clock.Add(5 * time.Minute), false},
+		{"far future", 0, clock.Add(6 * time.Minute), true},
+		{"within a wider skew", 3600, clock.Add(30 * time.Minute), false},
+		{"beyond a narrower skew", 60, clock.Add(2 * time.Minute), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &types.Bot{Config: &types.Config{FutureSkewSeconds: tt.skew}}
+			if future := isFutureDated(bot, types.NewsItem{ID: 1, Updated: tt.updated}); future != tt.future {
+				t.Errorf("Expected future-dated %v, got %v", tt.future, future)
+			}
+		})
+	}
 }
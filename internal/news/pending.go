@@ -0,0 +1,74 @@
+package news
+
+import (
+	"context"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// reservePost records a post as pending before it is sent and reports whether to send it. It is
+// not sent if another post of the item is in progress; if the reservation cannot be written it is
+// sent anyway, as before pending posts were recorded.
+func reservePost(b *types.Bot, channelID string, newsID int64) bool {
+	reserved, err := database.ReservePost(b, newsID, channelID)
+	if err != nil {
+		logger().Errorf("Failed to record pending post of news %d to channel %s: %v", newsID, channelID, err)
+		return true
+	}
+	if !reserved {
+		logger().Infof("Skipping news %d for channel %s: already being posted", newsID, channelID)
+	}
+	return reserved
+}
+
+// releasePost removes the pending record of a post that failed to send, so it is retried.
+func releasePost(b *types.Bot, channelID string, newsID int64) {
+	if err := database.ReleasePost(b, newsID, channelID); err != nil {
+		logger().Errorf("Failed to release pending post of news %d to channel %s: %v", newsID, channelID, err)
+	}
+}
+
+// ReconcilePendingPosts resolves the posts left pending by a bot that stopped between sending a
+// post and marking it as sent, and returns how many it found. Posts found in the channel's recent
+// messages are marked as sent; the others are released, so the poller or catch-up sends them again.
+// Cancelling ctx stops before the next post.
+func ReconcilePendingPosts(ctx context.Context, b *types.Bot) (int, error) {
+	posts, err := database.GetStalePendingPosts(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, post := range posts {
+		if err := ctx.Err(); err != nil {
+			return i, err
+		}
+		if wasSent(b, post) {
+			logger().Infof("Pending post of news %d to channel %s was sent, marking it as posted", post.NewsID, post.ChannelID)
+			if err := database.MarkNewsAsPosted(b, post.NewsID, post.ChannelID); err != nil {
+				logger().Errorf("Failed to mark news %d as posted: %v", post.NewsID, err)
+			}
+			continue
+		}
+		logger().Infof("Pending post of news %d to channel %s was not sent, posting it again", post.NewsID, post.ChannelID)
+		releasePost(b, post.ChannelID, post.NewsID)
+	}
+
+	return len(posts), nil
+}
+
+// wasSent reports whether a pending post appears in its channel's recent messages. Without the
+// cached news item, the channel or the duplicate check it cannot be verified and counts as not sent.
+func wasSent(b *types.Bot, post database.PendingPost) bool {
+	newsItem, err := database.GetCachedNewsByID(b, post.NewsID)
+	if err != nil || newsItem == nil {
+		logger().Warnf("Cannot verify pending post of news %d: not cached (%v)", post.NewsID, err)
+		return false
+	}
+	cfg, err := database.GetChannelConfig(b, post.ChannelID)
+	if err != nil || cfg == nil {
+		logger().Warnf("Cannot verify pending post to channel %s: not registered (%v)", post.ChannelID, err)
+		return false
+	}
+	return isDuplicatePost(b, *cfg, *newsItem)
+}
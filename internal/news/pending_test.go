@@ -0,0 +1,102 @@
+package news
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// reservePendingPost records a pending post as left by a bot that stopped while sending it,
+// started age ago.
+func reservePendingPost(t *testing.T, bot *types.Bot, newsID int64, channelID string, age time.Duration) {
+	t.Helper()
+	if reserved, err := database.ReservePost(bot, newsID, channelID); err != nil || !reserved {
+		t.Fatalf("Failed to reserve news %d: %v, %v", newsID, reserved, err)
+	}
+	postedAt := time.Now().UTC().Add(-age).Format("2006-01-02 15:04:05")
+	if _, err := bot.DB.Exec(`UPDATE posted_news SET posted_at = ? WHERE news_id = ? AND channel_id = ?`, postedAt, newsID, channelID); err != nil {
+		t.Fatalf("Failed to age pending post: %v", err)
+	}
+}
+
+func TestRunPollCycleSkipsPostsInProgress(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, pollCycleNews(), "channel-a")
+	reservePendingPost(t, bot, 1, "channel-a", time.Minute)
+
+	summary, err := RunPollCycle(context.Background(), bot)
+	if err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+	if summary.Posted != 1 {
+		t.Errorf("Expected only the news not in progress to be posted, got %+v", summary)
+	}
+	if calls := fake.RequestsTo("POST", "/channels/channel-a/messages"); len(calls) != 1 {
+		t.Errorf("Expected 1 post, got %d", len(calls))
+	}
+}
+
+func TestRunPollCycleReleasesFailedPosts(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, pollCycleNews(), "channel-a")
+	fake.Handle("POST", "/channels/channel-a/messages", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusBadRequest, map[string]interface{}{"message": "Invalid Form Body", "code": 50035})
+	})
+
+	if _, err := RunPollCycle(context.Background(), bot); err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+
+	var rows int
+	if err := bot.DB.QueryRow(`SELECT COUNT(*) FROM posted_news`).Scan(&rows); err != nil {
+		t.Fatalf("Failed to count posted news: %v", err)
+	}
+	if rows != 0 {
+		t.Errorf("Expected failed posts to leave no record, got %d rows", rows)
+	}
+}
+
+func TestReconcilePendingPosts(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, pollCycleNews(), "channel-a")
+	if err := database.CacheNews(bot, pollCycleNews()); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+
+	// The bot stopped after sending news 1 but before marking it, and while sending news 2
+	reservePendingPost(t, bot, 1, "channel-a", time.Hour)
+	reservePendingPost(t, bot, 2, "channel-a", time.Hour)
+	fake.Handle("GET", "/channels/channel-a/messages", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusOK, []map[string]interface{}{{
+			"id":     "msg-sent",
+			"author": map[string]interface{}{"id": "bot-user"},
+			"embeds": []map[string]interface{}{{"title": "Season Update"}},
+		}})
+	})
+
+	pending, err := ReconcilePendingPosts(context.Background(), bot)
+	if err != nil {
+		t.Fatalf("Failed to reconcile pending posts: %v", err)
+	}
+	if pending != 2 {
+		t.Errorf("Expected 2 pending posts, got %d", pending)
+	}
+	if stale, err := database.GetStalePendingPosts(bot); err != nil || len(stale) != 0 {
+		t.Errorf("Expected no pending posts left, got %+v (%v)", stale, err)
+	}
+
+	// Only the post that never reached the channel is sent again
+	if _, err := RunPollCycle(context.Background(), bot); err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+	calls := fake.RequestsTo("POST", "/channels/channel-a/messages")
+	if len(calls) != 1 {
+		t.Fatalf("Expected 1 post after reconciling, got %d", len(calls))
+	}
+	if !strings.Contains(string(calls[0].Body), "Patch Notes for 6/11/24") {
+		t.Errorf("Expected news 2 to be posted again, got %s", calls[0].Body)
+	}
+}
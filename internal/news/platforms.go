@@ -0,0 +1,68 @@
+package news
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// patchNotesTag is the tag of patch note articles.
+const patchNotesTag = "patch-notes"
+
+// titlePlatformPatterns map platform names as written in article titles to channel platforms.
+// "Console" covers both console platforms.
+var titlePlatformPatterns = []struct {
+	pattern   *regexp.Regexp
+	platforms []string
+}{
+	{regexp.MustCompile(`(?i)\bpc\b`), []string{"pc"}},
+	{regexp.MustCompile(`(?i)\bxbox\b`), []string{"xbox"}},
+	{regexp.MustCompile(`(?i)\bplaystation\b|\bps[45]?\b`), []string{"ps"}},
+	{regexp.MustCompile(`(?i)\bconsoles?\b`), []string{"xbox", "ps"}},
+}
+
+// titlePlatforms returns the platforms mentioned in an article title, in pc, xbox, ps order.
+// A title that names no platform is platform-neutral and returns nil.
+func titlePlatforms(title string) []string {
+	mentioned := make(map[string]bool)
+	for _, p := range titlePlatformPatterns {
+		if p.pattern.MatchString(title) {
+			for _, platform := range p.platforms {
+				mentioned[platform] = true
+			}
+		}
+	}
+
+	var platforms []string
+	for _, platform := range []string{"pc", "xbox", "ps"} {
+		if mentioned[platform] {
+			platforms = append(platforms, platform)
+		}
+	}
+	return platforms
+}
+
+// matchesStrictPatchNotes reports whether a news item may be posted to a channel in strict
+// platform patch notes mode. Patch notes whose title names platforms are only posted when one
+// of them is a channel platform; other articles and platform-neutral titles always pass.
+func matchesStrictPatchNotes(cfg database.ChannelConfig, newsItem types.NewsItem) bool {
+	if !cfg.StrictPatchNotes || !newsItem.HasTag(patchNotesTag) {
+		return true
+	}
+
+	mentioned := titlePlatforms(newsItem.Title)
+	if len(mentioned) == 0 {
+		return true
+	}
+
+	for _, platform := range mentioned {
+		for _, channelPlatform := range cfg.Platforms {
+			if strings.EqualFold(platform, strings.TrimSpace(channelPlatform)) {
+				return true
+			}
+		}
+	}
+	return false
+}
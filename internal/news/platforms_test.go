@@ -0,0 +1,73 @@
+package news
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+func TestTitlePlatforms(t *testing.T) {
+	tests := []struct {
+		title    string
+		expected []string
+	}{
+		{"PC Patch Notes for 6/11/24", []string{"pc"}},
+		{"Star Trek Online: PC Patch Notes for 3/2/23", []string{"pc"}},
+		{"Console Patch Notes for 6/13/24", []string{"xbox", "ps"}},
+		{"Consoles Patch Notes for 1/18/24", []string{"xbox", "ps"}},
+		{"Xbox and PlayStation Patch Notes for 6/13", []string{"xbox", "ps"}},
+		{"Xbox Patch Notes for 9/5/23", []string{"xbox"}},
+		{"PS4 Patch Notes for 1/17/23", []string{"ps"}},
+		{"Patch Notes for PC and Console - 4/4/24", []string{"pc", "xbox", "ps"}},
+		{"Patch Notes for 6/11/24", nil},
+		{"Star Trek Online: Patch Notes for 11/7/23", nil},
+		{"PSA: Maintenance Extended", nil},
+		{"Epic Phoenix Prize Pack Returns", nil},
+		{"pc patch notes for 2/8/24", []string{"pc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			if got := titlePlatforms(tt.title); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMatchesStrictPatchNotes(t *testing.T) {
+	allPlatforms := []string{"pc", "xbox", "ps"}
+	console := []string{"xbox", "ps"}
+
+	patchNotes := func(title string) types.NewsItem {
+		return types.NewsItem{Title: title, Tags: []string{"patch-notes"}, Platforms: allPlatforms}
+	}
+
+	tests := []struct {
+		name     string
+		cfg      database.ChannelConfig
+		item     types.NewsItem
+		expected bool
+	}{
+		{"strict off posts PC notes to console", database.ChannelConfig{Platforms: console}, patchNotes("PC Patch Notes for 6/11/24"), true},
+		{"PC notes skipped for console", database.ChannelConfig{Platforms: console, StrictPatchNotes: true}, patchNotes("PC Patch Notes for 6/11/24"), false},
+		{"console notes posted to console", database.ChannelConfig{Platforms: console, StrictPatchNotes: true}, patchNotes("Console Patch Notes for 6/13/24"), true},
+		{"console notes skipped for PC", database.ChannelConfig{Platforms: []string{"pc"}, StrictPatchNotes: true}, patchNotes("Console Patch Notes for 6/13/24"), false},
+		{"Xbox notes posted to mixed channel", database.ChannelConfig{Platforms: []string{"pc", "xbox"}, StrictPatchNotes: true}, patchNotes("Xbox Patch Notes for 9/5/23"), true},
+		{"PlayStation notes skipped for Xbox", database.ChannelConfig{Platforms: []string{"xbox"}, StrictPatchNotes: true}, patchNotes("PS4 Patch Notes for 1/17/23"), false},
+		{"neutral title posted", database.ChannelConfig{Platforms: console, StrictPatchNotes: true}, patchNotes("Patch Notes for 6/11/24"), true},
+		{"channel platforms with spaces", database.ChannelConfig{Platforms: []string{"pc", " ps"}, StrictPatchNotes: true}, patchNotes("PS4 Patch Notes for 1/17/23"), true},
+		{"non patch notes unaffected", database.ChannelConfig{Platforms: console, StrictPatchNotes: true},
+			types.NewsItem{Title: "Escalation Campaign Returns to PC", Tags: []string{"star-trek-online"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesStrictPatchNotes(tt.cfg, tt.item); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package news
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/metrics"
+	"github.com/FracKenA/sto_news_discord_bot/internal/ratelimit"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// PollCycleSummary reports the outcome of one poll cycle.
+type PollCycleSummary struct {
+	Channels int // Channels is the number of channels visited.
+	Fetched  int // Fetched is the number of news items fetched.
+	Posted   int // Posted is the number of news posts sent.
+	Failed   int // Failed is the number of news posts and direct messages that could not be sent.
+	Updated  int // Updated is the number of channels told that an article posted there was updated.
+
+	Delivered int // Delivered is the number of news items sent to subscribers by direct message.
+}
+
+// String returns a one-line summary of the cycle.
+func (s PollCycleSummary) String() string {
+	return fmt.Sprintf("%d channels, %d news items fetched, %d posted, %d failed, %d update notices, %d sent to subscribers",
+		s.Channels, s.Fetched, s.Posted, s.Failed, s.Updated, s.Delivered)
+}
+
+// DefaultPollTags are the news tags fetched by each poll cycle when the config sets none on the
+// command line. The API's default feed omits some patch notes and dev blogs, which are only
+// listed under their tag.
+var DefaultPollTags = []string{"star-trek-online", "patch-notes", "events", "dev-blogs"}
+
+// MessageSendRate is the number of news posts sent per second, across all channels.
+const MessageSendRate = ratelimit.MessageRate
+
+// DefaultPostConcurrency is the number of channels posted to at once when the config sets none.
+const DefaultPostConcurrency = 3
+
+// PollJitter is the fraction of the poll period each interval between poll cycles is randomly
+// lengthened or shortened by, so several bot instances do not poll the news API in step.
+const PollJitter = 0.1
+
+// pollRandom returns a random number in [0, 1) for the jitter of poll intervals (replaced in tests).
+var pollRandom = rand.Float64
+
+// newPollTimer returns a channel receiving once after d and a function stopping the timer
+// (replaced in tests).
+var newPollTimer = func(d time.Duration) (<-chan time.Time, func() bool) {
+	timer := time.NewTimer(d)
+	return timer.C, timer.Stop
+}
+
+// PollStats reports how this process's poll cycles have been running.
+type PollStats struct {
+	LastCycle         time.Time     // LastCycle is when the last cycle completed; zero if none has.
+	LastCycleDuration time.Duration // LastCycleDuration is how long the poller's last completed cycle took.
+	SkippedCycles     int           // SkippedCycles counts cycles skipped because the previous one was still running.
+}
+
+// pollStats records this process's poll cycles.
+var pollStats struct {
+	sync.Mutex
+	PollStats
+}
+
+// GetPollStats returns how this process's poll cycles have been running.
+func GetPollStats() PollStats {
+	pollStats.Lock()
+	defer pollStats.Unlock()
+	return pollStats.PollStats
+}
+
+// LastPollCycle returns when this process last completed a poll cycle successfully,
+// or the zero time if none has completed yet.
+func LastPollCycle() time.Time {
+	return GetPollStats().LastCycle
+}
+
+// recordPollCycle marks a poll cycle as completed now.
+func recordPollCycle() {
+	pollStats.Lock()
+	pollStats.LastCycle = time.Now()
+	pollStats.Unlock()
+}
+
+// recordPollCycleDuration records how long the poller's last cycle took.
+func recordPollCycleDuration(d time.Duration) {
+	pollStats.Lock()
+	pollStats.LastCycleDuration = d
+	pollStats.Unlock()
+}
+
+// recordSkippedPollCycle counts a poll cycle skipped because the previous one was still running.
+func recordSkippedPollCycle() {
+	pollStats.Lock()
+	pollStats.SkippedCycles++
+	pollStats.Unlock()
+	metrics.PollCyclesSkipped.Inc()
+}
+
+// pollInterval returns the time until the next poll cycle: the poll period, lengthened or
+// shortened by up to PollJitter of it at random.
+func pollInterval(period time.Duration) time.Duration {
+	jitter := (pollRandom()*2 - 1) * PollJitter
+	return period + time.Duration(float64(period)*jitter)
+}
+
+// fetchPollNews fetches the news of a poll cycle: PollCount items of each of the config's poll
+// tags, merged with each item once and sorted newest first, or PollCount items of the API's
+// default feed when the config has no poll tags. A tag that fails to fetch is logged and skipped;
+// an error is only returned when every tag fails.
+func fetchPollNews(b *types.Bot) ([]types.NewsItem, error) {
+	tags := b.Config.PollTags
+	if len(tags) == 0 {
+		return FetchNews(b, "", b.Config.PollCount, DefaultFetchOptions())
+	}
+
+	var newsItems []types.NewsItem
+	var errs []error
+	seen := make(map[int64]bool)
+	for _, tag := range tags {
+		items, err := FetchNews(b, tag, b.Config.PollCount, DefaultFetchOptions())
+		if err != nil {
+			logger().Errorf("Failed to fetch news for tag %s: %v", tag, err)
+			errs = append(errs, fmt.Errorf("tag %s: %v", tag, err))
+			continue
+		}
+		for _, item := range items {
+			if !seen[item.ID] {
+				seen[item.ID] = true
+				newsItems = append(newsItems, item)
+			}
+		}
+	}
+	if len(errs) == len(tags) {
+		return nil, errors.Join(errs...)
+	}
+
+	// Newest first, like the default feed
+	sort.SliceStable(newsItems, func(i, j int) bool {
+		return newsItems[i].Updated.After(newsItems[j].Updated)
+	})
+	return newsItems, nil
+}
+
+// RunPollCycle performs one fetch-and-post cycle: it fetches and caches the latest news once,
+// posts unposted news to every active channel, sends fresh news to subscribers by direct message,
+// retries queued publishes and cleans the cache.
+//
+// Posting only uses the Discord REST API, so the session does not need an open gateway
+// connection. Unless disabled in the config, each channel's recent messages are checked
+// before posting so articles already visible there are not repeated.
+// An error is returned when the cycle could not run at all; individual posting failures are
+// logged and counted in the summary. Up to Config.PostConcurrency channels are posted to at once,
+// and posts to all of them are paced to MessageSendRate per second. Cancelling ctx stops the
+// cycle before the next channel and each channel before its next post.
+//
+// A dry run only logs the posts to channels: it sends no updates, direct messages or publishes.
+func RunPollCycle(ctx context.Context, b *types.Bot) (PollCycleSummary, error) {
+	var summary PollCycleSummary
+
+	// Only channels that match the current environment (all channels if none is set)
+	var channels []database.ChannelConfig
+	err := database.ForEachActiveChannel(b, func(cfg database.ChannelConfig) error {
+		if len(cfg.Platforms) == 0 {
+			logger().Debugf("Channel %s has no platforms", cfg.ID)
+			return nil
+		}
+		if isSettingUp(cfg.ID) {
+			channelLogger(cfg.ID).Debugf("Skipping channel %s: it is being set up", cfg.ID)
+			return nil
+		}
+		channels = append(channels, cfg)
+		return nil
+	})
+	if err != nil {
+		return summary, fmt.Errorf("failed to list registered channels: %v", err)
+	}
+	metrics.RegisteredChannels.Set(len(channels))
+
+	subscriptions, err := database.GetUserSubscriptions(b)
+	if err != nil {
+		logger().Errorf("Failed to get subscriptions: %v", err)
+	}
+	if len(channels) == 0 && len(subscriptions) == 0 {
+		logger().Debug("No registered channels or subscribers found")
+		recordPollCycle()
+		return summary, nil
+	}
+
+	// Fetch all news once for every channel (no platform filtering)
+	newsItems, err := fetchPollNews(b)
+	if err != nil {
+		return summary, fmt.Errorf("failed to fetch news: %v", err)
+	}
+	summary.Fetched = len(newsItems)
+	if err := ctx.Err(); err != nil {
+		return summary, fmt.Errorf("poll cycle interrupted: %v", err)
+	}
+
+	// Articles dated later than their cached version were updated since they were posted. They
+	// are announced only once the cache holds the new date, so an update is never announced twice.
+	updated := findUpdatedNews(b, newsItems)
+
+	// Write all news to DB (cache). A dry run leaves updated articles cached as they were, so the
+	// next real run still announces their updates.
+	cacheItems := newsItems
+	if b.Config.DryRun && len(updated) > 0 {
+		cacheItems = withoutNews(newsItems, updated)
+	}
+	if err := database.CacheNews(b, cacheItems); err != nil {
+		logger().Errorf("Failed to cache news items: %v", err)
+	} else if len(updated) > 0 && b.Config.DryRun {
+		logger().Infof("[dry-run] Would announce updates to %d news items", len(updated))
+	} else if len(updated) > 0 {
+		summary.Updated = notifyNewsUpdates(ctx, b, updated)
+	}
+
+	concurrency := b.Config.PostConcurrency
+	if concurrency <= 0 {
+		concurrency = DefaultPostConcurrency
+	}
+
+	// A fixed pool of workers posts to the channels; ratelimit.Messages paces their sends
+	work := make(chan database.ChannelConfig)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for worker := 0; worker < concurrency && worker < len(channels); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for cfg := range work {
+				if cfg.GuildID == "" && !b.Config.DryRun {
+					resolveChannelGuild(b, cfg.ID)
+				}
+				posted, failed := postUnpostedNews(ctx, b, cfg, newsItems)
+
+				mu.Lock()
+				summary.Posted += posted
+				summary.Failed += failed
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, cfg := range channels {
+		if ctx.Err() != nil {
+			break
+		}
+		summary.Channels++
+		work <- cfg
+	}
+	close(work)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return summary, fmt.Errorf("poll cycle interrupted: %v", err)
+	}
+
+	if !b.Config.DryRun {
+		delivered, failed := deliverSubscriptions(ctx, b, subscriptions, newsItems)
+		summary.Delivered = delivered
+		summary.Failed += failed
+		if err := ctx.Err(); err != nil {
+			return summary, fmt.Errorf("poll cycle interrupted: %v", err)
+		}
+
+		// Retry publishes that were deferred by the rate limit
+		PublishQueued(b)
+	}
+
+	// Clean old cache every poll cycle
+	if err := database.CleanOldCache(b); err != nil {
+		logger().Errorf("Failed to clean old cache: %v", err)
+	}
+
+	recordPollCycle()
+	return summary, nil
+}
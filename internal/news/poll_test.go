@@ -0,0 +1,1187 @@
+package news
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/metrics"
+	"github.com/FracKenA/sto_news_discord_bot/internal/ratelimit"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+// setMessageLimiter replaces the limiter pacing news posts for the duration of a test.
+func setMessageLimiter(t *testing.T, limiter *ratelimit.Limiter) {
+	t.Helper()
+	original := ratelimit.Messages
+	ratelimit.Messages = limiter
+	t.Cleanup(func() { ratelimit.Messages = original })
+}
+
+// setupPollCycleTest creates a file-backed bot talking to a fake Discord server, with the news
+// API pointed at a mock server serving newsItems, and the given channels registered.
+func setupPollCycleTest(t *testing.T, newsItems []types.NewsItem, channels ...string) (*types.Bot, *testhelpers.FakeDiscord) {
+	t.Helper()
+	skipRetryDelays(t)
+	setMessageLimiter(t, ratelimit.New(1e6, 1e6))
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(NewsResponse{News: newsItems})
+	}))
+	t.Cleanup(api.Close)
+
+	db, err := database.InitDatabase(filepath.Join(t.TempDir(), "stobot.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	fake := testhelpers.NewFakeDiscord(t)
+	bot := &types.Bot{
+		Session: fake.Session(),
+		DB:      db,
+		Config:  &types.Config{PollCount: 10, BaseURL: api.URL},
+	}
+
+	for _, channelID := range channels {
+		if err := database.AddChannel(bot, channelID); err != nil {
+			t.Fatalf("Failed to add channel: %v", err)
+		}
+	}
+
+	return bot, fake
+}
+
+func pollCycleNews() []types.NewsItem {
+	return []types.NewsItem{
+		{ID: 1, Title: "Season Update", Summary: "New season", Tags: []string{"star-trek-online"}, Platforms: []string{"pc", "xbox", "ps"}, Updated: time.Now()},
+		{ID: 2, Title: "Patch Notes for 6/11/24", Summary: "Fixes", Tags: []string{"patch-notes"}, Platforms: []string{"pc"}, Updated: time.Now()},
+	}
+}
+
+func TestRunPollCycle(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, pollCycleNews(), "channel-a", "channel-b")
+
+	summary, err := RunPollCycle(context.Background(), bot)
+	if err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+	expected := PollCycleSummary{Channels: 2, Fetched: 2, Posted: 4}
+	if summary != expected {
+		t.Errorf("Expected summary %+v, got %+v", expected, summary)
+	}
+
+	for _, channelID := range []string{"channel-a", "channel-b"} {
+		if calls := fake.RequestsTo("POST", "/channels/"+channelID+"/messages"); len(calls) != 2 {
+			t.Errorf("Expected 2 posts to %s, got %d", channelID, len(calls))
+		}
+		posted, err := database.IsNewsPosted(bot, 2, channelID)
+		if err != nil {
+			t.Fatalf("Failed to check posted news: %v", err)
+		}
+		if !posted {
+			t.Errorf("Expected news 2 to be marked posted in %s", channelID)
+		}
+	}
+
+	// The database is the only state: a second cycle posts nothing new
+	summary, err = RunPollCycle(context.Background(), bot)
+	if err != nil {
+		t.Fatalf("Second poll cycle failed: %v", err)
+	}
+	if summary.Posted != 0 || summary.Failed != 0 {
+		t.Errorf("Expected nothing posted on the second cycle, got %+v", summary)
+	}
+}
+
+func TestFetchPollNews(t *testing.T) {
+	day := time.Date(2024, 6, 11, 16, 0, 0, 0, time.UTC)
+	newsByTag := map[string][]types.NewsItem{
+		"star-trek-online": {
+			{ID: 3, Title: "Season Update", Updated: day.Add(2 * time.Hour)},
+			{ID: 1, Title: "Patch Notes", Updated: day},
+		},
+		"patch-notes": {
+			{ID: 1, Title: "Patch Notes", Updated: day},
+			{ID: 2, Title: "Xbox Patch Notes", Updated: day.Add(time.Hour)},
+		},
+		"events": {
+			{ID: 3, Title: "Season Update", Updated: day.Add(2 * time.Hour)},
+		},
+	}
+	fetcher := testhelpers.NewFakeNewsFetcher()
+	fetcher.Fetch = func(tag string, count int, options types.FetchOptions) ([]types.NewsItem, error) {
+		if tag == "dev-blogs" {
+			return nil, errors.New("source unavailable")
+		}
+		return newsByTag[tag], nil
+	}
+
+	tests := []struct {
+		name     string
+		tags     []string
+		expected []int64
+		err      bool
+	}{
+		{"overlapping tags merged newest first", []string{"star-trek-online", "patch-notes", "events"}, []int64{3, 2, 1}, false},
+		{"failing tag skipped", []string{"dev-blogs", "patch-notes"}, []int64{2, 1}, false},
+		{"every tag failing", []string{"dev-blogs"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &types.Bot{Config: &types.Config{PollCount: 5, PollTags: tt.tags}, Fetcher: fetcher}
+			newsItems, err := fetchPollNews(bot)
+			if (err != nil) != tt.err {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			var ids []int64
+			for _, newsItem := range newsItems {
+				ids = append(ids, newsItem.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("Expected news %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+
+	// Each tag is fetched once, PollCount items at a time
+	bot := &types.Bot{Config: &types.Config{PollCount: 5, PollTags: []string{"events", "patch-notes"}}, Fetcher: fetcher}
+	before := len(fetcher.Calls())
+	if _, err := fetchPollNews(bot); err != nil {
+		t.Fatalf("Failed to fetch poll news: %v", err)
+	}
+	calls := fetcher.Calls()[before:]
+	if len(calls) != 2 || calls[0].Tag != "events" || calls[1].Tag != "patch-notes" || calls[0].Count != 5 {
+		t.Errorf("Expected one fetch of 5 items per tag, got %+v", calls)
+	}
+}
+
+func TestRunPollCycleLogFields(t *testing.T) {
+	bot, _ := setupPollCycleTest(t, pollCycleNews(), "channel-a")
+	hook := test.NewGlobal()
+	t.Cleanup(func() { log.StandardLogger().ReplaceHooks(make(log.LevelHooks)) })
+
+	if _, err := RunPollCycle(context.Background(), bot); err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+
+	posts := 0
+	for _, entry := range hook.AllEntries() {
+		if !strings.HasPrefix(entry.Message, "Posted news item") {
+			continue
+		}
+		posts++
+		if entry.Data["component"] != "news" || entry.Data["channel_id"] != "channel-a" || entry.Data["news_id"] == nil {
+			t.Errorf("Expected news, channel and component fields, got %v", entry.Data)
+		}
+	}
+	if posts != 2 {
+		t.Errorf("Expected a log line per post, got %d", posts)
+	}
+}
+
+func TestRunPollCyclePollTags(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, nil, "channel-a")
+	news := pollCycleNews()
+	devBlog := types.NewsItem{ID: 3, Title: "Dev Blog", Tags: []string{"dev-blogs"}, Platforms: []string{"pc", "xbox", "ps"}, Updated: time.Now()}
+	fetcher := testhelpers.NewFakeNewsFetcher()
+	fetcher.Fetch = func(tag string, count int, options types.FetchOptions) ([]types.NewsItem, error) {
+		switch tag {
+		case "patch-notes":
+			return []types.NewsItem{news[1]}, nil
+		case "dev-blogs":
+			// Only listed under its tag
+			return []types.NewsItem{devBlog}, nil
+		default:
+			return news, nil
+		}
+	}
+	bot.Fetcher = fetcher
+	bot.Config.PollTags = []string{"star-trek-online", "patch-notes", "dev-blogs"}
+
+	summary, err := RunPollCycle(context.Background(), bot)
+	if err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+	if summary.Fetched != 3 || summary.Posted != 3 {
+		t.Errorf("Expected the 3 merged news items fetched and posted, got %+v", summary)
+	}
+	if calls := fake.RequestsTo("POST", "/channels/channel-a/messages"); len(calls) != 3 {
+		t.Errorf("Expected 3 posts, got %d", len(calls))
+	}
+	if calls := fetcher.Calls(); len(calls) != 3 {
+		t.Errorf("Expected one fetch per poll tag, got %+v", calls)
+	}
+}
+
+func TestRunPollCycleFetchesOnce(t *testing.T) {
+	channels := []string{"channel-a", "channel-b", "channel-c", "channel-d", "channel-e"}
+	bot, fake := setupPollCycleTest(t, nil, channels...)
+	fetcher := testhelpers.NewFakeNewsFetcher(pollCycleNews()...)
+	bot.Fetcher = fetcher
+
+	summary, err := RunPollCycle(context.Background(), bot)
+	if err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+	if calls := fetcher.Calls(); len(calls) != 1 || calls[0].Tag != "" || calls[0].Count != bot.Config.PollCount {
+		t.Errorf("Expected 1 fetch of all news for %d channels, got %+v", len(channels), calls)
+	}
+	if summary.Channels != len(channels) || summary.Posted != 2*len(channels) {
+		t.Errorf("Expected every channel to get both news items, got %+v", summary)
+	}
+	for _, channelID := range channels {
+		if calls := fake.RequestsTo("POST", "/channels/"+channelID+"/messages"); len(calls) != 2 {
+			t.Errorf("Expected 2 posts to %s, got %d", channelID, len(calls))
+		}
+	}
+
+	// Each cycle fetches once more
+	if _, err := RunPollCycle(context.Background(), bot); err != nil {
+		t.Fatalf("Second poll cycle failed: %v", err)
+	}
+	if fetches := len(fetcher.Calls()); fetches != 2 {
+		t.Errorf("Expected 2 fetches after two cycles, got %d", fetches)
+	}
+}
+
+func TestRunPollCyclePartialFailure(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, pollCycleNews(), "channel-a", "channel-b")
+	fake.Handle("POST", "/channels/channel-b/messages", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusForbidden, map[string]interface{}{"code": 50013, "message": "Missing Permissions"})
+	})
+
+	summary, err := RunPollCycle(context.Background(), bot)
+	if err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+	if summary.Posted != 2 || summary.Failed != 2 {
+		t.Errorf("Expected 2 posted and 2 failed, got %+v", summary)
+	}
+
+	// Failed posts are not marked and are retried on the next cycle
+	posted, err := database.IsNewsPosted(bot, 1, "channel-b")
+	if err != nil {
+		t.Fatalf("Failed to check posted news: %v", err)
+	}
+	if posted {
+		t.Error("Expected failed post not to be marked as posted")
+	}
+}
+
+func TestRunPollCycleFetchFailure(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, nil, "channel-a")
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer api.Close()
+	bot.Config.BaseURL = api.URL
+
+	if _, err := RunPollCycle(context.Background(), bot); err == nil {
+		t.Fatal("Expected an error when the news API is down")
+	}
+	if calls := fake.RequestsTo("POST", "/channels/channel-a/messages"); len(calls) != 0 {
+		t.Errorf("Expected no posts, got %d", len(calls))
+	}
+}
+
+func TestRunPollCycleNoChannels(t *testing.T) {
+	bot, _ := setupPollCycleTest(t, pollCycleNews())
+
+	summary, err := RunPollCycle(context.Background(), bot)
+	if err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+	if summary != (PollCycleSummary{}) {
+		t.Errorf("Expected an empty summary without channels, got %+v", summary)
+	}
+}
+
+func TestRunPollCycleCancelled(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, pollCycleNews(), "channel-a")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := RunPollCycle(ctx, bot); err == nil {
+		t.Fatal("Expected an error for a cancelled cycle")
+	}
+	if calls := fake.RequestsTo("POST", "/channels/channel-a/messages"); len(calls) != 0 {
+		t.Errorf("Expected no posts after cancellation, got %d", len(calls))
+	}
+}
+
+func TestLastPollCycle(t *testing.T) {
+	bot, _ := setupPollCycleTest(t, pollCycleNews(), "channel-a")
+
+	before := time.Now()
+	if _, err := RunPollCycle(context.Background(), bot); err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+	completed := LastPollCycle()
+	if completed.Before(before) {
+		t.Fatalf("Expected the completed cycle to be recorded, got %v", completed)
+	}
+
+	// A failed cycle does not count as a successful poll
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer api.Close()
+	bot.Config.BaseURL = api.URL
+
+	if _, err := RunPollCycle(context.Background(), bot); err == nil {
+		t.Fatal("Expected an error when the news API is down")
+	}
+	if last := LastPollCycle(); !last.Equal(completed) {
+		t.Errorf("Expected the last poll cycle to stay %v after a failure, got %v", completed, last)
+	}
+}
+
+func TestRunPollCycleChannelTags(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, pollCycleNews(), "channel-a", "channel-b")
+	if err := database.UpdateChannelTags(bot, "channel-a", []string{"patch-notes"}); err != nil {
+		t.Fatalf("Failed to update channel tags: %v", err)
+	}
+
+	if _, err := RunPollCycle(context.Background(), bot); err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+
+	// channel-a only gets the patch notes, channel-b gets everything
+	if calls := fake.RequestsTo("POST", "/channels/channel-a/messages"); len(calls) != 1 {
+		t.Errorf("Expected 1 post to channel-a, got %d", len(calls))
+	}
+	if calls := fake.RequestsTo("POST", "/channels/channel-b/messages"); len(calls) != 2 {
+		t.Errorf("Expected 2 posts to channel-b, got %d", len(calls))
+	}
+	for newsID, expected := range map[int64]bool{1: false, 2: true} {
+		posted, err := database.IsNewsPosted(bot, newsID, "channel-a")
+		if err != nil {
+			t.Fatalf("Failed to check posted news: %v", err)
+		}
+		if posted != expected {
+			t.Errorf("Expected news %d posted to channel-a to be %v, got %v", newsID, expected, posted)
+		}
+	}
+}
+
+func TestRunPollCycleChannelPlatforms(t *testing.T) {
+	newsItems := []types.NewsItem{
+		{ID: 10, Title: "PlayStation Maintenance", Summary: "PS only", Tags: []string{"star-trek-online"}, Platforms: []string{"ps"}, Updated: time.Now()},
+		{ID: 11, Title: "PC Event", Summary: "PC only", Tags: []string{"star-trek-online"}, Platforms: []string{"pc"}, Updated: time.Now()},
+		{ID: 12, Title: "Community News", Summary: "No platforms", Tags: []string{"star-trek-online"}, Updated: time.Now()},
+	}
+	bot, fake := setupPollCycleTest(t, newsItems, "pc-channel")
+	if err := database.UpdateChannelPlatforms(bot, "pc-channel", []string{"pc"}); err != nil {
+		t.Fatalf("Failed to update channel platforms: %v", err)
+	}
+
+	if _, err := RunPollCycle(context.Background(), bot); err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+
+	if calls := fake.RequestsTo("POST", "/channels/pc-channel/messages"); len(calls) != 2 {
+		t.Errorf("Expected 2 posts to pc-channel, got %d", len(calls))
+	}
+	for newsID, expected := range map[int64]bool{10: false, 11: true, 12: true} {
+		posted, err := database.IsNewsPosted(bot, newsID, "pc-channel")
+		if err != nil {
+			t.Fatalf("Failed to check posted news: %v", err)
+		}
+		if posted != expected {
+			t.Errorf("Expected news %d posted to pc-channel to be %v, got %v", newsID, expected, posted)
+		}
+	}
+}
+
+func TestRunPollCycleEnvironment(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, pollCycleNews())
+	bot.Config.Environment = "DEV"
+	for channelID, environment := range map[string]string{"dev-channel": "DEV", "prod-channel": "PROD"} {
+		if err := database.AddChannelWithEnvironment(bot, channelID, environment); err != nil {
+			t.Fatalf("Failed to add channel: %v", err)
+		}
+	}
+
+	summary, err := RunPollCycle(context.Background(), bot)
+	if err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+	if summary.Channels != 1 || summary.Posted != 2 {
+		t.Errorf("Expected only the DEV channel to be served, got %+v", summary)
+	}
+	if calls := fake.RequestsTo("POST", "/channels/dev-channel/messages"); len(calls) != 2 {
+		t.Errorf("Expected 2 posts to dev-channel, got %d", len(calls))
+	}
+	if calls := fake.RequestsTo("POST", "/channels/prod-channel/messages"); len(calls) != 0 {
+		t.Errorf("Expected a DEV bot not to post to prod-channel, got %d posts", len(calls))
+	}
+}
+
+func TestRunPollCycleRepublishedNews(t *testing.T) {
+	republished := []types.NewsItem{
+		{ID: 20, Title: "Rise of Discovery Launches", Summary: "PC", Content: "Join the crew of the Discovery.", Tags: []string{"star-trek-online"}, Platforms: []string{"pc"}, Updated: time.Now()},
+		{ID: 21, Title: "Rise of Discovery Launches", Summary: "Consoles", Content: "Join the crew of the Discovery.", Tags: []string{"star-trek-online"}, Platforms: []string{"xbox", "ps"}, Updated: time.Now()},
+	}
+
+	tests := []struct {
+		name       string
+		windowDays int
+		posts      int
+	}{
+		{"within the duplicate window", database.DefaultDuplicateWindowDays, 1},
+		{"duplicate check disabled", 0, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot, fake := setupPollCycleTest(t, republished, "channel-a")
+			bot.Config.DuplicateWindowDays = tt.windowDays
+
+			summary, err := RunPollCycle(context.Background(), bot)
+			if err != nil {
+				t.Fatalf("Poll cycle failed: %v", err)
+			}
+			if summary.Posted != tt.posts {
+				t.Errorf("Expected %d posts, got %+v", tt.posts, summary)
+			}
+			if calls := fake.RequestsTo("POST", "/channels/channel-a/messages"); len(calls) != tt.posts {
+				t.Errorf("Expected %d posts to channel-a, got %d", tt.posts, len(calls))
+			}
+
+			// The skipped copy is marked posted, so it is not checked again
+			for _, newsItem := range republished {
+				posted, err := database.IsNewsPosted(bot, newsItem.ID, "channel-a")
+				if err != nil {
+					t.Fatalf("Failed to check posted news: %v", err)
+				}
+				if !posted {
+					t.Errorf("Expected news %d to be marked posted", newsItem.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestRunPollCycleExcludedTags(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, pollCycleNews(), "channel-a", "channel-b")
+	if err := database.SetChannelExcludedTags(bot, "channel-a", []string{"patch-notes"}); err != nil {
+		t.Fatalf("Failed to set excluded tags: %v", err)
+	}
+
+	summary, err := RunPollCycle(context.Background(), bot)
+	if err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+	if summary.Posted != 3 || summary.Failed != 0 {
+		t.Errorf("Expected 3 posted and none failed, got %+v", summary)
+	}
+
+	// channel-a skips the patch notes, channel-b gets everything
+	if calls := fake.RequestsTo("POST", "/channels/channel-a/messages"); len(calls) != 1 {
+		t.Errorf("Expected 1 post to channel-a, got %d", len(calls))
+	}
+	if calls := fake.RequestsTo("POST", "/channels/channel-b/messages"); len(calls) != 2 {
+		t.Errorf("Expected 2 posts to channel-b, got %d", len(calls))
+	}
+
+	// The excluded item is marked as posted so it does not retrigger
+	posted, err := database.IsNewsPosted(bot, 2, "channel-a")
+	if err != nil {
+		t.Fatalf("Failed to check posted news: %v", err)
+	}
+	if !posted {
+		t.Error("Expected excluded news to be marked as posted")
+	}
+}
+
+func TestRunPollCyclePingRole(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, pollCycleNews()[:1], "channel-a", "channel-b")
+	if err := database.UpdateChannelPingRole(bot, "channel-a", "role-1"); err != nil {
+		t.Fatalf("Failed to update ping role: %v", err)
+	}
+
+	if _, err := RunPollCycle(context.Background(), bot); err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+
+	decodePost := func(channelID string) discordgo.MessageSend {
+		t.Helper()
+		calls := fake.RequestsTo("POST", "/channels/"+channelID+"/messages")
+		if len(calls) != 1 {
+			t.Fatalf("Expected 1 post to %s, got %d", channelID, len(calls))
+		}
+		// An explicit empty parse list is what stops Discord resolving @everyone and user mentions
+		if !strings.Contains(string(calls[0].Body), `"parse":[]`) {
+			t.Errorf("Expected an empty parse list, got %s", calls[0].Body)
+		}
+		var msg discordgo.MessageSend
+		if err := json.Unmarshal(calls[0].Body, &msg); err != nil {
+			t.Fatalf("Failed to decode message: %v", err)
+		}
+		return msg
+	}
+
+	// channel-a mentions its role and nothing else
+	msg := decodePost("channel-a")
+	if msg.Content != "<@&role-1>" {
+		t.Errorf("Expected the role mention as content, got %q", msg.Content)
+	}
+	if msg.AllowedMentions == nil || len(msg.AllowedMentions.Parse) != 0 ||
+		!reflect.DeepEqual(msg.AllowedMentions.Roles, []string{"role-1"}) {
+		t.Errorf("Expected only role-1 to be mentionable, got %+v", msg.AllowedMentions)
+	}
+
+	// channel-b has no role and allows no mentions
+	msg = decodePost("channel-b")
+	if msg.Content != "" {
+		t.Errorf("Expected no content, got %q", msg.Content)
+	}
+	if msg.AllowedMentions == nil || len(msg.AllowedMentions.Parse) != 0 || len(msg.AllowedMentions.Roles) != 0 {
+		t.Errorf("Expected no mentions to be allowed, got %+v", msg.AllowedMentions)
+	}
+}
+
+func TestRunPollCycleSkipsRecentDuplicates(t *testing.T) {
+	tests := []struct {
+		name               string
+		skipDuplicateCheck bool
+		expectedPosts      int
+	}{
+		{name: "duplicate check enabled", expectedPosts: 1},
+		{name: "duplicate check disabled", skipDuplicateCheck: true, expectedPosts: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot, fake := setupPollCycleTest(t, pollCycleNews(), "channel-a")
+			bot.Config.MsgCount = 10
+			bot.Config.SkipDuplicateCheck = tt.skipDuplicateCheck
+
+			// The season update is already in the channel from before a database reset;
+			// a user quoting the patch notes does not count
+			fake.Handle("GET", "/channels/channel-a/messages", func(w http.ResponseWriter, r *http.Request) {
+				testhelpers.RespondJSON(w, http.StatusOK, []map[string]interface{}{
+					{"id": "msg-old", "author": map[string]interface{}{"id": "bot-user"},
+						"embeds": []map[string]interface{}{{"title": "Season Update", "description": "New season"}}},
+					{"id": "msg-user", "author": map[string]interface{}{"id": "user-1"},
+						"content": "Patch Notes for 6/11/24"},
+				})
+			})
+
+			if _, err := RunPollCycle(context.Background(), bot); err != nil {
+				t.Fatalf("Poll cycle failed: %v", err)
+			}
+
+			calls := fake.RequestsTo("POST", "/channels/channel-a/messages")
+			if len(calls) != tt.expectedPosts {
+				t.Fatalf("Expected %d posts, got %d", tt.expectedPosts, len(calls))
+			}
+			if !strings.Contains(string(calls[len(calls)-1].Body), "Patch Notes") {
+				t.Errorf("Expected the patch notes to be posted, got %s", calls[len(calls)-1].Body)
+			}
+			if reads := fake.RequestsTo("GET", "/channels/channel-a/messages"); tt.skipDuplicateCheck && len(reads) != 0 {
+				t.Errorf("Expected no message reads with the check disabled, got %d", len(reads))
+			}
+
+			// The duplicate is marked as posted either way, so it is not checked again
+			posted, err := database.IsNewsPosted(bot, 1, "channel-a")
+			if err != nil {
+				t.Fatalf("Failed to check posted news: %v", err)
+			}
+			if !posted {
+				t.Error("Expected the season update to be marked as posted")
+			}
+		})
+	}
+}
+
+func TestRunPollCycleMetrics(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, pollCycleNews(), "channel-a", "channel-b")
+	fake.Handle("POST", "/channels/channel-b/messages", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusForbidden, map[string]interface{}{"message": "Missing Access", "code": 50001})
+	})
+
+	fetched, posted, failures := metrics.NewsFetched.Value(), metrics.NewsPosted.Value(), metrics.PostFailures.Value()
+	fetchErrors, fetches := metrics.FetchErrors.Value(), metrics.FetchDuration.Count()
+
+	if _, err := RunPollCycle(context.Background(), bot); err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+
+	if got := metrics.NewsFetched.Value() - fetched; got != 2 {
+		t.Errorf("Expected 2 news fetched, got %d", got)
+	}
+	if got := metrics.NewsPosted.Value() - posted; got != 2 {
+		t.Errorf("Expected 2 news posted, got %d", got)
+	}
+	if got := metrics.PostFailures.Value() - failures; got != 2 {
+		t.Errorf("Expected 2 post failures, got %d", got)
+	}
+	if got := metrics.FetchDuration.Count() - fetches; got != 1 {
+		t.Errorf("Expected 1 fetch duration observation, got %d", got)
+	}
+	if got := metrics.FetchErrors.Value() - fetchErrors; got != 0 {
+		t.Errorf("Expected no fetch errors, got %d", got)
+	}
+	if got := metrics.RegisteredChannels.Value(); got != 2 {
+		t.Errorf("Expected 2 registered channels, got %d", got)
+	}
+
+	// A failing API counts as a fetch error
+	bot.Config.BaseURL = "http://127.0.0.1:1"
+	if _, err := RunPollCycle(context.Background(), bot); err == nil {
+		t.Fatal("Expected the poll cycle to fail")
+	}
+	if got := metrics.FetchErrors.Value() - fetchErrors; got != 1 {
+		t.Errorf("Expected 1 fetch error, got %d", got)
+	}
+}
+
+func TestRunPollCycleShutdownMidPoll(t *testing.T) {
+	var newsItems []types.NewsItem
+	for id := int64(1); id <= 5; id++ {
+		newsItems = append(newsItems, types.NewsItem{ID: id, Title: fmt.Sprintf("News %d", id), Platforms: []string{"pc"}, Updated: time.Now()})
+	}
+	bot, fake := setupPollCycleTest(t, newsItems, "channel-a")
+
+	// Shutdown starts while the first post is in flight
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fake.Handle("POST", "/channels/channel-a/messages", func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "msg-1", "channel_id": "channel-a"})
+	})
+
+	if _, err := RunPollCycle(ctx, bot); err == nil {
+		t.Fatal("Expected the cycle to report the interruption")
+	}
+
+	// The in-flight post completes and is marked; nothing else is posted
+	if calls := fake.RequestsTo("POST", "/channels/channel-a/messages"); len(calls) != 1 {
+		t.Errorf("Expected 1 post, got %d", len(calls))
+	}
+	countPosted := func() int {
+		t.Helper()
+		var count int
+		if err := bot.DB.QueryRow("SELECT COUNT(*) FROM posted_news WHERE channel_id = 'channel-a'").Scan(&count); err != nil {
+			t.Fatalf("Failed to count posted news: %v", err)
+		}
+		return count
+	}
+	posted := countPosted()
+	if posted != 1 {
+		t.Fatalf("Expected the in-flight post to be marked as posted, got %d marks", posted)
+	}
+
+	// No writes happen once the cycle has returned
+	time.Sleep(50 * time.Millisecond)
+	if after := countPosted(); after != posted {
+		t.Errorf("Expected no writes after shutdown, got %d marks (was %d)", after, posted)
+	}
+}
+
+func TestNewsPollerStopsOnCancel(t *testing.T) {
+	bot, _ := setupPollCycleTest(t, pollCycleNews(), "channel-a")
+	bot.Config.PollPeriod = 3600
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		NewsPoller(ctx, bot)
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the poller to stop when its context is cancelled")
+	}
+}
+
+// setFakePollTimer makes the poller wait on ticks between cycles instead of a real timer, and
+// returns the intervals it schedules in the order it schedules them.
+func setFakePollTimer(t *testing.T, ticks chan time.Time) <-chan time.Duration {
+	t.Helper()
+	intervals := make(chan time.Duration, 100)
+	original := newPollTimer
+	newPollTimer = func(d time.Duration) (<-chan time.Time, func() bool) {
+		intervals <- d
+		return ticks, func() bool { return true }
+	}
+	t.Cleanup(func() { newPollTimer = original })
+	return intervals
+}
+
+// resetPollStats clears the recorded poll stats for the duration of a test.
+func resetPollStats(t *testing.T) {
+	t.Helper()
+	pollStats.Lock()
+	original := pollStats.PollStats
+	pollStats.PollStats = PollStats{}
+	pollStats.Unlock()
+	t.Cleanup(func() {
+		pollStats.Lock()
+		pollStats.PollStats = original
+		pollStats.Unlock()
+	})
+}
+
+func TestNewsPollerSkipsOverlappingCycles(t *testing.T) {
+	bot, _ := setupPollCycleTest(t, nil, "channel-a")
+	bot.Config.PollPeriod = 600
+	resetPollStats(t)
+	ticks := make(chan time.Time)
+	intervals := setFakePollTimer(t, ticks)
+
+	// Each fetch blocks until released, like a slow news API
+	started := make(chan struct{})
+	release := make(chan struct{})
+	fetcher := &testhelpers.FakeNewsFetcher{Fetch: func(tag string, count int, options types.FetchOptions) ([]types.NewsItem, error) {
+		started <- struct{}{}
+		<-release
+		return pollCycleNews(), nil
+	}}
+	bot.Fetcher = fetcher
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		NewsPoller(ctx, bot)
+		close(done)
+	}()
+	// tick fires the poller's timer once it is scheduled
+	tick := func() {
+		t.Helper()
+		select {
+		case <-intervals:
+		case <-time.After(time.Second):
+			t.Fatal("Expected the poller to schedule its next cycle")
+		}
+		ticks <- time.Now()
+	}
+	waitForFetch := func() {
+		t.Helper()
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatal("Expected a poll cycle to fetch news")
+		}
+	}
+
+	tick()
+	waitForFetch()
+
+	// Ticks while the cycle is still running are skipped
+	tick()
+	tick()
+	release <- struct{}{}
+	deadline := time.Now().Add(time.Second)
+	for GetPollStats().LastCycleDuration == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the poll cycle to complete")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if stats := GetPollStats(); stats.SkippedCycles != 2 || stats.LastCycle.IsZero() {
+		t.Errorf("Expected a completed cycle and 2 skipped, got %+v", stats)
+	}
+
+	// Once it has finished, the next tick runs a cycle again
+	tick()
+	waitForFetch()
+	cancel()
+	release <- struct{}{}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the poller to stop once its running cycle did")
+	}
+	if calls := len(fetcher.Calls()); calls != 2 {
+		t.Errorf("Expected 2 poll cycles to fetch news, got %d fetches", calls)
+	}
+	if stats := GetPollStats(); stats.SkippedCycles != 2 {
+		t.Errorf("Expected 2 skipped cycles, got %d", stats.SkippedCycles)
+	}
+}
+
+func TestPollInterval(t *testing.T) {
+	original := pollRandom
+	t.Cleanup(func() { pollRandom = original })
+
+	period := 600 * time.Second
+	tests := []struct {
+		random   float64
+		expected time.Duration
+	}{
+		{0, 540 * time.Second},
+		{0.5, 600 * time.Second},
+		{0.75, 630 * time.Second},
+	}
+	for _, tt := range tests {
+		pollRandom = func() float64 { return tt.random }
+		if interval := pollInterval(period); interval != tt.expected {
+			t.Errorf("pollInterval with random %v = %v, want %v", tt.random, interval, tt.expected)
+		}
+	}
+
+	// Real intervals stay within the jitter
+	pollRandom = original
+	for n := 0; n < 100; n++ {
+		if interval := pollInterval(period); interval < 540*time.Second || interval >= 660*time.Second {
+			t.Fatalf("Expected an interval within 10%% of %v, got %v", period, interval)
+		}
+	}
+}
+
+func TestRunPollCycleFutureDated(t *testing.T) {
+	clock := time.Date(2024, 6, 11, 16, 0, 0, 0, time.UTC)
+	originalNow := now
+	now = func() time.Time { return clock }
+	t.Cleanup(func() { now = originalNow })
+
+	newsItems := []types.NewsItem{
+		{ID: 1, Title: "Season Update", Tags: []string{"star-trek-online"}, Platforms: []string{"pc"}, Updated: clock.Add(-time.Hour)},
+		{ID: 2, Title: "Patch Notes", Tags: []string{"patch-notes"}, Platforms: []string{"pc"}, Updated: clock.Add(2 * time.Minute)},
+		{ID: 3, Title: "Event Announcement", Tags: []string{"events"}, Platforms: []string{"pc"}, Updated: clock.Add(2 * time.Hour)},
+	}
+	bot, fake := setupPollCycleTest(t, newsItems, "channel-a")
+
+	// The past and near-future news is posted; the scheduled announcement is held back
+	summary, err := RunPollCycle(context.Background(), bot)
+	if err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+	if summary.Posted != 2 {
+		t.Errorf("Expected 2 posts, got %+v", summary)
+	}
+	posted, err := database.IsNewsPosted(bot, 3, "channel-a")
+	if err != nil {
+		t.Fatalf("Failed to check posted news: %v", err)
+	}
+	if posted {
+		t.Error("Expected future-dated news 3 not to be marked as posted")
+	}
+
+	// Once it is due, the next poll posts it
+	clock = clock.Add(2 * time.Hour)
+	summary, err = RunPollCycle(context.Background(), bot)
+	if err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+	if summary.Posted != 1 {
+		t.Errorf("Expected the held back news posted once due, got %+v", summary)
+	}
+	if calls := fake.RequestsTo("POST", "/channels/channel-a/messages"); len(calls) != 3 {
+		t.Errorf("Expected 3 posts, got %d", len(calls))
+	}
+}
+
+func TestRunPollCycleQuietHours(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, pollCycleNews(), "channel-a", "channel-b")
+	if err := database.UpdateChannelQuietHours(bot, "channel-a", true, 22, 7); err != nil {
+		t.Fatalf("Failed to update quiet hours: %v", err)
+	}
+
+	// 03:00 tomorrow, after the news was released
+	tomorrow := time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+	clock := tomorrow.Add(3 * time.Hour)
+	originalNow := now
+	now = func() time.Time { return clock }
+	t.Cleanup(func() { now = originalNow })
+
+	// At 03:00 channel-a is in its quiet hours; channel-b has none
+	if _, err := RunPollCycle(context.Background(), bot); err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+	if calls := fake.RequestsTo("POST", "/channels/channel-a/messages"); len(calls) != 0 {
+		t.Errorf("Expected no posts to channel-a during quiet hours, got %d", len(calls))
+	}
+	if calls := fake.RequestsTo("POST", "/channels/channel-b/messages"); len(calls) != 2 {
+		t.Errorf("Expected 2 posts to channel-b, got %d", len(calls))
+	}
+	for _, newsItem := range pollCycleNews() {
+		posted, err := database.IsNewsPosted(bot, newsItem.ID, "channel-a")
+		if err != nil {
+			t.Fatalf("Failed to check posted news: %v", err)
+		}
+		if posted {
+			t.Errorf("Expected news %d held during quiet hours not to be marked as posted", newsItem.ID)
+		}
+	}
+
+	// The first poll after the quiet hours posts the held news
+	clock = tomorrow.Add(7 * time.Hour)
+	if _, err := RunPollCycle(context.Background(), bot); err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+	if calls := fake.RequestsTo("POST", "/channels/channel-a/messages"); len(calls) != 2 {
+		t.Errorf("Expected the 2 held posts to channel-a after quiet hours, got %d", len(calls))
+	}
+	if calls := fake.RequestsTo("POST", "/channels/channel-b/messages"); len(calls) != 2 {
+		t.Errorf("Expected no further posts to channel-b, got %d", len(calls))
+	}
+}
+
+func TestRunPollCyclePausedChannel(t *testing.T) {
+	tests := []struct {
+		name         string
+		hold         bool
+		resumedPosts int // resumedPosts is the number of posts to channel-a by the poll after resuming.
+	}{
+		{"skip", false, 1},
+		{"hold", true, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot, fake := setupPollCycleTest(t, nil, "channel-a", "channel-b")
+			// The news released during the pause has left the fetched news by the time the channel is resumed
+			released := pollCycleNews()
+			fetcher := testhelpers.NewFakeNewsFetcher()
+			fetcher.Fetch = func(tag string, count int, options types.FetchOptions) ([]types.NewsItem, error) {
+				return released, nil
+			}
+			bot.Fetcher = fetcher
+			if err := database.UpdateChannelPaused(bot, "channel-a", true, tt.hold); err != nil {
+				t.Fatalf("Failed to pause channel: %v", err)
+			}
+
+			summary, err := RunPollCycle(context.Background(), bot)
+			if err != nil {
+				t.Fatalf("Poll cycle failed: %v", err)
+			}
+			if calls := fake.RequestsTo("POST", "/channels/channel-a/messages"); len(calls) != 0 {
+				t.Errorf("Expected no posts to the paused channel, got %d", len(calls))
+			}
+			if calls := fake.RequestsTo("POST", "/channels/channel-b/messages"); len(calls) != 2 || summary.Posted != 2 {
+				t.Errorf("Expected 2 posts to channel-b, got %d (%+v)", len(calls), summary)
+			}
+			for _, newsItem := range released {
+				posted, err := database.IsNewsPosted(bot, newsItem.ID, "channel-a")
+				if err != nil {
+					t.Fatalf("Failed to check posted news: %v", err)
+				}
+				if posted == tt.hold {
+					t.Errorf("Expected news %d marked as posted to be %v while paused, got %v", newsItem.ID, !tt.hold, posted)
+				}
+			}
+			heldCount, err := database.CountHeldNews(bot, "channel-a")
+			if err != nil {
+				t.Fatalf("Failed to count held news: %v", err)
+			}
+			if expected := map[bool]int{false: 0, true: 2}[tt.hold]; heldCount != expected {
+				t.Errorf("Expected %d held news items, got %d", expected, heldCount)
+			}
+
+			// The poll after resuming posts new news, and the held news in hold mode
+			if err := database.UpdateChannelPaused(bot, "channel-a", false, false); err != nil {
+				t.Fatalf("Failed to resume channel: %v", err)
+			}
+			released = []types.NewsItem{{ID: 3, Title: "Dev Blog", Tags: []string{"dev-blogs"}, Platforms: []string{"pc", "xbox", "ps"}, Updated: time.Now()}}
+			if _, err := RunPollCycle(context.Background(), bot); err != nil {
+				t.Fatalf("Poll cycle failed: %v", err)
+			}
+			if calls := fake.RequestsTo("POST", "/channels/channel-a/messages"); len(calls) != tt.resumedPosts {
+				t.Errorf("Expected %d posts to channel-a after resuming, got %d", tt.resumedPosts, len(calls))
+			}
+			for _, newsItem := range pollCycleNews() {
+				if posted, _ := database.IsNewsPosted(bot, newsItem.ID, "channel-a"); !posted {
+					t.Errorf("Expected news %d to be marked as posted after resuming", newsItem.ID)
+				}
+			}
+
+			// Held news is posted once
+			if _, err := RunPollCycle(context.Background(), bot); err != nil {
+				t.Fatalf("Poll cycle failed: %v", err)
+			}
+			if calls := fake.RequestsTo("POST", "/channels/channel-a/messages"); len(calls) != tt.resumedPosts {
+				t.Errorf("Expected no further posts to channel-a, got %d", len(calls))
+			}
+			if heldCount, _ := database.CountHeldNews(bot, "channel-a"); heldCount != 0 {
+				t.Errorf("Expected no held news left, got %d", heldCount)
+			}
+		})
+	}
+}
+
+func TestRunPollCycleResolvesChannelGuilds(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, pollCycleNews(), "channel-a", "channel-b")
+	if err := database.UpdateChannelGuild(bot, "channel-b", "guild-2"); err != nil {
+		t.Fatalf("Failed to update channel guild: %v", err)
+	}
+	fake.Handle("GET", "/channels/channel-a", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "channel-a", "guild_id": "guild-1"})
+	})
+
+	for cycle := 0; cycle < 2; cycle++ {
+		if _, err := RunPollCycle(context.Background(), bot); err != nil {
+			t.Fatalf("Poll cycle failed: %v", err)
+		}
+	}
+
+	// channel-a's guild is looked up once and recorded; channel-b's is already known
+	if calls := fake.RequestsTo("GET", "/channels/channel-a"); len(calls) != 1 {
+		t.Errorf("Expected 1 lookup of channel-a, got %d", len(calls))
+	}
+	if calls := fake.RequestsTo("GET", "/channels/channel-b"); len(calls) != 0 {
+		t.Errorf("Expected no lookup of channel-b, got %d", len(calls))
+	}
+	for channelID, expected := range map[string]string{"channel-a": "guild-1", "channel-b": "guild-2"} {
+		cfg, err := database.GetChannelConfig(bot, channelID)
+		if err != nil || cfg == nil {
+			t.Fatalf("Failed to get channel config: %v", err)
+		}
+		if cfg.GuildID != expected {
+			t.Errorf("Expected %s in guild %s, got %q", channelID, expected, cfg.GuildID)
+		}
+	}
+}
+
+func TestRunPollCycleWorkerPool(t *testing.T) {
+	channels := []string{"channel-a", "channel-b", "channel-c", "channel-d", "channel-e"}
+	bot, fake := setupPollCycleTest(t, pollCycleNews()[:1], channels...)
+	bot.Config.PostConcurrency = 2
+
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	for _, channelID := range channels {
+		channelID := channelID
+		fake.Handle("POST", "/channels/"+channelID+"/messages", func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			mu.Unlock()
+
+			time.Sleep(20 * time.Millisecond)
+
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+			testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "msg-" + channelID, "channel_id": channelID})
+		})
+	}
+
+	summary, err := RunPollCycle(context.Background(), bot)
+	if err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+	if summary.Posted != len(channels) {
+		t.Errorf("Expected %d posts, got %d", len(channels), summary.Posted)
+	}
+	if maxInFlight > 2 {
+		t.Errorf("Expected at most 2 channels posted to at once, got %d", maxInFlight)
+	}
+}
+
+func TestRunPollCyclePacesPosts(t *testing.T) {
+	bot, _ := setupPollCycleTest(t, pollCycleNews()[:1], "channel-a", "channel-b", "channel-c", "channel-d", "channel-e")
+
+	// One post straight away, then one every 50ms across all channels
+	setMessageLimiter(t, ratelimit.New(20, 1))
+	start := time.Now()
+	summary, err := RunPollCycle(context.Background(), bot)
+	if err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+
+	if summary.Posted != 5 {
+		t.Errorf("Expected 5 posts, got %d", summary.Posted)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("Expected 5 posts to take at least 200ms, took %v", elapsed)
+	}
+}
+
+// backlogNews returns count news items released a minute apart, news 1 the oldest.
+func backlogNews(count int) []types.NewsItem {
+	newsItems := make([]types.NewsItem, count)
+	for i := range newsItems {
+		newsItems[i] = types.NewsItem{
+			ID:        int64(i + 1),
+			Title:     fmt.Sprintf("News %d", i+1),
+			Tags:      []string{"star-trek-online"},
+			Platforms: []string{"pc"},
+			Updated:   time.Now().Add(-time.Duration(count-i) * time.Minute),
+		}
+	}
+	return newsItems
+}
+
+func TestRunPollCycleMaxPostsPerCycle(t *testing.T) {
+	// Newest first, like the API
+	newsItems := backlogNews(50)
+	slices.Reverse(newsItems)
+	bot, fake := setupPollCycleTest(t, newsItems, "channel-a", "channel-b")
+	bot.Config.PollCount = 50
+	bot.Config.MaxPostsPerCycle = 5
+	if err := database.UpdateChannelMaxPostsPerCycle(bot, "channel-b", 20); err != nil {
+		t.Fatalf("Failed to update max posts per cycle: %v", err)
+	}
+
+	summary, err := RunPollCycle(context.Background(), bot)
+	if err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+	if summary.Posted != 25 {
+		t.Errorf("Expected 5 posts to channel-a and 20 to channel-b, got %+v", summary)
+	}
+	// The oldest news is posted first, and the rest is queued
+	if titles := postedTitles(t, fake); !slices.Equal(titles, []string{"News 1", "News 2", "News 3", "News 4", "News 5"}) {
+		t.Errorf("Expected the oldest 5 news posted in order, got %v", titles)
+	}
+	if held, err := database.GetHeldNews(bot, "channel-a"); err != nil || len(held) != 45 {
+		t.Errorf("Expected 45 news items queued for channel-a, got %d (%v)", len(held), err)
+	}
+
+	// Every cycle posts the next oldest, until all is delivered
+	cycles := 1
+	for ; cycles < 20; cycles++ {
+		summary, err := RunPollCycle(context.Background(), bot)
+		if err != nil {
+			t.Fatalf("Poll cycle failed: %v", err)
+		}
+		if summary.Posted == 0 {
+			break
+		}
+		if posts := len(fake.RequestsTo("POST", "/channels/channel-a/messages")); posts > 5*(cycles+1) {
+			t.Fatalf("Expected at most 5 posts to channel-a per cycle, got %d after %d cycles", posts, cycles+1)
+		}
+	}
+	if cycles != 10 {
+		t.Errorf("Expected the backlog delivered in 10 cycles, took %d", cycles)
+	}
+	titles := postedTitles(t, fake)
+	expected := make([]string, 50)
+	for i := range expected {
+		expected[i] = fmt.Sprintf("News %d", i+1)
+	}
+	if !slices.Equal(titles, expected) {
+		t.Errorf("Expected all 50 news posted to channel-a once, oldest first, got %v", titles)
+	}
+	if posts := fake.RequestsTo("POST", "/channels/channel-b/messages"); len(posts) != 50 {
+		t.Errorf("Expected all 50 news posted to channel-b, got %d", len(posts))
+	}
+	if held, err := database.GetHeldNews(bot, "channel-a"); err != nil || len(held) != 0 {
+		t.Errorf("Expected nothing left queued, got %d (%v)", len(held), err)
+	}
+}
@@ -0,0 +1,109 @@
+package news
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// DiscordPoster posts news to Discord channels and records the posts in posted_news. It is the
+// news poster of bots without one, unless they run dry.
+type DiscordPoster struct{}
+
+// IsPosted reports whether a news item was posted to a channel, or is being posted to it.
+func (DiscordPoster) IsPosted(b *types.Bot, newsID int64, channelID string) (bool, error) {
+	return database.IsNewsPosted(b, newsID, channelID)
+}
+
+// MarkPosted records a news item as posted to a channel without posting it.
+func (DiscordPoster) MarkPosted(b *types.Bot, newsItem types.NewsItem, channelID, delivery string) error {
+	if delivery == "" {
+		return database.MarkNewsAsPosted(b, newsItem.ID, channelID)
+	}
+	return database.MarkNewsAsDelivered(b, newsItem, channelID, delivery)
+}
+
+// Post sends a news item to a channel in the channel's message style and marks it as posted.
+// The post is recorded as pending while it is sent, so it is not sent twice. Once sent, it is
+// followed up as afterNewsPosted describes.
+func (DiscordPoster) Post(ctx context.Context, b *types.Bot, cfg types.ChannelConfig, newsItem types.NewsItem, delivery string) (bool, error) {
+	if !reservePost(b, cfg.ID, newsItem.ID) {
+		return false, nil
+	}
+	message, err := sendNewsToChannel(ctx, b, cfg, newsItem)
+	if err != nil {
+		releasePost(b, cfg.ID, newsItem.ID)
+		return false, err
+	}
+	afterNewsPosted(b, cfg, newsItem, message.ID, delivery)
+	return true, nil
+}
+
+// afterNewsPosted follows up a news item sent to a channel as message messageID: it clears the
+// channel's failed posts, marks the item as posted by delivery and records the message, publishes
+// it, gives it a discussion thread and a scheduled event as the channel is configured, and runs
+// the AfterPost hooks.
+func afterNewsPosted(b *types.Bot, cfg types.ChannelConfig, newsItem types.NewsItem, messageID, delivery string) {
+	channelID := cfg.ID
+	recordPostSuccess(b, channelID)
+	if err := database.MarkNewsAsDelivered(b, newsItem, channelID, delivery); err != nil {
+		channelLogger(channelID).WithField("news_id", newsItem.ID).Errorf("Failed to mark news %d as posted: %v", newsItem.ID, err)
+	}
+	recordNewsMessage(b, channelID, newsItem.ID, messageID)
+	if cfg.AutoPublish {
+		publishNews(b, channelID, newsItem.ID, messageID)
+	}
+	if cfg.CreateThreads {
+		createNewsThread(b, channelID, newsItem, messageID)
+	}
+	if cfg.CreateEvents {
+		createNewsEvent(b, cfg, newsItem)
+	}
+	DefaultHooks.RunAfterPost(channelID, newsItem, messageID)
+}
+
+// DryRunPoster logs the news posts a bot would send instead of sending them, and records them in
+// the dry_run_posted table rather than posted_news, so repeated dry runs log each post once and
+// never keep the real bot from posting. It is the news poster of bots with Config.DryRun set.
+type DryRunPoster struct{}
+
+// IsPosted reports whether a dry run posted a news item to a channel.
+func (DryRunPoster) IsPosted(b *types.Bot, newsID int64, channelID string) (bool, error) {
+	return database.IsNewsDryRunPosted(b, newsID, channelID)
+}
+
+// MarkPosted records a news item as posted to a channel by a dry run.
+func (DryRunPoster) MarkPosted(b *types.Bot, newsItem types.NewsItem, channelID, delivery string) error {
+	return database.MarkNewsDryRunPosted(b, newsItem.ID, channelID, delivery)
+}
+
+// Post logs the message that would post a news item to a channel, as JSON, and records it as
+// posted by a dry run. Nothing is sent to Discord.
+func (DryRunPoster) Post(ctx context.Context, b *types.Bot, cfg types.ChannelConfig, newsItem types.NewsItem, delivery string) (bool, error) {
+	message := newsMessage(b, cfg, newsItem)
+	rendered, err := json.Marshal(message)
+	if err != nil {
+		return false, err
+	}
+	channelLogger(cfg.ID).WithField("news_id", newsItem.ID).
+		Infof("[dry-run] Would post news %d ('%s') to channel %s: %s", newsItem.ID, newsItem.Title, cfg.ID, rendered)
+
+	if err := database.MarkNewsDryRunPosted(b, newsItem.ID, cfg.ID, delivery); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Poster returns the news poster of a bot: its Poster if set, a DryRunPoster if its config runs
+// dry, and a DiscordPoster otherwise.
+func Poster(b *types.Bot) types.NewsPoster {
+	if b.Poster != nil {
+		return b.Poster
+	}
+	if b.Config != nil && b.Config.DryRun {
+		return DryRunPoster{}
+	}
+	return DiscordPoster{}
+}
@@ -0,0 +1,139 @@
+package news
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+// dryRunPosts returns the messages of the dry-run posts logged to hook.
+func dryRunPosts(hook *test.Hook) []string {
+	var posts []string
+	for _, entry := range hook.AllEntries() {
+		if strings.HasPrefix(entry.Message, "[dry-run] Would post news") {
+			posts = append(posts, entry.Message)
+		}
+	}
+	return posts
+}
+
+func TestRunPollCycleDryRun(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, pollCycleNews(), "channel-a", "channel-b")
+	bot.Config.DryRun = true
+	if err := database.UpdateChannelCreateThreads(bot, "channel-a", true); err != nil {
+		t.Fatalf("Failed to enable threads: %v", err)
+	}
+	hook := test.NewGlobal()
+	t.Cleanup(func() { log.StandardLogger().ReplaceHooks(make(log.LevelHooks)) })
+
+	summary, err := RunPollCycle(context.Background(), bot)
+	if err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+	if expected := (PollCycleSummary{Channels: 2, Fetched: 2, Posted: 4}); summary != expected {
+		t.Errorf("Expected summary %+v, got %+v", expected, summary)
+	}
+
+	// Nothing is sent to Discord, not even the duplicate check or guild lookup
+	if requests := fake.Requests(); len(requests) != 0 {
+		t.Errorf("Expected no Discord requests, got %d: %+v", len(requests), requests)
+	}
+	posts := dryRunPosts(hook)
+	if len(posts) != 4 {
+		t.Fatalf("Expected the 4 posts to be logged, got %q", posts)
+	}
+	if !strings.Contains(posts[0], "channel-") || !strings.Contains(posts[0], "Season Update") || !strings.Contains(posts[0], `"embeds":[{`) {
+		t.Errorf("Expected the channel, title and embed JSON to be logged, got %q", posts[0])
+	}
+
+	// The posts are recorded apart from real ones
+	for _, channelID := range []string{"channel-a", "channel-b"} {
+		if posted, err := database.IsNewsDryRunPosted(bot, 2, channelID); err != nil || !posted {
+			t.Errorf("Expected news 2 to be dry-run posted in %s, got %v (%v)", channelID, posted, err)
+		}
+		if posted, err := database.IsNewsPosted(bot, 2, channelID); err != nil || posted {
+			t.Errorf("Expected news 2 not to be marked posted in %s, got %v (%v)", channelID, posted, err)
+		}
+	}
+
+	// A second run logs nothing again
+	hook.Reset()
+	summary, err = RunPollCycle(context.Background(), bot)
+	if err != nil {
+		t.Fatalf("Second poll cycle failed: %v", err)
+	}
+	if summary.Posted != 0 || len(dryRunPosts(hook)) != 0 {
+		t.Errorf("Expected the second run to post nothing, got %+v", summary)
+	}
+	if requests := fake.Requests(); len(requests) != 0 {
+		t.Errorf("Expected no Discord requests, got %d", len(requests))
+	}
+}
+
+// recordingPoster is a news poster recording the news it posts and marks.
+type recordingPoster struct {
+	mu     sync.Mutex
+	posted map[string][]int64
+	marked map[string][]int64
+}
+
+func (p *recordingPoster) IsPosted(b *types.Bot, newsID int64, channelID string) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, id := range append(p.posted[channelID], p.marked[channelID]...) {
+		if id == newsID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (p *recordingPoster) MarkPosted(b *types.Bot, newsItem types.NewsItem, channelID, delivery string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.marked[channelID] = append(p.marked[channelID], newsItem.ID)
+	return nil
+}
+
+func (p *recordingPoster) Post(ctx context.Context, b *types.Bot, cfg types.ChannelConfig, newsItem types.NewsItem, delivery string) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.posted[cfg.ID] = append(p.posted[cfg.ID], newsItem.ID)
+	return true, nil
+}
+
+func TestPostUnpostedNewsUsesPoster(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, nil, "channel-a")
+	bot.Config.SkipDuplicateCheck = true
+	poster := &recordingPoster{posted: map[string][]int64{}, marked: map[string][]int64{}}
+	bot.Poster = poster
+	if err := database.SetChannelExcludedTags(bot, "channel-a", []string{"patch-notes"}); err != nil {
+		t.Fatalf("Failed to exclude tags: %v", err)
+	}
+	cfg, err := database.GetChannelConfig(bot, "channel-a")
+	if err != nil {
+		t.Fatalf("Failed to get config: %v", err)
+	}
+
+	posted, failed := postUnpostedNews(context.Background(), bot, *cfg, pollCycleNews())
+	if posted != 1 || failed != 0 {
+		t.Errorf("Expected 1 post, got %d posted and %d failed", posted, failed)
+	}
+	if len(poster.posted["channel-a"]) != 1 || poster.posted["channel-a"][0] != 1 {
+		t.Errorf("Expected news 1 to be posted, got %v", poster.posted)
+	}
+	// Excluded news is marked by the poster too
+	if len(poster.marked["channel-a"]) != 1 || poster.marked["channel-a"][0] != 2 {
+		t.Errorf("Expected news 2 to be marked, got %v", poster.marked)
+	}
+	if requests := fake.Requests(); len(requests) != 0 {
+		t.Errorf("Expected no Discord requests, got %d", len(requests))
+	}
+}
@@ -0,0 +1,113 @@
+package news
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// autoPublishDisabledNotice is posted to a channel when auto-publish is turned off for lack of permission.
+const autoPublishDisabledNotice = "⚠️ Auto-publish has been disabled for this channel because the bot is missing permission to publish messages. " +
+	"Grant the bot **Manage Messages** here and re-enable it with `/stobot_auto_publish`."
+
+// publishNews crossposts a freshly posted news message when its channel is an announcement channel.
+// Rate-limited or transiently failing publishes are queued and retried by PublishQueued.
+func publishNews(b *types.Bot, channelID string, newsID int64, messageID string) {
+	announcement, err := isAnnouncementChannel(b, channelID)
+	if err != nil {
+		channelLogger(channelID).Errorf("Failed to get type of channel %s: %v", channelID, err)
+		return
+	}
+	if !announcement {
+		channelLogger(channelID).Debugf("Channel %s is not an announcement channel, skipping auto-publish", channelID)
+		return
+	}
+
+	status := crosspostMessage(b, channelID, messageID)
+	if err := database.SetPublishStatus(b, newsID, channelID, messageID, status); err != nil {
+		channelLogger(channelID).WithField("news_id", newsID).Errorf("Failed to record publish status for news %d in channel %s: %v", newsID, channelID, err)
+	}
+}
+
+// PublishQueued retries queued publishes. Once a channel hits the rate limit, its remaining
+// publishes stay queued for the next call.
+func PublishQueued(b *types.Bot) {
+	queued, err := database.GetQueuedPublishes(b)
+	if err != nil {
+		logger().Errorf("Failed to get queued publishes: %v", err)
+		return
+	}
+
+	rateLimited := make(map[string]bool)
+	for _, q := range queued {
+		if rateLimited[q.ChannelID] {
+			continue
+		}
+
+		status := crosspostMessage(b, q.ChannelID, q.MessageID)
+		if status == database.PublishStatusQueued {
+			rateLimited[q.ChannelID] = true
+			continue
+		}
+		if err := database.SetPublishStatus(b, q.NewsID, q.ChannelID, q.MessageID, status); err != nil {
+			logger().Errorf("Failed to record publish status for news %d in channel %s: %v", q.NewsID, q.ChannelID, err)
+		}
+	}
+}
+
+// crosspostMessage publishes a message and returns the resulting publish status.
+// A missing permission disables auto-publish for the channel and notifies it.
+func crosspostMessage(b *types.Bot, channelID, messageID string) string {
+	_, err := b.Session.ChannelMessageCrosspost(channelID, messageID, discordgo.WithRetryOnRatelimit(false))
+	if err == nil {
+		channelLogger(channelID).Infof("Published message %s in channel %s", messageID, channelID)
+		return database.PublishStatusPublished
+	}
+
+	var rateLimitErr *discordgo.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		channelLogger(channelID).Infof("Publish rate limit reached for channel %s, queueing message %s", channelID, messageID)
+		return database.PublishStatusQueued
+	}
+
+	var restErr *discordgo.RESTError
+	if errors.As(err, &restErr) && restErr.Response != nil {
+		switch {
+		case restErr.Response.StatusCode == http.StatusForbidden:
+			channelLogger(channelID).Warnf("Missing permission to publish in channel %s, disabling auto-publish: %v", channelID, err)
+			if err := database.UpdateChannelAutoPublish(b, channelID, false); err != nil {
+				channelLogger(channelID).Errorf("Failed to disable auto-publish for channel %s: %v", channelID, err)
+			}
+			if _, err := b.Session.ChannelMessageSend(channelID, autoPublishDisabledNotice); err != nil {
+				channelLogger(channelID).Errorf("Failed to send auto-publish notice to channel %s: %v", channelID, err)
+			}
+			return database.PublishStatusFailed
+		case restErr.Response.StatusCode < http.StatusInternalServerError:
+			channelLogger(channelID).Errorf("Failed to publish message %s in channel %s: %v", messageID, channelID, err)
+			return database.PublishStatusFailed
+		}
+	}
+
+	channelLogger(channelID).Warnf("Failed to publish message %s in channel %s, will retry: %v", messageID, channelID, err)
+	return database.PublishStatusQueued
+}
+
+// isAnnouncementChannel reports whether a channel is an announcement (news) channel,
+// preferring the session state cache over an API call.
+func isAnnouncementChannel(b *types.Bot, channelID string) (bool, error) {
+	if b.Session.State != nil {
+		if channel, err := b.Session.State.Channel(channelID); err == nil {
+			return channel.Type == discordgo.ChannelTypeGuildNews, nil
+		}
+	}
+
+	channel, err := b.Session.Channel(channelID)
+	if err != nil {
+		return false, err
+	}
+	return channel.Type == discordgo.ChannelTypeGuildNews, nil
+}
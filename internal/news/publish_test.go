@@ -0,0 +1,142 @@
+package news
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// setupPublishTest creates a bot backed by a fake Discord server with an auto-publishing
+// announcement channel that already has news item 1 posted as message "msg-1".
+func setupPublishTest(t *testing.T) (*types.Bot, *testhelpers.FakeDiscord) {
+	t.Helper()
+
+	fake := testhelpers.NewFakeDiscord(t)
+	bot := testhelpers.CreateTestBot(t)
+	t.Cleanup(func() { bot.DB.Close() })
+	bot.Session = fake.Session()
+
+	if err := database.AddChannel(bot, "news-channel"); err != nil {
+		t.Fatalf("Failed to add channel: %v", err)
+	}
+	if err := database.UpdateChannelAutoPublish(bot, "news-channel", true); err != nil {
+		t.Fatalf("Failed to enable auto-publish: %v", err)
+	}
+	if err := database.MarkNewsAsPosted(bot, 1, "news-channel"); err != nil {
+		t.Fatalf("Failed to mark news as posted: %v", err)
+	}
+
+	fake.Handle("GET", "/channels/news-channel", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "news-channel", "type": discordgo.ChannelTypeGuildNews})
+	})
+
+	return bot, fake
+}
+
+func getPublishStatus(t *testing.T, bot *types.Bot) string {
+	t.Helper()
+
+	var status *string
+	err := bot.DB.QueryRow("SELECT publish_status FROM posted_news WHERE news_id = 1 AND channel_id = 'news-channel'").Scan(&status)
+	if err != nil {
+		t.Fatalf("Failed to read publish status: %v", err)
+	}
+	if status == nil {
+		return ""
+	}
+	return *status
+}
+
+func TestPublishNewsCrossposts(t *testing.T) {
+	bot, fake := setupPublishTest(t)
+
+	publishNews(bot, "news-channel", 1, "msg-1")
+
+	if calls := fake.RequestsTo("POST", "/channels/news-channel/messages/msg-1/crosspost"); len(calls) != 1 {
+		t.Fatalf("Expected 1 crosspost call, got %d", len(calls))
+	}
+	if status := getPublishStatus(t, bot); status != database.PublishStatusPublished {
+		t.Errorf("Expected status %q, got %q", database.PublishStatusPublished, status)
+	}
+}
+
+func TestPublishNewsSkipsTextChannels(t *testing.T) {
+	bot, fake := setupPublishTest(t)
+	fake.Handle("GET", "/channels/news-channel", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "news-channel", "type": discordgo.ChannelTypeGuildText})
+	})
+
+	publishNews(bot, "news-channel", 1, "msg-1")
+
+	if calls := fake.RequestsTo("POST", "/channels/news-channel/messages/msg-1/crosspost"); len(calls) != 0 {
+		t.Errorf("Expected no crosspost call for a text channel, got %d", len(calls))
+	}
+	if status := getPublishStatus(t, bot); status != "" {
+		t.Errorf("Expected no publish status, got %q", status)
+	}
+}
+
+func TestPublishNewsQueuesOnRateLimit(t *testing.T) {
+	bot, fake := setupPublishTest(t)
+	crosspostPath := "/channels/news-channel/messages/msg-1/crosspost"
+	fake.Handle("POST", crosspostPath, func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusTooManyRequests, map[string]interface{}{
+			"message": "You are being rate limited.", "retry_after": 3600, "global": false,
+		})
+	})
+
+	publishNews(bot, "news-channel", 1, "msg-1")
+
+	if status := getPublishStatus(t, bot); status != database.PublishStatusQueued {
+		t.Fatalf("Expected status %q after 429, got %q", database.PublishStatusQueued, status)
+	}
+
+	// Still rate limited: the publish stays queued
+	PublishQueued(bot)
+	if status := getPublishStatus(t, bot); status != database.PublishStatusQueued {
+		t.Fatalf("Expected status %q while rate limited, got %q", database.PublishStatusQueued, status)
+	}
+
+	// Rate limit lifted: the queued publish goes out
+	fake.Handle("POST", crosspostPath, func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "msg-1", "channel_id": "news-channel"})
+	})
+	PublishQueued(bot)
+
+	if calls := fake.RequestsTo("POST", crosspostPath); len(calls) != 3 {
+		t.Errorf("Expected 3 crosspost attempts, got %d", len(calls))
+	}
+	if status := getPublishStatus(t, bot); status != database.PublishStatusPublished {
+		t.Errorf("Expected status %q after retry, got %q", database.PublishStatusPublished, status)
+	}
+}
+
+func TestPublishNewsMissingPermissionDisablesAutoPublish(t *testing.T) {
+	bot, fake := setupPublishTest(t)
+	fake.Handle("POST", "/channels/news-channel/messages/msg-1/crosspost", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusForbidden, map[string]interface{}{"code": 50013, "message": "Missing Permissions"})
+	})
+
+	publishNews(bot, "news-channel", 1, "msg-1")
+
+	if status := getPublishStatus(t, bot); status != database.PublishStatusFailed {
+		t.Errorf("Expected status %q, got %q", database.PublishStatusFailed, status)
+	}
+
+	cfg, err := database.GetChannelConfig(bot, "news-channel")
+	if err != nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if cfg.AutoPublish {
+		t.Error("Expected auto-publish to be disabled after a permission error")
+	}
+
+	if notices := fake.RequestsTo("POST", "/channels/news-channel/messages"); len(notices) != 1 {
+		t.Errorf("Expected 1 notice message, got %d", len(notices))
+	}
+}
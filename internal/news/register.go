@@ -0,0 +1,138 @@
+package news
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// RegisterBackfill is what a newly registered channel is sent of the news cached before it.
+type RegisterBackfill string
+
+// The register backfill modes.
+const (
+	RegisterBackfillNone   RegisterBackfill = "none"   // RegisterBackfillNone marks all cached news as posted.
+	RegisterBackfillRecent RegisterBackfill = "recent" // RegisterBackfillRecent posts the newest cached news and marks the rest.
+	RegisterBackfillAll    RegisterBackfill = "all"    // RegisterBackfillAll posts all cached news, e.g. to an archive channel.
+)
+
+// RegisterBackfills are the register backfill modes, the default first.
+var RegisterBackfills = []RegisterBackfill{RegisterBackfillNone, RegisterBackfillRecent, RegisterBackfillAll}
+
+// DefaultRegisterBackfillCount is how many of the newest cached news items a channel registered
+// with backfill recent is sent.
+const DefaultRegisterBackfillCount = 5
+
+// ParseRegisterBackfill parses a register backfill mode; empty is RegisterBackfillNone.
+func ParseRegisterBackfill(value string) (RegisterBackfill, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return RegisterBackfillNone, nil
+	}
+	if !slices.Contains(RegisterBackfills, RegisterBackfill(value)) {
+		return "", fmt.Errorf("unknown backfill %q (expected none, recent or all)", value)
+	}
+	return RegisterBackfill(value), nil
+}
+
+var (
+	setupMu   sync.Mutex
+	settingUp = map[string]int{} // settingUp counts the setups in progress by channel ID.
+)
+
+// BeginChannelSetup marks a channel as being set up until the returned func is called. Polls and
+// catch-ups skip the channel meanwhile, so they do not post the cached news a setup is about to
+// mark as posted.
+func BeginChannelSetup(channelID string) func() {
+	setupMu.Lock()
+	settingUp[channelID]++
+	setupMu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			setupMu.Lock()
+			defer setupMu.Unlock()
+			if settingUp[channelID]--; settingUp[channelID] <= 0 {
+				delete(settingUp, channelID)
+			}
+		})
+	}
+}
+
+// isSettingUp reports whether a channel is being set up.
+func isSettingUp(channelID string) bool {
+	setupMu.Lock()
+	defer setupMu.Unlock()
+	return settingUp[channelID] > 0
+}
+
+// SetUpChannel sends a newly registered channel the cached news its backfill mode asks for and
+// marks the rest as posted, so polls do not post the backlog. It returns how many items were
+// posted. Cancelling ctx stops before the next post; items not yet posted are left to polls.
+func SetUpChannel(ctx context.Context, b *types.Bot, channelID string, backfill RegisterBackfill) (int, error) {
+	channelLog := channelLogger(channelID)
+	if backfill == RegisterBackfillNone || backfill == "" {
+		if _, err := database.MarkCachedNewsAsPosted(b, channelID); err != nil {
+			return 0, fmt.Errorf("failed to mark cached news as posted: %v", err)
+		}
+		return 0, nil
+	}
+
+	cfg, err := database.GetChannelConfig(b, channelID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get channel config: %v", err)
+	}
+
+	var newsItems []types.NewsItem
+	switch backfill {
+	case RegisterBackfillRecent:
+		// The newest items the channel would be sent, of the newest cached; the rest is marked
+		newsItems, err = database.GetRecentNews(b, 50)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get recent news: %v", err)
+		}
+		newsItems = filterNewsByTags(filterNewsByPlatforms(newsItems, cfg.Platforms), cfg.Tags)
+		if count := RegisterBackfillCount(b); len(newsItems) > count {
+			newsItems = newsItems[:count]
+		}
+		keep := make([]int64, len(newsItems))
+		for i, newsItem := range newsItems {
+			keep[i] = newsItem.ID
+		}
+		if _, err := database.MarkCachedNewsAsPosted(b, channelID, keep...); err != nil {
+			return 0, fmt.Errorf("failed to mark cached news as posted: %v", err)
+		}
+	case RegisterBackfillAll:
+		newsItems, err = database.GetAllCachedNews(b)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get cached news: %v", err)
+		}
+	default:
+		return 0, fmt.Errorf("unknown backfill %q", backfill)
+	}
+
+	// Oldest first, so the channel reads in release order
+	slices.SortStableFunc(newsItems, func(x, y types.NewsItem) int {
+		return x.Updated.Compare(y.Updated)
+	})
+	channelLog.Infof("Backfilling %d cached news items to new channel %s (backfill %s)", len(newsItems), channelID, backfill)
+	posted, failed := postUnpostedNews(ctx, b, *cfg, newsItems)
+	if failed > 0 {
+		channelLog.Warnf("Failed to backfill %d news items to channel %s; polls retry them", failed, channelID)
+	}
+	return posted, nil
+}
+
+// RegisterBackfillCount returns how many of the newest cached news items backfill recent sends.
+func RegisterBackfillCount(b *types.Bot) int {
+	if b.Config == nil || b.Config.RegisterBackfillCount == 0 {
+		return DefaultRegisterBackfillCount
+	}
+	return b.Config.RegisterBackfillCount
+}
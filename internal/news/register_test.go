@@ -0,0 +1,200 @@
+package news
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// registerNews returns count cached news items, news 1 the oldest.
+func registerNews(count int) []types.NewsItem {
+	start := time.Now().Add(-time.Duration(count) * time.Hour)
+	newsItems := make([]types.NewsItem, count)
+	for i := range newsItems {
+		newsItems[i] = types.NewsItem{
+			ID:        int64(i + 1),
+			Title:     fmt.Sprintf("News %d", i+1),
+			Tags:      []string{"star-trek-online"},
+			Platforms: []string{"pc", "xbox", "ps"},
+			Updated:   start.Add(time.Duration(i) * time.Hour),
+		}
+	}
+	return newsItems
+}
+
+// setupRegisterTest returns a bot with count cached news items and channel-a registered without
+// marking them.
+func setupRegisterTest(t *testing.T, count int) (*types.Bot, *testhelpers.FakeDiscord) {
+	t.Helper()
+	bot, fake := setupPollCycleTest(t, registerNews(count))
+	if err := database.CacheNews(bot, registerNews(count)); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+	isNew, err := database.RegisterChannel(bot, "channel-a")
+	if err != nil || !isNew {
+		t.Fatalf("Failed to register a new channel: %v (new: %v)", err, isNew)
+	}
+	return bot, fake
+}
+
+// postedTitles returns the titles of the news posted to channel-a, in order.
+func postedTitles(t *testing.T, fake *testhelpers.FakeDiscord) []string {
+	t.Helper()
+	var titles []string
+	for _, call := range fake.RequestsTo("POST", "/channels/channel-a/messages") {
+		var message discordgo.MessageSend
+		if err := json.Unmarshal(call.Body, &message); err != nil {
+			t.Fatalf("Failed to decode post: %v", err)
+		}
+		titles = append(titles, message.Embeds[0].Title)
+	}
+	return titles
+}
+
+// unpostedCount returns how many of the news items 1 to count are not marked as posted to channel-a.
+func unpostedCount(t *testing.T, bot *types.Bot, count int) int {
+	t.Helper()
+	unposted := 0
+	for id := int64(1); id <= int64(count); id++ {
+		posted, err := database.IsNewsPosted(bot, id, "channel-a")
+		if err != nil {
+			t.Fatalf("Failed to check posted news: %v", err)
+		}
+		if !posted {
+			unposted++
+		}
+	}
+	return unposted
+}
+
+func TestParseRegisterBackfill(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected RegisterBackfill
+		wantErr  bool
+	}{
+		{"", RegisterBackfillNone, false},
+		{"none", RegisterBackfillNone, false},
+		{" Recent ", RegisterBackfillRecent, false},
+		{"all", RegisterBackfillAll, false},
+		{"some", "", true},
+	}
+	for _, tt := range tests {
+		backfill, err := ParseRegisterBackfill(tt.value)
+		if backfill != tt.expected || (err != nil) != tt.wantErr {
+			t.Errorf("ParseRegisterBackfill(%q) = %q, %v; want %q (error: %v)", tt.value, backfill, err, tt.expected, tt.wantErr)
+		}
+	}
+}
+
+func TestSetUpChannelNone(t *testing.T) {
+	bot, fake := setupRegisterTest(t, 20)
+
+	posted, err := SetUpChannel(context.Background(), bot, "channel-a", RegisterBackfillNone)
+	if err != nil {
+		t.Fatalf("Failed to set up channel: %v", err)
+	}
+	if posted != 0 || len(postedTitles(t, fake)) != 0 {
+		t.Errorf("Expected nothing posted, got %d", posted)
+	}
+	if unposted := unpostedCount(t, bot, 20); unposted != 0 {
+		t.Errorf("Expected all cached news marked as posted, %d are not", unposted)
+	}
+}
+
+func TestSetUpChannelRecent(t *testing.T) {
+	bot, fake := setupRegisterTest(t, 20)
+	bot.Config.RegisterBackfillCount = 3
+
+	posted, err := SetUpChannel(context.Background(), bot, "channel-a", RegisterBackfillRecent)
+	if err != nil {
+		t.Fatalf("Failed to set up channel: %v", err)
+	}
+	if posted != 3 {
+		t.Errorf("Expected 3 posts, got %d", posted)
+	}
+	// The newest 3, oldest first
+	if titles := postedTitles(t, fake); !slices.Equal(titles, []string{"News 18", "News 19", "News 20"}) {
+		t.Errorf("Expected the newest news posted oldest first, got %v", titles)
+	}
+	if unposted := unpostedCount(t, bot, 20); unposted != 0 {
+		t.Errorf("Expected the older news marked as posted, %d are not", unposted)
+	}
+}
+
+func TestSetUpChannelRecentFiltered(t *testing.T) {
+	bot, fake := setupRegisterTest(t, 10)
+	if err := database.UpdateChannelPlatforms(bot, "channel-a", []string{"pc"}); err != nil {
+		t.Fatalf("Failed to update platforms: %v", err)
+	}
+	newest := types.NewsItem{ID: 11, Title: "Xbox Maintenance", Tags: []string{"star-trek-online"}, Platforms: []string{"xbox"}, Updated: time.Now()}
+	if err := database.CacheNews(bot, []types.NewsItem{newest}); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+
+	// The newest news the channel would be sent, not the newest cached
+	if _, err := SetUpChannel(context.Background(), bot, "channel-a", RegisterBackfillRecent); err != nil {
+		t.Fatalf("Failed to set up channel: %v", err)
+	}
+	expected := []string{"News 6", "News 7", "News 8", "News 9", "News 10"}
+	if titles := postedTitles(t, fake); !slices.Equal(titles, expected) {
+		t.Errorf("Expected %v posted, got %v", expected, titles)
+	}
+	if posted, err := database.IsNewsPosted(bot, 11, "channel-a"); err != nil || !posted {
+		t.Errorf("Expected the Xbox news marked as posted (%v)", err)
+	}
+}
+
+func TestSetUpChannelAll(t *testing.T) {
+	bot, fake := setupRegisterTest(t, 8)
+
+	posted, err := SetUpChannel(context.Background(), bot, "channel-a", RegisterBackfillAll)
+	if err != nil {
+		t.Fatalf("Failed to set up channel: %v", err)
+	}
+	if posted != 8 {
+		t.Errorf("Expected 8 posts, got %d", posted)
+	}
+	titles := postedTitles(t, fake)
+	if len(titles) != 8 || titles[0] != "News 1" || titles[7] != "News 8" {
+		t.Errorf("Expected all news posted oldest first, got %v", titles)
+	}
+	if unposted := unpostedCount(t, bot, 8); unposted != 0 {
+		t.Errorf("Expected all news marked as posted, %d are not", unposted)
+	}
+}
+
+func TestRunPollCycleSkipsChannelSetup(t *testing.T) {
+	bot, fake := setupRegisterTest(t, 3)
+
+	finish := BeginChannelSetup("channel-a")
+	summary, err := RunPollCycle(context.Background(), bot)
+	if err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+	if summary.Posted != 0 || len(postedTitles(t, fake)) != 0 {
+		t.Errorf("Expected nothing posted to a channel being set up, got %+v", summary)
+	}
+
+	// Ending the setup twice ends it once
+	finish()
+	finish()
+	if isSettingUp("channel-a") {
+		t.Error("Expected the setup to have ended")
+	}
+	if _, err := SetUpChannel(context.Background(), bot, "channel-a", RegisterBackfillNone); err != nil {
+		t.Fatalf("Failed to set up channel: %v", err)
+	}
+	if summary, err := RunPollCycle(context.Background(), bot); err != nil || summary.Posted != 0 {
+		t.Errorf("Expected nothing posted after the setup marked the news, got %+v (%v)", summary, err)
+	}
+}
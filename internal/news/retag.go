@@ -0,0 +1,137 @@
+package news
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/ratelimit"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// DefaultRetagRate is the number of news API requests per second RetagNews makes when the
+// options set no limiter.
+const DefaultRetagRate = 2
+
+// retagBatchSize is the number of cached news items RetagNews reads at once.
+const retagBatchSize = 50
+
+// RetagOptions controls RetagNews.
+type RetagOptions struct {
+	IDs     []int64            // IDs only retags these cached news items; empty retags the whole cache.
+	DryRun  bool               // DryRun reports the changes without writing them.
+	Limiter *ratelimit.Limiter // Limiter paces news API requests; nil uses DefaultRetagRate.
+}
+
+// RetagChange describes the tags of a cached news item changed by RetagNews.
+type RetagChange struct {
+	NewsID  int64    // NewsID is the cached news item.
+	OldTags []string // OldTags are the cached tags.
+	NewTags []string // NewTags are the tags merged with the ones from the news API.
+}
+
+// RetagResult reports the outcome of RetagNews.
+type RetagResult struct {
+	Checked  int           // Checked is the number of cached news items looked up in the news API.
+	Missing  int           // Missing is the number of news items unknown to the API or not cached.
+	Failed   int           // Failed is the number of news items that could not be fetched or updated.
+	Changes  []RetagChange // Changes are the news items whose tags changed, or would change on a dry run.
+	Canceled bool          // Canceled reports that ctx was cancelled before all news was checked.
+}
+
+// RetagNews refetches cached news from the news API and merges the tags it gives into the
+// cached tags with database.MergeNewsTags, so news cached with missing tags can be found by tag
+// again. Locally added tags are kept. News is read in batches and fetched one item at a time,
+// paced by the options' limiter; failures are logged and counted, and do not stop the run.
+// Cancelling ctx stops before the next fetch. An error is returned when the cache cannot be read.
+func RetagNews(ctx context.Context, b *types.Bot, options RetagOptions) (RetagResult, error) {
+	limiter := options.Limiter
+	if limiter == nil {
+		limiter = ratelimit.New(DefaultRetagRate, 1)
+	}
+
+	var result RetagResult
+	retagBatch := func(batch []types.NewsItem) error {
+		for _, cached := range batch {
+			if err := limiter.Wait(ctx); err != nil {
+				result.Canceled = true
+				return err
+			}
+			retagNewsItem(b, cached, options.DryRun, &result)
+		}
+		return nil
+	}
+
+	var err error
+	if len(options.IDs) > 0 {
+		err = retagNewsByID(b, options.IDs, retagBatch, &result)
+	} else {
+		err = database.ForEachCachedNews(b, retagBatchSize, retagBatch)
+	}
+	if result.Canceled {
+		return result, nil
+	}
+	if err != nil {
+		return result, fmt.Errorf("failed to read cached news: %v", err)
+	}
+	return result, nil
+}
+
+// retagNewsByID passes the cached news items with the given IDs to fn in batches. IDs that are
+// not cached are logged and counted as missing.
+func retagNewsByID(b *types.Bot, ids []int64, fn func([]types.NewsItem) error, result *RetagResult) error {
+	var batch []types.NewsItem
+	for _, id := range ids {
+		newsItem, err := database.GetCachedNewsByID(b, id)
+		if err != nil {
+			return err
+		}
+		if newsItem == nil {
+			logger().Warnf("News %d is not cached, skipping it", id)
+			result.Missing++
+			continue
+		}
+		batch = append(batch, *newsItem)
+		if len(batch) == retagBatchSize {
+			if err := fn(batch); err != nil {
+				return err
+			}
+			batch = nil
+		}
+	}
+	if len(batch) > 0 {
+		return fn(batch)
+	}
+	return nil
+}
+
+// retagNewsItem fetches a cached news item and merges the fetched tags into it, recording the
+// outcome in result.
+func retagNewsItem(b *types.Bot, cached types.NewsItem, dryRun bool, result *RetagResult) {
+	result.Checked++
+	fetched, err := FetchNewsByID(b, cached.ID)
+	if err != nil {
+		logger().Errorf("Failed to fetch news %d: %v", cached.ID, err)
+		result.Failed++
+		return
+	}
+	if fetched == nil {
+		logger().Warnf("News %d is no longer known to the news API, keeping its tags", cached.ID)
+		result.Missing++
+		return
+	}
+
+	tags := b.Config.NormalizeTags(database.MergeNewsTags(cached.Tags, fetched.Tags))
+	if strings.Join(tags, ",") == strings.Join(cached.Tags, ",") {
+		return
+	}
+	if !dryRun {
+		if err := database.UpdateNewsTags(b, cached.ID, tags); err != nil {
+			logger().Errorf("Failed to update tags of news %d: %v", cached.ID, err)
+			result.Failed++
+			return
+		}
+	}
+	result.Changes = append(result.Changes, RetagChange{NewsID: cached.ID, OldTags: cached.Tags, NewTags: tags})
+}
@@ -0,0 +1,136 @@
+package news
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/ratelimit"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+func setupRetagTest(t *testing.T) *types.Bot {
+	t.Helper()
+	skipRetryDelays(t)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/news/1":
+			_, _ = w.Write([]byte(`{"news": {"id": 1, "title": "Season Update", "tags": ["star-trek-online", "events"]}}`))
+		case "/news/2":
+			_, _ = w.Write([]byte(`{"news": {"id": 2, "title": "Patch Notes", "tags": ["patch-notes"]}}`))
+		case "/news/3":
+			_, _ = w.Write([]byte(`{"news": {"id": 3, "title": "Dev Blog", "tags": ["dev-blogs"]}}`))
+		case "/news/5":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(api.Close)
+
+	bot := testhelpers.CreateTestBot(t)
+	t.Cleanup(func() { bot.DB.Close() })
+	bot.Config.BaseURL = api.URL + "/news"
+	if err := database.CacheNews(bot, []types.NewsItem{
+		{ID: 1, Title: "Season Update", Tags: []string{"featured"}, Updated: time.Now()},
+		{ID: 2, Title: "Patch Notes", Tags: []string{"patch-notes"}, Updated: time.Now()},
+		{ID: 3, Title: "Dev Blog", Updated: time.Now()},
+		{ID: 4, Title: "Removed", Tags: []string{"events"}, Updated: time.Now()},
+		{ID: 5, Title: "Broken", Updated: time.Now()},
+	}); err != nil {
+		t.Fatalf("Failed to cache news: %v", err)
+	}
+	return bot
+}
+
+func cachedTags(t *testing.T, bot *types.Bot, newsID int64) []string {
+	t.Helper()
+	newsItem, err := database.GetCachedNewsByID(bot, newsID)
+	if err != nil {
+		t.Fatalf("Failed to get cached news: %v", err)
+	}
+	return newsItem.Tags
+}
+
+func TestRetagNews(t *testing.T) {
+	bot := setupRetagTest(t)
+	limiter := ratelimit.New(1e6, 1e6)
+
+	// A dry run reports the changes without writing them
+	result, err := RetagNews(context.Background(), bot, RetagOptions{DryRun: true, Limiter: limiter})
+	if err != nil {
+		t.Fatalf("Failed to retag news: %v", err)
+	}
+	expected := []RetagChange{
+		{NewsID: 3, OldTags: nil, NewTags: []string{"dev-blogs"}},
+		{NewsID: 1, OldTags: []string{"featured"}, NewTags: []string{"featured", "star-trek-online", "events"}},
+	}
+	if !reflect.DeepEqual(result.Changes, expected) {
+		t.Errorf("Expected changes %+v, got %+v", expected, result.Changes)
+	}
+	if result.Checked != 5 || result.Missing != 1 || result.Failed != 1 {
+		t.Errorf("Expected 5 checked, 1 missing and 1 failed, got %+v", result)
+	}
+	if tags := cachedTags(t, bot, 1); !reflect.DeepEqual(tags, []string{"featured"}) {
+		t.Errorf("Expected a dry run to leave the tags, got %v", tags)
+	}
+
+	result, err = RetagNews(context.Background(), bot, RetagOptions{Limiter: limiter})
+	if err != nil {
+		t.Fatalf("Failed to retag news: %v", err)
+	}
+	if len(result.Changes) != 2 {
+		t.Errorf("Expected 2 changes, got %+v", result.Changes)
+	}
+	for _, change := range expected {
+		if tags := cachedTags(t, bot, change.NewsID); !reflect.DeepEqual(tags, change.NewTags) {
+			t.Errorf("Expected news %d to have tags %v, got %v", change.NewsID, change.NewTags, tags)
+		}
+	}
+	if tags := cachedTags(t, bot, 4); !reflect.DeepEqual(tags, []string{"events"}) {
+		t.Errorf("Expected news unknown to the API to keep its tags, got %v", tags)
+	}
+
+	// Nothing is left to change
+	result, err = RetagNews(context.Background(), bot, RetagOptions{Limiter: limiter})
+	if err != nil {
+		t.Fatalf("Failed to retag news: %v", err)
+	}
+	if len(result.Changes) != 0 {
+		t.Errorf("Expected no changes on the second run, got %+v", result.Changes)
+	}
+}
+
+func TestRetagNewsByID(t *testing.T) {
+	bot := setupRetagTest(t)
+
+	result, err := RetagNews(context.Background(), bot, RetagOptions{IDs: []int64{1, 99}, Limiter: ratelimit.New(1e6, 1e6)})
+	if err != nil {
+		t.Fatalf("Failed to retag news: %v", err)
+	}
+	if result.Checked != 1 || result.Missing != 1 || len(result.Changes) != 1 || result.Changes[0].NewsID != 1 {
+		t.Errorf("Expected only news 1 to be retagged and 99 to be missing, got %+v", result)
+	}
+	if tags := cachedTags(t, bot, 3); len(tags) != 0 {
+		t.Errorf("Expected other news to be left alone, got %v", tags)
+	}
+}
+
+func TestRetagNewsCancelled(t *testing.T) {
+	bot := setupRetagTest(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := RetagNews(ctx, bot, RetagOptions{Limiter: ratelimit.New(1e6, 1e6)})
+	if err != nil {
+		t.Fatalf("Expected a cancelled run to report its progress, got %v", err)
+	}
+	if !result.Canceled || result.Checked != 0 {
+		t.Errorf("Expected a cancelled run that checked nothing, got %+v", result)
+	}
+}
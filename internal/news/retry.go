@@ -0,0 +1,107 @@
+package news
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// DefaultRetryConfig returns the default retry configuration for news API requests.
+func DefaultRetryConfig() types.RetryConfig {
+	return types.RetryConfig{
+		MaxRetries: 3,
+		BaseDelay:  time.Second,
+		MaxDelay:   time.Second * 10,
+	}
+}
+
+// sleep waits between retries (replaced in tests).
+var sleep = time.Sleep
+
+// statusError is returned when the news API responds with a non-200 status.
+type statusError struct {
+	StatusCode int
+}
+
+// Error implements the error interface.
+func (e *statusError) Error() string {
+	return fmt.Sprintf("API returned status %d", e.StatusCode)
+}
+
+// fetchWithRetry fetches url and returns the response body, retrying retryable failures
+// with exponential backoff.
+func fetchWithRetry(client *http.Client, url string, config types.RetryConfig) ([]byte, error) {
+	var lastErr error
+
+	for attempt := 0; attempt <= config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			delay := config.Delay(attempt)
+			logger().Warnf("Retrying news API request in %v (attempt %d/%d)", delay, attempt, config.MaxRetries)
+			sleep(delay)
+		}
+
+		body, err := fetchBody(client, url)
+		if err == nil {
+			return body, nil
+		}
+		lastErr = err
+
+		if !isRetryableFetchError(err) {
+			return nil, err
+		}
+		logger().Warnf("Retryable news API error on attempt %d: %v", attempt+1, err)
+	}
+
+	if config.MaxRetries == 0 {
+		return nil, lastErr
+	}
+	return nil, fmt.Errorf("news API request failed after %d retries: %w", config.MaxRetries, lastErr)
+}
+
+// fetchBody performs a single GET request and returns the body of a 200 response.
+func fetchBody(client *http.Client, url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch news: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &statusError{StatusCode: resp.StatusCode}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read news response: %w", err)
+	}
+	return body, nil
+}
+
+// isRetryableFetchError determines if a news API error should be retried: timeouts, dropped
+// connections, rate limiting (429) and server errors (5xx). Other 4xx responses are not retried.
+func isRetryableFetchError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,177 @@
+package news
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// skipRetryDelays replaces the retry sleep for the duration of a test and returns the
+// delays that would have been waited.
+func skipRetryDelays(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var delays []time.Duration
+	original := sleep
+	sleep = func(d time.Duration) { delays = append(delays, d) }
+	t.Cleanup(func() { sleep = original })
+	return &delays
+}
+
+// flakyNewsServer serves total news items, paginated by limit and offset, and fails the first
+// failures requests for failAtOffset with status.
+func flakyNewsServer(t *testing.T, total, failAtOffset, failures, status int) (*httptest.Server, func() []int) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var offsets []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+		mu.Lock()
+		offsets = append(offsets, offset)
+		fail := offset == failAtOffset && failures > 0
+		if fail {
+			failures--
+		}
+		mu.Unlock()
+
+		if fail {
+			w.WriteHeader(status)
+			return
+		}
+
+		var items []types.NewsItem
+		for id := offset + 1; id <= total && id <= offset+limit; id++ {
+			items = append(items, types.NewsItem{ID: int64(id), Title: fmt.Sprintf("News %d", id), Updated: time.Now()})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(NewsResponse{News: items})
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []int {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]int(nil), offsets...)
+	}
+}
+
+func TestFetchNewsRetriesThenSucceeds(t *testing.T) {
+	delays := skipRetryDelays(t)
+	server, requested := flakyNewsServer(t, 10, 0, 2, http.StatusServiceUnavailable)
+
+	bot := &types.Bot{Config: &types.Config{BaseURL: server.URL}}
+	newsItems, err := FetchNews(bot, "", 10, DefaultFetchOptions())
+	if err != nil {
+		t.Fatalf("Failed to fetch news: %v", err)
+	}
+	if len(newsItems) != 10 {
+		t.Errorf("Expected 10 news items, got %d", len(newsItems))
+	}
+	if offsets := requested(); len(offsets) != 3 {
+		t.Errorf("Expected 3 requests, got %d", len(offsets))
+	}
+
+	expectedDelays := []time.Duration{time.Second, 2 * time.Second}
+	if len(*delays) != len(expectedDelays) || (*delays)[0] != expectedDelays[0] || (*delays)[1] != expectedDelays[1] {
+		t.Errorf("Expected backoff delays %v, got %v", expectedDelays, *delays)
+	}
+}
+
+func TestFetchNewsPaginationResumesAfterRetry(t *testing.T) {
+	skipRetryDelays(t)
+	server, requested := flakyNewsServer(t, 250, 100, 2, http.StatusTooManyRequests)
+
+	bot := &types.Bot{Config: &types.Config{BaseURL: server.URL}}
+	newsItems, err := FetchNews(bot, "", 250, BulkFetchOptions())
+	if err != nil {
+		t.Fatalf("Failed to fetch news: %v", err)
+	}
+
+	if len(newsItems) != 250 {
+		t.Fatalf("Expected 250 news items, got %d", len(newsItems))
+	}
+	seen := make(map[int64]bool)
+	for _, item := range newsItems {
+		if seen[item.ID] {
+			t.Errorf("News item %d fetched twice", item.ID)
+		}
+		seen[item.ID] = true
+	}
+
+	// The failing page is retried; earlier pages are not fetched again
+	expected := []int{0, 100, 100, 100, 200}
+	offsets := requested()
+	if fmt.Sprint(offsets) != fmt.Sprint(expected) {
+		t.Errorf("Expected requested offsets %v, got %v", expected, offsets)
+	}
+}
+
+func TestFetchNewsRetryLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		requests int
+	}{
+		{"client errors are not retried", http.StatusNotFound, 1},
+		{"server errors are retried until the limit", http.StatusBadGateway, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skipRetryDelays(t)
+			server, requested := flakyNewsServer(t, 10, 0, 100, tt.status)
+
+			bot := &types.Bot{Config: &types.Config{BaseURL: server.URL}}
+			if _, err := FetchNews(bot, "", 10, DefaultFetchOptions()); err == nil {
+				t.Fatalf("Expected an error for status %d", tt.status)
+			}
+			if offsets := requested(); len(offsets) != tt.requests {
+				t.Errorf("Expected %d requests, got %d", tt.requests, len(offsets))
+			}
+		})
+	}
+}
+
+// timeoutError is a net.Error reporting a timeout.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsRetryableFetchError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{"nil", nil, false},
+		{"rate limited", &statusError{StatusCode: http.StatusTooManyRequests}, true},
+		{"server error", &statusError{StatusCode: http.StatusInternalServerError}, true},
+		{"bad request", &statusError{StatusCode: http.StatusBadRequest}, false},
+		{"forbidden", &statusError{StatusCode: http.StatusForbidden}, false},
+		{"timeout", fmt.Errorf("failed to fetch news: %w", timeoutError{}), true},
+		{"connection reset", fmt.Errorf("failed to fetch news: %w", syscall.ECONNRESET), true},
+		{"unexpected schema", ErrUnexpectedSchema, false},
+		{"other error", errors.New("boom"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := isRetryableFetchError(tt.err); result != tt.retryable {
+				t.Errorf("Expected retryable=%v, got %v", tt.retryable, result)
+			}
+		})
+	}
+}
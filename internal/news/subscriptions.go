@@ -0,0 +1,87 @@
+package news
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/ratelimit"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// deliverSubscriptions sends each of subscriptions a direct message for every fresh news item matching
+// their subscription that was not delivered to them yet, and returns how many were sent and how
+// many failed. Users who do not accept messages from the bot are logged and the item is not
+// retried for them; other failures are retried on the next cycle. Messages share ratelimit.Messages
+// with channel posts; cancelling ctx stops before the next message.
+func deliverSubscriptions(ctx context.Context, b *types.Bot, subscriptions []types.UserSubscription, newsItems []types.NewsItem) (sent, failed int) {
+	for _, subscription := range subscriptions {
+		userID := subscription.UserID
+		for _, newsItem := range filterNewsByTags(filterNewsByPlatforms(newsItems, subscription.Platforms), subscription.Tags) {
+			if ctx.Err() != nil {
+				logger().Debugf("Stopping subscription messages: %v", ctx.Err())
+				return sent, failed
+			}
+			if !IsNewsFresh(b, newsItem, subscription.Platforms) {
+				continue
+			}
+			delivered, err := database.IsNewsDeliveredToUser(b, newsItem.ID, userID)
+			if err != nil {
+				logger().Errorf("Failed to check if news %d was sent to user %s: %v", newsItem.ID, userID, err)
+				failed++
+				continue
+			}
+			if delivered {
+				continue
+			}
+
+			err = sendNewsToUser(ctx, b, userID, newsItem)
+			switch {
+			case err == nil:
+				if err := database.MarkNewsDeliveredToUser(b, newsItem.ID, userID, database.DMSent); err != nil {
+					logger().Errorf("Failed to mark news %d as sent to user %s: %v", newsItem.ID, userID, err)
+				}
+				logger().Infof("Sent news item %d ('%s') to subscriber %s", newsItem.ID, newsItem.Title, userID)
+				sent++
+			case isDMClosed(err):
+				// Not retried: the user has to open their DMs, and later news is tried again
+				logger().Warnf("User %s does not accept direct messages, not sending news %d: %v", userID, newsItem.ID, err)
+				if err := database.MarkNewsDeliveredToUser(b, newsItem.ID, userID, database.DMClosed); err != nil {
+					logger().Errorf("Failed to mark news %d as undeliverable to user %s: %v", newsItem.ID, userID, err)
+				}
+			case ctx.Err() != nil:
+				logger().Debugf("Stopping subscription messages: %v", ctx.Err())
+				return sent, failed
+			default:
+				logger().Errorf("Failed to send news %d to user %s: %v", newsItem.ID, userID, err)
+				failed++
+			}
+		}
+	}
+	return sent, failed
+}
+
+// sendNewsToUser sends a news item to a user by direct message.
+func sendNewsToUser(ctx context.Context, b *types.Bot, userID string, newsItem types.NewsItem) error {
+	if err := ratelimit.Messages.Wait(ctx); err != nil {
+		return fmt.Errorf("stopped waiting to send: %v", err)
+	}
+
+	channel, err := b.Session.UserChannelCreate(userID)
+	if err != nil {
+		return err
+	}
+	_, err = b.Session.ChannelMessageSendComplex(channel.ID, newsMessage(b, database.ChannelConfig{}, newsItem))
+	return err
+}
+
+// isDMClosed reports whether an error means a user does not accept direct messages from the bot,
+// because they disabled them or share no server with it.
+func isDMClosed(err error) bool {
+	var restErr *discordgo.RESTError
+	return errors.As(err, &restErr) && restErr.Message != nil &&
+		restErr.Message.Code == discordgo.ErrCodeCannotSendMessagesToThisUser
+}
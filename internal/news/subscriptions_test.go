@@ -0,0 +1,150 @@
+package news
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+func TestRunPollCycleDeliversSubscriptions(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, pollCycleNews())
+	bot.Config.FreshSeconds = 3600
+
+	subscriptions := []struct {
+		userID    string
+		tags      []string
+		platforms []string
+	}{
+		{"user-1", []string{"patch-notes"}, []string{"pc"}},
+		{"user-2", []string{"star-trek-online"}, []string{"all"}},
+		{"user-3", []string{"patch-notes"}, []string{"xbox"}},
+	}
+	for _, sub := range subscriptions {
+		if err := database.SetUserSubscription(bot, sub.userID, sub.tags, sub.platforms); err != nil {
+			t.Fatalf("Failed to subscribe %s: %v", sub.userID, err)
+		}
+	}
+	// user-2 does not accept direct messages
+	fake.Handle("POST", "/channels/dm-user-2/messages", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusForbidden, map[string]interface{}{"code": 50007, "message": "Cannot send messages to this user"})
+	})
+
+	summary, err := RunPollCycle(context.Background(), bot)
+	if err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+	expected := PollCycleSummary{Fetched: 2, Delivered: 1}
+	if summary != expected {
+		t.Errorf("Expected summary %+v, got %+v", expected, summary)
+	}
+
+	expectedMessages := map[string]int{"dm-user-1": 1, "dm-user-2": 1, "dm-user-3": 0}
+	for channelID, count := range expectedMessages {
+		if calls := fake.RequestsTo("POST", "/channels/"+channelID+"/messages"); len(calls) != count {
+			t.Errorf("Expected %d messages to %s, got %d", count, channelID, len(calls))
+		}
+	}
+
+	// Deliveries are recorded, so a later cycle (e.g. after a restart) sends nothing again
+	summary, err = RunPollCycle(context.Background(), bot)
+	if err != nil {
+		t.Fatalf("Second poll cycle failed: %v", err)
+	}
+	if summary.Delivered != 0 || summary.Failed != 0 {
+		t.Errorf("Expected nothing sent on the second cycle, got %+v", summary)
+	}
+	for channelID, count := range expectedMessages {
+		if calls := fake.RequestsTo("POST", "/channels/"+channelID+"/messages"); len(calls) != count {
+			t.Errorf("Expected still %d messages to %s, got %d", count, channelID, len(calls))
+		}
+	}
+}
+
+func TestRunPollCycleRetriesFailedDelivery(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, pollCycleNews())
+	bot.Config.FreshSeconds = 3600
+
+	if err := database.SetUserSubscription(bot, "user-1", []string{"patch-notes"}, []string{"pc"}); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	fake.Handle("POST", "/channels/dm-user-1/messages", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusForbidden, map[string]interface{}{"code": 50001, "message": "Missing Access"})
+	})
+
+	summary, err := RunPollCycle(context.Background(), bot)
+	if err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+	if summary.Delivered != 0 || summary.Failed != 1 {
+		t.Errorf("Expected 1 failed message, got %+v", summary)
+	}
+
+	// Other failures are not recorded and are retried on the next cycle
+	fake.Handle("POST", "/channels/dm-user-1/messages", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "msg-1", "channel_id": "dm-user-1"})
+	})
+	summary, err = RunPollCycle(context.Background(), bot)
+	if err != nil {
+		t.Fatalf("Second poll cycle failed: %v", err)
+	}
+	if summary.Delivered != 1 || summary.Failed != 0 {
+		t.Errorf("Expected 1 message sent on retry, got %+v", summary)
+	}
+}
+
+func TestRunPollCycleSkipsStaleNewsForSubscribers(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, pollCycleNews())
+	bot.Config.FreshSeconds = 0
+
+	if err := database.SetUserSubscription(bot, "user-1", []string{"patch-notes"}, []string{"pc"}); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+
+	summary, err := RunPollCycle(context.Background(), bot)
+	if err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+	if summary.Delivered != 0 {
+		t.Errorf("Expected no messages for stale news, got %+v", summary)
+	}
+	if calls := fake.RequestsTo("POST", "/users/@me/channels"); len(calls) != 0 {
+		t.Errorf("Expected no DM channels opened, got %d", len(calls))
+	}
+}
+
+func TestRunPollCycleUsesConsoleReleaseForSubscribers(t *testing.T) {
+	// Updated at the PC release days before the news reached consoles
+	newsItems := []types.NewsItem{{
+		ID: 1, Title: "Season Update", Tags: []string{"star-trek-online"}, Platforms: []string{"pc", "ps"},
+		Updated:       time.Now().Add(-72 * time.Hour),
+		PlatformDates: map[string]time.Time{"pc": time.Now().Add(-72 * time.Hour), "ps": time.Now().Add(-time.Minute)},
+	}}
+	bot, fake := setupPollCycleTest(t, newsItems)
+	bot.Config.FreshSeconds = 3600
+
+	if err := database.SetUserSubscription(bot, "user-1", []string{"star-trek-online"}, []string{"pc"}); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	if err := database.SetUserSubscription(bot, "user-2", []string{"star-trek-online"}, []string{"ps"}); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+
+	summary, err := RunPollCycle(context.Background(), bot)
+	if err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+	if summary.Delivered != 1 {
+		t.Errorf("Expected 1 message for the console release, got %+v", summary)
+	}
+	expectedMessages := map[string]int{"dm-user-1": 0, "dm-user-2": 1}
+	for channelID, count := range expectedMessages {
+		if calls := fake.RequestsTo("POST", "/channels/"+channelID+"/messages"); len(calls) != count {
+			t.Errorf("Expected %d messages to %s, got %d", count, channelID, len(calls))
+		}
+	}
+}
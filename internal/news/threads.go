@@ -0,0 +1,48 @@
+package news
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// DefaultThreadArchiveMinutes is the default inactivity after which news discussion threads are archived.
+const DefaultThreadArchiveMinutes = 1440
+
+// maxThreadNameLength is Discord's limit on thread names, in characters.
+const maxThreadNameLength = 100
+
+// createNewsThread starts a public discussion thread on a news post, named after the article.
+// Failures are logged only; the post itself was sent and stays marked as posted.
+func createNewsThread(b *types.Bot, channelID string, newsItem types.NewsItem, messageID string) {
+	archiveMinutes := b.Config.ThreadArchiveMinutes
+	if archiveMinutes == 0 {
+		archiveMinutes = DefaultThreadArchiveMinutes
+	}
+
+	newsLog := channelLogger(channelID).WithField("news_id", newsItem.ID)
+	thread, err := b.Session.MessageThreadStart(channelID, messageID, threadName(newsItem.Title), archiveMinutes)
+	if err != nil {
+		newsLog.Errorf("Failed to create thread for news %d in channel %s: %v", newsItem.ID, channelID, err)
+		return
+	}
+	if err := database.SetPostThread(b, newsItem.ID, channelID, thread.ID); err != nil {
+		newsLog.Errorf("Failed to record thread of news %d in channel %s: %v", newsItem.ID, channelID, err)
+	}
+	newsLog.Debugf("Created thread %s for news %d in channel %s", thread.ID, newsItem.ID, channelID)
+}
+
+// threadName returns the name of an article's discussion thread: its title, shortened to
+// Discord's limit without splitting a character.
+func threadName(title string) string {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "News discussion"
+	}
+	if utf8.RuneCountInString(title) <= maxThreadNameLength {
+		return title
+	}
+	return strings.TrimSpace(string([]rune(title)[:maxThreadNameLength-3])) + "..."
+}
@@ -0,0 +1,137 @@
+package news
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestThreadName(t *testing.T) {
+	tests := []struct {
+		title    string
+		expected string
+	}{
+		{title: "Season Update", expected: "Season Update"},
+		{title: "  ", expected: "News discussion"},
+		{title: strings.Repeat("a", 100), expected: strings.Repeat("a", 100)},
+		{title: strings.Repeat("a", 101), expected: strings.Repeat("a", 97) + "..."},
+		{title: strings.Repeat("é", 120), expected: strings.Repeat("é", 97) + "..."},
+	}
+
+	for _, tt := range tests {
+		if name := threadName(tt.title); name != tt.expected {
+			t.Errorf("Expected thread name %q for %q, got %q", tt.expected, tt.title, name)
+		}
+	}
+}
+
+// threadRequests returns the thread creation requests a fake Discord server received, by channel.
+func threadRequests(fake *testhelpers.FakeDiscord) map[string][]discordgo.ThreadStart {
+	threads := make(map[string][]discordgo.ThreadStart)
+	for _, req := range fake.Requests() {
+		parts := strings.Split(strings.Trim(req.Path, "/"), "/")
+		if req.Method != http.MethodPost || len(parts) != 5 || parts[4] != "threads" {
+			continue
+		}
+		var start discordgo.ThreadStart
+		_ = json.Unmarshal(req.Body, &start)
+		threads[parts[1]] = append(threads[parts[1]], start)
+	}
+	return threads
+}
+
+func TestRunPollCycleCreatesThreads(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, pollCycleNews(), "channel-a", "channel-threads")
+	bot.Config.SkipDuplicateCheck = true
+	bot.Config.ThreadArchiveMinutes = 4320
+	if err := database.UpdateChannelCreateThreads(bot, "channel-threads", true); err != nil {
+		t.Fatalf("Failed to enable threads: %v", err)
+	}
+
+	if _, err := RunPollCycle(context.Background(), bot); err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+
+	threads := threadRequests(fake)
+	if len(threads["channel-a"]) != 0 {
+		t.Errorf("Expected no threads in a channel without threads, got %+v", threads["channel-a"])
+	}
+	started := threads["channel-threads"]
+	if len(started) != 2 {
+		t.Fatalf("Expected a thread per news post, got %+v", started)
+	}
+	for _, start := range started {
+		if start.AutoArchiveDuration != 4320 {
+			t.Errorf("Expected threads archived after 4320 minutes, got %d", start.AutoArchiveDuration)
+		}
+		if start.Name != "Season Update" && start.Name != "Patch Notes for 6/11/24" {
+			t.Errorf("Expected a thread named after the article, got %q", start.Name)
+		}
+	}
+}
+
+func TestRunPollCycleRecordsThreads(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, pollCycleNews()[:1], "channel-a")
+	bot.Config.SkipDuplicateCheck = true
+	if err := database.UpdateChannelCreateThreads(bot, "channel-a", true); err != nil {
+		t.Fatalf("Failed to enable threads: %v", err)
+	}
+	fake.Handle("POST", "/channels/channel-a/messages/msg-1/threads", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusCreated, map[string]interface{}{"id": "thread-1", "type": discordgo.ChannelTypeGuildPublicThread})
+	})
+
+	if _, err := RunPollCycle(context.Background(), bot); err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+
+	var threadID string
+	if err := bot.DB.QueryRow(`SELECT thread_id FROM posted_news WHERE news_id = 1 AND channel_id = 'channel-a'`).Scan(&threadID); err != nil {
+		t.Fatalf("Failed to read thread ID: %v", err)
+	}
+	if threadID != "thread-1" {
+		t.Errorf("Expected thread thread-1 to be recorded, got %q", threadID)
+	}
+	if start := threadRequests(fake)["channel-a"]; len(start) != 1 || start[0].AutoArchiveDuration != DefaultThreadArchiveMinutes {
+		t.Errorf("Expected a thread archived after the default duration, got %+v", start)
+	}
+}
+
+func TestRunPollCycleThreadFailureKeepsPost(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, pollCycleNews()[:1], "channel-a")
+	bot.Config.SkipDuplicateCheck = true
+	if err := database.UpdateChannelCreateThreads(bot, "channel-a", true); err != nil {
+		t.Fatalf("Failed to enable threads: %v", err)
+	}
+	fake.Handle("POST", "/channels/channel-a/messages/msg-1/threads", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusForbidden, map[string]interface{}{"message": "Missing Permissions", "code": discordgo.ErrCodeMissingPermissions})
+	})
+
+	summary, err := RunPollCycle(context.Background(), bot)
+	if err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+	if summary.Posted != 1 || summary.Failed != 0 {
+		t.Errorf("Expected the post to succeed despite the thread failure, got %+v", summary)
+	}
+	posted, err := database.IsNewsPosted(bot, 1, "channel-a")
+	if err != nil {
+		t.Fatalf("Failed to check posted news: %v", err)
+	}
+	if !posted {
+		t.Error("Expected the news to be marked posted")
+	}
+	cfg, err := database.GetChannelConfig(bot, "channel-a")
+	if err != nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+	if cfg.PostFailures != 0 || cfg.Disabled {
+		t.Errorf("Expected a thread failure not to count as a failed post, got %+v", cfg)
+	}
+}
@@ -0,0 +1,95 @@
+package news
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Thumbnail validation defaults: older articles often point at images the CDN has removed.
+const (
+	thumbnailCheckTimeout = 3 * time.Second
+	thumbnailCheckTTL     = time.Hour
+)
+
+// thumbnailChecker checks whether thumbnail URLs are reachable, caching each result for a TTL
+// so the same image is not checked again for every channel.
+type thumbnailChecker struct {
+	client *http.Client
+	ttl    time.Duration
+
+	mu      sync.Mutex
+	results map[string]thumbnailCheck
+}
+
+// thumbnailCheck is a cached thumbnail validation result.
+type thumbnailCheck struct {
+	ok        bool
+	checkedAt time.Time
+}
+
+// newThumbnailChecker creates a checker whose HEAD requests give up after timeout.
+func newThumbnailChecker(timeout, ttl time.Duration) *thumbnailChecker {
+	return &thumbnailChecker{
+		client:  &http.Client{Timeout: timeout},
+		ttl:     ttl,
+		results: make(map[string]thumbnailCheck),
+	}
+}
+
+// thumbnails validates the thumbnails of posted news (replaced in tests).
+var thumbnails = newThumbnailChecker(thumbnailCheckTimeout, thumbnailCheckTTL)
+
+// valid reports whether a HEAD request for url returns 200 OK.
+func (c *thumbnailChecker) valid(url string) bool {
+	c.mu.Lock()
+	cached, found := c.results[url]
+	c.mu.Unlock()
+	if found && time.Since(cached.checkedAt) < c.ttl {
+		return cached.ok
+	}
+
+	ok := c.check(url)
+
+	c.mu.Lock()
+	c.results[url] = thumbnailCheck{ok: ok, checkedAt: time.Now()}
+	c.mu.Unlock()
+	return ok
+}
+
+// check sends a HEAD request for url.
+func (c *thumbnailChecker) check(url string) bool {
+	resp, err := c.client.Head(url)
+	if err != nil {
+		logger().Debugf("Thumbnail %s is unreachable: %v", url, err)
+		return false
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		logger().Debugf("Thumbnail %s returned status %d", url, resp.StatusCode)
+		return false
+	}
+	return true
+}
+
+// validateThumbnail drops an embed thumbnail that cannot be loaded, substituting the
+// configured default thumbnail if there is one.
+func validateThumbnail(b *types.Bot, embed *discordgo.MessageEmbed) {
+	if embed.Thumbnail == nil || embed.Thumbnail.URL == "" {
+		return
+	}
+	if thumbnails.valid(embed.Thumbnail.URL) {
+		return
+	}
+
+	logger().Infof("Dropping unavailable thumbnail %s", embed.Thumbnail.URL)
+	embed.Thumbnail = nil
+	if b.Config != nil && b.Config.DefaultThumbnailURL != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: b.Config.DefaultThumbnailURL}
+	}
+}
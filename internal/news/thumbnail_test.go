@@ -0,0 +1,150 @@
+package news
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// roundTripFunc adapts a function to an http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// stubThumbnails makes every thumbnail check return status for the duration of the test,
+// so tests posting news do not reach the network.
+func stubThumbnails(t *testing.T, status int) {
+	t.Helper()
+	saved := thumbnails
+	thumbnails = newThumbnailChecker(time.Second, time.Hour)
+	thumbnails.client.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader("")), Request: r}, nil
+	})
+	t.Cleanup(func() { thumbnails = saved })
+}
+
+func TestThumbnailChecker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("Expected a HEAD request, got %s", r.Method)
+		}
+		switch r.URL.Path {
+		case "/ok.jpg":
+			w.WriteHeader(http.StatusOK)
+		case "/slow.jpg":
+			time.Sleep(200 * time.Millisecond)
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		path     string
+		expected bool
+	}{
+		{"available", "/ok.jpg", true},
+		{"removed from the CDN", "/removed.jpg", false},
+		{"timeout", "/slow.jpg", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := newThumbnailChecker(50*time.Millisecond, time.Hour)
+			if got := checker.valid(server.URL + tt.path); got != tt.expected {
+				t.Errorf("Expected valid=%v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	if got := newThumbnailChecker(50*time.Millisecond, time.Hour).valid("http://127.0.0.1:1/unreachable.jpg"); got {
+		t.Error("Expected an unreachable thumbnail to be invalid")
+	}
+}
+
+func TestThumbnailCheckerCache(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	checker := newThumbnailChecker(time.Second, time.Hour)
+	for i := 0; i < 3; i++ {
+		if checker.valid(server.URL + "/removed.jpg") {
+			t.Fatal("Expected the thumbnail to be invalid")
+		}
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("Expected 1 request for a cached URL, got %d", got)
+	}
+
+	// Expired results are checked again
+	checker.ttl = 0
+	checker.valid(server.URL + "/removed.jpg")
+	if got := requests.Load(); got != 2 {
+		t.Errorf("Expected the expired result to be checked again, got %d requests", got)
+	}
+}
+
+func TestPostNewsToChannelBrokenThumbnail(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           int
+		defaultThumbnail string
+		expected         string
+	}{
+		{name: "available thumbnail is kept", status: http.StatusOK, expected: "https://images.arcgames.com/42.jpg"},
+		{name: "broken thumbnail is dropped", status: http.StatusNotFound},
+		{name: "broken thumbnail uses the default", status: http.StatusNotFound,
+			defaultThumbnail: "https://example.com/stobot.png", expected: "https://example.com/stobot.png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubThumbnails(t, tt.status)
+			fake := testhelpers.NewFakeDiscord(t)
+			bot := testhelpers.CreateTestBot(t)
+			defer bot.DB.Close()
+			bot.Session = fake.Session()
+			bot.Config.DefaultThumbnailURL = tt.defaultThumbnail
+
+			newsItem := types.NewsItem{ID: 42, Title: "Old Article", Updated: time.Now(), ThumbnailURL: "https://images.arcgames.com/42.jpg"}
+			if err := PostNewsToChannel(bot, "channel-a", newsItem); err != nil {
+				t.Fatalf("Failed to post news: %v", err)
+			}
+
+			calls := fake.RequestsTo("POST", "/channels/channel-a/messages")
+			if len(calls) != 1 {
+				t.Fatalf("Expected 1 post, got %d", len(calls))
+			}
+			var message discordgo.MessageSend
+			if err := json.Unmarshal(calls[0].Body, &message); err != nil {
+				t.Fatalf("Failed to decode message: %v", err)
+			}
+
+			var got string
+			if message.Embeds[0].Thumbnail != nil {
+				got = message.Embeds[0].Thumbnail.URL
+			}
+			if got != tt.expected {
+				t.Errorf("Expected thumbnail %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package news
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/ratelimit"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// DefaultUpdateNoticeSeconds is how much later than its cached version a fetched article must be
+// dated before it counts as updated.
+const DefaultUpdateNoticeSeconds = 3600
+
+// maxEmbedTitle is Discord's limit on embed titles, in characters.
+const maxEmbedTitle = 256
+
+// recordNewsMessage records the message a news item was posted to a channel as, so channels can
+// later be told when the article is updated.
+func recordNewsMessage(b *types.Bot, channelID string, newsID int64, messageID string) {
+	if err := database.SetPostMessage(b, newsID, channelID, messageID); err != nil {
+		channelLogger(channelID).WithField("news_id", newsID).Errorf("Failed to record message of news %d in channel %s: %v", newsID, channelID, err)
+	}
+}
+
+// findUpdatedNews returns the fetched news items dated more than Config.UpdateNoticeSeconds after
+// their cached version. Items that are not cached yet are new rather than updated. It must run
+// before the fetched items are cached, which records their new date.
+func findUpdatedNews(b *types.Bot, newsItems []types.NewsItem) []types.NewsItem {
+	delta := time.Duration(b.Config.UpdateNoticeSeconds) * time.Second
+	if delta == 0 {
+		delta = DefaultUpdateNoticeSeconds * time.Second
+	}
+
+	ids := make([]int64, len(newsItems))
+	for n, newsItem := range newsItems {
+		ids[n] = newsItem.ID
+	}
+	cached, err := database.GetCachedUpdateTimes(b, ids)
+	if err != nil {
+		logger().Errorf("Failed to check news for updates: %v", err)
+		return nil
+	}
+
+	var updated []types.NewsItem
+	for _, newsItem := range newsItems {
+		cachedAt, ok := cached[newsItem.ID]
+		if ok && !cachedAt.IsZero() && newsItem.Updated.Sub(cachedAt) > delta {
+			logger().WithField("news_id", newsItem.ID).Infof("News item %d ('%s') was updated at %v, cached as of %v", newsItem.ID, newsItem.Title, newsItem.Updated, cachedAt)
+			updated = append(updated, newsItem)
+		}
+	}
+	return updated
+}
+
+// withoutNews returns newsItems without the items in excluded, compared by ID.
+func withoutNews(newsItems, excluded []types.NewsItem) []types.NewsItem {
+	skip := make(map[int64]bool, len(excluded))
+	for _, newsItem := range excluded {
+		skip[newsItem.ID] = true
+	}
+	var kept []types.NewsItem
+	for _, newsItem := range newsItems {
+		if !skip[newsItem.ID] {
+			kept = append(kept, newsItem)
+		}
+	}
+	return kept
+}
+
+// notifyNewsUpdates tells the channels each of the updated news items was posted to that it was
+// updated, editing the post or replying to it as each channel prefers, and returns the number of
+// channels told. Disabled, paused and other environments' channels are left alone.
+//
+// Each update is only announced once: the caller caches the updated items before calling it, so
+// the next poll compares against the new date whether or not a notice could be sent.
+func notifyNewsUpdates(ctx context.Context, b *types.Bot, updated []types.NewsItem) int {
+	notified := 0
+	for _, newsItem := range updated {
+		posts, err := database.GetNewsPosts(b, newsItem.ID)
+		if err != nil {
+			logger().WithField("news_id", newsItem.ID).Errorf("Failed to get posts of news %d: %v", newsItem.ID, err)
+			continue
+		}
+		for _, post := range posts {
+			if ctx.Err() != nil {
+				return notified
+			}
+			cfg, err := database.GetChannelConfig(b, post.ChannelID)
+			if err != nil {
+				channelLogger(post.ChannelID).Errorf("Failed to get config of channel %s: %v", post.ChannelID, err)
+				continue
+			}
+			if cfg == nil || cfg.Disabled || cfg.Paused || cfg.UpdateNotices == types.UpdateNoticesOff {
+				continue
+			}
+			if b.Config.Environment != "" && cfg.Environment != b.Config.Environment {
+				continue
+			}
+			if err := notifyNewsUpdate(ctx, b, *cfg, post.MessageID, localizeNewsItem(b, cfg.Locale, newsItem)); err != nil {
+				channelLogger(post.ChannelID).WithField("news_id", newsItem.ID).Errorf("Failed to notify channel %s of the update of news %d: %v", post.ChannelID, newsItem.ID, err)
+				continue
+			}
+			notified++
+		}
+	}
+	return notified
+}
+
+// notifyNewsUpdate tells a channel that a news item it was posted as messageID was updated.
+// Channels preferring edits get the post edited; if that fails, e.g. because the message was
+// deleted, they get a notice like other channels.
+func notifyNewsUpdate(ctx context.Context, b *types.Bot, cfg database.ChannelConfig, messageID string, newsItem types.NewsItem) error {
+	if err := ratelimit.Messages.Wait(ctx); err != nil {
+		return fmt.Errorf("stopped waiting to post: %v", err)
+	}
+	newsLog := channelLogger(cfg.ID).WithField("news_id", newsItem.ID)
+
+	if cfg.UpdateNotices == types.UpdateNoticesEdit {
+		err := editNewsPost(b, cfg, messageID, newsItem)
+		if err == nil {
+			newsLog.Infof("Edited post of updated news %d in channel %s", newsItem.ID, cfg.ID)
+			return nil
+		}
+		newsLog.Warnf("Failed to edit post of updated news %d in channel %s, posting a notice: %v", newsItem.ID, cfg.ID, err)
+	}
+
+	notice := &discordgo.MessageSend{
+		Embeds:          []*discordgo.MessageEmbed{updateNoticeEmbed(b, cfg, messageID, newsItem)},
+		AllowedMentions: &discordgo.MessageAllowedMentions{Parse: []discordgo.AllowedMentionType{}},
+		Reference:       &discordgo.MessageReference{MessageID: messageID, ChannelID: cfg.ID, GuildID: cfg.GuildID},
+	}
+	if _, err := b.Session.ChannelMessageSendComplex(cfg.ID, notice); err != nil {
+		return err
+	}
+	newsLog.Infof("Posted update notice for news %d to channel %s", newsItem.ID, cfg.ID)
+	return nil
+}
+
+// editNewsPost replaces the embed of a news post with the updated article's, or the line of a
+// compact post. Posts sent through the channel's webhook can only be edited through it; bot posts
+// only by the bot.
+func editNewsPost(b *types.Bot, cfg database.ChannelConfig, messageID string, newsItem types.NewsItem) error {
+	message := newsMessage(b, cfg, newsItem)
+	embeds := message.Embeds
+	var content *string
+	if cfg.MessageStyle == types.MessageStyleCompact {
+		content = &message.Content
+		embeds = []*discordgo.MessageEmbed{}
+	}
+	if cfg.WebhookURL != "" {
+		id, token, err := ParseWebhookURL(cfg.WebhookURL)
+		if err == nil {
+			_, err = b.Session.WebhookMessageEdit(id, token, messageID, &discordgo.WebhookEdit{Content: content, Embeds: &embeds})
+		}
+		if err == nil {
+			return nil
+		}
+		channelLogger(cfg.ID).Debugf("Failed to edit message %s in channel %s through its webhook, editing as the bot: %v", messageID, cfg.ID, redactWebhookError(err))
+	}
+
+	_, err := b.Session.ChannelMessageEditComplex(&discordgo.MessageEdit{ID: messageID, Channel: cfg.ID, Content: content, Embeds: embeds})
+	return err
+}
+
+// updateNoticeEmbed builds the compact embed telling a channel that an article was updated,
+// titled and colored like the article's post and linking to it.
+func updateNoticeEmbed(b *types.Bot, cfg database.ChannelConfig, messageID string, newsItem types.NewsItem) *discordgo.MessageEmbed {
+	post := BuildNewsEmbed(b, newsItem, cfg.SpoilerTags)
+	description := "This article was updated after it was posted here."
+	if cfg.GuildID != "" {
+		description += fmt.Sprintf(" [Original post](https://discord.com/channels/%s/%s/%s)", cfg.GuildID, cfg.ID, messageID)
+	}
+	return &discordgo.MessageEmbed{
+		Title:       updateNoticeTitle(newsItem.Title),
+		URL:         post.URL,
+		Description: description,
+		Color:       post.Color,
+		Timestamp:   newsItem.Updated.UTC().Format(time.RFC3339),
+	}
+}
+
+// updateNoticeTitle returns the title of an update notice for an article, shortened to Discord's
+// limit without splitting a character.
+func updateNoticeTitle(title string) string {
+	title = "📝 Updated: " + strings.TrimSpace(title)
+	if utf8.RuneCountInString(title) <= maxEmbedTitle {
+		return title
+	}
+	return strings.TrimSpace(string([]rune(title)[:maxEmbedTitle-3])) + "..."
+}
@@ -0,0 +1,154 @@
+package news
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// newsPostMessage returns the ID of the message a news item was posted to a channel as.
+func newsPostMessage(t *testing.T, bot *types.Bot, newsID int64, channelID string) string {
+	t.Helper()
+	posts, err := database.GetNewsPosts(bot, newsID)
+	if err != nil {
+		t.Fatalf("Failed to get news posts: %v", err)
+	}
+	for _, post := range posts {
+		if post.ChannelID == channelID {
+			return post.MessageID
+		}
+	}
+	t.Fatalf("Expected news %d to be posted to %s with a message ID, got %+v", newsID, channelID, posts)
+	return ""
+}
+
+func TestRunPollCycleUpdateNotices(t *testing.T) {
+	newsItems := pollCycleNews()
+	for n := range newsItems {
+		newsItems[n].Updated = time.Now().Add(-3 * time.Hour)
+	}
+	bot, fake := setupPollCycleTest(t, newsItems, "channel-a", "channel-b")
+	if err := database.UpdateChannelUpdateNotices(bot, "channel-b", types.UpdateNoticesEdit); err != nil {
+		t.Fatalf("Failed to set update notices: %v", err)
+	}
+
+	if _, err := RunPollCycle(context.Background(), bot); err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+	messageA := newsPostMessage(t, bot, 2, "channel-a")
+	messageB := newsPostMessage(t, bot, 2, "channel-b")
+
+	// The patch notes are edited well past the delta; the other article only slightly
+	newsItems[0].Updated = newsItems[0].Updated.Add(30 * time.Minute)
+	newsItems[1].Updated = time.Now()
+	for cycle := 0; cycle < 2; cycle++ {
+		summary, err := RunPollCycle(context.Background(), bot)
+		if err != nil {
+			t.Fatalf("Poll cycle failed: %v", err)
+		}
+		if expected := 2 * (1 - cycle); summary.Updated != expected || summary.Posted != 0 {
+			t.Errorf("Cycle %d: expected %d update notices and no posts, got %+v", cycle, expected, summary)
+		}
+	}
+
+	// channel-a gets exactly one notice replying to the original post
+	posts := fake.RequestsTo("POST", "/channels/channel-a/messages")
+	if len(posts) != 3 {
+		t.Fatalf("Expected 2 posts and 1 notice in channel-a, got %d messages", len(posts))
+	}
+	var notice discordgo.MessageSend
+	if err := json.Unmarshal(posts[2].Body, &notice); err != nil {
+		t.Fatalf("Failed to decode notice: %v", err)
+	}
+	if notice.Reference == nil || notice.Reference.MessageID != messageA {
+		t.Errorf("Expected the notice to reply to %s, got %+v", messageA, notice.Reference)
+	}
+	if len(notice.Embeds) != 1 || !strings.Contains(notice.Embeds[0].Title, "Patch Notes for 6/11/24") {
+		t.Errorf("Expected an update notice for the patch notes, got %+v", notice.Embeds)
+	}
+
+	// channel-b gets its post edited instead
+	if posts := fake.RequestsTo("POST", "/channels/channel-b/messages"); len(posts) != 2 {
+		t.Errorf("Expected no notice in channel-b, got %d messages", len(posts))
+	}
+	if edits := fake.RequestsTo("PATCH", "/channels/channel-b/messages/"+messageB); len(edits) != 1 {
+		t.Errorf("Expected the post in channel-b edited once, got %d edits", len(edits))
+	}
+}
+
+func TestRunPollCycleDryRunKeepsUpdates(t *testing.T) {
+	newsItems := pollCycleNews()
+	for n := range newsItems {
+		newsItems[n].Updated = time.Now().Add(-3 * time.Hour)
+	}
+	bot, fake := setupPollCycleTest(t, newsItems, "channel-a")
+	if _, err := RunPollCycle(context.Background(), bot); err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+
+	// A dry run sees the update but leaves it to be announced
+	newsItems[1].Updated = time.Now()
+	bot.Config.DryRun = true
+	summary, err := RunPollCycle(context.Background(), bot)
+	if err != nil {
+		t.Fatalf("Dry-run poll cycle failed: %v", err)
+	}
+	if summary.Updated != 0 {
+		t.Errorf("Expected the dry run to announce no updates, got %+v", summary)
+	}
+	if posts := fake.RequestsTo("POST", "/channels/channel-a/messages"); len(posts) != 2 {
+		t.Fatalf("Expected only the 2 posts in channel-a after the dry run, got %d messages", len(posts))
+	}
+
+	// The next real run announces it
+	bot.Config.DryRun = false
+	summary, err = RunPollCycle(context.Background(), bot)
+	if err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+	if summary.Updated != 1 {
+		t.Errorf("Expected the real run to announce the update, got %+v", summary)
+	}
+	if posts := fake.RequestsTo("POST", "/channels/channel-a/messages"); len(posts) != 3 {
+		t.Errorf("Expected 2 posts and 1 notice in channel-a, got %d messages", len(posts))
+	}
+}
+
+func TestNotifyNewsUpdatesFallsBackToNotice(t *testing.T) {
+	newsItems := pollCycleNews()
+	bot, fake := setupPollCycleTest(t, newsItems, "channel-a", "channel-b")
+	if err := database.UpdateChannelUpdateNotices(bot, "channel-a", types.UpdateNoticesEdit); err != nil {
+		t.Fatalf("Failed to set update notices: %v", err)
+	}
+	if err := database.UpdateChannelUpdateNotices(bot, "channel-b", types.UpdateNoticesOff); err != nil {
+		t.Fatalf("Failed to set update notices: %v", err)
+	}
+	if _, err := RunPollCycle(context.Background(), bot); err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+
+	// The original post was deleted, so it cannot be edited
+	messageA := newsPostMessage(t, bot, 1, "channel-a")
+	fake.Handle("PATCH", "/channels/channel-a/messages/"+messageA, func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Unknown Message", "code": discordgo.ErrCodeUnknownMessage})
+	})
+
+	if notified := notifyNewsUpdates(context.Background(), bot, newsItems[:1]); notified != 1 {
+		t.Errorf("Expected 1 channel notified, got %d", notified)
+	}
+	if posts := fake.RequestsTo("POST", "/channels/channel-a/messages"); len(posts) != 3 {
+		t.Errorf("Expected a notice after the failed edit, got %d messages", len(posts))
+	}
+	if posts := fake.RequestsTo("POST", "/channels/channel-b/messages"); len(posts) != 2 {
+		t.Errorf("Expected no notice in a channel with update notices off, got %d messages", len(posts))
+	}
+}
@@ -0,0 +1,64 @@
+package news
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// webhookURLPattern matches Discord webhook URLs, capturing the webhook ID and token.
+var webhookURLPattern = regexp.MustCompile(`^https://(?:(?:canary|ptb)\.)?discord(?:app)?\.com/api(?:/v\d+)?/webhooks/(\d+)/([\w-]+)/?$`)
+
+// ParseWebhookURL returns the ID and token of a Discord webhook URL.
+// The error does not include the URL, so it is safe to log.
+func ParseWebhookURL(webhookURL string) (id, token string, err error) {
+	match := webhookURLPattern.FindStringSubmatch(webhookURL)
+	if match == nil {
+		return "", "", errors.New("not a Discord webhook URL")
+	}
+	return match[1], match[2], nil
+}
+
+// webhookID returns the ID of a webhook URL, or "" if it is empty or invalid.
+func webhookID(webhookURL string) string {
+	id, _, err := ParseWebhookURL(webhookURL)
+	if err != nil {
+		return ""
+	}
+	return id
+}
+
+// PostNewsViaWebhook posts a news item through the channel's webhook, with the same embed and
+// mentions as a bot post, and returns the sent message. The webhook's own name and avatar are shown.
+func PostNewsViaWebhook(b *types.Bot, cfg database.ChannelConfig, newsItem types.NewsItem) (*discordgo.Message, error) {
+	id, token, err := ParseWebhookURL(cfg.WebhookURL)
+	if err != nil {
+		return nil, err
+	}
+
+	message := newsMessage(b, cfg, newsItem)
+	sent, err := b.Session.WebhookExecute(id, token, true, &discordgo.WebhookParams{
+		Content:         message.Content,
+		Embeds:          message.Embeds,
+		AllowedMentions: message.AllowedMentions,
+	})
+	if err != nil {
+		return nil, redactWebhookError(err)
+	}
+	return sent, nil
+}
+
+// redactWebhookError strips the request URL, which contains the webhook token, from transport errors.
+func redactWebhookError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("webhook request failed: %v", urlErr.Err)
+	}
+	return err
+}
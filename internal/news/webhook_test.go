@@ -0,0 +1,129 @@
+package news
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+)
+
+func TestParseWebhookURL(t *testing.T) {
+	tests := []struct {
+		name          string
+		webhookURL    string
+		expectedID    string
+		expectedToken string
+		expectError   bool
+	}{
+		{"discord.com", "https://discord.com/api/webhooks/123/abc-DEF_1", "123", "abc-DEF_1", false},
+		{"discordapp.com", "https://discordapp.com/api/webhooks/123/abc", "123", "abc", false},
+		{"canary with version", "https://canary.discord.com/api/v10/webhooks/123/abc/", "123", "abc", false},
+		{"other host", "https://example.com/api/webhooks/123/abc", "", "", true},
+		{"plain http", "http://discord.com/api/webhooks/123/abc", "", "", true},
+		{"missing token", "https://discord.com/api/webhooks/123", "", "", true},
+		{"empty", "", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, token, err := ParseWebhookURL(tt.webhookURL)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected an error for %q", tt.webhookURL)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to parse webhook URL: %v", err)
+			}
+			if id != tt.expectedID || token != tt.expectedToken {
+				t.Errorf("Expected %s/%s, got %s/%s", tt.expectedID, tt.expectedToken, id, token)
+			}
+		})
+	}
+}
+
+func TestRunPollCycleWebhook(t *testing.T) {
+	const webhookPath = "/webhooks/111/secret-token"
+
+	tests := []struct {
+		name             string
+		webhookStatus    int
+		expectedWebhooks int
+		expectedBotPosts int
+	}{
+		{name: "posts through the webhook", webhookStatus: http.StatusOK, expectedWebhooks: 1},
+		{name: "falls back to the bot", webhookStatus: http.StatusInternalServerError, expectedWebhooks: 1, expectedBotPosts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot, fake := setupPollCycleTest(t, pollCycleNews()[:1], "channel-a")
+			if err := database.UpdateChannelPingRole(bot, "channel-a", "role-1"); err != nil {
+				t.Fatalf("Failed to update ping role: %v", err)
+			}
+			if err := database.UpdateChannelWebhook(bot, "channel-a", "https://discord.com/api/webhooks/111/secret-token"); err != nil {
+				t.Fatalf("Failed to update webhook: %v", err)
+			}
+			fake.Handle("POST", webhookPath, func(w http.ResponseWriter, r *http.Request) {
+				if tt.webhookStatus != http.StatusOK {
+					testhelpers.RespondJSON(w, tt.webhookStatus, map[string]interface{}{"message": "Internal Server Error"})
+					return
+				}
+				testhelpers.RespondJSON(w, http.StatusOK, map[string]interface{}{"id": "msg-webhook", "channel_id": "channel-a", "webhook_id": "111"})
+			})
+
+			if _, err := RunPollCycle(context.Background(), bot); err != nil {
+				t.Fatalf("Poll cycle failed: %v", err)
+			}
+
+			webhookCalls := fake.RequestsTo("POST", webhookPath)
+			if len(webhookCalls) != tt.expectedWebhooks {
+				t.Fatalf("Expected %d webhook posts, got %d", tt.expectedWebhooks, len(webhookCalls))
+			}
+			// The webhook gets the same embed and mentions as a bot post
+			body := string(webhookCalls[0].Body)
+			if !strings.Contains(body, "Season Update") || !strings.Contains(body, `"roles":["role-1"]`) {
+				t.Errorf("Expected the news embed and ping role, got %s", body)
+			}
+			if botPosts := fake.RequestsTo("POST", "/channels/channel-a/messages"); len(botPosts) != tt.expectedBotPosts {
+				t.Errorf("Expected %d bot posts, got %d", tt.expectedBotPosts, len(botPosts))
+			}
+
+			posted, err := database.IsNewsPosted(bot, 1, "channel-a")
+			if err != nil {
+				t.Fatalf("Failed to check posted news: %v", err)
+			}
+			if !posted {
+				t.Error("Expected the news to be marked as posted")
+			}
+		})
+	}
+}
+
+func TestRunPollCycleWebhookDuplicates(t *testing.T) {
+	bot, fake := setupPollCycleTest(t, pollCycleNews()[:1], "channel-a")
+	bot.Config.MsgCount = 10
+	if err := database.UpdateChannelWebhook(bot, "channel-a", "https://discord.com/api/webhooks/111/secret-token"); err != nil {
+		t.Fatalf("Failed to update webhook: %v", err)
+	}
+
+	// The season update was posted earlier by the channel's webhook, whose author is not the bot
+	fake.Handle("GET", "/channels/channel-a/messages", func(w http.ResponseWriter, r *http.Request) {
+		testhelpers.RespondJSON(w, http.StatusOK, []map[string]interface{}{
+			{"id": "msg-old", "webhook_id": "111", "author": map[string]interface{}{"id": "111"},
+				"embeds": []map[string]interface{}{{"title": "Season Update", "description": "New season"}}},
+		})
+	})
+
+	if _, err := RunPollCycle(context.Background(), bot); err != nil {
+		t.Fatalf("Poll cycle failed: %v", err)
+	}
+
+	if calls := fake.RequestsTo("POST", "/webhooks/111/secret-token"); len(calls) != 0 {
+		t.Errorf("Expected the duplicate not to be posted again, got %d webhook posts", len(calls))
+	}
+}
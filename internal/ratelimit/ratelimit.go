@@ -0,0 +1,132 @@
+// Package ratelimit paces outgoing Discord requests with a token bucket shared by every
+// goroutine that makes them.
+//
+// Example:
+//
+//	limiter := ratelimit.New(5, 5) // 5 requests per second, bursts of up to 5
+//	if err := limiter.Wait(ctx); err != nil {
+//	    return err // ctx was cancelled while waiting
+//	}
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MessageRate is the number of messages the bot sends per second, across all channels.
+const MessageRate = 5
+
+// Messages paces every message the bot sends: news posts, update notices, direct messages and
+// the Discord helpers' requests all wait on it, so together they stay under MessageRate.
+var Messages = New(MessageRate, MessageRate)
+
+// Limiter is a token bucket: it holds up to burst tokens, refilled at rate tokens per second,
+// and each request takes one. Requests arriving at an empty bucket wait their turn in order.
+// It is safe for concurrent use.
+type Limiter struct {
+	rate  float64 // rate is the number of tokens added per second.
+	burst float64 // burst is the maximum number of tokens held.
+
+	now   func() time.Time                                 // now is the clock tokens are refilled by (replaced in tests).
+	sleep func(ctx context.Context, d time.Duration) error // sleep waits for a token (replaced in tests).
+
+	mu     sync.Mutex
+	tokens float64   // tokens is the number of tokens left; negative while requests are waiting.
+	last   time.Time // last is when tokens was last refilled.
+}
+
+// New returns a Limiter allowing perSecond requests per second in bursts of up to burst, starting full.
+func New(perSecond float64, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{
+		rate:   perSecond,
+		burst:  float64(burst),
+		now:    time.Now,
+		sleep:  sleepContext,
+		tokens: float64(burst),
+	}
+}
+
+// Wait blocks until the request may be made, or until ctx is done.
+func (l *Limiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	delay := l.reserve()
+	if delay <= 0 {
+		return nil
+	}
+	if err := l.sleep(ctx, delay); err != nil {
+		l.cancel()
+		return err
+	}
+	return nil
+}
+
+// reserve takes a token and returns how long to wait before it is available.
+func (l *Limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill()
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// cancel returns a token taken by a request that stopped waiting.
+func (l *Limiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill()
+	l.tokens++
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
+
+// refill adds the tokens accumulated since the last refill. l.mu must be held.
+func (l *Limiter) refill() {
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+}
+
+// Stats returns the limiter's configuration and the tokens currently available.
+func (l *Limiter) Stats() map[string]interface{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill()
+	return map[string]interface{}{
+		"rate_per_second":  l.rate,
+		"burst":            int(l.burst),
+		"tokens_available": l.tokens,
+	}
+}
+
+// sleepContext waits for d, returning early with ctx's error if it is done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,134 @@
+package ratelimit
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock whose sleeps advance it instead of waiting.
+type fakeClock struct {
+	mu      sync.Mutex
+	current time.Time
+	slept   []time.Duration
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.current
+}
+
+func (c *fakeClock) sleep(ctx context.Context, d time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.slept = append(c.slept, d)
+	return ctx.Err()
+}
+
+// sleepAndAdvance sleeps by moving the clock forward, as a single caller would see it.
+func (c *fakeClock) sleepAndAdvance(ctx context.Context, d time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.slept = append(c.slept, d)
+	c.current = c.current.Add(d)
+	return ctx.Err()
+}
+
+func newFakeLimiter(perSecond float64, burst int) (*Limiter, *fakeClock) {
+	clock := &fakeClock{current: time.Date(2024, 6, 11, 12, 0, 0, 0, time.UTC)}
+	limiter := New(perSecond, burst)
+	limiter.now = clock.now
+	limiter.sleep = clock.sleep
+	return limiter, clock
+}
+
+func TestLimiterMinimumDuration(t *testing.T) {
+	tests := []struct {
+		name      string
+		perSecond float64
+		burst     int
+		requests  int
+		expected  time.Duration
+	}{
+		{"within burst", 5, 5, 5, 0},
+		{"paced after burst", 5, 5, 20, 3 * time.Second},
+		{"no burst", 2, 1, 5, 2 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter, clock := newFakeLimiter(tt.perSecond, tt.burst)
+			limiter.sleep = clock.sleepAndAdvance
+			start := clock.now()
+
+			for i := 0; i < tt.requests; i++ {
+				if err := limiter.Wait(context.Background()); err != nil {
+					t.Fatalf("Failed to wait: %v", err)
+				}
+			}
+
+			if elapsed := clock.now().Sub(start); elapsed < tt.expected || elapsed > tt.expected+time.Millisecond {
+				t.Errorf("Expected %d requests to take %v, took %v", tt.requests, tt.expected, elapsed)
+			}
+		})
+	}
+}
+
+func TestLimiterConcurrentWaitsQueue(t *testing.T) {
+	limiter, clock := newFakeLimiter(5, 2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := limiter.Wait(context.Background()); err != nil {
+				t.Errorf("Failed to wait: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Two requests go straight through; the rest wait one interval more than the one before
+	sort.Slice(clock.slept, func(a, b int) bool { return clock.slept[a] < clock.slept[b] })
+	expected := []time.Duration{200 * time.Millisecond, 400 * time.Millisecond, 600 * time.Millisecond, 800 * time.Millisecond}
+	if len(clock.slept) != len(expected) {
+		t.Fatalf("Expected %d waits, got %v", len(expected), clock.slept)
+	}
+	for i, d := range expected {
+		if diff := clock.slept[i] - d; diff < -time.Millisecond || diff > time.Millisecond {
+			t.Errorf("Expected wait %d to be %v, got %v", i, d, clock.slept[i])
+		}
+	}
+}
+
+func TestLimiterCancelledWait(t *testing.T) {
+	limiter, clock := newFakeLimiter(1, 1)
+
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("Failed to wait: %v", err)
+	}
+
+	// Shutdown starts while the second request waits for a token
+	ctx, cancel := context.WithCancel(context.Background())
+	limiter.sleep = func(ctx context.Context, d time.Duration) error {
+		cancel()
+		return ctx.Err()
+	}
+	if err := limiter.Wait(ctx); err == nil {
+		t.Fatal("Expected a cancelled wait to fail")
+	}
+
+	// The cancelled request did not use up the next token
+	limiter.sleep = clock.sleep
+	clock.current = clock.current.Add(time.Second)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("Failed to wait: %v", err)
+	}
+	if len(clock.slept) != 0 {
+		t.Errorf("Expected the refilled token to be available, waited %v", clock.slept)
+	}
+}
@@ -0,0 +1,162 @@
+package testhelpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/ratelimit"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// FakeDiscordRequest is a request received by a FakeDiscord server.
+type FakeDiscordRequest struct {
+	Method string // Method is the HTTP method.
+	Path   string // Path is the API path without the /api/vN prefix, e.g. "/channels/123/messages".
+	Body   []byte // Body is the raw request body.
+}
+
+// FakeDiscord is an in-process stand-in for the Discord REST API.
+//
+// NewFakeDiscord points the discordgo endpoint variables at the fake server for the
+// duration of the test, so any session talks to it, and lifts the shared ratelimit.Messages limit
+// so requests to it are not paced. Tests using it must not run in parallel.
+//
+// By default, posting a message returns a message with a generated ID, listing messages returns
+// none, fetching a channel returns a text channel, opening a DM with a user returns the channel
+// "dm-<user ID>", and every other request succeeds with an empty JSON object.
+// Use Handle to override the response for a specific route.
+type FakeDiscord struct {
+	Server *httptest.Server
+
+	mu        sync.Mutex
+	requests  []FakeDiscordRequest
+	handlers  map[string]http.HandlerFunc
+	messageID int
+}
+
+// NewFakeDiscord starts a fake Discord API server that is shut down when the test ends.
+func NewFakeDiscord(t *testing.T) *FakeDiscord {
+	t.Helper()
+
+	f := &FakeDiscord{handlers: make(map[string]http.HandlerFunc)}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+
+	saved := []string{
+		discordgo.EndpointDiscord, discordgo.EndpointAPI, discordgo.EndpointGuilds,
+		discordgo.EndpointChannels, discordgo.EndpointUsers, discordgo.EndpointGateway,
+		discordgo.EndpointGatewayBot, discordgo.EndpointWebhooks, discordgo.EndpointGuildCreate,
+		discordgo.EndpointApplications,
+	}
+
+	discordgo.EndpointDiscord = f.Server.URL + "/"
+	discordgo.EndpointAPI = discordgo.EndpointDiscord + "api/v" + discordgo.APIVersion + "/"
+	discordgo.EndpointGuilds = discordgo.EndpointAPI + "guilds/"
+	discordgo.EndpointChannels = discordgo.EndpointAPI + "channels/"
+	discordgo.EndpointUsers = discordgo.EndpointAPI + "users/"
+	discordgo.EndpointGateway = discordgo.EndpointAPI + "gateway"
+	discordgo.EndpointGatewayBot = discordgo.EndpointGateway + "/bot"
+	discordgo.EndpointWebhooks = discordgo.EndpointAPI + "webhooks/"
+	discordgo.EndpointGuildCreate = discordgo.EndpointAPI + "guilds"
+	discordgo.EndpointApplications = discordgo.EndpointAPI + "applications"
+
+	limiter := ratelimit.Messages
+	ratelimit.Messages = ratelimit.New(1e6, 1e6)
+
+	t.Cleanup(func() {
+		f.Server.Close()
+		ratelimit.Messages = limiter
+		discordgo.EndpointDiscord, discordgo.EndpointAPI, discordgo.EndpointGuilds,
+			discordgo.EndpointChannels, discordgo.EndpointUsers, discordgo.EndpointGateway,
+			discordgo.EndpointGatewayBot, discordgo.EndpointWebhooks, discordgo.EndpointGuildCreate,
+			discordgo.EndpointApplications = saved[0], saved[1], saved[2], saved[3], saved[4],
+			saved[5], saved[6], saved[7], saved[8], saved[9]
+	})
+
+	return f
+}
+
+// Session returns a Discord session that talks to the fake server, with a bot user in its state.
+func (f *FakeDiscord) Session() *discordgo.Session {
+	session, _ := discordgo.New("Bot test_token")
+	session.State.User = &discordgo.User{ID: "bot-user", Username: "STOBot", Bot: true}
+	session.Client = f.Server.Client()
+	return session
+}
+
+// Handle overrides the response for requests with the given method and API path,
+// e.g. Handle("POST", "/channels/123/messages/456/crosspost", handler).
+func (f *FakeDiscord) Handle(method, path string, handler http.HandlerFunc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.handlers[method+" "+path] = handler
+}
+
+// Requests returns all requests received so far.
+func (f *FakeDiscord) Requests() []FakeDiscordRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]FakeDiscordRequest(nil), f.requests...)
+}
+
+// RequestsTo returns the received requests with the given method and API path.
+func (f *FakeDiscord) RequestsTo(method, path string) []FakeDiscordRequest {
+	var matched []FakeDiscordRequest
+	for _, req := range f.Requests() {
+		if req.Method == method && req.Path == path {
+			matched = append(matched, req)
+		}
+	}
+	return matched
+}
+
+func (f *FakeDiscord) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	path := strings.TrimPrefix(r.URL.Path, "/api/v"+discordgo.APIVersion)
+
+	f.mu.Lock()
+	f.requests = append(f.requests, FakeDiscordRequest{Method: r.Method, Path: path, Body: body})
+	handler := f.handlers[r.Method+" "+path]
+	f.mu.Unlock()
+
+	if handler != nil {
+		handler(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[0] == "channels" && parts[2] == "messages":
+		f.mu.Lock()
+		f.messageID++
+		id := fmt.Sprintf("msg-%d", f.messageID)
+		f.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"id": id, "channel_id": parts[1]})
+	case r.Method == http.MethodGet && len(parts) == 3 && parts[0] == "channels" && parts[2] == "messages":
+		_, _ = w.Write([]byte("[]"))
+	case r.Method == http.MethodGet && len(parts) == 2 && parts[0] == "channels":
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"id": parts[1], "type": discordgo.ChannelTypeGuildText})
+	case r.Method == http.MethodPost && path == "/users/@me/channels":
+		var dm struct {
+			RecipientID string `json:"recipient_id"`
+		}
+		_ = json.Unmarshal(body, &dm)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"id": "dm-" + dm.RecipientID, "type": discordgo.ChannelTypeDM})
+	default:
+		_, _ = w.Write([]byte("{}"))
+	}
+}
+
+// RespondJSON writes a JSON response with the given status code; it is a convenience for Handle.
+func RespondJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
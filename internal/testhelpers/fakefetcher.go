@@ -0,0 +1,93 @@
+package testhelpers
+
+import (
+	"sync"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// FakeNewsFetchCall is a fetch made through a FakeNewsFetcher.
+type FakeNewsFetchCall struct {
+	Tag     string             // Tag is the requested tag; empty for all news.
+	Count   int                // Count is the requested number of items.
+	Options types.FetchOptions // Options are the fetch options.
+	ID      int64              // ID is the requested news item of FetchNewsByID; 0 for FetchNews.
+}
+
+// FakeNewsFetcher is a types.NewsFetcher serving fixed news items, for tests of code that fetches
+// news without network access.
+//
+// A fetch returns the items of News with the requested tag (all of them for an empty tag), at
+// most Count of them, in order, skipping the first Options.Offset of them. Err, when set, is returned instead. Set Fetch to take over fetches
+// completely, e.g. to return different news on each call. FetchNewsByID returns the item of News
+// with the ID, or Err when set.
+type FakeNewsFetcher struct {
+	News  []types.NewsItem
+	Err   error
+	Fetch func(tag string, count int, options types.FetchOptions) ([]types.NewsItem, error)
+
+	mu    sync.Mutex
+	calls []FakeNewsFetchCall
+}
+
+// NewFakeNewsFetcher returns a fake fetcher serving newsItems.
+func NewFakeNewsFetcher(newsItems ...types.NewsItem) *FakeNewsFetcher {
+	return &FakeNewsFetcher{News: newsItems}
+}
+
+// FetchNews records the call and returns the matching news items.
+func (f *FakeNewsFetcher) FetchNews(tag string, count int, options types.FetchOptions) ([]types.NewsItem, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, FakeNewsFetchCall{Tag: tag, Count: count, Options: options})
+	fetch, newsItems, err := f.Fetch, f.News, f.Err
+	f.mu.Unlock()
+
+	if fetch != nil {
+		return fetch(tag, count, options)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var matching []types.NewsItem
+	skip := options.Offset
+	for _, newsItem := range newsItems {
+		if len(matching) >= count {
+			break
+		}
+		if tag != "" && !newsItem.HasTag(tag) {
+			continue
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		matching = append(matching, newsItem)
+	}
+	return matching, nil
+}
+
+// FetchNewsByID records the call and returns the news item with the ID, or nil if News has none.
+func (f *FakeNewsFetcher) FetchNewsByID(id int64) (*types.NewsItem, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, FakeNewsFetchCall{ID: id})
+	newsItems, err := f.News, f.Err
+	f.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+	for _, newsItem := range newsItems {
+		if newsItem.ID == id {
+			return &newsItem, nil
+		}
+	}
+	return nil, nil
+}
+
+// Calls returns the fetches made so far, in order.
+func (f *FakeNewsFetcher) Calls() []FakeNewsFetchCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]FakeNewsFetchCall(nil), f.calls...)
+}
@@ -24,6 +24,9 @@ func CreateTestBot(t *testing.T) *types.Bot {
 	if err != nil {
 		t.Fatalf("Failed to create test database: %v", err)
 	}
+	// Each connection to :memory: opens a new, empty database, so background writes such as
+	// command usage records must share the one connection
+	db.SetMaxOpenConns(1)
 
 	// Create basic tables for testing - match the real database schema
 	_, err = db.Exec(`
@@ -31,6 +34,30 @@ func CreateTestBot(t *testing.T) *types.Bot {
 			id TEXT PRIMARY KEY,
 			platforms TEXT NOT NULL DEFAULT 'pc,xbox,ps',
 			environment TEXT NOT NULL DEFAULT 'PROD' CHECK (environment IN ('DEV', 'PROD')),
+			spoiler_tags TEXT NOT NULL DEFAULT '',
+			auto_publish INTEGER NOT NULL DEFAULT 0,
+			strict_patch_notes INTEGER NOT NULL DEFAULT 0,
+			tags TEXT NOT NULL DEFAULT '',
+			excluded_tags TEXT NOT NULL DEFAULT '',
+			ping_role TEXT NOT NULL DEFAULT '',
+			digest_day INTEGER,
+			digest_hour INTEGER NOT NULL DEFAULT 0,
+			last_digest_at DATETIME,
+			webhook_url TEXT NOT NULL DEFAULT '',
+			quiet_hours_start INTEGER,
+			quiet_hours_end INTEGER,
+			guild_id TEXT,
+			locale TEXT NOT NULL DEFAULT 'en',
+			disabled INTEGER NOT NULL DEFAULT 0,
+			post_failures INTEGER NOT NULL DEFAULT 0,
+			create_threads INTEGER NOT NULL DEFAULT 0,
+			paused INTEGER NOT NULL DEFAULT 0,
+			pause_hold INTEGER NOT NULL DEFAULT 0,
+			max_posts_per_cycle INTEGER NOT NULL DEFAULT 0,
+			update_notices TEXT NOT NULL DEFAULT 'notice',
+			show_galleries INTEGER NOT NULL DEFAULT 0,
+			message_style TEXT NOT NULL DEFAULT 'embed',
+			create_events INTEGER NOT NULL DEFAULT 0,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
 		);
@@ -39,6 +66,14 @@ func CreateTestBot(t *testing.T) *types.Bot {
 			news_id INTEGER NOT NULL,
 			channel_id TEXT NOT NULL,
 			posted_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			posted_by TEXT,
+			bot_version TEXT,
+			message_id TEXT,
+			publish_status TEXT,
+			latency_seconds INTEGER,
+			delivery TEXT,
+			status TEXT NOT NULL DEFAULT 'sent',
+			thread_id TEXT,
 			UNIQUE(news_id, channel_id),
 			FOREIGN KEY (channel_id) REFERENCES channels(id)
 		);
@@ -51,7 +86,78 @@ func CreateTestBot(t *testing.T) *types.Bot {
 			platforms TEXT,
 			updated_at DATETIME,
 			thumbnail_url TEXT,
-			fetched_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			fetched_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			fingerprint TEXT,
+			url TEXT,
+			platform_dates TEXT,
+			author TEXT
+		);
+		CREATE TABLE IF NOT EXISTS localized_news (
+			id INTEGER NOT NULL,
+			locale TEXT NOT NULL,
+			title TEXT NOT NULL,
+			summary TEXT,
+			content TEXT,
+			tags TEXT,
+			platforms TEXT,
+			updated_at DATETIME,
+			thumbnail_url TEXT,
+			url TEXT,
+			fetched_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (id, locale)
+		);
+		CREATE TABLE IF NOT EXISTS user_subscriptions (
+			user_id TEXT PRIMARY KEY,
+			tags TEXT NOT NULL DEFAULT '',
+			platforms TEXT NOT NULL DEFAULT 'pc,xbox,ps',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE IF NOT EXISTS user_deliveries (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			news_id INTEGER NOT NULL,
+			user_id TEXT NOT NULL,
+			status TEXT NOT NULL,
+			delivered_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(news_id, user_id)
+		);
+		CREATE TABLE IF NOT EXISTS command_usage (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			command_name TEXT NOT NULL,
+			guild_id TEXT NOT NULL DEFAULT '',
+			user_hash TEXT NOT NULL,
+			used_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE IF NOT EXISTS populate_state (
+			tag TEXT PRIMARY KEY,
+			last_offset INTEGER NOT NULL DEFAULT 0,
+			completed INTEGER NOT NULL DEFAULT 0,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE IF NOT EXISTS held_news (
+			news_id INTEGER NOT NULL,
+			channel_id TEXT NOT NULL,
+			held_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (news_id, channel_id)
+		);
+		CREATE TABLE IF NOT EXISTS user_checkpoints (
+			user_id TEXT NOT NULL,
+			guild_id TEXT NOT NULL DEFAULT '',
+			checked_at DATETIME NOT NULL,
+			PRIMARY KEY (user_id, guild_id)
+		);
+		CREATE TABLE IF NOT EXISTS dry_run_posted (
+			news_id INTEGER NOT NULL,
+			channel_id TEXT NOT NULL,
+			delivery TEXT,
+			posted_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (news_id, channel_id)
+		);
+		CREATE TABLE IF NOT EXISTS news_events (
+			news_id INTEGER NOT NULL,
+			guild_id TEXT NOT NULL,
+			event_id TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (news_id, guild_id)
 		);
 	`)
 	if err != nil {
@@ -0,0 +1,104 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxEmbedColor is the largest RGB value Discord accepts as an embed color.
+const maxEmbedColor = 0xffffff
+
+// ParseEmbedColors parses embed color overrides per news tag, given either as a JSON object or as
+// comma-separated tag=color pairs. Colors are hex strings with an optional "#" or "0x" prefix;
+// JSON values may also be decimal numbers. Tags are lowercased and an empty spec yields no overrides.
+//
+// Example:
+//
+//	colors, err := types.ParseEmbedColors("patch-notes=#ff8800,events=0x9b59b6")
+//	colors, err = types.ParseEmbedColors(`{"patch-notes": "#ff8800", "events": 10181046}`)
+func ParseEmbedColors(spec string) (map[string]int, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+
+	colors := make(map[string]int)
+	if strings.HasPrefix(spec, "{") {
+		var values map[string]interface{}
+		if err := json.Unmarshal([]byte(spec), &values); err != nil {
+			return nil, fmt.Errorf("invalid embed colors: %v", err)
+		}
+		for tag, value := range values {
+			color, err := embedColorValue(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid embed color for tag %q: %v", tag, err)
+			}
+			if err := addEmbedColor(colors, tag, color); err != nil {
+				return nil, err
+			}
+		}
+		return colors, nil
+	}
+
+	for _, pair := range strings.Split(spec, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		tag, value, found := strings.Cut(pair, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid embed color %q: expected tag=color", pair)
+		}
+		color, err := parseHexColor(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid embed color for tag %q: %v", strings.TrimSpace(tag), err)
+		}
+		if err := addEmbedColor(colors, tag, color); err != nil {
+			return nil, err
+		}
+	}
+	return colors, nil
+}
+
+// addEmbedColor adds a color for a tag, normalizing the tag.
+func addEmbedColor(colors map[string]int, tag string, color int) error {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" {
+		return fmt.Errorf("invalid embed color: empty tag")
+	}
+	colors[tag] = color
+	return nil
+}
+
+// embedColorValue converts a JSON value to an embed color.
+func embedColorValue(value interface{}) (int, error) {
+	switch v := value.(type) {
+	case string:
+		return parseHexColor(v)
+	case float64:
+		if v != float64(int(v)) || v < 0 || v > maxEmbedColor {
+			return 0, fmt.Errorf("%v is not a color between 0 and %d", v, maxEmbedColor)
+		}
+		return int(v), nil
+	default:
+		return 0, fmt.Errorf("expected a hex string or a number, got %v", value)
+	}
+}
+
+// parseHexColor parses a hex color such as "#ff8800", "0xff8800" or "ff8800".
+func parseHexColor(value string) (int, error) {
+	hex := strings.TrimSpace(value)
+	hex = strings.TrimPrefix(hex, "#")
+	if strings.HasPrefix(strings.ToLower(hex), "0x") {
+		hex = hex[2:]
+	}
+	if hex == "" || len(hex) > 6 {
+		return 0, fmt.Errorf("%q is not a hex color", value)
+	}
+	color, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a hex color", value)
+	}
+	return int(color), nil
+}
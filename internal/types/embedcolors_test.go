@@ -0,0 +1,52 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseEmbedColors(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        string
+		expected    map[string]int
+		expectError bool
+	}{
+		{name: "empty", spec: "  ", expected: nil},
+		{
+			name:     "pairs",
+			spec:     "patch-notes=#ff8800, Events=0x9B59B6,dev-blogs=3498db,",
+			expected: map[string]int{"patch-notes": 0xff8800, "events": 0x9b59b6, "dev-blogs": 0x3498db},
+		},
+		{
+			name:     "JSON",
+			spec:     `{"patch-notes": "#ff8800", "default": 255}`,
+			expected: map[string]int{"patch-notes": 0xff8800, "default": 255},
+		},
+		{name: "missing color", spec: "patch-notes", expectError: true},
+		{name: "empty tag", spec: "=#ff8800", expectError: true},
+		{name: "not hex", spec: "events=purple", expectError: true},
+		{name: "too long", spec: "events=#ff88001", expectError: true},
+		{name: "invalid JSON", spec: `{"events": }`, expectError: true},
+		{name: "JSON color out of range", spec: `{"events": 16777216}`, expectError: true},
+		{name: "JSON color not a number or string", spec: `{"events": true}`, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			colors, err := ParseEmbedColors(tt.spec)
+			if tt.expectError {
+				if err == nil {
+					t.Fatalf("Expected an error, got %v", colors)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to parse embed colors: %v", err)
+			}
+			if !reflect.DeepEqual(colors, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, colors)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package types
+
+import (
+	"sort"
+	"strings"
+)
+
+// ImageRef is an image of a news article, from the images object of the API.
+type ImageRef struct {
+	Key    string // Key is the name of the image in the images object, e.g. img_microsite_background.
+	Index  int    // Index is the position of the image in a list of images under Key; 0 otherwise.
+	URL    string // URL is the address of the image.
+	Width  int    // Width is the width of the image in pixels; 0 if the API gave none.
+	Height int    // Height is the height of the image in pixels; 0 if the API gave none.
+}
+
+// thumbnailImageKeys are the images used as an article's thumbnail, in order of preference.
+var thumbnailImageKeys = []string{"img_microsite_thumbnail", "thumbnail", "img_microsite_background", "unhighlight_img"}
+
+// imageKeyRanks order the images of an article: the full-size background first, then other
+// images such as screenshots, then the smaller variants used for thumbnails.
+var imageKeyRanks = map[string]int{
+	"img_microsite_background": 0,
+	"unhighlight_img":          2,
+	"img_microsite_thumbnail":  3,
+	"thumbnail":                3,
+}
+
+// imageKeyRank returns the rank of an image key in the order of ParseImages.
+func imageKeyRank(key string) int {
+	if rank, ok := imageKeyRanks[key]; ok {
+		return rank
+	}
+	return 1
+}
+
+// ParseImages returns the images in an images object of the API, ordered with the full-size
+// background first, then other images, then thumbnails. Images of the same rank are ordered
+// largest first where the API gave sizes, then by key and list position.
+//
+// An image is an object with a url and optionally a width and height, or a list of such objects,
+// e.g. screenshots. Other entries, like platform_dates, and images without an http(s) URL are
+// skipped.
+//
+// Example:
+//
+//	images := types.ParseImages(item.Images)
+func ParseImages(images map[string]interface{}) []ImageRef {
+	var refs []ImageRef
+	for key, value := range images {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if ref, ok := parseImageRef(key, 0, v); ok {
+				refs = append(refs, ref)
+			}
+		case []interface{}:
+			for index, item := range v {
+				if image, ok := item.(map[string]interface{}); ok {
+					if ref, ok := parseImageRef(key, index, image); ok {
+						refs = append(refs, ref)
+					}
+				}
+			}
+		}
+	}
+
+	sort.Slice(refs, func(i, j int) bool {
+		a, b := refs[i], refs[j]
+		if rankA, rankB := imageKeyRank(a.Key), imageKeyRank(b.Key); rankA != rankB {
+			return rankA < rankB
+		}
+		if areaA, areaB := a.Width*a.Height, b.Width*b.Height; areaA != areaB {
+			return areaA > areaB
+		}
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		return a.Index < b.Index
+	})
+	return refs
+}
+
+// parseImageRef returns the image described by an image object, if it has a usable URL.
+func parseImageRef(key string, index int, image map[string]interface{}) (ImageRef, bool) {
+	url, _ := image["url"].(string)
+	url = strings.TrimSpace(url)
+	if !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+		return ImageRef{}, false
+	}
+	return ImageRef{Key: key, Index: index, URL: url, Width: imageSize(image["width"]), Height: imageSize(image["height"])}, true
+}
+
+// imageSize returns an image dimension given as a JSON number, or 0 if it is not one.
+func imageSize(value interface{}) int {
+	if size, ok := value.(float64); ok && size > 0 {
+		return int(size)
+	}
+	return 0
+}
+
+// ThumbnailImage returns the image of images to use as an article's thumbnail: the first of
+// img_microsite_thumbnail, thumbnail, img_microsite_background and unhighlight_img it has.
+func ThumbnailImage(images []ImageRef) (ImageRef, bool) {
+	for _, key := range thumbnailImageKeys {
+		for _, image := range images {
+			if image.Key == key {
+				return image, true
+			}
+		}
+	}
+	return ImageRef{}, false
+}
+
+// FullImage returns the full-size image of the NewsItem to show below its summary: the first of
+// its images in ParseImages order that is not its thumbnail. It returns false if the article has
+// no other image.
+//
+// Example:
+//
+//	if image, ok := item.FullImage(); ok {
+//	    embed.Image = &discordgo.MessageEmbedImage{URL: image.URL}
+//	}
+func (n *NewsItem) FullImage() (ImageRef, bool) {
+	for _, image := range ParseImages(n.Images) {
+		if image.URL != n.ThumbnailURL {
+			return image, true
+		}
+	}
+	return ImageRef{}, false
+}
+
+// GalleryImages returns the images of the NewsItem other than its thumbnail and full-size image,
+// such as screenshots, in ParseImages order and without duplicate URLs.
+//
+// Example:
+//
+//	screenshots := item.GalleryImages()
+func (n *NewsItem) GalleryImages() []ImageRef {
+	seen := map[string]bool{n.ThumbnailURL: true}
+	if image, ok := n.FullImage(); ok {
+		seen[image.URL] = true
+	}
+
+	var gallery []ImageRef
+	for _, image := range ParseImages(n.Images) {
+		if seen[image.URL] {
+			continue
+		}
+		seen[image.URL] = true
+		gallery = append(gallery, image)
+	}
+	return gallery
+}
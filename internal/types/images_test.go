@@ -0,0 +1,106 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// galleryNewsJSON is an article with a background, a thumbnail and three screenshots, one of
+// them the background again.
+const galleryNewsJSON = `{
+	"id": 1,
+	"title": "New Ships",
+	"images": {
+		"screenshots": [
+			{"url": "https://example.com/shot-1.jpg"},
+			{"url": "https://example.com/shot-2.jpg"},
+			{"url": "https://example.com/background.jpg"}
+		],
+		"img_microsite_thumbnail": {"url": "https://example.com/thumb.jpg"},
+		"img_microsite_background": {"url": "https://example.com/background.jpg", "width": 1920, "height": 1080},
+		"platform_dates": {"pc": "2024-06-04T16:00:00Z"}
+	}
+}`
+
+func TestParseImages(t *testing.T) {
+	images := map[string]interface{}{
+		"thumbnail":                map[string]interface{}{"url": "https://example.com/thumb.jpg"},
+		"img_microsite_background": map[string]interface{}{"url": "https://example.com/background.jpg"},
+		"banner":                   map[string]interface{}{"url": "https://example.com/banner.jpg", "width": 800.0, "height": 200.0},
+		"poster":                   map[string]interface{}{"url": " https://example.com/poster.jpg ", "width": 1000.0, "height": 1500.0},
+		"screenshots": []interface{}{
+			map[string]interface{}{"url": "https://example.com/shot-1.jpg"},
+			"https://example.com/not-an-object.jpg",
+			map[string]interface{}{"url": "https://example.com/shot-3.jpg"},
+		},
+		"relative":       map[string]interface{}{"url": "/images/relative.jpg"},
+		"missing_url":    map[string]interface{}{"width": 100.0},
+		"platform_dates": map[string]interface{}{"pc": "2024-06-04T16:00:00Z"},
+	}
+
+	expected := []ImageRef{
+		{Key: "img_microsite_background", URL: "https://example.com/background.jpg"},
+		{Key: "poster", URL: "https://example.com/poster.jpg", Width: 1000, Height: 1500},
+		{Key: "banner", URL: "https://example.com/banner.jpg", Width: 800, Height: 200},
+		{Key: "screenshots", Index: 0, URL: "https://example.com/shot-1.jpg"},
+		{Key: "screenshots", Index: 2, URL: "https://example.com/shot-3.jpg"},
+		{Key: "thumbnail", URL: "https://example.com/thumb.jpg"},
+	}
+	if refs := ParseImages(images); !reflect.DeepEqual(refs, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, refs)
+	}
+
+	if refs := ParseImages(nil); len(refs) != 0 {
+		t.Errorf("Expected no images, got %+v", refs)
+	}
+}
+
+func TestThumbnailImage(t *testing.T) {
+	images := []ImageRef{
+		{Key: "unhighlight_img", URL: "https://example.com/unhighlight.jpg"},
+		{Key: "img_microsite_background", URL: "https://example.com/background.jpg"},
+	}
+	if image, ok := ThumbnailImage(images); !ok || image.Key != "img_microsite_background" {
+		t.Errorf("Expected the background as thumbnail, got %+v", image)
+	}
+	if _, ok := ThumbnailImage([]ImageRef{{Key: "screenshots", URL: "https://example.com/shot.jpg"}}); ok {
+		t.Error("Expected no thumbnail among screenshots")
+	}
+}
+
+func TestNewsItemImages(t *testing.T) {
+	var item NewsItem
+	if err := json.Unmarshal([]byte(galleryNewsJSON), &item); err != nil {
+		t.Fatalf("Failed to unmarshal news item: %v", err)
+	}
+	if item.ThumbnailURL != "https://example.com/thumb.jpg" {
+		t.Errorf("Expected the microsite thumbnail, got %q", item.ThumbnailURL)
+	}
+
+	full, ok := item.FullImage()
+	if !ok || full.URL != "https://example.com/background.jpg" {
+		t.Errorf("Expected the background as full image, got %+v", full)
+	}
+
+	// The background is not repeated among the screenshots
+	var gallery []string
+	for _, image := range item.GalleryImages() {
+		gallery = append(gallery, image.URL)
+	}
+	expected := []string{"https://example.com/shot-1.jpg", "https://example.com/shot-2.jpg"}
+	if !reflect.DeepEqual(gallery, expected) {
+		t.Errorf("Expected gallery %v, got %v", expected, gallery)
+	}
+
+	// An article with only a thumbnail has no other image to show
+	item = NewsItem{ThumbnailURL: "https://example.com/thumb.jpg", Images: map[string]interface{}{
+		"thumbnail": map[string]interface{}{"url": "https://example.com/thumb.jpg"},
+	}}
+	if image, ok := item.FullImage(); ok {
+		t.Errorf("Expected no full image, got %+v", image)
+	}
+	if gallery := item.GalleryImages(); len(gallery) != 0 {
+		t.Errorf("Expected no gallery, got %+v", gallery)
+	}
+}
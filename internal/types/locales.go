@@ -0,0 +1,31 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultLocale is the locale news is fetched, cached and posted in unless a channel sets another.
+const DefaultLocale = "en"
+
+// Locales are the locales the news API serves news in.
+var Locales = []string{"en", "de", "fr"}
+
+// NormalizeLocale converts a locale to its canonical spelling, case-insensitively. An empty
+// locale is the default locale; locales the news API does not serve are an error.
+//
+// Example:
+//
+//	locale, err := types.NormalizeLocale(" DE ") // "de"
+func NormalizeLocale(locale string) (string, error) {
+	name := strings.ToLower(strings.TrimSpace(locale))
+	if name == "" {
+		return DefaultLocale, nil
+	}
+	for _, supported := range Locales {
+		if name == supported {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("unknown locale %q: valid locales are %s", strings.TrimSpace(locale), strings.Join(Locales, ", "))
+}
@@ -0,0 +1,38 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeLocale(t *testing.T) {
+	tests := []struct {
+		locale      string
+		expected    string
+		expectError string
+	}{
+		{locale: "en", expected: "en"},
+		{locale: " DE ", expected: "de"},
+		{locale: "fr", expected: "fr"},
+		{locale: "", expected: DefaultLocale},
+		{locale: "es", expectError: `unknown locale "es": valid locales are en, de, fr`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			locale, err := NormalizeLocale(tt.locale)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing %q, got %v (%q)", tt.expectError, err, locale)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to normalize locale: %v", err)
+			}
+			if locale != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, locale)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Platforms are the platforms news is published for, in their canonical spelling.
+var Platforms = []string{"pc", "xbox", "ps"}
+
+// platformAliases maps accepted spellings to the platforms they stand for.
+var platformAliases = map[string][]string{
+	"pc":          {"pc"},
+	"xbox":        {"xbox"},
+	"ps":          {"ps"},
+	"playstation": {"ps"},
+	"ps4":         {"ps"},
+	"ps5":         {"ps"},
+	"all":         Platforms,
+}
+
+// NormalizePlatforms converts platform names to their canonical spelling, case-insensitively:
+// "playstation", "ps4" and "ps5" become "ps", and "all" expands to every platform. Duplicates are
+// removed and the order of first appearance is kept. Unknown or missing platforms are an error.
+//
+// Example:
+//
+//	platforms, err := types.NormalizePlatforms([]string{"PC", "PS5"}) // [pc ps]
+func NormalizePlatforms(platforms []string) ([]string, error) {
+	var normalized []string
+	seen := make(map[string]bool)
+	for _, platform := range platforms {
+		name := strings.ToLower(strings.TrimSpace(platform))
+		if name == "" {
+			continue
+		}
+		canonical, ok := platformAliases[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown platform %q: valid platforms are %s, or all", strings.TrimSpace(platform), strings.Join(Platforms, ", "))
+		}
+		for _, p := range canonical {
+			if !seen[p] {
+				seen[p] = true
+				normalized = append(normalized, p)
+			}
+		}
+	}
+	if len(normalized) == 0 {
+		return nil, errors.New("no platforms given: valid platforms are " + strings.Join(Platforms, ", ") + ", or all")
+	}
+	return normalized, nil
+}
+
+// ParsePlatforms parses a comma-separated platform list with NormalizePlatforms.
+//
+// Example:
+//
+//	platforms, err := types.ParsePlatforms("pc, playstation")
+func ParsePlatforms(spec string) ([]string, error) {
+	return NormalizePlatforms(strings.Split(spec, ","))
+}
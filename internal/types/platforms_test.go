@@ -0,0 +1,44 @@
+package types
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePlatforms(t *testing.T) {
+	tests := []struct {
+		spec        string
+		expected    []string
+		expectError string
+	}{
+		{spec: "pc,xbox,ps", expected: []string{"pc", "xbox", "ps"}},
+		{spec: " PC , Xbox ", expected: []string{"pc", "xbox"}},
+		{spec: "playstation", expected: []string{"ps"}},
+		{spec: "PS4,ps5,ps", expected: []string{"ps"}},
+		{spec: "xbox,all", expected: []string{"xbox", "pc", "ps"}},
+		{spec: "ALL", expected: []string{"pc", "xbox", "ps"}},
+		{spec: "pc,,", expected: []string{"pc"}},
+		{spec: "pc,xobx", expectError: `unknown platform "xobx": valid platforms are pc, xbox, ps, or all`},
+		{spec: "switch", expectError: `unknown platform "switch"`},
+		{spec: " , ", expectError: "no platforms given"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			platforms, err := ParsePlatforms(tt.spec)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("Expected error containing %q, got %v (%v)", tt.expectError, err, platforms)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to parse platforms: %v", err)
+			}
+			if !reflect.DeepEqual(platforms, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, platforms)
+			}
+		})
+	}
+}
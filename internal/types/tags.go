@@ -0,0 +1,151 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// DefaultTagAliases maps spellings the news API uses for some tags to the canonical tag, e.g.
+// "Dev Blog" (normalized to "dev-blog") to "dev-blogs". Keys and values are normalized.
+var DefaultTagAliases = map[string]string{
+	"dev-blog":       "dev-blogs",
+	"devblog":        "dev-blogs",
+	"devblogs":       "dev-blogs",
+	"patch-note":     "patch-notes",
+	"patchnotes":     "patch-notes",
+	"event":          "events",
+	"sto":            "star-trek-online",
+	"startrekonline": "star-trek-online",
+}
+
+// NormalizeTag returns the canonical form of a tag: lowercased, with runs of spaces, underscores
+// and hyphens as a single hyphen and none at either end, then mapped through aliases, or
+// DefaultTagAliases if aliases is nil. Normalizing a canonical tag returns it unchanged.
+//
+// Example:
+//
+//	tag := types.NormalizeTag(" Patch Notes ", nil) // "patch-notes"
+//	tag = types.NormalizeTag("Dev Blog", nil)       // "dev-blogs"
+func NormalizeTag(tag string, aliases map[string]string) string {
+	words := strings.FieldsFunc(strings.ToLower(tag), func(r rune) bool {
+		return unicode.IsSpace(r) || r == '_' || r == '-'
+	})
+	tag = strings.Join(words, "-")
+	if aliases == nil {
+		aliases = DefaultTagAliases
+	}
+	if canonical, ok := aliases[tag]; ok {
+		return canonical
+	}
+	return tag
+}
+
+// NormalizeTags normalizes tags with NormalizeTag, dropping empty and repeated tags. The first
+// occurrence of a tag keeps its place.
+func NormalizeTags(tags []string, aliases map[string]string) []string {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = NormalizeTag(tag, aliases)
+		if tag == "" || slices.Contains(normalized, tag) {
+			continue
+		}
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// NormalizeTags normalizes tags with the configured TagAliases (see NormalizeTag). A nil Config
+// uses DefaultTagAliases.
+func (c *Config) NormalizeTags(tags []string) []string {
+	if c == nil {
+		return NormalizeTags(tags, nil)
+	}
+	return NormalizeTags(tags, c.TagAliases)
+}
+
+// ParseTagAliases parses tag aliases, given either as a JSON object or as comma-separated
+// alias=tag pairs, and returns them merged with DefaultTagAliases. Aliases and tags are
+// normalized. A configured alias replaces the default one, and a tag configured as the target of
+// an alias is never itself aliased, so aliases map straight to canonical tags. An empty spec
+// yields DefaultTagAliases.
+//
+// Example:
+//
+//	aliases, err := types.ParseTagAliases("maintenance=server-maintenance,blog=dev-blogs")
+//	aliases, err = types.ParseTagAliases(`{"maintenance": "server-maintenance"}`)
+func ParseTagAliases(spec string) (map[string]string, error) {
+	overrides := make(map[string]string)
+	spec = strings.TrimSpace(spec)
+	switch {
+	case spec == "":
+	case strings.HasPrefix(spec, "{"):
+		var values map[string]string
+		if err := json.Unmarshal([]byte(spec), &values); err != nil {
+			return nil, fmt.Errorf("invalid tag aliases: %v", err)
+		}
+		for alias, tag := range values {
+			if err := addTagAlias(overrides, alias, tag); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		for _, pair := range strings.Split(spec, ",") {
+			if strings.TrimSpace(pair) == "" {
+				continue
+			}
+			alias, tag, found := strings.Cut(pair, "=")
+			if !found {
+				return nil, fmt.Errorf("invalid tag alias %q: expected alias=tag", pair)
+			}
+			if err := addTagAlias(overrides, alias, tag); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for alias, tag := range overrides {
+		if _, chained := overrides[tag]; chained {
+			return nil, fmt.Errorf("invalid tag alias %q: its tag %q is itself an alias", alias, tag)
+		}
+	}
+
+	aliases := make(map[string]string, len(DefaultTagAliases)+len(overrides))
+	for alias, tag := range DefaultTagAliases {
+		if isAliasTarget(overrides, alias) {
+			continue
+		}
+		if override, ok := overrides[tag]; ok {
+			tag = override
+		}
+		aliases[alias] = tag
+	}
+	for alias, tag := range overrides {
+		aliases[alias] = tag
+	}
+	return aliases, nil
+}
+
+// addTagAlias adds an alias of a tag, normalizing both.
+func addTagAlias(aliases map[string]string, alias, tag string) error {
+	noAliases := map[string]string{}
+	if NormalizeTag(alias, noAliases) == "" || NormalizeTag(tag, noAliases) == "" {
+		return fmt.Errorf("invalid tag alias %q: empty alias or tag", strings.TrimSpace(alias)+"="+strings.TrimSpace(tag))
+	}
+	alias, tag = NormalizeTag(alias, noAliases), NormalizeTag(tag, noAliases)
+	if alias != tag {
+		aliases[alias] = tag
+	}
+	return nil
+}
+
+// isAliasTarget reports whether tag is the target of any of aliases.
+func isAliasTarget(aliases map[string]string, tag string) bool {
+	for _, target := range aliases {
+		if target == tag {
+			return true
+		}
+	}
+	return false
+}
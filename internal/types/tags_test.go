@@ -0,0 +1,107 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected string
+	}{
+		{"patch-notes", "patch-notes"},
+		{" Patch Notes ", "patch-notes"},
+		{"patch_notes", "patch-notes"},
+		{"Patch  --  Notes", "patch-notes"},
+		{"Dev Blog", "dev-blogs"},
+		{"devblog", "dev-blogs"},
+		{"Event", "events"},
+		{"STO", "star-trek-online"},
+		{"-star-trek-online-", "star-trek-online"},
+		{"  ", ""},
+	}
+	for _, tt := range tests {
+		tag := NormalizeTag(tt.tag, nil)
+		if tag != tt.expected {
+			t.Errorf("NormalizeTag(%q) = %q, want %q", tt.tag, tag, tt.expected)
+		}
+		// Normalizing again changes nothing
+		if again := NormalizeTag(tag, nil); again != tag {
+			t.Errorf("NormalizeTag(%q) = %q, want it unchanged", tag, again)
+		}
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	tags := NormalizeTags([]string{"Dev Blog", "events", "", "dev-blogs", "Event", "Patch Notes"}, nil)
+	if expected := []string{"dev-blogs", "events", "patch-notes"}; !reflect.DeepEqual(tags, expected) {
+		t.Errorf("Expected %v, got %v", expected, tags)
+	}
+
+	var config *Config
+	if tags := config.NormalizeTags([]string{"Event"}); !reflect.DeepEqual(tags, []string{"events"}) {
+		t.Errorf("Expected a nil config to use the default aliases, got %v", tags)
+	}
+}
+
+func TestParseTagAliases(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        string
+		check       map[string]string // check maps tags to their expected normalization
+		expectError bool
+	}{
+		{name: "empty", spec: "", check: map[string]string{"Dev Blog": "dev-blogs", "maintenance": "maintenance"}},
+		{
+			name:  "pairs",
+			spec:  "Maintenance=Server Maintenance, blog=dev-blogs,",
+			check: map[string]string{"maintenance": "server-maintenance", "Blog": "dev-blogs", "devblog": "dev-blogs"},
+		},
+		{
+			name:  "JSON",
+			spec:  `{"maintenance": "server-maintenance"}`,
+			check: map[string]string{"Maintenance": "server-maintenance", "event": "events"},
+		},
+		{
+			// A configured tag is canonical, even where a default alias maps it elsewhere
+			name:  "target of a default alias",
+			spec:  "events=event",
+			check: map[string]string{"events": "event", "Event": "event"},
+		},
+		{
+			// Default aliases of a configured alias follow it
+			name:  "alias of a default target",
+			spec:  "dev-blogs=blog",
+			check: map[string]string{"devblog": "blog", "Dev Blogs": "blog", "blog": "blog"},
+		},
+		{name: "missing tag", spec: "maintenance", expectError: true},
+		{name: "empty alias", spec: "=events", expectError: true},
+		{name: "chained", spec: "a=b,b=c", expectError: true},
+		{name: "invalid JSON", spec: `{"a": 1}`, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aliases, err := ParseTagAliases(tt.spec)
+			if tt.expectError {
+				if err == nil {
+					t.Fatalf("Expected an error, got %v", aliases)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for tag, expected := range tt.check {
+				normalized := NormalizeTag(tag, aliases)
+				if normalized != expected {
+					t.Errorf("NormalizeTag(%q) = %q, want %q", tag, normalized, expected)
+				}
+				if again := NormalizeTag(normalized, aliases); again != normalized {
+					t.Errorf("NormalizeTag(%q) = %q, want it unchanged", normalized, again)
+				}
+			}
+		})
+	}
+}
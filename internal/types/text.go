@@ -0,0 +1,69 @@
+package types
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TruncateText truncates text to at most maxLength characters, adding an ellipsis if needed.
+// It counts runes, so multi-byte characters are never split.
+//
+// Example:
+//
+//	title := types.TruncateText(item.Title, 256)
+func TruncateText(text string, maxLength int) string {
+	return truncateRunes(text, maxLength, false)
+}
+
+// TruncateTextAtWord is like TruncateText, but cuts after the last whole word that fits. Text
+// whose last word break would drop more than half of it, such as a long URL, is cut mid-word.
+func TruncateTextAtWord(text string, maxLength int) string {
+	return truncateRunes(text, maxLength, true)
+}
+
+// TruncateBytes truncates text to at most maxBytes bytes, adding an ellipsis if needed, without
+// splitting a UTF-8 character. Use it for limits counted in bytes, such as Discord's when the
+// text may hold multi-byte characters.
+func TruncateBytes(text string, maxBytes int) string {
+	if len(text) <= maxBytes {
+		return text
+	}
+
+	if maxBytes <= 3 {
+		return strings.Repeat(".", max(maxBytes, 0))
+	}
+
+	cut := maxBytes - 3
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut] + "..."
+}
+
+// truncateRunes implements TruncateText and TruncateTextAtWord.
+func truncateRunes(text string, maxLength int, atWord bool) string {
+	if utf8.RuneCountInString(text) <= maxLength {
+		return text
+	}
+
+	if maxLength <= 3 {
+		// Return truncated ellipsis to fit within maxLength
+		return strings.Repeat(".", max(maxLength, 0))
+	}
+
+	runes := []rune(text)
+	kept := runes[:maxLength-3]
+	if !atWord {
+		return string(kept) + "..."
+	}
+	if !unicode.IsSpace(runes[len(kept)]) {
+		for at := len(kept) - 1; at >= len(kept)/2; at-- {
+			if unicode.IsSpace(kept[at]) {
+				kept = kept[:at]
+				break
+			}
+		}
+	}
+	return strings.TrimRightFunc(string(kept), unicode.IsSpace) + "..."
+}
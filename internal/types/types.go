@@ -4,10 +4,13 @@
 package types
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -41,6 +44,97 @@ type Config struct {
 	ChannelsPath string // ChannelsPath is the path to the file containing channel configurations.
 	DatabasePath string // DatabasePath is the path to the SQLite database file.
 	Environment  string // Environment is the current environment (DEV or PROD) for filtering channels.
+	BaseURL      string // BaseURL overrides the news API endpoint, e.g. for a proxy or mock server; empty uses the Arc Games API.
+
+	// PollTags are the news tags each poll cycle fetches PollCount items of, merged; empty fetches
+	// the API's default feed, which omits some items only listed under their tag.
+	PollTags []string
+
+	// GameStatusURL overrides the STO server status endpoint used by /stobot_game_status; empty uses the launcher's.
+	GameStatusURL string
+
+	// DefaultThumbnailURL replaces article thumbnails that can no longer be loaded; empty drops them.
+	DefaultThumbnailURL string
+
+	// SkipDuplicateCheck disables checking a channel's recent messages for an article before posting it,
+	// for deployments where the bot lacks the Read Message History permission.
+	SkipDuplicateCheck bool
+
+	// DryRun logs the news the poller would post instead of posting it, and records it as posted
+	// apart from real posts, e.g. to try a staging setup against the real news API and database.
+	// Nothing is sent to Discord, so no Discord token is needed.
+	DryRun bool
+
+	// DisableUsageStats stops recording which slash commands are used for the engagement report.
+	DisableUsageStats bool
+
+	// PostConcurrency is how many channels a poll cycle posts to at once; 0 uses the default.
+	PostConcurrency int
+
+	// CacheRetentionDays is how many days unposted news stays in the cache; 0 keeps it forever.
+	CacheRetentionDays int
+
+	// CatchUpDays is how many days back unposted news is posted at startup; 0 disables the catch-up.
+	CatchUpDays int
+
+	// DisableAfterFailures is how many consecutive posts to a channel may fail because it was
+	// deleted or the bot lost access before the channel is disabled; 0 never disables channels.
+	DisableAfterFailures int
+
+	// DuplicateWindowDays is how many days a posted article keeps copies of it republished under
+	// a new ID from being posted to the same channel; 0 disables the check.
+	DuplicateWindowDays int
+
+	// FutureSkewSeconds is how far in the future a news item may be dated and still be posted;
+	// items dated later are held back until they are due. 0 uses the default.
+	FutureSkewSeconds int
+
+	// MaxPostsPerCycle is how many news items may be posted to a channel per poll cycle or
+	// catch-up, unless the channel sets its own; the rest waits for later cycles, oldest first.
+	// 0 disables the limit.
+	MaxPostsPerCycle int
+
+	// UpdateNoticeSeconds is how much later than the cached version a fetched article must be
+	// dated before channels it was posted to are told it was updated. 0 uses the default.
+	UpdateNoticeSeconds int
+
+	// GalleryMaxImages is how many extra images of an article, such as screenshots, are posted as a
+	// gallery in channels showing galleries, at most 9. 0 uses the default.
+	GalleryMaxImages int
+
+	// RegisterBackfillCount is how many of the newest cached news items a channel registered
+	// with backfill recent is sent, at most 50. 0 uses the default.
+	RegisterBackfillCount int
+
+	// ThreadArchiveMinutes is the inactivity in minutes after which Discord archives the discussion
+	// thread of a news post: 60, 1440, 4320 or 10080. 0 uses the default.
+	ThreadArchiveMinutes int
+
+	// SearchCooldownUses is how many expensive searches each user may run per
+	// SearchCooldownSeconds; administrators are not limited. 0 disables the cooldown.
+	SearchCooldownUses    int
+	SearchCooldownSeconds int // SearchCooldownSeconds is the search cooldown window in seconds.
+
+	// EmbedColors overrides the embed color of news with a tag; the "default" key overrides the
+	// color of news without a colored tag.
+	EmbedColors map[string]int
+
+	// TagAliases maps tag spellings to canonical tags, see ParseTagAliases; nil uses
+	// DefaultTagAliases.
+	TagAliases map[string]string
+
+	URLRewrites []URLRewriteRule // URLRewrites are applied to article links and thumbnails before they are displayed.
+}
+
+// ThreadArchiveDurations are the thread auto-archive durations in minutes Discord accepts.
+var ThreadArchiveDurations = []int{60, 1440, 4320, 10080}
+
+// ValidateEnvironment checks that environment is a bot environment, DEV or PROD.
+func ValidateEnvironment(environment string) error {
+	if environment != "DEV" && environment != "PROD" {
+		return fmt.Errorf("environment must be 'DEV' or 'PROD', got %q", environment)
+	}
+	return nil
 }
 
 // Validate checks if the Config is valid. Returns an error if any required field is missing or invalid.
@@ -52,7 +146,7 @@ type Config struct {
 //	    // handle error
 //	}
 func (c *Config) Validate() error {
-	if c.DiscordToken == "" {
+	if c.DiscordToken == "" && !c.DryRun {
 		return errors.New("discord token is required")
 	}
 	if c.PollPeriod <= 0 {
@@ -67,11 +161,67 @@ func (c *Config) Validate() error {
 	if c.MsgCount <= 0 {
 		return errors.New("message count must be positive")
 	}
+	if c.PostConcurrency < 0 {
+		return errors.New("post concurrency must not be negative")
+	}
+	if c.CacheRetentionDays < 0 {
+		return errors.New("cache retention days must not be negative")
+	}
+	if c.CatchUpDays < 0 {
+		return errors.New("catch-up days must not be negative")
+	}
+	if c.DisableAfterFailures < 0 {
+		return errors.New("disable after failures must not be negative")
+	}
+	if c.DuplicateWindowDays < 0 {
+		return errors.New("duplicate window days must not be negative")
+	}
+	if c.FutureSkewSeconds < 0 {
+		return errors.New("future skew seconds must not be negative")
+	}
+	if c.MaxPostsPerCycle < 0 {
+		return errors.New("max posts per cycle must not be negative")
+	}
+	if c.UpdateNoticeSeconds < 0 {
+		return errors.New("update notice seconds must not be negative")
+	}
+	if c.GalleryMaxImages < 0 || c.GalleryMaxImages > 9 {
+		return fmt.Errorf("gallery max images must be between 0 and 9, got %d", c.GalleryMaxImages)
+	}
+	if c.RegisterBackfillCount < 0 || c.RegisterBackfillCount > 50 {
+		return fmt.Errorf("register backfill count must be between 0 and 50, got %d", c.RegisterBackfillCount)
+	}
+	if c.SearchCooldownUses < 0 || c.SearchCooldownSeconds < 0 {
+		return errors.New("search cooldown must not be negative")
+	}
+	if c.ThreadArchiveMinutes != 0 && !slices.Contains(ThreadArchiveDurations, c.ThreadArchiveMinutes) {
+		return fmt.Errorf("thread archive minutes must be one of %v, got %d", ThreadArchiveDurations, c.ThreadArchiveMinutes)
+	}
 	if c.DatabasePath == "" {
 		return errors.New("database path is required")
 	}
-	if c.Environment != "" && c.Environment != "DEV" && c.Environment != "PROD" {
-		return errors.New("environment must be 'DEV' or 'PROD'")
+	if c.Environment != "" {
+		if err := ValidateEnvironment(c.Environment); err != nil {
+			return err
+		}
+	}
+	if c.BaseURL != "" {
+		parsed, err := url.Parse(c.BaseURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return errors.New("API base URL must be an absolute http(s) URL")
+		}
+	}
+	if c.GameStatusURL != "" {
+		parsed, err := url.Parse(c.GameStatusURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return errors.New("game status URL must be an absolute http(s) URL")
+		}
+	}
+	if c.DefaultThumbnailURL != "" {
+		parsed, err := url.Parse(c.DefaultThumbnailURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return errors.New("default thumbnail URL must be an absolute http(s) URL")
+		}
 	}
 	return nil
 }
@@ -86,9 +236,151 @@ func (c *Config) Validate() error {
 //	    Config:  &config,
 //	}
 type Bot struct {
-	Session *discordgo.Session // Session is the Discord session used by the bot.
-	DB      *sql.DB            // DB is the SQLite database connection used by the bot.
-	Config  *Config            // Config is the bot's configuration.
+	Session    *discordgo.Session // Session is the Discord session used by the bot.
+	DB         *sql.DB            // DB is the SQLite database connection used by the bot.
+	Config     *Config            // Config is the bot's configuration.
+	InstanceID string             // InstanceID identifies this bot process in posted_news rows (see BuildInstanceID).
+	Version    string             // Version is the build version recorded alongside posted_news rows.
+	Fetcher    NewsFetcher        // Fetcher fetches news; nil fetches from the news API in Config.
+	Poster     NewsPoster         // Poster posts news to channels; nil posts to Discord, or logs the posts in a dry run.
+}
+
+// NewsPoster posts news items to channels and records what was posted to each. The poller
+// checks and records posts only through it, so a poster may keep its own record.
+type NewsPoster interface {
+	// IsPosted reports whether a news item was posted to a channel.
+	IsPosted(b *Bot, newsID int64, channelID string) (bool, error)
+	// MarkPosted records a news item as posted to a channel without posting it, e.g. because the
+	// channel excludes its tag. delivery is how the item reaches the channel otherwise, e.g.
+	// "digest", or empty if it does not.
+	MarkPosted(b *Bot, newsItem NewsItem, channelID, delivery string) error
+	// Post posts a news item to a channel and records it as posted by delivery, e.g. "live" for
+	// the poller or "catchup". It returns false without an error if the item is already being
+	// posted to the channel elsewhere.
+	Post(ctx context.Context, b *Bot, cfg ChannelConfig, newsItem NewsItem, delivery string) (bool, error)
+}
+
+// NewsFetcher fetches news items from a news source. tag selects news with a tag (empty for all
+// news) and count is the number of items to fetch. FetchNewsByID fetches a single news item with
+// its full content, and returns nil if the source does not know the ID.
+type NewsFetcher interface {
+	FetchNews(tag string, count int, options FetchOptions) ([]NewsItem, error)
+	FetchNewsByID(id int64) (*NewsItem, error)
+}
+
+// BuildInstanceID returns the identifier recorded as posted_by for news posted by this process.
+// It combines the environment, hostname and shard so rows from different instances sharing
+// one database can be told apart. An empty environment is reported as "ANY".
+//
+// Example:
+//
+//	id := types.BuildInstanceID("PROD", "bot-host", 0) // "PROD@bot-host#0"
+func BuildInstanceID(environment, hostname string, shard int) string {
+	if environment == "" {
+		environment = "ANY"
+	}
+	if hostname == "" {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s@%s#%d", environment, hostname, shard)
+}
+
+// UserSubscription is a user's subscription to news by direct message.
+type UserSubscription struct {
+	UserID    string    // UserID is the subscribed Discord user.
+	Tags      []string  // Tags are the news tags the user gets messages for.
+	Platforms []string  // Platforms are the platforms the user gets messages for.
+	CreatedAt time.Time // CreatedAt is when the user first subscribed.
+}
+
+// ChannelConfig holds a registered channel's posting configuration.
+//
+// Example:
+//
+//	cfg := types.ChannelConfig{
+//	    ID:          "123456789",
+//	    Platforms:   []string{"pc"},
+//	    Environment: "PROD",
+//	}
+type ChannelConfig struct {
+	ID               string   // ID is the Discord channel ID.
+	Platforms        []string // Platforms are the platforms the channel is subscribed to.
+	Environment      string   // Environment is the bot environment (DEV or PROD) serving the channel.
+	SpoilerTags      []string // SpoilerTags are tags whose articles are posted with their summary hidden.
+	AutoPublish      bool     // AutoPublish crossposts bot messages when the channel is an announcement channel.
+	StrictPatchNotes bool     // StrictPatchNotes skips patch notes whose title names only other platforms.
+	Tags             []string // Tags are the news tags posted to the channel; empty means all tags.
+	ExcludedTags     []string // ExcludedTags are news tags never posted to the channel, even if listed in Tags.
+	PingRole         string   // PingRole is the ID of the role mentioned in news posts; empty for none.
+	GuildID          string   // GuildID is the ID of the server the channel belongs to; empty until it is known.
+	Locale           string   // Locale is the locale news is posted in, e.g. "de"; empty means DefaultLocale.
+
+	// Disabled stops news from being posted to the channel, e.g. after it was deleted or the bot lost access.
+	Disabled bool
+	// PostFailures counts consecutive posts that failed because the channel is gone or inaccessible.
+	PostFailures int
+	// CreateThreads starts a public discussion thread on every news post in the channel.
+	CreateThreads bool
+	// Paused stops news from being posted to the channel until it is resumed, keeping its configuration.
+	// News released meanwhile is skipped, or held for the channel when PauseHold is set.
+	Paused    bool
+	PauseHold bool // PauseHold queues news released during a pause and posts it once the channel is resumed.
+	// MaxPostsPerCycle is how many news items may be posted to the channel per poll cycle or
+	// catch-up; the rest waits for later cycles. 0 uses Config.MaxPostsPerCycle.
+	MaxPostsPerCycle int
+	// UpdateNotices is how the channel is told about articles updated after they were posted
+	// there: UpdateNoticesPost, UpdateNoticesEdit or UpdateNoticesOff.
+	UpdateNotices string
+	// ShowGalleries posts the extra images of articles with at least two, such as screenshots, as a
+	// gallery below the article's embed.
+	ShowGalleries bool
+	// MessageStyle is how news is posted to the channel: MessageStyleEmbed or MessageStyleCompact.
+	MessageStyle string
+	// CreateEvents creates a Discord scheduled event in the channel's server for the in-game
+	// events announced in its news posts, once per article and server.
+	CreateEvents bool
+
+	// WebhookURL is the webhook news is posted through instead of the bot user; empty posts as the bot.
+	// It contains the webhook's token and must not be logged.
+	WebhookURL string
+
+	Digest     bool         // Digest collects the channel's news into a weekly digest instead of posting each article.
+	DigestDay  time.Weekday // DigestDay is the weekday the weekly digest is posted on.
+	DigestHour int          // DigestHour is the UTC hour the weekly digest is posted at.
+
+	QuietHours      bool // QuietHours holds automatic posts back from QuietHoursStart until QuietHoursEnd.
+	QuietHoursStart int  // QuietHoursStart is the UTC hour quiet hours begin at.
+	QuietHoursEnd   int  // QuietHoursEnd is the UTC hour quiet hours end at; if before QuietHoursStart, they span midnight.
+}
+
+// Update notice modes of a channel, see ChannelConfig.UpdateNotices.
+const (
+	UpdateNoticesPost = "notice" // UpdateNoticesPost replies to the original post with a short notice linking to it.
+	UpdateNoticesEdit = "edit"   // UpdateNoticesEdit edits the original post to show the updated article.
+	UpdateNoticesOff  = "off"    // UpdateNoticesOff ignores article updates.
+)
+
+// Message styles of a channel, see ChannelConfig.MessageStyle.
+const (
+	MessageStyleEmbed   = "embed"   // MessageStyleEmbed posts news as an embed with the article's summary and image.
+	MessageStyleCompact = "compact" // MessageStyleCompact posts news as a single line linking to the article.
+)
+
+// InQuietHours reports whether t falls within the channel's quiet hours.
+//
+// Example:
+//
+//	cfg := types.ChannelConfig{QuietHours: true, QuietHoursStart: 22, QuietHoursEnd: 7}
+//	cfg.InQuietHours(time.Date(2024, 6, 1, 3, 0, 0, 0, time.UTC)) // true
+func (c ChannelConfig) InQuietHours(t time.Time) bool {
+	if !c.QuietHours || c.QuietHoursStart == c.QuietHoursEnd {
+		return false
+	}
+	hour := t.UTC().Hour()
+	if c.QuietHoursStart < c.QuietHoursEnd {
+		return hour >= c.QuietHoursStart && hour < c.QuietHoursEnd
+	}
+	return hour >= c.QuietHoursStart || hour < c.QuietHoursEnd
 }
 
 // NewsItem represents a news article from the STO API.
@@ -113,6 +405,41 @@ type NewsItem struct {
 	Updated      time.Time              `json:"updated"`       // Updated is the timestamp of the last update to the news item.
 	ThumbnailURL string                 `json:"thumbnail_url"` // ThumbnailURL is the URL of the thumbnail image for the news item.
 	Images       map[string]interface{} `json:"images"`        // Images is a map of image metadata for the news item.
+	URL          string                 `json:"url"`           // URL is the canonical link to the article; see Link.
+
+	// PlatformDates are the release dates of the news item per canonical platform, for articles
+	// released on consoles later than on PC. Empty when the API gave none; see ReleasedFor.
+	PlatformDates map[string]time.Time `json:"platform_dates,omitempty"`
+
+	// Author is the byline of the article, e.g. the developer who wrote a dev blog; empty when
+	// the API gave none.
+	Author string `json:"author,omitempty"`
+}
+
+// ArticleSiteURL is the STO website that article links are relative to.
+const ArticleSiteURL = "https://playstartrekonline.com"
+
+// ArticleURL returns the default link to a news article on the STO website, for news the API
+// gave no canonical URL for.
+//
+// Example:
+//
+//	link := types.ArticleURL(11523743) // https://playstartrekonline.com/en/news/article/11523743
+func ArticleURL(newsID int64) string {
+	return fmt.Sprintf("%s/en/news/article/%d", ArticleSiteURL, newsID)
+}
+
+// Link returns the canonical URL of the NewsItem, or the default article link if it has none,
+// e.g. when it was cached before URLs were stored.
+//
+// Example:
+//
+//	embed.URL = item.Link()
+func (n *NewsItem) Link() string {
+	if n.URL != "" {
+		return n.URL
+	}
+	return ArticleURL(n.ID)
 }
 
 // IsEmpty reports whether the NewsItem has no title and no summary.
@@ -171,12 +498,39 @@ func (n *NewsItem) String() string {
 		n.ID, n.Title, n.Updated.Format(time.RFC3339), n.Platforms, n.Tags)
 }
 
+// ReleasedFor returns when the news item was released on the latest of platforms, or on the
+// latest platform it has a date for when platforms is empty. Dates before Updated and missing
+// platform dates count as Updated, so news without per-platform dates is released at Updated.
+//
+// Example:
+//
+//	released := item.ReleasedFor(cfg.Platforms)
+func (n *NewsItem) ReleasedFor(platforms []string) time.Time {
+	released := n.Updated
+	for platform, date := range n.PlatformDates {
+		if len(platforms) > 0 && !slices.Contains(platforms, platform) {
+			continue
+		}
+		if date.After(released) {
+			released = date
+		}
+	}
+	return released
+}
+
 // UnmarshalJSON implements custom JSON unmarshaling for NewsItem, handling flexible ID and timestamp formats.
+// A site-relative url is resolved against ArticleSiteURL; a missing or unusable one is replaced by
+// the default article link. Per-platform release dates are read from platform_dates, at the top
+// level or in the images or metadata objects. The author is read as a name or an object with a
+// name, and left empty otherwise.
 func (n *NewsItem) UnmarshalJSON(data []byte) error {
 	type Alias NewsItem
 	aux := &struct {
-		ID      interface{} `json:"id"`      // ID can be a string or a number in the JSON payload.
-		Updated string      `json:"updated"` // Updated is the timestamp in string format in the JSON payload.
+		ID            interface{}            `json:"id"`             // ID can be a string or a number in the JSON payload.
+		Updated       string                 `json:"updated"`        // Updated is the timestamp in string format in the JSON payload.
+		PlatformDates map[string]interface{} `json:"platform_dates"` // PlatformDates maps platform names to timestamps.
+		Metadata      map[string]interface{} `json:"metadata"`       // Metadata may hold platform_dates instead.
+		Author        interface{}            `json:"author"`         // Author is a name, or an object with a name.
 		*Alias
 	}{
 		Alias: (*Alias)(n),
@@ -199,50 +553,154 @@ func (n *NewsItem) UnmarshalJSON(data []byte) error {
 	}
 
 	// Parse the updated timestamp
-	if aux.Updated != "" {
-		// Try multiple time formats
-		formats := []string{
-			time.RFC3339,
-			"2006-01-02T15:04:05Z",
-			"2006-01-02 15:04:05",
-			"2006-01-02T15:04:05",
+	if t, ok := parseNewsTime(aux.Updated); ok {
+		n.Updated = t
+	}
+
+	// Collect per-platform release dates, wherever the payload keeps them
+	n.PlatformDates = nil
+	for _, dates := range []interface{}{aux.PlatformDates, aux.Metadata["platform_dates"], n.Images["platform_dates"]} {
+		if dates, ok := dates.(map[string]interface{}); ok {
+			n.addPlatformDates(dates)
 		}
+	}
+
+	// Extract thumbnail URL from images if available
+	if thumbnail, ok := ThumbnailImage(ParseImages(n.Images)); ok {
+		n.ThumbnailURL = thumbnail.URL
+	}
+
+	n.URL = canonicalArticleURL(n.URL, n.ID)
+	n.Author = authorName(aux.Author)
+
+	return nil
+}
 
-		for _, format := range formats {
-			if t, err := time.Parse(format, aux.Updated); err == nil {
-				n.Updated = t
-				break
+// authorName returns the name of an article author from the API: a string, or an object with a
+// name or display_name. Anything else has no name.
+func authorName(author interface{}) string {
+	switch v := author.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case map[string]interface{}:
+		for _, field := range []string{"name", "display_name"} {
+			if name, ok := v[field].(string); ok && strings.TrimSpace(name) != "" {
+				return strings.TrimSpace(name)
 			}
 		}
 	}
+	return ""
+}
 
-	// Extract thumbnail URL from images if available
-	if n.Images != nil {
-		// Try different thumbnail field names in order of preference
-		thumbnailFields := []string{"img_microsite_thumbnail", "thumbnail", "img_microsite_background", "unhighlight_img"}
-
-		for _, field := range thumbnailFields {
-			if thumbnail, ok := n.Images[field].(map[string]interface{}); ok {
-				if url, ok := thumbnail["url"].(string); ok {
-					n.ThumbnailURL = url
-					break
-				}
+// newsTimeFormats are the timestamp formats the API uses for news dates.
+var newsTimeFormats = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// parseNewsTime parses a news timestamp in any of newsTimeFormats.
+func parseNewsTime(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	for _, format := range newsTimeFormats {
+		if t, err := time.Parse(format, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// addPlatformDates adds the release dates in dates, keyed by platform name, to n.PlatformDates.
+// Platform names are normalized with NormalizePlatforms; unknown platforms and unparsable dates
+// are skipped, and the later date wins when names overlap.
+func (n *NewsItem) addPlatformDates(dates map[string]interface{}) {
+	for name, value := range dates {
+		str, ok := value.(string)
+		if !ok {
+			continue
+		}
+		date, ok := parseNewsTime(str)
+		if !ok {
+			continue
+		}
+		platforms, err := NormalizePlatforms([]string{name})
+		if err != nil {
+			continue
+		}
+		for _, platform := range platforms {
+			if n.PlatformDates == nil {
+				n.PlatformDates = make(map[string]time.Time)
+			}
+			if date.After(n.PlatformDates[platform]) {
+				n.PlatformDates[platform] = date
 			}
 		}
 	}
+}
 
-	return nil
+// canonicalArticleURL returns the absolute form of an article URL from the API, or the default
+// article link for newsID when it is missing or not an http(s) URL.
+func canonicalArticleURL(raw string, newsID int64) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ArticleURL(newsID)
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return ArticleURL(newsID)
+	}
+	if parsed.Scheme == "" && parsed.Host == "" && strings.HasPrefix(parsed.Path, "/") {
+		site, _ := url.Parse(ArticleSiteURL)
+		return site.ResolveReference(parsed).String()
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return ArticleURL(newsID)
+	}
+	return parsed.String()
 }
 
 // FetchOptions controls how fetchNews behaves.
 //
 // Example:
 //
-//	opts := types.FetchOptions{EnablePagination: true, PageLimit: 5}
+//	opts := types.FetchOptions{EnablePagination: true, PageSize: 100, PageLimit: 5}
 type FetchOptions struct {
 	EnablePagination bool // EnablePagination determines whether to fetch all pages or stop at the first.
+	PageSize         int  // PageSize is the number of items requested per page (0 = 100).
 	PageLimit        int  // PageLimit is the maximum number of pages to fetch (0 = unlimited).
 	ItemLimit        int  // ItemLimit is the maximum total items to fetch (0 = unlimited).
+	Offset           int  // Offset is the number of news items to skip before the first one fetched.
+
+	Retry RetryConfig // Retry controls how failed news API requests are retried (zero = no retries).
+}
+
+// RetryConfig defines retry behavior for news API requests.
+//
+// Each retry waits twice as long as the previous one, starting at BaseDelay and capped at MaxDelay.
+//
+// Example:
+//
+//	retry := types.RetryConfig{MaxRetries: 3, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+type RetryConfig struct {
+	MaxRetries int           // MaxRetries is the number of retries after the first attempt.
+	BaseDelay  time.Duration // BaseDelay is the delay before the first retry.
+	MaxDelay   time.Duration // MaxDelay caps the delay between retries.
+}
+
+// Delay returns the backoff delay before the given retry (1 for the first retry).
+func (c RetryConfig) Delay(retry int) time.Duration {
+	delay := c.BaseDelay
+	for i := 1; i < retry && (c.MaxDelay == 0 || delay < c.MaxDelay); i++ {
+		delay *= 2
+	}
+	if c.MaxDelay > 0 && delay > c.MaxDelay {
+		delay = c.MaxDelay
+	}
+	return delay
 }
 
 // DatabaseOptions controls how database operations behave.
@@ -255,6 +713,16 @@ type DatabaseOptions struct {
 	IgnoreErrors bool // IgnoreErrors determines whether to continue on individual item errors in batch operations.
 	RetryCount   int  // RetryCount is the number of retries on failure (default: 3).
 	LogProgress  bool // LogProgress determines whether to log progress for batch operations.
+
+	// RetryDelay is the delay before the first retry; each further retry waits twice as long,
+	// up to MaxRetryDelay. Zero retries immediately.
+	RetryDelay    time.Duration
+	MaxRetryDelay time.Duration // MaxRetryDelay caps the delay between retries (zero = no cap).
+}
+
+// RetryBackoff returns the delay before the given retry (1 for the first retry).
+func (o DatabaseOptions) RetryBackoff(retry int) time.Duration {
+	return RetryConfig{BaseDelay: o.RetryDelay, MaxDelay: o.MaxRetryDelay}.Delay(retry)
 }
 
 // DefaultFetchOptions returns sensible defaults for most fetch operations.
@@ -265,6 +733,7 @@ type DatabaseOptions struct {
 func DefaultFetchOptions() FetchOptions {
 	return FetchOptions{
 		EnablePagination: false,
+		PageSize:         0,
 		PageLimit:        0,
 		ItemLimit:        0,
 	}
@@ -277,10 +746,12 @@ func DefaultFetchOptions() FetchOptions {
 //	opts := types.DefaultDatabaseOptions()
 func DefaultDatabaseOptions() DatabaseOptions {
 	return DatabaseOptions{
-		UseBatch:     false,
-		IgnoreErrors: false,
-		RetryCount:   3,
-		LogProgress:  false,
+		UseBatch:      false,
+		IgnoreErrors:  false,
+		RetryCount:    3,
+		LogProgress:   false,
+		RetryDelay:    50 * time.Millisecond,
+		MaxRetryDelay: 500 * time.Millisecond,
 	}
 }
 
@@ -291,9 +762,11 @@ func DefaultDatabaseOptions() DatabaseOptions {
 //	opts := types.BatchDatabaseOptions()
 func BatchDatabaseOptions() DatabaseOptions {
 	return DatabaseOptions{
-		UseBatch:     true,
-		IgnoreErrors: true,
-		RetryCount:   3,
-		LogProgress:  true,
+		UseBatch:      true,
+		IgnoreErrors:  true,
+		RetryCount:    3,
+		LogProgress:   true,
+		RetryDelay:    50 * time.Millisecond,
+		MaxRetryDelay: 500 * time.Millisecond,
 	}
 }
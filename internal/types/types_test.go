@@ -5,6 +5,9 @@ package types
 
 import (
 	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -41,6 +44,19 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			shouldError: true,
 		},
+		{
+			name: "dry run without discord token",
+			config: Config{
+				PollPeriod:   600,
+				PollCount:    20,
+				FreshSeconds: 600,
+				MsgCount:     10,
+				ChannelsPath: "/data/channels.txt",
+				DatabasePath: "/data/stobot.db",
+				DryRun:       true,
+			},
+			shouldError: false,
+		},
 		{
 			name: "invalid poll period",
 			config: Config{
@@ -58,6 +74,188 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			shouldError: true,
 		},
+		{
+			name: "custom API base URL",
+			config: Config{
+				DiscordToken: "valid_token",
+				PollPeriod:   600,
+				PollCount:    20,
+				FreshSeconds: 600,
+				MsgCount:     10,
+				DatabasePath: "/data/stobot.db",
+				BaseURL:      "http://localhost:8080/sto/news",
+			},
+			shouldError: false,
+		},
+		{
+			name: "relative API base URL",
+			config: Config{
+				DiscordToken: "valid_token",
+				PollPeriod:   600,
+				PollCount:    20,
+				FreshSeconds: 600,
+				MsgCount:     10,
+				DatabasePath: "/data/stobot.db",
+				BaseURL:      "localhost:8080/sto/news",
+			},
+			shouldError: true,
+		},
+		{
+			name: "default thumbnail URL",
+			config: Config{
+				DiscordToken:        "valid_token",
+				PollPeriod:          600,
+				PollCount:           20,
+				FreshSeconds:        600,
+				MsgCount:            10,
+				DatabasePath:        "/data/stobot.db",
+				DefaultThumbnailURL: "https://example.com/stobot.png",
+			},
+			shouldError: false,
+		},
+		{
+			name: "relative default thumbnail URL",
+			config: Config{
+				DiscordToken:        "valid_token",
+				PollPeriod:          600,
+				PollCount:           20,
+				FreshSeconds:        600,
+				MsgCount:            10,
+				DatabasePath:        "/data/stobot.db",
+				DefaultThumbnailURL: "stobot.png",
+			},
+			shouldError: true,
+		},
+		{
+			name: "negative catch-up days",
+			config: Config{
+				DiscordToken: "valid_token",
+				PollPeriod:   600,
+				PollCount:    20,
+				FreshSeconds: 600,
+				MsgCount:     10,
+				DatabasePath: "/data/stobot.db",
+				CatchUpDays:  -1,
+			},
+			shouldError: true,
+		},
+		{
+			name: "thread archive duration",
+			config: Config{
+				DiscordToken:         "valid_token",
+				PollPeriod:           600,
+				PollCount:            20,
+				FreshSeconds:         600,
+				MsgCount:             10,
+				DatabasePath:         "/data/stobot.db",
+				ThreadArchiveMinutes: 4320,
+			},
+			shouldError: false,
+		},
+		{
+			name: "unsupported thread archive duration",
+			config: Config{
+				DiscordToken:         "valid_token",
+				PollPeriod:           600,
+				PollCount:            20,
+				FreshSeconds:         600,
+				MsgCount:             10,
+				DatabasePath:         "/data/stobot.db",
+				ThreadArchiveMinutes: 90,
+			},
+			shouldError: true,
+		},
+		{
+			name: "negative future skew",
+			config: Config{
+				DiscordToken:      "valid_token",
+				PollPeriod:        600,
+				PollCount:         20,
+				FreshSeconds:      600,
+				MsgCount:          10,
+				DatabasePath:      "/data/stobot.db",
+				FutureSkewSeconds: -1,
+			},
+			shouldError: true,
+		},
+		{
+			name: "negative max posts per cycle",
+			config: Config{
+				DiscordToken:     "valid_token",
+				PollPeriod:       600,
+				PollCount:        20,
+				FreshSeconds:     600,
+				MsgCount:         10,
+				DatabasePath:     "/data/stobot.db",
+				MaxPostsPerCycle: -1,
+			},
+			shouldError: true,
+		},
+		{
+			name: "negative update notice seconds",
+			config: Config{
+				DiscordToken:        "valid_token",
+				PollPeriod:          600,
+				PollCount:           20,
+				FreshSeconds:        600,
+				MsgCount:            10,
+				DatabasePath:        "/data/stobot.db",
+				UpdateNoticeSeconds: -1,
+			},
+			shouldError: true,
+		},
+		{
+			name: "gallery max images above 9",
+			config: Config{
+				DiscordToken:     "valid_token",
+				PollPeriod:       600,
+				PollCount:        20,
+				FreshSeconds:     600,
+				MsgCount:         10,
+				DatabasePath:     "/data/stobot.db",
+				GalleryMaxImages: 10,
+			},
+			shouldError: true,
+		},
+		{
+			name: "register backfill count above 50",
+			config: Config{
+				DiscordToken:          "valid_token",
+				PollPeriod:            600,
+				PollCount:             20,
+				FreshSeconds:          600,
+				MsgCount:              10,
+				DatabasePath:          "/data/stobot.db",
+				RegisterBackfillCount: 51,
+			},
+			shouldError: true,
+		},
+		{
+			name: "DEV environment",
+			config: Config{
+				DiscordToken: "valid_token",
+				PollPeriod:   600,
+				PollCount:    20,
+				FreshSeconds: 600,
+				MsgCount:     10,
+				DatabasePath: "/data/stobot.db",
+				Environment:  "DEV",
+			},
+			shouldError: false,
+		},
+		{
+			name: "unknown environment",
+			config: Config{
+				DiscordToken: "valid_token",
+				PollPeriod:   600,
+				PollCount:    20,
+				FreshSeconds: 600,
+				MsgCount:     10,
+				DatabasePath: "/data/stobot.db",
+				Environment:  "staging",
+			},
+			shouldError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -270,6 +468,30 @@ func TestBot_Creation(t *testing.T) {
 	}
 }
 
+func TestBuildInstanceID(t *testing.T) {
+	tests := []struct {
+		name        string
+		environment string
+		hostname    string
+		shard       int
+		expected    string
+	}{
+		{"prod", "PROD", "bot-host", 0, "PROD@bot-host#0"},
+		{"dev with shard", "DEV", "laptop", 3, "DEV@laptop#3"},
+		{"no environment", "", "bot-host", 0, "ANY@bot-host#0"},
+		{"no hostname", "PROD", "", 1, "PROD@unknown#1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := BuildInstanceID(tt.environment, tt.hostname, tt.shard)
+			if result != tt.expected {
+				t.Errorf("BuildInstanceID(%q, %q, %d) = %q, expected %q", tt.environment, tt.hostname, tt.shard, result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestFetchOptions_Defaults(t *testing.T) {
 	options := FetchOptions{}
 
@@ -280,6 +502,9 @@ func TestFetchOptions_Defaults(t *testing.T) {
 	if options.ItemLimit != 0 {
 		t.Error("Expected ItemLimit to default to 0")
 	}
+	if options.PageSize != 0 {
+		t.Error("Expected PageSize to default to 0")
+	}
 }
 
 func TestDatabaseOptions_Defaults(t *testing.T) {
@@ -345,3 +570,204 @@ func containsSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestNewsItem_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  string
+		expected string
+	}{
+		{"canonical URL", `{"id": 42, "url": "https://playstartrekonline.com/en/news/article/42-anniversary-event"}`, "https://playstartrekonline.com/en/news/article/42-anniversary-event"},
+		{"site-relative URL", `{"id": 42, "url": "/en/news/article/42/xbox"}`, "https://playstartrekonline.com/en/news/article/42/xbox"},
+		{"no URL", `{"id": "42", "title": "Anniversary Event"}`, "https://playstartrekonline.com/en/news/article/42"},
+		{"empty URL", `{"id": 42, "url": "  "}`, "https://playstartrekonline.com/en/news/article/42"},
+		{"unusable URL", `{"id": 42, "url": "javascript:alert(1)"}`, "https://playstartrekonline.com/en/news/article/42"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var newsItem NewsItem
+			if err := json.Unmarshal([]byte(tt.payload), &newsItem); err != nil {
+				t.Fatalf("Failed to unmarshal news item: %v", err)
+			}
+			if newsItem.URL != tt.expected {
+				t.Errorf("Expected URL %q, got %q", tt.expected, newsItem.URL)
+			}
+			if newsItem.Link() != tt.expected {
+				t.Errorf("Expected link %q, got %q", tt.expected, newsItem.Link())
+			}
+		})
+	}
+}
+
+func TestNewsItem_UnmarshalJSONPlatformDates(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "news_platform_dates.json"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	var newsItems []NewsItem
+	if err := json.Unmarshal(data, &newsItems); err != nil {
+		t.Fatalf("Failed to unmarshal fixture: %v", err)
+	}
+	if len(newsItems) != 4 {
+		t.Fatalf("Expected 4 news items, got %d", len(newsItems))
+	}
+
+	date := func(day, hour int) time.Time { return time.Date(2024, 6, day, hour, 0, 0, 0, time.UTC) }
+	expected := []map[string]time.Time{
+		// In images, with an alias and mixed timestamp formats
+		{"pc": date(4, 16), "xbox": date(11, 16), "ps": date(11, 16)},
+		// In metadata, case-insensitive; unknown platforms and bad dates are skipped
+		{"pc": date(20, 0), "ps": date(27, 15)},
+		// At the top level
+		{"xbox": date(26, 10)},
+		// None given
+		nil,
+	}
+	for i, newsItem := range newsItems {
+		if len(newsItem.PlatformDates) != len(expected[i]) {
+			t.Errorf("News %d: expected platform dates %v, got %v", newsItem.ID, expected[i], newsItem.PlatformDates)
+			continue
+		}
+		for platform, want := range expected[i] {
+			if got := newsItem.PlatformDates[platform]; !got.Equal(want) {
+				t.Errorf("News %d: expected %s date %v, got %v", newsItem.ID, platform, want, got)
+			}
+		}
+	}
+
+	if newsItems[0].ThumbnailURL != "https://example.com/ascension.jpg" {
+		t.Errorf("Expected the thumbnail next to the platform dates, got %q", newsItems[0].ThumbnailURL)
+	}
+	if !newsItems[1].Updated.Equal(date(20, 15)) {
+		t.Errorf("Expected updated %v, got %v", date(20, 15), newsItems[1].Updated)
+	}
+
+	// Platform dates survive a round trip, as in exports
+	encoded, err := json.Marshal(newsItems[0])
+	if err != nil {
+		t.Fatalf("Failed to marshal news item: %v", err)
+	}
+	var decoded NewsItem
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal marshaled news item: %v", err)
+	}
+	if !decoded.PlatformDates["ps"].Equal(date(11, 16)) {
+		t.Errorf("Expected the ps date to round trip, got %v", decoded.PlatformDates)
+	}
+}
+
+func TestNewsItem_UnmarshalJSONAuthor(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "news_authors.json"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	var newsItems []NewsItem
+	if err := json.Unmarshal(data, &newsItems); err != nil {
+		t.Fatalf("Failed to unmarshal fixture: %v", err)
+	}
+
+	// A string, an object's name, its display name, an unusable value and none given
+	expected := []string{"Ambassador Kael", "Jane Doe", "CaptainWoof", "", ""}
+	if len(newsItems) != len(expected) {
+		t.Fatalf("Expected %d news items, got %d", len(expected), len(newsItems))
+	}
+	for i, newsItem := range newsItems {
+		if newsItem.Author != expected[i] {
+			t.Errorf("News %d: expected author %q, got %q", newsItem.ID, expected[i], newsItem.Author)
+		}
+	}
+
+	// The author survives a round trip, as in exports
+	encoded, err := json.Marshal(newsItems[1])
+	if err != nil {
+		t.Fatalf("Failed to marshal news item: %v", err)
+	}
+	var decoded NewsItem
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal marshaled news item: %v", err)
+	}
+	if decoded.Author != "Jane Doe" {
+		t.Errorf("Expected the author to round trip, got %q", decoded.Author)
+	}
+}
+
+func TestNewsItem_ReleasedFor(t *testing.T) {
+	updated := time.Date(2024, 6, 4, 16, 0, 0, 0, time.UTC)
+	consoles := time.Date(2024, 6, 11, 16, 0, 0, 0, time.UTC)
+	newsItem := NewsItem{
+		Updated:       updated,
+		PlatformDates: map[string]time.Time{"pc": updated.Add(-time.Hour), "xbox": consoles, "ps": consoles.Add(time.Hour)},
+	}
+
+	tests := []struct {
+		name      string
+		platforms []string
+		expected  time.Time
+	}{
+		{"earlier date counts as updated", []string{"pc"}, updated},
+		{"console release", []string{"xbox"}, consoles},
+		{"latest of the platforms", []string{"pc", "xbox", "ps"}, consoles.Add(time.Hour)},
+		{"all platforms", nil, consoles.Add(time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if released := newsItem.ReleasedFor(tt.platforms); !released.Equal(tt.expected) {
+				t.Errorf("Expected release %v, got %v", tt.expected, released)
+			}
+		})
+	}
+
+	withoutDates := NewsItem{Updated: updated}
+	if released := withoutDates.ReleasedFor([]string{"ps"}); !released.Equal(updated) {
+		t.Errorf("Expected news without platform dates to be released at updated, got %v", released)
+	}
+}
+
+func TestNewsItem_Link(t *testing.T) {
+	newsItem := NewsItem{ID: 42}
+	if link := newsItem.Link(); link != "https://playstartrekonline.com/en/news/article/42" {
+		t.Errorf("Expected the default article link without a URL, got %q", link)
+	}
+	newsItem.URL = "https://playstartrekonline.com/en/news/article/42-slug"
+	if link := newsItem.Link(); link != newsItem.URL {
+		t.Errorf("Expected the canonical URL, got %q", link)
+	}
+}
+
+func TestRetryConfigDelay(t *testing.T) {
+	config := RetryConfig{MaxRetries: 5, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second}
+	for i, delay := range expected {
+		if result := config.Delay(i + 1); result != delay {
+			t.Errorf("Expected delay %v before retry %d, got %v", delay, i+1, result)
+		}
+	}
+}
+
+func TestChannelConfig_InQuietHours(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      ChannelConfig
+		hour     int
+		expected bool
+	}{
+		{"no quiet hours", ChannelConfig{}, 3, false},
+		{"same day, inside", ChannelConfig{QuietHours: true, QuietHoursStart: 1, QuietHoursEnd: 6}, 3, true},
+		{"same day, at the end", ChannelConfig{QuietHours: true, QuietHoursStart: 1, QuietHoursEnd: 6}, 6, false},
+		{"same day, before", ChannelConfig{QuietHours: true, QuietHoursStart: 1, QuietHoursEnd: 6}, 0, false},
+		{"over midnight, at the start", ChannelConfig{QuietHours: true, QuietHoursStart: 22, QuietHoursEnd: 7}, 22, true},
+		{"over midnight, after midnight", ChannelConfig{QuietHours: true, QuietHoursStart: 22, QuietHoursEnd: 7}, 3, true},
+		{"over midnight, daytime", ChannelConfig{QuietHours: true, QuietHoursStart: 22, QuietHoursEnd: 7}, 12, false},
+		{"empty window", ChannelConfig{QuietHours: true, QuietHoursStart: 5, QuietHoursEnd: 5}, 5, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at := time.Date(2024, 6, 1, tt.hour, 30, 0, 0, time.UTC)
+			if result := tt.cfg.InQuietHours(at); result != tt.expected {
+				t.Errorf("Expected InQuietHours at %02d:30 to be %v, got %v", tt.hour, tt.expected, result)
+			}
+		})
+	}
+}
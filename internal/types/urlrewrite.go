@@ -0,0 +1,145 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// urlRewriteSeparator separates the old and new prefix of a rewrite rule specification.
+const urlRewriteSeparator = "=>"
+
+// URLRewriteRule rewrites URLs starting with From to start with To instead.
+// Rules are used to keep historical links and thumbnails working after the Arc Games
+// site moves to a new domain or path.
+//
+// Example:
+//
+//	rule := types.URLRewriteRule{
+//	    From: "https://www.arcgames.com/en/games/star-trek-online/news",
+//	    To:   "https://playstartrekonline.com/en/news",
+//	}
+type URLRewriteRule struct {
+	From string // From is the URL prefix to replace.
+	To   string // To is the URL prefix to replace it with.
+}
+
+// ParseURLRewriteRule parses a rule specification of the form "old-prefix=>new-prefix".
+// Both prefixes must be absolute http(s) URLs.
+//
+// Example:
+//
+//	rule, err := types.ParseURLRewriteRule("https://old.example.com/news=>https://new.example.com/news")
+func ParseURLRewriteRule(spec string) (URLRewriteRule, error) {
+	from, to, found := strings.Cut(spec, urlRewriteSeparator)
+	if !found {
+		return URLRewriteRule{}, fmt.Errorf("invalid URL rewrite rule %q: expected old-prefix%snew-prefix", spec, urlRewriteSeparator)
+	}
+
+	rule := URLRewriteRule{From: strings.TrimSpace(from), To: strings.TrimSpace(to)}
+	for _, prefix := range []string{rule.From, rule.To} {
+		parsed, err := url.Parse(prefix)
+		if err != nil {
+			return URLRewriteRule{}, fmt.Errorf("invalid URL rewrite rule %q: %v", spec, err)
+		}
+		if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return URLRewriteRule{}, fmt.Errorf("invalid URL rewrite rule %q: %q is not an absolute http(s) URL", spec, prefix)
+		}
+	}
+	if rule.From == rule.To {
+		return URLRewriteRule{}, fmt.Errorf("invalid URL rewrite rule %q: old and new prefix are the same", spec)
+	}
+
+	return rule, nil
+}
+
+// ParseURLRewriteRules parses a list of rule specifications, skipping empty entries.
+func ParseURLRewriteRules(specs []string) ([]URLRewriteRule, error) {
+	var rules []URLRewriteRule
+	for _, spec := range specs {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		rule, err := ParseURLRewriteRule(spec)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Apply rewrites rawURL if it starts with the rule's From prefix and reports whether it did.
+//
+// Matching is anchored at the start of the URL and ends on a path boundary, so a From of
+// "https://arcgames.com/news" matches "https://arcgames.com/news/1" and
+// "https://arcgames.com/news?id=1" but not "https://arcgames.com/newsletter" or
+// "https://arcgames.com.example.net/news". The scheme and host are compared case-insensitively.
+// URLs that already start with the To prefix are left unchanged, so rewriting is idempotent
+// even when To extends From.
+func (r URLRewriteRule) Apply(rawURL string) (string, bool) {
+	rest, ok := cutURLPrefix(rawURL, r.From)
+	if !ok {
+		return rawURL, false
+	}
+	if _, migrated := cutURLPrefix(rawURL, r.To); migrated {
+		return rawURL, false
+	}
+	return r.To + rest, true
+}
+
+// cutURLPrefix returns rawURL without prefix if prefix matches it up to a path boundary.
+func cutURLPrefix(rawURL, prefix string) (string, bool) {
+	if len(rawURL) < len(prefix) {
+		return "", false
+	}
+
+	// The scheme and host are case-insensitive, the path is not
+	authorityEnd := len(prefix)
+	if start := strings.Index(prefix, "://"); start >= 0 {
+		if slash := strings.IndexByte(prefix[start+3:], '/'); slash >= 0 {
+			authorityEnd = start + 3 + slash
+		}
+	}
+	if !strings.EqualFold(rawURL[:authorityEnd], prefix[:authorityEnd]) || rawURL[authorityEnd:len(prefix)] != prefix[authorityEnd:] {
+		return "", false
+	}
+
+	rest := rawURL[len(prefix):]
+	if rest != "" && !strings.HasSuffix(prefix, "/") && !strings.ContainsRune("/?#", rune(rest[0])) {
+		return "", false
+	}
+	return rest, true
+}
+
+// RewriteURL applies the first matching rule to rawURL and reports whether it was rewritten.
+//
+// Example:
+//
+//	newURL, changed := types.RewriteURL(item.ThumbnailURL, config.URLRewrites)
+func RewriteURL(rawURL string, rules []URLRewriteRule) (string, bool) {
+	for _, rule := range rules {
+		if rewritten, ok := rule.Apply(rawURL); ok {
+			return rewritten, true
+		}
+	}
+	return rawURL, false
+}
+
+// RewriteEmbedURLs applies the configured URL rewrite rules to the link, thumbnail and image of
+// a news embed before it is sent. Stored data is left unchanged. It is safe to call on a nil Config.
+func (c *Config) RewriteEmbedURLs(embed *discordgo.MessageEmbed) {
+	if c == nil || len(c.URLRewrites) == 0 || embed == nil {
+		return
+	}
+
+	embed.URL, _ = RewriteURL(embed.URL, c.URLRewrites)
+	if embed.Thumbnail != nil {
+		embed.Thumbnail.URL, _ = RewriteURL(embed.Thumbnail.URL, c.URLRewrites)
+	}
+	if embed.Image != nil {
+		embed.Image.URL, _ = RewriteURL(embed.Image.URL, c.URLRewrites)
+	}
+}
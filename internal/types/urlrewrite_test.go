@@ -0,0 +1,188 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestParseURLRewriteRule(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        string
+		expected    URLRewriteRule
+		shouldError bool
+	}{
+		{
+			name:     "valid rule",
+			spec:     "https://www.arcgames.com/en/games/star-trek-online/news=>https://playstartrekonline.com/en/news",
+			expected: URLRewriteRule{From: "https://www.arcgames.com/en/games/star-trek-online/news", To: "https://playstartrekonline.com/en/news"},
+		},
+		{
+			name:     "surrounding whitespace",
+			spec:     " https://old.example.com => https://new.example.com ",
+			expected: URLRewriteRule{From: "https://old.example.com", To: "https://new.example.com"},
+		},
+		{name: "missing separator", spec: "https://old.example.com", shouldError: true},
+		{name: "relative prefix", spec: "/news=>https://new.example.com/news", shouldError: true},
+		{name: "unsupported scheme", spec: "ftp://old.example.com=>https://new.example.com", shouldError: true},
+		{name: "same prefix", spec: "https://example.com=>https://example.com", shouldError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseURLRewriteRule(tt.spec)
+			if tt.shouldError {
+				if err == nil {
+					t.Errorf("Expected error for %q, got rule %+v", tt.spec, rule)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to parse rule: %v", err)
+			}
+			if rule != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, rule)
+			}
+		})
+	}
+}
+
+func TestParseURLRewriteRulesSkipsEmpty(t *testing.T) {
+	rules, err := ParseURLRewriteRules([]string{"", "https://a.example.com=>https://b.example.com", "  "})
+	if err != nil {
+		t.Fatalf("Failed to parse rules: %v", err)
+	}
+	if len(rules) != 1 {
+		t.Errorf("Expected 1 rule, got %d", len(rules))
+	}
+}
+
+func TestRewriteURL(t *testing.T) {
+	rules := []URLRewriteRule{
+		{From: "https://www.arcgames.com/en/games/star-trek-online/news", To: "https://playstartrekonline.com/en/news"},
+		{From: "https://images.arcgames.com", To: "https://cdn.playstartrekonline.com"},
+		{From: "https://legacy.example.com/news", To: "https://legacy.example.com/news/en"},
+	}
+
+	tests := []struct {
+		name     string
+		url      string
+		expected string
+		changed  bool
+	}{
+		{
+			name:     "path prefix",
+			url:      "https://www.arcgames.com/en/games/star-trek-online/news/article/123",
+			expected: "https://playstartrekonline.com/en/news/article/123",
+			changed:  true,
+		},
+		{
+			name:     "query string is kept",
+			url:      "https://images.arcgames.com/thumb.jpg?w=400&h=300",
+			expected: "https://cdn.playstartrekonline.com/thumb.jpg?w=400&h=300",
+			changed:  true,
+		},
+		{
+			name:     "query string directly after prefix",
+			url:      "https://www.arcgames.com/en/games/star-trek-online/news?id=1",
+			expected: "https://playstartrekonline.com/en/news?id=1",
+			changed:  true,
+		},
+		{
+			name:     "exact prefix",
+			url:      "https://images.arcgames.com",
+			expected: "https://cdn.playstartrekonline.com",
+			changed:  true,
+		},
+		{
+			name:     "host is case-insensitive",
+			url:      "https://IMAGES.ArcGames.com/thumb.jpg",
+			expected: "https://cdn.playstartrekonline.com/thumb.jpg",
+			changed:  true,
+		},
+		{
+			name:     "path is case-sensitive",
+			url:      "https://www.arcgames.com/EN/games/star-trek-online/news/article/1",
+			expected: "https://www.arcgames.com/EN/games/star-trek-online/news/article/1",
+		},
+		{
+			name:     "already migrated",
+			url:      "https://playstartrekonline.com/en/news/article/123",
+			expected: "https://playstartrekonline.com/en/news/article/123",
+		},
+		{
+			name:     "already migrated when new prefix extends old prefix",
+			url:      "https://legacy.example.com/news/en/article/1",
+			expected: "https://legacy.example.com/news/en/article/1",
+		},
+		{
+			name:     "new prefix extends old prefix",
+			url:      "https://legacy.example.com/news/article/1",
+			expected: "https://legacy.example.com/news/en/article/1",
+			changed:  true,
+		},
+		{
+			name:     "unrelated domain",
+			url:      "https://example.com/thumb.jpg",
+			expected: "https://example.com/thumb.jpg",
+		},
+		{
+			name:     "lookalike domain",
+			url:      "https://images.arcgames.com.evil.example/thumb.jpg",
+			expected: "https://images.arcgames.com.evil.example/thumb.jpg",
+		},
+		{
+			name:     "prefix in the middle of a path segment",
+			url:      "https://www.arcgames.com/en/games/star-trek-online/newsletter",
+			expected: "https://www.arcgames.com/en/games/star-trek-online/newsletter",
+		},
+		{
+			name:     "prefix not at the start",
+			url:      "https://proxy.example.com/?u=https://images.arcgames.com/thumb.jpg",
+			expected: "https://proxy.example.com/?u=https://images.arcgames.com/thumb.jpg",
+		},
+		{
+			name:     "empty URL",
+			url:      "",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, changed := RewriteURL(tt.url, rules)
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+			if changed != tt.changed {
+				t.Errorf("Expected changed=%v, got %v", tt.changed, changed)
+			}
+		})
+	}
+}
+
+func TestConfigRewriteEmbedURLs(t *testing.T) {
+	config := &Config{URLRewrites: []URLRewriteRule{{From: "https://old.example.com", To: "https://new.example.com"}}}
+	embed := &discordgo.MessageEmbed{
+		URL:       "https://old.example.com/article/1",
+		Thumbnail: &discordgo.MessageEmbedThumbnail{URL: "https://old.example.com/thumb.jpg"},
+		Image:     &discordgo.MessageEmbedImage{URL: "https://other.example.com/image.jpg"},
+	}
+
+	config.RewriteEmbedURLs(embed)
+
+	if embed.URL != "https://new.example.com/article/1" {
+		t.Errorf("Expected rewritten article URL, got %q", embed.URL)
+	}
+	if embed.Thumbnail.URL != "https://new.example.com/thumb.jpg" {
+		t.Errorf("Expected rewritten thumbnail URL, got %q", embed.Thumbnail.URL)
+	}
+	if embed.Image.URL != "https://other.example.com/image.jpg" {
+		t.Errorf("Expected unrelated image URL to be unchanged, got %q", embed.Image.URL)
+	}
+
+	// A nil config leaves the embed alone
+	var nilConfig *Config
+	nilConfig.RewriteEmbedURLs(embed)
+}
@@ -0,0 +1,87 @@
+// Package stobot is the public API for running STOBot from other Go programs: it starts the bot
+// and registers hooks that extend its news pipeline. The bot itself lives in internal packages;
+// the types here are aliases of theirs.
+//
+// Hooks are registered before the bot starts:
+//
+//	stobot.RegisterBeforePost("skip-maintenance", func(cfg stobot.ChannelConfig, item stobot.NewsItem) (stobot.NewsItem, bool, error) {
+//	    return item, slices.Contains(item.Tags, "maintenance"), nil
+//	})
+//
+//	a, err := stobot.New(config, stobot.Options{Version: "1.2.3"})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer a.Shutdown()
+//	if err := a.Run(ctx); err != nil {
+//	    log.Fatal(err)
+//	}
+package stobot
+
+import (
+	"github.com/FracKenA/sto_news_discord_bot/internal/app"
+	"github.com/FracKenA/sto_news_discord_bot/internal/news"
+	"github.com/FracKenA/sto_news_discord_bot/internal/types"
+)
+
+// App is a running STOBot: its database, Discord session and background posting.
+type App = app.App
+
+// Options configure an App beyond the bot's Config.
+type Options = app.Options
+
+// Config holds the bot configuration.
+type Config = types.Config
+
+// Bot holds the Discord session, database connection and configuration of a bot.
+type Bot = types.Bot
+
+// NewsItem represents a news article from the STO API.
+type NewsItem = types.NewsItem
+
+// FetchOptions controls how news is fetched.
+type FetchOptions = types.FetchOptions
+
+// NewsFetcher fetches news for a bot instead of the news API, see Options.Fetcher.
+type NewsFetcher = types.NewsFetcher
+
+// ChannelConfig holds a registered channel's posting configuration.
+type ChannelConfig = types.ChannelConfig
+
+// AfterFetchHook post-processes a batch of freshly fetched news items and returns the batch to
+// keep. cfg is the config of the bot that fetched them; it may be nil.
+type AfterFetchHook = news.AfterFetchHook
+
+// BeforePostHook inspects or rewrites a news item before it is posted to a channel, with the
+// channel's settings. Returning skip=true prevents the item from being posted to that channel.
+type BeforePostHook = news.BeforePostHook
+
+// AfterPostHook is notified after a news item has been posted to a channel.
+type AfterPostHook = news.AfterPostHook
+
+// DefaultShutdownTimeout is how long Run waits for in-flight news posts when the bot stops.
+const DefaultShutdownTimeout = app.DefaultShutdownTimeout
+
+// New validates config and prepares a bot; see App for running it.
+func New(config *Config, options Options) (*App, error) {
+	return app.New(config, options)
+}
+
+// RegisterAfterFetch adds a hook that runs after news is fetched, after the built-in ones that
+// clean the content and map tag aliases.
+//
+// Hooks run in registration order. A hook that returns an error or panics is logged and
+// skipped; the pipeline continues with the input it was given.
+func RegisterAfterFetch(name string, fn AfterFetchHook) {
+	news.DefaultHooks.RegisterAfterFetch(name, fn)
+}
+
+// RegisterBeforePost adds a hook that runs before a news item is posted to a channel.
+func RegisterBeforePost(name string, fn BeforePostHook) {
+	news.DefaultHooks.RegisterBeforePost(name, fn)
+}
+
+// RegisterAfterPost adds a hook that runs after a news item is posted to a channel.
+func RegisterAfterPost(name string, fn AfterPostHook) {
+	news.DefaultHooks.RegisterAfterPost(name, fn)
+}
@@ -0,0 +1,99 @@
+package stobot_test
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/FracKenA/sto_news_discord_bot/internal/database"
+	"github.com/FracKenA/sto_news_discord_bot/internal/news"
+	"github.com/FracKenA/sto_news_discord_bot/internal/testhelpers"
+	"github.com/FracKenA/sto_news_discord_bot/pkg/stobot"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestHooks(t *testing.T) {
+	original := news.DefaultHooks
+	news.DefaultHooks = news.NewHookRegistry()
+	t.Cleanup(func() { news.DefaultHooks = original })
+
+	stobot.RegisterAfterFetch("summaries", func(cfg *stobot.Config, items []stobot.NewsItem) ([]stobot.NewsItem, error) {
+		for i := range items {
+			items[i].Summary = "Read all about it."
+		}
+		return items, nil
+	})
+	stobot.RegisterBeforePost("channel-titles", func(cfg stobot.ChannelConfig, item stobot.NewsItem) (stobot.NewsItem, bool, error) {
+		item.Title = "[" + cfg.ID + "] " + item.Title
+		return item, false, nil
+	})
+	var mu sync.Mutex
+	var posted []string
+	stobot.RegisterAfterPost("record", func(channelID string, item stobot.NewsItem, messageID string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		posted = append(posted, channelID+"/"+item.Title)
+		return nil
+	})
+
+	fake := testhelpers.NewFakeDiscord(t)
+	fetcher := testhelpers.NewFakeNewsFetcher(stobot.NewsItem{
+		ID:        1,
+		Title:     "Patch Notes",
+		Tags:      []string{"patch-notes"},
+		Platforms: []string{"pc"},
+		Updated:   time.Now().Add(-time.Minute),
+	})
+	config := &stobot.Config{
+		DiscordToken: "test_token",
+		PollPeriod:   1,
+		PollCount:    10,
+		FreshSeconds: 3600,
+		MsgCount:     10,
+		DatabasePath: filepath.Join(t.TempDir(), "stobot.db"),
+		Environment:  "PROD",
+	}
+
+	a, err := stobot.New(config, stobot.Options{NoMigrationBackup: true, Session: fake.Session(), Fetcher: fetcher, ShutdownTimeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("Failed to create app: %v", err)
+	}
+	defer a.Shutdown()
+	if err := database.AddChannel(a.Bot(), "channel-a"); err != nil {
+		t.Fatalf("Failed to register channel: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = a.Run(ctx) }()
+
+	deadline := time.Now().Add(10 * time.Second)
+	var posts []testhelpers.FakeDiscordRequest
+	for len(posts) == 0 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+		posts = fake.RequestsTo("POST", "/channels/channel-a/messages")
+	}
+	if len(posts) != 1 {
+		t.Fatalf("Expected 1 post to channel-a, got %d", len(posts))
+	}
+	var message discordgo.MessageSend
+	if err := json.Unmarshal(posts[0].Body, &message); err != nil {
+		t.Fatalf("Failed to decode post: %v", err)
+	}
+	if len(message.Embeds) != 1 || message.Embeds[0].Title != "[channel-a] Patch Notes" || message.Embeds[0].Description != "Read all about it." {
+		t.Errorf("Expected the news rewritten by the hooks, got %+v", message.Embeds)
+	}
+
+	if err := a.Shutdown(); err != nil {
+		t.Errorf("Failed to shut down: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(posted) != 1 || posted[0] != "channel-a/[channel-a] Patch Notes" {
+		t.Errorf("Expected the AfterPost hook to see the post, got %v", posted)
+	}
+}